@@ -0,0 +1,215 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// parkedSession is the session left by the last switch, kept with its
+// watcher running so ctrl+o can bring it back where the user left it, with
+// whatever it wrote in the meantime.
+type parkedSession struct {
+	path           string
+	rawMessages    []message
+	teams          []parser.TeamSnapshot
+	todos          []parser.Todo
+	expanded       map[int]bool
+	foldedTurns    map[int]bool
+	cursor         int
+	scroll         int
+	ongoing        bool
+	ongoingWhy     parser.OngoingVerdict
+	lastTailUpdate time.Time
+	cwd            string
+	gitBranch      string
+	mode           string
+	highlightQuery string
+	basket         []basketEntry
+	mergedFrom     string
+	growth         growthRate
+	evictedTurns   int
+	fullHistory    bool
+	partialHistory bool
+	branches       []parser.Branch
+	pollRate       pollRateMsg
+
+	watcher *sessionWatcher
+	sub     chan tailUpdateMsg
+	errc    chan error
+	rates   chan pollRateMsg
+
+	// Each channel keeps the waiter it had when the session was parked until
+	// that waiter fires; what it delivers is held here for the restore.
+	pending     *tailUpdateMsg
+	errs        []error
+	tailWaiting bool
+	errWaiting  bool
+	rateWaiting bool
+}
+
+// parkSession captures the current session, watcher included, for a later
+// restoreSession. The watcher keeps running.
+func (m *model) parkSession() *parkedSession {
+	return &parkedSession{
+		path:           m.sessionPath,
+		rawMessages:    m.rawMessages,
+		teams:          m.teams,
+		todos:          m.todos,
+		expanded:       m.expanded,
+		foldedTurns:    m.foldedTurns,
+		cursor:         m.cursor,
+		scroll:         m.scroll,
+		ongoing:        m.sessionOngoing,
+		ongoingWhy:     m.ongoingWhy,
+		lastTailUpdate: m.lastTailUpdate,
+		cwd:            m.sessionCwd,
+		gitBranch:      m.sessionGitBranch,
+		mode:           m.sessionMode,
+		highlightQuery: m.highlightQuery,
+		basket:         m.basket,
+		mergedFrom:     m.mergedFrom,
+		growth:         m.growth,
+		evictedTurns:   m.evictedTurns,
+		fullHistory:    m.fullHistory,
+		partialHistory: m.partialHistory,
+		branches:       m.branches,
+		pollRate:       m.pollRate,
+		watcher:        m.watcher,
+		sub:            m.tailSub,
+		errc:           m.tailErrc,
+		rates:          m.pollRates,
+		tailWaiting:    true,
+		errWaiting:     true,
+		rateWaiting:    true,
+	}
+}
+
+// dropAltSession stops the alternate session's watcher and forgets it.
+func (m *model) dropAltSession() {
+	if m.altSession != nil {
+		m.altSession.watcher.stop()
+		m.altSession = nil
+	}
+}
+
+// restoreSession makes p the current session and resubscribes to its
+// watcher, replaying any update that arrived while it was parked.
+func (m *model) restoreSession(p *parkedSession) tea.Cmd {
+	m.stopDebugWatcher()
+	oldPath := m.sessionPath
+
+	m.setMessages(p.rawMessages)
+	m.resetWebhookState()
+	m.teams = p.teams
+	m.todos = p.todos
+	m.teamScroll = 0
+	m.expanded = p.expanded
+	m.foldedTurns = p.foldedTurns
+	m.clearRange()
+	m.resetDetailState()
+	m.cursor = p.cursor
+	m.scroll = p.scroll
+	m.sessionPath = p.path
+	m.sessionOngoing = p.ongoing
+	m.ongoingWhy = p.ongoingWhy
+	m.lastTailUpdate = p.lastTailUpdate
+	m.sessionCwd = p.cwd
+	m.sessionGitBranch = p.gitBranch
+	m.sessionMode = p.mode
+	m.highlightQuery = p.highlightQuery
+	m.basket, m.basketCursor, m.basketScroll = p.basket, 0, 0
+	m.turnPopup = nil
+	m.fork, m.forkConfirm = nil, false
+	m.mergedFrom = p.mergedFrom
+	m.growth = p.growth
+	m.evictedTurns = p.evictedTurns
+	m.fullHistory = p.fullHistory
+	m.partialHistory = p.partialHistory
+	m.loadingHistory = false
+	m.branches = p.branches
+	m.pollRate = p.pollRate
+	m.watcher = p.watcher
+	m.tailSub = p.sub
+	m.tailErrc = p.errc
+	m.pollRates = p.rates
+	m.watching = true
+	m.animFrame = 0
+	m.dismissErrorBanner()
+	m.view = viewList
+	m.layoutList()
+
+	cmds := []tea.Cmd{m.moveViewer(oldPath)}
+	switch {
+	case p.pending != nil:
+		update := *p.pending
+		cmds = append(cmds, func() tea.Msg { return update })
+	case !p.tailWaiting:
+		cmds = append(cmds, waitForTailUpdate(m.tailSub))
+	}
+	if !p.errWaiting {
+		cmds = append(cmds, waitForWatcherErr(m.tailErrc))
+	}
+	if !p.rateWaiting {
+		cmds = append(cmds, waitForPollRate(m.pollRates))
+	}
+	for _, err := range p.errs {
+		cmds = append(cmds, m.reportTailError(err, time.Now()))
+	}
+	if m.sessionOngoing {
+		m.tickSeq++
+		cmds = append(cmds, tickCmd(m.tickSeq))
+	}
+	return tea.Batch(cmds...)
+}
+
+// toggleAltSession swaps the current session with the alternate one, like
+// vim's alternate file.
+func (m model) toggleAltSession() (model, tea.Cmd) {
+	alt := m.altSession
+	if alt == nil || m.watcher == nil {
+		m.flashStatus = "No alternate session"
+		return m, flashClearCmd()
+	}
+	m.altSession = m.parkSession()
+	cmd := m.restoreSession(alt)
+	m.flashStatus = "Switched to " + strings.TrimSuffix(filepath.Base(alt.path), ".jsonl")
+	return m, tea.Batch(cmd, flashClearCmd())
+}
+
+// parkedTailUpdate holds an update from the alternate session's watcher for
+// its restore, reporting whether u came from it.
+func (p *parkedSession) parkedTailUpdate(u tailUpdateMsg) bool {
+	if p == nil || u.source != p.sub {
+		return false
+	}
+	p.pending = &u
+	p.tailWaiting = false
+	return true
+}
+
+// parkedWatcherErr holds an error from the alternate session's watcher for
+// its restore, reporting whether e came from it.
+func (p *parkedSession) parkedWatcherErr(e watcherErrMsg) bool {
+	if p == nil || e.source != p.errc {
+		return false
+	}
+	p.errs = append(p.errs, e.err)
+	p.errWaiting = false
+	return true
+}
+
+// parkedPollRate records a poll rate from the alternate session's watcher,
+// reporting whether r came from it.
+func (p *parkedSession) parkedPollRate(r pollRateMsg) bool {
+	if p == nil || r.source != p.rates {
+		return false
+	}
+	p.pollRate = r
+	p.rateWaiting = false
+	return true
+}
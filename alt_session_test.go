@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestToggleAltSession(t *testing.T) {
+	first, second := writeTurns(t, 2), writeTurns(t, 3)
+	m := testModel()
+	t.Cleanup(func() {
+		if m.watcher != nil {
+			m.watcher.stop()
+		}
+		m.dropAltSession()
+	})
+	load := func(path string) {
+		t.Helper()
+		r, err := loadSession(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, _ := m.Update(loadSessionMsg{loadResult: r})
+		m = asModel(result)
+	}
+
+	result, _ := m.Update(key("ctrl+o"))
+	if got := asModel(result).flashStatus; got != "No alternate session" {
+		t.Errorf("flash = %q without an alternate", got)
+	}
+
+	load(first)
+	m.cursor = 1
+	firstWatcher := m.watcher
+	load(second)
+	secondCount := len(m.messages)
+	if m.altSession == nil || m.altSession.path != first || m.altSession.watcher != firstWatcher {
+		t.Fatalf("the first session should be parked with its watcher")
+	}
+
+	// Updates from the parked watcher wait for its restore.
+	parked := tailUpdateMsg{messages: []message{userMsg("new prompt")}, source: m.altSession.sub}
+	result, _ = m.Update(parked)
+	m = asModel(result)
+	if len(m.messages) != secondCount || m.altSession.pending == nil {
+		t.Fatalf("parked update applied to the current session")
+	}
+
+	result, _ = m.Update(key("ctrl+o"))
+	m = asModel(result)
+	if m.sessionPath != first || m.watcher != firstWatcher || m.cursor != 1 {
+		t.Errorf("ctrl+o: path %s, cursor %d; want the first session as left", m.sessionPath, m.cursor)
+	}
+	if m.altSession == nil || m.altSession.path != second {
+		t.Errorf("the second session should become the alternate")
+	}
+}
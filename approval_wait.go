@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// approvalWait totals the gap between a session's prompted tool calls and
+// their results: the time a person took to approve (or reject) each call
+// plus the time it ran. The transcript records no approval event, so the
+// two are only told apart when the tool reports its own run time, or when
+// it was rejected and never ran.
+type approvalWait struct {
+	calls   int   // prompted calls with a result and a measured gap
+	totalMs int64 // approval plus execution, summed over calls
+	split   int   // calls whose run time is known
+	waitMs  int64 // approval alone, summed over the split calls
+}
+
+// buildApprovalWait measures the calls that ran in a mode that prompts for
+// them (the audit's "approved" and "rejected"), main agent and subagents,
+// with the same permission mode tracking as the audit log.
+func buildApprovalWait(msgs []message) approvalWait {
+	var a approvalWait
+	var visit func(items []displayItem, mode string)
+	visit = func(items []displayItem, mode string) {
+		for _, item := range items {
+			if item.itemType == parser.ItemToolCall {
+				a.add(item, auditApproval(item, mode))
+			}
+			if item.subagentProcess != nil {
+				visit(buildTraceItems(item), mode)
+			}
+		}
+	}
+	mode := ""
+	for _, msg := range msgs {
+		if msg.role == RoleUser && msg.permissionMode != "" {
+			mode = msg.permissionMode
+		}
+		visit(msg.items, mode)
+	}
+	return a
+}
+
+// add counts one tool call. Calls without a gap (no timestamps, or one
+// discarded as inflated by a concurrent subagent) are left out.
+func (a *approvalWait) add(item displayItem, approval string) {
+	if item.durationMs <= 0 || (approval != approvalApproved && approval != approvalRejected) {
+		return
+	}
+	a.calls++
+	a.totalMs += item.durationMs
+	switch {
+	case approval == approvalRejected:
+		a.split++
+		a.waitMs += item.durationMs
+	case item.execMs > 0:
+		a.split++
+		a.waitMs += max(item.durationMs-item.execMs, 0)
+	}
+}
+
+// String renders "14 prompted calls, 3m 12s approval+execution, at least
+// 1m 5s waiting on approval", or "" when nothing was prompted. The wait is a
+// floor: calls whose run time is unknown add nothing to it.
+func (a approvalWait) String() string {
+	if a.calls == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("%s, %s approval+execution", parser.Plural(a.calls, "prompted call"), localRender.Duration(a.totalMs))
+	if a.split == 0 {
+		return s
+	}
+	if a.split == a.calls {
+		return s + ", " + localRender.Duration(a.waitMs) + " waiting on approval"
+	}
+	return s + ", at least " + localRender.Duration(a.waitMs) + " waiting on approval"
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestBuildApprovalWait(t *testing.T) {
+	call := func(name string, cat parser.ToolCategory, durMs, execMs int64, result string, isErr bool) displayItem {
+		return displayItem{itemType: parser.ItemToolCall, toolName: name, toolCategory: cat,
+			durationMs: durMs, execMs: execMs, toolResult: result, toolError: isErr}
+	}
+	const rejected = "The user doesn't want to proceed with this tool use. The tool use was rejected."
+	prompt := userMsg("go")
+	msgs := []message{
+		prompt,
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				call("Bash", parser.CategoryBash, 20_000, 0, "ok", false),         // unsplit
+				call("WebFetch", parser.CategoryWeb, 9_000, 1_500, "page", false), // 7.5s wait
+				call("Read", parser.CategoryRead, 30_000, 0, "file", false),       // never prompts
+				call("Bash", parser.CategoryBash, 4_000, 0, rejected, true),       // rejected: all wait
+				call("Bash", parser.CategoryBash, 0, 0, "ok", false),              // no gap
+			}
+		}),
+	}
+	got := buildApprovalWait(msgs)
+	want := approvalWait{calls: 3, totalMs: 33_000, split: 2, waitMs: 11_500}
+	if got != want {
+		t.Errorf("wait = %+v, want %+v", got, want)
+	}
+	if s := got.String(); s != "3 prompted calls, 33s approval+execution, at least 12s waiting on approval" {
+		t.Errorf("String() = %q", s)
+	}
+
+	// Nothing prompts in bypassPermissions.
+	prompt.permissionMode = "bypassPermissions"
+	msgs[0] = prompt
+	if got := buildApprovalWait(msgs); got.calls != 1 || got.String() == "" {
+		t.Errorf("bypass: wait = %+v, want only the rejection", got)
+	}
+}
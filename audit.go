@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// Audit approval values. The transcript records rejections but not prompts,
+// so "approved" covers both a user clicking approve and an allow rule.
+const (
+	approvalRejected    = "rejected"     // user rejected the call at the prompt
+	approvalPending     = "pending"      // no result yet
+	approvalNotRequired = "not required" // read-only tools never prompt
+	approvalApproved    = "approved"     // ran in a mode that prompts for it
+	approvalAuto        = "auto"         // the permission mode approved it
+)
+
+// auditEntry is one tool call in an audit report.
+type auditEntry struct {
+	Time     string `json:"time,omitempty"` // RFC 3339; empty when not recorded
+	Agent    string `json:"agent"`          // "main" or the subagent label
+	Tool     string `json:"tool"`
+	Target   string `json:"target,omitempty"` // file path, command, URL, or pattern
+	Mode     string `json:"mode,omitempty"`   // permission mode in effect
+	Approval string `json:"approval"`
+	Error    bool   `json:"error,omitempty"`
+}
+
+// auditReport is the JSON document written by --export audit.
+type auditReport struct {
+	Session   string       `json:"session"`
+	Cwd       string       `json:"cwd,omitempty"`
+	ToolCalls []auditEntry `json:"toolCalls"`
+}
+
+// buildAuditLog lists every tool call in the session, including those made
+// by subagents (nested ones too), in transcript order. Subagents run in the
+// permission mode in effect when they were spawned.
+func buildAuditLog(msgs []message) []auditEntry {
+	var entries []auditEntry
+	var visit func(items []displayItem, agent, mode string)
+	visit = func(items []displayItem, agent, mode string) {
+		for _, item := range items {
+			if item.itemType != parser.ItemToolCall && item.itemType != parser.ItemSubagent {
+				continue
+			}
+			entries = append(entries, auditEntryFor(item, agent, mode))
+			if item.subagentProcess != nil {
+				visit(buildTraceItems(item), agentLabel(item.subagentProcess), mode)
+			}
+		}
+	}
+
+	mode := ""
+	for _, msg := range msgs {
+		if msg.role == RoleUser && msg.permissionMode != "" {
+			mode = msg.permissionMode
+		}
+		visit(msg.items, mainAgentLabel, mode)
+	}
+	return entries
+}
+
+// auditEntryFor describes one tool call.
+func auditEntryFor(item displayItem, agent, mode string) auditEntry {
+	e := auditEntry{
+		Agent:    agent,
+		Tool:     item.toolName,
+		Target:   parser.ToolTarget(json.RawMessage(item.toolInput)),
+		Mode:     mode,
+		Approval: auditApproval(item, mode),
+		Error:    item.toolError,
+	}
+	if !item.timestamp.IsZero() {
+		e.Time = item.timestamp.UTC().Format(time.RFC3339)
+	}
+	if e.Target == "" {
+		e.Target = item.toolSummary
+	}
+	return e
+}
+
+// auditApproval classifies how a tool call was allowed to run.
+func auditApproval(item displayItem, mode string) string {
+	if item.toolError && parser.IsToolRejection(item.toolResult) {
+		return approvalRejected
+	}
+	if item.toolResult == "" && !item.toolError {
+		return approvalPending
+	}
+	if parser.NeverPrompts(item.toolCategory) {
+		return approvalNotRequired
+	}
+	switch {
+	case mode == "bypassPermissions":
+		return approvalAuto
+	case mode == "acceptEdits" && (item.toolCategory == parser.CategoryEdit || item.toolCategory == parser.CategoryWrite):
+		return approvalAuto
+	}
+	return approvalApproved
+}
+
+// auditJSON renders the audit report as indented JSON.
+func auditJSON(session, cwd string, entries []auditEntry) ([]byte, error) {
+	if entries == nil {
+		entries = []auditEntry{}
+	}
+	data, err := json.MarshalIndent(auditReport{Session: session, Cwd: cwd, ToolCalls: entries}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestBuildAuditLog(t *testing.T) {
+	at := time.Date(2025, 1, 15, 10, 4, 12, 0, time.UTC)
+	explore := &parser.SubagentProcess{
+		ID:           "a1b2c3d4e5",
+		SubagentType: "Explore",
+		Chunks: []parser.Chunk{
+			{Type: parser.AIChunk, Items: []parser.DisplayItem{
+				{Type: parser.ItemToolCall, ToolName: "Bash", ToolCategory: parser.CategoryBash, ToolInput: []byte(`{"command":"go test ./..."}`), ToolResult: "ok"},
+			}},
+		},
+	}
+	plan := userMsg("plan it")
+	plan.permissionMode = "plan"
+	edit := userMsg("now edit")
+	edit.permissionMode = "acceptEdits"
+	msgs := []message{
+		plan,
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Read", toolCategory: parser.CategoryRead, toolInput: `{"file_path": "/repo/main.go"}`, toolResult: "package main", timestamp: at},
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolCategory: parser.CategoryBash, toolInput: `{"command": "rm -rf build"}`, toolResult: "The user doesn't want to proceed with this tool use. The tool use was rejected.", toolError: true},
+			}
+		}),
+		edit,
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Edit", toolCategory: parser.CategoryEdit, toolInput: `{"file_path": "/repo/main.go"}`, toolResult: "ok"},
+				{itemType: parser.ItemSubagent, toolName: "Task", toolCategory: parser.CategoryTask, subagentType: "Explore", subagentProcess: explore, toolResult: "done"},
+				{itemType: parser.ItemToolCall, toolName: "WebFetch", toolCategory: parser.CategoryWeb, toolInput: `{"url": "https://go.dev"}`},
+			}
+		}),
+	}
+
+	want := []auditEntry{
+		{Time: "2025-01-15T10:04:12Z", Agent: "main", Tool: "Read", Target: "/repo/main.go", Mode: "plan", Approval: approvalNotRequired},
+		{Agent: "main", Tool: "Bash", Target: "rm -rf build", Mode: "plan", Approval: approvalRejected, Error: true},
+		{Agent: "main", Tool: "Edit", Target: "/repo/main.go", Mode: "acceptEdits", Approval: approvalAuto},
+		{Agent: "main", Tool: "Task", Mode: "acceptEdits", Approval: approvalNotRequired},
+		{Agent: agentLabel(explore), Tool: "Bash", Target: "go test ./...", Mode: "acceptEdits", Approval: approvalApproved},
+		{Agent: "main", Tool: "WebFetch", Target: "https://go.dev", Mode: "acceptEdits", Approval: approvalPending},
+	}
+	got := buildAuditLog(msgs)
+	if len(got) != len(want) {
+		t.Fatalf("len(entries) = %d, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAuditApproval_Bypass(t *testing.T) {
+	item := displayItem{itemType: parser.ItemToolCall, toolName: "Bash", toolCategory: parser.CategoryBash, toolResult: "ok"}
+	if got := auditApproval(item, "bypassPermissions"); got != approvalAuto {
+		t.Errorf("bypassPermissions Bash = %q, want %q", got, approvalAuto)
+	}
+	if got := auditApproval(item, "acceptEdits"); got != approvalApproved {
+		t.Errorf("acceptEdits Bash = %q, want %q", got, approvalApproved)
+	}
+}
+
+func TestAuditJSON(t *testing.T) {
+	data, err := auditJSON("abc123", "/repo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report auditReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if report.Session != "abc123" || report.Cwd != "/repo" || report.ToolCalls == nil {
+		t.Errorf("report = %+v, want session, cwd, and an empty toolCalls list", report)
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// basketPreviewLines caps the result lines tab shows under a basket entry.
+const basketPreviewLines = 8
+
+// basketEntry is a detail item put in the evidence basket (B): a copy of the
+// item as it was then, and the message it came from, so the basket keeps
+// it however the list changes and enter can go back to it.
+type basketEntry struct {
+	item    displayItem
+	source  string // heading of the message: "Turn 3 · Claude opus4.6 3:04:05 PM"
+	msgUUID string // uuid of the message, to find it again
+	itemIdx int    // the item's index in the message, or -1 inside a subagent trace
+}
+
+// sameBasketItem reports whether two items are the same tool call or text,
+// whichever message view they were taken from.
+func sameBasketItem(a, b displayItem) bool {
+	return a.itemType == b.itemType && a.toolName == b.toolName && a.timestamp.Equal(b.timestamp) &&
+		a.toolInput == b.toolInput && a.text == b.text
+}
+
+// basketIndex returns where item is in the basket, or -1.
+func (m model) basketIndex(item displayItem) int {
+	for i, e := range m.basket {
+		if sameBasketItem(e.item, item) {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleBasket puts the detail row under the cursor in the evidence basket,
+// or takes it out.
+func (m *model) toggleBasket() tea.Cmd {
+	rows := m.detailVisibleRows()
+	if m.detailCursor >= len(rows) || m.cursor >= len(m.messages) {
+		return nil
+	}
+	row := rows[m.detailCursor]
+	if i := m.basketIndex(row.item); i >= 0 {
+		m.basket = append(m.basket[:i:i], m.basket[i+1:]...)
+		m.flashStatus = fmt.Sprintf("Removed from the basket (%s)", parser.Plural(len(m.basket), "item"))
+		return flashClearCmd()
+	}
+	msg := m.currentDetailMsg()
+	source := exportHeading(msg)
+	if turn := m.messages[m.cursor].turn; turn > 0 {
+		source = fmt.Sprintf("Turn %d · %s", turn+m.evictedTurns, source)
+	}
+	itemIdx := row.parentIndex
+	if m.traceMsg != nil || row.childIndex != -1 {
+		itemIdx = -1
+	}
+	m.basket = append(m.basket, basketEntry{
+		item:    row.item,
+		source:  source,
+		msgUUID: m.messages[m.cursor].uuid,
+		itemIdx: itemIdx,
+	})
+	m.flashStatus = fmt.Sprintf("Added to the basket (%s, B in the list)", parser.Plural(len(m.basket), "item"))
+	return flashClearCmd()
+}
+
+// openBasket switches to the basket panel, returning to the list on exit.
+func (m *model) openBasket() tea.Cmd {
+	if len(m.basket) == 0 {
+		m.flashStatus = "The basket is empty (B on a detail item adds it)"
+		return flashClearCmd()
+	}
+	m.basketCursor = min(m.basketCursor, len(m.basket)-1)
+	m.view = viewBasket
+	m.ensureBasketVisible()
+	return nil
+}
+
+// updateBasket handles key events in the basket panel.
+func (m model) updateBasket(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "B":
+		m.view = viewList
+	case "j", "down":
+		if m.basketCursor < len(m.basket)-1 {
+			m.basketCursor++
+		}
+		m.ensureBasketVisible()
+	case "k", "up":
+		if m.basketCursor > 0 {
+			m.basketCursor--
+		}
+		m.ensureBasketVisible()
+	case "G":
+		m.basketCursor = max(len(m.basket)-1, 0)
+		m.ensureBasketVisible()
+	case "g":
+		m.basketCursor = 0
+		m.basketScroll = 0
+	case "tab":
+		m.basketPreview = !m.basketPreview
+		m.ensureBasketVisible()
+	case "d":
+		if m.basketCursor < len(m.basket) {
+			m.basket = append(m.basket[:m.basketCursor:m.basketCursor], m.basket[m.basketCursor+1:]...)
+			if len(m.basket) == 0 {
+				m.view = viewList
+				m.basketCursor = 0
+				return m, nil
+			}
+			m.basketCursor = min(m.basketCursor, len(m.basket)-1)
+			m.ensureBasketVisible()
+		}
+	case "D":
+		m.basket = nil
+		m.basketCursor, m.basketScroll = 0, 0
+		m.view = viewList
+		m.flashStatus = "Emptied the basket"
+		return m, flashClearCmd()
+	case "enter":
+		cmd := m.openBasketEntry()
+		return m, cmd
+	case "y":
+		cmd := m.copyBasket()
+		return m, cmd
+	case "x":
+		cmd := m.exportBasket()
+		return m, cmd
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// openBasketEntry opens the message the entry under the cursor came from
+// in the detail view, on the item when it's a top-level one.
+func (m *model) openBasketEntry() tea.Cmd {
+	if m.basketCursor >= len(m.basket) {
+		return nil
+	}
+	e := m.basket[m.basketCursor]
+	idx := slices.IndexFunc(m.messages, func(msg message) bool { return msg.uuid == e.msgUUID })
+	if idx < 0 || e.msgUUID == "" {
+		m.flashStatus = "That message is no longer in the list"
+		return flashClearCmd()
+	}
+	m.cursor = idx
+	m.layoutList()
+	m.ensureCursorVisible()
+	m.enterDetail()
+	if e.itemIdx >= 0 {
+		m.selectDetailItem(e.itemIdx)
+		m.ensureDetailCursorVisible()
+	}
+	return nil
+}
+
+// basketItems returns the items in the basket, in the order added.
+func (m model) basketItems() []displayItem {
+	items := make([]displayItem, len(m.basket))
+	for i, e := range m.basket {
+		items[i] = e.item
+	}
+	return items
+}
+
+// copyBasket copies the results of everything in the basket, separated by
+// blank lines, as y does for marked detail items.
+func (m *model) copyBasket() tea.Cmd {
+	var parts []string
+	for _, item := range m.basketItems() {
+		if text := strings.TrimSpace(markedItemText(item)); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	if len(parts) == 0 {
+		m.flashStatus = "Nothing in the basket has a result to copy"
+		return flashClearCmd()
+	}
+	m.flashStatus = fmt.Sprintf("Copied %s", parser.Plural(len(parts), "result"))
+	return tea.Batch(tea.SetClipboard(strings.Join(parts, "\n\n")), flashClearCmd())
+}
+
+// basketMarkdown renders the basket as the marked items export does, with
+// the message each item came from under its heading.
+func (m model) basketMarkdown() string {
+	var b strings.Builder
+	b.WriteString("# Evidence basket\n")
+	for _, e := range m.basket {
+		writeItemMarkdown(&b, e.item, e.source)
+	}
+	return b.String()
+}
+
+// exportBasket writes the basket to a Markdown file in exportDir.
+func (m *model) exportBasket() tea.Cmd {
+	if m.readOnly {
+		m.flashStatus = "Read-only: exports are off"
+		return flashClearCmd()
+	}
+	name := strings.TrimSuffix(filepath.Base(m.sessionPath), ".jsonl")
+	if m.sessionPath == "" {
+		name = "session"
+	}
+	path := filepath.Join(exportDir, name+"-basket-"+time.Now().Format("20060102-150405")+".md")
+	m.flashStatus = fmt.Sprintf("Exporting %s...", parser.Plural(len(m.basket), "item"))
+	return exportItemsCmd(m.basketMarkdown(), len(m.basket), path)
+}
+
+// basketViewHeight returns the visible rows (minus header and footer).
+func (m model) basketViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// basketLines renders the basket's rows, and with the preview on each
+// entry's result under it. starts[i] is the line entry i begins on.
+func (m model) basketLines(width int) (lines []string, starts []int) {
+	for i, e := range m.basket {
+		starts = append(starts, len(lines))
+		active := i == m.basketCursor
+		indicator, name := itemIndicatorName(e.item)
+		left := selectionIndicator(active) + indicator + " " + StylePrimaryBold.Render(name)
+		source := StyleDim.Render(e.source)
+		style := StyleSecondary
+		if active {
+			style = StylePrimaryBold
+		}
+		summary, _, _ := strings.Cut(strings.TrimSpace(fullItemSummary(e.item)), "\n")
+		room := max(width-lipgloss.Width(left)-lipgloss.Width(source)-3, 10)
+		lines = append(lines, spaceBetween(left+" "+style.Render(parser.Truncate(summary, room)), source, width))
+		if !m.basketPreview {
+			continue
+		}
+		text := strings.TrimSpace(markedItemText(e.item))
+		if text == "" {
+			continue
+		}
+		preview := strings.Split(text, "\n")
+		more := len(preview) - basketPreviewLines
+		preview = preview[:min(len(preview), basketPreviewLines)]
+		for _, line := range preview {
+			lines = append(lines, "    "+StyleDim.Render(parser.Truncate(line, width-4)))
+		}
+		if more > 0 {
+			lines = append(lines, "    "+StyleMuted.Render(fmt.Sprintf("… %s more", pluralCount(more, "line"))))
+		}
+	}
+	return lines, starts
+}
+
+// ensureBasketVisible adjusts basketScroll so the cursor's entry is
+// visible.
+func (m *model) ensureBasketVisible() {
+	_, starts := m.basketLines(m.clampWidth())
+	if m.basketCursor >= len(starts) {
+		return
+	}
+	m.basketScroll = scrollToShow(m.basketScroll, starts[m.basketCursor], 1, m.basketViewHeight())
+}
+
+// viewBasketPanel renders the evidence basket: one row per item with the
+// message it came from.
+func (m model) viewBasketPanel() string {
+	width := m.clampWidth()
+
+	header := StyleAccentBold.Render("Evidence basket") + " " +
+		StyleDim.Render("("+parser.Plural(len(m.basket), "item")+")") + "\n"
+	lines, _ := m.basketLines(width)
+	content := header + "\n" + strings.Join(scrollWindow(lines, m.basketViewHeight(), m.basketScroll), "\n")
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"j/k", "nav",
+		"tab", "preview",
+		"enter", "open",
+		"d", "remove",
+		"D", "empty",
+		"y", "copy results",
+		"x", "export md",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
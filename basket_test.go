@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestEvidenceBasket(t *testing.T) {
+	msg := claudeMsg(func(m *message) {
+		m.uuid = "a1"
+		m.items = []displayItem{
+			{itemType: parser.ItemThinking, text: "let me think"},
+			{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "go test ./..."}`, toolResult: "ok  \tpkg\t0.1s"},
+			{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "main.go"}`, toolResult: "package main"},
+		}
+	})
+	m := detailModel(msg)
+	press := func(keys ...string) {
+		t.Helper()
+		for _, k := range keys {
+			result, _ := m.Update(key(k))
+			m = asModel(result)
+		}
+	}
+
+	m.detailCursor = 2
+	press("B")
+	m.detailCursor = 1
+	press("B")
+	if len(m.basket) != 2 || m.basket[0].item.toolName != "Read" || m.basket[1].item.toolName != "Bash" {
+		t.Fatalf("basket = %+v, want Read then Bash", m.basket)
+	}
+	if !strings.Contains(plain(m.viewDetail()), Icon.Basket.Glyph) {
+		t.Error("detail rows in the basket should carry its badge")
+	}
+
+	// The basket outlives the detail view, unlike marks.
+	press("q", "B")
+	if m.view != viewBasket {
+		t.Fatalf("B in the list: view %v, want the basket", m.view)
+	}
+	if got := plain(m.viewBasketPanel()); !strings.Contains(got, "2 items") || !strings.Contains(got, "main.go") {
+		t.Errorf("basket panel = %q", got)
+	}
+
+	press("j", "enter")
+	if m.view != viewDetail || m.detailCursor != 1 {
+		t.Errorf("enter: view %v, cursor %d; want the Bash call in detail", m.view, m.detailCursor)
+	}
+
+	// B on an item already in the basket takes it out.
+	press("B")
+	if len(m.basket) != 1 || m.basket[0].item.toolName != "Read" {
+		t.Errorf("after removing Bash: basket = %+v", m.basket)
+	}
+
+	press("q", "B", "d")
+	if len(m.basket) != 0 || m.view != viewList {
+		t.Errorf("d on the last entry: %d left, view %v; want empty and back to the list", len(m.basket), m.view)
+	}
+	press("B")
+	if m.view != viewList || !strings.Contains(m.flashStatus, "empty") {
+		t.Errorf("B with an empty basket: view %v, flash %q", m.view, m.flashStatus)
+	}
+}
+
+func TestOpenBasketEntry_SameSecond(t *testing.T) {
+	// Two turns shown at the same time of day, on different days or within
+	// a second: the entry goes back to the one it came from.
+	turn := func(uuid, tool string) message {
+		return claudeMsg(func(m *message) {
+			m.uuid = uuid
+			m.items = []displayItem{{itemType: parser.ItemToolCall, toolName: tool}}
+		})
+	}
+	m := initialModel([]message{turn("a1", "Read"), turn("a2", "Bash")}, true)
+	m.width, m.height = 120, 40
+	m.cursor = 1
+	m.layoutList()
+	m.enterDetail()
+	m.toggleBasket()
+	m.cursor = 0
+	m.view = viewBasket
+
+	m.openBasketEntry()
+	if m.view != viewDetail || m.cursor != 1 {
+		t.Errorf("enter: view %v, cursor %d; want the second turn in detail", m.view, m.cursor)
+	}
+
+	m.setMessages([]message{turn("a2", "Bash")})
+	m.view = viewBasket
+	m.openBasketEntry()
+	if m.cursor != 0 || m.view != viewDetail {
+		t.Errorf("after a reload: view %v, cursor %d; want the turn found by its uuid", m.view, m.cursor)
+	}
+}
+
+func TestBasketMarkdown(t *testing.T) {
+	m := testModel()
+	m.basket = []basketEntry{
+		{item: displayItem{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "make"}`, toolResult: "boom", toolError: true}, source: "Turn 3 · Claude"},
+		{item: displayItem{itemType: parser.ItemOutput, text: "done"}, source: "Turn 4 · Claude"},
+	}
+	got := m.basketMarkdown()
+	for _, want := range []string{
+		"# Evidence basket\n",
+		"## `Bash` make\n\n_Turn 3 · Claude_\n",
+		"Result (error):\n\n```\nboom\n```",
+		"_Turn 4 · Claude_\n\ndone\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown missing %q:\n%s", want, got)
+		}
+	}
+}
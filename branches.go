@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// requestBranch asks the watcher to show the branch ending at leaf ("" for
+// the newest). Safe to call from the UI goroutine; a pending request is
+// replaced by the newer one.
+func (w *sessionWatcher) requestBranch(leaf string) {
+	select {
+	case w.branch <- leaf:
+	default:
+		select {
+		case <-w.branch:
+		default:
+		}
+		w.branch <- leaf
+	}
+}
+
+// openBranches switches to the branch list, cursor on the branch shown.
+// Flashes a notice instead when the session never forked.
+func (m *model) openBranches() tea.Cmd {
+	if len(m.branches) == 0 || m.watcher == nil {
+		m.flashStatus = "This session has no branches"
+		return flashClearCmd()
+	}
+	m.branchCursor = 0
+	for i, b := range m.branches {
+		if b.Current {
+			m.branchCursor = i
+		}
+	}
+	m.branchScroll = 0
+	m.view = viewBranches
+	m.ensureBranchVisible()
+	return nil
+}
+
+// updateBranches handles key events in the branch list.
+func (m model) updateBranches(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "b":
+		m.view = viewList
+	case "j", "down":
+		if m.branchCursor < len(m.branches)-1 {
+			m.branchCursor++
+		}
+		m.ensureBranchVisible()
+	case "k", "up":
+		if m.branchCursor > 0 {
+			m.branchCursor--
+		}
+		m.ensureBranchVisible()
+	case "G":
+		m.branchCursor = max(len(m.branches)-1, 0)
+		m.ensureBranchVisible()
+	case "g":
+		m.branchCursor = 0
+		m.branchScroll = 0
+	case "enter":
+		if m.branchCursor < len(m.branches) {
+			b := m.branches[m.branchCursor]
+			leaf := b.Leaf
+			if b.Latest {
+				leaf = "" // keep following the session as it grows
+			}
+			m.watcher.requestBranch(leaf)
+			m.view = viewList
+			m.flashStatus = fmt.Sprintf("Showing branch %d of %d", m.branchCursor+1, len(m.branches))
+			return m, flashClearCmd()
+		}
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// branchViewHeight returns the visible rows (minus header and footer).
+func (m model) branchViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// ensureBranchVisible adjusts branchScroll so the cursor row is visible.
+func (m *model) ensureBranchVisible() {
+	m.branchScroll = scrollToShow(m.branchScroll, m.branchCursor, 1, m.branchViewHeight())
+}
+
+// branchRow renders one branch: when its opening prompt was sent, the
+// prompt's first line, and whether it is the newest or the one shown.
+func branchRow(i int, b parser.Branch, selected bool, width int) string {
+	sel := selectionIndicator(selected)
+	num := fmt.Sprintf("%d. ", i+1)
+	var tags []string
+	if b.Latest {
+		tags = append(tags, "latest")
+	}
+	if b.Current {
+		tags = append(tags, "showing")
+	}
+	tag := ""
+	if len(tags) > 0 {
+		tag = "  " + StyleDim.Render(strings.Join(tags, ", "))
+	}
+	when := StyleDim.Render(formatTime(b.Time)) + "  "
+	style := StyleSecondary
+	if selected {
+		style = StylePrimaryBold
+	}
+	prompt, _, _ := strings.Cut(strings.TrimSpace(b.Prompt), "\n")
+	room := max(width-lipgloss.Width(sel)-len(num)-lipgloss.Width(when)-lipgloss.Width(tag), 10)
+	return sel + StyleAccentBold.Render(num) + when + style.Render(parser.Truncate(prompt, room)) + tag
+}
+
+// viewBranchList renders the branches of a forked session, each named by
+// the prompt that opened it.
+func (m model) viewBranchList() string {
+	width := m.clampWidth()
+
+	header := StyleAccentBold.Render("Branches") + " " +
+		StyleDim.Render("("+formatCount(len(m.branches))+" branches from /rewind or a checkpoint restore)") + "\n"
+
+	var lines []string
+	for i, b := range m.branches {
+		lines = append(lines, branchRow(i, b, i == m.branchCursor, width))
+	}
+
+	content := header + "\n" + strings.Join(scrollWindow(lines, m.branchViewHeight(), m.branchScroll), "\n")
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"enter", "show",
+		"j/k", "nav",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// writeForked writes a session rewound once: "try A" was abandoned for
+// "try B", both sent after the first answer.
+func writeForked(t *testing.T) string {
+	t.Helper()
+	lines := []string{
+		`{"uuid":"u1","type":"user","timestamp":"2025-01-15T10:00:00.000Z","message":{"role":"user","content":"set up"}}`,
+		`{"uuid":"a1","parentUuid":"u1","type":"assistant","timestamp":"2025-01-15T10:00:05.000Z","message":{"role":"assistant","content":[{"type":"text","text":"done"}],"model":"claude-opus-4-6","stop_reason":"end_turn"}}`,
+		`{"uuid":"u2","parentUuid":"a1","type":"user","timestamp":"2025-01-15T10:01:00.000Z","message":{"role":"user","content":"try A"}}`,
+		`{"uuid":"a2","parentUuid":"u2","type":"assistant","timestamp":"2025-01-15T10:01:05.000Z","message":{"role":"assistant","content":[{"type":"text","text":"A failed"}],"model":"claude-opus-4-6","stop_reason":"end_turn"}}`,
+		`{"uuid":"u3","parentUuid":"a1","type":"user","timestamp":"2025-01-15T10:02:00.000Z","message":{"role":"user","content":"try B"}}`,
+		`{"uuid":"a3","parentUuid":"u3","type":"assistant","timestamp":"2025-01-15T10:02:05.000Z","message":{"role":"assistant","content":[{"type":"text","text":"B worked"}],"model":"claude-opus-4-6","stop_reason":"end_turn"}}`,
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// userPrompts returns the prompt texts of msgs.
+func userPrompts(msgs []message) []string {
+	var out []string
+	for _, msg := range msgs {
+		if msg.role == RoleUser {
+			out = append(out, msg.content)
+		}
+	}
+	return out
+}
+
+func TestLoadSession_ForkedShowsNewestBranch(t *testing.T) {
+	result, err := loadSession(writeForked(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(userPrompts(result.messages), ", "); got != "set up, try B" {
+		t.Errorf("prompts = %q, want the newest branch", got)
+	}
+	if len(result.branches) != 2 || !result.branches[1].Current {
+		t.Errorf("branches = %+v, want two with the second shown", result.branches)
+	}
+	last := result.messages[len(result.messages)-2]
+	if last.branch != 2 || last.branches != 2 {
+		t.Errorf("fork prompt branch = %d of %d, want 2 of 2", last.branch, last.branches)
+	}
+	if got := userHeaderLine(last); !strings.Contains(got, "branch 2 of 2") {
+		t.Errorf("header = %q, want a fork marker", got)
+	}
+}
+
+func TestSessionWatcher_SwitchBranch(t *testing.T) {
+	path := writeForked(t)
+	msgs, offsets, end, err := parser.ReadSessionIncrementalOffsets(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newSessionWatcher(parser.FileSource(path), msgs, end)
+	w.lineOffsets = offsets
+
+	w.leaf = "a2"
+	w.readAndRebuild()
+	u := <-w.sub
+	if got := strings.Join(userPrompts(u.messages), ", "); got != "set up, try A" {
+		t.Errorf("prompts = %q, want the abandoned branch", got)
+	}
+	if len(u.branches) != 2 || !u.branches[0].Current {
+		t.Errorf("branches = %+v, want the first shown", u.branches)
+	}
+}
+
+func TestUpdateBranches_PickLatestFollowsSession(t *testing.T) {
+	m := testModel()
+	m.watcher = newSessionWatcher(parser.FileSource("/nonexistent.jsonl"), nil, 0)
+	m.branches = []parser.Branch{
+		{Leaf: "a2", Prompt: "try A"},
+		{Leaf: "a3", Prompt: "try B", Latest: true},
+	}
+	m.openBranches()
+	if m.view != viewBranches {
+		t.Fatalf("view = %d, want branches", m.view)
+	}
+	result, _ := m.Update(key("G"))
+	result, _ = asModel(result).Update(key("enter"))
+	m = asModel(result)
+	if m.view != viewList {
+		t.Errorf("view = %d, want list after picking", m.view)
+	}
+	if leaf := <-m.watcher.branch; leaf != "" {
+		t.Errorf("requested leaf %q, want \"\" to keep following the newest", leaf)
+	}
+
+	m.branches = nil
+	m.openBranches()
+	if m.view == viewBranches || m.flashStatus == "" {
+		t.Error("a session without branches should flash a notice, not open the list")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// Change detection modes, named as they appear in the changeDetection config.
+// Network and synced drives (NFS, SMB, Dropbox, iCloud) cache file attributes
+// and rarely deliver fsnotify events, so the size poll can miss writes.
+const (
+	detectStat    = "stat"    // fsnotify plus a poll comparing the file size with the read offset (default)
+	detectContent = "content" // the poll reads the file: bytes past the offset, and a hash of the tail already read
+)
+
+var changeDetectionModes = []string{detectStat, detectContent}
+
+// tailHashBytes is how much of the data already read, ending at the read
+// offset, the content mode hashes to notice the file being rewritten.
+const tailHashBytes = 4096
+
+// minRereadInterval is the shortest forced re-read period accepted from
+// config: each one reads the whole session.
+const minRereadInterval = 5 * time.Second
+
+// validateChangeDetection reports an unknown mode. Empty keeps the default.
+func validateChangeDetection(mode string) error {
+	if mode == "" || slices.Contains(changeDetectionModes, mode) {
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q (want one of %s)", mode, strings.Join(changeDetectionModes, ", "))
+}
+
+// parseRereadInterval parses a forced re-read period such as "1m".
+// Periods below minRereadInterval are rejected.
+func parseRereadInterval(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < minRereadInterval {
+		return 0, fmt.Errorf("re-read interval %s is below the %s minimum", d, minRereadInterval)
+	}
+	return d, nil
+}
+
+// tailHash hashes the tailHashBytes of path before offset. ok is false when
+// the file can't be read that far: it was truncated or replaced by a
+// shorter one.
+func tailHash(path string, offset int64) (sum [sha256.Size]byte, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, false
+	}
+	defer f.Close()
+	start := max(offset-tailHashBytes, 0)
+	buf := make([]byte, offset-start)
+	if _, err := f.ReadAt(buf, start); err != nil {
+		return sum, false
+	}
+	return sha256.Sum256(buf), true
+}
+
+// hasBytesAt reports whether path has data at offset. Reading, unlike a
+// stat, goes past attribute caches: NFS revalidates a file on open.
+func hasBytesAt(path string, offset int64) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	var b [1]byte
+	n, err := f.ReadAt(b[:], offset)
+	return n > 0 && (err == nil || err == io.EOF)
+}
+
+// noteTail records the hash of the data read so far, for pollContent. Only
+// called from run().
+func (w *sessionWatcher) noteTail() {
+	if w.detect == detectContent && w.local {
+		w.tailSum, _ = tailHash(w.path, w.offset)
+	}
+}
+
+// pollContent is poll for the content mode. A changed tail means the file
+// was rewritten under the watcher, so everything is read again; otherwise
+// any byte past the offset is new data.
+func (w *sessionWatcher) pollContent() {
+	if sum, ok := tailHash(w.path, w.offset); !ok || sum != w.tailSum {
+		w.log.Info("session rewritten, reading it whole", "offset", w.offset)
+		w.reread()
+		return
+	}
+	if hasBytesAt(w.path, w.offset) {
+		w.readAndRebuild()
+	}
+}
+
+// reread replaces everything read so far with a fresh read of the whole
+// file, for changes the other checks missed: a sync tool rewriting it, or
+// cached attributes hiding a write. A session opened reduced re-reads just
+// its last turns. Only called from run().
+func (w *sessionWatcher) reread() {
+	w.lastReread = time.Now()
+	var msgs []parser.ClassifiedMsg
+	var offsets []int64
+	var start, offset int64
+	var err error
+	if w.partial {
+		msgs, offsets, start, offset, err = parser.ReadSourceTail(w.src, w.window)
+	} else {
+		msgs, offsets, offset, err = parser.ReadSourceIncrementalOffsets(w.src, 0)
+	}
+	if err != nil {
+		w.reportErr(fmt.Errorf("re-reading %s: %w", filepath.Base(w.path), err))
+		return
+	}
+	if offset != w.offset {
+		w.lastActivity = w.lastReread
+	}
+	w.allClassified, w.lineOffsets, w.offset = msgs, offsets, offset
+	w.windowStart, w.evictedTurns, w.partial = 0, 0, false
+	w.lineage = parser.NewLineage()
+	w.startPartial(start)
+	w.evict()
+	w.tokens = lastUsageTokens(msgs)
+	w.noteTail()
+	w.readAndRebuild()
+}
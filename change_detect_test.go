@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestValidateChangeDetection(t *testing.T) {
+	for _, mode := range []string{"", detectStat, detectContent} {
+		if err := validateChangeDetection(mode); err != nil {
+			t.Errorf("validateChangeDetection(%q) = %v", mode, err)
+		}
+	}
+	if err := validateChangeDetection("inotify"); err == nil {
+		t.Error("unknown mode should be rejected")
+	}
+	if _, err := parseRereadInterval("1s"); err == nil {
+		t.Error("re-read interval below the minimum should be rejected")
+	}
+	if d, err := parseRereadInterval("1m"); err != nil || d.Minutes() != 1 {
+		t.Errorf("parseRereadInterval(1m) = %s, %v", d, err)
+	}
+}
+
+func TestPollContent(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("parser", "testdata", "multi_turn.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	write := func(b []byte) {
+		t.Helper()
+		if err := os.WriteFile(path, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updated := func(w *sessionWatcher) bool {
+		select {
+		case <-w.sub:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// Start with the first half of the session.
+	half := len(data) / 2
+	for data[half-1] != '\n' {
+		half--
+	}
+	write(data[:half])
+	w := newSessionWatcher(parser.FileSource(path), nil, 0)
+	w.detect = detectContent
+	w.reread()
+	updated(w)
+	if w.offset != int64(half) {
+		t.Fatalf("offset = %d, want %d", w.offset, half)
+	}
+
+	w.pollContent()
+	if updated(w) {
+		t.Error("unchanged file should not rebuild")
+	}
+
+	write(data)
+	w.pollContent()
+	if !updated(w) || w.offset != int64(len(data)) {
+		t.Errorf("appended data: offset = %d, want %d", w.offset, len(data))
+	}
+	read := len(w.allClassified)
+
+	// A sync tool replaces the file with a shorter version.
+	write(data[:half])
+	w.pollContent()
+	if !updated(w) || w.offset != int64(half) || len(w.allClassified) >= read {
+		t.Errorf("rewritten file: offset = %d, %d messages; want a fresh read of %d bytes",
+			w.offset, len(w.allClassified), half)
+	}
+}
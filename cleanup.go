@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// cleanupAge is how long a session must sit untouched before the cleanup
+// view counts it as reclaimable.
+const cleanupAge = 30 * 24 * time.Hour
+
+// archiveDirName is the subdirectory of a project directory that archived
+// sessions move into. "includeSubdirs" in the config lists them again.
+const archiveDirName = "archive"
+
+// Cleanup actions, as the confirmation prompt names them.
+const (
+	cleanupArchive = "archive"
+	cleanupDelete  = "delete"
+)
+
+// cleanupEntry is one session in the cleanup view.
+type cleanupEntry struct {
+	session parser.SessionInfo
+	bytes   int64 // the .jsonl and the session's directory of subagent traces and tool output
+}
+
+// reclaimable reports whether the entry is old and idle enough to suggest
+// removing.
+func (e cleanupEntry) reclaimable(now time.Time) bool {
+	return !e.session.IsOngoing && now.Sub(e.session.ModTime) > cleanupAge
+}
+
+// cleanupScannedMsg delivers the project's sessions with their disk usage.
+type cleanupScannedMsg struct {
+	entries []cleanupEntry
+}
+
+// cleanupDoneMsg reports an archive or delete run: the sessions it
+// finished, and the error that stopped it, if any.
+type cleanupDoneMsg struct {
+	action string
+	done   []string // session paths
+	bytes  int64
+	err    error
+}
+
+// sessionDataDir returns the directory holding a session's subagent traces
+// and tool output, named after the session file beside it.
+func sessionDataDir(path string) string {
+	return strings.TrimSuffix(path, ".jsonl")
+}
+
+// sessionDiskUsage returns the bytes a session takes: its file and its data
+// directory. Files that vanish mid-walk are skipped.
+func sessionDiskUsage(path string) int64 {
+	var total int64
+	if info, err := os.Stat(path); err == nil {
+		total = info.Size()
+	}
+	_ = filepath.WalkDir(sessionDataDir(path), func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// scanCleanupCmd lists the sessions of projectDirs, ignoring the age cutoff
+// since the oldest sessions are the ones worth cleaning, and measures each
+// off the UI goroutine. A scan cut short by ctx reports nothing: the view it
+// was for is gone.
+func scanCleanupCmd(ctx context.Context, projectDirs []string, cache *parser.SessionCache) tea.Cmd {
+	return func() tea.Msg {
+		scope := parser.Discovery
+		scope.MaxAge = 0
+		var sessions []parser.SessionInfo
+		if cache != nil {
+			sessions, _ = cache.DiscoverAllProjectSessionsIn(ctx, scope, projectDirs)
+		} else {
+			sessions, _ = parser.DiscoverAllProjectSessionsIn(ctx, scope, projectDirs)
+		}
+		entries := make([]cleanupEntry, len(sessions))
+		err := parser.ForEachParallel(ctx, len(sessions), parser.ScanWorkers, func(i int) {
+			entries[i] = cleanupEntry{session: sessions[i], bytes: sessionDiskUsage(sessions[i].Path)}
+		})
+		if err != nil {
+			return nil
+		}
+		return cleanupScannedMsg{entries: entries}
+	}
+}
+
+// archiveSession moves a session file and its data directory into the
+// archive subdirectory beside it. A session already there stays put. When
+// the data directory can't be moved the file is moved back, so the two are
+// never split between the folders.
+func archiveSession(path string) error {
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == archiveDirName {
+		return nil
+	}
+	dest := filepath.Join(dir, archiveDirName)
+	if err := os.MkdirAll(dest, 0o700); err != nil {
+		return err
+	}
+	archived := filepath.Join(dest, filepath.Base(path))
+	if err := os.Rename(path, archived); err != nil {
+		return err
+	}
+	data := sessionDataDir(path)
+	if err := os.Rename(data, filepath.Join(dest, filepath.Base(data))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		if undo := os.Rename(archived, path); undo != nil {
+			return fmt.Errorf("%w (and the session file stays archived: %v)", err, undo)
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteSession removes a session file and its data directory.
+func deleteSession(path string) error {
+	if !strings.HasSuffix(path, ".jsonl") {
+		return fmt.Errorf("%s is not a session file", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return os.RemoveAll(sessionDataDir(path))
+}
+
+// cleanupCmd archives or deletes sessions off the UI goroutine, stopping at
+// the first failure.
+func cleanupCmd(action string, entries []cleanupEntry) tea.Cmd {
+	return func() tea.Msg {
+		apply := archiveSession
+		if action == cleanupDelete {
+			apply = deleteSession
+		}
+		msg := cleanupDoneMsg{action: action}
+		for _, e := range entries {
+			if err := apply(e.session.Path); err != nil {
+				msg.err = err
+				break
+			}
+			msg.done = append(msg.done, e.session.Path)
+			msg.bytes += e.bytes
+		}
+		return msg
+	}
+}
+
+// openCleanup switches to the cleanup view and starts measuring sessions.
+func (m *model) openCleanup() tea.Cmd {
+	m.cleanupEntries = nil
+	m.cleanupMarked = nil
+	m.cleanupLoading = true
+	m.cleanupCursor = 0
+	m.cleanupScroll = 0
+	m.cleanupConfirm = ""
+	m.view = viewCleanup
+	return scanCleanupCmd(m.viewContext(), m.projectDirs, m.sessionCache)
+}
+
+// sortCleanup orders the entries oldest first, or largest first when
+// cleanupBySize is set.
+func (m *model) sortCleanup() {
+	sort.SliceStable(m.cleanupEntries, func(i, j int) bool {
+		a, b := m.cleanupEntries[i], m.cleanupEntries[j]
+		if m.cleanupBySize && a.bytes != b.bytes {
+			return a.bytes > b.bytes
+		}
+		return a.session.ModTime.Before(b.session.ModTime)
+	})
+}
+
+// cleanupKept reports why a session can't be cleaned up, or "" when it can:
+// sessions still running or open in this instance are left alone.
+func (m model) cleanupKept(e cleanupEntry) string {
+	switch {
+	case e.session.IsOngoing:
+		return "running"
+	case e.session.Path == m.sessionPath:
+		return "open"
+	case m.altSession != nil && e.session.Path == m.altSession.path:
+		return "open"
+	}
+	return ""
+}
+
+// cleanupTargets returns the marked sessions, or the one under the cursor
+// when none are marked, leaving out those cleanupKept protects.
+func (m model) cleanupTargets() []cleanupEntry {
+	var targets []cleanupEntry
+	for i, e := range m.cleanupEntries {
+		if (len(m.cleanupMarked) == 0 && i == m.cleanupCursor) || m.cleanupMarked[e.session.Path] {
+			if m.cleanupKept(e) == "" {
+				targets = append(targets, e)
+			}
+		}
+	}
+	return targets
+}
+
+// cleanupTotal sums the entries' bytes.
+func cleanupTotal(entries []cleanupEntry) int64 {
+	var total int64
+	for _, e := range entries {
+		total += e.bytes
+	}
+	return total
+}
+
+// updateCleanup handles key events in the cleanup view. Archive and delete
+// ask first; y confirms and any other key cancels.
+func (m model) updateCleanup(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if action := m.cleanupConfirm; action != "" {
+		m.cleanupConfirm = ""
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if msg.String() != "y" {
+			return m, nil
+		}
+		targets := m.cleanupTargets()
+		m.flashStatus = fmt.Sprintf("%s %s...", cleanupVerb(action, false), pluralCount(len(targets), "session"))
+		return m, cleanupCmd(action, targets)
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "C":
+		m.view = viewPicker
+	case "j", "down":
+		if m.cleanupCursor < len(m.cleanupEntries)-1 {
+			m.cleanupCursor++
+		}
+		m.ensureCleanupVisible()
+	case "k", "up":
+		if m.cleanupCursor > 0 {
+			m.cleanupCursor--
+		}
+		m.ensureCleanupVisible()
+	case "G":
+		m.cleanupCursor = max(len(m.cleanupEntries)-1, 0)
+		m.ensureCleanupVisible()
+	case "g":
+		m.cleanupCursor = 0
+		m.cleanupScroll = 0
+	case "space":
+		if m.cleanupCursor < len(m.cleanupEntries) {
+			if m.cleanupMarked == nil {
+				m.cleanupMarked = make(map[string]bool)
+			}
+			path := m.cleanupEntries[m.cleanupCursor].session.Path
+			if m.cleanupMarked[path] {
+				delete(m.cleanupMarked, path)
+			} else {
+				m.cleanupMarked[path] = true
+			}
+			if m.cleanupCursor < len(m.cleanupEntries)-1 {
+				m.cleanupCursor++
+			}
+			m.ensureCleanupVisible()
+		}
+	case "o":
+		// Mark every reclaimable session, the advisor's suggestion.
+		m.cleanupMarked = make(map[string]bool)
+		now := time.Now()
+		for _, e := range m.cleanupEntries {
+			if e.reclaimable(now) && m.cleanupKept(e) == "" {
+				m.cleanupMarked[e.session.Path] = true
+			}
+		}
+	case "s":
+		m.cleanupBySize = !m.cleanupBySize
+		m.sortCleanup()
+		m.cleanupCursor = 0
+		m.cleanupScroll = 0
+	case "a", "D":
+		action := cleanupArchive
+		if msg.String() == "D" {
+			action = cleanupDelete
+		}
+		if m.readOnly {
+			m.flashStatus = "Read-only: cleanup is off"
+			return m, flashClearCmd()
+		}
+		if len(m.cleanupTargets()) == 0 {
+			m.flashStatus = "Nothing to " + action + ": running and open sessions are kept"
+			return m, flashClearCmd()
+		}
+		m.cleanupConfirm = action
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// cleanupVerb names an action for status lines: "Archiving" while it runs,
+// "Archived" once done.
+func cleanupVerb(action string, done bool) string {
+	switch {
+	case action == cleanupDelete && done:
+		return "Deleted"
+	case action == cleanupDelete:
+		return "Deleting"
+	case done:
+		return "Archived"
+	default:
+		return "Archiving"
+	}
+}
+
+// applyCleanupDone drops the finished sessions from the view and reports
+// the run.
+func (m *model) applyCleanupDone(msg cleanupDoneMsg) {
+	done := make(map[string]bool, len(msg.done))
+	for _, path := range msg.done {
+		done[path] = true
+		delete(m.cleanupMarked, path)
+	}
+	kept := m.cleanupEntries[:0]
+	for _, e := range m.cleanupEntries {
+		if !done[e.session.Path] {
+			kept = append(kept, e)
+		}
+	}
+	m.cleanupEntries = kept
+	m.cleanupCursor = min(m.cleanupCursor, max(len(kept)-1, 0))
+	m.ensureCleanupVisible()
+
+	status := fmt.Sprintf("%s %s (%s)", cleanupVerb(msg.action, true), pluralCount(len(msg.done), "session"), formatBytes(float64(msg.bytes)))
+	if msg.action == cleanupArchive {
+		status += " into " + archiveDirName + "/"
+	}
+	if msg.err != nil {
+		status += fmt.Sprintf("; stopped: %v", msg.err)
+	}
+	m.flashStatus = status
+}
+
+// cleanupViewHeight returns the visible rows (minus header, summary, and
+// footer).
+func (m model) cleanupViewHeight() int {
+	return m.panelHeight(4)
+}
+
+// ensureCleanupVisible adjusts cleanupScroll so the cursor row is visible.
+func (m *model) ensureCleanupVisible() {
+	m.cleanupScroll = scrollToShow(m.cleanupScroll, m.cleanupCursor, 1, m.cleanupViewHeight())
+}
+
+// cleanupRow renders one session: its size, when it was last written, its
+// first prompt, and why it is kept when it can't be removed.
+func (m model) cleanupRow(e cleanupEntry, selected bool, now time.Time, width int) string {
+	sel := selectionIndicator(selected)
+	mark := "  "
+	if m.cleanupMarked[e.session.Path] {
+		mark = Icon.Task.Done.Render() + " "
+	}
+	size := fmt.Sprintf("%9s ", formatBytes(float64(e.bytes)))
+	ageStyle := StyleDim
+	if e.reclaimable(now) {
+		ageStyle = StyleWarningBold
+	}
+	age := ageStyle.Render(fmt.Sprintf("%-9s", relativeTime(e.session.ModTime)))
+	tag := ""
+	if kept := m.cleanupKept(e); kept != "" {
+		tag = "  " + StyleDim.Render(kept)
+	} else if filepath.Base(filepath.Dir(e.session.Path)) == archiveDirName {
+		tag = "  " + StyleDim.Render("archived")
+	}
+	style := StyleSecondary
+	if selected {
+		style = StylePrimaryBold
+	}
+	prompt := e.session.FirstMessage
+	if prompt == "" {
+		prompt = "Untitled"
+	}
+	room := max(width-lipgloss.Width(sel)-lipgloss.Width(mark)-len(size)-lipgloss.Width(age)-1-lipgloss.Width(tag), 10)
+	return sel + mark + StyleAccentBold.Render(size) + age + " " + style.Render(parser.Truncate(prompt, room)) + tag
+}
+
+// viewCleanupList renders the cleanup advisor: every session of the project
+// with its disk usage, and how much the old ones would free.
+func (m model) viewCleanupList() string {
+	width := m.clampWidth()
+	now := time.Now()
+
+	header := StyleAccentBold.Render("Cleanup")
+	var body string
+	switch {
+	case m.cleanupLoading:
+		body = StyleDim.Render("Measuring sessions" + Icon.Ellipsis.Glyph)
+	case len(m.cleanupEntries) == 0:
+		body = StyleDim.Render("No sessions found for this project.")
+	default:
+		var old []cleanupEntry
+		for _, e := range m.cleanupEntries {
+			if e.reclaimable(now) {
+				old = append(old, e)
+			}
+		}
+		header += " " + StyleDim.Render(fmt.Sprintf("%s, %s", pluralCount(len(m.cleanupEntries), "session"), formatBytes(float64(cleanupTotal(m.cleanupEntries)))))
+		summary := fmt.Sprintf("%s idle over %s: %s reclaimable", pluralCount(len(old), "session"), formatMaxAge(cleanupAge), formatBytes(float64(cleanupTotal(old))))
+		if n := len(m.cleanupMarked); n > 0 {
+			var marked []cleanupEntry
+			for _, e := range m.cleanupEntries {
+				if m.cleanupMarked[e.session.Path] {
+					marked = append(marked, e)
+				}
+			}
+			summary += fmt.Sprintf(" · %d marked (%s)", n, formatBytes(float64(cleanupTotal(marked))))
+		}
+		if action := m.cleanupConfirm; action != "" {
+			targets := m.cleanupTargets()
+			question := fmt.Sprintf("%s %s (%s) and their subagent files? y/n",
+				strings.ToUpper(action[:1])+action[1:], pluralCount(len(targets), "session"), formatBytes(float64(cleanupTotal(targets))))
+			if action == cleanupArchive {
+				question = fmt.Sprintf("Move %s (%s) into %s/? y/n", pluralCount(len(targets), "session"), formatBytes(float64(cleanupTotal(targets))), archiveDirName)
+			}
+			summary = StyleErrorBold.Render(question)
+		} else {
+			summary = StyleMuted.Render(summary)
+		}
+		var lines []string
+		for i, e := range m.cleanupEntries {
+			lines = append(lines, m.cleanupRow(e, i == m.cleanupCursor, now, width))
+		}
+		body = summary + "\n" + strings.Join(scrollWindow(lines, m.cleanupViewHeight(), m.cleanupScroll), "\n")
+	}
+	content := centerBlock(header+"\n\n"+body, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	sortLabel := "by size"
+	if m.cleanupBySize {
+		sortLabel = "by age"
+	}
+	footer := m.renderFooter(
+		"j/k", "nav",
+		"space", "mark",
+		"o", "mark old",
+		"s", sortLabel,
+		"a", "archive",
+		"D", "delete",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
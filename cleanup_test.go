@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// writeCleanupSession writes a session file of size bytes with a data
+// directory holding one subagent trace of size bytes, and returns its path.
+func writeCleanupSession(t *testing.T, dir, id string, size int) string {
+	t.Helper()
+	path := filepath.Join(dir, id+".jsonl")
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	subagents := filepath.Join(dir, id, "subagents")
+	if err := os.MkdirAll(subagents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(subagents, "agent-a.jsonl"), make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSessionDiskUsage(t *testing.T) {
+	path := writeCleanupSession(t, t.TempDir(), "s1", 100)
+	if got := sessionDiskUsage(path); got != 200 {
+		t.Errorf("sessionDiskUsage = %d, want 200 (file and subagent trace)", got)
+	}
+}
+
+func TestArchiveAndDeleteSession(t *testing.T) {
+	dir := t.TempDir()
+	path := writeCleanupSession(t, dir, "s1", 10)
+	if err := archiveSession(path); err != nil {
+		t.Fatal(err)
+	}
+	archived := filepath.Join(dir, archiveDirName, "s1.jsonl")
+	for _, p := range []string{archived, filepath.Join(dir, archiveDirName, "s1", "subagents", "agent-a.jsonl")} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("after archive, %s missing: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("after archive, %s still in place", path)
+	}
+	// Archiving an archived session leaves it where it is.
+	if err := archiveSession(archived); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deleteSession(archived); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, archiveDirName))
+	if len(entries) != 0 {
+		t.Errorf("after delete, archive holds %d entries, want none", len(entries))
+	}
+	if err := deleteSession(filepath.Join(dir, "notes.md")); err == nil {
+		t.Error("deleteSession should refuse a file that isn't a session")
+	}
+
+	// A data directory that can't move (the archive already holds a
+	// non-empty one by that name) takes the file back out of the archive.
+	path = writeCleanupSession(t, dir, "s2", 10)
+	writeCleanupSession(t, filepath.Join(dir, archiveDirName), "s2", 10)
+	os.Remove(filepath.Join(dir, archiveDirName, "s2.jsonl"))
+	if err := archiveSession(path); err == nil {
+		t.Fatal("archiveSession should fail when the data directory can't move")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("after a failed archive, %s should be back: %v", path, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, archiveDirName, "s2.jsonl")); !os.IsNotExist(err) {
+		t.Error("after a failed archive, the session file should not stay in the archive")
+	}
+}
+
+func TestCleanupView(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := writeCleanupSession(t, dir, "old", 1000)
+	open := writeCleanupSession(t, dir, "open", 10)
+
+	m := testModel()
+	m.sessionPath = open
+	m.view = viewCleanup
+	m.cleanupEntries = []cleanupEntry{
+		{session: parser.SessionInfo{Path: open, ModTime: now.Add(-60 * 24 * time.Hour), FirstMessage: "Still open"}, bytes: 20},
+		{session: parser.SessionInfo{Path: "/x/running.jsonl", ModTime: now.Add(-time.Minute), IsOngoing: true}, bytes: 5},
+		{session: parser.SessionInfo{Path: old, ModTime: now.Add(-45 * 24 * time.Hour), FirstMessage: "Old work"}, bytes: 2000},
+	}
+
+	out := m.viewCleanupList()
+	for _, want := range []string{"3 sessions", "2 sessions idle over 30d", "Old work", "running", "open"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("cleanup view missing %q:\n%s", want, out)
+		}
+	}
+
+	// o marks the old sessions that aren't protected: the open one stays.
+	result, _ := m.updateCleanup(key("o"))
+	m = asModel(result)
+	if len(m.cleanupMarked) != 1 || !m.cleanupMarked[old] {
+		t.Fatalf("marked = %v, want only %s", m.cleanupMarked, old)
+	}
+
+	// D asks first; any key but y cancels.
+	result, _ = m.updateCleanup(key("D"))
+	m = asModel(result)
+	if m.cleanupConfirm != cleanupDelete || !strings.Contains(m.viewCleanupList(), "Delete 1 session") {
+		t.Fatalf("D: confirm = %q, want delete prompt", m.cleanupConfirm)
+	}
+	result, cmd := m.updateCleanup(key("n"))
+	m = asModel(result)
+	if m.cleanupConfirm != "" || cmd != nil {
+		t.Fatalf("n should cancel: confirm = %q, cmd = %v", m.cleanupConfirm, cmd)
+	}
+
+	result, _ = m.updateCleanup(key("D"))
+	m = asModel(result)
+	result, cmd = m.updateCleanup(key("y"))
+	m = asModel(result)
+	if cmd == nil {
+		t.Fatal("y should start the delete")
+	}
+	done := cmd().(cleanupDoneMsg)
+	if done.err != nil || len(done.done) != 1 || done.bytes != 2000 {
+		t.Fatalf("done = %+v, want 1 session, 2000 bytes", done)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("old session file still on disk")
+	}
+	if _, err := os.Stat(open); err != nil {
+		t.Error("open session was removed")
+	}
+	result, _ = m.Update(done)
+	m = asModel(result)
+	if len(m.cleanupEntries) != 2 || len(m.cleanupMarked) != 0 || !strings.Contains(m.flashStatus, "Deleted 1 session") {
+		t.Errorf("after delete: %d entries, %d marked, status %q", len(m.cleanupEntries), len(m.cleanupMarked), m.flashStatus)
+	}
+}
+
+func TestCleanupView_ReadOnly(t *testing.T) {
+	m := testModel()
+	m.view = viewCleanup
+	m.readOnly = true
+	m.cleanupEntries = []cleanupEntry{{session: parser.SessionInfo{Path: "/x/a.jsonl"}}}
+	result, _ := m.updateCleanup(key("a"))
+	m = asModel(result)
+	if m.cleanupConfirm != "" || !strings.Contains(m.flashStatus, "Read-only") {
+		t.Errorf("read-only archive: confirm = %q, status = %q", m.cleanupConfirm, m.flashStatus)
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// config holds user preferences persisted across runs.
+// Stored as JSON at $XDG_CONFIG_HOME/tail-claude/config.json (or the
+// platform equivalent from os.UserConfigDir).
+type config struct {
+	HiddenTools  []string `json:"hiddenTools,omitempty"`  // tool names hidden from item lists and counts
+	HiddenSystem []string `json:"hiddenSystem,omitempty"` // system message kinds left out of the list: "status", "outputStyle", "queue"
+	PollInterval string   `json:"pollInterval,omitempty"` // watcher base poll interval, e.g. "2s"
+	WindowTurns  int      `json:"windowTurns,omitempty"`  // turns kept in memory while tailing; 0 keeps all
+	Follow       bool     `json:"follow,omitempty"`       // start on the newest message with the latest Claude turn expanded
+	TurnGroups   bool     `json:"turnGroups,omitempty"`   // start with the list grouped by turn (#)
+	NowMarker    string   `json:"nowMarker,omitempty"`    // divider above messages written this recently while tailing, e.g. "30s" (default); "0" turns it off
+	Locale       string   `json:"locale,omitempty"`       // number formatting, e.g. "de_DE"; empty follows LC_ALL, LC_NUMERIC, LANG
+	ExactTokens  bool     `json:"exactTokens,omitempty"`  // show token counts whole ("100,321") instead of abbreviated ("100.3k"); + toggles
+
+	// Sessions too big to parse whole up front; see large_session.go.
+	LargeSession string `json:"largeSession,omitempty"` // size past which opening a session asks to read only its last turns, e.g. "100MB" (default); "0" never asks
+	ReducedTurns int    `json:"reducedTurns,omitempty"` // turns a large session opened reduced starts with; 0 means 50
+
+	// For ~/.claude on a network or synced drive; see change_detect.go.
+	ChangeDetection string `json:"changeDetection,omitempty"` // "stat" (default) or "content"
+	RereadInterval  string `json:"rereadInterval,omitempty"`  // read the whole session this often, e.g. "1m"; empty disables
+
+	// Where the picker finds sessions beyond the project's directory.
+	SessionDirs    []string `json:"sessionDirs,omitempty"`    // more directories to list sessions from; a leading "~/" is the home directory
+	IncludeSubdirs bool     `json:"includeSubdirs,omitempty"` // also list sessions in subdirectories, e.g. an archive/
+	MaxAge         string   `json:"maxAge,omitempty"`         // hide sessions last written longer ago, e.g. "30d"; empty keeps all
+
+	Workspaces []workspaceConfig `json:"workspaces,omitempty"` // repos whose sessions the picker and digest list together
+
+	Webhook     *webhookConfig     `json:"webhook,omitempty"`     // HTTP events for the tailed session; nil disables
+	Summarizer  *summarizerConfig  `json:"summarizer,omitempty"`  // command S pipes long results and thinking through; nil disables
+	TurnSummary *turnSummaryConfig `json:"turnSummary,omitempty"` // Claude CLI call E summarizes a turn with; {} uses claude -p, nil disables
+
+	DetailFocus string `json:"detailFocus,omitempty"` // where the detail view's cursor starts: "cursor" (default), "expand", or "top"
+	KeepBase64  bool   `json:"keepBase64,omitempty"`  // show base64 blobs in expanded tool results instead of "…[37.5 KB base64 omitted]"
+
+	TokenCounter string `json:"tokenCounter,omitempty"` // command that prints the token count of text on stdin, for range selections; empty estimates
+
+	Ongoing *ongoingConfig `json:"ongoing,omitempty"` // tunes live-session detection; nil keeps the built-in heuristics
+
+	InfoBar  *infoBarLayout `json:"infoBar,omitempty"` // info bar elements and order; nil keeps the default
+	Collapse collapseConfig `json:"collapse,omitzero"` // preview limits of collapsed content, per view
+	Columns  columnsConfig  `json:"columns,omitzero"`  // column widths of detail item rows
+}
+
+// Default preview limits for collapsed content.
+const (
+	defaultCollapsedLines      = 12 // list: content lines of a collapsed message
+	defaultPreviewChars        = 80 // list: collapsed tool result preview
+	defaultTextSummaryChars    = 40 // detail: thinking and output row summaries
+	defaultMessageSummaryChars = 60 // detail: teammate message and hook row summaries
+)
+
+// collapseConfig sets how much preview content collapsed messages and item
+// rows show. Zero or negative values keep the defaults.
+type collapseConfig struct {
+	List   listCollapse   `json:"list,omitzero"`
+	Detail detailCollapse `json:"detail,omitzero"`
+}
+
+// listCollapse holds the list view's limits.
+type listCollapse struct {
+	Lines   int `json:"lines,omitempty"`   // content lines of a collapsed message
+	Preview int `json:"preview,omitempty"` // characters of a collapsed tool result preview
+}
+
+// detailCollapse holds the detail view's item row limits. Expanded list
+// messages show the same rows.
+type detailCollapse struct {
+	Text    int `json:"text,omitempty"`    // characters of thinking and output summaries
+	Message int `json:"message,omitempty"` // characters of teammate message and hook summaries
+}
+
+// withDefaults fills unset limits with their defaults.
+func (c collapseConfig) withDefaults() collapseConfig {
+	orDefault := func(n, def int) int {
+		if n <= 0 {
+			return def
+		}
+		return n
+	}
+	c.List.Lines = orDefault(c.List.Lines, defaultCollapsedLines)
+	c.List.Preview = orDefault(c.List.Preview, defaultPreviewChars)
+	c.Detail.Text = orDefault(c.Detail.Text, defaultTextSummaryChars)
+	c.Detail.Message = orDefault(c.Detail.Message, defaultMessageSummaryChars)
+	return c
+}
+
+// Default column widths of detail item rows.
+const (
+	defaultNameColumn     = 12 // tool or agent name; widens to the longest shown
+	defaultMaxNameColumn  = 32 // widest the name column grows before names are cut
+	defaultTokensColumn   = 9  // "~9.9k tok"
+	defaultDurationColumn = 5  // "999ms", "1m 5s"
+)
+
+// columnsConfig sets the column widths of detail item rows (and expanded
+// list messages). Each column is at least its width and widens to fit the
+// longest value among the rows shown together, the name column up to
+// maxName, so rows stay aligned. Zero or negative values keep the defaults.
+type columnsConfig struct {
+	Name     int `json:"name,omitempty"`     // minimum name width
+	MaxName  int `json:"maxName,omitempty"`  // longer names are cut
+	Tokens   int `json:"tokens,omitempty"`   // minimum token count width
+	Duration int `json:"duration,omitempty"` // minimum duration width
+}
+
+// withDefaults fills unset widths with their defaults. maxName is never
+// below name.
+func (c columnsConfig) withDefaults() columnsConfig {
+	orDefault := func(n, def int) int {
+		if n <= 0 {
+			return def
+		}
+		return n
+	}
+	c.Name = orDefault(c.Name, defaultNameColumn)
+	c.MaxName = max(orDefault(c.MaxName, defaultMaxNameColumn), c.Name)
+	c.Tokens = orDefault(c.Tokens, defaultTokensColumn)
+	c.Duration = orDefault(c.Duration, defaultDurationColumn)
+	return c
+}
+
+// Info bar elements, named as they appear in the infoBar config.
+const (
+	infoProject = "project" // shortened cwd
+	infoBranch  = "branch"  // live git branch with dirty marker
+	infoWindow  = "window"  // evicted turns under --window
+	infoViewers = "viewers" // other tail-claude instances viewing the session
+	infoFollow  = "follow"  // whether the list follows new messages while tailing
+	infoMode    = "mode"    // permission mode (a chip for non-default modes)
+	infoGrowth  = "growth"  // session file and token growth per minute while tailing
+	infoContext = "ctx"     // context window usage percentage
+)
+
+// infoBarElements lists every element the info bar can show.
+var infoBarElements = []string{infoProject, infoBranch, infoWindow, infoViewers, infoMode, infoFollow, infoGrowth, infoContext}
+
+// infoBarLayout orders the info bar's elements. Left elements follow the
+// mode chip; right elements are right-aligned. An omitted side keeps its
+// default, an empty one shows nothing.
+type infoBarLayout struct {
+	Left  []string `json:"left"`
+	Right []string `json:"right"`
+}
+
+// defaultInfoBarLayout is the layout used when the config doesn't set one.
+var defaultInfoBarLayout = infoBarLayout{
+	Left:  []string{infoProject, infoBranch, infoWindow, infoViewers, infoMode},
+	Right: []string{infoFollow, infoGrowth, infoContext},
+}
+
+// validateInfoBar reports the first unknown or repeated element in l.
+func validateInfoBar(l *infoBarLayout) error {
+	if l == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, name := range append(append([]string{}, l.Left...), l.Right...) {
+		if !slices.Contains(infoBarElements, name) {
+			return fmt.Errorf("unknown element %q (want one of %s)", name, strings.Join(infoBarElements, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("element %q listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// infoBarLayout resolves the configured layout against the default. An
+// invalid layout is ignored (main warns about it at startup) but kept in the
+// config so saving other preferences doesn't drop it.
+func (c config) infoBarLayout() infoBarLayout {
+	l := defaultInfoBarLayout
+	if c.InfoBar != nil && validateInfoBar(c.InfoBar) == nil {
+		if c.InfoBar.Left != nil {
+			l.Left = c.InfoBar.Left
+		}
+		if c.InfoBar.Right != nil {
+			l.Right = c.InfoBar.Right
+		}
+	}
+	return l
+}
+
+// has reports whether the layout shows the named element on either side.
+func (l infoBarLayout) has(name string) bool {
+	return slices.Contains(l.Left, name) || slices.Contains(l.Right, name)
+}
+
+// configPath returns the config file location, or "" when the user config
+// directory can't be determined.
+func configPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "tail-claude", "config.json")
+}
+
+// loadConfig reads the config at path. A missing file yields the zero config;
+// a malformed one is reported so the caller can decide whether to continue.
+func loadConfig(path string) (config, error) {
+	var cfg config
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return config{}, err
+	}
+	return cfg, nil
+}
+
+// saveConfig writes cfg to path, creating the parent directory if needed.
+// The file is replaced atomically so another instance never reads it torn.
+func saveConfig(path string, cfg config) error {
+	if path == "" {
+		return errors.New("no config directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// writeFileAtomic replaces the file at path with data by writing a
+// temporary file beside it and renaming it into place, so a reader never
+// sees it half written. The temporary name is unique, so two instances
+// writing the same file can't clobber each other's.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// parsePollInterval parses a watcher poll interval such as "500ms" or "2s".
+// Intervals below minPollInterval are rejected.
+func parsePollInterval(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < minPollInterval {
+		return 0, fmt.Errorf("poll interval %s is below the %s minimum", d, minPollInterval)
+	}
+	return d, nil
+}
+
+// parseMaxAge parses a session age cutoff: a number of days ("30d") or a
+// duration ("36h"). Zero keeps every session.
+func parseMaxAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("unrecognized age %q (want 30d or 36h)", s)
+}
+
+// formatMaxAge writes an age cutoff the way parseMaxAge reads it: whole days
+// as "30d", anything else as a duration.
+func formatMaxAge(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d%day == 0 {
+		return strconv.Itoa(int(d/day)) + "d"
+	}
+	return d.String()
+}
+
+// ongoingConfig tunes how tail-claude decides a session is still running.
+// The debug view's w panel shows which rule decided it.
+type ongoingConfig struct {
+	Staleness          string   `json:"staleness,omitempty"`          // silence after which a session is dead, e.g. "5m"; empty uses 2m
+	EndingEvents       []string `json:"endingEvents,omitempty"`       // events that end Claude's turn; omitted uses them all
+	IgnorePendingCalls bool     `json:"ignorePendingCalls,omitempty"` // don't treat calls awaiting a result as work in progress
+}
+
+// rules converts the config to parser.OngoingRules.
+func (c *ongoingConfig) rules() (parser.OngoingRules, error) {
+	var r parser.OngoingRules
+	if c == nil {
+		return r, nil
+	}
+	if c.Staleness != "" {
+		d, err := time.ParseDuration(c.Staleness)
+		if err != nil || d <= 0 {
+			return r, fmt.Errorf("staleness %q is not a positive duration", c.Staleness)
+		}
+		r.Staleness = d
+	}
+	if c.EndingEvents != nil {
+		if err := parser.ValidateEndingEvents(c.EndingEvents); err != nil {
+			return r, err
+		}
+		r.Endings = make([]parser.EndingEvent, len(c.EndingEvents))
+		for i, e := range c.EndingEvents {
+			r.Endings[i] = parser.EndingEvent(e)
+		}
+	}
+	r.IgnorePending = c.IgnorePendingCalls
+	return r, nil
+}
+
+// sessionDirs returns SessionDirs with a leading "~/" expanded to the home
+// directory.
+func (c config) sessionDirs() []string {
+	dirs := make([]string, 0, len(c.SessionDirs))
+	for _, dir := range c.SessionDirs {
+		dirs = append(dirs, expandHome(dir))
+	}
+	return dirs
+}
+
+// expandHome expands a leading "~/" in path to the home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// hiddenToolSet returns HiddenTools as a lookup set.
+func (c config) hiddenToolSet() map[string]bool {
+	set := make(map[string]bool, len(c.HiddenTools))
+	for _, name := range c.HiddenTools {
+		set[name] = true
+	}
+	return set
+}
+
+// validateHiddenSystem reports the first name in kinds that isn't a system
+// message kind.
+func validateHiddenSystem(kinds []string) error {
+	for _, k := range kinds {
+		if !slices.Contains(parser.SystemKinds, parser.SystemKind(k)) {
+			names := make([]string, len(parser.SystemKinds))
+			for i, kind := range parser.SystemKinds {
+				names[i] = string(kind)
+			}
+			return fmt.Errorf("unknown kind %q (want one of %s)", k, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// hiddenSystemSet returns HiddenSystem as a lookup set.
+func (c config) hiddenSystemSet() map[parser.SystemKind]bool {
+	set := make(map[parser.SystemKind]bool, len(c.HiddenSystem))
+	for _, k := range c.HiddenSystem {
+		set[parser.SystemKind(k)] = true
+	}
+	return set
+}
+
+// withHiddenTools returns a copy of c with HiddenTools replaced by the sorted
+// keys of set that are true.
+func (c config) withHiddenTools(set map[string]bool) config {
+	var names []string
+	for name, hidden := range set {
+		if hidden {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	c.HiddenTools = names
+	return c
+}
+
+// applyConfig installs cfg on the model and re-derives the visible messages.
+// path is where toggles from the tool menu are saved.
+func (m *model) applyConfig(path string, cfg config) {
+	m.configPath = path
+	m.cfg = cfg
+	m.hiddenTools = cfg.hiddenToolSet()
+	m.hiddenSystem = cfg.hiddenSystemSet()
+	m.turnGroups = cfg.TurnGroups
+	m.setMessages(m.rawMessages)
+}
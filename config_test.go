@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("missing file yields zero config", func(t *testing.T) {
+		cfg, err := loadConfig(filepath.Join(t.TempDir(), "nope.json"))
+		if err != nil {
+			t.Fatalf("loadConfig error: %v", err)
+		}
+		if len(cfg.HiddenTools) != 0 {
+			t.Errorf("HiddenTools = %v, want empty", cfg.HiddenTools)
+		}
+	})
+
+	t.Run("malformed file is an error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		os.WriteFile(path, []byte("{not json"), 0o644)
+		if _, err := loadConfig(path); err == nil {
+			t.Error("expected error for malformed config")
+		}
+	})
+
+	t.Run("save then load round-trips", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "config.json")
+		want := config{}.withHiddenTools(map[string]bool{"TodoWrite": true, "Bash": false, "Glob": true})
+		if err := saveConfig(path, want); err != nil {
+			t.Fatalf("saveConfig error: %v", err)
+		}
+		got, err := loadConfig(path)
+		if err != nil {
+			t.Fatalf("loadConfig error: %v", err)
+		}
+		if len(got.HiddenTools) != 2 || got.HiddenTools[0] != "Glob" || got.HiddenTools[1] != "TodoWrite" {
+			t.Errorf("HiddenTools = %v, want [Glob TodoWrite]", got.HiddenTools)
+		}
+	})
+}
+
+func TestParsePollInterval(t *testing.T) {
+	if d, err := parsePollInterval("2s"); err != nil || d != 2*time.Second {
+		t.Errorf("parsePollInterval(2s) = %s, %v; want 2s", d, err)
+	}
+	for _, bad := range []string{"", "fast", "10ms"} {
+		if _, err := parsePollInterval(bad); err == nil {
+			t.Errorf("parsePollInterval(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseMaxAge(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"0":   0,
+	} {
+		if d, err := parseMaxAge(in); err != nil || d != want {
+			t.Errorf("parseMaxAge(%q) = %s, %v; want %s", in, d, err, want)
+		}
+		if d, _ := parseMaxAge(in); d > 0 {
+			if again, _ := parseMaxAge(formatMaxAge(d)); again != d {
+				t.Errorf("formatMaxAge(%s) = %q doesn't parse back", d, formatMaxAge(d))
+			}
+		}
+	}
+	for _, bad := range []string{"", "month", "-3d", "-1h"} {
+		if _, err := parseMaxAge(bad); err == nil {
+			t.Errorf("parseMaxAge(%q) should fail", bad)
+		}
+	}
+}
+
+func TestInfoBarLayout(t *testing.T) {
+	t.Run("unset keeps the default", func(t *testing.T) {
+		got := config{}.infoBarLayout()
+		if !slices.Equal(got.Left, defaultInfoBarLayout.Left) || !slices.Equal(got.Right, defaultInfoBarLayout.Right) {
+			t.Errorf("layout = %+v, want default", got)
+		}
+	})
+
+	t.Run("omitted side keeps its default, empty side hides", func(t *testing.T) {
+		var cfg config
+		if err := json.Unmarshal([]byte(`{"infoBar": {"left": ["branch", "project"]}}`), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		got := cfg.infoBarLayout()
+		if !slices.Equal(got.Left, []string{"branch", "project"}) || !slices.Equal(got.Right, defaultInfoBarLayout.Right) {
+			t.Errorf("layout = %+v, want custom left, default right", got)
+		}
+
+		cfg.InfoBar.Right = []string{}
+		if got := cfg.infoBarLayout(); len(got.Right) != 0 || got.has(infoContext) {
+			t.Errorf("layout = %+v, want empty right", got)
+		}
+	})
+
+	t.Run("invalid layout falls back to the default", func(t *testing.T) {
+		for _, l := range []infoBarLayout{
+			{Left: []string{"project", "cost"}},
+			{Left: []string{"ctx"}, Right: []string{"ctx"}},
+		} {
+			if err := validateInfoBar(&l); err == nil {
+				t.Errorf("validateInfoBar(%+v) = nil, want error", l)
+			}
+			got := config{InfoBar: &l}.infoBarLayout()
+			if !slices.Equal(got.Left, defaultInfoBarLayout.Left) {
+				t.Errorf("layout = %+v, want default", got)
+			}
+		}
+	})
+}
+
+func TestCollapseConfig(t *testing.T) {
+	var cfg config
+	if err := json.Unmarshal([]byte(`{"collapse": {"list": {"lines": 30}, "detail": {"text": -1, "message": 100}}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	got := cfg.Collapse.withDefaults()
+	want := collapseConfig{
+		List:   listCollapse{Lines: 30, Preview: defaultPreviewChars},
+		Detail: detailCollapse{Text: defaultTextSummaryChars, Message: 100},
+	}
+	if got != want {
+		t.Errorf("withDefaults = %+v, want %+v", got, want)
+	}
+
+	// Unset limits stay out of the saved file.
+	data, err := json.Marshal(config{HiddenTools: []string{"Bash"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "collapse") {
+		t.Errorf("saved config %s includes an unset collapse section", data)
+	}
+}
+
+func TestOngoingConfigRules(t *testing.T) {
+	r, err := (&ongoingConfig{Staleness: "5m", EndingEvents: []string{"text"}, IgnorePendingCalls: true}).rules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Threshold() != 5*time.Minute || len(r.Endings) != 1 || r.Endings[0] != parser.EndOnText || !r.IgnorePending {
+		t.Errorf("rules = %+v", r)
+	}
+	if r, _ := (*ongoingConfig)(nil).rules(); r.Threshold() != parser.OngoingStalenessThreshold || r.Endings != nil {
+		t.Errorf("nil config rules = %+v, want the defaults", r)
+	}
+	for _, c := range []ongoingConfig{{Staleness: "soon"}, {Staleness: "-1m"}, {EndingEvents: []string{"txt"}}} {
+		if _, err := c.rules(); err == nil {
+			t.Errorf("%+v: want an error", c)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/kylesnowschwartz/tail-claude/parser"
@@ -19,17 +20,44 @@ var teamColorPool = []string{
 // Discovered subagent processes are linked to their corresponding
 // ItemSubagent display items by matching ParentTaskID to ToolID.
 // colorByToolID provides fallback team colors for items without a linked process.
+//
+// When consecutive AI chunks report different models (/model, fallback), a
+// RoleModel divider is inserted before the AI message that switched.
+// Likewise a RoleMode divider marks each prompt sent in a different
+// permission mode than the one before, and a session that starts in a
+// non-default mode.
 func chunksToMessages(chunks []parser.Chunk, subagents []parser.SubagentProcess, colorByToolID map[string]string) []message {
 	msgs := make([]message, 0, len(chunks))
+	prevModel := ""
+	prevMode := ""
 	for _, c := range chunks {
 		switch c.Type {
 		case parser.UserChunk:
+			if mode := c.PermissionMode; mode != "" && mode != prevMode {
+				if prevMode != "" || mode != "default" {
+					msgs = append(msgs, modeChangeMessage(prevMode, mode, c.Timestamp))
+				}
+				prevMode = mode
+			}
 			msgs = append(msgs, message{
-				role:      RoleUser,
-				content:   c.UserText,
-				timestamp: formatTime(c.Timestamp),
+				role:           RoleUser,
+				content:        c.UserText,
+				timestamp:      formatTime(c.Timestamp),
+				startedAt:      c.Timestamp,
+				uuid:           c.UUID,
+				attachments:    c.Attachments,
+				permissionMode: c.PermissionMode,
+				branch:         c.Branch,
+				branches:       c.Branches,
 			})
 		case parser.AIChunk:
+			if c.Model != "" {
+				if prevModel != "" && c.Model != prevModel {
+					msgs = append(msgs, modelChangeMessage(prevModel, c.Model, c.Timestamp))
+				}
+				prevModel = c.Model
+			}
+
 			// Count distinct team-spawned subagents and teammate message senders.
 			var teamSpawns int
 			teammateIDs := make(map[string]bool)
@@ -49,32 +77,268 @@ func chunksToMessages(chunks []parser.Chunk, subagents []parser.SubagentProcess,
 				toolCallCount:    len(c.ToolCalls),
 				outputCount:      countOutputItems(c.Items),
 				tokensRaw:        c.Usage.TotalTokens(),
-				contextTokens:    c.Usage.InputTokens + c.Usage.CacheReadTokens + c.Usage.CacheCreationTokens,
+				contextTokens:    c.Usage.ContextTokens(),
+				contextDelta:     c.ContextDelta,
+				cacheRebuilt:     c.CacheRebuilt,
 				durationMs:       c.DurationMs,
 				timestamp:        formatTime(c.Timestamp),
+				startedAt:        c.Timestamp,
+				uuid:             c.UUID,
+				usage:            c.Usage,
 				items:            convertDisplayItems(c.Items, subagents, colorByToolID),
 				lastOutput:       parser.FindLastOutput(c.Items),
+				summary:          parser.SummarizeTurn(c),
 				teammateSpawns:   teamSpawns,
 				teammateMessages: len(teammateIDs),
+				settings:         c.Settings,
+				apiErrors:        c.Errors,
 			})
 		case parser.SystemChunk:
 			msgs = append(msgs, message{
-				role:      RoleSystem,
+				role:        RoleSystem,
+				content:     c.Output,
+				timestamp:   formatTime(c.Timestamp),
+				startedAt:   c.Timestamp,
+				isError:     c.IsError,
+				systemKind:  c.SystemKind,
+				systemLevel: c.SystemLevel,
+			})
+		case parser.CommandChunk:
+			cmd := c.Command
+			if c.CommandArgs != "" {
+				cmd += " " + c.CommandArgs
+			}
+			msgs = append(msgs, message{
+				role:      RoleCommand,
+				command:   cmd,
 				content:   c.Output,
 				timestamp: formatTime(c.Timestamp),
+				startedAt: c.Timestamp,
 				isError:   c.IsError,
 			})
-		case parser.CompactChunk:
+		case parser.ErrorChunk:
+			lines := make([]string, len(c.Errors))
+			for i, e := range c.Errors {
+				lines[i] = localRender.ErrorLine(e)
+			}
 			msgs = append(msgs, message{
-				role:      RoleCompact,
-				content:   c.Output,
+				role:      RoleError,
+				content:   strings.Join(lines, "\n"),
 				timestamp: formatTime(c.Timestamp),
+				startedAt: c.Timestamp,
+				isError:   c.Errors[len(c.Errors)-1].Final,
+				apiErrors: c.Errors,
+			})
+		case parser.CompactChunk:
+			msgs = append(msgs, message{
+				role:           RoleCompact,
+				content:        c.Output,
+				timestamp:      formatTime(c.Timestamp),
+				startedAt:      c.Timestamp,
+				compactTrigger: c.CompactTrigger,
+				compactPre:     c.PreTokens,
+				compactPost:    c.PostTokens,
+				compactSummary: c.CompactSummary,
 			})
 		}
 	}
+	markRetries(msgs)
+	markLoops(msgs)
+	linkSettings(msgs)
 	return msgs
 }
 
+// linkSettings points each Claude message at the settings of the Claude
+// message before it, so the detail view can show what changed between turns.
+func linkSettings(msgs []message) {
+	var prev *parser.RequestSettings
+	for i := range msgs {
+		if msgs[i].role != RoleClaude {
+			continue
+		}
+		msgs[i].prevSettings = prev
+		prev = &msgs[i].settings
+	}
+}
+
+// maxIdenticalCalls is how often a tool may be called with identical input
+// before the run is flagged as a possible loop.
+const maxIdenticalCalls = 3
+
+// markLoops flags Claude messages where the same tool call, with byte-identical
+// input, has been made more than maxIdenticalCalls times in a row of Claude
+// messages. Counts carry across consecutive messages (and the prompts between
+// them) while each message repeats the call, so an agent retrying the same
+// command turn after turn is caught as well as one stuck within a turn.
+func markLoops(msgs []message) {
+	var streak map[string]int // call key -> identical calls in the current run
+	for i := range msgs {
+		if msgs[i].role != RoleClaude {
+			continue
+		}
+		next := make(map[string]int)
+		for _, item := range msgs[i].items {
+			if item.itemType != parser.ItemToolCall {
+				continue
+			}
+			key := item.toolName + "\x00" + item.toolInput
+			if _, seen := next[key]; !seen {
+				next[key] = streak[key]
+			}
+			next[key]++
+			if next[key] > maxIdenticalCalls && next[key] > msgs[i].loopCount {
+				msgs[i].loopTool = item.toolName
+				msgs[i].loopCount = next[key]
+			}
+		}
+		streak = next
+	}
+}
+
+// maxRetryCompareRunes caps the prompt length compared by edit distance.
+// Longer prompts only count as retries when they normalize identically.
+const maxRetryCompareRunes = 500
+
+// markRetries groups consecutive user prompts that re-send the same request
+// after the previous attempt failed, numbering each "attempt N of M".
+// Prompts are compared after case and whitespace normalization, allowing a
+// few typo-sized edits.
+func markRetries(msgs []message) {
+	prev := -1  // index of the previous user prompt
+	start := -1 // index of the first prompt in the current retry group
+	attempts := 0
+	for i := range msgs {
+		if msgs[i].role != RoleUser {
+			continue
+		}
+		if prev >= 0 && turnFailed(msgs[prev+1:i]) && promptsSimilar(msgs[prev].content, msgs[i].content) {
+			if attempts == 0 {
+				start, attempts = prev, 1
+			}
+			attempts++
+		} else {
+			numberAttempts(msgs, start, i, attempts)
+			attempts = 0
+		}
+		prev = i
+	}
+	numberAttempts(msgs, start, len(msgs), attempts)
+}
+
+// numberAttempts stamps attempt/attempts on the user prompts in msgs[start:end].
+func numberAttempts(msgs []message, start, end, attempts int) {
+	if attempts < 2 {
+		return
+	}
+	n := 0
+	for i := start; i < end; i++ {
+		if msgs[i].role == RoleUser {
+			n++
+			msgs[i].attempt = n
+			msgs[i].attempts = attempts
+		}
+	}
+}
+
+// turnFailed reports whether the messages answering a prompt show it never
+// completed: no reply at all, an error, or a reply that stopped before its
+// final text output (interruptions drop the trailing answer).
+func turnFailed(turn []message) bool {
+	var last *message
+	for i := range turn {
+		if turn[i].isError {
+			return true
+		}
+		if turn[i].role == RoleClaude {
+			last = &turn[i]
+		}
+	}
+	if last == nil || len(last.items) == 0 {
+		return last == nil || last.content == ""
+	}
+	final := last.items[len(last.items)-1]
+	return final.itemType != parser.ItemOutput || final.toolError
+}
+
+// promptsSimilar reports whether two prompts are identical or near-identical
+// once case and whitespace are normalized.
+func promptsSimilar(a, b string) bool {
+	a, b = normalizePrompt(a), normalizePrompt(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > maxRetryCompareRunes || len(rb) > maxRetryCompareRunes {
+		return false
+	}
+	return editDistance(ra, rb) <= max(len(ra), len(rb))/10
+}
+
+// normalizePrompt lowercases text, collapses whitespace, and drops trailing
+// punctuation so "Fix the build." and "fix the  build" compare equal.
+func normalizePrompt(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return strings.TrimRight(s, ".!?")
+}
+
+// editDistance is the edit distance between two rune slices, counting an
+// adjacent transposition ("teh" for "the") as a single edit.
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// modelChangeMessage builds the divider shown between AI turns that ran on
+// different models, e.g. "model changed: opus4.6 → sonnet4.5".
+func modelChangeMessage(from, to string, ts time.Time) message {
+	return message{
+		role:      RoleModel,
+		model:     shortModel(to),
+		content:   "model changed: " + shortModel(from) + " \u2192 " + shortModel(to),
+		timestamp: formatTime(ts),
+		startedAt: ts,
+	}
+}
+
+// modeChangeMessage builds the divider shown before a prompt sent in a new
+// permission mode, e.g. "permission mode: default → acceptEdits". from is
+// empty for the session's first recorded mode.
+func modeChangeMessage(from, to string, ts time.Time) message {
+	content := "permission mode: " + to
+	if from != "" {
+		content = "permission mode: " + from + " \u2192 " + to
+	}
+	return message{
+		role:           RoleMode,
+		permissionMode: to,
+		content:        content,
+		timestamp:      formatTime(ts),
+		startedAt:      ts,
+	}
+}
+
 // displayItemFromParser maps a single parser.DisplayItem to the TUI's displayItem,
 // including JSON pretty-printing of tool input.
 func displayItemFromParser(it parser.DisplayItem) displayItem {
@@ -91,18 +355,25 @@ func displayItemFromParser(it parser.DisplayItem) displayItem {
 		itemType:       it.Type,
 		text:           it.Text,
 		toolName:       it.ToolName,
+		toolID:         it.ToolID,
 		toolSummary:    it.ToolSummary,
 		toolCategory:   it.ToolCategory,
 		toolInput:      input,
 		toolResult:     it.ToolResult,
 		toolError:      it.ToolError,
 		durationMs:     it.DurationMs,
+		execMs:         it.ExecMs,
 		tokenCount:     it.TokenCount,
+		timestamp:      it.Timestamp,
 		subagentType:   it.SubagentType,
 		subagentDesc:   it.SubagentDesc,
 		teamMemberName: it.TeamMemberName,
 		teammateID:     it.TeammateID,
 		teamColor:      it.TeammateColor,
+		hooks:          it.Hooks,
+		patch:          it.Patch,
+		background:     it.Background,
+		references:     it.References,
 	}
 }
 
@@ -132,6 +403,9 @@ func convertDisplayItems(items []parser.DisplayItem, subagents []parser.Subagent
 			if proc := procByTaskID[it.ToolID]; proc != nil {
 				out[i].subagentProcess = proc
 				out[i].subagentOngoing = isSubagentOngoing(proc)
+				if out[i].subagentOngoing {
+					out[i].subagentStep = parser.CurrentStep(proc.Chunks)
+				}
 				if proc.TeammateColor != "" {
 					out[i].teamColor = proc.TeammateColor
 				}
@@ -175,7 +449,7 @@ func convertDisplayItems(items []parser.DisplayItem, subagents []parser.Subagent
 
 // isSubagentOngoing checks whether a subagent session is still in progress.
 // Combines chunk-based activity analysis with a file staleness check: if the
-// session file hasn't been modified in OngoingStalenessThreshold, the agent
+// session file hasn't been modified within the staleness threshold, the agent
 // process is gone regardless of what the chunks say. This catches edge cases
 // where IsOngoing returns a false positive on fully completed sessions.
 func isSubagentOngoing(proc *parser.SubagentProcess) bool {
@@ -183,7 +457,7 @@ func isSubagentOngoing(proc *parser.SubagentProcess) bool {
 		return false
 	}
 	// File hasn't been written to recently — agent is dead.
-	if !proc.FileModTime.IsZero() && time.Since(proc.FileModTime) > parser.OngoingStalenessThreshold {
+	if !proc.FileModTime.IsZero() && parser.Ongoing.Stale(proc.FileModTime, time.Now()) {
 		return false
 	}
 	return true
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -117,6 +118,171 @@ func TestChunksToMessages_CompactChunk(t *testing.T) {
 	}
 }
 
+func TestChunksToMessages_ModelChange(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	chunks := []parser.Chunk{
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "hi"},
+		{Type: parser.AIChunk, Timestamp: ts, Model: "claude-opus-4-6"},
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "again"},
+		{Type: parser.AIChunk, Timestamp: ts, Model: "claude-opus-4-6"},
+		{Type: parser.SystemChunk, Timestamp: ts, Output: "Set model to sonnet"},
+		{Type: parser.AIChunk, Timestamp: ts, Model: "claude-sonnet-4-5-20250929"},
+		{Type: parser.AIChunk, Timestamp: ts}, // no model: doesn't reset tracking
+		{Type: parser.AIChunk, Timestamp: ts, Model: "claude-sonnet-4-5-20250929"},
+	}
+	msgs := chunksToMessages(chunks, nil, nil)
+
+	var markers []message
+	for _, m := range msgs {
+		if m.role == RoleModel {
+			markers = append(markers, m)
+		}
+	}
+	if len(markers) != 1 {
+		t.Fatalf("got %d model markers, want 1", len(markers))
+	}
+	want := "model changed: opus4.6 → sonnet4.5"
+	if markers[0].content != want {
+		t.Errorf("content = %q, want %q", markers[0].content, want)
+	}
+	if markers[0].model != "sonnet4.5" {
+		t.Errorf("model = %q, want %q", markers[0].model, "sonnet4.5")
+	}
+	// Marker sits immediately before the AI message that switched.
+	if msgs[5].role != RoleModel || msgs[6].model != "sonnet4.5" {
+		t.Errorf("marker not placed before switching AI message: roles %q, %q", msgs[5].role, msgs[6].role)
+	}
+}
+
+func TestChunksToMessages_PermissionModeChange(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	chunks := []parser.Chunk{
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "plan it", PermissionMode: "default"},
+		{Type: parser.AIChunk, Timestamp: ts},
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "go", PermissionMode: "acceptEdits"},
+		{Type: parser.AIChunk, Timestamp: ts},
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "more"}, // no mode recorded
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "again", PermissionMode: "acceptEdits"},
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "yolo", PermissionMode: "bypassPermissions"},
+	}
+	msgs := chunksToMessages(chunks, nil, nil)
+
+	var markers []message
+	for i, m := range msgs {
+		if m.role == RoleMode {
+			markers = append(markers, m)
+			if i+1 >= len(msgs) || msgs[i+1].role != RoleUser {
+				t.Errorf("marker %q should precede the prompt that switched", m.content)
+			}
+		}
+	}
+	want := []string{
+		"permission mode: default \u2192 acceptEdits",
+		"permission mode: acceptEdits \u2192 bypassPermissions",
+	}
+	if len(markers) != len(want) {
+		t.Fatalf("got %d markers, want %d: %+v", len(markers), len(want), markers)
+	}
+	for i, w := range want {
+		if markers[i].content != w {
+			t.Errorf("marker[%d] = %q, want %q", i, markers[i].content, w)
+		}
+	}
+	if markers[1].permissionMode != "bypassPermissions" {
+		t.Errorf("permissionMode = %q, want bypassPermissions", markers[1].permissionMode)
+	}
+
+	// A session that starts outside the default mode is marked too.
+	msgs = chunksToMessages([]parser.Chunk{
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "hi", PermissionMode: "plan"},
+	}, nil, nil)
+	if len(msgs) != 2 || msgs[0].role != RoleMode || msgs[0].content != "permission mode: plan" {
+		t.Errorf("msgs = %+v, want a leading plan marker", msgs)
+	}
+}
+
+func TestChunksToMessages_Command(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	chunks := []parser.Chunk{
+		{Type: parser.CommandChunk, Timestamp: ts, Command: "/review", CommandArgs: "src/", Output: "no issues", IsError: true},
+		{Type: parser.CommandChunk, Timestamp: ts, Command: "/clear"},
+	}
+	msgs := chunksToMessages(chunks, nil, nil)
+	if len(msgs) != 2 {
+		t.Fatalf("len(msgs) = %d, want 2", len(msgs))
+	}
+	if msgs[0].role != RoleCommand {
+		t.Errorf("role = %q, want %q", msgs[0].role, RoleCommand)
+	}
+	if msgs[0].command != "/review src/" {
+		t.Errorf("command = %q, want %q", msgs[0].command, "/review src/")
+	}
+	if msgs[0].content != "no issues" || !msgs[0].isError {
+		t.Errorf("content/isError = %q/%v, want %q/true", msgs[0].content, msgs[0].isError, "no issues")
+	}
+	if msgs[1].command != "/clear" {
+		t.Errorf("command = %q, want %q", msgs[1].command, "/clear")
+	}
+}
+
+func TestChunksToMessages_Retries(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	interrupted := []parser.DisplayItem{
+		{Type: parser.ItemOutput, Text: "Let me look."},
+		{Type: parser.ItemToolCall, ToolName: "Bash"},
+	}
+	answered := []parser.DisplayItem{{Type: parser.ItemOutput, Text: "Done."}}
+	chunks := []parser.Chunk{
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "Fix the build."},
+		{Type: parser.AIChunk, Timestamp: ts, Items: interrupted},
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "fix the  build"},
+		{Type: parser.SystemChunk, Timestamp: ts, Output: "API error", IsError: true},
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "Fix teh build"},
+		{Type: parser.AIChunk, Timestamp: ts, Items: answered},
+		// Same prompt after a completed answer is a new request, not a retry.
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "Fix the build"},
+		{Type: parser.AIChunk, Timestamp: ts, Items: answered},
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "continue"},
+		{Type: parser.AIChunk, Timestamp: ts, Items: interrupted},
+		{Type: parser.UserChunk, Timestamp: ts, UserText: "now run the tests"},
+	}
+	msgs := chunksToMessages(chunks, nil, nil)
+
+	var got []string
+	for _, m := range msgs {
+		if m.role == RoleUser {
+			got = append(got, fmt.Sprintf("%d/%d", m.attempt, m.attempts))
+		}
+	}
+	want := []string{"1/3", "2/3", "3/3", "0/0", "0/0", "0/0"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("attempts = %v, want %v", got, want)
+	}
+
+	header := userHeaderLine(msgs[2])
+	if !strings.Contains(header, "attempt 2 of 3") {
+		t.Errorf("header = %q, want attempt badge", header)
+	}
+}
+
+func TestPromptsSimilar(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"fix the build", "Fix the build!", true},
+		{"fix the build", "fix teh build", true},
+		{"fix the build", "run the tests", false},
+		{"yes", "no", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := promptsSimilar(tt.a, tt.b); got != tt.want {
+			t.Errorf("promptsSimilar(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
 func TestDisplayItemFromParser(t *testing.T) {
 	t.Run("tool call with JSON input is pretty-printed", func(t *testing.T) {
 		it := parser.DisplayItem{
@@ -169,6 +335,24 @@ func TestDisplayItemFromParser(t *testing.T) {
 			t.Errorf("toolInput = %q, want raw fallback %q", got.toolInput, "{not valid json")
 		}
 	})
+
+	t.Run("hook output carries over and flags hook errors", func(t *testing.T) {
+		it := parser.DisplayItem{
+			Type:     parser.ItemToolCall,
+			ToolName: "Bash",
+			Hooks: []parser.HookOutput{
+				{Event: "PreToolUse", Matcher: "Bash", Output: "ok"},
+				{Event: "PostToolUse", Matcher: "Bash", Output: "lint failed", IsError: true},
+			},
+		}
+		got := displayItemFromParser(it)
+		if len(got.hooks) != 2 {
+			t.Fatalf("len(hooks) = %d, want 2", len(got.hooks))
+		}
+		if !got.hookError() {
+			t.Error("hookError() should be true when any hook failed")
+		}
+	})
 }
 
 func TestConvertDisplayItems(t *testing.T) {
@@ -432,3 +616,71 @@ func TestCurrentDetailMsg(t *testing.T) {
 		}
 	})
 }
+
+func TestMarkLoops(t *testing.T) {
+	call := func(name, input string) displayItem {
+		return displayItem{itemType: parser.ItemToolCall, toolName: name, toolInput: input}
+	}
+	turn := func(items ...displayItem) message {
+		return claudeMsg(func(m *message) { m.items = items })
+	}
+	test := call("Bash", `{"command": "go test ./..."}`)
+	read := call("Read", `{"file_path": "/repo/main.go"}`)
+
+	t.Run("within one message", func(t *testing.T) {
+		msgs := []message{turn(test, test, read, test, test)}
+		markLoops(msgs)
+		if msgs[0].loopTool != "Bash" || msgs[0].loopCount != 4 {
+			t.Errorf("loop = %q ×%d, want Bash ×4", msgs[0].loopTool, msgs[0].loopCount)
+		}
+	})
+
+	t.Run("across consecutive turns", func(t *testing.T) {
+		msgs := []message{turn(test, test), userMsg("try again"), turn(test), turn(test, read)}
+		markLoops(msgs)
+		if msgs[2].loopCount != 0 {
+			t.Errorf("third call flagged: %q ×%d", msgs[2].loopTool, msgs[2].loopCount)
+		}
+		if msgs[3].loopTool != "Bash" || msgs[3].loopCount != 4 {
+			t.Errorf("loop = %q ×%d, want Bash ×4", msgs[3].loopTool, msgs[3].loopCount)
+		}
+	})
+
+	t.Run("run broken by a turn without the call", func(t *testing.T) {
+		msgs := []message{turn(test, test), turn(read), turn(test, test)}
+		markLoops(msgs)
+		for i, m := range msgs {
+			if m.loopCount != 0 {
+				t.Errorf("msgs[%d] flagged: %q ×%d", i, m.loopTool, m.loopCount)
+			}
+		}
+	})
+
+	t.Run("different input is not a repeat", func(t *testing.T) {
+		msgs := []message{turn(test, test, test, call("Bash", `{"command": "go vet ./..."}`))}
+		markLoops(msgs)
+		if msgs[0].loopCount != 0 {
+			t.Errorf("flagged: %q ×%d", msgs[0].loopTool, msgs[0].loopCount)
+		}
+	})
+}
+
+func TestChunksToMessages_APIErrors(t *testing.T) {
+	chunks := []parser.Chunk{{
+		Type: parser.ErrorChunk,
+		Errors: []parser.ErrorMsg{
+			{Kind: "overloaded_error", RetryAttempt: 1, MaxRetries: 10},
+			{Kind: "overloaded_error", Final: true},
+		},
+	}}
+	msgs := chunksToMessages(chunks, nil, nil)
+	if len(msgs) != 1 || msgs[0].role != RoleError {
+		t.Fatalf("msgs = %+v, want one error message", msgs)
+	}
+	if !msgs[0].isError {
+		t.Error("failed request should set isError")
+	}
+	if len(msgs[0].apiErrors) != 2 || !strings.Contains(msgs[0].content, "overloaded · failed") {
+		t.Errorf("content = %q, want both attempts", msgs[0].content)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+)
+
+// turnCSVHeader names the columns of --export csv.
+var turnCSVHeader = []string{
+	"turn", "timestamp", "model",
+	"input_tokens", "output_tokens", "cache_read_tokens", "cache_creation_tokens",
+	"duration_ms", "tool_calls", "tool_errors",
+}
+
+// turnsCSV renders one row per Claude turn for spreadsheets and notebooks.
+// Numbers are plain integers and times RFC 3339 in UTC, whatever the
+// locale, so the file parses the same everywhere. Tool errors count the
+// turn's failed and rejected calls; subagents' own calls aren't counted.
+func turnsCSV(msgs []message) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(turnCSVHeader); err != nil {
+		return nil, err
+	}
+	turn := 0
+	for _, msg := range msgs {
+		if msg.role != RoleClaude {
+			continue
+		}
+		turn++
+		failed := 0
+		for _, item := range msg.items {
+			if item.toolError {
+				failed++
+			}
+		}
+		ts := ""
+		if !msg.startedAt.IsZero() {
+			ts = msg.startedAt.UTC().Format(time.RFC3339)
+		}
+		row := []string{
+			strconv.Itoa(turn), ts, msg.model,
+			strconv.Itoa(msg.usage.InputTokens),
+			strconv.Itoa(msg.usage.OutputTokens),
+			strconv.Itoa(msg.usage.CacheReadTokens),
+			strconv.Itoa(msg.usage.CacheCreationTokens),
+			strconv.FormatInt(msg.durationMs, 10),
+			strconv.Itoa(msg.toolCallCount),
+			strconv.Itoa(failed),
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestTurnsCSV(t *testing.T) {
+	at := time.Date(2025, 1, 15, 10, 4, 12, 0, time.FixedZone("NZDT", 13*3600))
+	msgs := []message{
+		userMsg("fix the build"),
+		claudeMsg(func(m *message) {
+			m.model = "opus4.6"
+			m.startedAt = at
+			m.usage = parser.Usage{InputTokens: 12, OutputTokens: 1500, CacheReadTokens: 30000, CacheCreationTokens: 2048}
+			m.durationMs = 42_000
+			m.toolCallCount = 3
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolError: true},
+				{itemType: parser.ItemToolCall, toolName: "Read"},
+				{itemType: parser.ItemToolCall, toolName: "Edit", toolError: true},
+			}
+		}),
+		userMsg("thanks"),
+		claudeMsg(func(m *message) {
+			m.model = "sonnet4.5"
+		}),
+	}
+	data, err := turnsCSV(msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatalf("output doesn't parse as CSV: %v\n%s", err, data)
+	}
+	want := [][]string{
+		turnCSVHeader,
+		{"1", "2025-01-14T21:04:12Z", "opus4.6", "12", "1500", "30000", "2048", "42000", "3", "2"},
+		{"2", "", "sonnet4.5", "0", "0", "0", "0", "0", "0", "0"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%s", len(rows), len(want), data)
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// itemsExportedMsg reports the result of exporting marked detail items.
+type itemsExportedMsg struct {
+	count int
+	path  string
+	err   error
+}
+
+// toggleDetailMark marks or unmarks the row under the cursor and moves down.
+func (m *model) toggleDetailMark() {
+	rows := m.detailVisibleRows()
+	if m.detailCursor >= len(rows) {
+		return
+	}
+	row := rows[m.detailCursor]
+	key := visibleRowKey{row.parentIndex, row.childIndex}
+	if m.detailMarked == nil {
+		m.detailMarked = make(map[visibleRowKey]bool)
+	}
+	if m.detailMarked[key] {
+		delete(m.detailMarked, key)
+	} else {
+		m.detailMarked[key] = true
+	}
+	if m.detailCursor < len(rows)-1 {
+		m.detailCursor++
+	}
+	m.ensureDetailCursorVisible()
+}
+
+// markedItems returns the marked items in message order, including marked
+// trace rows whose subagent has since been collapsed.
+func (m model) markedItems() []displayItem {
+	keys := make([]visibleRowKey, 0, len(m.detailMarked))
+	for k := range m.detailMarked {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].parentIndex != keys[j].parentIndex {
+			return keys[i].parentIndex < keys[j].parentIndex
+		}
+		return keys[i].childIndex < keys[j].childIndex
+	})
+
+	msgItems := m.currentDetailMsg().items
+	var items []displayItem
+	for _, k := range keys {
+		if k.parentIndex >= len(msgItems) {
+			continue
+		}
+		parent := msgItems[k.parentIndex]
+		if k.childIndex == -1 {
+			items = append(items, parent)
+			continue
+		}
+		if trace := buildTraceItems(parent); k.childIndex < len(trace) {
+			items = append(items, trace[k.childIndex])
+		}
+	}
+	return items
+}
+
+// markedItemText returns what "copy marked results" takes from an item: the
+// result of a tool call or subagent, the text of anything else.
+func markedItemText(item displayItem) string {
+	switch item.itemType {
+	case parser.ItemToolCall, parser.ItemSubagent:
+		return item.toolResult
+	}
+	return item.text
+}
+
+// copyMarkedItems copies the results of all marked items to the clipboard,
+// separated by blank lines.
+func (m *model) copyMarkedItems() tea.Cmd {
+	items := m.markedItems()
+	if len(items) == 0 {
+		m.flashStatus = "No items marked (space marks)"
+		return flashClearCmd()
+	}
+	var parts []string
+	for _, item := range items {
+		if text := strings.TrimSpace(markedItemText(item)); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	if len(parts) == 0 {
+		m.flashStatus = "Marked items have no results to copy"
+		return flashClearCmd()
+	}
+	m.flashStatus = fmt.Sprintf("Copied %s", parser.Plural(len(parts), "result"))
+	return tea.Batch(tea.SetClipboard(strings.Join(parts, "\n\n")), flashClearCmd())
+}
+
+// exportMarkedItems writes the marked items to a Markdown file in exportDir.
+func (m *model) exportMarkedItems() tea.Cmd {
+	if m.readOnly {
+		m.flashStatus = "Read-only: exports are off"
+		return flashClearCmd()
+	}
+	items := m.markedItems()
+	if len(items) == 0 {
+		m.flashStatus = "No items marked (space marks)"
+		return flashClearCmd()
+	}
+	name := strings.TrimSuffix(filepath.Base(m.sessionPath), ".jsonl")
+	if m.sessionPath == "" {
+		name = "session"
+	}
+	path := filepath.Join(exportDir, name+"-items-"+time.Now().Format("20060102-150405")+".md")
+	m.flashStatus = fmt.Sprintf("Exporting %s...", parser.Plural(len(items), "item"))
+	return exportItemsCmd(markedItemsMarkdown(exportHeading(m.currentDetailMsg()), items), len(items), path)
+}
+
+// exportItemsCmd writes an items export off the UI goroutine.
+func exportItemsCmd(content string, count int, path string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return itemsExportedMsg{path: path, err: err}
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return itemsExportedMsg{path: path, err: err}
+		}
+		return itemsExportedMsg{count: count, path: path}
+	}
+}
+
+// markedItemsMarkdown renders items as Markdown: one section per item with
+// a tool call's input and result in code blocks, or the item's text.
+func markedItemsMarkdown(heading string, items []displayItem) string {
+	var b strings.Builder
+	b.WriteString("# " + heading + "\n")
+	for _, item := range items {
+		writeItemMarkdown(&b, item, "")
+	}
+	return b.String()
+}
+
+// writeItemMarkdown writes one item's section of an items export, with
+// source, when set, in italics under its heading.
+func writeItemMarkdown(b *strings.Builder, item displayItem, source string) {
+	switch item.itemType {
+	case parser.ItemToolCall, parser.ItemSubagent:
+		fmt.Fprintf(b, "\n## `%s` %s\n", exportToolName(item), fullItemSummary(item))
+	default:
+		b.WriteString("\n## " + markedItemName(item) + "\n")
+	}
+	if source != "" {
+		b.WriteString("\n_" + source + "_\n")
+	}
+	switch item.itemType {
+	case parser.ItemToolCall, parser.ItemSubagent:
+		if input := strings.TrimSpace(item.toolInput); input != "" {
+			b.WriteString("\nInput:\n\n```json\n" + input + "\n```\n")
+		}
+		if result := strings.TrimSpace(item.toolResult); result != "" {
+			label := "Result"
+			if item.toolError {
+				label += " (error)"
+			}
+			b.WriteString("\n" + label + ":\n\n```\n" + result + "\n```\n")
+		}
+	default:
+		if text := strings.TrimSpace(item.text); text != "" {
+			b.WriteString("\n" + text + "\n")
+		}
+	}
+}
+
+// markedItemName names a non-tool item in an export: "Thinking", "Output",
+// the hook event, the teammate, or a queued prompt.
+func markedItemName(item displayItem) string {
+	switch item.itemType {
+	case parser.ItemThinking:
+		return "Thinking"
+	case parser.ItemTeammateMessage:
+		if item.teammateID != "" {
+			return "Teammate " + item.teammateID
+		}
+		return "Teammate"
+	case parser.ItemQueuedMessage:
+		return "Queued prompt"
+	}
+	if item.toolName != "" {
+		return item.toolName
+	}
+	return "Output"
+}
+
+// collapseUnmarked expands the marked rows and collapses everything else.
+// Subagents with marked trace rows stay expanded so those rows stay visible.
+// The cursor stays on its row, or moves to its subagent when that row is
+// folded away.
+func (m *model) collapseUnmarked() tea.Cmd {
+	if len(m.detailMarked) == 0 {
+		m.flashStatus = "No items marked (space marks)"
+		return flashClearCmd()
+	}
+	var cursorKey visibleRowKey
+	if rows := m.detailVisibleRows(); m.detailCursor < len(rows) {
+		cursorKey = visibleRowKey{rows[m.detailCursor].parentIndex, rows[m.detailCursor].childIndex}
+	}
+
+	m.detailExpanded = make(map[int]bool)
+	m.detailChildExpanded = make(map[visibleRowKey]bool)
+	for k := range m.detailMarked {
+		m.detailExpanded[k.parentIndex] = true
+		if k.childIndex != -1 {
+			m.detailChildExpanded[k] = true
+		}
+	}
+
+	m.detailCursor = 0
+	for i, row := range m.detailVisibleRows() {
+		if row.parentIndex == cursorKey.parentIndex && (row.childIndex == cursorKey.childIndex || row.childIndex == -1) {
+			m.detailCursor = i
+			if row.childIndex == cursorKey.childIndex {
+				break
+			}
+		}
+	}
+	m.computeDetailMaxScroll()
+	m.ensureDetailCursorVisible()
+	return nil
+}
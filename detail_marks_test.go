@@ -0,0 +1,124 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestDetailMarks(t *testing.T) {
+	msg := claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemThinking, text: "let me think"},
+			{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "go test ./..."}`, toolResult: "ok  \tpkg\t0.1s"},
+			{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "main.go"}`, toolResult: "package main"},
+			{itemType: parser.ItemOutput, text: "done"},
+		}
+	})
+
+	press := func(m model, keys ...string) model {
+		for _, k := range keys {
+			result, _ := m.updateDetail(key(k))
+			m = asModel(result)
+		}
+		return m
+	}
+
+	t.Run("space marks and advances", func(t *testing.T) {
+		m := detailModel(msg)
+		m.detailCursor = 1
+		m = press(m, "space", "space")
+		if m.detailCursor != 3 {
+			t.Errorf("cursor = %d, want 3", m.detailCursor)
+		}
+		if !m.detailMarked[visibleRowKey{1, -1}] || !m.detailMarked[visibleRowKey{2, -1}] {
+			t.Errorf("marked = %v, want rows 1 and 2", m.detailMarked)
+		}
+		m.detailCursor = 1
+		m = press(m, "space")
+		if m.detailMarked[visibleRowKey{1, -1}] {
+			t.Error("space on a marked row should unmark it")
+		}
+	})
+
+	t.Run("Y copies marked results in order", func(t *testing.T) {
+		m := detailModel(msg)
+		m.detailMarked = map[visibleRowKey]bool{{2, -1}: true, {1, -1}: true}
+		result, cmd := m.updateDetail(key("Y"))
+		if got := asModel(result).flashStatus; got != "Copied 2 results" {
+			t.Errorf("flash = %q", got)
+		}
+		if cmd == nil {
+			t.Error("Y should return a clipboard command")
+		}
+		if got := markedItemText(asModel(result).markedItems()[0]); got != "ok  \tpkg\t0.1s" {
+			t.Errorf("first marked item text = %q", got)
+		}
+	})
+
+	t.Run("actions need marks", func(t *testing.T) {
+		for _, k := range []string{"Y", "x", "C"} {
+			m := press(detailModel(msg), k)
+			if m.flashStatus != "No items marked (space marks)" {
+				t.Errorf("%s: flash = %q", k, m.flashStatus)
+			}
+		}
+	})
+
+	t.Run("x writes nothing under --read-only", func(t *testing.T) {
+		m := detailModel(msg)
+		m.readOnly = true
+		m.detailMarked = map[visibleRowKey]bool{{1, -1}: true}
+		result, _ := m.updateDetail(key("x"))
+		if got := asModel(result).flashStatus; got != "Read-only: exports are off" {
+			t.Errorf("flash = %q, want the export refused", got)
+		}
+	})
+
+	t.Run("C collapses all but marked", func(t *testing.T) {
+		m := detailModel(msg)
+		m.detailExpanded = map[int]bool{0: true, 1: true, 3: true}
+		m.detailMarked = map[visibleRowKey]bool{{2, -1}: true}
+		m.detailCursor = 3
+		m = press(m, "C")
+		want := map[int]bool{2: true}
+		if len(m.detailExpanded) != len(want) || !m.detailExpanded[2] {
+			t.Errorf("expanded = %v, want %v", m.detailExpanded, want)
+		}
+		if m.detailCursor != 3 {
+			t.Errorf("cursor = %d, want 3", m.detailCursor)
+		}
+	})
+
+	t.Run("C keeps marked trace rows visible", func(t *testing.T) {
+		m := detailModel(claudeMsgWithSubagent())
+		m.detailMarked = map[visibleRowKey]bool{{1, 1}: true}
+		m = press(m, "C")
+		if !m.detailExpanded[1] || !m.detailChildExpanded[visibleRowKey{1, 1}] {
+			t.Errorf("expanded = %v, child = %v", m.detailExpanded, m.detailChildExpanded)
+		}
+		if items := m.markedItems(); len(items) != 1 || items[0].toolName != "Read" {
+			t.Errorf("markedItems = %+v", items)
+		}
+	})
+}
+
+func TestMarkedItemsMarkdown(t *testing.T) {
+	items := []displayItem{
+		{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "make"}`, toolResult: "boom", toolError: true},
+		{itemType: parser.ItemThinking, text: "hmm"},
+	}
+	got := markedItemsMarkdown("Claude opus4.6 10:04:20 AM", items)
+	for _, want := range []string{
+		"# Claude opus4.6 10:04:20 AM\n",
+		"## `Bash` make\n",
+		"```json\n{\"command\": \"make\"}\n```",
+		"Result (error):\n\n```\nboom\n```",
+		"## Thinking\n\nhmm\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown missing %q:\n%s", want, got)
+		}
+	}
+}
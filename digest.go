@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// digestPromptChars caps each prompt in the digest to one readable line.
+const digestPromptChars = 100
+
+// digestSession is what one session did within the digest period.
+type digestSession struct {
+	name       string    // short session ID
+	root       string    // workspace root label, or ""
+	title      string    // first prompt of the session
+	first      time.Time // first activity in the period
+	last       time.Time // last activity in the period
+	prompts    []string  // prompts and slash commands sent in the period
+	files      []string  // files edited or written, relative to the session cwd
+	tokens     int
+	errors     int          // failed tool calls, failed API requests, and stderr output
+	approvals  approvalWait // time prompted tool calls waited for approval and ran
+	unfinished bool         // the session stopped mid-turn
+	running    bool         // the session is still writing
+}
+
+// runDigest implements `tail-claude digest [--since WHEN]`: it summarizes
+// every session of the current project (or of every root of its
+// workspace) active in the period and prints a Markdown note. Returns the
+// exit status: 0 with activity, 1 without, 2 on errors.
+func runDigest(args []string) int {
+	sinceArg := "yesterday"
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--since":
+			i++
+			if i >= len(args) {
+				fmt.Fprintln(os.Stderr, "--since requires a value")
+				return 2
+			}
+			sinceArg = args[i]
+		default:
+			fmt.Fprintln(os.Stderr, "usage: tail-claude digest [--since today|yesterday|Nd|DURATION|YYYY-MM-DD]")
+			return 2
+		}
+	}
+	now := time.Now()
+	since, err := parseSince(sinceArg, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--since: %v\n", err)
+		return 2
+	}
+	projectDir, err := parser.CurrentProjectDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 2
+	}
+	dirs := []string{projectDir}
+	cfgPath := configPath()
+	cfg, _ := loadConfig(cfgPath)
+	if err := validateWorkspaces(cfg.Workspaces); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring workspaces in %s: %v\n", cfgPath, err)
+		cfg.Workspaces = nil
+	}
+	ws := resolveWorkspace(cfg.Workspaces, projectDir)
+	if ws != nil {
+		dirs = dedup(append(dirs, ws.dirs...))
+	}
+	sessions, err := parser.DiscoverAllProjectSessions(dirs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "No sessions found for this project.")
+		return 2
+	}
+	digest := buildDigest(context.Background(), sessions, ws, since, now)
+	fmt.Print(digestMarkdown(digest, since))
+	if len(digest) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// parseSince resolves a --since value relative to now: "today" and
+// "yesterday" (from local midnight), a number of days ("3d"), a duration
+// ("36h"), or a date ("2026-10-14").
+func parseSince(s string, now time.Time) (time.Time, error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch s {
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return midnight.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q (want today, yesterday, 3d, 36h, or 2026-10-14)", s)
+}
+
+// buildDigest summarizes the sessions with activity since the given time,
+// oldest first, labeling each with its root in ws (which may be nil).
+// Sessions are parsed in parallel; ones that fail to parse are skipped, as
+// are the ones not reached before ctx ends.
+func buildDigest(ctx context.Context, sessions []parser.SessionInfo, ws *workspace, since, now time.Time) []digestSession {
+	var recent []parser.SessionInfo
+	for _, s := range sessions {
+		if !s.ModTime.Before(since) {
+			recent = append(recent, s)
+		}
+	}
+	results := make([]*digestSession, len(recent))
+	_ = parser.ForEachParallel(ctx, len(recent), parser.ScanWorkers, func(i int) {
+		results[i] = digestSessionFile(recent[i], since, now)
+	})
+	var digest []digestSession
+	for i, d := range results {
+		if d != nil {
+			d.root = ws.label(recent[i].Path)
+			digest = append(digest, *d)
+		}
+	}
+	sort.SliceStable(digest, func(i, j int) bool { return digest[i].first.Before(digest[j].first) })
+	return digest
+}
+
+// digestSessionFile summarizes the chunks of one session dated since the
+// given time. Returns nil when nothing in the period.
+func digestSessionFile(s parser.SessionInfo, since, now time.Time) *digestSession {
+	classified, _, _, err := parser.ReadSessionIncrementalOffsets(s.Path, 0)
+	if err != nil {
+		return nil
+	}
+	chunks := parser.BuildChunks(classified)
+	subagents, _ := parser.DiscoverSubagents(s.Path)
+	colorMap := parser.LinkSubagents(subagents, chunks, s.Path)
+	parser.LinkNestedSubagents(subagents)
+
+	d := &digestSession{name: formatSessionName(s.SessionID), title: s.FirstMessage}
+	var inPeriod []parser.Chunk
+	include := false
+	for _, c := range chunks {
+		// Chunks without a timestamp go with the chunk before them.
+		if !c.Timestamp.IsZero() {
+			include = !c.Timestamp.Before(since)
+		}
+		if !include {
+			continue
+		}
+		inPeriod = append(inPeriod, c)
+		if c.Timestamp.IsZero() {
+			continue
+		}
+		if d.first.IsZero() {
+			d.first = c.Timestamp
+		}
+		d.last = c.Timestamp
+	}
+	if len(inPeriod) == 0 {
+		return nil
+	}
+
+	msgs := chunksToMessages(inPeriod, subagents, colorMap)
+	for _, msg := range msgs {
+		switch msg.role {
+		case RoleUser:
+			d.prompts = append(d.prompts, parser.Truncate(strings.Join(strings.Fields(msg.content), " "), digestPromptChars))
+		case RoleCommand:
+			d.prompts = append(d.prompts, msg.command)
+		case RoleClaude:
+			d.tokens += msg.tokensRaw
+			for _, item := range msg.items {
+				if item.toolError {
+					d.errors++
+				}
+			}
+		case RoleError:
+			d.errors++
+		}
+		if msg.role != RoleError && msg.isError {
+			d.errors++
+		}
+	}
+	d.approvals = buildApprovalWait(msgs)
+	for _, f := range buildFileReport(msgs) {
+		if f.touched() {
+			d.files = append(d.files, relPath(f.path, s.Cwd))
+		}
+	}
+
+	if parser.IsOngoing(chunks) {
+		d.unfinished = true
+		d.running = !parser.Ongoing.Stale(s.ModTime, now)
+	}
+	return d
+}
+
+// digestMarkdown renders the digest as a Markdown note: a totals line, then
+// one section per session with its prompts and changed files.
+func digestMarkdown(digest []digestSession, since time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Digest since %s\n\n", since.Format("Mon Jan 2 15:04"))
+	if len(digest) == 0 {
+		b.WriteString("No session activity in this period.\n")
+		return b.String()
+	}
+
+	var prompts, tokens, errors, unfinished int
+	files := make(map[string]bool)
+	for _, d := range digest {
+		prompts += len(d.prompts)
+		tokens += d.tokens
+		errors += d.errors
+		for _, f := range d.files {
+			files[f] = true
+		}
+		if d.unfinished {
+			unfinished++
+		}
+	}
+	totals := []string{
+		pluralCount(len(digest), "session"),
+		pluralCount(prompts, "prompt"),
+		parser.Plural(len(files), "file") + " changed",
+		formatTokens(tokens) + " tokens",
+		parser.Plural(errors, "error"),
+	}
+	if unfinished > 0 {
+		totals = append(totals, fmt.Sprintf("%d unfinished", unfinished))
+	}
+	b.WriteString(strings.Join(totals, ", ") + "\n")
+
+	for _, d := range digest {
+		title := parser.Truncate(strings.Join(strings.Fields(d.title), " "), digestPromptChars)
+		if title == "" {
+			title = d.name
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+
+		var facts []string
+		if d.root != "" {
+			facts = append(facts, d.root)
+		}
+		facts = append(facts, "`"+d.name+"`", digestSpan(d.first, d.last))
+		facts = append(facts, formatTokens(d.tokens)+" tokens")
+		if d.errors > 0 {
+			facts = append(facts, parser.Plural(d.errors, "error"))
+		}
+		if wait := d.approvals.String(); wait != "" {
+			facts = append(facts, wait)
+		}
+		switch {
+		case d.running:
+			facts = append(facts, "**still running**")
+		case d.unfinished:
+			facts = append(facts, "**stopped mid-turn**")
+		}
+		b.WriteString(strings.Join(facts, ", ") + "\n")
+
+		if len(d.prompts) > 0 {
+			b.WriteString("\nPrompts:\n")
+			for _, p := range d.prompts {
+				fmt.Fprintf(&b, "- %s\n", p)
+			}
+		}
+		if len(d.files) > 0 {
+			b.WriteString("\nFiles changed:\n")
+			for _, f := range d.files {
+				fmt.Fprintf(&b, "- `%s`\n", f)
+			}
+		}
+	}
+	return b.String()
+}
+
+// digestSpan renders a session's active time in the period: "Oct 15 14:02-16:30",
+// or with both dates when it crosses midnight.
+func digestSpan(first, last time.Time) string {
+	first, last = first.Local(), last.Local()
+	if first.YearDay() == last.YearDay() && first.Year() == last.Year() {
+		return first.Format("Jan 2 15:04") + "-" + last.Format("15:04")
+	}
+	return first.Format("Jan 2 15:04") + " - " + last.Format("Jan 2 15:04")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"today", time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)},
+		{"yesterday", time.Date(2026, 10, 15, 0, 0, 0, 0, time.Local)},
+		{"3d", time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "last week", "-2h"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("parseSince(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	path := writeTurns(t, 5) // prompts at 10:00 through 10:04 UTC
+	sessions := []parser.SessionInfo{{Path: path, SessionID: "session", FirstMessage: "prompt 0", ModTime: time.Now()}}
+
+	since := time.Date(2025, 1, 15, 10, 2, 0, 0, time.UTC)
+	digest := buildDigest(context.Background(), sessions, nil, since, time.Now())
+	if len(digest) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(digest))
+	}
+	d := digest[0]
+	if got := strings.Join(d.prompts, ","); got != "prompt 2,prompt 3,prompt 4" {
+		t.Errorf("prompts = %q, want the ones since 10:02", got)
+	}
+	if d.unfinished {
+		t.Error("a session ending on a finished turn isn't unfinished")
+	}
+
+	note := digestMarkdown(digest, since)
+	for _, want := range []string{"1 session, 3 prompts, 0 files changed", "## prompt 0", "- prompt 4"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("digest missing %q:\n%s", want, note)
+		}
+	}
+
+	if got := buildDigest(context.Background(), sessions, nil, time.Now().Add(time.Hour), time.Now()); len(got) != 0 {
+		t.Errorf("sessions untouched since should be left out, got %d", len(got))
+	}
+}
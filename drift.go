@@ -0,0 +1,340 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// driftStatus is how a file on disk compares with what the session wrote.
+type driftStatus int
+
+const (
+	driftInSync    driftStatus = iota // disk matches the session's last write/edit
+	driftChanged                      // disk content differs
+	driftMissing                      // file no longer exists
+	driftUnchecked                    // nothing to compare (e.g. an edit that only deleted text)
+)
+
+// expectedFile is what the session believes a file contains after its last
+// successful Edit or Write. A Write gives the full content; edits on a file
+// never written in the session only tell us their replacement text.
+type expectedFile struct {
+	path     string
+	agent    string // who made the last change
+	tool     string // tool of the last change
+	changes  int    // successful edits and writes
+	content  string // full expected content when known
+	known    bool   // content is the whole file
+	fragment string // last edit's new text, when content is not known
+}
+
+// driftEntry is one row of the drift view.
+type driftEntry struct {
+	expectedFile
+	status driftStatus
+	detail string // "+3 -1 lines", "edited text not found", ...
+}
+
+// driftCheckedMsg delivers the result of a disk comparison.
+type driftCheckedMsg struct {
+	entries []driftEntry
+}
+
+// buildExpectedFiles replays every successful Edit, MultiEdit, and Write in
+// the session and its subagent traces, in order, to reconstruct what each
+// file should contain. Relative paths resolve against cwd.
+func buildExpectedFiles(msgs []message, cwd string) []expectedFile {
+	byPath := make(map[string]*expectedFile)
+	var order []string
+
+	var visit func(items []displayItem, agent string)
+	visit = func(items []displayItem, agent string) {
+		for _, item := range items {
+			if item.subagentProcess != nil {
+				visit(buildTraceItems(item), agentLabel(item.subagentProcess))
+			}
+			if item.itemType != parser.ItemToolCall || item.toolError {
+				continue
+			}
+			switch item.toolName {
+			case "Edit", "MultiEdit", "Write":
+			default:
+				continue
+			}
+			var input struct {
+				FilePath string `json:"file_path"`
+				Content  string `json:"content"`
+				fileEdit
+				Edits []fileEdit `json:"edits"`
+			}
+			if json.Unmarshal([]byte(item.toolInput), &input) != nil || input.FilePath == "" {
+				continue
+			}
+			path := input.FilePath
+			if !filepath.IsAbs(path) && cwd != "" {
+				path = filepath.Join(cwd, path)
+			}
+			ef := byPath[path]
+			if ef == nil {
+				ef = &expectedFile{path: path}
+				byPath[path] = ef
+				order = append(order, path)
+			}
+			ef.agent, ef.tool = agent, item.toolName
+			ef.changes++
+			switch item.toolName {
+			case "Write":
+				ef.content, ef.known, ef.fragment = input.Content, true, ""
+			case "Edit":
+				ef.applyEdit(input.fileEdit)
+			case "MultiEdit":
+				for _, e := range input.Edits {
+					ef.applyEdit(e)
+				}
+			}
+		}
+	}
+	for _, msg := range msgs {
+		visit(msg.items, mainAgentLabel)
+	}
+
+	files := make([]expectedFile, 0, len(order))
+	for _, path := range order {
+		files = append(files, *byPath[path])
+	}
+	return files
+}
+
+// fileEdit is one string replacement from an Edit or MultiEdit call.
+type fileEdit struct {
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all"`
+}
+
+// applyEdit updates the expected content with one replacement.
+func (ef *expectedFile) applyEdit(e fileEdit) {
+	if !ef.known {
+		ef.fragment = e.NewString
+		return
+	}
+	if e.ReplaceAll {
+		ef.content = strings.ReplaceAll(ef.content, e.OldString, e.NewString)
+	} else {
+		ef.content = strings.Replace(ef.content, e.OldString, e.NewString, 1)
+	}
+}
+
+// checkDrift compares each expected file with the file on disk. Files not in
+// sync sort first, then by path.
+func checkDrift(files []expectedFile) []driftEntry {
+	entries := make([]driftEntry, 0, len(files))
+	for _, ef := range files {
+		entries = append(entries, checkFileDrift(ef))
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		di, dj := entries[i].status != driftInSync, entries[j].status != driftInSync
+		if di != dj {
+			return di
+		}
+		return entries[i].path < entries[j].path
+	})
+	return entries
+}
+
+// checkFileDrift compares one expected file with the disk.
+func checkFileDrift(ef expectedFile) driftEntry {
+	e := driftEntry{expectedFile: ef}
+	data, err := os.ReadFile(ef.path)
+	switch {
+	case os.IsNotExist(err):
+		e.status, e.detail = driftMissing, "deleted since"
+		return e
+	case err != nil:
+		e.status, e.detail = driftUnchecked, err.Error()
+		return e
+	}
+	disk := string(data)
+	switch {
+	case ef.known:
+		if disk == ef.content {
+			e.status = driftInSync
+		} else {
+			e.status = driftChanged
+			e.detail = lineDelta(ef.content, disk)
+		}
+	case ef.fragment == "":
+		e.status, e.detail = driftUnchecked, "last edit only deleted text"
+	case strings.Contains(disk, ef.fragment):
+		e.status = driftInSync
+	default:
+		e.status, e.detail = driftChanged, "edited text not found"
+	}
+	return e
+}
+
+// lineDelta summarizes how disk differs from want as "+added -removed lines",
+// counting lines present on one side more often than on the other. Moves
+// within the file don't count.
+func lineDelta(want, disk string) string {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(want, "\n") {
+		counts[line]--
+	}
+	for _, line := range strings.Split(disk, "\n") {
+		counts[line]++
+	}
+	var added, removed int
+	for _, n := range counts {
+		if n > 0 {
+			added += n
+		} else {
+			removed -= n
+		}
+	}
+	if added == 0 && removed == 0 {
+		return "lines reordered"
+	}
+	return fmt.Sprintf("+%d -%d lines", added, removed)
+}
+
+// checkDriftCmd reads the files off the UI goroutine.
+func checkDriftCmd(files []expectedFile) tea.Cmd {
+	return func() tea.Msg {
+		return driftCheckedMsg{entries: checkDrift(files)}
+	}
+}
+
+// openDrift switches to the drift view and starts a disk check.
+func (m *model) openDrift() tea.Cmd {
+	m.driftEntries = nil
+	m.driftCursor = 0
+	m.driftScroll = 0
+	m.view = viewDrift
+	return m.recheckDrift()
+}
+
+// recheckDrift compares the session's edits with the disk again.
+func (m *model) recheckDrift() tea.Cmd {
+	return checkDriftCmd(buildExpectedFiles(m.rawMessages, m.sessionCwd))
+}
+
+// updateDrift handles key events in the drift view.
+func (m model) updateDrift(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "D":
+		m.view = viewList
+	case "j", "down":
+		if m.driftCursor < len(m.driftEntries)-1 {
+			m.driftCursor++
+		}
+		m.ensureDriftVisible()
+	case "k", "up":
+		if m.driftCursor > 0 {
+			m.driftCursor--
+		}
+		m.ensureDriftVisible()
+	case "G":
+		m.driftCursor = max(len(m.driftEntries)-1, 0)
+		m.ensureDriftVisible()
+	case "g":
+		m.driftCursor = 0
+		m.driftScroll = 0
+	case "r":
+		return m, m.recheckDrift()
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// driftViewHeight returns the visible rows (minus header and footer).
+func (m model) driftViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// ensureDriftVisible adjusts driftScroll so the cursor row is visible.
+func (m *model) ensureDriftVisible() {
+	m.driftScroll = scrollToShow(m.driftScroll, m.driftCursor, 1, m.driftViewHeight())
+}
+
+// viewDriftList renders the drift view: one row per file the session changed.
+func (m model) viewDriftList() string {
+	width := m.clampWidth()
+
+	drifted := 0
+	for _, e := range m.driftEntries {
+		if e.status == driftChanged || e.status == driftMissing {
+			drifted++
+		}
+	}
+	header := StyleAccentBold.Render("Drift") + " " +
+		StyleDim.Render(fmt.Sprintf("(%s, %d changed on disk)", parser.Plural(len(m.driftEntries), "file"), drifted)) + "\n"
+
+	var lines []string
+	for i, e := range m.driftEntries {
+		lines = append(lines, m.renderDriftRow(e, i == m.driftCursor, width))
+	}
+
+	content := header
+	if len(m.driftEntries) == 0 {
+		content += "\n" + StyleDim.Render("No edits or writes in this session.")
+	} else {
+		content += "\n" + strings.Join(scrollWindow(lines, m.driftViewHeight(), m.driftScroll), "\n")
+	}
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"j/k", "nav",
+		"r", "recheck",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
+
+// renderDriftRow renders "{sel} {icon} path    changed +3 -1 lines  Edit by main".
+func (m model) renderDriftRow(e driftEntry, isSelected bool, width int) string {
+	sel := selectionIndicator(isSelected)
+
+	var status string
+	switch e.status {
+	case driftInSync:
+		status = StyleDim.Render("in sync")
+	case driftChanged:
+		status = StyleErrorBold.Render("changed")
+	case driftMissing:
+		status = StyleErrorBold.Render("missing")
+	default:
+		status = StyleMuted.Render("unchecked")
+	}
+	if e.detail != "" {
+		status += " " + StyleDim.Render(e.detail)
+	}
+	right := status + "  " + StyleMuted.Render(e.tool+" by "+e.agent)
+
+	icon := Icon.Tool.Edit.Render()
+	if e.status == driftChanged || e.status == driftMissing {
+		icon = Icon.Tool.Err.Render()
+	}
+	pathStyle := StyleSecondary
+	if isSelected {
+		pathStyle = StylePrimaryBold
+	}
+	room := max(width-lipgloss.Width(sel)-lipgloss.Width(right)-6, 10)
+	left := sel + icon + " " + pathStyle.Render(parser.Truncate(relPath(e.path, m.sessionCwd), room))
+	return spaceBetween(left, right, width)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestBuildExpectedFiles(t *testing.T) {
+	edit := func(path, old, new string) displayItem {
+		return displayItem{itemType: parser.ItemToolCall, toolName: "Edit", toolCategory: parser.CategoryEdit,
+			toolInput: `{"file_path": "` + path + `", "old_string": ` + strconv.Quote(old) + `, "new_string": ` + strconv.Quote(new) + `}`}
+	}
+	helper := &parser.SubagentProcess{
+		ID:           "a1b2c3d4e5",
+		SubagentType: "general-purpose",
+		Chunks: []parser.Chunk{
+			{Type: parser.AIChunk, Items: []parser.DisplayItem{
+				{Type: parser.ItemToolCall, ToolName: "Edit", ToolCategory: parser.CategoryEdit, ToolInput: []byte(`{"file_path":"/repo/util.go","old_string":"a","new_string":"b"}`)},
+			}},
+		},
+	}
+	failed := edit("/repo/main.go", "x", "y")
+	failed.toolError = true
+	msgs := []message{
+		userMsg("go"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Write", toolCategory: parser.CategoryWrite, toolInput: `{"file_path": "main.go", "content": "one\ntwo two\n"}`},
+				edit("/repo/main.go", "two", "2"),
+				failed,
+				{itemType: parser.ItemToolCall, toolName: "MultiEdit", toolCategory: parser.CategoryEdit,
+					toolInput: `{"file_path": "/repo/main.go", "edits": [{"old_string": "one", "new_string": "1"}, {"old_string": "two", "new_string": "3", "replace_all": true}]}`},
+				{itemType: parser.ItemSubagent, subagentProcess: helper},
+			}
+		}),
+	}
+
+	files := buildExpectedFiles(msgs, "/repo")
+	if len(files) != 2 {
+		t.Fatalf("len(files) = %d, want 2: %+v", len(files), files)
+	}
+	mainGo := files[0]
+	if mainGo.path != "/repo/main.go" || !mainGo.known || mainGo.content != "1\n2 3\n" || mainGo.changes != 3 {
+		t.Errorf("main.go = %+v, want known content %q after 3 changes", mainGo, "1\n2 3\n")
+	}
+	util := files[1]
+	if util.known || util.fragment != "b" || util.agent != agentLabel(helper) {
+		t.Errorf("util.go = %+v, want fragment %q by %s", util, "b", agentLabel(helper))
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	synced := write("synced.go", "a\nb\n")
+	changed := write("changed.go", "a\nB\nc\n")
+	edited := write("edited.go", "func main() {}\n")
+	gone := filepath.Join(dir, "gone.go")
+
+	entries := checkDrift([]expectedFile{
+		{path: synced, content: "a\nb\n", known: true},
+		{path: changed, content: "a\nb\n", known: true},
+		{path: edited, fragment: "func run() {}"},
+		{path: gone, fragment: "x"},
+	})
+
+	want := map[string]struct {
+		status driftStatus
+		detail string
+	}{
+		synced:  {driftInSync, ""},
+		changed: {driftChanged, "+2 -1 lines"},
+		edited:  {driftChanged, "edited text not found"},
+		gone:    {driftMissing, "deleted since"},
+	}
+	for _, e := range entries {
+		w := want[e.path]
+		if e.status != w.status || e.detail != w.detail {
+			t.Errorf("%s: status %d %q, want %d %q", filepath.Base(e.path), e.status, e.detail, w.status, w.detail)
+		}
+	}
+	if last := entries[len(entries)-1]; last.path != synced {
+		t.Errorf("last entry = %s, want in-sync file sorted last", last.path)
+	}
+}
+
+func TestUpdateDrift_OpenAndBack(t *testing.T) {
+	m := testModel()
+	result, cmd := m.Update(key("D"))
+	m = asModel(result)
+	if m.view != viewDrift {
+		t.Fatalf("view = %d, want viewDrift", m.view)
+	}
+	if cmd == nil {
+		t.Fatal("opening the drift view should start a disk check")
+	}
+	if _, ok := cmd().(driftCheckedMsg); !ok {
+		t.Errorf("cmd() = %T, want driftCheckedMsg", cmd())
+	}
+	result, _ = m.Update(key("q"))
+	if asModel(result).view != viewList {
+		t.Errorf("q should return to the list view")
+	}
+}
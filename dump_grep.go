@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/filter"
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// dumpGrepLineMax caps the width of a matching line in --grep output.
+const dumpGrepLineMax = 300
+
+// dumpGrepMatch is one line of a message that matched --grep.
+type dumpGrepMatch struct {
+	source string // "You", "Bash", "Thinking", "Bash (Explore a1b2c3d)"
+	line   string
+}
+
+// grepMessageLines returns the lines of msg that match re: its content, or
+// for a Claude turn, its items' text, summaries, inputs, and results, with
+// subagent traces searched too.
+func grepMessageLines(msg message, re *regexp.Regexp) []dumpGrepMatch {
+	if len(msg.items) == 0 {
+		return grepLines(msg.content, roleLabel(msg.role), re)
+	}
+	var out []dumpGrepMatch
+	for _, item := range msg.items {
+		out = append(out, grepItemLines(item, "", re)...)
+	}
+	return out
+}
+
+// grepItemLines matches an item's fields and, for a subagent, its trace.
+func grepItemLines(item displayItem, agent string, re *regexp.Regexp) []dumpGrepMatch {
+	source := searchSourceLabel(item)
+	if agent != "" {
+		source += " (" + agent + ")"
+	}
+	var out []dumpGrepMatch
+	for _, field := range []string{item.text, item.subagentDesc, item.toolInput, item.toolResult} {
+		out = append(out, grepLines(field, source, re)...)
+	}
+	if item.subagentProcess != nil {
+		label := agentLabel(item.subagentProcess)
+		for _, child := range buildTraceItems(item) {
+			out = append(out, grepItemLines(child, label, re)...)
+		}
+	}
+	return out
+}
+
+// grepLines returns the lines of text that match re, trimmed.
+func grepLines(text, source string, re *regexp.Regexp) []dumpGrepMatch {
+	if text == "" || !re.MatchString(text) {
+		return nil
+	}
+	var out []dumpGrepMatch
+	for _, line := range strings.Split(text, "\n") {
+		if re.MatchString(line) {
+			out = append(out, dumpGrepMatch{source: source, line: parser.Truncate(strings.TrimSpace(line), dumpGrepLineMax)})
+		}
+	}
+	if len(out) == 0 {
+		// The pattern spans lines: report the text's first line.
+		first, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+		out = append(out, dumpGrepMatch{source: source, line: parser.Truncate(first, dumpGrepLineMax)})
+	}
+	return out
+}
+
+// writeDumpGrep prints each message of the session that matches re: a
+// metadata line, "path:turn: Role time model", then its matching lines as
+// "  Source: line". Messages outside expr (nil for none) are skipped.
+// Reports whether anything matched, for the exit status.
+func writeDumpGrep(w io.Writer, path string, msgs []message, re *regexp.Regexp, expr filter.Expr) bool {
+	matched := false
+	for i, msg := range msgs {
+		if expr != nil && !expr.Match(messageRecord{&msgs[i]}) {
+			continue
+		}
+		lines := grepMessageLines(msg, re)
+		if len(lines) == 0 {
+			continue
+		}
+		matched = true
+		meta := []string{roleLabel(msg.role)}
+		if msg.timestamp != "" {
+			meta = append(meta, msg.timestamp)
+		}
+		if msg.model != "" {
+			meta = append(meta, msg.model)
+		}
+		fmt.Fprintf(w, "%s:%d: %s\n", path, promptNumber(msgs, i), strings.Join(meta, " "))
+		for _, l := range lines {
+			fmt.Fprintf(w, "  %s: %s\n", l.source, l.line)
+		}
+	}
+	return matched
+}
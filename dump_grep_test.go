@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestWriteDumpGrep(t *testing.T) {
+	msgs := []message{
+		userMsg("Format the code\nthen run the tests"),
+		claudeMsg(func(m *message) {
+			m.timestamp = "10:00:05 AM"
+			m.model = "opus4.6"
+			m.items = []displayItem{
+				{itemType: parser.ItemThinking, text: "I should format first."},
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: "{\n  \"command\": \"gofmt -w .\"\n}", toolResult: ""},
+				{itemType: parser.ItemOutput, text: "Done."},
+			}
+		}),
+		userMsg("Thanks"),
+	}
+
+	var out bytes.Buffer
+	if !writeDumpGrep(&out, "s.jsonl", msgs, regexp.MustCompile(`gofmt`), nil) {
+		t.Fatal("want a match")
+	}
+	want := "s.jsonl:1: Claude 10:00:05 AM opus4.6\n" +
+		"  Bash: \"command\": \"gofmt -w .\"\n"
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writeDumpGrep(&out, "s.jsonl", msgs, regexp.MustCompile(`(?i)^(format|thanks)`), nil)
+	want = "s.jsonl:1: You 10:00:00 AM\n" +
+		"  You: Format the code\n" +
+		"s.jsonl:2: You 10:00:00 AM\n" +
+		"  You: Thanks\n"
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	// A pattern spanning lines reports the text's first line.
+	out.Reset()
+	writeDumpGrep(&out, "s.jsonl", msgs[:1], regexp.MustCompile(`code\nthen`), nil)
+	if want := "s.jsonl:1: You 10:00:00 AM\n  You: Format the code\n"; out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if writeDumpGrep(&out, "s.jsonl", msgs, regexp.MustCompile(`prettier`), nil) || out.Len() != 0 {
+		t.Errorf("no match: wrote %q", out.String())
+	}
+}
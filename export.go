@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// exportDir is where picker exports are written, relative to the working
+// directory tail-claude was started from.
+const exportDir = "tail-claude-export"
+
+// Session export formats.
+const (
+	exportMarkdown = "md"
+	exportJSON     = "json"
+)
+
+// sessionsExportedMsg reports the result of a picker export.
+type sessionsExportedMsg struct {
+	count int
+	dir   string
+	err   error
+}
+
+// exportSessionsCmd loads each session and writes it to dir in format, one
+// file per session named after the session file. Sessions are parsed in
+// parallel and each is written as soon as it loads, so only the ones being
+// exported are held in memory. The first failure, or ctx ending, stops the
+// rest; count is the sessions written by then.
+func exportSessionsCmd(ctx context.Context, paths []string, format, dir string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return sessionsExportedMsg{dir: dir, err: err}
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var (
+			mu      sync.Mutex
+			count   int
+			failure error
+		)
+		err := parser.ForEachParallel(ctx, len(paths), parser.ScanWorkers, func(i int) {
+			err := exportSession(paths[i], format, dir)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if failure == nil {
+					failure = err
+					cancel()
+				}
+				return
+			}
+			count++
+		})
+		if failure != nil {
+			err = failure
+		}
+		return sessionsExportedMsg{count: count, dir: dir, err: err}
+	}
+}
+
+// exportSession loads the session at path and writes it to dir in format.
+func exportSession(path, format, dir string) error {
+	result, err := loadSession(path)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	var data []byte
+	switch format {
+	case exportJSON:
+		data, err = sessionJSON(name, result.meta.Cwd, result.messages, result.todos)
+		if err != nil {
+			return err
+		}
+	default:
+		data = []byte(sessionMarkdown(name, result.meta.Cwd, result.messages, result.todos))
+	}
+	return os.WriteFile(filepath.Join(dir, name+"."+format), data, 0o644)
+}
+
+// sessionMarkdown renders a session transcript as Markdown: the final
+// answer first, then one section per message, with Claude's tool calls
+// listed as bullets, and the todos left unfinished last.
+func sessionMarkdown(name, cwd string, msgs []message, todos []parser.Todo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Session %s\n", name)
+	if cwd != "" {
+		fmt.Fprintf(&b, "\n`%s`\n", cwd)
+	}
+	if answer := finalAnswerText(msgs); answer != "" {
+		b.WriteString("\n## Final answer\n\n" + answer + "\n\n---\n")
+	}
+	writeMessagesMarkdown(&b, msgs)
+	if todo := todoMarkdown(todos); todo != "" {
+		b.WriteString("\n" + todo)
+	}
+	return b.String()
+}
+
+// writeMessagesMarkdown writes one Markdown section per message, with
+// Claude's outputs, cited references, queued prompts, and tool calls.
+func writeMessagesMarkdown(b *strings.Builder, msgs []message) {
+	for _, msg := range msgs {
+		b.WriteString("\n## " + exportHeading(msg) + "\n\n")
+		if msg.role == RoleCompact {
+			b.WriteString(compactLabel(msg) + "\n")
+			continue
+		}
+		if msg.role != RoleClaude || len(msg.items) == 0 {
+			if body := strings.TrimSpace(msg.content); body != "" {
+				b.WriteString(body + "\n")
+			}
+			continue
+		}
+		for _, item := range msg.items {
+			switch item.itemType {
+			case parser.ItemOutput:
+				if text := strings.TrimSpace(item.text); text != "" {
+					b.WriteString(text + "\n\n")
+				}
+				if refs := referencesMarkdown(item.references); refs != "" {
+					b.WriteString(refs + "\n")
+				}
+			case parser.ItemQueuedMessage:
+				fmt.Fprintf(b, "> **Queued:** %s\n\n", strings.Join(strings.Fields(item.text), " "))
+			case parser.ItemToolCall, parser.ItemSubagent:
+				fmt.Fprintf(b, "- `%s` %s", exportToolName(item), item.toolSummary)
+				if item.toolError {
+					b.WriteString(" (error)")
+				}
+				b.WriteString("\n\n")
+			}
+		}
+	}
+}
+
+// exportHeading names a message's section: "You 10:04:12 AM",
+// "Claude opus4.6 10:04:20 AM", "/review src/".
+func exportHeading(msg message) string {
+	var parts []string
+	switch msg.role {
+	case RoleClaude:
+		parts = append(parts, "Claude", msg.model)
+	case RoleCommand:
+		parts = append(parts, msg.command)
+	case RoleCompact:
+		parts = append(parts, "Compacted")
+	case RoleModel:
+		parts = append(parts, "Model")
+	case RoleMode:
+		parts = append(parts, "Permission mode")
+	default:
+		parts = append(parts, roleLabel(msg.role))
+	}
+	parts = append(parts, msg.timestamp)
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// exportToolName returns the tool name, or the subagent type for Task items.
+func exportToolName(item displayItem) string {
+	if item.itemType == parser.ItemSubagent && item.subagentType != "" {
+		return item.subagentType
+	}
+	return item.toolName
+}
+
+// exportedSession is the JSON form of a session export.
+type exportedSession struct {
+	Session  string            `json:"session"`
+	Cwd      string            `json:"cwd,omitempty"`
+	Answer   string            `json:"answer,omitempty"` // the final answer, as z jumps to it
+	Messages []exportedMessage `json:"messages"`
+	Todos    []parser.Todo     `json:"todos,omitempty"` // the final TodoWrite list, finished items included
+}
+
+// exportedMessage is one message in a JSON export.
+type exportedMessage struct {
+	Role       string             `json:"role"`
+	Model      string             `json:"model,omitempty"`
+	Timestamp  string             `json:"timestamp,omitempty"`
+	Command    string             `json:"command,omitempty"`
+	Content    string             `json:"content,omitempty"`
+	Tokens     int                `json:"tokens,omitempty"`
+	Tools      []exportedTool     `json:"tools,omitempty"`
+	References []parser.Reference `json:"references,omitempty"` // sources the message's text cites
+}
+
+// exportedTool is one tool call or subagent in a JSON export.
+type exportedTool struct {
+	Name    string `json:"name"`
+	Summary string `json:"summary,omitempty"`
+	Error   bool   `json:"error,omitempty"`
+}
+
+// sessionJSON renders a session transcript as indented JSON, with todos,
+// the session's last TodoWrite list.
+func sessionJSON(name, cwd string, msgs []message, todos []parser.Todo) ([]byte, error) {
+	out := exportedSession{Session: name, Cwd: cwd, Answer: finalAnswerText(msgs), Messages: make([]exportedMessage, 0, len(msgs))}
+	for _, msg := range msgs {
+		em := exportedMessage{
+			Role:       msg.role,
+			Model:      msg.model,
+			Timestamp:  msg.timestamp,
+			Command:    msg.command,
+			Content:    msg.content,
+			Tokens:     msg.tokensRaw,
+			References: messageReferences(msg),
+		}
+		for _, item := range msg.items {
+			if item.itemType != parser.ItemToolCall && item.itemType != parser.ItemSubagent {
+				continue
+			}
+			em.Tools = append(em.Tools, exportedTool{
+				Name:    exportToolName(item),
+				Summary: item.toolSummary,
+				Error:   item.toolError,
+			})
+		}
+		out.Messages = append(out.Messages, em)
+	}
+	out.Todos = todos
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
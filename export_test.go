@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func exportMsgs() []message {
+	return []message{
+		userMsg("fix the build"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemThinking, text: "hmm"},
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolSummary: "go build ./...", toolError: true},
+				{itemType: parser.ItemSubagent, toolName: "Task", subagentType: "Explore", toolSummary: "find callers"},
+				{itemType: parser.ItemOutput, text: "Fixed.", references: []parser.Reference{
+					{Title: "Go modules", URL: "https://go.dev/ref/mod", CitedText: "go.sum lists hashes"},
+				}},
+			}
+		}),
+		{role: RoleCommand, command: "/review src/", timestamp: "10:01:00 AM"},
+	}
+}
+
+func TestSessionMarkdown(t *testing.T) {
+	md := sessionMarkdown("abc123", "/repo", exportMsgs(), nil)
+	for _, want := range []string{
+		"# Session abc123",
+		"`/repo`",
+		"## You 10:00:00 AM\n\nfix the build",
+		"## Claude opus4.6 10:00:00 AM",
+		"- `Bash` go build ./... (error)",
+		"- `Explore` find callers",
+		"Fixed.",
+		"References:\n\n1. [Go modules](https://go.dev/ref/mod) — \"go.sum lists hashes\"",
+		"## /review src/ 10:01:00 AM",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "hmm") {
+		t.Error("thinking should not be exported")
+	}
+	if !strings.HasPrefix(md, "# Session abc123\n\n`/repo`\n\n## Final answer\n\nFixed.\n") {
+		t.Errorf("markdown should open with the final answer:\n%s", md)
+	}
+}
+
+func TestSessionJSON(t *testing.T) {
+	data, err := sessionJSON("abc123", "/repo", exportMsgs(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got exportedSession
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Session != "abc123" || got.Answer != "Fixed." || len(got.Messages) != 3 {
+		t.Fatalf("got %+v", got)
+	}
+	tools := got.Messages[1].Tools
+	if len(tools) != 2 || tools[0].Name != "Bash" || !tools[0].Error || tools[1].Name != "Explore" {
+		t.Errorf("tools = %+v", tools)
+	}
+	if refs := got.Messages[1].References; len(refs) != 1 || refs[0].URL != "https://go.dev/ref/mod" {
+		t.Errorf("references = %+v", refs)
+	}
+}
+
+func TestExportSessionsCmd(t *testing.T) {
+	fixture := filepath.Join("parser", "testdata", "minimal.jsonl")
+	dir := filepath.Join(t.TempDir(), "out")
+
+	msg := exportSessionsCmd(context.Background(), []string{fixture}, exportMarkdown, dir)().(sessionsExportedMsg)
+	if msg.err != nil || msg.count != 1 {
+		t.Fatalf("msg = %+v", msg)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "minimal.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# Session minimal") {
+		t.Errorf("export = %q", data)
+	}
+
+	msg = exportSessionsCmd(context.Background(), []string{"/nonexistent.jsonl"}, exportJSON, dir)().(sessionsExportedMsg)
+	if msg.err == nil {
+		t.Error("missing session should report an error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	msg = exportSessionsCmd(ctx, []string{fixture}, exportJSON, dir)().(sessionsExportedMsg)
+	if !errors.Is(msg.err, context.Canceled) || msg.count != 0 {
+		t.Errorf("msg = %+v, want a cancelled export with nothing written", msg)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// mainAgentLabel attributes file activity to the parent session.
+const mainAgentLabel = "main"
+
+// fileActivity tallies what the session and its subagents did to one file.
+type fileActivity struct {
+	path   string
+	reads  int
+	edits  int
+	writes int
+	agents []string // who touched the file, in first-touch order
+}
+
+// touched reports whether the file was modified (edited or written).
+func (f fileActivity) touched() bool {
+	return f.edits+f.writes > 0
+}
+
+// buildFileReport collects every file read, edited, or written across the
+// messages and all linked subagent traces (nested ones included). Modified
+// files sort first, then by path.
+func buildFileReport(msgs []message) []fileActivity {
+	byPath := make(map[string]*fileActivity)
+	var order []string
+
+	var visit func(items []displayItem, agent string)
+	visit = func(items []displayItem, agent string) {
+		for _, item := range items {
+			if item.subagentProcess != nil {
+				visit(buildTraceItems(item), agentLabel(item.subagentProcess))
+			}
+			if item.itemType != parser.ItemToolCall {
+				continue
+			}
+			switch item.toolCategory {
+			case parser.CategoryRead, parser.CategoryEdit, parser.CategoryWrite:
+			default:
+				continue
+			}
+			path := parser.ToolFilePath(json.RawMessage(item.toolInput))
+			if path == "" {
+				continue
+			}
+			fa := byPath[path]
+			if fa == nil {
+				fa = &fileActivity{path: path}
+				byPath[path] = fa
+				order = append(order, path)
+			}
+			switch item.toolCategory {
+			case parser.CategoryRead:
+				fa.reads++
+			case parser.CategoryEdit:
+				fa.edits++
+			case parser.CategoryWrite:
+				fa.writes++
+			}
+			if !slices.Contains(fa.agents, agent) {
+				fa.agents = append(fa.agents, agent)
+			}
+		}
+	}
+	for _, msg := range msgs {
+		visit(msg.items, mainAgentLabel)
+	}
+
+	files := make([]fileActivity, 0, len(order))
+	for _, path := range order {
+		files = append(files, *byPath[path])
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].touched() != files[j].touched() {
+			return files[i].touched()
+		}
+		return files[i].path < files[j].path
+	})
+	return files
+}
+
+// relPath shows path relative to cwd when it lives underneath it.
+func relPath(path, cwd string) string {
+	if cwd == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// fileReportMarkdown renders the report as a Markdown table for code review.
+func fileReportMarkdown(files []fileActivity, title, cwd string) string {
+	var read, modified int
+	agents := make(map[string]bool)
+	for _, f := range files {
+		if f.touched() {
+			modified++
+		}
+		if f.reads > 0 {
+			read++
+		}
+		for _, a := range f.agents {
+			agents[a] = true
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Files touched: %s\n\n", title)
+	if len(files) == 0 {
+		b.WriteString("No file reads or edits in this session.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%s read, %s edited or written, by %s.\n\n",
+		parser.Plural(read, "file"), parser.Plural(modified, "file"), parser.Plural(len(agents), "agent"))
+	b.WriteString("| File | Reads | Edits | Writes | Agents |\n")
+	b.WriteString("|------|------:|------:|-------:|--------|\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "| `%s` | %d | %d | %d | %s |\n",
+			relPath(f.path, cwd), f.reads, f.edits, f.writes, strings.Join(f.agents, ", "))
+	}
+	return b.String()
+}
+
+// fileReportTitle names the session in the report heading.
+func (m model) fileReportTitle() string {
+	if m.sessionPath == "" {
+		return "session"
+	}
+	return strings.TrimSuffix(filepath.Base(m.sessionPath), ".jsonl")
+}
+
+// updateFiles handles key events in the file report view.
+func (m model) updateFiles(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	files := buildFileReport(m.rawMessages)
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "F":
+		m.view = viewList
+	case "j", "down":
+		if m.filesCursor < len(files)-1 {
+			m.filesCursor++
+		}
+		m.ensureFilesVisible()
+	case "k", "up":
+		if m.filesCursor > 0 {
+			m.filesCursor--
+		}
+		m.ensureFilesVisible()
+	case "G":
+		m.filesCursor = max(len(files)-1, 0)
+		m.ensureFilesVisible()
+	case "g":
+		m.filesCursor = 0
+		m.filesScroll = 0
+	case "y":
+		// Copy the Markdown report for pasting into a review.
+		report := fileReportMarkdown(files, m.fileReportTitle(), m.sessionCwd)
+		m.flashStatus = fmt.Sprintf("Copied file report (%s)", parser.Plural(len(files), "file"))
+		return m, tea.Batch(tea.SetClipboard(report), flashClearCmd())
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// filesViewHeight returns the visible report rows (minus header and footer).
+func (m model) filesViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// ensureFilesVisible adjusts filesScroll so the cursor row is visible.
+func (m *model) ensureFilesVisible() {
+	m.filesScroll = scrollToShow(m.filesScroll, m.filesCursor, 1, m.filesViewHeight())
+}
+
+// viewFiles renders the file report: one row per file with counts and agents.
+func (m model) viewFiles() string {
+	width := m.clampWidth()
+	files := buildFileReport(m.rawMessages)
+
+	modified := 0
+	for _, f := range files {
+		if f.touched() {
+			modified++
+		}
+	}
+	header := StyleAccentBold.Render("Files") + " " +
+		StyleDim.Render(fmt.Sprintf("(%d files, %d modified)", len(files), modified)) + "\n"
+
+	var lines []string
+	for i, f := range files {
+		lines = append(lines, m.renderFileRow(f, i == m.filesCursor, width))
+	}
+
+	content := header
+	if len(files) == 0 {
+		content += "\n" + StyleDim.Render("No file reads or edits in this session.")
+	} else {
+		content += "\n" + strings.Join(scrollWindow(lines, m.filesViewHeight(), m.filesScroll), "\n")
+	}
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"j/k", "nav",
+		"y", "copy markdown",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
+
+// renderFileRow renders "{sel} {icon} path    2 read 1 edit  main, Explore a1b2c3d".
+func (m model) renderFileRow(f fileActivity, isSelected bool, width int) string {
+	sel := selectionIndicator(isSelected)
+	icon := Icon.Tool.Read.Render()
+	if f.touched() {
+		icon = Icon.Tool.Edit.Render()
+	}
+
+	var counts []string
+	if f.reads > 0 {
+		counts = append(counts, fmt.Sprintf("%d read", f.reads))
+	}
+	if f.edits > 0 {
+		counts = append(counts, fmt.Sprintf("%d edit", f.edits))
+	}
+	if f.writes > 0 {
+		counts = append(counts, fmt.Sprintf("%d write", f.writes))
+	}
+	right := StyleDim.Render(strings.Join(counts, " ")) + "  " +
+		StyleMuted.Render(strings.Join(f.agents, ", "))
+
+	pathStyle := StyleSecondary
+	if isSelected {
+		pathStyle = StylePrimaryBold
+	}
+	room := max(width-lipgloss.Width(sel)-lipgloss.Width(right)-6, 10)
+	left := sel + icon + " " + pathStyle.Render(parser.Truncate(relPath(f.path, m.sessionCwd), room))
+	return spaceBetween(left, right, width)
+}
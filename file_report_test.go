@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func fileReportMsgs() []message {
+	explore := &parser.SubagentProcess{
+		ID:           "a1b2c3d4e5",
+		SubagentType: "Explore",
+		Chunks: []parser.Chunk{
+			{Type: parser.AIChunk, Items: []parser.DisplayItem{
+				{Type: parser.ItemToolCall, ToolName: "Read", ToolCategory: parser.CategoryRead, ToolInput: []byte(`{"file_path":"/repo/main.go"}`)},
+				{Type: parser.ItemToolCall, ToolName: "Read", ToolCategory: parser.CategoryRead, ToolInput: []byte(`{"file_path":"/repo/util.go"}`)},
+			}},
+		},
+	}
+	return []message{
+		userMsg("fix it"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Read", toolCategory: parser.CategoryRead, toolInput: `{"file_path": "/repo/main.go"}`},
+				{itemType: parser.ItemSubagent, subagentType: "Explore", subagentProcess: explore},
+				{itemType: parser.ItemToolCall, toolName: "Edit", toolCategory: parser.CategoryEdit, toolInput: `{"file_path": "/repo/main.go"}`},
+				{itemType: parser.ItemToolCall, toolName: "Write", toolCategory: parser.CategoryWrite, toolInput: `{"file_path": "/repo/new.go"}`},
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolCategory: parser.CategoryBash, toolInput: `{"command": "ls"}`},
+			}
+		}),
+	}
+}
+
+func TestBuildFileReport(t *testing.T) {
+	files := buildFileReport(fileReportMsgs())
+	if len(files) != 3 {
+		t.Fatalf("len(files) = %d, want 3: %+v", len(files), files)
+	}
+
+	// Modified files first, then by path.
+	wantOrder := []string{"/repo/main.go", "/repo/new.go", "/repo/util.go"}
+	for i, want := range wantOrder {
+		if files[i].path != want {
+			t.Errorf("files[%d].path = %q, want %q", i, files[i].path, want)
+		}
+	}
+
+	main := files[0]
+	if main.reads != 2 || main.edits != 1 || main.writes != 0 {
+		t.Errorf("main.go counts = %d/%d/%d, want 2/1/0", main.reads, main.edits, main.writes)
+	}
+	if strings.Join(main.agents, ",") != "main,Explore a1b2c3d" {
+		t.Errorf("main.go agents = %v, want [main Explore a1b2c3d]", main.agents)
+	}
+	if files[1].writes != 1 || !files[1].touched() {
+		t.Errorf("new.go = %+v, want one write", files[1])
+	}
+	if files[2].touched() {
+		t.Error("util.go was only read")
+	}
+}
+
+func TestFileReportMarkdown(t *testing.T) {
+	md := fileReportMarkdown(buildFileReport(fileReportMsgs()), "abc123", "/repo")
+	for _, want := range []string{
+		"# Files touched: abc123",
+		"2 files read",
+		"2 files edited or written, by 2 agents.",
+		"| `main.go` | 2 | 1 | 0 | main, Explore a1b2c3d |",
+		"| `new.go` | 0 | 0 | 1 | main |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	empty := fileReportMarkdown(nil, "abc123", "")
+	if !strings.Contains(empty, "No file reads or edits") {
+		t.Errorf("empty report = %q", empty)
+	}
+}
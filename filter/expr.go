@@ -0,0 +1,456 @@
+// Package filter parses the filter expressions the list's filter bar and
+// --filter accept:
+//
+//	role=claude AND tool=Bash AND error
+//	(tool=Edit OR tool=Write) AND NOT agent=Explore
+//	tokens>20k OR duration>=2m
+//
+// A term is a flag ("error") or a comparison of a field with a value:
+// = (case-insensitive, * matches any run of characters), != (no value
+// matches), ~ (contains), and >, >=, <, <= (numbers; a "k" suffix means
+// thousands, and durations like 90s compare as milliseconds). Terms combine
+// with NOT, AND, and OR, in that order of precedence, and parentheses; AND
+// may be left out between terms. Keywords are case-insensitive, and "!" is
+// NOT. Values with spaces or parentheses go in double quotes.
+//
+// The caller names its fields and flags in a Vocabulary and answers for
+// them through a Record; the package knows nothing about sessions.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Vocabulary lists the fields and flags expressions may use. Parse rejects
+// any other name.
+type Vocabulary struct {
+	Fields []string // compared with a value: "tool=Bash"
+	Flags  []string // bare words: "error"
+}
+
+// Record is what an expression is matched against.
+type Record interface {
+	// Field returns the field's values: every tool a message called, say.
+	// A comparison holds when any value satisfies it (!= when none does).
+	Field(name string) []string
+	// Flag reports whether a flag holds.
+	Flag(name string) bool
+}
+
+// Expr is a parsed filter expression.
+type Expr interface {
+	// Match reports whether r satisfies the expression.
+	Match(r Record) bool
+	// String returns the expression in canonical form: keywords upper
+	// case, AND written out, parentheses only where needed.
+	String() string
+}
+
+// Parse parses src against vocab. Errors name the offending token and its
+// 1-based column.
+func Parse(src string, vocab Vocabulary) (Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks, vocab: vocab}
+	if p.peek().kind == tokEOF {
+		return nil, fmt.Errorf("empty expression")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("col %d: unexpected %q", t.pos+1, t.text)
+	}
+	return e, nil
+}
+
+// And joins exprs with AND, leaving out nil ones. It returns nil when none
+// is left, so a caller can add terms to an optional filter.
+func And(exprs ...Expr) Expr {
+	var out Expr
+	for _, e := range exprs {
+		switch {
+		case e == nil:
+		case out == nil:
+			out = e
+		default:
+			out = andExpr{out, e}
+		}
+	}
+	return out
+}
+
+// Excluding returns field!=value for each of values, joined with AND: the
+// terms a hidden-set toggle stands for. It returns nil for no values.
+func Excluding(field string, values ...string) Expr {
+	exprs := make([]Expr, len(values))
+	for i, v := range values {
+		exprs[i] = cmpExpr{field: field, op: "!=", value: v}
+	}
+	return And(exprs...)
+}
+
+// -- Lexer --------------------------------------------------------------------
+
+type tokKind int
+
+const (
+	tokEOF    tokKind = iota
+	tokWord           // name, value, or keyword
+	tokOp             // = != ~ > >= < <=
+	tokLParen         // (
+	tokRParen         // )
+	tokNot            // !
+)
+
+type token struct {
+	kind   tokKind
+	text   string
+	pos    int  // byte offset in the source
+	quoted bool // a "..." value: never a keyword
+}
+
+// opChars start a comparison operator.
+const opChars = "=!~<>"
+
+// lex splits src into tokens. A word right after an operator is a value and
+// runs to the next space or parenthesis, operator characters included.
+func lex(src string) ([]token, error) {
+	var toks []token
+	afterOp := false
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+			continue
+		case c == '(':
+			toks = append(toks, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			toks = append(toks, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case c == '"':
+			val, n, err := lexQuoted(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("col %d: %v", i+1, err)
+			}
+			toks = append(toks, token{kind: tokWord, text: val, pos: i, quoted: true})
+			i += n
+		case !afterOp && strings.IndexByte(opChars, c) >= 0:
+			op := string(c)
+			if i+1 < len(src) && src[i+1] == '=' && c != '=' && c != '~' {
+				op += "="
+			}
+			if op == "!" {
+				toks = append(toks, token{kind: tokNot, text: "!", pos: i})
+				i++
+				continue
+			}
+			toks = append(toks, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+			afterOp = true
+			continue
+		default:
+			j := i
+			for j < len(src) && !strings.ContainsRune(" \t\n()\"", rune(src[j])) &&
+				(afterOp || strings.IndexByte(opChars, src[j]) < 0) {
+				j++
+			}
+			toks = append(toks, token{kind: tokWord, text: src[i:j], pos: i})
+			i = j
+		}
+		afterOp = false
+	}
+	return append(toks, token{kind: tokEOF, text: "end of expression", pos: len(src)}), nil
+}
+
+// lexQuoted reads a double-quoted value at the start of s, with \" and \\
+// escapes, and returns it and the bytes consumed.
+func lexQuoted(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quote")
+}
+
+// -- Parser -------------------------------------------------------------------
+
+type exprParser struct {
+	toks  []token
+	i     int
+	vocab Vocabulary
+}
+
+func (p *exprParser) peek() token { return p.toks[p.i] }
+
+func (p *exprParser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// keyword reports whether t is the unquoted keyword kw, in any case.
+func keyword(t token, kw string) bool {
+	return t.kind == tokWord && !t.quoted && strings.EqualFold(t.text, kw)
+}
+
+func (p *exprParser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for keyword(p.peek(), "OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case keyword(t, "AND"):
+			p.next()
+		case t.kind == tokEOF || t.kind == tokRParen || keyword(t, "OR"):
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+}
+
+func (p *exprParser) parseUnary() (Expr, error) {
+	if t := p.peek(); t.kind == tokNot || keyword(t, "NOT") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokLParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != tokRParen {
+			return nil, fmt.Errorf("col %d: want ) before %q", r.pos+1, r.text)
+		}
+		return e, nil
+	case t.kind != tokWord || t.quoted || keyword(t, "AND") || keyword(t, "OR"):
+		return nil, fmt.Errorf("col %d: want a field or flag, not %q", t.pos+1, t.text)
+	}
+
+	name := strings.ToLower(t.text)
+	if p.peek().kind != tokOp {
+		if !contains(p.vocab.Flags, name) {
+			if contains(p.vocab.Fields, name) {
+				return nil, fmt.Errorf("col %d: %s needs a comparison, e.g. %s=...", t.pos+1, name, name)
+			}
+			return nil, fmt.Errorf("col %d: unknown flag %q (flags: %s)", t.pos+1, t.text, strings.Join(p.vocab.Flags, ", "))
+		}
+		return flagExpr{name}, nil
+	}
+	if !contains(p.vocab.Fields, name) {
+		return nil, fmt.Errorf("col %d: unknown field %q (fields: %s)", t.pos+1, t.text, strings.Join(p.vocab.Fields, ", "))
+	}
+	op := p.next()
+	v := p.next()
+	if v.kind != tokWord {
+		return nil, fmt.Errorf("col %d: %s%s needs a value", op.pos+1, name, op.text)
+	}
+	c := cmpExpr{field: name, op: op.text, value: v.text}
+	if c.numeric() {
+		n, ok := parseNumber(v.text)
+		if !ok {
+			return nil, fmt.Errorf("col %d: %s%s needs a number or duration, not %q", v.pos+1, name, op.text, v.text)
+		}
+		c.num = n
+	}
+	return c, nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNumber parses "1500", "2.5k" (thousands), or a Go duration such as
+// "90s" (as milliseconds).
+func parseNumber(s string) (float64, bool) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n, true
+	}
+	if k, ok := strings.CutSuffix(strings.ToLower(s), "k"); ok {
+		if n, err := strconv.ParseFloat(k, 64); err == nil {
+			return n * 1000, true
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return float64(d.Milliseconds()), true
+	}
+	return 0, false
+}
+
+// -- Nodes --------------------------------------------------------------------
+
+type andExpr struct{ l, r Expr }
+
+func (e andExpr) Match(r Record) bool { return e.l.Match(r) && e.r.Match(r) }
+func (e andExpr) String() string      { return group(e.l) + " AND " + group(e.r) }
+
+type orExpr struct{ l, r Expr }
+
+func (e orExpr) Match(r Record) bool { return e.l.Match(r) || e.r.Match(r) }
+func (e orExpr) String() string      { return e.l.String() + " OR " + e.r.String() }
+
+type notExpr struct{ x Expr }
+
+func (e notExpr) Match(r Record) bool { return !e.x.Match(r) }
+func (e notExpr) String() string {
+	switch e.x.(type) {
+	case andExpr, orExpr:
+		return "NOT (" + e.x.String() + ")"
+	}
+	return "NOT " + e.x.String()
+}
+
+// group parenthesizes an OR operand of AND, which binds tighter.
+func group(e Expr) string {
+	if _, ok := e.(orExpr); ok {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+type flagExpr struct{ name string }
+
+func (e flagExpr) Match(r Record) bool { return r.Flag(e.name) }
+func (e flagExpr) String() string      { return e.name }
+
+type cmpExpr struct {
+	field, op, value string
+	num              float64 // the value, for numeric operators
+}
+
+// numeric reports whether the operator compares numbers.
+func (e cmpExpr) numeric() bool {
+	return e.op == ">" || e.op == ">=" || e.op == "<" || e.op == "<="
+}
+
+func (e cmpExpr) Match(r Record) bool {
+	values := r.Field(e.field)
+	if e.op == "!=" {
+		for _, v := range values {
+			if globMatch(strings.ToLower(e.value), strings.ToLower(v)) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if e.matchOne(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e cmpExpr) matchOne(v string) bool {
+	switch e.op {
+	case "=":
+		return globMatch(strings.ToLower(e.value), strings.ToLower(v))
+	case "~":
+		return strings.Contains(strings.ToLower(v), strings.ToLower(e.value))
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return false
+	}
+	switch e.op {
+	case ">":
+		return n > e.num
+	case ">=":
+		return n >= e.num
+	case "<":
+		return n < e.num
+	}
+	return n <= e.num
+}
+
+func (e cmpExpr) String() string {
+	value := e.value
+	if value == "" || strings.ContainsAny(value, " \t\n()\"") {
+		value = strconv.Quote(value)
+	}
+	return e.field + e.op + value
+}
+
+// globMatch reports whether s matches pattern, where * matches any run of
+// characters, including none.
+func globMatch(pattern, s string) bool {
+	star, mark := -1, 0
+	p, i := 0, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, i
+			p++
+		case p < len(pattern) && pattern[p] == s[i]:
+			p++
+			i++
+		case star >= 0:
+			p = star + 1
+			mark++
+			i = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,132 @@
+package filter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/filter"
+)
+
+var vocab = filter.Vocabulary{
+	Fields: []string{"role", "tool", "tokens", "text"},
+	Flags:  []string{"error"},
+}
+
+// record is a Record backed by maps.
+type record struct {
+	fields map[string][]string
+	flags  map[string]bool
+}
+
+func (r record) Field(name string) []string { return r.fields[name] }
+func (r record) Flag(name string) bool      { return r.flags[name] }
+
+func TestParseAndMatch(t *testing.T) {
+	bash := record{
+		fields: map[string][]string{"role": {"claude"}, "tool": {"Read", "Bash"}, "tokens": {"25000"}, "text": {"go test ./..."}},
+		flags:  map[string]bool{"error": true},
+	}
+	prompt := record{fields: map[string][]string{"role": {"user"}, "text": {"run the tests"}}}
+
+	tests := []struct {
+		src          string
+		bash, prompt bool
+	}{
+		{"role=claude AND tool=Bash AND error", true, false},
+		{"role=CLAUDE tool=bash error", true, false}, // implicit AND, case-insensitive
+		{"role=user OR tool=Bash", true, true},
+		{"NOT error", false, true},
+		{"!error", false, true},
+		{"tool!=Bash", false, true},
+		{"tool=B*", true, false},
+		{`text~"go test"`, true, false},
+		{"text~tests", false, true},
+		{"tokens>20k", true, false},
+		{"tokens<=25000 AND tokens>=25000", true, false},
+		{"tokens>1", true, false},
+		{"(role=user OR error) AND NOT tool=Read", false, true},
+		{"role=user or error and tool=Read", true, true}, // AND binds tighter
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := filter.Parse(tt.src, vocab)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := e.Match(bash); got != tt.bash {
+				t.Errorf("Match(bash) = %v, want %v", got, tt.bash)
+			}
+			if got := e.Match(prompt); got != tt.prompt {
+				t.Errorf("Match(prompt) = %v, want %v", got, tt.prompt)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"", "empty expression"},
+		{"tol=Bash", `col 1: unknown field "tol"`},
+		{"role=user AND broken", `col 15: unknown flag "broken"`},
+		{"tool", "tool needs a comparison"},
+		{"tool=", "tool= needs a value"},
+		{"tokens>lots", `needs a number or duration, not "lots"`},
+		{"(error", "want )"},
+		{"error)", `col 6: unexpected ")"`},
+		{"AND error", "want a field or flag"},
+		{`text~"open`, "unterminated quote"},
+	}
+	for _, tt := range tests {
+		_, err := filter.Parse(tt.src, vocab)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestExprString(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"role=claude tool=bash error", "role=claude AND tool=bash AND error"},
+		{"(role=user or error) and not (tool=Read or tool=Bash)", "(role=user OR error) AND NOT (tool=Read OR tool=Bash)"},
+		{`text~"go test"`, `text~"go test"`},
+	}
+	for _, tt := range tests {
+		e, err := filter.Parse(tt.src, vocab)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		if got := e.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		if _, err := filter.Parse(e.String(), vocab); err != nil {
+			t.Errorf("String() %q doesn't parse: %v", e.String(), err)
+		}
+	}
+}
+
+func TestAndExcluding(t *testing.T) {
+	if filter.And() != nil || filter.And(nil, nil) != nil || filter.Excluding("tool") != nil {
+		t.Error("no terms should give a nil expression")
+	}
+	user, err := filter.Parse("role=user", vocab)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := filter.And(nil, user, filter.Excluding("tool", "Read", "mcp tool"))
+	if got, want := e.String(), `role=user AND tool!=Read AND tool!="mcp tool"`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	for _, tt := range []struct {
+		r    record
+		want bool
+	}{
+		{record{fields: map[string][]string{"role": {"user"}}}, true},
+		{record{fields: map[string][]string{"role": {"user"}, "tool": {"Bash"}}}, true},
+		{record{fields: map[string][]string{"role": {"user"}, "tool": {"Bash", "read"}}}, false},
+		{record{fields: map[string][]string{"role": {"claude"}}}, false},
+	} {
+		if got := e.Match(tt.r); got != tt.want {
+			t.Errorf("Match(%v) = %v, want %v", tt.r.fields, got, tt.want)
+		}
+	}
+}
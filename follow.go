@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// defaultNowWindow is how recent a message must be for the now marker to
+// set it apart while tailing.
+const defaultNowWindow = 30 * time.Second
+
+// parseNowMarker parses the nowMarker config, e.g. "30s". Zero turns the
+// marker off.
+func parseNowMarker(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative window %s", d)
+	}
+	return d, nil
+}
+
+// following reports whether the list follows new messages: it shows session
+// order with the cursor on the last message, so tail updates move the
+// cursor and viewport to the newest one. Moving the cursor up (or sorting)
+// detaches it; f re-engages.
+func (m model) following() bool {
+	return m.view == viewList && m.listSort == sortChronological && m.cursor >= len(m.messages)-1
+}
+
+// followNewest re-engages following: session order, the cursor on the
+// newest message, and the viewport on it.
+func (m *model) followNewest() {
+	if len(m.messages) == 0 {
+		return
+	}
+	m.listSort = sortChronological
+	m.cursor = len(m.messages) - 1
+	m.layoutList()
+	m.ensureCursorVisible()
+}
+
+// nowMarkerIndex returns the first of the messages at the end of the list
+// written within the now window, which the now marker goes above, or -1 for
+// no marker: not tailing, sorted, the marker turned off, nothing recent, or
+// every message recent (a marker above the first one says nothing).
+func (m model) nowMarkerIndex(now time.Time) int {
+	if !m.watching || m.nowWindow <= 0 || m.listSort != sortChronological {
+		return -1
+	}
+	cutoff := now.Add(-m.nowWindow)
+	first := -1
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		at := m.messages[i].startedAt
+		if at.IsZero() || at.Before(cutoff) {
+			break
+		}
+		first = i
+	}
+	if first <= 0 {
+		return -1
+	}
+	return first
+}
+
+// renderNowMarker renders the divider above the recent messages: "new ·
+// last 30s" between muted rules.
+func (m model) renderNowMarker(width int) string {
+	label := "new · last " + localRender.Duration(m.nowWindow.Milliseconds())
+	left, right := dividerRules(label, width)
+	return StyleMuted.Render(left+" ") + StyleDim.Render(label) + StyleMuted.Render(" "+right)
+}
+
+// nowMarkerExpiredMsg ends the now window of the tail update that started
+// timer seq.
+type nowMarkerExpiredMsg struct{ seq int }
+
+// nowMarkerCmd lays the list out again once the newest messages age past
+// the now window, so the marker moves or goes. Only the latest timer
+// counts: until it fires, the ongoing indicator's ticks keep the marker
+// current.
+func (m *model) nowMarkerCmd() tea.Cmd {
+	if !m.watching || m.nowWindow <= 0 {
+		return nil
+	}
+	m.nowSeq++
+	seq := m.nowSeq
+	return tea.Tick(m.nowWindow+time.Second, func(time.Time) tea.Msg {
+		return nowMarkerExpiredMsg{seq: seq}
+	})
+}
+
+// renderFollowState renders the info bar's follow element while tailing
+// the list: "following", or "detached · f follow" after moving off the
+// newest message.
+func (m model) renderFollowState() string {
+	if !m.watching || m.view != viewList || len(m.messages) == 0 {
+		return ""
+	}
+	if m.following() {
+		return StyleDim.Render("following")
+	}
+	return StyleWarningBold.Render("detached") + StyleDim.Render(" · f follow")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNowMarkerIndex(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 5, 0, 0, time.UTC)
+	m := testModel()
+	m.watching = true
+	m.nowWindow = 30 * time.Second
+	for i, ago := range []time.Duration{5 * time.Minute, 20 * time.Second, 3 * time.Second} {
+		m.messages[i].startedAt = now.Add(-ago)
+	}
+
+	if got := m.nowMarkerIndex(now); got != 1 {
+		t.Errorf("nowMarkerIndex = %d, want 1 (the two recent messages)", got)
+	}
+	if got := m.nowMarkerIndex(now.Add(25 * time.Second)); got != 2 {
+		t.Errorf("25s later: nowMarkerIndex = %d, want 2", got)
+	}
+	if got := m.nowMarkerIndex(now.Add(time.Minute)); got != -1 {
+		t.Errorf("a minute later: nowMarkerIndex = %d, want -1", got)
+	}
+	if got := m.nowMarkerIndex(now.Add(-5 * time.Minute)); got != -1 {
+		t.Errorf("all recent: nowMarkerIndex = %d, want -1", got)
+	}
+
+	m.listSort = sortTokens
+	if got := m.nowMarkerIndex(now); got != -1 {
+		t.Errorf("sorted: nowMarkerIndex = %d, want -1", got)
+	}
+	m.listSort = sortChronological
+	m.nowWindow = 0
+	if got := m.nowMarkerIndex(now); got != -1 {
+		t.Errorf("turned off: nowMarkerIndex = %d, want -1", got)
+	}
+}
+
+func TestNowMarkerInList(t *testing.T) {
+	m := testModel()
+	m.watching = true
+	m.nowWindow = 30 * time.Second
+	m.messages[0].startedAt = time.Now().Add(-time.Hour)
+	m.messages[2].startedAt = time.Now()
+	m.layoutList()
+
+	if !strings.Contains(plain(m.listParts[2]), "new · last 30s") {
+		t.Errorf("last message's part lacks the marker:\n%s", plain(m.listParts[2]))
+	}
+	if m.lineOffsets[2]+m.messageLines[2] != m.totalRenderedLines {
+		t.Error("marker line not counted in the message's lines")
+	}
+}
+
+func TestFollowState(t *testing.T) {
+	m := testModel()
+	m.watching = true
+	m.cursor = len(m.messages) - 1
+	if got := plain(m.renderFollowState()); got != "following" {
+		t.Errorf("on the newest message: %q, want following", got)
+	}
+
+	result, _ := m.Update(key("k"))
+	m = asModel(result)
+	if got := plain(m.renderFollowState()); !strings.HasPrefix(got, "detached") {
+		t.Errorf("after k: %q, want detached", got)
+	}
+
+	result, _ = m.Update(key("S"))
+	m = asModel(result)
+	result, _ = m.Update(key("f"))
+	m = asModel(result)
+	if !m.following() || m.listSort != sortChronological || m.cursor != len(m.messages)-1 {
+		t.Errorf("f: sort %d, cursor %d; want session order on the newest message", m.listSort, m.cursor)
+	}
+
+	m.watching = false
+	if got := m.renderFollowState(); got != "" {
+		t.Errorf("not tailing: %q, want nothing", got)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"image/color"
 	"strings"
 	"time"
@@ -84,31 +83,36 @@ func formatTime(t time.Time) string {
 	return t.Local().Format("3:04:05 PM")
 }
 
-// formatTokens formats a token count for display: 1234 -> "1.2k", 123456 -> "123.5k", 1234567 -> "1.2M"
+// exactTokens shows token counts whole, "100,321" rather than "100.3k", for
+// tracking a budget: the exactTokens config, toggled with +. Set in main
+// before the subcommands, like the number format.
+var exactTokens bool
+
+// formatTokens formats a token count for display: 1234 -> "1.2k", 123456 -> "123.5k", 1234567 -> "1.2M",
+// or with exactTokens every digit, grouped: 123456 -> "123,456"
 func formatTokens(n int) string {
 	switch {
+	case exactTokens:
+		return formatCount(n)
 	case n >= 1_000_000:
-		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+		return formatDecimal(float64(n)/1_000_000, 1) + "M"
 	case n >= 1_000:
-		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+		return formatDecimal(float64(n)/1_000, 1) + "k"
 	default:
-		return fmt.Sprintf("%d", n)
+		return formatCount(n)
 	}
 }
 
-// formatDuration formats milliseconds into human-readable duration: 71000 -> "1m 11s", 3500 -> "3.5s"
-func formatDuration(ms int64) string {
-	secs := float64(ms) / 1000
+// formatContextDelta renders a context snapshot change as "+3.2k ctx" or
+// "-40.0k ctx". Zero renders as empty.
+func formatContextDelta(n int) string {
 	switch {
-	case secs >= 60:
-		mins := int(secs) / 60
-		rem := int(secs) % 60
-		return fmt.Sprintf("%dm %ds", mins, rem)
-	case secs >= 10:
-		return fmt.Sprintf("%.0fs", secs)
-	default:
-		return fmt.Sprintf("%.1fs", secs)
+	case n > 0:
+		return "+" + formatTokens(n) + " ctx"
+	case n < 0:
+		return "-" + formatTokens(-n) + " ctx"
 	}
+	return ""
 }
 
 // formatSessionName formats a session ID for compact picker display.
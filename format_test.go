@@ -50,22 +50,45 @@ func TestFormatTokens(t *testing.T) {
 	}
 }
 
-func TestFormatDuration(t *testing.T) {
+func TestFormatTokensExact(t *testing.T) {
+	defer func() { exactTokens = false }()
+	exactTokens = true
+	for n, want := range map[int]string{999: "999", 100321: "100,321", 1234567: "1,234,567"} {
+		if got := formatTokens(n); got != want {
+			t.Errorf("exact formatTokens(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestToggleExactTokens(t *testing.T) {
+	defer func() { exactTokens = false }()
+	m := testModel()
+	result, _ := m.Update(key("+"))
+	m = asModel(result)
+	if !exactTokens || m.flashStatus != "Exact token counts" {
+		t.Errorf("+: exact %v, flash %q; want exact counts", exactTokens, m.flashStatus)
+	}
+	result, _ = m.Update(key("+"))
+	m = asModel(result)
+	if exactTokens || m.flashStatus != "Abbreviated token counts" {
+		t.Errorf("+ again: exact %v, flash %q; want abbreviated counts", exactTokens, m.flashStatus)
+	}
+}
+
+func TestFormatContextDelta(t *testing.T) {
 	tests := []struct {
-		input int64
+		input int
 		want  string
 	}{
-		{0, "0.0s"},
-		{3500, "3.5s"},
-		{9999, "10.0s"},
-		{15000, "15s"},
-		{60000, "1m 0s"},
-		{71000, "1m 11s"},
+		{0, ""},
+		{3200, "+3.2k ctx"},
+		{-40000, "-40.0k ctx"},
+		{250, "+250 ctx"},
 	}
 	for _, tt := range tests {
-		got := formatDuration(tt.input)
+		got := formatContextDelta(tt.input)
 		if got != tt.want {
-			t.Errorf("formatDuration(%d) = %q, want %q", tt.input, got, tt.want)
+			t.Errorf("formatContextDelta(%d) = %q, want %q", tt.input, got, tt.want)
 		}
 	}
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// growthWindow is the span the session growth rate is averaged over. A rate
+// older than this is stale and no longer shown.
+const growthWindow = time.Minute
+
+// minGrowthElapsed is the shortest span a rate is computed over; shorter
+// spans turn a single burst of writes into a misleading spike.
+const minGrowthElapsed = 5 * time.Second
+
+// growthSample records the session file size and token count at a moment.
+type growthSample struct {
+	at     time.Time
+	bytes  int64
+	tokens int
+}
+
+// growthRate is how fast the watched session is growing: bytes appended to
+// the JSONL and tokens added to the context, per minute.
+type growthRate struct {
+	bytesPerMin  float64
+	tokensPerMin float64
+	at           time.Time // when the rate was computed; zero when unknown
+}
+
+// growthTracker averages session growth over a sliding window. Owned by the
+// watcher's run() goroutine.
+type growthTracker struct {
+	samples []growthSample // oldest first; samples[0] may predate the window
+}
+
+// add records a sample and returns the rate since the window's baseline:
+// the newest sample at or before the window start, so a burst after an idle
+// stretch averages over the quiet time instead of spiking.
+func (g *growthTracker) add(s growthSample) growthRate {
+	g.samples = append(g.samples, s)
+	cutoff := s.at.Add(-growthWindow)
+	for len(g.samples) > 2 && !g.samples[1].at.After(cutoff) {
+		g.samples = g.samples[1:]
+	}
+
+	base := g.samples[0]
+	elapsed := s.at.Sub(base.at)
+	if elapsed < minGrowthElapsed {
+		return growthRate{}
+	}
+	mins := elapsed.Minutes()
+	return growthRate{
+		bytesPerMin:  float64(s.bytes-base.bytes) / mins,
+		tokensPerMin: float64(max(s.tokens-base.tokens, 0)) / mins,
+		at:           s.at,
+	}
+}
+
+// lastUsageTokens returns the context size reported by the last assistant
+// response in msgs (input, cache, and output tokens), or 0 when none carries
+// usage.
+func lastUsageTokens(msgs []parser.ClassifiedMsg) int {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if ai, ok := msgs[i].(parser.AIMsg); ok && !ai.IsMeta && ai.Usage.TotalTokens() > 0 {
+			return ai.Usage.TotalTokens()
+		}
+	}
+	return 0
+}
+
+// formatGrowth renders a rate as "12.4 KB/min 1.2k tok/min". Returns "" when
+// the rate is unknown or older than growthWindow at now.
+func formatGrowth(g growthRate, now time.Time) string {
+	if g.at.IsZero() || now.Sub(g.at) > growthWindow {
+		return ""
+	}
+	return formatBytes(g.bytesPerMin) + "/min " + formatTokens(int(g.tokensPerMin)) + " tok/min"
+}
+
+// formatBytes formats a byte count: 512 -> "512 B", 12700 -> "12.4 KB".
+func formatBytes(n float64) string {
+	switch {
+	case n >= 1<<30:
+		return formatDecimal(n/(1<<30), 1) + " GB"
+	case n >= 1<<20:
+		return formatDecimal(n/(1<<20), 1) + " MB"
+	case n >= 1<<10:
+		return formatDecimal(n/(1<<10), 1) + " KB"
+	default:
+		return formatDecimal(n, 0) + " B"
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestGrowthTracker(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	var g growthTracker
+
+	if r := g.add(growthSample{at: t0, bytes: 1000, tokens: 5000}); !r.at.IsZero() {
+		t.Errorf("first sample rate = %+v, want unknown", r)
+	}
+	if r := g.add(growthSample{at: t0.Add(2 * time.Second), bytes: 2000, tokens: 5000}); !r.at.IsZero() {
+		t.Errorf("rate under minGrowthElapsed = %+v, want unknown", r)
+	}
+
+	r := g.add(growthSample{at: t0.Add(30 * time.Second), bytes: 7144, tokens: 6000})
+	if math.Abs(r.bytesPerMin-12288) > 0.01 {
+		t.Errorf("bytesPerMin = %v, want 12288", r.bytesPerMin)
+	}
+	if math.Abs(r.tokensPerMin-2000) > 0.01 {
+		t.Errorf("tokensPerMin = %v, want 2000", r.tokensPerMin)
+	}
+
+	// Samples older than the window drop out, keeping one baseline.
+	r = g.add(growthSample{at: t0.Add(3 * time.Minute), bytes: 7144 + 600, tokens: 6000})
+	if len(g.samples) != 2 {
+		t.Errorf("len(samples) = %d, want 2 (baseline + newest)", len(g.samples))
+	}
+	if math.Abs(r.bytesPerMin-240) > 0.01 {
+		t.Errorf("bytesPerMin after idle = %v, want 240 (averaged over 2.5m)", r.bytesPerMin)
+	}
+
+	// Compaction shrinks the context; the token rate floors at zero.
+	r = g.add(growthSample{at: t0.Add(3*time.Minute + 30*time.Second), bytes: 8000, tokens: 1000})
+	if r.tokensPerMin != 0 {
+		t.Errorf("tokensPerMin after compaction = %v, want 0", r.tokensPerMin)
+	}
+}
+
+func TestLastUsageTokens(t *testing.T) {
+	msgs := []parser.ClassifiedMsg{
+		parser.AIMsg{Usage: parser.Usage{InputTokens: 10, OutputTokens: 5}},
+		parser.AIMsg{Usage: parser.Usage{InputTokens: 100, CacheReadTokens: 900, OutputTokens: 20}},
+		parser.AIMsg{IsMeta: true, Usage: parser.Usage{InputTokens: 1}},
+		parser.UserMsg{},
+	}
+	if got := lastUsageTokens(msgs); got != 1020 {
+		t.Errorf("lastUsageTokens = %d, want 1020", got)
+	}
+	if got := lastUsageTokens(nil); got != 0 {
+		t.Errorf("lastUsageTokens(nil) = %d, want 0", got)
+	}
+}
+
+func TestFormatGrowth(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	rate := growthRate{bytesPerMin: 12700, tokensPerMin: 1234, at: now.Add(-10 * time.Second)}
+
+	if got, want := formatGrowth(rate, now), "12.4 KB/min 1.2k tok/min"; got != want {
+		t.Errorf("formatGrowth = %q, want %q", got, want)
+	}
+	if got := formatGrowth(rate, now.Add(2*growthWindow)); got != "" {
+		t.Errorf("stale rate = %q, want empty", got)
+	}
+	if got := formatGrowth(growthRate{}, now); got != "" {
+		t.Errorf("unknown rate = %q, want empty", got)
+	}
+	if got := formatBytes(512); got != "512 B" {
+		t.Errorf("formatBytes(512) = %q", got)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"charm.land/lipgloss/v2"
+)
+
+// sgrReset ends every SGR attribute.
+const sgrReset = "\x1b[m"
+
+// matchSpan is one rune of the normalized match stream: runes [start, end)
+// of a line's visible text. A run of whitespace is one span, clipped to its
+// line.
+type matchSpan struct {
+	line, start, end int
+}
+
+// highlightMatches marks every case-insensitive occurrence of query in
+// rendered, ANSI-styled text, as shown in the list, detail, and debug views.
+// Matching runs on the visible text with styling stripped, and any run of
+// whitespace, line breaks and the next line's indentation included, counts
+// as one space. A match that markdown rendering or word wrapping split
+// across lines is still found, and each line's part is marked in place.
+// Styles inside a match are kept from overriding the mark and restored
+// after it.
+func highlightMatches(rendered, query string) string {
+	q := []rune(strings.ToLower(strings.Join(strings.Fields(query), " ")))
+	if len(q) == 0 || rendered == "" {
+		return rendered
+	}
+
+	lines := strings.Split(rendered, "\n")
+	visible := make([][]rune, len(lines))
+	var stream []rune
+	var spans []matchSpan
+	for li, line := range lines {
+		visible[li] = visibleRunes(line)
+		if li > 0 && len(stream) > 0 && stream[len(stream)-1] != ' ' {
+			// The line break itself; its span is empty.
+			stream = append(stream, ' ')
+			spans = append(spans, matchSpan{li, 0, 0})
+		}
+		runes := visible[li]
+		for i := 0; i < len(runes); i++ {
+			if !unicode.IsSpace(runes[i]) {
+				stream = append(stream, unicode.ToLower(runes[i]))
+				spans = append(spans, matchSpan{li, i, i + 1})
+				continue
+			}
+			end := i + 1
+			for end < len(runes) && unicode.IsSpace(runes[end]) {
+				end++
+			}
+			if len(stream) > 0 && stream[len(stream)-1] == ' ' {
+				// Continues a run begun on an earlier line: unmarked.
+				i = end - 1
+				continue
+			}
+			stream = append(stream, ' ')
+			if end == len(runes) {
+				// Trailing padding: matched as the line break, never marked.
+				spans = append(spans, matchSpan{li, i, i})
+			} else {
+				spans = append(spans, matchSpan{li, i, end})
+			}
+			i = end - 1
+		}
+	}
+
+	marks := make([][]bool, len(lines))
+	found := false
+	for i := 0; i+len(q) <= len(stream); {
+		if !runesEqual(stream[i:i+len(q)], q) {
+			i++
+			continue
+		}
+		found = true
+		for _, sp := range spans[i : i+len(q)] {
+			if marks[sp.line] == nil {
+				marks[sp.line] = make([]bool, len(visible[sp.line]))
+			}
+			for r := sp.start; r < sp.end; r++ {
+				marks[sp.line][r] = true
+			}
+		}
+		i += len(q)
+	}
+	if !found {
+		return rendered
+	}
+
+	on := highlightSGR()
+	for li, m := range marks {
+		if m != nil {
+			lines[li] = markLine(lines[li], m, on)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightSGR returns the escape sequence that starts the match mark.
+func highlightSGR() string {
+	probe := lipgloss.NewStyle().Bold(true).Reverse(true).Foreground(ColorAccent).Render("x")
+	on, _, _ := strings.Cut(probe, "x")
+	if on == "" {
+		on = "\x1b[1;7m"
+	}
+	return on
+}
+
+// runesEqual reports whether a and b hold the same runes.
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeLen returns the length of the escape sequence at the start of s, or
+// 0 when s doesn't start with one. Handles CSI (colors, cursor moves), OSC
+// (hyperlinks, ended by BEL or ST), and two-byte escapes.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	}
+	return 2
+}
+
+// visibleRunes returns the runes of line with escape sequences removed.
+func visibleRunes(line string) []rune {
+	var runes []rune
+	for i := 0; i < len(line); {
+		if n := escapeLen(line[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		runes = append(runes, r)
+		i += size
+	}
+	return runes
+}
+
+// markLine wraps the visible runes flagged in marks with the on sequence.
+// SGR sequences seen since the last reset are replayed after each mark, and
+// the mark is restored after any that fall inside it.
+func markLine(line string, marks []bool, on string) string {
+	var out strings.Builder
+	var state strings.Builder // SGR sequences in effect
+	inMark := false
+	vis := 0
+	for i := 0; i < len(line); {
+		if n := escapeLen(line[i:]); n > 0 {
+			seq := line[i : i+n]
+			out.WriteString(seq)
+			if strings.HasSuffix(seq, "m") && seq[1] == '[' {
+				if seq == sgrReset || seq == "\x1b[0m" {
+					state.Reset()
+				} else {
+					state.WriteString(seq)
+				}
+				if inMark {
+					out.WriteString(on)
+				}
+			}
+			i += n
+			continue
+		}
+		marked := vis < len(marks) && marks[vis]
+		if marked && !inMark {
+			out.WriteString(on)
+		} else if !marked && inMark {
+			out.WriteString(sgrReset + state.String())
+		}
+		inMark = marked
+		_, size := utf8.DecodeRuneInString(line[i:])
+		out.WriteString(line[i : i+size])
+		i += size
+		vis++
+	}
+	if inMark {
+		out.WriteString(sgrReset + state.String())
+	}
+	return out.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightMatches(t *testing.T) {
+	// markOf replaces the highlight start with "[" and the reset that ends it
+	// with "]" so expectations stay readable.
+	markOf := func(s string) string {
+		s = strings.ReplaceAll(s, highlightSGR(), "[")
+		return strings.ReplaceAll(s, sgrReset, "]")
+	}
+
+	tests := []struct {
+		name, rendered, query, want string
+	}{
+		{"plain", "the Parser runs", "parser", "the [Parser] runs"},
+		{"every match", "ab ab", "ab", "[ab] [ab]"},
+		{"no match", "abc", "xyz", "abc"},
+		{"empty query", "abc", "  ", "abc"},
+		{
+			"a reset inside the mark keeps it",
+			"\x1b[1mbold word\x1b[m tail",
+			"word",
+			"\x1b[1mbold [word][] tail", // the reset inside the mark re-applies it
+		},
+		{
+			"the style in effect is restored after the mark",
+			"\x1b[1mword tail\x1b[m",
+			"word",
+			"\x1b[1m[word]\x1b[1m tail]",
+		},
+		{
+			"style change inside a match re-applies the mark",
+			"go\x1b[3mod\x1b[m",
+			"good",
+			"[go\x1b[3m[od][]",
+		},
+		{
+			"soft-wrapped across lines",
+			"fix the search\n    index bug",
+			"search index",
+			"fix the [search]\n    [index] bug",
+		},
+		{
+			"trailing padding stays unmarked",
+			"search   \nindex",
+			"search index",
+			"[search]   \n[index]",
+		},
+		{"collapsed spaces", "a  b", "a b", "[a  b]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markOf(highlightMatches(tt.rendered, tt.query)); got != tt.want {
+				t.Errorf("highlightMatches(%q, %q) = %q, want %q", tt.rendered, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightQueryLifecycle(t *testing.T) {
+	m := testModel()
+	m.searchQuery = "help with"
+	m.view = viewSearch
+	result, _ := m.updateSearch(key("enter"))
+	m = asModel(result)
+	if m.highlightQuery != "help with" {
+		t.Fatalf("highlightQuery = %q after opening a hit", m.highlightQuery)
+	}
+	if !strings.Contains(m.viewList(), highlightSGR()) {
+		t.Error("list should mark the query")
+	}
+
+	result, _ = m.updateList(key("esc"))
+	m = asModel(result)
+	if m.highlightQuery != "" || m.view != viewList {
+		t.Errorf("esc should clear highlights first: query %q, view %d", m.highlightQuery, m.view)
+	}
+}
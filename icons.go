@@ -65,15 +65,18 @@ type taskIcons struct {
 // Requires a Nerd Font patched terminal font (e.g. JetBrains Mono Nerd Font).
 // Codepoints from Font Awesome (U+F000-U+F2E0) and Material Design (U+F0001+).
 type iconSet struct {
+	Basket    StyledIcon
 	Branch    StyledIcon
 	Chat      StyledIcon
 	Claude    StyledIcon
 	Clock     StyledIcon
 	Collapsed StyledIcon
+	Command   StyledIcon
 	Dot       StyledIcon
 	DrillDown StyledIcon
 	Ellipsis  StyledIcon
 	Expanded  StyledIcon
+	Hook      StyledIcon
 	Output    StyledIcon
 	Selected  StyledIcon
 	Session   StyledIcon
@@ -84,6 +87,7 @@ type iconSet struct {
 	Thinking  StyledIcon
 	Token     StyledIcon
 	User      StyledIcon
+	Warning   StyledIcon
 	Tool      toolIcons
 	Task      taskIcons
 }
@@ -109,15 +113,18 @@ var SpinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "
 // in the Private Use Area are particularly vulnerable to being dropped.
 func initIcons() {
 	Icon = iconSet{
+		Basket:    StyledIcon{"\uF02E", ColorAccent},    // nf-fa-bookmark
 		Branch:    StyledIcon{"\uE0A0", ColorGitBranch}, // nf-pl-branch
 		Chat:      StyledIcon{"\uF086", ColorTextDim},   // nf-fa-comments
 		Claude:    StyledIcon{glyphRobot, ColorInfo},
 		Clock:     StyledIcon{"\uF017", ColorTextDim},     // nf-fa-clock
 		Collapsed: StyledIcon{"\uF054", ColorTextDim},     // nf-fa-chevron_right
+		Command:   StyledIcon{"\uF120", ColorAccent},      // nf-fa-terminal
 		Dot:       StyledIcon{"\u00B7", ColorTextMuted},   // middle dot
 		DrillDown: StyledIcon{"\uF061", ColorAccent},      // nf-fa-arrow_right
 		Ellipsis:  StyledIcon{"\u2026", ColorTextDim},     // horizontal ellipsis
 		Expanded:  StyledIcon{"\uF078", ColorTextPrimary}, // nf-fa-chevron_down
+		Hook:      StyledIcon{"\U000F06E2", ColorTextDim}, // nf-md-hook
 		Output:    StyledIcon{"\U000F0182", ColorAccent},  // nf-md-comment_outline
 		Selected:  StyledIcon{"\u2502", ColorAccent},      // box drawing vertical
 		Session:   StyledIcon{"\U000F0237", ColorTextDim}, // nf-md-fingerprint
@@ -128,6 +135,7 @@ func initIcons() {
 		Thinking:  StyledIcon{"\uF0EB", ColorTextDim},       // nf-fa-lightbulb
 		Token:     StyledIcon{"\uEDE8", ColorTextDim},       // nf-fa-coins
 		User:      StyledIcon{"\uF007", ColorTextSecondary}, // nf-fa-user
+		Warning:   StyledIcon{"\uF071", ColorWarning},       // nf-fa-warning
 		Tool: toolIcons{
 			Err:   StyledIcon{glyphWrench, ColorError},
 			Ok:    StyledIcon{glyphWrench, ColorTextDim},
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// diffMaxCells bounds the LCS table of a diff. Inputs larger than this are
+// shown as a whole replacement rather than diffed.
+const diffMaxCells = 4_000_000
+
+// diffContextLines is how many unchanged lines show around each change.
+const diffContextLines = 2
+
+// diffKind says what an edit script step does.
+type diffKind int
+
+const (
+	diffSame diffKind = iota
+	diffDel
+	diffAdd
+)
+
+// diffOp is one step of an edit script: a line or word kept, removed, or added.
+type diffOp struct {
+	kind diffKind
+	text string
+}
+
+// diffTokens returns the edit script turning a into b, from their longest
+// common subsequence. Removals come before additions within each change.
+func diffTokens(a, b []string) []diffOp {
+	if len(a)*len(b) > diffMaxCells {
+		var ops []diffOp
+		for _, s := range a {
+			ops = append(ops, diffOp{diffDel, s})
+		}
+		for _, s := range b {
+			ops = append(ops, diffOp{diffAdd, s})
+		}
+		return ops
+	}
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{diffSame, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{diffDel, a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{diffAdd, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{diffDel, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{diffAdd, b[j]})
+	}
+	return ops
+}
+
+// diffWordRe splits a line into words, runs of whitespace, and single
+// punctuation characters, so a changed flag or path shows as one token.
+var diffWordRe = regexp.MustCompile(`\s+|[\pL\pN_]+|.`)
+
+// inputDiff is the change in a tool call's input since the previous call of
+// the same tool.
+type inputDiff struct {
+	since string   // when the previous call was made: "10:04:12", or "earlier"
+	ops   []diffOp // line edit script, previous input to this one
+}
+
+// inputDiffKey identifies a tool call by name and input, so a diff follows
+// the call across rebuilds and trace levels.
+func inputDiffKey(item displayItem) string {
+	return item.toolName + "\x00" + item.toolInput
+}
+
+// previousCall finds the last call of the same tool before the row: earlier
+// in a subagent's trace for its steps, else earlier in the message, then in
+// earlier messages of the session.
+func (m model) previousCall(msg message, row visibleRow) (displayItem, bool) {
+	same := func(it displayItem) bool {
+		return it.itemType == parser.ItemToolCall && it.toolName == row.item.toolName && it.toolInput != ""
+	}
+	if row.childIndex >= 0 {
+		siblings := buildTraceItems(msg.items[row.parentIndex])
+		for i := min(row.childIndex, len(siblings)) - 1; i >= 0; i-- {
+			if same(siblings[i]) {
+				return siblings[i], true
+			}
+		}
+		return displayItem{}, false
+	}
+	for i := row.parentIndex - 1; i >= 0; i-- {
+		if same(msg.items[i]) {
+			return msg.items[i], true
+		}
+	}
+	if m.traceMsg != nil {
+		return displayItem{}, false
+	}
+	for mi := min(m.cursor, len(m.messages)) - 1; mi >= 0; mi-- {
+		items := m.messages[mi].items
+		for i := len(items) - 1; i >= 0; i-- {
+			if same(items[i]) {
+				return items[i], true
+			}
+		}
+	}
+	return displayItem{}, false
+}
+
+// toggleInputDiff shows, above the input of the tool call under the cursor,
+// what changed since the previous call of the same tool. Pressing D again
+// hides it.
+func (m *model) toggleInputDiff() tea.Cmd {
+	rows := m.detailVisibleRows()
+	if m.detailCursor >= len(rows) {
+		return nil
+	}
+	row := rows[m.detailCursor]
+	if row.item.itemType != parser.ItemToolCall || row.item.toolInput == "" {
+		m.flashStatus = "Input diff compares tool calls"
+		return flashClearCmd()
+	}
+	key := inputDiffKey(row.item)
+	if _, ok := m.inputDiffs[key]; ok {
+		delete(m.inputDiffs, key)
+		m.computeDetailMaxScroll()
+		return nil
+	}
+	prev, ok := m.previousCall(m.currentDetailMsg(), row)
+	if !ok {
+		m.flashStatus = fmt.Sprintf("No earlier %s call to compare with", row.item.toolName)
+		return flashClearCmd()
+	}
+	since := "earlier"
+	if !prev.timestamp.IsZero() {
+		since = prev.timestamp.Format("15:04:05")
+	}
+	if prev.toolInput == row.item.toolInput {
+		m.flashStatus = fmt.Sprintf("Same input as the previous %s call (%s)", row.item.toolName, since)
+		return flashClearCmd()
+	}
+	if m.inputDiffs == nil {
+		m.inputDiffs = make(map[string]inputDiff)
+	}
+	m.inputDiffs[key] = inputDiff{
+		since: since,
+		ops:   diffTokens(strings.Split(prev.toolInput, "\n"), strings.Split(row.item.toolInput, "\n")),
+	}
+	expanded := m.detailExpanded[row.parentIndex]
+	if row.childIndex != -1 {
+		expanded = m.detailChildExpanded[visibleRowKey{row.parentIndex, row.childIndex}]
+	}
+	if !expanded {
+		m.toggleDetailExpansion()
+	} else {
+		m.computeDetailMaxScroll()
+	}
+	return nil
+}
+
+// renderInputDiff renders the input diff of an expanded tool call, if one
+// was asked for, followed by a separator from the input below it. Unchanged
+// lines away from a change are elided; a removed line followed by an added
+// one has the words that changed highlighted in both.
+func (m model) renderInputDiff(item displayItem, wrapWidth int, indent string) string {
+	d, ok := m.inputDiffs[inputDiffKey(item)]
+	if !ok {
+		return ""
+	}
+	del := lipgloss.NewStyle().Foreground(ColorError)
+	add := lipgloss.NewStyle().Foreground(ColorContextOk)
+	wrap := lipgloss.NewStyle().Width(wrapWidth)
+
+	lines := []string{indent + StyleSecondaryBold.Render("Changed since the previous "+item.toolName+" call") +
+		StyleDim.Render(" ("+d.since+")")}
+	line := func(prefix string, style lipgloss.Style, text string) {
+		lines = append(lines, indentBlock(wrap.Render(style.Render(prefix)+text), indent))
+	}
+
+	ops := d.ops
+	near := changedNear(ops, diffContextLines)
+	elided := false
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+		if op.kind == diffSame {
+			if !near[i] {
+				if !elided {
+					lines = append(lines, indent+StyleMuted.Render("  …"))
+					elided = true
+				}
+				continue
+			}
+			line("  ", StyleDim, StyleDim.Render(op.text))
+			elided = false
+			continue
+		}
+		elided = false
+		// Pair a run of removals with the additions that follow it.
+		j := i
+		for j < len(ops) && ops[j].kind == diffDel {
+			j++
+		}
+		k := j
+		for k < len(ops) && ops[k].kind == diffAdd {
+			k++
+		}
+		dels, adds := ops[i:j], ops[j:k]
+		delText := make([]string, len(dels))
+		for n, d := range dels {
+			delText[n] = del.Render(d.text)
+		}
+		addText := make([]string, len(adds))
+		for n, a := range adds {
+			addText[n] = add.Render(a.text)
+		}
+		for n := range min(len(dels), len(adds)) {
+			delText[n], addText[n] = highlightWordChanges(dels[n].text, adds[n].text, del, add)
+		}
+		for _, t := range delText {
+			line("- ", del, t)
+		}
+		for _, t := range addText {
+			line("+ ", add, t)
+		}
+		i = k - 1
+	}
+	lines = append(lines, indent+StyleMuted.Render(strings.Repeat("-", wrapWidth)))
+	return strings.Join(lines, "\n")
+}
+
+// changedNear marks the ops within n steps of a change.
+func changedNear(ops []diffOp, n int) []bool {
+	near := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.kind == diffSame {
+			continue
+		}
+		for j := max(i-n, 0); j <= min(i+n, len(ops)-1); j++ {
+			near[j] = true
+		}
+	}
+	return near
+}
+
+// highlightWordChanges renders a changed line pair with the words each side
+// doesn't share in bold, underlined.
+func highlightWordChanges(before, after string, del, add lipgloss.Style) (string, string) {
+	ops := diffTokens(diffWordRe.FindAllString(before, -1), diffWordRe.FindAllString(after, -1))
+	delMark := del.Bold(true).Underline(true)
+	addMark := add.Bold(true).Underline(true)
+	var b, a strings.Builder
+	for i := 0; i < len(ops); {
+		// Render each run of one kind at once.
+		var run strings.Builder
+		kind := ops[i].kind
+		for ; i < len(ops) && ops[i].kind == kind; i++ {
+			run.WriteString(ops[i].text)
+		}
+		switch kind {
+		case diffSame:
+			b.WriteString(del.Render(run.String()))
+			a.WriteString(add.Render(run.String()))
+		case diffDel:
+			b.WriteString(delMark.Render(run.String()))
+		case diffAdd:
+			a.WriteString(addMark.Render(run.String()))
+		}
+	}
+	return b.String(), a.String()
+}
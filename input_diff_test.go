@@ -0,0 +1,92 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// sgrRe matches the color and style escapes lipgloss renders.
+var sgrRe = regexp.MustCompile("\x1b\\[[0-9;:]*m")
+
+// plain strips styling from rendered output.
+func plain(s string) string {
+	return sgrRe.ReplaceAllString(s, "")
+}
+
+func TestDiffTokens(t *testing.T) {
+	ops := diffTokens(strings.Fields("a b c d"), strings.Fields("a x c d e"))
+	var got []string
+	for _, op := range ops {
+		got = append(got, [...]string{" ", "-", "+"}[op.kind]+op.text)
+	}
+	if want := " a -b +x  c  d +e"; strings.Join(got, " ") != want {
+		t.Errorf("ops = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestToggleInputDiff(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 4, 12, 0, time.Local)
+	bash := func(cmd string) displayItem {
+		return displayItem{itemType: parser.ItemToolCall, toolName: "Bash", timestamp: t0,
+			toolInput: "{\n  \"command\": \"" + cmd + "\",\n  \"description\": \"Run the tests\"\n}"}
+	}
+	earlier := claudeMsg(func(m *message) { m.items = []displayItem{bash("go test ./parser")} })
+	msg := claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "a.go"}`},
+			bash("go test -run TestFoo ./parser"),
+			bash("go test -run TestFoo ./parser"),
+		}
+	})
+	m := detailModel(msg)
+	m.messages = []message{earlier, msg}
+	m.cursor = 1
+
+	result, _ := m.updateDetail(key("D"))
+	if got := asModel(result).flashStatus; !strings.Contains(got, "No earlier Read call") {
+		t.Errorf("Read with no earlier call: flash = %q", got)
+	}
+
+	// The first Bash call of the turn compares with the earlier turn's.
+	m.detailCursor = 1
+	result, _ = m.updateDetail(key("D"))
+	m = asModel(result)
+	if !m.detailExpanded[1] {
+		t.Error("D should expand the call")
+	}
+	out := plain(m.viewDetail())
+	diff, input := strings.Index(out, "Changed since the previous Bash call (10:04:12)"), strings.Index(out, "Input:")
+	if diff < 0 || input < diff {
+		t.Fatalf("want the diff above the input:\n%s", out)
+	}
+	if !strings.Contains(out, `-   "command": "go test ./parser",`) || !strings.Contains(out, `+   "command": "go test -run TestFoo ./parser",`) {
+		t.Errorf("changed line missing:\n%s", out)
+	}
+	if !strings.Contains(out[diff:input], "description") {
+		t.Errorf("context line missing:\n%s", out[diff:input])
+	}
+
+	// D again hides it.
+	result, _ = m.updateDetail(key("D"))
+	if out := asModel(result).viewDetail(); strings.Contains(out, "Changed since") {
+		t.Error("second D should hide the diff")
+	}
+
+	// A repeat of the same command says so instead.
+	m.detailCursor = 2
+	result, _ = m.updateDetail(key("D"))
+	if got := asModel(result).flashStatus; !strings.Contains(got, "Same input as the previous Bash call") {
+		t.Errorf("repeat: flash = %q", got)
+	}
+}
+
+func TestHighlightWordChanges(t *testing.T) {
+	before, after := highlightWordChanges("go test ./parser", "go test -run X ./parser", StyleDim, StyleDim)
+	if plain(before) != "go test ./parser" || plain(after) != "go test -run X ./parser" {
+		t.Errorf("text changed: %q / %q", plain(before), plain(after))
+	}
+}
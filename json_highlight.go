@@ -20,6 +20,7 @@ type jsonHL struct {
 	lexer     chroma.Lexer
 	formatter chroma.Formatter
 	style     *chroma.Style
+	perf      *perfStats // times highlighting for the perf overlay; may be nil
 }
 
 // newJSONHL creates a highlighter with the pre-detected background color
@@ -49,6 +50,7 @@ func newJSONHL(hasDarkBg bool) *jsonHL {
 // syntax-highlighted text. Returns ("", false) for non-JSON input
 // so the caller can fall back to plain rendering.
 func (h *jsonHL) highlight(s string) (string, bool) {
+	defer h.perf.record(perfHighlight, h.perf.start())
 	raw := []byte(s)
 	if !json.Valid(raw) {
 		return "", false
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// jsonTreeInlineLines is the pretty-printed size above which an expanded tool
+// input shows its top-level keys as a collapsed tree instead of the full
+// payload. Smaller inputs read better flat.
+const jsonTreeInlineLines = 40
+
+// jsonNode is one value in a JSON tree. Object members keep their document
+// order, which a map would lose.
+type jsonNode struct {
+	key      string // member name, "[i]" for array elements, "" for the root
+	kind     byte   // '{' or '[' for containers, 0 for scalars
+	value    any    // scalar: string, json.Number, bool, or nil
+	children []*jsonNode
+	parent   *jsonNode
+	depth    int // 0 for the root's children; the root itself is never shown
+	expanded bool
+}
+
+// parseJSONTree parses s into a tree with only the root expanded. Scalar
+// documents are rejected: there is nothing to navigate.
+func parseJSONTree(s string) (*jsonNode, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	root, err := decodeJSONNode(dec, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("trailing data after JSON value")
+	}
+	if root.kind == 0 {
+		return nil, errors.New("not a JSON object or array")
+	}
+	root.expanded = true
+	return root, nil
+}
+
+// decodeJSONNode reads the next value from dec as a child of parent.
+func decodeJSONNode(dec *json.Decoder, parent *jsonNode, key string) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{key: key, parent: parent, depth: -1}
+	if parent != nil {
+		n.depth = parent.depth + 1
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		n.value = tok
+		return n, nil
+	}
+	n.kind = byte(delim)
+	for i := 0; dec.More(); i++ {
+		childKey := fmt.Sprintf("[%d]", i)
+		if n.kind == '{' {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			childKey, _ = k.(string)
+		}
+		child, err := decodeJSONNode(dec, n, childKey)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, child)
+	}
+	// Closing delimiter.
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// visibleJSONRows returns the nodes shown under root, in display order.
+func visibleJSONRows(root *jsonNode) []*jsonNode {
+	var rows []*jsonNode
+	var walk func(n *jsonNode)
+	walk = func(n *jsonNode) {
+		for _, c := range n.children {
+			rows = append(rows, c)
+			if c.expanded {
+				walk(c)
+			}
+		}
+	}
+	walk(root)
+	return rows
+}
+
+// setExpanded expands or collapses n and every container below it.
+func (n *jsonNode) setExpanded(expanded bool) {
+	if n.kind == 0 {
+		return
+	}
+	n.expanded = expanded
+	for _, c := range n.children {
+		c.setExpanded(expanded)
+	}
+}
+
+// text returns the node as clipboard text: a string's contents unquoted,
+// other values as indented JSON.
+func (n *jsonNode) text() string {
+	if s, ok := n.value.(string); ok && n.kind == 0 {
+		return s
+	}
+	var buf bytes.Buffer
+	n.writeJSON(&buf)
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, buf.Bytes(), "", "  ") != nil {
+		return buf.String()
+	}
+	return pretty.String()
+}
+
+// writeJSON writes the node as compact JSON, members in document order.
+func (n *jsonNode) writeJSON(buf *bytes.Buffer) {
+	switch n.kind {
+	case '{', '[':
+		buf.WriteByte(n.kind)
+		for i, c := range n.children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if n.kind == '{' {
+				writeJSONScalar(buf, c.key)
+				buf.WriteByte(':')
+			}
+			c.writeJSON(buf)
+		}
+		if n.kind == '{' {
+			buf.WriteByte('}')
+		} else {
+			buf.WriteByte(']')
+		}
+	default:
+		writeJSONScalar(buf, n.value)
+	}
+}
+
+// writeJSONScalar encodes v without escaping HTML characters.
+func writeJSONScalar(buf *bytes.Buffer, v any) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	buf.Truncate(buf.Len() - 1) // Encode appends a newline
+}
+
+// jsonContainerSize describes a container's size: "3 keys", "20 items".
+func jsonContainerSize(n *jsonNode) string {
+	if n.kind == '{' {
+		return parser.Plural(len(n.children), "key")
+	}
+	return parser.Plural(len(n.children), "item")
+}
+
+// renderJSONTreeRow renders one node: indentation by depth, an expand marker
+// for containers, the key, and the scalar value or container size.
+func renderJSONTreeRow(n *jsonNode, selected bool, width int) string {
+	indent := strings.Repeat("  ", n.depth)
+	marker := "  "
+	if n.kind != 0 {
+		if n.expanded {
+			marker = Icon.Expanded.Render() + " "
+		} else {
+			marker = Icon.Collapsed.Render() + " "
+		}
+	}
+	keyStyle := StyleSecondaryBold
+	if selected {
+		keyStyle = StylePrimaryBold
+	}
+	left := indent + marker + keyStyle.Render(n.key) + StyleDim.Render(": ")
+	room := max(width-lipgloss.Width(left), 10)
+
+	if n.kind != 0 {
+		brackets := "{}"
+		if n.kind == '[' {
+			brackets = "[]"
+		}
+		return left + StyleMuted.Render(brackets+" "+jsonContainerSize(n))
+	}
+	var buf bytes.Buffer
+	writeJSONScalar(&buf, n.value)
+	value := parser.Truncate(buf.String(), room)
+	if _, ok := n.value.(string); ok {
+		return left + StyleSecondary.Render(value)
+	}
+	return left + lipgloss.NewStyle().Foreground(ColorAccent).Render(value)
+}
+
+// renderJSONTreeInline renders the top-level keys of a large JSON input with
+// containers collapsed, followed by a hint to browse the rest. Returns false
+// for inputs small enough, or not structured enough, to show flat.
+func renderJSONTreeInline(input string, wrapWidth int) (string, bool) {
+	if strings.Count(input, "\n")+1 <= jsonTreeInlineLines {
+		return "", false
+	}
+	root, err := parseJSONTree(input)
+	if err != nil {
+		return "", false
+	}
+	var lines []string
+	for _, n := range visibleJSONRows(root) {
+		lines = append(lines, renderJSONTreeRow(n, false, wrapWidth))
+	}
+	lines = append(lines, StyleDim.Render("l browses the full input as a tree"))
+	return strings.Join(lines, "\n"), true
+}
+
+// openJSONTree switches to the tree browser for the tool input of item,
+// returning to the current view on exit. Flashes a notice instead when the
+// input isn't a JSON object or array.
+func (m *model) openJSONTree(item displayItem) tea.Cmd {
+	root, err := parseJSONTree(item.toolInput)
+	if (item.itemType != parser.ItemToolCall && item.itemType != parser.ItemSubagent) || err != nil {
+		m.flashStatus = "No JSON input to browse"
+		return flashClearCmd()
+	}
+	name := item.toolName
+	if name == "" {
+		name = item.subagentType
+	}
+	m.jsonTree = root
+	m.jsonTitle = name
+	m.jsonCursor = 0
+	m.jsonScroll = 0
+	m.jsonReturn = m.view
+	m.view = viewJSONTree
+	return nil
+}
+
+// updateJSONTree handles key events in the tree browser. l and h expand and
+// collapse, or step into the first child and out to the parent when there
+// is nothing left to expand or collapse.
+func (m model) updateJSONTree(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	rows := visibleJSONRows(m.jsonTree)
+	if len(rows) == 0 {
+		m.view = m.jsonReturn
+		return m, nil
+	}
+	m.jsonCursor = min(m.jsonCursor, len(rows)-1)
+	node := rows[m.jsonCursor]
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace":
+		m.view = m.jsonReturn
+		m.jsonTree = nil
+		return m, nil
+	case "j", "down":
+		if m.jsonCursor < len(rows)-1 {
+			m.jsonCursor++
+		}
+	case "k", "up":
+		if m.jsonCursor > 0 {
+			m.jsonCursor--
+		}
+	case "G":
+		m.jsonCursor = len(rows) - 1
+	case "g":
+		m.jsonCursor = 0
+	case "l", "right":
+		switch {
+		case node.kind == 0:
+		case !node.expanded:
+			node.expanded = true
+		case len(node.children) > 0:
+			m.jsonCursor++
+		}
+	case "h", "left":
+		if node.kind != 0 && node.expanded {
+			node.expanded = false
+		} else if node.parent != m.jsonTree {
+			m.setJSONCursor(node.parent)
+		}
+	case "enter", "tab":
+		node.expanded = node.kind != 0 && !node.expanded
+	case "L":
+		node.setExpanded(true)
+	case "H":
+		// Collapse everything and land on the top-level ancestor.
+		for node.parent != m.jsonTree {
+			node = node.parent
+		}
+		m.jsonTree.setExpanded(false)
+		m.jsonTree.expanded = true
+		m.setJSONCursor(node)
+	case "y":
+		m.flashStatus = "Copied " + node.key
+		return m, tea.Batch(tea.SetClipboard(node.text()), flashClearCmd())
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	m.ensureJSONCursorVisible()
+	return m, nil
+}
+
+// setJSONCursor moves the cursor to n, which must be visible.
+func (m *model) setJSONCursor(n *jsonNode) {
+	for i, row := range visibleJSONRows(m.jsonTree) {
+		if row == n {
+			m.jsonCursor = i
+			return
+		}
+	}
+}
+
+// jsonTreeViewHeight returns the visible rows (minus header and footer).
+func (m model) jsonTreeViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// ensureJSONCursorVisible adjusts jsonScroll so the cursor row is visible.
+func (m *model) ensureJSONCursorVisible() {
+	m.jsonScroll = scrollToShow(m.jsonScroll, m.jsonCursor, 1, m.jsonTreeViewHeight())
+}
+
+// viewJSONTreeBrowser renders the tool input tree.
+func (m model) viewJSONTreeBrowser() string {
+	width := m.clampWidth()
+
+	header := StyleAccentBold.Render(m.jsonTitle+" input") + " " +
+		StyleDim.Render("("+jsonContainerSize(m.jsonTree)+")") + "\n"
+
+	var lines []string
+	for i, n := range visibleJSONRows(m.jsonTree) {
+		sel := selectionIndicator(i == m.jsonCursor)
+		lines = append(lines, sel+renderJSONTreeRow(n, i == m.jsonCursor, width-lipgloss.Width(sel)))
+	}
+
+	content := header + "\n" + strings.Join(scrollWindow(lines, m.jsonTreeViewHeight(), m.jsonScroll), "\n")
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"l/h", "expand/collapse",
+		"L/H", "expand below/collapse all",
+		"y", "copy value",
+		"j/k", "nav",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// jsonRowKeys lists the keys of the visible rows, indented by depth.
+func jsonRowKeys(root *jsonNode) []string {
+	var keys []string
+	for _, n := range visibleJSONRows(root) {
+		keys = append(keys, strings.Repeat(".", n.depth)+n.key)
+	}
+	return keys
+}
+
+func TestParseJSONTree(t *testing.T) {
+	root, err := parseJSONTree(`{"file_path": "a.go", "edits": [{"old_string": "<a>", "new_string": "b"}, {"old_string": "c", "new_string": "d"}], "n": 1.50, "ok": null}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(jsonRowKeys(root), " "); got != "file_path edits n ok" {
+		t.Errorf("top-level rows = %q, want document order", got)
+	}
+
+	edits := root.children[1]
+	edits.expanded = true
+	edits.children[0].expanded = true
+	want := "file_path edits .[0] ..old_string ..new_string .[1] n ok"
+	if got := strings.Join(jsonRowKeys(root), " "); got != want {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+
+	if got := edits.children[0].text(); got != "{\n  \"old_string\": \"<a>\",\n  \"new_string\": \"b\"\n}" {
+		t.Errorf("object text = %q", got)
+	}
+	if got := root.children[0].text(); got != "a.go" {
+		t.Errorf("string text = %q, want unquoted", got)
+	}
+	if got := root.children[2].text(); got != "1.50" {
+		t.Errorf("number text = %q, want as written", got)
+	}
+
+	for _, bad := range []string{`"just a string"`, `{"a": 1} trailing`, `{"a": `} {
+		if _, err := parseJSONTree(bad); err == nil {
+			t.Errorf("parseJSONTree(%q) should fail", bad)
+		}
+	}
+}
+
+func TestRenderJSONTreeInline(t *testing.T) {
+	if _, ok := renderJSONTreeInline("{\n  \"a\": 1\n}", 80); ok {
+		t.Error("small inputs should render flat")
+	}
+
+	var edits []string
+	for i := range 20 {
+		edits = append(edits, fmt.Sprintf("{\"old_string\": \"o%d\", \"new_string\": \"n%d\"}", i, i))
+	}
+	input := displayItemFromParser(parser.DisplayItem{
+		Type:      parser.ItemToolCall,
+		ToolInput: []byte(`{"file_path": "a.go", "edits": [` + strings.Join(edits, ",") + `]}`),
+	}).toolInput
+	tree, ok := renderJSONTreeInline(input, 80)
+	if !ok {
+		t.Fatal("a 20-edit MultiEdit should render as a tree")
+	}
+	if !strings.Contains(tree, "edits: [] 20 items") || strings.Contains(tree, "o3") {
+		t.Errorf("tree should collapse the edits:\n%s", tree)
+	}
+}
+
+func TestUpdateJSONTree(t *testing.T) {
+	msg := claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemOutput, text: "plain"},
+			{itemType: parser.ItemToolCall, toolName: "MultiEdit", toolInput: `{"file_path": "a.go", "edits": [{"old_string": "x"}]}`},
+		}
+	})
+	m := detailModel(msg)
+	press := func(keys ...string) {
+		t.Helper()
+		for _, k := range keys {
+			result, _ := m.Update(key(k))
+			m = asModel(result)
+		}
+	}
+
+	press("l")
+	if m.view != viewDetail || m.flashStatus != "No JSON input to browse" {
+		t.Fatalf("l on text: view = %d, flash = %q", m.view, m.flashStatus)
+	}
+
+	m.detailCursor = 1
+	press("l")
+	if m.view != viewJSONTree || m.jsonTitle != "MultiEdit" {
+		t.Fatalf("l on a tool call should open its input tree, view = %d", m.view)
+	}
+
+	// l expands a container, then steps into it; h climbs out of a scalar
+	// or collapsed container and collapses an expanded one.
+	press("j", "l", "l", "l", "l")
+	if got := strings.Join(jsonRowKeys(m.jsonTree), " "); got != "file_path edits .[0] ..old_string" {
+		t.Errorf("rows = %q", got)
+	}
+	if m.jsonCursor != 3 {
+		t.Errorf("cursor = %d, want 3 (old_string)", m.jsonCursor)
+	}
+	press("h", "h", "h")
+	if m.jsonCursor != 1 || m.jsonTree.children[1].children[0].expanded {
+		t.Errorf("h should collapse [0] and climb to edits: cursor = %d, want 1", m.jsonCursor)
+	}
+	press("H")
+	if got := len(visibleJSONRows(m.jsonTree)); got != 2 || m.jsonCursor != 1 {
+		t.Errorf("H: %d rows, cursor %d; want 2 rows, cursor on edits", got, m.jsonCursor)
+	}
+	press("L")
+	if got := len(visibleJSONRows(m.jsonTree)); got != 4 {
+		t.Errorf("L: %d rows, want everything under edits expanded", got)
+	}
+
+	press("q")
+	if m.view != viewDetail || m.jsonTree != nil {
+		t.Errorf("q should return to the detail view")
+	}
+}
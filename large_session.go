@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"golang.org/x/term"
+)
+
+// defaultLargeSession is the size past which opening a session asks whether
+// to read only its last turns.
+const defaultLargeSession = 100 << 20
+
+// defaultReducedTurns is how many turns a large session opened reduced
+// starts with.
+const defaultReducedTurns = 50
+
+// Answers to the large session question that --full and --reduced give up
+// front. Without either, it is asked.
+const (
+	largeFull    = "full"
+	largeReduced = "reduced"
+)
+
+// parseSize reads a byte size: "100MB", "1.5GB", "512KB", or a plain byte
+// count. Units are binary (1KB = 1024 bytes).
+func parseSize(s string) (int64, error) {
+	num := strings.TrimSpace(strings.ToUpper(s))
+	mult := 1.0
+	for _, u := range []struct {
+		suffix string
+		mult   float64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if n, ok := strings.CutSuffix(num, u.suffix); ok {
+			num, mult = strings.TrimSpace(n), u.mult
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("unrecognized size %q (want 100MB or 1.5GB)", s)
+	}
+	return int64(f * mult), nil
+}
+
+// largeSessionTurns decides how much of the session at src to read on
+// startup: 0 for all of it, or turns for its last turns. Only a session
+// past threshold is read reduced, as mode says or, with no mode, as the
+// user answers on the terminal. Without a terminal to ask on it opens
+// reduced.
+func largeSessionTurns(src parser.SessionSource, threshold int64, turns int, mode string) int {
+	size, err := src.Size()
+	if err != nil || threshold <= 0 || size <= threshold || mode == largeFull {
+		return 0
+	}
+	if mode == largeReduced || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return turns
+	}
+	fmt.Fprintf(os.Stderr, "%s is %s. Open only its last %s? Earlier history loads on scroll-up or with L. [Y/n] ",
+		filepath.Base(src.Name()), formatBytes(float64(size)), pluralCount(turns, "turn"))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "n", "no":
+		return 0
+	}
+	return turns
+}
+
+// watchWindow returns the turns a session's watcher keeps resident: the
+// --window setting, or for a session opened reduced without one its
+// reduced turns, so tailing doesn't grow it back to full size.
+func watchWindow(windowTurns, reducedTurns int, result loadResult) int {
+	if result.windowStart > 0 && windowTurns == 0 {
+		return reducedTurns
+	}
+	return windowTurns
+}
+
+// openSession loads the session at path from the picker or project
+// search. A session past the large session size asks first, unless
+// --full or --reduced answered.
+func (m *model) openSession(path string) tea.Cmd {
+	info, err := os.Stat(path)
+	if err != nil || m.largeSession <= 0 || info.Size() <= m.largeSession {
+		return loadSessionCmd(path, 0)
+	}
+	switch m.largeMode {
+	case largeFull:
+		return loadSessionCmd(path, 0)
+	case largeReduced:
+		return loadSessionCmd(path, m.reducedTurns)
+	}
+	m.largeConfirm, m.largeConfirmSize = path, info.Size()
+	return nil
+}
+
+// updateLargeConfirm answers the large session question: y or enter opens
+// the last turns, f reads the whole session, and any other key cancels.
+func (m model) updateLargeConfirm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	path := m.largeConfirm
+	m.largeConfirm, m.largeConfirmSize = "", 0
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y", "enter":
+		return m, loadSessionCmd(path, m.reducedTurns)
+	case "f":
+		return m, loadSessionCmd(path, 0)
+	}
+	m.pendingHit = nil
+	return m, nil
+}
+
+// renderLargeConfirm renders the large session question in the info bar.
+func (m model) renderLargeConfirm() string {
+	question := fmt.Sprintf("%s is %s. Open only its last %s? y/enter yes · f full history · other key cancels",
+		filepath.Base(m.largeConfirm), formatBytes(float64(m.largeConfirmSize)), pluralCount(m.reducedTurns, "turn"))
+	return " " + StyleErrorBold.Render(question)
+}
+
+// loadEarlierHistory asks the watcher for the turns before the resident
+// ones: evicted by --window, or never read in a session opened reduced.
+func (m *model) loadEarlierHistory() tea.Cmd {
+	if m.watcher == nil || (m.evictedTurns == 0 && !m.partialHistory) {
+		return nil
+	}
+	m.loadingHistory = true
+	if m.partialHistory {
+		m.flashStatus = "Loading earlier history from disk"
+	} else {
+		m.flashStatus = fmt.Sprintf("Loading %s from disk", pluralCount(m.evictedTurns, "evicted turn"))
+	}
+	m.watcher.requestHistory(true)
+	return flashClearCmd()
+}
+
+// scrollPastTop loads the unread start of a session opened reduced when
+// the list, in session order, is moved up past its top.
+func (m *model) scrollPastTop() tea.Cmd {
+	if !m.partialHistory || m.listSort != sortChronological {
+		return nil
+	}
+	return m.loadEarlierHistory()
+}
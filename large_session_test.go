@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		err  bool
+	}{
+		{"100MB", 100 << 20, false},
+		{"1.5gb", 3 << 29, false},
+		{"512 KB", 512 << 10, false},
+		{"2048", 2048, false},
+		{"0", 0, false},
+		{"big", 0, true},
+		{"-1MB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d, err %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestLoadSourceReduced(t *testing.T) {
+	path := writeTurns(t, 5)
+	result, err := loadSource(parser.FileSource(path), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.windowStart == 0 || len(result.classified) != 4 {
+		t.Fatalf("reduced load: start %d, %d messages; want > 0, 4", result.windowStart, len(result.classified))
+	}
+
+	// The watcher starts partial and loads the unread turns on request.
+	w := newSessionWatcher(parser.FileSource(path), result.classified, result.offset)
+	w.lineOffsets = result.lineOffsets
+	w.window = watchWindow(0, 2, result)
+	w.startPartial(result.windowStart)
+	if w.window != 2 || !w.partial || w.evict() {
+		t.Fatalf("window %d, partial %v; want 2, true, and nothing to evict", w.window, w.partial)
+	}
+	w.setFullHistory(true)
+	if w.partial || !w.fullHistory || len(w.allClassified) != 10 || firstPrompt(w) != "prompt 0" {
+		t.Errorf("after loading history: partial %v, full %v, %d messages from %q; want false, true, 10 from prompt 0",
+			w.partial, w.fullHistory, len(w.allClassified), firstPrompt(w))
+	}
+}
+
+func TestOpenSessionAsksForLargeSessions(t *testing.T) {
+	path := writeTurns(t, 3)
+	m := testModel()
+	m.largeSession, m.reducedTurns = 10, 2
+
+	if cmd := m.openSession(path); cmd != nil || m.largeConfirm != path {
+		t.Fatalf("openSession = %v, confirm %q; want no load and a question", cmd, m.largeConfirm)
+	}
+	if got := plain(m.renderInfoBar()); !strings.Contains(got, "session.jsonl is") || !strings.Contains(got, "last 2 turns?") {
+		t.Errorf("info bar = %q, want the question", got)
+	}
+	result, cmd := m.Update(key("x"))
+	m = asModel(result)
+	if cmd != nil || m.largeConfirm != "" {
+		t.Errorf("x: cmd %v, confirm %q; want cancelled", cmd, m.largeConfirm)
+	}
+
+	m.openSession(path)
+	result, cmd = m.Update(key("enter"))
+	m = asModel(result)
+	if cmd == nil || m.largeConfirm != "" {
+		t.Fatal("enter: want the session loading")
+	}
+	if msg, ok := cmd().(loadSessionMsg); !ok || msg.windowStart == 0 {
+		t.Errorf("enter loaded %+v, want the last turns only", msg)
+	}
+
+	m.largeMode = largeFull
+	if cmd := m.openSession(path); cmd == nil || m.largeConfirm != "" {
+		t.Error("--full: want the session loading without a question")
+	}
+	m.largeSession = 0
+	m.largeMode = ""
+	if cmd := m.openSession(path); cmd == nil || m.largeConfirm != "" {
+		t.Error(`largeSession "0": want the session loading without a question`)
+	}
+}
+
+func TestReducedSessionLoadsHistoryOnScrollUp(t *testing.T) {
+	m := testModel()
+	m.watcher = newSessionWatcher(parser.FileSource(writeTurns(t, 1)), nil, 0)
+	m.partialHistory = true
+	m.cursor = 0
+
+	result, _ := m.Update(key("k"))
+	m = asModel(result)
+	if !m.loadingHistory || !strings.Contains(m.flashStatus, "earlier history") {
+		t.Fatalf("k at the top: loading %v, flash %q; want the history requested", m.loadingHistory, m.flashStatus)
+	}
+	if full := <-m.watcher.history; !full {
+		t.Error("requested history = false, want true")
+	}
+
+	// The earlier turns land in front; the cursor stays on its message.
+	msgs := append([]message{userMsg("earlier"), claudeMsg()}, m.messages...)
+	result, _ = m.Update(tailUpdateMsg{messages: msgs})
+	m = asModel(result)
+	if m.partialHistory || m.loadingHistory || m.cursor != 2 {
+		t.Errorf("after load: partial %v, loading %v, cursor %d; want false, false, 2", m.partialHistory, m.loadingHistory, m.cursor)
+	}
+}
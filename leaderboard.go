@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// untypedAgent labels subagents whose Task call named no subagent_type.
+const untypedAgent = "(untyped)"
+
+// agentStats is one row of the agent leaderboard: every run of a subagent
+// type across the project's sessions.
+type agentStats struct {
+	agentType  string
+	runs       int
+	sessions   int   // sessions that used the type
+	durationMs int64 // summed over runs
+	tokens     int   // summed over runs
+	completed  int   // runs that answered and stopped on their own
+	failed     int   // runs interrupted, errored, or out of context
+	running    int   // runs still writing; left out of the success rate
+}
+
+// finished is the runs counted in the success rate.
+func (a agentStats) finished() int {
+	return a.runs - a.running
+}
+
+// successRate is the share of finished runs that completed, or -1 when none
+// finished. A run that stopped mid-turn without a trace counts as failed.
+func (a agentStats) successRate() float64 {
+	if a.finished() == 0 {
+		return -1
+	}
+	return float64(a.completed) / float64(a.finished())
+}
+
+// agentLeaderboardMsg delivers the leaderboard built off the UI goroutine.
+type agentLeaderboardMsg struct {
+	rows     []agentStats
+	sessions int // sessions that ran any subagent
+}
+
+// buildAgentLeaderboard aggregates the subagents of every session by type,
+// most-used first. Sessions are parsed in parallel, as the digest does;
+// ones that fail to parse are skipped. Once ctx ends no more sessions are
+// parsed, and the error says why.
+func buildAgentLeaderboard(ctx context.Context, sessions []parser.SessionInfo, now time.Time) ([]agentStats, int, error) {
+	perSession := make([][]parser.SubagentProcess, len(sessions))
+	err := parser.ForEachParallel(ctx, len(sessions), parser.ScanWorkers, func(i int) {
+		perSession[i] = sessionSubagents(sessions[i].Path)
+	})
+	byType := make(map[string]*agentStats)
+	used := 0
+	for _, procs := range perSession {
+		if len(procs) > 0 {
+			used++
+		}
+		seen := make(map[string]bool)
+		for _, p := range procs {
+			t := p.SubagentType
+			if t == "" {
+				t = untypedAgent
+			}
+			a := byType[t]
+			if a == nil {
+				a = &agentStats{agentType: t}
+				byType[t] = a
+			}
+			if !seen[t] {
+				seen[t] = true
+				a.sessions++
+			}
+			a.add(p, now)
+		}
+	}
+	rows := make([]agentStats, 0, len(byType))
+	for _, a := range byType {
+		rows = append(rows, *a)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].runs != rows[j].runs {
+			return rows[i].runs > rows[j].runs
+		}
+		return rows[i].agentType < rows[j].agentType
+	})
+	return rows, used, err
+}
+
+// add folds one run into the row.
+func (a *agentStats) add(p parser.SubagentProcess, now time.Time) {
+	a.runs++
+	a.durationMs += p.DurationMs
+	a.tokens += p.Usage.TotalTokens()
+	switch {
+	case p.EndState == parser.EndCompleted:
+		a.completed++
+	case p.EndState == parser.EndUnknown && !parser.Ongoing.Stale(p.FileModTime, now):
+		a.running++
+	default:
+		a.failed++
+	}
+}
+
+// sessionSubagents parses one session and returns its subagents, nested ones
+// included, with their types taken from the Task calls that spawned them.
+func sessionSubagents(path string) []parser.SubagentProcess {
+	subagents, _ := parser.DiscoverSubagents(path)
+	if len(subagents) == 0 {
+		return nil
+	}
+	classified, _, _, err := parser.ReadSessionIncrementalOffsets(path, 0)
+	if err != nil {
+		return nil
+	}
+	parser.LinkSubagents(subagents, parser.BuildChunks(classified), path)
+	parser.LinkNestedSubagents(subagents)
+	return subagents
+}
+
+// agentLeaderboardCmd builds the leaderboard off the UI goroutine. A build
+// cut short by ctx reports nothing: the view it was for is gone.
+func agentLeaderboardCmd(ctx context.Context, sessions []parser.SessionInfo) tea.Cmd {
+	return func() tea.Msg {
+		rows, used, err := buildAgentLeaderboard(ctx, sessions, time.Now())
+		if err != nil {
+			return nil
+		}
+		return agentLeaderboardMsg{rows: rows, sessions: used}
+	}
+}
+
+// openLeaderboard switches to the leaderboard view and starts building it.
+func (m *model) openLeaderboard() tea.Cmd {
+	m.leaderboard = nil
+	m.leaderboardLoading = true
+	m.leaderboardScroll = 0
+	m.view = viewLeaderboard
+	return agentLeaderboardCmd(m.viewContext(), m.pickerSessions)
+}
+
+// updateLeaderboard handles key events in the leaderboard view.
+func (m model) updateLeaderboard(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "A":
+		m.view = viewPicker
+	case "j", "down":
+		m.leaderboardScroll++
+	case "k", "up":
+		m.leaderboardScroll--
+	case "G":
+		m.leaderboardScroll = len(m.leaderboard)
+	case "g":
+		m.leaderboardScroll = 0
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	m.leaderboardScroll = max(min(m.leaderboardScroll, len(m.leaderboard)-m.leaderboardViewHeight()), 0)
+	return m, nil
+}
+
+// leaderboardViewHeight returns the visible rows (minus title, column
+// header, and footer).
+func (m model) leaderboardViewHeight() int {
+	return m.panelHeight(4)
+}
+
+// leaderboardRow formats one row of the table; the header uses it too so the
+// columns line up.
+func leaderboardRow(agentType, runs, sessions, avgTime, avgTokens, success string, width int) string {
+	nameWidth := max(min(width-50, 40), 12)
+	return fmt.Sprintf("%-*s %6s %8s %9s %10s %8s", nameWidth, parser.Truncate(agentType, nameWidth),
+		runs, sessions, avgTime, avgTokens, success)
+}
+
+// renderLeaderboardRow renders one subagent type's averages and success rate.
+func renderLeaderboardRow(a agentStats, width int) string {
+	success := "-"
+	if rate := a.successRate(); rate >= 0 {
+		success = fmt.Sprintf("%.0f%%", rate*100)
+	}
+	row := leaderboardRow(a.agentType,
+		formatCount(a.runs),
+		formatCount(a.sessions),
+		localRender.Duration(a.durationMs/int64(a.runs)),
+		formatTokens(a.tokens/a.runs),
+		success, width)
+	if a.running > 0 {
+		row += StyleDim.Render(fmt.Sprintf("  %d running", a.running))
+	}
+	return row
+}
+
+// viewLeaderboard renders the agent leaderboard: one row per subagent type
+// used in the project, with its average run and how often it completed.
+func (m model) viewLeaderboard() string {
+	width := m.clampWidth()
+
+	header := StyleAccentBold.Render("Agent leaderboard")
+	var body string
+	switch {
+	case m.leaderboardLoading:
+		body = StyleDim.Render(fmt.Sprintf("Reading subagents of %s%s", pluralCount(len(m.pickerSessions), "session"), Icon.Ellipsis.Glyph))
+	case len(m.leaderboard) == 0:
+		body = StyleDim.Render("No subagents in this project's sessions.")
+	default:
+		runs := 0
+		for _, a := range m.leaderboard {
+			runs += a.runs
+		}
+		header += " " + StyleDim.Render(fmt.Sprintf("%s across %s", pluralCount(runs, "run"), parser.Plural(m.leaderboardSessions, "session")))
+		var lines []string
+		for _, a := range m.leaderboard {
+			lines = append(lines, renderLeaderboardRow(a, width))
+		}
+		body = StyleMuted.Render(leaderboardRow("type", "runs", "sessions", "avg time", "avg tokens", "success", width)) + "\n" +
+			strings.Join(scrollWindow(lines, m.leaderboardViewHeight(), m.leaderboardScroll), "\n")
+	}
+	content := centerBlock(header+"\n\n"+body, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"j/k", "scroll",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestAgentStatsAdd(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var a agentStats
+	for _, p := range []parser.SubagentProcess{
+		{DurationMs: 60_000, Usage: parser.Usage{InputTokens: 1000}, EndState: parser.EndCompleted},
+		{DurationMs: 20_000, Usage: parser.Usage{OutputTokens: 500}, EndState: parser.EndErrored},
+		{DurationMs: 10_000, EndState: parser.EndUnknown, FileModTime: now.Add(-time.Hour)},
+		{DurationMs: 5_000, EndState: parser.EndUnknown, FileModTime: now.Add(-10 * time.Second)},
+	} {
+		a.add(p, now)
+	}
+	if a.runs != 4 || a.completed != 1 || a.failed != 2 || a.running != 1 {
+		t.Errorf("stats = %+v, want 4 runs: 1 completed, 2 failed, 1 running", a)
+	}
+	if a.durationMs != 95_000 || a.tokens != 1500 {
+		t.Errorf("totals = %dms %d tokens, want 95000ms 1500 tokens", a.durationMs, a.tokens)
+	}
+	if rate := a.successRate(); rate < 0.333 || rate > 0.334 {
+		t.Errorf("successRate = %v, want 1/3 (running run left out)", rate)
+	}
+	if rate := (agentStats{runs: 1, running: 1}).successRate(); rate != -1 {
+		t.Errorf("successRate with nothing finished = %v, want -1", rate)
+	}
+}
+
+func TestBuildAgentLeaderboard(t *testing.T) {
+	sessions := []parser.SessionInfo{
+		{Path: "parser/testdata/test-session.jsonl"},
+		{Path: "parser/testdata/multi_turn.jsonl"}, // no subagents
+	}
+	rows, used, _ := buildAgentLeaderboard(context.Background(), sessions, time.Now())
+	if used != 1 {
+		t.Errorf("sessions with subagents = %d, want 1", used)
+	}
+	runs := 0
+	for _, a := range rows {
+		runs += a.runs
+		if a.sessions != 1 {
+			t.Errorf("%s: sessions = %d, want 1", a.agentType, a.sessions)
+		}
+	}
+	if runs != 2 {
+		t.Errorf("runs = %d, want 2 (compact, warmup, and empty agents skipped): %+v", runs, rows)
+	}
+}
+
+func TestViewLeaderboard(t *testing.T) {
+	m := testModel()
+	m.view = viewLeaderboard
+	m.leaderboardSessions = 3
+	m.leaderboard = []agentStats{
+		{agentType: "Explore", runs: 4, sessions: 3, durationMs: 240_000, tokens: 40_000, completed: 3, failed: 1},
+		{agentType: "Plan", runs: 1, sessions: 1, durationMs: 30_000, tokens: 5_000, running: 1},
+	}
+	out := m.viewLeaderboard()
+	for _, want := range []string{"5 runs across 3 sessions", "avg tokens", "Explore", "75%", "1 running"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("leaderboard missing %q:\n%s", want, out)
+		}
+	}
+
+	m.view = viewPicker
+	m.pickerSessions = nil
+	result, cmd := m.updatePicker(key("A"))
+	m = asModel(result)
+	if m.view != viewLeaderboard || !m.leaderboardLoading || cmd == nil {
+		t.Errorf("A in picker: view = %d, loading = %v, cmd = %v; want leaderboard loading", m.view, m.leaderboardLoading, cmd)
+	}
+	result, _ = m.Update(agentLeaderboardMsg{rows: []agentStats{{agentType: "Explore", runs: 1}}, sessions: 1})
+	m = asModel(result)
+	if m.leaderboardLoading || len(m.leaderboard) != 1 {
+		t.Errorf("after load: loading = %v, rows = %d", m.leaderboardLoading, len(m.leaderboard))
+	}
+}
+
+func TestLeaderboard_LeavingCancelsBuild(t *testing.T) {
+	m := testModel()
+	m.view = viewPicker
+	m.openLeaderboard()
+	ctx := m.viewContext()
+	if ctx != m.viewContext() {
+		t.Fatal("the view's work should share one context")
+	}
+	result, _ := m.Update(key("esc"))
+	m = asModel(result)
+	if m.view != viewPicker {
+		t.Fatalf("view = %v, want the picker", m.view)
+	}
+	if ctx.Err() == nil {
+		t.Error("leaving the leaderboard should cancel its build")
+	}
+	if m.viewWork != nil {
+		t.Error("the cancelled work should be dropped")
+	}
+}
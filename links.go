@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// reURL matches http(s) URLs in free text. Closing brackets and quotes end a
+// URL so Markdown links and quoted strings don't swallow their delimiters.
+var reURL = regexp.MustCompile("https?://[^\\s<>\"'`)\\]}]+")
+
+// linkOpenedMsg reports the outcome of handing a URL to the browser.
+type linkOpenedMsg struct {
+	url string
+	err error
+}
+
+// extractURLs returns the URLs in text in order of appearance, trailing
+// sentence punctuation trimmed.
+func extractURLs(text string) []string {
+	var urls []string
+	for _, u := range reURL.FindAllString(text, -1) {
+		u = strings.TrimRight(u, ".,;:!?")
+		if strings.Count(u, "/") >= 2 && len(u) > len("https://") {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// messageLinks collects the distinct URLs in a message: its text, then each
+// item's text, tool input, and tool result, in order.
+func messageLinks(msg message) []string {
+	seen := make(map[string]bool)
+	var links []string
+	add := func(text string) {
+		for _, u := range extractURLs(text) {
+			if !seen[u] {
+				seen[u] = true
+				links = append(links, u)
+			}
+		}
+	}
+	add(msg.content)
+	for _, item := range msg.items {
+		add(item.text)
+		add(item.toolInput)
+		add(item.toolResult)
+		for _, r := range item.references {
+			add(r.URL)
+		}
+	}
+	return links
+}
+
+// browserCommand builds the command that opens url in the default browser.
+// A variable so tests can stub it.
+var browserCommand = func(url string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("open", url)
+	}
+	return exec.Command("xdg-open", url)
+}
+
+// openURLCmd starts the browser off the UI goroutine.
+func openURLCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		return linkOpenedMsg{url: url, err: browserCommand(url).Run()}
+	}
+}
+
+// openLinks switches to the link list for msg, returning to the current view
+// on exit. Flashes a notice instead when the message has no links.
+func (m *model) openLinks(msg message) tea.Cmd {
+	links := messageLinks(msg)
+	if len(links) == 0 {
+		m.flashStatus = "No links in this message"
+		return flashClearCmd()
+	}
+	m.links = links
+	m.linkCursor = 0
+	m.linkScroll = 0
+	m.linkReturn = m.view
+	m.view = viewLinks
+	return nil
+}
+
+// updateLinks handles key events in the link list. Digits 1-9 open the
+// numbered link directly.
+func (m model) updateLinks(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "u":
+		m.view = m.linkReturn
+	case "j", "down":
+		if m.linkCursor < len(m.links)-1 {
+			m.linkCursor++
+		}
+		m.ensureLinkVisible()
+	case "k", "up":
+		if m.linkCursor > 0 {
+			m.linkCursor--
+		}
+		m.ensureLinkVisible()
+	case "G":
+		m.linkCursor = max(len(m.links)-1, 0)
+		m.ensureLinkVisible()
+	case "g":
+		m.linkCursor = 0
+		m.linkScroll = 0
+	case "enter":
+		if m.linkCursor < len(m.links) {
+			return m, openURLCmd(m.links[m.linkCursor])
+		}
+	case "y":
+		if m.linkCursor < len(m.links) {
+			url := m.links[m.linkCursor]
+			m.flashStatus = "Copied: " + url
+			return m, tea.Batch(tea.SetClipboard(url), flashClearCmd())
+		}
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	default:
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+			if i := int(key[0] - '1'); i < len(m.links) {
+				m.linkCursor = i
+				m.ensureLinkVisible()
+				return m, openURLCmd(m.links[i])
+			}
+		}
+	}
+	return m, nil
+}
+
+// linkViewHeight returns the visible rows (minus header and footer).
+func (m model) linkViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// ensureLinkVisible adjusts linkScroll so the cursor row is visible.
+func (m *model) ensureLinkVisible() {
+	m.linkScroll = scrollToShow(m.linkScroll, m.linkCursor, 1, m.linkViewHeight())
+}
+
+// viewLinkList renders the numbered links of the message.
+func (m model) viewLinkList() string {
+	width := m.clampWidth()
+
+	header := StyleAccentBold.Render("Links") + " " +
+		StyleDim.Render("("+parser.Plural(len(m.links), "link")+", 1-9 or Enter opens)") + "\n"
+
+	var lines []string
+	for i, url := range m.links {
+		sel := selectionIndicator(i == m.linkCursor)
+		num := "   "
+		if i < 9 {
+			num = fmt.Sprintf("%d. ", i+1)
+		}
+		style := StyleSecondary
+		if i == m.linkCursor {
+			style = StylePrimaryBold
+		}
+		room := max(width-lipgloss.Width(sel)-len(num)-1, 10)
+		lines = append(lines, sel+StyleAccentBold.Render(num)+style.Render(parser.Truncate(url, room)))
+	}
+
+	content := header + "\n" + strings.Join(scrollWindow(lines, m.linkViewHeight(), m.linkScroll), "\n")
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"1-9/enter", "open",
+		"y", "copy",
+		"j/k", "nav",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestExtractURLs(t *testing.T) {
+	text := "See https://go.dev/doc/effective_go. Also [the PR](https://github.com/o/r/pull/12), " +
+		"\"http://localhost:8080/health\" and https://example.com/a?b=1&c=2!"
+	want := []string{
+		"https://go.dev/doc/effective_go",
+		"https://github.com/o/r/pull/12",
+		"http://localhost:8080/health",
+		"https://example.com/a?b=1&c=2",
+	}
+	if got := extractURLs(text); !slices.Equal(got, want) {
+		t.Errorf("extractURLs =\n  %q\nwant\n  %q", got, want)
+	}
+	if got := extractURLs("no links, just https:// and text"); len(got) != 0 {
+		t.Errorf("extractURLs = %q, want none", got)
+	}
+}
+
+func TestMessageLinks(t *testing.T) {
+	msg := claudeMsg(func(m *message) {
+		m.content = "Docs: https://go.dev/doc"
+		m.items = []displayItem{
+			{toolInput: `{"url": "https://pkg.go.dev/net/http"}`},
+			{toolResult: "Fetched https://go.dev/doc again"},
+			{text: "Opened https://github.com/o/r/pull/12"},
+		}
+	})
+	want := []string{"https://go.dev/doc", "https://pkg.go.dev/net/http", "https://github.com/o/r/pull/12"}
+	if got := messageLinks(msg); !slices.Equal(got, want) {
+		t.Errorf("messageLinks = %q, want %q", got, want)
+	}
+}
+
+func TestUpdateLinks(t *testing.T) {
+	var opened []string
+	orig := browserCommand
+	browserCommand = func(url string) *exec.Cmd {
+		opened = append(opened, url)
+		return exec.Command("true")
+	}
+	t.Cleanup(func() { browserCommand = orig })
+
+	m := testModel()
+	m.messages = append(m.messages, claudeMsg(func(m *message) {
+		m.content = "https://a.example/one and https://b.example/two"
+	}))
+	m.cursor = len(m.messages) - 1
+
+	result, _ := m.Update(key("u"))
+	m = asModel(result)
+	if m.view != viewLinks || len(m.links) != 2 {
+		t.Fatalf("view = %d, links = %q; want viewLinks with 2 links", m.view, m.links)
+	}
+
+	result, cmd := m.Update(key("2"))
+	m = asModel(result)
+	if cmd == nil {
+		t.Fatal("2 should open the second link")
+	}
+	if got, ok := cmd().(linkOpenedMsg); !ok || got.url != "https://b.example/two" || got.err != nil {
+		t.Errorf("cmd() = %+v, want opened second link", got)
+	}
+	if !slices.Equal(opened, []string{"https://b.example/two"}) {
+		t.Errorf("browser opened %q", opened)
+	}
+
+	result, _ = m.Update(key("q"))
+	m = asModel(result)
+	if m.view != viewList {
+		t.Errorf("q should return to the list view")
+	}
+
+	// A message without links flashes instead of opening the list.
+	m.cursor = 0
+	m.messages[0].content = "no links"
+	m.messages[0].items = nil
+	result, _ = m.Update(key("u"))
+	if got := asModel(result); got.view != viewList || got.flashStatus == "" {
+		t.Errorf("view = %d, flash = %q; want list view with a notice", got.view, got.flashStatus)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kylesnowschwartz/tail-claude/filter"
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// messageFilterVocab is what filter expressions can test on a message, in
+// the list's filter bar (=) and with --filter.
+var messageFilterVocab = filter.Vocabulary{
+	Fields: []string{"role", "system", "tool", "agent", "model", "text", "tokens", "duration"},
+	Flags:  []string{"error", "subagent", "thinking"},
+}
+
+// parseMessageFilter parses a filter expression over messages.
+func parseMessageFilter(src string) (filter.Expr, error) {
+	return filter.Parse(src, messageFilterVocab)
+}
+
+// messageRecord answers filter expressions for one message.
+type messageRecord struct{ msg *message }
+
+// Field returns role ("claude", "user", "system", ...), a system message's
+// kind (parser.SystemKind), the tools and subagent types a Claude turn
+// called, its model, its text (content, item text, summaries, inputs, and
+// results), and its tokens and duration in milliseconds.
+func (r messageRecord) Field(name string) []string {
+	msg := r.msg
+	switch name {
+	case "role":
+		return []string{msg.role}
+	case "system":
+		if msg.role != RoleSystem || msg.systemKind == "" {
+			return nil
+		}
+		return []string{string(msg.systemKind)}
+	case "model":
+		return []string{msg.model}
+	case "tokens":
+		return []string{strconv.Itoa(msg.tokensRaw)}
+	case "duration":
+		return []string{strconv.FormatInt(msg.durationMs, 10)}
+	}
+	var out []string
+	if name == "text" {
+		out = append(out, msg.content)
+	}
+	for _, item := range msg.items {
+		switch name {
+		case "tool":
+			if item.toolName != "" {
+				out = append(out, item.toolName)
+			}
+		case "agent":
+			if item.itemType == parser.ItemSubagent {
+				out = append(out, item.subagentType)
+			}
+		case "text":
+			out = append(out, item.text, item.toolSummary, item.toolInput, item.toolResult)
+		}
+	}
+	return out
+}
+
+// Flag reports error (failed tool calls, hooks, API requests, stderr),
+// subagent (spawned one), and thinking (has a thinking block).
+func (r messageRecord) Flag(name string) bool {
+	switch name {
+	case "error":
+		return messageErrors(*r.msg) > 0
+	case "subagent", "thinking":
+		want := parser.ItemSubagent
+		if name == "thinking" {
+			want = parser.ItemThinking
+		}
+		for _, item := range r.msg.items {
+			if item.itemType == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterMessages returns the messages expr matches, or msgs when expr is
+// nil.
+func filterMessages(msgs []message, expr filter.Expr) []message {
+	if expr == nil {
+		return msgs
+	}
+	out := make([]message, 0, len(msgs))
+	for i := range msgs {
+		if expr.Match(messageRecord{&msgs[i]}) {
+			out = append(out, msgs[i])
+		}
+	}
+	return out
+}
+
+// openFilterBar starts editing the list filter, beginning from the one in
+// force.
+func (m *model) openFilterBar() {
+	m.filterInput = true
+	m.filterErr = ""
+	m.filterDraft = ""
+	if m.listFilter != nil {
+		m.filterDraft = m.listFilter.String()
+	}
+}
+
+// updateFilterBar handles keys while the filter bar is open. Enter applies
+// the expression (an empty one clears the filter); a parse error stays in
+// the bar until the expression is fixed or esc abandons the edit.
+func (m model) updateFilterBar(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "enter":
+		if strings.TrimSpace(m.filterDraft) == "" {
+			m.filterInput = false
+			m.setListFilter(nil)
+			return m, nil
+		}
+		expr, err := parseMessageFilter(m.filterDraft)
+		if err != nil {
+			m.filterErr = err.Error()
+			return m, nil
+		}
+		m.filterInput = false
+		m.setListFilter(expr)
+	case "esc", "escape":
+		m.filterInput = false
+		m.layoutList()
+		m.clampListScroll()
+	case "backspace":
+		if len(m.filterDraft) > 0 {
+			_, size := utf8.DecodeLastRuneInString(m.filterDraft)
+			m.filterDraft = m.filterDraft[:len(m.filterDraft)-size]
+		}
+		m.filterErr = ""
+	case "space":
+		m.filterDraft += " "
+	default:
+		if len(key) == 1 && key[0] >= 32 && key[0] < 127 {
+			m.filterDraft += key
+			m.filterErr = ""
+		}
+	}
+	return m, nil
+}
+
+// setListFilter shows only the messages expr matches (all of them for nil)
+// and puts the cursor on the newest.
+func (m *model) setListFilter(expr filter.Expr) {
+	m.listFilter = expr
+	m.clearRange()
+	m.setMessages(m.rawMessages)
+	m.expanded = make(map[int]bool)
+	m.cursor = max(len(m.messages)-1, 0)
+	m.scroll = 0
+	m.layoutList()
+	m.ensureCursorVisible()
+}
+
+// filterBarHeight is the filter bar's height above the list: one line while
+// editing or filtered, else none.
+func (m model) filterBarHeight() int {
+	if m.filterInput || m.listFilter != nil {
+		return 1
+	}
+	return 0
+}
+
+// renderFilterBar renders the line above the list: the expression being
+// typed with a hint or its parse error, or the filter in force with how
+// many messages it kept.
+func (m model) renderFilterBar() string {
+	if m.filterInput {
+		line := " " + StyleAccentBold.Render("Filter: ") + m.filterDraft + StyleDim.Render("▏")
+		if m.filterErr != "" {
+			return line + "  " + StyleErrorBold.Render(m.filterErr)
+		}
+		return line + StyleDim.Render("  enter apply · esc cancel · e.g. role=claude AND tool=Bash AND error")
+	}
+	return " " + StyleAccentBold.Render("Filtered: ") + m.listFilter.String() +
+		StyleDim.Render(fmt.Sprintf(" · %d of %d messages · = edit · esc clear", len(m.messages), len(m.rawMessages)))
+}
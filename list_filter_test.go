@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestFilterMessages(t *testing.T) {
+	msgs := []message{
+		userMsg("run the tests"),
+		claudeMsg(func(m *message) {
+			m.tokensRaw = 30000
+			m.items = []displayItem{
+				{itemType: parser.ItemThinking, text: "which package?"},
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolSummary: "go test ./...", toolError: true},
+			}
+		}),
+		claudeMsg(func(m *message) {
+			m.tokensRaw = 800
+			m.items = []displayItem{
+				{itemType: parser.ItemSubagent, toolName: "Task", subagentType: "Explore"},
+			}
+		}),
+	}
+	tests := []struct {
+		src  string
+		want []int
+	}{
+		{"role=claude AND tool=Bash AND error", []int{1}},
+		{"role=claude NOT error", []int{2}},
+		{"agent=explore OR thinking", []int{1, 2}},
+		{"text~test", []int{0, 1}},
+		{"tokens>=1k", []int{1}},
+		{"subagent", []int{2}},
+	}
+	for _, tt := range tests {
+		expr, err := parseMessageFilter(tt.src)
+		if err != nil {
+			t.Fatalf("parseMessageFilter(%q): %v", tt.src, err)
+		}
+		var got []int
+		for i := range msgs {
+			if expr.Match(messageRecord{&msgs[i]}) {
+				got = append(got, i)
+			}
+		}
+		if len(got) != len(tt.want) || len(filterMessages(msgs, expr)) != len(tt.want) {
+			t.Errorf("%s: matched %v, want %v", tt.src, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: matched %v, want %v", tt.src, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// typeFilter opens the filter bar and types src.
+func typeFilter(m model, src string) model {
+	result, _ := m.Update(key("="))
+	m = asModel(result)
+	for _, r := range src {
+		k := string(r)
+		if k == " " {
+			k = "space"
+		}
+		result, _ = m.Update(key(k))
+		m = asModel(result)
+	}
+	return m
+}
+
+func TestFilterBar(t *testing.T) {
+	m := typeFilter(testModel(), "role=user")
+	if !m.filterInput || !strings.Contains(plain(m.renderFilterBar()), "Filter: role=user") {
+		t.Fatalf("filter bar = %q, want the draft", plain(m.renderFilterBar()))
+	}
+	result, _ := m.Update(key("enter"))
+	m = asModel(result)
+	if m.filterInput || len(m.messages) != 1 || m.messages[0].role != RoleUser {
+		t.Fatalf("after enter: %d messages, want the user message", len(m.messages))
+	}
+	if got := plain(m.renderFilterBar()); !strings.Contains(got, "Filtered: role=user · 1 of 3 messages") {
+		t.Errorf("filter bar = %q", got)
+	}
+
+	// A bad expression keeps the bar open with the error.
+	m = typeFilter(m, " AND bogus")
+	result, _ = m.Update(key("enter"))
+	m = asModel(result)
+	if !m.filterInput || !strings.Contains(m.filterErr, `unknown flag "bogus"`) {
+		t.Errorf("bad expression: input %v, error %q", m.filterInput, m.filterErr)
+	}
+	result, _ = m.Update(key("esc"))
+	m = asModel(result)
+	if m.filterInput || m.listFilter == nil {
+		t.Error("esc in the bar should keep the filter in force")
+	}
+
+	result, _ = m.Update(key("esc"))
+	m = asModel(result)
+	if m.listFilter != nil || len(m.messages) != 3 {
+		t.Errorf("esc in the list: %d messages, want the filter cleared", len(m.messages))
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import "slices"
+
+// listSort is the order of the message list.
+type listSort int
+
+const (
+	sortChronological listSort = iota // session order (default)
+	sortTokens                        // most tokens first
+	sortDuration                      // longest turn first
+	sortErrors                        // most errors first
+)
+
+// listSortCount is the number of orders S cycles through.
+const listSortCount = 4
+
+// messageErrors counts what went wrong in a message: failed tool calls and
+// hooks (subagent traces included via their items' flags), API errors, and
+// stderr or killed-task system output.
+func messageErrors(msg message) int {
+	n := len(msg.apiErrors)
+	if msg.isError {
+		n++
+	}
+	for _, item := range msg.items {
+		if item.toolError || item.hookError() {
+			n++
+		}
+	}
+	return n
+}
+
+// listSortKey returns the value msg is ordered by, larger first.
+func listSortKey(msg message, s listSort) int64 {
+	switch s {
+	case sortTokens:
+		return int64(msg.tokensRaw)
+	case sortDuration:
+		return msg.durationMs
+	case sortErrors:
+		return int64(messageErrors(msg))
+	}
+	return 0
+}
+
+// sortList recomputes the display order for the current sort. Equal keys
+// keep their session order. Chronological order needs no table.
+func (m *model) sortList() {
+	if m.listSort == sortChronological {
+		m.listOrder, m.listRank = nil, nil
+		return
+	}
+	order := make([]int, len(m.messages))
+	for i := range order {
+		order[i] = i
+	}
+	keys := make([]int64, len(m.messages))
+	for i, msg := range m.messages {
+		keys[i] = listSortKey(msg, m.listSort)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case keys[a] > keys[b]:
+			return -1
+		case keys[a] < keys[b]:
+			return 1
+		}
+		return 0
+	})
+	rank := make([]int, len(order))
+	for pos, i := range order {
+		rank[i] = pos
+	}
+	m.listOrder, m.listRank = order, rank
+}
+
+// listAt returns the index of the message shown at position pos of the list.
+// An order left stale by new messages, until the next layout re-sorts,
+// reads as session order.
+func (m model) listAt(pos int) int {
+	if len(m.listOrder) != len(m.messages) || pos >= len(m.listOrder) {
+		return pos
+	}
+	return m.listOrder[pos]
+}
+
+// listPos returns the position in the list of message i.
+func (m model) listPos(i int) int {
+	if len(m.listRank) != len(m.messages) || i >= len(m.listRank) {
+		return i
+	}
+	return m.listRank[i]
+}
+
+// moveListCursor moves the cursor delta positions through the list as
+// shown, stopping at either end. A folded turn is one position: its header.
+func (m *model) moveListCursor(delta int) {
+	if len(m.messages) == 0 {
+		return
+	}
+	pos := min(max(m.listPos(m.cursor)+delta, 0), len(m.messages)-1)
+	m.cursor = m.listAt(pos)
+	if t, folded := m.foldedTurnAt(m.cursor); folded && m.cursor != t.first {
+		m.cursor = t.first
+		if delta > 0 && t.last+1 < len(m.messages) {
+			m.cursor = t.last + 1
+		}
+	}
+}
+
+// cycleListSort switches the list to the next order, wrapping back to
+// chronological, and keeps the cursor's message in view.
+func (m *model) cycleListSort() {
+	m.setListSort((m.listSort + 1) % listSortCount)
+}
+
+// setListSort reorders the list and scrolls to the cursor's message.
+func (m *model) setListSort(s listSort) {
+	m.listSort = s
+	m.layoutList()
+	m.ensureCursorVisible()
+}
+
+// sortBannerHeight is the list's sorted banner height: one line while
+// sorted, else none.
+func (m model) sortBannerHeight() int {
+	if m.listSort == sortChronological {
+		return 0
+	}
+	return 1
+}
+
+// renderSortBanner renders the line above a sorted list saying how it is
+// ordered and how to get back: "Sorted by tokens, most first · S next order
+// · esc session order".
+func (m model) renderSortBanner() string {
+	what := map[listSort]string{
+		sortTokens:   "tokens, most first",
+		sortDuration: "duration, longest first",
+		sortErrors:   "errors, most first",
+	}[m.listSort]
+	return " " + StyleAccentBold.Render("Sorted by "+what) +
+		StyleDim.Render(" · S next order · esc session order")
+}
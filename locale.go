@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+	"github.com/kylesnowschwartz/tail-claude/render"
+)
+
+// numberFormat is how numbers are written for a locale: the decimal
+// separator and the thousands separator.
+type numberFormat struct {
+	decimal string
+	group   string
+}
+
+// nbsp groups thousands where the locale uses a space, so a number never
+// wraps across lines.
+const nbsp = "\u00a0"
+
+var (
+	pointComma = numberFormat{decimal: ".", group: ","}  // 1,234.5
+	commaPoint = numberFormat{decimal: ",", group: "."}  // 1.234,5
+	commaSpace = numberFormat{decimal: ",", group: nbsp} // 1 234,5
+	pointQuote = numberFormat{decimal: ".", group: "'"}  // 1'234.5
+)
+
+// numbers is the format every number shown goes through: formatTokens,
+// localRender, formatBytes, pluralCount. Set once at startup by
+// initNumberFormat, like the theme and icons; the default is English.
+var numbers = pointComma
+
+// localRender writes the render package's labels with numbers the way the
+// rest of the TUI writes them.
+var localRender = render.Options{Decimal: formatDecimal}
+
+// languageNumbers maps a language to its number format. Languages not
+// listed, English among them, write numbers the English way.
+var languageNumbers = map[string]numberFormat{
+	"da": commaPoint, "de": commaPoint, "el": commaPoint, "es": commaPoint,
+	"hr": commaPoint, "id": commaPoint, "it": commaPoint, "nl": commaPoint,
+	"pt": commaPoint, "ro": commaPoint, "sl": commaPoint, "sr": commaPoint,
+	"tr": commaPoint, "vi": commaPoint,
+	"bg": commaSpace, "cs": commaSpace, "et": commaSpace, "fi": commaSpace,
+	"fr": commaSpace, "hu": commaSpace, "lt": commaSpace, "lv": commaSpace,
+	"nb": commaSpace, "nn": commaSpace, "no": commaSpace, "pl": commaSpace,
+	"ru": commaSpace, "sk": commaSpace, "sv": commaSpace, "uk": commaSpace,
+}
+
+// regionNumbers holds the regions that write numbers differently from the
+// rest of their language.
+var regionNumbers = map[string]numberFormat{
+	"de_CH": pointQuote, "fr_CH": pointQuote, "it_CH": pointQuote,
+	"de_LI": pointQuote, "es_MX": pointComma, "es_US": pointComma,
+}
+
+// lookupNumberFormat returns the format for a POSIX locale name such as
+// "de_DE.UTF-8" or "fr". ok is false for a language it doesn't know.
+func lookupNumberFormat(locale string) (f numberFormat, ok bool) {
+	name, _, _ := strings.Cut(locale, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ReplaceAll(name, "-", "_") // BCP 47 "de-CH" too
+	lang, region, _ := strings.Cut(name, "_")
+	lang = strings.ToLower(lang)
+	if f, ok := regionNumbers[lang+"_"+strings.ToUpper(region)]; ok {
+		return f, true
+	}
+	if f, ok := languageNumbers[lang]; ok {
+		return f, true
+	}
+	switch lang {
+	case "", "c", "posix", "en", "ja", "zh", "ko", "he", "th", "hi":
+		return pointComma, true
+	}
+	return pointComma, false
+}
+
+// envLocale returns the locale numbers are formatted in, by POSIX
+// precedence: LC_ALL, then LC_NUMERIC, then LANG.
+func envLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// initNumberFormat sets the number format from the config's locale, or from
+// the environment when the config names none. An unknown configured locale
+// is reported and the environment used instead.
+func initNumberFormat(configured string) error {
+	if configured != "" {
+		if f, ok := lookupNumberFormat(configured); ok {
+			numbers = f
+			return nil
+		}
+	}
+	numbers, _ = lookupNumberFormat(envLocale())
+	if configured != "" {
+		return fmt.Errorf("unknown locale %q", configured)
+	}
+	return nil
+}
+
+// formatDecimal writes f with prec decimals in the current number format:
+// formatDecimal(1234.56, 1) is "1,234.6", or "1.234,6" in German.
+func formatDecimal(f float64, prec int) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	s = groupThousands(whole)
+	if hasFrac {
+		s += numbers.decimal + frac
+	}
+	return s
+}
+
+// formatCount writes an integer with thousands grouped: 12345 is "12,345".
+func formatCount(n int) string {
+	return groupThousands(strconv.Itoa(n))
+}
+
+// pluralCount is parser.Plural with the count written the locale's way, for
+// counts that run into the thousands: "1 234 sessions".
+func pluralCount(n int, noun string) string {
+	_, word, _ := strings.Cut(parser.Plural(n, noun), " ")
+	return formatCount(n) + " " + word
+}
+
+// groupThousands inserts the group separator into a string of digits with
+// an optional leading minus.
+func groupThousands(digits string) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= 3 {
+		return sign + digits
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	lead := len(digits) % 3
+	if lead == 0 {
+		lead = 3
+	}
+	b.WriteString(digits[:lead])
+	for i := lead; i < len(digits); i += 3 {
+		b.WriteString(numbers.group)
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+// withNumbers switches the number format for one test.
+func withNumbers(t *testing.T, f numberFormat) {
+	t.Helper()
+	prev := numbers
+	numbers = f
+	t.Cleanup(func() { numbers = prev })
+}
+
+func TestLookupNumberFormat(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   numberFormat
+		ok     bool
+	}{
+		{"", pointComma, true},
+		{"C", pointComma, true},
+		{"en_US.UTF-8", pointComma, true},
+		{"de_DE.UTF-8", commaPoint, true},
+		{"de_CH.UTF-8", pointQuote, true},
+		{"de-CH", pointQuote, true},
+		{"fr_FR.UTF-8@euro", commaSpace, true},
+		{"es_MX", pointComma, true},
+		{"pt_BR", commaPoint, true},
+		{"sv", commaSpace, true},
+		{"klingon", pointComma, false},
+	}
+	for _, tt := range tests {
+		got, ok := lookupNumberFormat(tt.locale)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("lookupNumberFormat(%q) = %+v, %v; want %+v, %v", tt.locale, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestInitNumberFormat(t *testing.T) {
+	withNumbers(t, pointComma)
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_NUMERIC", "de_DE.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+
+	if err := initNumberFormat(""); err != nil || numbers != commaPoint {
+		t.Errorf("from env: numbers = %+v, err = %v; want LC_NUMERIC over LANG", numbers, err)
+	}
+	if err := initNumberFormat("fr_FR"); err != nil || numbers != commaSpace {
+		t.Errorf("config override: numbers = %+v, err = %v", numbers, err)
+	}
+	if err := initNumberFormat("xx_YY"); err == nil || numbers != commaPoint {
+		t.Errorf("unknown override: numbers = %+v, err = %v; want an error and the env format", numbers, err)
+	}
+}
+
+func TestLocalizedFormatting(t *testing.T) {
+	tests := []struct {
+		name   string
+		format numberFormat
+		got    func() string
+		want   string
+	}{
+		{"tokens", commaPoint, func() string { return formatTokens(1234) }, "1,2k"},
+		{"tokens millions", commaSpace, func() string { return formatTokens(12_345_678) }, "12,3M"},
+		{"duration", commaPoint, func() string { return localRender.Duration(3500) }, "3,5s"},
+		{"long duration", pointQuote, func() string { return localRender.Duration(75_000_000) }, "1'250m 0s"},
+		{"bytes", commaPoint, func() string { return formatBytes(12700) }, "12,4 KB"},
+		{"count", commaSpace, func() string { return pluralCount(1_234_567, "token") }, "1 234 567 tokens"},
+		{"decimal grouped", commaPoint, func() string { return formatDecimal(-1234.56, 1) }, "-1.234,6"},
+		{"english", pointComma, func() string { return formatDecimal(1234567.891, 2) }, "1,234,567.89"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withNumbers(t, tt.format)
+			if got := tt.got(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// logger receives tail-claude's own diagnostics as leveled JSON: watcher
+// activity, errors shown in the banner, and (through parser.Log) transcript
+// lines the parser skipped. Discarded unless --log-file is given. The
+// in-TUI debug view (d) shows Claude Code's debug log, not these.
+var logger = slog.New(slog.DiscardHandler)
+
+// logLevels maps --log-level values to slog levels.
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// parseLogLevel parses a --log-level value.
+func parseLogLevel(s string) (slog.Level, error) {
+	if level, ok := logLevels[strings.ToLower(s)]; ok {
+		return level, nil
+	}
+	return 0, fmt.Errorf("unknown level %q (want debug, info, warn, or error)", s)
+}
+
+// logUIError logs an error the TUI surfaced as a flash status, which is
+// gone a few seconds later.
+func logUIError(msg string, err error, attrs ...any) {
+	logger.Error(msg, append([]any{"component", "ui", "err", err}, attrs...)...)
+}
+
+// openLogFile appends JSON log records at level and above to path, creating
+// it if needed, and points logger and parser.Log at it. The caller closes
+// the returned file on exit.
+func openLogFile(path string, level slog.Level) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	logger = slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: level})).With("pid", os.Getpid())
+	parser.Log = logger.With("component", "parser")
+	logger.Info("started", "version", currentVersion(), "args", os.Args[1:])
+	return f, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	if level, err := parseLogLevel("DEBUG"); err != nil || level != slog.LevelDebug {
+		t.Errorf("parseLogLevel(DEBUG) = %v, %v; want debug", level, err)
+	}
+	if _, err := parseLogLevel("verbose"); err == nil {
+		t.Error("parseLogLevel(verbose) accepted an unknown level")
+	}
+}
+
+func TestOpenLogFile(t *testing.T) {
+	defer func(l, p *slog.Logger) { logger, parser.Log = l, p }(logger, parser.Log)
+	path := filepath.Join(t.TempDir(), "tail-claude.log")
+
+	f, err := openLogFile(path, slog.LevelWarn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("dropped below the level")
+	logUIError("config not saved", errors.New("read-only file system"), "path", "/cfg.json")
+	parser.Log.Warn("skipped malformed line", "offset", 42)
+	f.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2 (warn and above):\n%s", len(lines), data)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if rec["level"] != "ERROR" || rec["component"] != "ui" || rec["err"] != "read-only file system" || rec["path"] != "/cfg.json" {
+		t.Errorf("UI error record = %v", rec)
+	}
+	if !strings.Contains(lines[1], `"component":"parser"`) {
+		t.Errorf("parser record = %s, want component parser", lines[1])
+	}
+}
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kylesnowschwartz/tail-claude/filter"
 	"github.com/kylesnowschwartz/tail-claude/parser"
 
 	tea "charm.land/bubbletea/v2"
@@ -19,17 +25,34 @@ const (
 	RoleUser    = "user"
 	RoleSystem  = "system"
 	RoleCompact = "compact"
+	RoleModel   = "model" // synthetic divider: model switched between AI turns
+	RoleMode    = "mode"  // synthetic divider: permission mode changed at a prompt
+	RoleCommand = "command"
+	RoleError   = "error" // failed API requests and their retries
 )
 
 // View states
 type viewState int
 
 const (
-	viewList   viewState = iota // message list (main view)
-	viewDetail                  // full-screen single message
-	viewPicker                  // session picker
-	viewDebug                   // debug log viewer
-	viewTeam                    // team task board
+	viewList          viewState = iota // message list (main view)
+	viewDetail                         // full-screen single message
+	viewPicker                         // session picker
+	viewDebug                          // debug log viewer
+	viewTeam                           // team task board
+	viewOutline                        // turn-by-turn table of contents
+	viewTools                          // tool visibility menu
+	viewSearch                         // text search across messages (and subagents)
+	viewFiles                          // files read/edited across the session and subagents
+	viewDrift                          // edited files compared with the working tree
+	viewLinks                          // numbered URLs found in a message
+	viewProjectSearch                  // text search across every session in the project
+	viewJSONTree                       // collapsible tree of a tool call's JSON input
+	viewMemory                         // CLAUDE.md and other instruction files the session runs under
+	viewLeaderboard                    // subagent types ranked across the project's sessions
+	viewBranches                       // branches of a forked session
+	viewCleanup                        // the project's sessions by size and age, to archive or delete
+	viewBasket                         // detail items collected across the session (B)
 )
 
 // staleSessionThreshold controls when an auto-discovered session is
@@ -120,13 +143,16 @@ type displayItem struct {
 	itemType        parser.DisplayItemType
 	text            string
 	toolName        string
+	toolID          string // tool_use ID
 	toolSummary     string
 	toolCategory    parser.ToolCategory
 	toolInput       string // formatted JSON for display
 	toolResult      string
 	toolError       bool
 	durationMs      int64
+	execMs          int64 // run time the tool reported; 0 when not recorded
 	tokenCount      int
+	timestamp       time.Time // when the tool was called
 	subagentType    string
 	subagentDesc    string
 	teamMemberName  string // team member name (e.g. "file-counter")
@@ -134,6 +160,21 @@ type displayItem struct {
 	teamColor       string                  // team color name (e.g. "blue", "green")
 	subagentProcess *parser.SubagentProcess // linked subagent execution trace
 	subagentOngoing bool                    // linked subagent session is still in progress
+	subagentStep    string                  // what an ongoing subagent is doing now: "Reading chunk.go…"
+	hooks           []parser.HookOutput     // hook output attributed to this item
+	patch           *parser.FilePatch       // Edit, MultiEdit, or Write: the change it made
+	background      *parser.BackgroundTask  // Bash run in the background: its lifecycle
+	references      []parser.Reference      // output: the sources its text cites
+}
+
+// hookError reports whether any hook attached to the item failed.
+func (d displayItem) hookError() bool {
+	for _, h := range d.hooks {
+		if h.IsError {
+			return true
+		}
+	}
+	return false
 }
 
 type message struct {
@@ -145,24 +186,54 @@ type message struct {
 	outputCount      int
 	tokensRaw        int
 	contextTokens    int // input + cache tokens (context window snapshot, excludes output)
+	contextDelta     int // contextTokens change since the previous Claude message
+	cacheRebuilt     int // tokens re-written to a warm prompt cache in this turn (cache busting)
 	durationMs       int64
 	timestamp        string
+	startedAt        time.Time    // when the message's first entry was written; Claude message: the turn's first response
+	uuid             string       // prompt or Claude message: the chunk's parser.Chunk.UUID, a key that survives reloads
+	usage            parser.Usage // Claude message: the turn's tokens by kind
 	items            []displayItem
 	lastOutput       *parser.LastOutput
-	subagentLabel    string // non-empty for trace views: "Explore", "Plan", etc.
-	teammateSpawns   int    // count of distinct team-spawned subagent Task calls
-	teammateMessages int    // count of distinct teammate IDs sending messages
-	isError          bool   // system message: bash stderr or killed task
+	summary          parser.TurnSummary      // one-line digest for outline and collapsed cards
+	subagentLabel    string                  // non-empty for trace views: "Explore", "Plan", etc.
+	teammateSpawns   int                     // count of distinct team-spawned subagent Task calls
+	teammateMessages int                     // count of distinct teammate IDs sending messages
+	isError          bool                    // system message: bash stderr, killed task, or error status
+	systemKind       parser.SystemKind       // system message: output, status, output style, or queue
+	systemLevel      string                  // system message: status level, "info", "warning", "error"
+	command          string                  // command message: "/review src/"
+	attachments      []parser.Attachment     // user message: @-mentioned context
+	attempt          int                     // user message: position in a retry group (1-based); 0 when not retried
+	attempts         int                     // user message: size of the retry group
+	permissionMode   string                  // user message: mode the prompt was sent in; mode divider: the mode switched to
+	branch           int                     // user message: place among the branches of the fork it opens (1-based)
+	branches         int                     // user message: branches of that fork; 0 when it opens none
+	hiddenToolCount  int                     // tool items removed by the visibility filter
+	loopTool         string                  // Claude message: tool repeated with identical input (possible loop)
+	loopCount        int                     // Claude message: identical calls so far, counting earlier consecutive messages
+	settings         parser.RequestSettings  // Claude message: request settings recorded for the turn
+	prevSettings     *parser.RequestSettings // Claude message: previous Claude message's settings; nil for the first
+	apiErrors        []parser.ErrorMsg       // error message: consecutive API errors; Claude message: errors during the turn
+	compactTrigger   string                  // compact message: "auto" or "manual"
+	compactPre       int                     // compact message: context tokens before compaction
+	compactPost      int                     // compact message: context tokens after compaction
+	compactSummary   string                  // compact message: summary the session continues from
+	turn             int                     // prompts up to and including this message; 0 before the first
 }
 
 // savedDetailState preserves parent detail view state when drilling into a
-// subagent trace. Restored on Escape.
+// subagent trace. Restored on Escape. Levels chain through parent, so nested
+// subagents drill to any depth and pop one level at a time.
 type savedDetailState struct {
 	cursor        int
 	scroll        int
 	expanded      map[int]bool
 	childExpanded map[visibleRowKey]bool
-	label         string // breadcrumb label for the parent view, e.g. "Claude opus4.6"
+	marked        map[visibleRowKey]bool
+	label         string            // breadcrumb label for the parent view, e.g. "Claude opus4.6"
+	traceMsg      *message          // trace shown at the parent level; nil for the top-level message
+	parent        *savedDetailState // next level up; nil at the top
 }
 
 type model struct {
@@ -172,12 +243,40 @@ type model struct {
 	width        int
 	height       int
 	scroll       int
-	listParts    []string // cached per-message rendered content, set by layoutList
+	listParts    []string // cached rendered content in list order, set by layoutList
 	lineOffsets  []int    // starting line of each message in rendered output
 	messageLines []int    // number of rendered lines per message
+	listSort     listSort // list order (S); messages stay in session order
+	listOrder    []int    // message index at each list position; nil in session order
+	listRank     []int    // list position of each message; nil in session order
+
+	// List filter (=); see list_filter.go.
+	listFilter  filter.Expr // messages shown; nil shows all
+	filterInput bool        // the filter bar is being edited
+	filterDraft string      // the expression being typed
+	filterErr   string      // why the draft didn't parse
 
 	totalRenderedLines int // total lines in list view, updated by layoutList
 
+	// Tail update throttling; see relayoutTail.
+	lastRelayout    time.Time // when a tail update last laid out the view
+	relayoutPending bool      // a layout is deferred to the end of the interval
+	relayoutFollow  bool      // the deferred layout follows the newest message
+	layoutShift     int       // messages evicted from the front since the layout on screen
+
+	denseList bool // compact list: one line per message (Z)
+
+	// Range selection in the list (v): anchor to cursor, in list order.
+	rangeActive   bool
+	rangeAnchor   int   // message index where the selection started
+	rangeSeq      int   // bumped on every change, to drop stale counts
+	rangeTokens   int   // the token counter's count; 0 until it reports
+	rangeCounting bool  // a token counter run is in flight
+	rangeCountErr error // the last run's failure
+
+	turnGroups  bool         // grouped list: each turn framed in a container (#)
+	foldedTurns map[int]bool // grouped list: turns folded to their header, by number
+
 	// Detail view state
 	view                viewState
 	detailScroll        int                    // scroll offset within the detail view
@@ -185,6 +284,9 @@ type model struct {
 	detailCursor        int                    // selected row in the flat visible-row list
 	detailExpanded      map[int]bool           // which parent items are expanded
 	detailChildExpanded map[visibleRowKey]bool // which child items have expanded content
+	detailMarked        map[visibleRowKey]bool // rows marked for bulk actions (space); parent rows use childIndex -1
+	detailFootnote      string                 // full summary of the selected row, shown above the footer until the next key
+	tableScroll         int                    // first column shown of tool results wider than the view (←/→)
 
 	// Markdown rendering
 	md *mdRenderer
@@ -192,17 +294,73 @@ type model struct {
 	// JSON syntax highlighting
 	jsonHL *jsonHL
 
+	// Frame timings for the hidden perf overlay (ctrl+p)
+	perf *perfStats
+
 	// Live tailing state
 	sessionPath     string
 	watching        bool
 	watcher         *sessionWatcher
 	tailSub         chan tailUpdateMsg
 	tailErrc        chan error
-	sessionOngoing  bool      // whether the watched session is still in progress
-	ongoingGraceSeq int       // sequence counter for grace period timers (stale timers ignored)
-	tickSeq         int       // sequence counter for tick chains (stale ticks from old chains ignored)
-	lastTailUpdate  time.Time // when the last tailUpdateMsg arrived (ongoing staleness failsafe)
-	animFrame       int       // animation frame counter for activity indicator
+	altSession      *parkedSession             // previous session, still watched; ctrl+o swaps back
+	hookState       webhookState               // webhook events already sent for this session
+	summaries       map[summaryKey]itemSummary // summarizer output (S), by content
+	turnSummaries   map[string]itemSummary     // turn summaries (E), by the turn's content hash
+	turnPopup       *turnSummaryPopup          // turn summary shown over the list, or nil
+	inputDiffs      map[string]inputDiff       // tool input diffs shown (D), by inputDiffKey
+	sessionOngoing  bool                       // whether the watched session is still in progress
+	ongoingWhy      parser.OngoingVerdict      // how the last read decided sessionOngoing (debug view, w)
+	ongoingGraceSeq int                        // sequence counter for grace period timers (stale timers ignored)
+	tickSeq         int                        // sequence counter for tick chains (stale ticks from old chains ignored)
+	lastTailUpdate  time.Time                  // when the last tailUpdateMsg arrived (ongoing staleness failsafe)
+	animFrame       int                        // animation frame counter for activity indicator
+
+	// Watcher polling, shown in the debug view.
+	pollRates chan pollRateMsg
+	pollBase  time.Duration // poll interval while the session is active
+	detect    string        // watcher change detection mode (changeDetection config)
+	reread    time.Duration // watcher forced re-read period; 0 disables
+	pollRate  pollRateMsg   // last interval reported by the watcher
+
+	growth growthRate // session file growth, shown in the info bar while tailing
+
+	// Resumed in another terminal: a file beside the session that carries it on
+	fork         *parser.Continuation // found and not yet followed or dismissed, or nil
+	forkConfirm  bool                 // R asked how to follow it
+	forkScanning bool                 // a check is running
+	forkSession  string               // session the fields below are for
+	forkSince    time.Time            // files written before this aren't checked
+	forkChecked  map[string]bool      // files ruled out or dismissed
+	forkEnds     *parser.EntryEnds    // the session's entries, read as it grows
+	mergedFrom   string               // original session shown ahead of its continuation (merged view), or ""
+
+	// Now marker: the divider above messages written within nowWindow.
+	nowWindow time.Duration // 0 turns the marker off
+	nowSeq    int           // sequence counter for expiry timers (stale timers ignored)
+
+	// Tail window (--window): turns evicted from memory, and whether they were
+	// reloaded with L. A large session opened reduced starts partial: the
+	// turns before its last reducedTurns are unread rather than evicted.
+	windowTurns    int
+	evictedTurns   int
+	fullHistory    bool
+	partialHistory bool
+	loadingHistory bool // earlier turns were asked for; the cursor keeps its message when they land
+
+	// Large sessions: opening one past largeSession bytes asks whether to
+	// read only its last reducedTurns, unless --full or --reduced answered.
+	largeSession     int64 // 0 never asks
+	reducedTurns     int
+	largeMode        string // largeFull, largeReduced, or "" to ask
+	largeConfirm     string // session awaiting the answer, or ""
+	largeConfirmSize int64
+
+	// Branches of a session forked by /rewind or a checkpoint restore, as
+	// the watcher last reported them; nil for a single-lineage session.
+	branches     []parser.Branch
+	branchCursor int
+	branchScroll int
 
 	// Subagent trace drill-down state
 	traceMsg    *message          // non-nil when viewing a subagent's execution trace
@@ -227,6 +385,7 @@ type model struct {
 	// CurrentProjectDir(). Exact match only -- no prefix expansion.
 	projectDir  string
 	projectDirs []string
+	workspace   *workspace // configured workspace the project belongs to, or nil
 
 	// Worktree session discovery
 	worktreeProjectDirs []string // extra project dirs from git worktrees (set once at startup)
@@ -239,38 +398,145 @@ type model struct {
 	pickerCursor          int
 	pickerScroll          int
 	pickerWatcher         *pickerWatcher
-	pickerAnimFrame       int          // spinner frame counter, incremented each tick
-	pickerHasOngoing      bool         // true when any session is still in progress
-	pickerTickActive      bool         // true while the picker tick loop is running
-	pickerLoading         bool         // true while initial session discovery is in progress
-	pickerOngoingGraceSeq int          // sequence counter for picker grace timers (stale timers ignored)
-	pickerExpanded        map[int]bool // tab-expanded previews in picker
-	pickerUniformModel    bool         // all sessions share the same model family
+	viewWork              *viewWork       // the current view's background scans; see view_work.go
+	pickerAnimFrame       int             // spinner frame counter, incremented each tick
+	pickerHasOngoing      bool            // true when any session is still in progress
+	pickerTickActive      bool            // true while the picker tick loop is running
+	pickerLoading         bool            // true while initial session discovery is in progress
+	pickerOngoingGraceSeq int             // sequence counter for picker grace timers (stale timers ignored)
+	pickerExpanded        map[int]bool    // tab-expanded previews in picker
+	pickerUniformModel    bool            // all sessions share the same model family
+	pickerMarked          map[string]bool // session paths marked for export (space)
 
 	// Team task board state
 	teams      []parser.TeamSnapshot
 	teamScroll int
+	todos      []parser.Todo // final TodoWrite list; its open items head the board
+
+	// Tool visibility. rawMessages is the unfiltered source for messages;
+	// see setMessages.
+	rawMessages    []message
+	approvalWait   string // buildApprovalWait's line over rawMessages, for the outline header
+	hiddenTools    map[string]bool
+	hiddenSystem   map[parser.SystemKind]bool // system message kinds left out of messages
+	cfg            config
+	configPath     string
+	toolMenuCursor int
+	toolMenuScroll int
+
+	// Search view state
+	searchQuery  string
+	searchInput  bool // true while the query prompt has focus
+	searchAgents bool // include subagent traces in results
+	searchCursor int
+	searchScroll int
+
+	// highlightQuery is marked wherever it appears in the list and detail
+	// views once a search hit is opened. Esc in the list clears it.
+	highlightQuery string
+
+	// Project search view state
+	projectQuery     string
+	projectInput     bool // true while the query prompt has focus
+	projectSearching bool // true while a search is running
+	projectSearchSeq int  // identifies the latest search; see projectSearchDoneMsg
+	projectHits      []projectHit
+	projectCursor    int
+	projectScroll    int
+	pendingHit       *projectHit         // hit to open once its session has loaded
+	searchIndex      *parser.SearchIndex // per-session trigram filters; nil with --no-index
+	viewers          *viewerRegistry     // heartbeat files shared with other instances; nil when unavailable
+	readOnly         bool                // --read-only: features that write to disk are off
+	otherViewers     int                 // other instances viewing the current session
+	indexing         bool                // true while a background index update runs
+	uiStateSaved     bool                // a signal saved the UI state on its way out
+
+	// File report view state
+	filesCursor int
+	filesScroll int
+
+	// Drift view state
+	driftEntries []driftEntry // last disk comparison
+	driftCursor  int
+	driftScroll  int
+
+	// Agent leaderboard view state
+	leaderboard         []agentStats
+	leaderboardSessions int  // sessions that ran any subagent
+	leaderboardLoading  bool // true until every session has been read
+	leaderboardScroll   int
+
+	// Cleanup view state
+	cleanupEntries []cleanupEntry
+	cleanupMarked  map[string]bool // session paths marked to archive or delete
+	cleanupCursor  int
+	cleanupScroll  int
+	cleanupLoading bool   // true until every session has been measured
+	cleanupBySize  bool   // largest first; oldest first otherwise
+	cleanupConfirm string // action awaiting y/n, or ""
+
+	// Onboarding tour (T): the view under the overlay is the real one
+	touring  bool
+	tourStep int // index into tourSteps
+
+	// Memory view state
+	memoryFiles   []memoryFile
+	memoryLoading bool // true until the files have been read
+	memoryIndex   int  // file shown
+	memoryScroll  int
+
+	// Link list state
+	links      []string // URLs of the message the list was opened on
+	linkCursor int
+	linkScroll int
+	linkReturn viewState // view to go back to
+
+	// Evidence basket: detail items kept across messages, for the session
+	basket        []basketEntry
+	basketCursor  int
+	basketScroll  int
+	basketPreview bool // tab: results shown under each entry
+
+	// JSON tree browser state
+	jsonTree   *jsonNode // root of the input being browsed; nodes hold their expansion
+	jsonTitle  string    // tool name
+	jsonCursor int       // index into the visible rows
+	jsonScroll int
+	jsonReturn viewState // view to go back to
+
+	// Outline view state
+	outlineCursor int // selected turn
+	outlineScroll int
 
 	// Debug log viewer state
 	debugEntries    []parser.DebugEntry // raw parsed entries (before filter/collapse)
 	debugFiltered   []parser.DebugEntry // after level filter + duplicate collapse
 	debugCursor     int
 	debugScroll     int
-	debugExpanded   map[int]bool      // which multi-line entries are expanded
-	debugMinLevel   parser.DebugLevel // current filter: LevelDebug (all), LevelWarn, LevelError
-	debugPath       string            // path to the debug .txt file
-	debugWatcher    *debugLogWatcher  // live tailing watcher for debug file
-	debugFilterText string            // text search query (stacks with level filter)
-	debugFilterMode bool              // true when the / input prompt is active
+	debugExpanded   map[int]bool           // which multi-line entries are expanded
+	debugMinLevel   parser.DebugLevel      // current filter: LevelDebug (all), LevelWarn, LevelError
+	debugPath       string                 // path to the debug .txt file
+	debugWatcher    *debugLogWatcher       // live tailing watcher for debug file
+	debugFilterText string                 // text search query (stacks with level filter)
+	debugFilterMode bool                   // true when the / input prompt is active
+	debugOngoing    bool                   // true when the why-ongoing panel is shown (w)
+	debugPickerWhy  *parser.OngoingVerdict // the picker's verdict, when the session is listed there
 
 	// Flash status (ephemeral notification in the info bar, e.g. "Copied: /path/to/file").
 	flashStatus string
+
+	// Watcher errors: a dismissible banner above the info bar, and a log
+	// merged into the debug view.
+	errorBanner    string // latest error's first line; empty when hidden
+	errorRepeats   int    // consecutive reports of the banner's error
+	errorBannerSeq int    // ties errorBannerClearMsg to the error that scheduled it
+	tailErrors     []parser.DebugEntry
 }
 
 // applyDebugFilters rebuilds debugFiltered from debugEntries using the current
 // level filter, text filter, and duplicate collapsing. Clamps cursor to valid range.
 func (m *model) applyDebugFilters() {
-	filtered := parser.FilterByLevel(m.debugEntries, m.debugMinLevel)
+	filtered := parser.FilterByLevel(withTailErrors(m.debugEntries, m.tailErrors), m.debugMinLevel)
 	filtered = parser.FilterByText(filtered, m.debugFilterText)
 	m.debugFiltered = parser.CollapseDuplicates(filtered)
 	if m.debugCursor >= len(m.debugFiltered) {
@@ -278,6 +544,19 @@ func (m *model) applyDebugFilters() {
 	}
 }
 
+// openDebugView shows the debug view over entries read from path (empty when
+// the session has no debug log), with tail errors merged in.
+func (m *model) openDebugView(entries []parser.DebugEntry, path string) {
+	m.debugEntries = entries
+	m.debugPath = path
+	m.debugCursor = 0
+	m.debugScroll = 0
+	m.debugMinLevel = parser.LevelDebug
+	m.debugExpanded = make(map[int]bool)
+	m.applyDebugFilters()
+	m.view = viewDebug
+}
+
 // stopDebugWatcher stops the debug log watcher if one is running.
 func (m *model) stopDebugWatcher() {
 	if m.debugWatcher != nil {
@@ -289,53 +568,91 @@ func (m *model) stopDebugWatcher() {
 // loadResult holds everything needed to bootstrap the TUI and watcher.
 type loadResult struct {
 	messages     []message
+	chunks       []parser.Chunk // what messages were converted from, for the plain-text renderer
 	teams        []parser.TeamSnapshot
+	todos        []parser.Todo // the last TodoWrite list
 	path         string
+	src          parser.SessionSource // where path is read from; nil means the local file
 	classified   []parser.ClassifiedMsg
+	lineOffsets  []int64 // file offset of each classified message's line
+	windowStart  int64   // where reading began; above 0 for a large session opened reduced
+	branches     []parser.Branch
 	offset       int64
 	ongoing      bool
+	ongoingWhy   parser.OngoingVerdict // how ongoing was decided, for the debug view
 	hasTeamTasks bool
 	meta         parser.SessionMeta // cwd, branch, permission mode
 }
 
+// source returns where the session is read from.
+func (r loadResult) source() parser.SessionSource {
+	if r.src == nil {
+		return parser.FileSource(r.path)
+	}
+	return r.src
+}
+
 // loadSession reads a JSONL session file and converts chunks to display messages.
 // The path must be non-empty — callers resolve auto-discovery before calling.
 func loadSession(path string) (loadResult, error) {
 	if path == "" {
 		return loadResult{}, fmt.Errorf("no session path provided")
 	}
+	return loadSource(parser.FileSource(path), 0)
+}
 
-	classified, offset, err := parser.ReadSessionIncremental(path, 0)
+// loadSource is loadSession for any session source, reading only the last
+// turns of it when turns is above 0 (a large session opened reduced).
+// Subagent traces, team sessions, and the staleness check need the
+// session's directory, so only local files get them.
+func loadSource(src parser.SessionSource, turns int) (loadResult, error) {
+	path := src.Name()
+	var classified []parser.ClassifiedMsg
+	var lineOffsets []int64
+	var start, offset int64
+	var err error
+	if turns > 0 {
+		classified, lineOffsets, start, offset, err = parser.ReadSourceTail(src, turns)
+	} else {
+		classified, lineOffsets, offset, err = parser.ReadSourceIncrementalOffsets(src, 0)
+	}
 	if err != nil {
 		return loadResult{}, fmt.Errorf("reading session %s: %w", path, err)
 	}
 
-	chunks := parser.BuildChunks(classified)
+	// A forked session shows its newest branch until another is picked.
+	lineage := parser.NewLineageFrom(start)
+	_ = lineage.ReadSource(src) // without it the session shows unfiltered
+	chunks := parser.BuildChunks(lineage.Apply(classified, lineOffsets, ""))
 	if len(chunks) == 0 {
 		return loadResult{}, fmt.Errorf("session %s has no messages", path)
 	}
 
 	// Discover and link subagent execution traces.
-	subagents, _ := parser.DiscoverSubagents(path)
-	teamProcs, _ := parser.DiscoverTeamSessions(path, chunks)
-	allProcs := append(subagents, teamProcs...)
-	colorMap := parser.LinkSubagents(allProcs, chunks, path)
+	var allProcs []parser.SubagentProcess
+	var colorMap map[string]string
+	_, local := parser.LocalPath(src)
+	if local {
+		subagents, _ := parser.DiscoverSubagents(path)
+		teamProcs, _ := parser.DiscoverTeamSessions(path, chunks)
+		allProcs = append(subagents, teamProcs...)
+		colorMap = parser.LinkSubagents(allProcs, chunks, path)
+		parser.LinkNestedSubagents(allProcs)
+	}
 
-	ongoing := parser.IsOngoing(chunks)
-	if !ongoing {
+	why := parser.ExplainOngoing(chunks)
+	if !why.Ongoing {
 		// Parent may be idle while subagents/team members are still working.
 		for i := range allProcs {
 			if parser.IsOngoing(allProcs[i].Chunks) {
-				ongoing = true
+				why.Override(true, "subagent "+allProcs[i].ID+" is still working: ongoing")
 				break
 			}
 		}
 	}
-	if ongoing {
+	if local {
 		if info, err := os.Stat(path); err == nil {
-			if time.Since(info.ModTime()) > parser.OngoingStalenessThreshold {
-				ongoing = false
-			}
+			why.CheckStaleness(info.ModTime(), time.Now())
 		}
 	}
 
@@ -343,52 +660,95 @@ func loadSession(path string) (loadResult, error) {
 
 	return loadResult{
 		messages:     chunksToMessages(chunks, allProcs, colorMap),
+		chunks:       chunks,
 		teams:        teams,
+		todos:        parser.FinalTodos(chunks),
 		path:         path,
+		src:          src,
 		classified:   classified,
+		lineOffsets:  lineOffsets,
+		windowStart:  start,
+		branches:     lineage.Branches(""),
 		offset:       offset,
-		ongoing:      ongoing,
+		ongoing:      why.Ongoing,
+		ongoingWhy:   why,
 		hasTeamTasks: hasTeamTaskItems(chunks),
-		meta:         parser.ExtractSessionMeta(path),
+		meta:         parser.ExtractSourceMeta(src),
 	}, nil
 }
 
-// switchSession replaces the current session with a new one, stopping the old
-// watcher and starting a new one. Centralizes the state reset that happens when
-// the user picks a different session from the picker.
+// switchSession replaces the current session with a new one and starts its
+// watcher. The outgoing session, watcher still running, becomes the alternate
+// that ctrl+o returns to. Centralizes the state reset that happens when the
+// user picks a different session from the picker.
 func (m model) switchSession(result loadResult) (model, tea.Cmd) {
-	if m.watcher != nil {
+	switch {
+	case m.watcher == nil:
+	case m.sessionPath == result.path:
 		m.watcher.stop()
+	default:
+		// A fresh load of the alternate replaces it.
+		m.dropAltSession()
+		m.altSession = m.parkSession()
 	}
 	m.stopDebugWatcher()
+	oldPath := m.sessionPath
 
-	m.messages = result.messages
+	m.setMessages(result.messages)
+	m.resetWebhookState()
 	m.teams = result.teams
+	m.todos = result.todos
 	m.teamScroll = 0
 	m.expanded = make(map[int]bool)
+	m.foldedTurns = make(map[int]bool)
+	m.clearRange()
 	m.resetDetailState()
+	m.highlightQuery = ""
+	m.basket, m.basketCursor, m.basketScroll = nil, 0, 0
+	m.turnPopup = nil
+	m.fork, m.forkConfirm = nil, false
+	m.mergedFrom = ""
+	if src, ok := result.src.(parser.MergedSource); ok {
+		m.mergedFrom = src.Base
+	}
 	m.cursor = 0
 	m.scroll = 0
 	m.sessionPath = result.path
 	m.sessionOngoing = result.ongoing
+	m.ongoingWhy = result.ongoingWhy
 	m.sessionCwd = result.meta.Cwd
 	m.sessionGitBranch = result.meta.GitBranch
 	m.liveBranch = checkGitBranch(m.gitCwd)
 	m.sessionMode = result.meta.PermissionMode
 	m.liveDirty = checkGitDirty(m.gitCwd)
 	m.animFrame = 0
+	m.dismissErrorBanner()
 	m.view = viewList
 	m.layoutList()
 
-	w := newSessionWatcher(result.path, result.classified, result.offset)
+	w := newSessionWatcher(result.source(), result.classified, result.offset)
 	w.hasTeamTasks = result.hasTeamTasks
+	w.lineOffsets = result.lineOffsets
+	w.window = watchWindow(m.windowTurns, m.reducedTurns, result)
+	w.startPartial(result.windowStart)
+	m.evictedTurns = 0
+	m.fullHistory = false
+	m.partialHistory = result.windowStart > 0
+	m.loadingHistory = false
+	m.branches = result.branches
+	if m.pollBase > 0 {
+		w.pollBase = m.pollBase
+	}
+	w.detect = m.detect
+	w.rereadEvery = m.reread
 	go w.run()
 	m.watcher = w
 	m.watching = true
 	m.tailSub = w.sub
 	m.tailErrc = w.errc
+	m.pollRates = w.rates
 
-	cmds := []tea.Cmd{waitForTailUpdate(m.tailSub), waitForWatcherErr(m.tailErrc)}
+	cmds := []tea.Cmd{waitForTailUpdate(m.tailSub), waitForWatcherErr(m.tailErrc), waitForPollRate(m.pollRates), m.moveViewer(oldPath)}
 	if m.sessionOngoing {
 		m.tickSeq++
 		cmds = append(cmds, tickCmd(m.tickSeq))
@@ -397,15 +757,24 @@ func (m model) switchSession(result loadResult) (model, tea.Cmd) {
 }
 
 func initialModel(msgs []message, hasDarkBg bool) model {
+	perf := &perfStats{}
+	md := newMdRenderer(hasDarkBg)
+	md.perf = perf
+	hl := newJSONHL(hasDarkBg)
+	hl.perf = perf
+	numberTurns(msgs)
 	return model{
 		messages:            msgs,
+		rawMessages:         msgs,
 		expanded:            make(map[int]bool), // all messages start collapsed
+		foldedTurns:         make(map[int]bool),
 		cursor:              0,
 		showKeybinds:        false,
 		detailExpanded:      make(map[int]bool),
 		detailChildExpanded: make(map[visibleRowKey]bool),
-		md:                  newMdRenderer(hasDarkBg),
-		jsonHL:              newJSONHL(hasDarkBg),
+		md:                  md,
+		jsonHL:              hl,
+		perf:                perf,
 	}
 }
 
@@ -416,6 +785,7 @@ func (m model) Init() tea.Cmd {
 		cmds = append(cmds,
 			waitForTailUpdate(m.tailSub),
 			waitForWatcherErr(m.tailErrc),
+			waitForPollRate(m.pollRates),
 		)
 		if m.sessionOngoing {
 			m.tickSeq++
@@ -432,6 +802,16 @@ func (m model) Init() tea.Cmd {
 		}
 	}
 
+	if m.viewers != nil {
+		cmds = append(cmds, viewerBeatCmd(m.viewers, m.sessionPath), viewerTickCmd())
+	}
+	cmds = append(cmds, forkTickCmd())
+
+	// Without a size the first frame waits; keep asking until one arrives.
+	if m.width == 0 {
+		cmds = append(cmds, tea.RequestWindowSize, sizeProbeCmd(1))
+	}
+
 	// Poll git dirty state every 3 seconds regardless of JSONL activity.
 	if m.gitCwd != "" {
 		cmds = append(cmds, gitDirtyTickCmd())
@@ -440,18 +820,28 @@ func (m model) Init() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// Update handles msg, then cancels the background work of a view it left.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(model); ok && nm.viewWork != nil {
+		nm.endViewWork()
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.layoutList()
-		m.ensureCursorVisible()
-		if m.view == viewDetail {
-			m.computeDetailMaxScroll()
-		}
+		m.resize(msg.Width, msg.Height)
 		return m, nil
 
+	case sizeProbeMsg:
+		if m.width > 0 || msg.n > sizeProbeLimit {
+			return m, nil
+		}
+		return m, tea.Batch(tea.RequestWindowSize, sizeProbeCmd(msg.n+1))
+
 	case tickMsg:
 		if msg.seq != m.tickSeq || !m.watching || !m.sessionOngoing {
 			return m, nil
@@ -460,7 +850,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// indicator. The next tailUpdateMsg re-enables it if genuinely ongoing.
 		if !m.lastTailUpdate.IsZero() && time.Since(m.lastTailUpdate) > ongoingIdleTimeout {
 			m.sessionOngoing = false
-			return m, nil
+			cmd := postWebhooksCmd(m.cfg.Webhook, m.idleEvent(time.Now()))
+			return m, cmd
 		}
 		m.animFrame++
 		if m.view == viewList {
@@ -471,8 +862,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ongoingGraceExpiredMsg:
 		// Grace period elapsed. If no newer timer was started (seq matches),
 		// the session is genuinely idle — turn off the indicator.
-		if msg.seq == m.ongoingGraceSeq {
+		if msg.seq == m.ongoingGraceSeq && m.sessionOngoing {
 			m.sessionOngoing = false
+			return m, postWebhooksCmd(m.cfg.Webhook, m.turnCompletedEvent(time.Now()))
 		}
 		return m, nil
 
@@ -480,15 +872,57 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.liveDirty = checkGitDirty(m.gitCwd)
 		return m, gitDirtyTickCmd()
 
+	case viewerTickMsg:
+		return m, tea.Batch(viewerBeatCmd(m.viewers, m.sessionPath), viewerTickCmd())
+
+	case viewersMsg:
+		if msg.session == m.sessionPath {
+			m.otherViewers = msg.others
+		}
+		return m, nil
+
+	case forkTickMsg:
+		cmd := m.startForkScan()
+		return m, tea.Batch(cmd, forkTickCmd())
+
+	case forkScanMsg:
+		cmd := m.applyForkScan(msg)
+		return m, cmd
+
 	case tailUpdateMsg:
+		if msg.source != nil && msg.source != m.tailSub {
+			// From the alternate session, or a watcher already stopped.
+			m.altSession.parkedTailUpdate(msg)
+			return m, nil
+		}
 		m.lastTailUpdate = time.Now()
 
 		// Auto-follow only when the user is in the list view AND the cursor
 		// is already on the last message. Other views (detail, picker) should
 		// receive fresh data but not have their cursor or scroll disturbed.
-		wasAtEnd := m.view == viewList && m.cursor >= len(m.messages)-1
-		m.messages = msg.messages
+		// A sorted list has no end to follow.
+		wasAtEnd := m.following()
+		prevCount := len(m.messages)
+		prev := m.messages
+		m.setMessages(msg.messages)
+		if dropped := msg.evictedTurns - m.evictedTurns; dropped > 0 {
+			// The window moved: keep the cursor, expansions, and folds on
+			// the messages they were on.
+			m.shiftMessages(evictionShift(prev, m.messages), dropped)
+		}
 		m.teams = msg.teams
+		m.todos = msg.todos
+		m.growth = msg.growth
+		m.evictedTurns = msg.evictedTurns
+		m.fullHistory = msg.fullHistory
+		m.partialHistory = msg.partialHistory
+		if m.loadingHistory && msg.evictedTurns == 0 && !msg.partialHistory {
+			// Earlier turns landed in front: stay on the same message.
+			m.loadingHistory = false
+			m.cursor = min(m.cursor+len(msg.messages)-prevCount, len(msg.messages)-1)
+			m.relayoutFollow = true
+		}
+		m.branches = msg.branches
 		if msg.permissionMode != "" {
 			m.sessionMode = msg.permissionMode
 		}
@@ -503,24 +937,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor = len(m.messages) - 1
 		}
 
-		// Only recompute list layout when we're looking at it.
-		if m.view == viewList {
-			m.layoutList()
-			if wasAtEnd {
-				m.ensureCursorVisible()
+		cmds := []tea.Cmd{waitForTailUpdate(m.tailSub), postWebhooksCmd(m.cfg.Webhook, m.tailWebhookEvents(time.Now())), m.nowMarkerCmd()}
+
+		// Only recompute layout when we're looking at it, and at most every
+		// relayoutInterval: a burst lays out once when the interval is up.
+		if m.view == viewList || m.view == viewDetail {
+			m.relayoutFollow = m.relayoutFollow || wasAtEnd
+			if since := time.Since(m.lastRelayout); since >= relayoutInterval {
+				m.relayoutTail()
+			} else if !m.relayoutPending {
+				m.relayoutPending = true
+				cmds = append(cmds, relayoutCmd(relayoutInterval-since))
 			}
-		} else if m.view == viewDetail {
-			// The current detail message may have grown (new tool calls,
-			// streaming text). Recompute max scroll so the user can reach
-			// the new content, but don't move their scroll position.
-			m.computeDetailMaxScroll()
 		}
 
 		// Ongoing indicator with grace period.
 		// Rising edge (false->true): immediate. Falling edge (true->false):
 		// delayed by ongoingGracePeriod so the indicator stays steady between
 		// API round-trips.
-		cmds := []tea.Cmd{waitForTailUpdate(m.tailSub)}
+		if m.view == viewDrift {
+			// New edits may have landed; compare with the disk again.
+			cmds = append(cmds, m.recheckDrift())
+		}
+		m.ongoingWhy = msg.ongoingWhy
 		if msg.ongoing {
 			if !m.sessionOngoing {
 				m.tickSeq++
@@ -535,9 +974,41 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, tea.Batch(cmds...)
 
+	case relayoutMsg:
+		if m.relayoutPending {
+			m.relayoutTail()
+		}
+		return m, nil
+
+	case nowMarkerExpiredMsg:
+		// The newest messages aged out of the now window: move the marker.
+		if msg.seq == m.nowSeq && m.view == viewList {
+			m.relayoutFollow = m.following()
+			m.relayoutTail()
+		}
+		return m, nil
+
 	case watcherErrMsg:
-		// Transient watcher errors: re-subscribe and keep going.
-		return m, waitForWatcherErr(m.tailErrc)
+		if msg.source != nil && msg.source != m.tailErrc {
+			m.altSession.parkedWatcherErr(msg)
+			return m, nil
+		}
+		// Surface the error, then re-subscribe and keep going.
+		return m, tea.Batch(m.reportTailError(msg.err, time.Now()), waitForWatcherErr(m.tailErrc))
+
+	case errorBannerClearMsg:
+		if msg.seq == m.errorBannerSeq {
+			m.dismissErrorBanner()
+		}
+		return m, nil
+
+	case pollRateMsg:
+		if msg.source != nil && msg.source != m.pollRates {
+			m.altSession.parkedPollRate(msg)
+			return m, nil
+		}
+		m.pollRate = msg
+		return m, waitForPollRate(m.pollRates)
 
 	case pickerTickMsg:
 		// Keep spinning as long as the tick is active (covers both genuine
@@ -584,6 +1055,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if tickCmd := m.updatePickerSessionState(); tickCmd != nil {
 			cmds = append(cmds, tickCmd)
 		}
+		if indexCmd := m.maybeIndexSessions(); indexCmd != nil {
+			cmds = append(cmds, indexCmd)
+		}
 
 		// Start picker directory watcher for live refresh.
 		if m.pickerWatcher == nil && len(m.projectDirs) > 0 {
@@ -621,6 +1095,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if tickCmd := m.updatePickerSessionState(); tickCmd != nil {
 			cmds = append(cmds, tickCmd)
 		}
+		if indexCmd := m.maybeIndexSessions(); indexCmd != nil {
+			cmds = append(cmds, indexCmd)
+		}
 
 		// Re-subscribe for next refresh.
 		if m.pickerWatcher != nil {
@@ -630,9 +1107,92 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case loadSessionMsg:
 		if msg.err != nil || len(msg.messages) == 0 {
+			m.pendingHit = nil
 			return m, nil
 		}
-		return m.switchSession(msg.loadResult)
+		if msg.windowStart > 0 {
+			// Hits index the whole session; a reduced one can't place them.
+			m.pendingHit = nil
+		}
+		m, cmd := m.switchSession(msg.loadResult)
+		m.jumpToPendingHit()
+		return m, cmd
+
+	case sessionsIndexedMsg:
+		m.indexing = false
+		return m, nil
+
+	case projectSearchDoneMsg:
+		// Drop results of a search that has since been replaced. One cut
+		// short by leaving the view is run again from the prompt.
+		if msg.seq == m.projectSearchSeq {
+			m.projectHits = msg.hits
+			m.projectSearching = false
+			if msg.stopped {
+				m.projectHits = nil
+				m.projectInput = true
+			}
+		}
+		return m, nil
+
+	case sessionsExportedMsg:
+		if errors.Is(msg.err, context.Canceled) {
+			m.flashStatus = fmt.Sprintf("Export stopped after %s", pluralCount(msg.count, "session"))
+		} else if msg.err != nil {
+			m.flashStatus = fmt.Sprintf("Export failed after %s: %v", pluralCount(msg.count, "session"), msg.err)
+			logUIError("session export failed", msg.err, "exported", msg.count, "dir", msg.dir)
+		} else {
+			m.flashStatus = fmt.Sprintf("Exported %s to %s/", pluralCount(msg.count, "session"), msg.dir)
+			m.pickerMarked = nil
+		}
+		return m, flashClearCmd()
+
+	case itemsExportedMsg:
+		if msg.err != nil {
+			m.flashStatus = fmt.Sprintf("Export failed: %v", msg.err)
+			logUIError("item export failed", msg.err, "path", msg.path)
+		} else {
+			m.flashStatus = fmt.Sprintf("Exported %s to %s", parser.Plural(msg.count, "item"), msg.path)
+		}
+		return m, flashClearCmd()
+
+	case linkOpenedMsg:
+		if msg.err != nil {
+			m.flashStatus = fmt.Sprintf("Could not open %s: %v", msg.url, msg.err)
+			logUIError("link open failed", msg.err, "url", msg.url)
+		} else {
+			m.flashStatus = "Opened: " + msg.url
+		}
+		return m, flashClearCmd()
+
+	case agentLeaderboardMsg:
+		m.leaderboard = msg.rows
+		m.leaderboardSessions = msg.sessions
+		m.leaderboardLoading = false
+		return m, nil
+
+	case cleanupScannedMsg:
+		m.cleanupEntries = msg.entries
+		m.cleanupLoading = false
+		m.sortCleanup()
+		return m, nil
+
+	case cleanupDoneMsg:
+		m.applyCleanupDone(msg)
+		return m, tea.Batch(flashClearCmd(), loadPickerSessionsCmd(m.projectDirs, m.sessionCache))
+
+	case memoryLoadedMsg:
+		m.memoryFiles = msg.files
+		m.memoryLoading = false
+		m.memoryIndex = 0
+		m.memoryScroll = 0
+		return m, nil
+
+	case driftCheckedMsg:
+		m.driftEntries = msg.entries
+		m.driftCursor = min(m.driftCursor, max(len(m.driftEntries)-1, 0))
+		m.ensureDriftVisible()
+		return m, nil
 
 	case debugUpdateMsg:
 		m.debugEntries = msg.entries
@@ -647,6 +1207,34 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.flashStatus = ""
 		return m, nil
 
+	case summaryDoneMsg:
+		m.applySummary(msg)
+		return m, nil
+
+	case turnSummaryMsg:
+		m.applyTurnSummary(msg)
+		return m, nil
+
+	case rangeCountTickMsg:
+		cmd := m.startRangeCount(msg.seq)
+		return m, cmd
+
+	case rangeCountMsg:
+		m.applyRangeCount(msg)
+		return m, nil
+
+	case webhookSentMsg:
+		m.flashStatus = fmt.Sprintf("Webhook %s failed: %v", msg.event, msg.err)
+		logUIError("webhook failed", msg.err, "event", msg.event)
+		return m, flashClearCmd()
+
+	case signalQuitMsg:
+		if err := m.saveUIState(statePath()); err != nil {
+			logUIError("save UI state", err)
+		}
+		m.uiStateSaved = true
+		return m, nil
+
 	case editorFinishedMsg:
 		// Re-layout after returning from external editor.
 		m.layoutList()
@@ -660,6 +1248,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.String() == "ctrl+z" {
 			return m, tea.Suspend
 		}
+		// ctrl+l repaints every view from scratch.
+		if msg.String() == "ctrl+l" {
+			cmd := m.redraw()
+			return m, cmd
+		}
+		// The perf overlay works over every view.
+		if msg.String() == "ctrl+p" && m.perf != nil {
+			m.perf.toggle()
+			return m, nil
+		}
+		if m.touring {
+			return m.updateTour(msg)
+		}
+		if m.largeConfirm != "" {
+			return m.updateLargeConfirm(msg)
+		}
+		if m.turnPopup != nil {
+			return m.updateTurnPopup(msg)
+		}
+		if m.forkConfirm {
+			return m.updateForkConfirm(msg)
+		}
 		switch m.view {
 		case viewDetail:
 			return m.updateDetail(msg)
@@ -669,6 +1279,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateDebug(msg)
 		case viewTeam:
 			return m.updateTeam(msg)
+		case viewOutline:
+			return m.updateOutline(msg)
+		case viewTools:
+			return m.updateToolMenu(msg)
+		case viewSearch:
+			return m.updateSearch(msg)
+		case viewFiles:
+			return m.updateFiles(msg)
+		case viewDrift:
+			return m.updateDrift(msg)
+		case viewLinks:
+			return m.updateLinks(msg)
+		case viewProjectSearch:
+			return m.updateProjectSearch(msg)
+		case viewJSONTree:
+			return m.updateJSONTree(msg)
+		case viewMemory:
+			return m.updateMemory(msg)
+		case viewLeaderboard:
+			return m.updateLeaderboard(msg)
+		case viewBranches:
+			return m.updateBranches(msg)
+		case viewCleanup:
+			return m.updateCleanup(msg)
+		case viewBasket:
+			return m.updateBasket(msg)
 		default:
 			return m.updateList(msg)
 		}
@@ -689,6 +1325,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateDebugMouse(msg)
 		case viewTeam:
 			return m.updateTeamMouse(msg)
+		case viewOutline:
+			return m.updateOutlineMouse(msg)
+		case viewTools, viewSearch, viewFiles, viewDrift, viewLinks, viewProjectSearch, viewJSONTree, viewMemory, viewLeaderboard, viewBranches, viewCleanup, viewBasket:
+			return m, nil
 		default:
 			return m.updateListMouse(msg)
 		}
@@ -698,10 +1338,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) View() tea.View {
+	start := m.perf.start()
+	// Nothing is drawn until the terminal size is known: a frame laid out
+	// for 0 columns would flash before the real one.
 	var content string
-	if m.width == 0 {
-		content = "Loading..."
-	} else {
+	if m.width > 0 {
 		switch m.view {
 		case viewDetail:
 			content = m.viewDetail()
@@ -711,9 +1352,45 @@ func (m model) View() tea.View {
 			content = m.viewDebugLog()
 		case viewTeam:
 			content = m.viewTeamBoard()
+		case viewOutline:
+			content = m.viewOutline()
+		case viewTools:
+			content = m.viewToolMenu()
+		case viewSearch:
+			content = m.viewSearch()
+		case viewFiles:
+			content = m.viewFiles()
+		case viewDrift:
+			content = m.viewDriftList()
+		case viewLinks:
+			content = m.viewLinkList()
+		case viewProjectSearch:
+			content = m.viewProjectSearch()
+		case viewJSONTree:
+			content = m.viewJSONTreeBrowser()
+		case viewMemory:
+			content = m.viewMemoryFiles()
+		case viewLeaderboard:
+			content = m.viewLeaderboard()
+		case viewBranches:
+			content = m.viewBranchList()
+		case viewCleanup:
+			content = m.viewCleanupList()
+		case viewBasket:
+			content = m.viewBasketPanel()
 		default:
 			content = m.viewList()
 		}
+		if m.turnPopup != nil {
+			content = m.withTurnPopup(content)
+		}
+		if m.touring {
+			content = m.withTourOverlay(content)
+		}
+		if !start.IsZero() {
+			m.perf.endFrame(start, len(m.messages))
+			content = m.withPerfOverlay(content)
+		}
 	}
 	v := tea.NewView(content)
 	v.AltScreen = true
@@ -746,10 +1423,18 @@ func (m model) viewList() string {
 		lines = append(lines, "")
 	}
 
-	output := strings.Join(lines, "\n")
+	hlStart := m.perf.start()
+	output := highlightMatches(strings.Join(lines, "\n"), m.highlightQuery)
+	m.perf.record(perfHighlight, hlStart)
 
 	// Center content within the terminal when wider than the content cap.
 	output = centerBlock(output, m.clampWidth(), m.width)
+	if m.sortBannerHeight() > 0 {
+		output = m.renderSortBanner() + "\n" + output
+	}
+	if m.filterBarHeight() > 0 {
+		output = m.renderFilterBar() + "\n" + output
+	}
 
 	// Activity indicator (above status bar, only when ongoing)
 	indicator := m.renderActivityIndicator(m.width)
@@ -764,12 +1449,50 @@ func (m model) viewList() string {
 		"G/g", "jump",
 		"tab", "toggle",
 		"enter", "detail",
+		"z", "final answer",
+		"Z", "compact",
+		"#", "turns",
+		"S", "sort",
+		"=", "filter",
+		"o", "outline",
+		"/", "search",
+		"F", "files",
+		"D", "drift",
+		"M", "memory",
+		"u", "links",
+		"H", "hide tools",
 		"d", "debug log",
 	}
-	if len(m.teams) > 0 {
+	if m.watching {
+		footerPairs = append(footerPairs, "f", "follow")
+	}
+	if m.altSession != nil {
+		footerPairs = append(footerPairs, "ctrl+o", "alternate session")
+	}
+	if m.hasTaskBoard() {
 		footerPairs = append(footerPairs, "t", "tasks")
 	}
+	if len(m.branches) > 0 {
+		footerPairs = append(footerPairs, "b", "branches")
+	}
+	if n := len(m.basket); n > 0 {
+		footerPairs = append(footerPairs, "B", fmt.Sprintf("basket (%d)", n))
+	}
+	if m.cfg.TurnSummary != nil {
+		footerPairs = append(footerPairs, "E", "summarize turn")
+	}
+	if m.fork != nil {
+		footerPairs = append(footerPairs, "R", "resumed elsewhere")
+	}
+	if m.groupingTurns() {
+		footerPairs = append(footerPairs, "space", "fold turn")
+	}
+	footerPairs = append(footerPairs, "v", "select range")
+	if m.highlightQuery != "" {
+		footerPairs = append(footerPairs, "n/N", "next/prev match")
+	}
 	footerPairs = append(footerPairs,
+		"+", "exact tokens",
 		"e/c", "expand/collapse",
 		"y", "copy path",
 		"O", "editor",
@@ -819,7 +1542,9 @@ func (m model) viewDetail() string {
 		lines = append(lines, "")
 	}
 
-	output := strings.Join(lines, "\n")
+	hlStart := m.perf.start()
+	output := highlightMatches(strings.Join(lines, "\n"), m.highlightQuery)
+	m.perf.record(perfHighlight, hlStart)
 
 	// Center content within the terminal when wider than the content cap.
 	output = centerBlock(output, width, m.width)
@@ -834,6 +1559,11 @@ func (m model) viewDetail() string {
 		scrollInfo = fmt.Sprintf("  %d%% (%d/%d)", pct, scroll+viewHeight, totalLines)
 	}
 
+	// Full summary of the selected row, on request
+	if footnote := m.renderDetailFootnote(width); footnote != "" {
+		output += "\n" + centerBlock(footnote, width, m.width)
+	}
+
 	// Activity indicator (above status bar, only when ongoing)
 	indicator := m.renderActivityIndicator(m.width)
 	if indicator != "" {
@@ -844,21 +1574,48 @@ func (m model) viewDetail() string {
 	hasItems := msg.role == RoleClaude && len(msg.items) > 0
 	var footer string
 	if hasItems {
-		footer = m.renderFooter(
+		pairs := []string{
 			"j/k", "items",
 			"tab", "toggle",
 			"enter", "open",
+			"i/r/p", "copy input/result/path",
+			"f", "full summary",
+			"l", "input tree",
+			"D", "diff input",
+			"v", "page result",
+			"space", "mark",
+			"B", "basket",
+		}
+		if m.cfg.Summarizer != nil {
+			pairs = append(pairs, "S", "summarize")
+		}
+		if n := len(m.detailMarked); n > 0 {
+			pairs = append(pairs, "Y/x/C", fmt.Sprintf("copy/export/collapse all but %d marked", n))
+		}
+		pairs = append(pairs, "u", "links")
+		if m.highlightQuery != "" {
+			pairs = append(pairs, "n/N", "next/prev match")
+		}
+		pairs = append(pairs,
+			"+", "exact tokens",
 			"↑/↓", "scroll",
 			"J/K", "page",
 			"G/g", "jump",
+		)
+		if m.traceMsg != nil {
+			pairs = append(pairs, "-", "up")
+		}
+		pairs = append(pairs,
 			"q/esc", "back"+scrollInfo,
 			"?", "keys",
 		)
+		footer = m.renderFooter(pairs...)
 	} else {
 		footer = m.renderFooter(
 			"j/k", "scroll",
 			"↑/↓", "scroll",
 			"G/g", "jump",
+			"u", "links",
 			"q/esc", "back"+scrollInfo,
 			"?", "keys",
 		)
@@ -874,16 +1631,68 @@ func main() {
 	initTheme(hasDarkBg)
 	initIcons()
 
+	// Numbers follow the locale, or the config's override. Set before the
+	// subcommands so grep and digest write them the same way.
+	localeCfg, _ := loadConfig(configPath())
+	if err := initNumberFormat(localeCfg.Locale); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring locale in %s: %v\n", configPath(), err)
+	}
+	exactTokens = localeCfg.ExactTokens
+	// Ongoing detection rules, likewise: digest reports running sessions.
+	if rules, err := localeCfg.Ongoing.rules(); err == nil {
+		parser.Ongoing = rules
+	} else {
+		fmt.Fprintf(os.Stderr, "warning: ignoring ongoing in %s: %v\n", configPath(), err)
+	}
+
 	dumpMode := false
 	expandAll := false
 	dumpWidth := 0
+	var dumpGrep *regexp.Regexp
+	var listFilter filter.Expr
+	pollFlag := ""
+	windowFlag := 0
+	largeMode := ""
+	maxAgeFlag := ""
+	exportFormat := ""
+	noIndex := false
+	follow := false
+	readOnly := false
+	logFile := ""
+	logLevel := slog.LevelInfo
 	var sessionPath string
 
+	if len(os.Args) > 1 && os.Args[1] == "grep" {
+		os.Exit(runGrep(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "digest" {
+		os.Exit(runDigest(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "version" {
+		os.Exit(runVersion(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "self-update" {
+		os.Exit(runSelfUpdate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		// Developer command: the TUI opens on a copy the replay keeps growing.
+		args, code := startReplay(os.Args[2:])
+		if code != 0 {
+			os.Exit(code)
+		}
+		os.Args = append(os.Args[:1], args...)
+	}
+
 	for i := 1; i < len(os.Args); i++ {
 		arg := os.Args[i]
 		switch {
 		case arg == "--help" || arg == "-h":
 			fmt.Print(`Usage: tail-claude [flags] [session.jsonl]
+       tail-claude grep [--no-index] [--filter EXPR] <pattern>
+       tail-claude digest [--since WHEN]
+       tail-claude version [--check]
+       tail-claude self-update [--force]
+       tail-claude replay [--delay D | --speed X] <session.jsonl> [flags]
 
 Without arguments, auto-discovers the most recent session and opens
 the interactive TUI.
@@ -891,10 +1700,80 @@ the interactive TUI.
 Pass a JSONL path to view a specific session:
   tail-claude ~/.claude/projects/-Users-me-Code-foo/abc123.jsonl
 
+or "-" to read it from stdin, an http(s):// URL (tailed with Range
+requests), or ssh://[user@]host[:port]/path (tailed with tail over ssh):
+  tail-claude ssh://devbox/~/.claude/projects/-home-me-foo/abc123.jsonl
+Sessions that aren't local files are polled and show no subagent traces.
+
+"tail-claude grep" searches every session in the current project
+(case-insensitive) and prints one "session.jsonl:turn: source: line" per
+match; --filter keeps the matches in messages EXPR matches. Press / in the
+session picker to search from the TUI.
+
+"tail-claude digest" prints a Markdown summary of the project's sessions
+since WHEN (today, yesterday, 3d, 36h, or 2026-10-14; default yesterday):
+prompts, files changed, tokens, errors, and sessions left mid-turn.
+
+"tail-claude version" prints the running version; --check asks GitHub for
+the latest release and exits 1 when it is newer. "tail-claude self-update"
+downloads that release's binary for this platform, verifies it against the
+release's checksums.txt, and replaces the running executable (--force
+reinstalls the current release).
+
+"tail-claude replay" is for developing tail-claude: it copies a recorded
+session into a temp file line by line, 200ms apart (--delay D), or at the
+recorded pace sped up X times (--speed X, pauses capped by --max-gap D,
+default 5s), and opens the TUI on it. --split writes each line in two
+parts, as a writer flushing mid-line does. Flags after the session go to
+the TUI.
+
 Flags:
   --dump          Print rendered output to stdout (no interactive TUI)
   --expand        Expand all messages (use with --dump)
+  --grep RE       Print only the messages matching the regexp RE (Go syntax;
+                  (?i) for case-insensitive), each as a "path:turn: Role time
+                  model" line and its matching lines; implies --dump. Exits 1
+                  when nothing matches, 2 on a bad pattern
+  --filter EXPR   Show only the messages matching a filter expression, e.g.
+                  "role=claude AND tool=Bash AND error", in the TUI, --dump,
+                  --grep, and tail-claude grep; the same language as the
+                  list's filter bar (=). Not with --export
   --width N       Set terminal width for --dump output (default 160, min 40)
+  --export FMT    Print a report instead of the TUI. FMT is one of:
+                    files  every file read/edited/written, per agent (Markdown)
+                    audit  every tool call with its time, target, permission
+                           mode, and approval (JSON)
+                    script every Bash command in order, with its description
+                           and exit status as comments (shell script)
+                    patch  every Edit/MultiEdit/Write as unified diffs, one
+                           patch per turn (patch-by-file: one per file)
+                    csv    one row per Claude turn: time, model, input/
+                           output/cache tokens, duration, tool calls and
+                           errors (CSV)
+                    text   the transcript without colors: prompts, Claude's
+                           thinking, output, and tool calls with results
+  --poll D        Watcher poll interval while a session is active (default 1s,
+                  min 100ms); backs off up to 30s when the session goes idle
+  --window N      Keep only the last N turns in memory while tailing; older
+                  turns are evicted and reloaded from disk with L
+  --full          Parse a session past the large session size (100MB) whole
+                  without asking
+  --reduced       Open a session past the large session size with only its
+                  last turns without asking; earlier history loads on
+                  scroll-up or with L
+  --max-age D     List only sessions written in the last D (30d, 36h; 0 for
+                  all) in the picker and when picking the latest session
+  --no-index      Don't keep a search index in the user cache dir; project
+                  search then parses every session (also: grep --no-index)
+  --follow        Start on the newest message with the latest Claude turn
+                  expanded and the view scrolled to the bottom
+  --read-only     Never write to disk: no search index, viewer heartbeat,
+                  exports, or config saves (tool menu changes last the run)
+  --log-file PATH Append tail-claude's own diagnostics to PATH as JSON lines:
+                  watcher activity and errors, skipped transcript lines, and
+                  errors the TUI flashed. Attach it to bug reports
+  --log-level L   Lowest level logged: debug (every read and poll interval
+                  change), info (default), warn, or error
   -h, --help      Show this help
 `)
 			os.Exit(0)
@@ -902,6 +1781,31 @@ Flags:
 			dumpMode = true
 		case arg == "--expand":
 			expandAll = true
+		case arg == "--grep":
+			i++
+			if i >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "--grep requires a pattern")
+				os.Exit(2)
+			}
+			re, err := regexp.Compile(os.Args[i])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--grep: %v\n", err)
+				os.Exit(2)
+			}
+			dumpGrep = re
+			dumpMode = true
+		case arg == "--filter":
+			i++
+			if i >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "--filter requires an expression")
+				os.Exit(2)
+			}
+			expr, err := parseMessageFilter(os.Args[i])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--filter: %v\n", err)
+				os.Exit(2)
+			}
+			listFilter = expr
 		case arg == "--width":
 			i++
 			if i >= len(os.Args) {
@@ -914,7 +1818,83 @@ Flags:
 				os.Exit(1)
 			}
 			dumpWidth = n
-		case strings.HasPrefix(arg, "-"):
+		case arg == "--export":
+			i++
+			if i >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "--export requires a format")
+				os.Exit(1)
+			}
+			switch os.Args[i] {
+			case "files", "audit", "script", "patch", "patch-by-file", "csv", "text":
+				exportFormat = os.Args[i]
+			default:
+				fmt.Fprintf(os.Stderr, "unknown --export format: %s (want files, audit, script, patch, patch-by-file, csv, or text)\n", os.Args[i])
+				os.Exit(1)
+			}
+		case arg == "--poll":
+			i++
+			if i >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "--poll requires a value")
+				os.Exit(1)
+			}
+			if _, err := parsePollInterval(os.Args[i]); err != nil {
+				fmt.Fprintf(os.Stderr, "--poll: %v\n", err)
+				os.Exit(1)
+			}
+			pollFlag = os.Args[i]
+		case arg == "--max-age":
+			i++
+			if i >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "--max-age requires a value")
+				os.Exit(1)
+			}
+			if _, err := parseMaxAge(os.Args[i]); err != nil {
+				fmt.Fprintf(os.Stderr, "--max-age: %v\n", err)
+				os.Exit(1)
+			}
+			maxAgeFlag = os.Args[i]
+		case arg == "--no-index":
+			noIndex = true
+		case arg == "--follow":
+			follow = true
+		case arg == "--log-file":
+			i++
+			if i >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "--log-file requires a path")
+				os.Exit(1)
+			}
+			logFile = os.Args[i]
+		case arg == "--log-level":
+			i++
+			if i >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "--log-level requires a level")
+				os.Exit(1)
+			}
+			level, err := parseLogLevel(os.Args[i])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--log-level: %v\n", err)
+				os.Exit(1)
+			}
+			logLevel = level
+		case arg == "--read-only":
+			readOnly = true
+		case arg == "--window":
+			i++
+			if i >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "--window requires a value")
+				os.Exit(1)
+			}
+			n, err := strconv.Atoi(os.Args[i])
+			if err != nil || n < 1 {
+				fmt.Fprintln(os.Stderr, "--window must be an integer >= 1")
+				os.Exit(1)
+			}
+			windowFlag = n
+		case arg == "--full":
+			largeMode = largeFull
+		case arg == "--reduced":
+			largeMode = largeReduced
+		case strings.HasPrefix(arg, "-") && arg != "-":
 			fmt.Fprintf(os.Stderr, "unknown flag: %s\n", arg)
 			os.Exit(1)
 		default:
@@ -922,6 +1902,136 @@ Flags:
 		}
 	}
 
+	// Reports cover the whole session; a filter would be silently dropped.
+	if listFilter != nil && exportFormat != "" {
+		fmt.Fprintln(os.Stderr, "--filter can't be combined with --export: reports cover the whole session")
+		os.Exit(1)
+	}
+
+	// Diagnostics log, asked for by path, so written even under --read-only.
+	if logFile != "" {
+		f, err := openLogFile(logFile, logLevel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+	}
+
+	// User preferences. A malformed config is reported but doesn't block startup.
+	cfgPath := configPath()
+	cfg, err := loadConfig(cfgPath)
+	// Where tool menu changes are saved; nowhere under --read-only.
+	savePath := cfgPath
+	if readOnly {
+		savePath = ""
+		noIndex = true
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring config %s: %v\n", cfgPath, err)
+	}
+	if err := validateInfoBar(cfg.InfoBar); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring infoBar in %s: %v\n", cfgPath, err)
+	}
+	if err := validateHiddenSystem(cfg.HiddenSystem); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring hiddenSystem in %s: %v\n", cfgPath, err)
+		cfg.HiddenSystem = nil
+	}
+	if err := validateWebhook(cfg.Webhook); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring webhook in %s: %v\n", cfgPath, err)
+		cfg.Webhook = nil
+	}
+	if err := validateDetailFocus(cfg.DetailFocus); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring detailFocus in %s: %v\n", cfgPath, err)
+		cfg.DetailFocus = ""
+	}
+	if err := validateSummarizer(cfg.Summarizer); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring summarizer in %s: %v\n", cfgPath, err)
+		cfg.Summarizer = nil
+	}
+	if err := validateTurnSummary(cfg.TurnSummary); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring turnSummary.timeout in %s: %v\n", cfgPath, err)
+		cfg.TurnSummary.Timeout = ""
+	}
+	if err := validateWorkspaces(cfg.Workspaces); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring workspaces in %s: %v\n", cfgPath, err)
+		cfg.Workspaces = nil
+	}
+
+	// Poll interval: --poll wins over the config file.
+	pollBase := defaultPollInterval
+	if cfg.PollInterval != "" {
+		if d, err := parsePollInterval(cfg.PollInterval); err == nil {
+			pollBase = d
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: ignoring pollInterval in %s: %v\n", cfgPath, err)
+		}
+	}
+	if pollFlag != "" {
+		pollBase, _ = parsePollInterval(pollFlag)
+	}
+
+	// Change detection for sessions on network or synced drives.
+	if err := validateChangeDetection(cfg.ChangeDetection); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring changeDetection in %s: %v\n", cfgPath, err)
+		cfg.ChangeDetection = ""
+	}
+	var reread time.Duration
+	if cfg.RereadInterval != "" {
+		if d, err := parseRereadInterval(cfg.RereadInterval); err == nil {
+			reread = d
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: ignoring rereadInterval in %s: %v\n", cfgPath, err)
+		}
+	}
+
+	// Now marker window.
+	nowWindow := defaultNowWindow
+	if cfg.NowMarker != "" {
+		if d, err := parseNowMarker(cfg.NowMarker); err == nil {
+			nowWindow = d
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: ignoring nowMarker in %s: %v\n", cfgPath, err)
+		}
+	}
+
+	// Tail window: --window wins over the config file.
+	windowTurns := 0
+	if cfg.WindowTurns > 0 {
+		windowTurns = cfg.WindowTurns
+	}
+	if windowFlag > 0 {
+		windowTurns = windowFlag
+	}
+
+	// Large sessions: --full and --reduced answer the question up front.
+	largeSession := int64(defaultLargeSession)
+	if cfg.LargeSession != "" {
+		if n, err := parseSize(cfg.LargeSession); err == nil {
+			largeSession = n
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: ignoring largeSession in %s: %v\n", cfgPath, err)
+		}
+	}
+	reducedTurns := defaultReducedTurns
+	if cfg.ReducedTurns > 0 {
+		reducedTurns = cfg.ReducedTurns
+	}
+
+	// Session discovery scope: --max-age wins over the config file.
+	scope := parser.DiscoveryScope{Dirs: cfg.sessionDirs(), Subdirs: cfg.IncludeSubdirs}
+	if cfg.MaxAge != "" {
+		if d, err := parseMaxAge(cfg.MaxAge); err == nil {
+			scope.MaxAge = d
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: ignoring maxAge in %s: %v\n", cfgPath, err)
+		}
+	}
+	if maxAgeFlag != "" {
+		scope.MaxAge, _ = parseMaxAge(maxAgeFlag)
+	}
+	parser.Discovery = scope
+
 	// Capture the directory tail-claude was invoked from for live git queries.
 	invokedFrom, _ := os.Getwd()
 
@@ -955,8 +2065,14 @@ Flags:
 		}
 	}
 
+	// A configured workspace lists the sessions of all its roots.
+	ws := resolveWorkspace(cfg.Workspaces, projectDir)
+	if ws != nil {
+		projectDirs = dedup(append(projectDirs, ws.dirs...))
+	}
+
 	// When no explicit path was given, find the latest session across the
-	// main project and any worktree directories.
+	// main project, any worktree directories, and the workspace's roots.
 	autoDiscovered := sessionPath == ""
 	if sessionPath == "" && len(projectDirs) > 0 {
 		if sessions, err := parser.DiscoverAllProjectSessions(projectDirs); err == nil && len(sessions) > 0 {
@@ -964,52 +2080,141 @@ Flags:
 		}
 	}
 
+	// Exit status for a session that can't be read. --grep, like grep,
+	// keeps 1 for "nothing matched".
+	failStatus := 1
+	if dumpGrep != nil {
+		failStatus = 2
+	}
+
 	// Empty project, no session to show.
 	if sessionPath == "" {
-		if dumpMode {
+		if dumpMode || exportFormat != "" {
 			fmt.Fprintln(os.Stderr, "No sessions found for this project.")
-			os.Exit(1)
+			os.Exit(failStatus)
 		}
 
 		// Bootstrap an empty picker that live-updates when sessions appear.
 		// Ensure the project directory exists so fsnotify can watch it.
-		if projectDir != "" {
+		if projectDir != "" && !readOnly {
 			os.MkdirAll(projectDir, 0o700)
 		}
 
 		m := initialModel(nil, hasDarkBg)
+		m.listFilter = listFilter
+		m.applyConfig(savePath, cfg)
+		m.pollBase = pollBase
+		m.detect = cfg.ChangeDetection
+		m.reread = reread
+		m.nowWindow = nowWindow
+		m.largeSession, m.reducedTurns, m.largeMode = largeSession, reducedTurns, largeMode
 		m.projectDir = projectDir
 		m.projectDirs = projectDirs
+		m.workspace = ws
 		m.worktreeProjectDirs = worktreeProjectDirs
 		m.pickerWorktreeMode = inWorktree
 		m.gitCwd = invokedFrom
 		m.liveBranch = checkGitBranch(invokedFrom)
 		m.liveDirty = checkGitDirty(invokedFrom)
 		m.sessionCache = parser.NewSessionCache()
+		m.searchIndex = newSearchIndex(noIndex)
+		m.readOnly = readOnly
+		if !readOnly {
+			m.viewers = newViewerRegistry()
+		}
 		m.view = viewPicker
 		m.pickerLoading = true
 		m.pickerTickActive = true
 
-		p := tea.NewProgram(m)
-		if _, err := p.Run(); err != nil {
+		if err := runProgram(m); err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(1)
 		}
 		return
 	}
 
-	result, err := loadSession(sessionPath)
+	// The session may be a local path, "-" for stdin, a URL, or ssh://.
+	src, err := parser.ParseSource(sessionPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		os.Exit(failStatus)
+	}
+	// os.Exit skips deferred calls, so exits from here on remove the
+	// stream's spool file first.
+	exit := os.Exit
+	if stream, ok := src.(*parser.StreamSource); ok {
+		defer stream.Close()
+		exit = func(code int) {
+			stream.Close()
+			os.Exit(code)
+		}
+		stream.WaitData()
+	}
+	// Reports and dumps need the whole session; the TUI may take its end.
+	turns := 0
+	if exportFormat == "" && dumpGrep == nil && !dumpMode {
+		turns = largeSessionTurns(src, largeSession, reducedTurns, largeMode)
+	}
+	result, err := loadSource(src, turns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		exit(failStatus)
 	}
 
+	if exportFormat != "" {
+		m := initialModel(result.messages, hasDarkBg)
+		m.sessionPath = result.path
+		if exportFormat == "audit" {
+			name := strings.TrimSuffix(filepath.Base(result.path), ".jsonl")
+			data, err := auditJSON(name, result.meta.Cwd, buildAuditLog(m.rawMessages))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				exit(1)
+			}
+			os.Stdout.Write(data)
+			return
+		}
+		if exportFormat == "script" {
+			name := strings.TrimSuffix(filepath.Base(result.path), ".jsonl")
+			fmt.Print(scriptShell(name, result.meta.Cwd, buildScript(m.rawMessages)))
+			return
+		}
+		if exportFormat == "csv" {
+			data, err := turnsCSV(m.rawMessages)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				exit(1)
+			}
+			os.Stdout.Write(data)
+			return
+		}
+		if exportFormat == "text" {
+			fmt.Print(localRender.PlainSession(result.chunks))
+			return
+		}
+		if exportFormat == "patch" || exportFormat == "patch-by-file" {
+			name := strings.TrimSuffix(filepath.Base(result.path), ".jsonl")
+			fmt.Print(patchSeries(name, result.meta.Cwd, buildPatchEdits(m.rawMessages), exportFormat == "patch-by-file"))
+			return
+		}
+		fmt.Print(fileReportMarkdown(buildFileReport(m.rawMessages), m.fileReportTitle(), result.meta.Cwd))
+		return
+	}
+
+	if dumpGrep != nil {
+		if !writeDumpGrep(os.Stdout, result.path, result.messages, dumpGrep, listFilter) {
+			exit(1)
+		}
+		return
+	}
 	if dumpMode {
 		width := maxContentWidth
 		if dumpWidth > 0 {
 			width = dumpWidth
 		}
 		m := initialModel(result.messages, hasDarkBg)
+		m.listFilter = listFilter
+		m.applyConfig(savePath, cfg)
 		m.width = width
 		m.height = 1_000_000
 		m.gitCwd = invokedFrom
@@ -1025,6 +2230,9 @@ Flags:
 		}
 		m.layoutList()
 		fmt.Println(m.viewList())
+		if answer := m.renderFinalAnswer(width); answer != "" {
+			fmt.Println(answer)
+		}
 		return
 	}
 
@@ -1032,21 +2240,39 @@ Flags:
 	sessionCache := parser.NewSessionCache()
 
 	// Start the file watcher for live tailing.
-	watcher := newSessionWatcher(result.path, result.classified, result.offset)
+	watcher := newSessionWatcher(result.source(), result.classified, result.offset)
 	watcher.hasTeamTasks = result.hasTeamTasks
+	watcher.pollBase = pollBase
+	watcher.detect = cfg.ChangeDetection
+	watcher.rereadEvery = reread
+	watcher.lineOffsets = result.lineOffsets
+	watcher.window = watchWindow(windowTurns, reducedTurns, result)
+	watcher.startPartial(result.windowStart)
 	go watcher.run()
 
 	m := initialModel(result.messages, hasDarkBg)
+	m.listFilter = listFilter
+	m.applyConfig(savePath, cfg)
 	m.sessionPath = result.path
 	m.projectDir = projectDir
 	m.projectDirs = projectDirs
+	m.workspace = ws
 	m.worktreeProjectDirs = worktreeProjectDirs
 	m.pickerWorktreeMode = inWorktree
 	m.watching = true
 	m.watcher = watcher
 	m.tailSub = watcher.sub
 	m.tailErrc = watcher.errc
+	m.pollRates = watcher.rates
+	m.pollBase = pollBase
+	m.detect = cfg.ChangeDetection
+	m.reread = reread
+	m.nowWindow = nowWindow
+	m.windowTurns = windowTurns
+	m.partialHistory = result.windowStart > 0
+	m.largeSession, m.reducedTurns, m.largeMode = largeSession, reducedTurns, largeMode
 	m.sessionOngoing = result.ongoing
+	m.ongoingWhy = result.ongoingWhy
 	m.gitCwd = invokedFrom
 	m.sessionCwd = result.meta.Cwd
 	m.sessionGitBranch = result.meta.GitBranch
@@ -1054,7 +2280,17 @@ Flags:
 	m.sessionMode = result.meta.PermissionMode
 	m.liveDirty = checkGitDirty(invokedFrom)
 	m.teams = result.teams
+	m.todos = result.todos
 	m.sessionCache = sessionCache
+	m.searchIndex = newSearchIndex(noIndex)
+	m.readOnly = readOnly
+	if !readOnly {
+		m.viewers = newViewerRegistry()
+	}
+	m.resetWebhookState()
+	if follow || cfg.Follow {
+		m.followLatest()
+	}
 
 	// When the session was auto-discovered (no explicit path) and it's stale,
 	// start on the picker so the user can choose instead of seeing old output.
@@ -1068,9 +2304,23 @@ Flags:
 		}
 	}
 
-	p := tea.NewProgram(m)
-	if _, err := p.Run(); err != nil {
+	// Start where the last run on this session left the list. First run:
+	// offer the tour on the session just loaded. The state file is written
+	// up front so it is only offered once.
+	if path := statePath(); path != "" {
+		st, ok := loadState(path)
+		if !follow && !cfg.Follow {
+			m.restoreUIState(st)
+		}
+		if m.view == viewList && len(m.messages) > 0 && !readOnly && !ok {
+			m.startTour()
+			st.TourOffered = true
+			_ = saveState(path, st)
+		}
+	}
+
+	if err := runProgram(m); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		exit(1)
 	}
 }
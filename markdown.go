@@ -20,6 +20,7 @@ type mdRenderer struct {
 	renderer  *glamour.TermRenderer
 	width     int
 	hasDarkBg bool
+	perf      *perfStats // times renders for the perf overlay; may be nil
 }
 
 // newMdRenderer creates an mdRenderer with the pre-detected background color.
@@ -64,6 +65,7 @@ func (r *mdRenderer) renderMarkdown(content string, width int) string {
 	if width <= 0 {
 		return content
 	}
+	defer r.perf.record(perfMarkdown, r.perf.start())
 	if r.renderer == nil || r.width != width {
 		renderer, err := glamour.NewTermRenderer(
 			glamour.WithStyles(r.glamourStyle()),
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// memoryFileNames are the instruction files Claude Code loads from the
+// working directory and each directory above it.
+var memoryFileNames = []string{"CLAUDE.md", "CLAUDE.local.md", filepath.Join(".claude", "CLAUDE.md")}
+
+// maxMemoryImportDepth matches how deep Claude Code follows @imports.
+const maxMemoryImportDepth = 5
+
+// reMemoryImport matches an @path import: "@docs/style.md", "@~/notes.md".
+var reMemoryImport = regexp.MustCompile(`(?:^|\s)@((?:~/|\.{0,2}/)?[\w.\-/]+)`)
+
+// memoryFile is one instruction file shown in the memory view.
+type memoryFile struct {
+	path    string
+	scope   string // "user", "project", "local", "import", "session"
+	content string
+	err     error
+}
+
+// memoryLoadedMsg delivers the memory files read from disk.
+type memoryLoadedMsg struct {
+	files []memoryFile
+}
+
+// findMemoryFiles lists the instruction files a session in cwd runs under,
+// in the order Claude Code loads them: the user's file, then each directory
+// from the outermost down to cwd, then CLAUDE.md files the session itself
+// touched (nested directories are loaded on demand). Only files that exist
+// are returned; content is not read.
+func findMemoryFiles(cwd, home string, msgs []message) []memoryFile {
+	var files []memoryFile
+	seen := make(map[string]bool)
+	add := func(path, scope string) {
+		if seen[path] {
+			return
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return
+		}
+		seen[path] = true
+		files = append(files, memoryFile{path: path, scope: scope})
+	}
+
+	if home != "" {
+		add(filepath.Join(home, ".claude", "CLAUDE.md"), "user")
+	}
+	if cwd != "" {
+		var dirs []string
+		for dir := filepath.Clean(cwd); ; dir = filepath.Dir(dir) {
+			dirs = append([]string{dir}, dirs...)
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+		for _, dir := range dirs {
+			for _, name := range memoryFileNames {
+				scope := "project"
+				if name == "CLAUDE.local.md" {
+					scope = "local"
+				}
+				add(filepath.Join(dir, name), scope)
+			}
+		}
+	}
+	for _, path := range sessionMemoryPaths(msgs, cwd) {
+		add(path, "session")
+	}
+	return files
+}
+
+// sessionMemoryPaths returns the CLAUDE.md and CLAUDE.local.md files the
+// session's tool calls referenced, in order.
+func sessionMemoryPaths(msgs []message, cwd string) []string {
+	var paths []string
+	for _, msg := range msgs {
+		for _, item := range msg.items {
+			if item.itemType != parser.ItemToolCall {
+				continue
+			}
+			var input struct {
+				FilePath string `json:"file_path"`
+			}
+			if json.Unmarshal([]byte(item.toolInput), &input) != nil || input.FilePath == "" {
+				continue
+			}
+			if base := filepath.Base(input.FilePath); base != "CLAUDE.md" && base != "CLAUDE.local.md" {
+				continue
+			}
+			path := input.FilePath
+			if !filepath.IsAbs(path) && cwd != "" {
+				path = filepath.Join(cwd, path)
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// loadMemoryFiles reads each file and, after it, the files it pulls in with
+// @imports that exist on disk.
+func loadMemoryFiles(files []memoryFile, home string) []memoryFile {
+	var out []memoryFile
+	seen := make(map[string]bool)
+	var load func(f memoryFile, depth int)
+	load = func(f memoryFile, depth int) {
+		if seen[f.path] {
+			return
+		}
+		seen[f.path] = true
+		data, err := os.ReadFile(f.path)
+		f.content, f.err = string(data), err
+		out = append(out, f)
+		if err != nil || depth >= maxMemoryImportDepth {
+			return
+		}
+		for _, path := range memoryImports(f.content, filepath.Dir(f.path), home) {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				load(memoryFile{path: path, scope: "import"}, depth+1)
+			}
+		}
+	}
+	for _, f := range files {
+		load(f, 0)
+	}
+	return out
+}
+
+// memoryImports returns the paths of the @imports in content, resolved
+// against dir (or home for "~/"). Code blocks and spans are skipped, as
+// Claude Code does.
+func memoryImports(content, dir, home string) []string {
+	var paths []string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, m := range reMemoryImport.FindAllStringSubmatch(stripCodeSpans(line), -1) {
+			path := strings.TrimRight(m[1], ".")
+			switch {
+			case strings.HasPrefix(path, "~/"):
+				if home == "" {
+					continue
+				}
+				path = filepath.Join(home, path[2:])
+			case !filepath.IsAbs(path):
+				path = filepath.Join(dir, path)
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// stripCodeSpans removes `inline code` from a line.
+func stripCodeSpans(line string) string {
+	parts := strings.Split(line, "`")
+	var b strings.Builder
+	for i := 0; i < len(parts); i += 2 {
+		b.WriteString(parts[i] + " ")
+	}
+	return b.String()
+}
+
+// loadMemoryCmd finds and reads the memory files off the UI goroutine.
+func loadMemoryCmd(cwd string, msgs []message) tea.Cmd {
+	return func() tea.Msg {
+		home, _ := os.UserHomeDir()
+		return memoryLoadedMsg{files: loadMemoryFiles(findMemoryFiles(cwd, home, msgs), home)}
+	}
+}
+
+// openMemory switches to the memory view and starts reading the files.
+func (m *model) openMemory() tea.Cmd {
+	m.memoryFiles = nil
+	m.memoryLoading = true
+	m.memoryIndex = 0
+	m.memoryScroll = 0
+	m.view = viewMemory
+	return loadMemoryCmd(m.sessionCwd, m.rawMessages)
+}
+
+// updateMemory handles key events in the memory view.
+func (m model) updateMemory(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "M":
+		m.view = viewList
+	case "tab", "n":
+		if len(m.memoryFiles) > 0 {
+			m.memoryIndex = (m.memoryIndex + 1) % len(m.memoryFiles)
+			m.memoryScroll = 0
+		}
+	case "shift+tab", "p":
+		if len(m.memoryFiles) > 0 {
+			m.memoryIndex = (m.memoryIndex + len(m.memoryFiles) - 1) % len(m.memoryFiles)
+			m.memoryScroll = 0
+		}
+	case "j", "down":
+		m.memoryScroll++
+	case "k", "up":
+		m.memoryScroll--
+	case "J", "ctrl+d":
+		m.memoryScroll += m.memoryViewHeight() / 2
+	case "K", "ctrl+u":
+		m.memoryScroll -= m.memoryViewHeight() / 2
+	case "G":
+		m.memoryScroll = len(m.memoryLines())
+	case "g":
+		m.memoryScroll = 0
+	case "O":
+		if m.memoryIndex < len(m.memoryFiles) {
+			if cmd := editorCmd(m.memoryFiles[m.memoryIndex].path); cmd != nil {
+				return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+					return editorFinishedMsg{err}
+				})
+			}
+			m.flashStatus = "No $EDITOR set"
+			return m, flashClearCmd()
+		}
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	m.memoryScroll = max(min(m.memoryScroll, len(m.memoryLines())-m.memoryViewHeight()), 0)
+	return m, nil
+}
+
+// memoryViewHeight returns the visible rows (minus header and footer).
+func (m model) memoryViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// memoryLines renders the current file as Markdown, split into lines.
+func (m model) memoryLines() []string {
+	if m.memoryIndex >= len(m.memoryFiles) {
+		return nil
+	}
+	f := m.memoryFiles[m.memoryIndex]
+	if f.err != nil {
+		return []string{StyleErrorBold.Render(f.err.Error())}
+	}
+	if strings.TrimSpace(f.content) == "" {
+		return []string{StyleDim.Render("(empty)")}
+	}
+	return strings.Split(m.md.renderMarkdown(f.content, max(m.clampWidth()-4, 20)), "\n")
+}
+
+// viewMemoryFiles renders the memory view: one instruction file at a time,
+// tab cycling through them.
+func (m model) viewMemoryFiles() string {
+	width := m.clampWidth()
+
+	header := StyleAccentBold.Render("Memory")
+	var body string
+	switch {
+	case m.memoryLoading:
+		body = StyleDim.Render("Reading instruction files" + Icon.Ellipsis.Glyph)
+	case len(m.memoryFiles) == 0:
+		body = StyleDim.Render("No CLAUDE.md found for this session.")
+	default:
+		f := m.memoryFiles[m.memoryIndex]
+		header += " " + StyleSecondary.Render(relPath(f.path, m.sessionCwd)) + " " +
+			StyleDim.Render(fmt.Sprintf("(%s, %d/%d)", f.scope, m.memoryIndex+1, len(m.memoryFiles)))
+		body = strings.Join(scrollWindow(m.memoryLines(), m.memoryViewHeight(), m.memoryScroll), "\n")
+	}
+	content := centerBlock(header+"\n\n"+body, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"j/k", "scroll",
+		"tab", "next file",
+		"O", "editor",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
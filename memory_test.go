@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestFindMemoryFiles(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	repo := filepath.Join(root, "repo")
+	cwd := filepath.Join(repo, "svc")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(home, ".claude", "CLAUDE.md"), "user prefs")
+	write(filepath.Join(repo, "CLAUDE.md"), "repo rules, see @docs/style.md and `@not/an/import.md`")
+	write(filepath.Join(repo, "docs", "style.md"), "use tabs")
+	write(filepath.Join(cwd, "CLAUDE.local.md"), "my notes")
+	write(filepath.Join(cwd, ".claude", "CLAUDE.md"), "svc rules")
+	write(filepath.Join(cwd, "pkg", "CLAUDE.md"), "pkg rules")
+
+	msgs := []message{claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "pkg/CLAUDE.md"}`},
+			{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "` + filepath.Join(repo, "CLAUDE.md") + `"}`},
+		}
+	})}
+
+	files := loadMemoryFiles(findMemoryFiles(cwd, home, msgs), home)
+	want := []struct{ path, scope string }{
+		{filepath.Join(home, ".claude", "CLAUDE.md"), "user"},
+		{filepath.Join(repo, "CLAUDE.md"), "project"},
+		{filepath.Join(repo, "docs", "style.md"), "import"},
+		{filepath.Join(cwd, "CLAUDE.local.md"), "local"},
+		{filepath.Join(cwd, ".claude", "CLAUDE.md"), "project"},
+		{filepath.Join(cwd, "pkg", "CLAUDE.md"), "session"},
+	}
+	if len(files) != len(want) {
+		for _, f := range files {
+			t.Logf("%s (%s)", f.path, f.scope)
+		}
+		t.Fatalf("got %d files, want %d", len(files), len(want))
+	}
+	for i, w := range want {
+		if files[i].path != w.path || files[i].scope != w.scope {
+			t.Errorf("files[%d] = %s (%s), want %s (%s)", i, files[i].path, files[i].scope, w.path, w.scope)
+		}
+	}
+	if files[2].content != "use tabs" {
+		t.Errorf("import content = %q", files[2].content)
+	}
+}
+
+func TestMemoryImports(t *testing.T) {
+	content := "See @a.md and @~/b.md.\nmail me@example.com\n```\n@fenced.md\n```\n"
+	got := memoryImports(content, "/repo", "/home/u")
+	want := []string{"/repo/a.md", "/home/u/b.md"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("memoryImports = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateMemory(t *testing.T) {
+	m := testModel()
+	m.view = viewMemory
+	m.memoryFiles = []memoryFile{
+		{path: "/repo/CLAUDE.md", scope: "project", content: "one"},
+		{path: "/repo/CLAUDE.local.md", scope: "local", content: "two"},
+	}
+	result, _ := m.updateMemory(key("tab"))
+	m = asModel(result)
+	if m.memoryIndex != 1 {
+		t.Errorf("tab: memoryIndex = %d, want 1", m.memoryIndex)
+	}
+	result, _ = m.updateMemory(key("tab"))
+	m = asModel(result)
+	if m.memoryIndex != 0 {
+		t.Errorf("tab wraps: memoryIndex = %d, want 0", m.memoryIndex)
+	}
+	if !strings.Contains(m.viewMemoryFiles(), "(project, 1/2)") {
+		t.Error("header should name the scope and position")
+	}
+	result, _ = m.updateMemory(key("esc"))
+	if asModel(result).view != viewList {
+		t.Error("esc should return to the list")
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// outlineEntryLines is the rendered height of one outline entry:
+// prompt line, summary line, blank separator.
+const outlineEntryLines = 3
+
+// outlineEntry is one turn in the outline: a user prompt and a one-line
+// summary of the AI response that followed it.
+type outlineEntry struct {
+	msgIndex  int    // index of the user message in m.messages
+	prompt    string // first line of the prompt
+	summary   string // digest of the turn's last Claude message; empty if none yet
+	loop      string // "Bash ×4" when a Claude message in the turn is a possible loop
+	apiErrors int    // failed API requests and retries during the turn
+}
+
+// buildOutline groups messages into turns keyed by user prompt. The summary
+// comes from the last Claude message before the next prompt.
+func buildOutline(msgs []message) []outlineEntry {
+	var entries []outlineEntry
+	for i, msg := range msgs {
+		switch msg.role {
+		case RoleUser:
+			first, _, _ := strings.Cut(strings.TrimSpace(msg.content), "\n")
+			entries = append(entries, outlineEntry{msgIndex: i, prompt: first})
+		case RoleClaude:
+			if len(entries) == 0 {
+				continue
+			}
+			if s := msg.summary.String(); s != "" {
+				entries[len(entries)-1].summary = s
+			}
+			if msg.loopCount > 0 {
+				entries[len(entries)-1].loop = fmt.Sprintf("%s ×%d", msg.loopTool, msg.loopCount)
+			}
+			entries[len(entries)-1].apiErrors += len(msg.apiErrors)
+		case RoleError:
+			if len(entries) > 0 {
+				entries[len(entries)-1].apiErrors += len(msg.apiErrors)
+			}
+		}
+	}
+	return entries
+}
+
+// updateOutline handles key events in the outline view.
+func (m model) updateOutline(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	entries := buildOutline(m.messages)
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "o":
+		m.view = viewList
+	case "j", "down":
+		if m.outlineCursor < len(entries)-1 {
+			m.outlineCursor++
+		}
+		m.ensureOutlineVisible()
+	case "k", "up":
+		if m.outlineCursor > 0 {
+			m.outlineCursor--
+		}
+		m.ensureOutlineVisible()
+	case "G":
+		m.outlineCursor = max(len(entries)-1, 0)
+		m.ensureOutlineVisible()
+	case "g":
+		m.outlineCursor = 0
+		m.outlineScroll = 0
+	case "enter":
+		if m.outlineCursor < len(entries) {
+			// Jump to the turn with its prompt at the top of the viewport.
+			m.cursor = entries[m.outlineCursor].msgIndex
+			m.view = viewList
+			m.layoutList()
+			m.scroll = m.lineOffsets[m.cursor]
+			m.clampListScroll()
+		}
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// updateOutlineMouse scrolls the outline viewport on wheel events.
+func (m model) updateOutlineMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	maxScroll := max(len(buildOutline(m.messages))*outlineEntryLines-m.outlineViewHeight(), 0)
+	switch msg.Mouse().Button {
+	case tea.MouseWheelUp:
+		m.outlineScroll = max(m.outlineScroll-3, 0)
+	case tea.MouseWheelDown:
+		m.outlineScroll = min(m.outlineScroll+3, maxScroll)
+	}
+	return m, nil
+}
+
+// openOutline switches to the outline view with the cursor on the turn that
+// contains the list cursor.
+func (m *model) openOutline() {
+	m.outlineCursor = 0
+	for i, e := range buildOutline(m.messages) {
+		if e.msgIndex > m.cursor {
+			break
+		}
+		m.outlineCursor = i
+	}
+	m.outlineScroll = 0
+	m.view = viewOutline
+	m.ensureOutlineVisible()
+}
+
+// outlineViewHeight returns the visible entry lines (minus header and footer).
+func (m model) outlineViewHeight() int {
+	header := 2
+	if invocationsLine(countInvocations(m.rawMessages)) != "" {
+		header++
+	}
+	if m.approvalWait != "" {
+		header++
+	}
+	return m.panelHeight(header)
+}
+
+// ensureOutlineVisible adjusts outlineScroll so the cursor entry is visible.
+func (m *model) ensureOutlineVisible() {
+	// The entry's blank separator may scroll off.
+	m.outlineScroll = scrollToShow(m.outlineScroll, m.outlineCursor*outlineEntryLines, outlineEntryLines-1, m.outlineViewHeight())
+}
+
+// viewOutline renders the outline: one prompt + summary pair per turn.
+func (m model) viewOutline() string {
+	width := m.clampWidth()
+	entries := buildOutline(m.messages)
+
+	counts := fmt.Sprintf("%d turns", len(entries))
+	if loops := outlineLoopCount(entries); loops > 0 {
+		counts += ", " + parser.Plural(loops, "possible loop")
+	}
+	if errs := outlineAPIErrorCount(entries); errs > 0 {
+		counts += ", " + parser.Plural(errs, "API error")
+	}
+	header := StyleAccentBold.Render("Outline") + " " +
+		StyleDim.Render("("+counts+")") + "\n"
+	if line := invocationsLine(countInvocations(m.rawMessages)); line != "" {
+		header += StyleDim.Render(parser.Truncate(line, width)) + "\n"
+	}
+	if m.approvalWait != "" {
+		header += StyleDim.Render(parser.Truncate("Approvals "+m.approvalWait, width)) + "\n"
+	}
+
+	var lines []string
+	for i, e := range entries {
+		lines = append(lines, renderOutlineEntry(e, i, i == m.outlineCursor, width)...)
+	}
+
+	content := header
+	if len(entries) == 0 {
+		content += "\n" + StyleDim.Render("No prompts yet.")
+	} else {
+		content += "\n" + strings.Join(scrollWindow(lines, m.outlineViewHeight(), m.outlineScroll), "\n")
+	}
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"j/k", "nav",
+		"enter", "jump to turn",
+		"G/g", "jump",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
+
+// outlineLoopCount counts the turns flagged as possible loops.
+func outlineLoopCount(entries []outlineEntry) int {
+	n := 0
+	for _, e := range entries {
+		if e.loop != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// outlineAPIErrorCount totals the API errors across all turns.
+func outlineAPIErrorCount(entries []outlineEntry) int {
+	n := 0
+	for _, e := range entries {
+		n += e.apiErrors
+	}
+	return n
+}
+
+// renderOutlineEntry renders "{sel} 3. prompt" followed by an indented
+// dim summary line and a blank separator. Possible loops and API errors lead
+// the summary.
+func renderOutlineEntry(e outlineEntry, index int, isSelected bool, width int) []string {
+	sel := selectionIndicator(isSelected)
+	num := StyleDim.Render(fmt.Sprintf("%3d.", index+1))
+	prefix := sel + num + " "
+
+	promptStyle := StyleSecondary
+	if isSelected {
+		promptStyle = StylePrimaryBold
+	}
+	room := max(width-lipgloss.Width(prefix)-1, 10)
+	prompt := promptStyle.Render(parser.Truncate(e.prompt, room))
+
+	summary := Icon.Ellipsis.Render()
+	if e.summary != "" || e.loop != "" || e.apiErrors > 0 {
+		summary = Icon.Claude.Render() + " "
+		if e.loop != "" {
+			badge := "possible loop " + e.loop
+			summary += StyleErrorBold.Render(badge) + "  "
+			room -= lipgloss.Width(badge) + 2
+		}
+		if e.apiErrors > 0 {
+			badge := parser.Plural(e.apiErrors, "API error")
+			summary += StyleWarningBold.Render(badge) + "  "
+			room -= lipgloss.Width(badge) + 2
+		}
+		summary += StyleDim.Render(parser.Truncate(e.summary, max(room-2, 1)))
+	}
+	return []string{
+		prefix + prompt,
+		sel + "     " + summary,
+		"",
+	}
+}
+
+// invocation is how often one skill or agent ran in the session.
+type invocation struct {
+	name  string
+	count int
+}
+
+// countInvocations tallies Skill calls by skill and subagents by agent type,
+// across the session and its subagent traces, most used first.
+func countInvocations(msgs []message) (skills, agents []invocation) {
+	skillCounts := make(map[string]int)
+	agentCounts := make(map[string]int)
+	var visit func(items []displayItem)
+	visit = func(items []displayItem) {
+		for _, item := range items {
+			if skill := itemSkill(item); skill != "" {
+				skillCounts[skill]++
+			}
+			if item.itemType == parser.ItemSubagent && item.subagentType != "" {
+				agentCounts[item.subagentType]++
+			}
+			if item.subagentProcess != nil {
+				visit(buildTraceItems(item))
+			}
+		}
+	}
+	for _, msg := range msgs {
+		visit(msg.items)
+	}
+	return sortedInvocations(skillCounts), sortedInvocations(agentCounts)
+}
+
+// sortedInvocations orders counts by use, then name.
+func sortedInvocations(counts map[string]int) []invocation {
+	out := make([]invocation, 0, len(counts))
+	for name, n := range counts {
+		out = append(out, invocation{name, n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].count != out[j].count {
+			return out[i].count > out[j].count
+		}
+		return out[i].name < out[j].name
+	})
+	return out
+}
+
+// invocationsLine renders "Skills pdf ×3 · xlsx ×1   Agents Explore ×2" for
+// the outline header, or "" when the session used neither.
+func invocationsLine(skills, agents []invocation) string {
+	group := func(label string, invs []invocation) string {
+		parts := make([]string, len(invs))
+		for i, inv := range invs {
+			parts[i] = fmt.Sprintf("%s ×%d", inv.name, inv.count)
+		}
+		return label + " " + strings.Join(parts, " \u00B7 ")
+	}
+	var groups []string
+	if len(skills) > 0 {
+		groups = append(groups, group("Skills", skills))
+	}
+	if len(agents) > 0 {
+		groups = append(groups, group("Agents", agents))
+	}
+	return strings.Join(groups, "   ")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestBuildOutline(t *testing.T) {
+	msgs := []message{
+		claudeMsg(func(m *message) { m.content = "Preamble before any prompt." }),
+		userMsg("Fix the parser\nwith details"),
+		claudeMsg(func(m *message) { m.summary = parser.TurnSummary{Text: "Looking."} }),
+		claudeMsg(func(m *message) {
+			m.content = "ignored"
+			m.summary = parser.TurnSummary{Text: "Fixed it.", Activity: "edited 1 file"}
+		}),
+		{role: RoleSystem, content: "ok"},
+		userMsg("Thanks"),
+	}
+	entries := buildOutline(msgs)
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+	if entries[0].msgIndex != 1 || entries[0].prompt != "Fix the parser" {
+		t.Errorf("entries[0] = %+v, want msgIndex 1, prompt %q", entries[0], "Fix the parser")
+	}
+	if want := "Fixed it. \u00B7 edited 1 file"; entries[0].summary != want {
+		t.Errorf("entries[0].summary = %q, want %q", entries[0].summary, want)
+	}
+	if entries[1].summary != "" {
+		t.Errorf("entries[1].summary = %q, want empty (no response yet)", entries[1].summary)
+	}
+}
+
+func TestBuildOutline_Loop(t *testing.T) {
+	msgs := []message{
+		userMsg("Fix the tests"),
+		claudeMsg(func(m *message) { m.loopTool, m.loopCount = "Bash", 5 }),
+		userMsg("Thanks"),
+	}
+	entries := buildOutline(msgs)
+	if entries[0].loop != "Bash ×5" || entries[1].loop != "" {
+		t.Errorf("loops = %q, %q; want \"Bash ×5\", \"\"", entries[0].loop, entries[1].loop)
+	}
+	if n := outlineLoopCount(entries); n != 1 {
+		t.Errorf("outlineLoopCount = %d, want 1", n)
+	}
+	if row := renderOutlineEntry(entries[0], 0, false, 80)[1]; !strings.Contains(row, "possible loop Bash ×5") {
+		t.Errorf("summary row %q missing loop badge", row)
+	}
+}
+
+func TestUpdateOutline(t *testing.T) {
+	t.Run("o opens outline on the turn containing the cursor", func(t *testing.T) {
+		m := testModel()
+		m.cursor = 1 // claude message answering the first prompt
+		result, _ := m.updateList(key("o"))
+		got := asModel(result)
+		if got.view != viewOutline {
+			t.Fatalf("view = %v, want viewOutline", got.view)
+		}
+		if got.outlineCursor != 0 {
+			t.Errorf("outlineCursor = %d, want 0", got.outlineCursor)
+		}
+	})
+
+	t.Run("enter jumps to the prompt in the list", func(t *testing.T) {
+		m := testModel()
+		m.messages = append(m.messages, userMsg("second prompt"))
+		m.layoutList()
+		m.view = viewOutline
+		m.outlineCursor = 1
+		result, _ := m.updateOutline(key("enter"))
+		got := asModel(result)
+		if got.view != viewList {
+			t.Errorf("view = %v, want viewList", got.view)
+		}
+		if got.cursor != len(m.messages)-1 {
+			t.Errorf("cursor = %d, want %d", got.cursor, len(m.messages)-1)
+		}
+	})
+
+	t.Run("j does not move past the last turn", func(t *testing.T) {
+		m := testModel()
+		m.view = viewOutline
+		result, _ := m.updateOutline(key("j"))
+		got := asModel(result)
+		if got.outlineCursor != 0 {
+			t.Errorf("outlineCursor = %d, want 0 (single turn)", got.outlineCursor)
+		}
+	})
+}
+
+func TestBuildOutline_APIErrors(t *testing.T) {
+	msgs := []message{
+		userMsg("Fix the tests"),
+		{role: RoleError, apiErrors: make([]parser.ErrorMsg, 3)},
+		claudeMsg(),
+		userMsg("Thanks"),
+	}
+	entries := buildOutline(msgs)
+	if entries[0].apiErrors != 3 || entries[1].apiErrors != 0 {
+		t.Errorf("apiErrors = %d, %d; want 3, 0", entries[0].apiErrors, entries[1].apiErrors)
+	}
+	if n := outlineAPIErrorCount(entries); n != 3 {
+		t.Errorf("outlineAPIErrorCount = %d, want 3", n)
+	}
+	if row := renderOutlineEntry(entries[0], 0, false, 80)[1]; !strings.Contains(row, "3 API errors") {
+		t.Errorf("summary row %q missing API error badge", row)
+	}
+}
+
+func TestCountInvocations(t *testing.T) {
+	skill := func(name string) displayItem {
+		return displayItem{itemType: parser.ItemToolCall, toolName: "Skill", toolInput: `{"skill": "` + name + `"}`}
+	}
+	reviewer := &parser.SubagentProcess{
+		ID:           "a1b2c3d4e5",
+		SubagentType: "code-reviewer",
+		Chunks: []parser.Chunk{
+			{Type: parser.AIChunk, Items: []parser.DisplayItem{
+				{Type: parser.ItemToolCall, ToolName: "Skill", ToolInput: []byte(`{"skill":"xlsx"}`)},
+			}},
+		},
+	}
+	msgs := []message{
+		userMsg("go"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				skill("pdf"),
+				skill("xlsx"),
+				skill("pdf"),
+				{itemType: parser.ItemSubagent, subagentType: "code-reviewer", subagentProcess: reviewer},
+				{itemType: parser.ItemSubagent, subagentType: "Explore"},
+				{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "a.go"}`},
+			}
+		}),
+	}
+	skills, agents := countInvocations(msgs)
+	if got := invocationsLine(skills, agents); got != "Skills pdf ×2 · xlsx ×2   Agents Explore ×1 · code-reviewer ×1" {
+		t.Errorf("invocationsLine = %q", got)
+	}
+	if invocationsLine(countInvocations([]message{userMsg("hi")})) != "" {
+		t.Error("a session without skills or agents should have no line")
+	}
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"regexp"
+	"time"
+)
+
+// BackgroundRunning is a background task's status until its notification
+// arrives. Notifications report "completed", "killed", or "failed".
+const BackgroundRunning = "running"
+
+// reBackgroundTaskID matches the tool result of a Bash call started with
+// run_in_background: "Command running in background with ID: bash_1. ..."
+var reBackgroundTaskID = regexp.MustCompile(`(?i)running in background with ID: ([\w-]+)`)
+
+// BackgroundTask is the lifecycle of a Bash command run in the background:
+// started by its tool call, running once the tool result hands back a task
+// ID, and finished when a <task-notification> for that ID arrives later in
+// the session.
+type BackgroundTask struct {
+	ID      string
+	Status  string    // BackgroundRunning, or the notification's status
+	Started time.Time // the tool call
+	Ended   time.Time // the notification; zero while running
+	Summary string    // the notification's summary: `Background command "Run tests" completed (exit code 0)`
+}
+
+// Running reports whether no notification has arrived for the task yet.
+func (t *BackgroundTask) Running() bool {
+	return t.Status == BackgroundRunning
+}
+
+// ElapsedMs returns the time from start to notification, or 0 while the
+// task runs or when either end has no timestamp.
+func (t *BackgroundTask) ElapsedMs() int64 {
+	if t.Running() || t.Started.IsZero() || t.Ended.IsZero() {
+		return 0
+	}
+	return t.Ended.Sub(t.Started).Milliseconds()
+}
+
+// backgroundTaskID returns the task ID in a background Bash tool result, or
+// "" for any other result.
+func backgroundTaskID(result string) string {
+	if m := reBackgroundTaskID.FindStringSubmatch(result); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// finishBackgroundTask applies a task notification to the background Bash
+// item that started the task, searching the chunks built so far from the
+// newest. Reports false when no item has the task's ID, so the caller keeps
+// the notification as a system line.
+func finishBackgroundTask(chunks []Chunk, m SystemMsg) bool {
+	for i := len(chunks) - 1; i >= 0; i-- {
+		for j := range chunks[i].Items {
+			t := chunks[i].Items[j].Background
+			if t == nil || t.ID != m.TaskID {
+				continue
+			}
+			t.Status = m.TaskStatus
+			if t.Status == "" || t.Status == BackgroundRunning {
+				t.Status = "completed"
+			}
+			t.Ended = m.Timestamp
+			t.Summary = m.Output
+			return true
+		}
+	}
+	return false
+}
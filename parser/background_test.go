@@ -0,0 +1,107 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// backgroundBashMsgs starts a background Bash call and reports its task ID.
+func backgroundBashMsgs(t0 time.Time) []parser.ClassifiedMsg {
+	return []parser.ClassifiedMsg{
+		parser.AIMsg{
+			Timestamp: t0,
+			ToolCalls: []parser.ToolCall{{ID: "call_1", Name: "Bash"}},
+			Blocks: []parser.ContentBlock{
+				{Type: "tool_use", ToolID: "call_1", ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"go test ./...","run_in_background":true}`)},
+			},
+		},
+		parser.AIMsg{
+			Timestamp: t0.Add(time.Second),
+			IsMeta:    true,
+			Blocks: []parser.ContentBlock{
+				{Type: "tool_result", ToolID: "call_1", Content: "Command running in background with ID: bash_1. Output is being written to: /tmp/bash_1.out"},
+			},
+		},
+	}
+}
+
+func TestBuildChunks_BackgroundTaskCompleted(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := append(backgroundBashMsgs(t0), parser.SystemMsg{
+		Timestamp:  t0.Add(90 * time.Second),
+		Output:     `Background command "Run tests" completed (exit code 0)`,
+		TaskID:     "bash_1",
+		TaskStatus: "completed",
+	})
+
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 1 {
+		t.Fatalf("len(chunks) = %d, want 1 (notification folded into the Bash item)", len(chunks))
+	}
+	bg := chunks[0].Items[0].Background
+	if bg == nil {
+		t.Fatal("Background = nil, want the task")
+	}
+	if bg.ID != "bash_1" || bg.Status != "completed" {
+		t.Errorf("task = %s %s, want bash_1 completed", bg.ID, bg.Status)
+	}
+	if bg.Running() {
+		t.Error("task should not be running after its notification")
+	}
+	if got := bg.ElapsedMs(); got != 90000 {
+		t.Errorf("ElapsedMs = %d, want 90000", got)
+	}
+	if bg.Summary != `Background command "Run tests" completed (exit code 0)` {
+		t.Errorf("Summary = %q", bg.Summary)
+	}
+}
+
+func TestBuildChunks_BackgroundTaskRunning(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	chunks := parser.BuildChunks(backgroundBashMsgs(t0))
+	bg := chunks[0].Items[0].Background
+	if bg == nil || !bg.Running() {
+		t.Fatalf("Background = %+v, want a running task", bg)
+	}
+	if !bg.Started.Equal(t0) {
+		t.Errorf("Started = %v, want the tool call's time", bg.Started)
+	}
+	if bg.ElapsedMs() != 0 {
+		t.Errorf("ElapsedMs = %d, want 0 while running", bg.ElapsedMs())
+	}
+}
+
+func TestBuildChunks_BackgroundNotificationUnmatched(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := append(backgroundBashMsgs(t0), parser.SystemMsg{
+		Timestamp:  t0.Add(time.Minute),
+		Output:     "Background command stopped",
+		TaskID:     "bash_9",
+		TaskStatus: "killed",
+	})
+
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 2 || chunks[1].Type != parser.SystemChunk {
+		t.Fatalf("chunks = %d, want the unmatched notification kept as a system chunk", len(chunks))
+	}
+	if !chunks[0].Items[0].Background.Running() {
+		t.Error("an unrelated notification should leave the task running")
+	}
+}
+
+func TestClassify_TaskNotificationID(t *testing.T) {
+	content := json.RawMessage(`"<task-notification>\n<task-id>bash_1</task-id>\n<status>failed</status>\n<summary>Background command failed</summary>\n</task-notification>"`)
+	e := makeEntry("user", "t3", "2025-01-15T10:00:00Z", content)
+
+	msg, ok := parser.Classify(e)
+	if !ok {
+		t.Fatal("expected Classify to succeed for task notification")
+	}
+	sys := msg.(parser.SystemMsg)
+	if sys.TaskID != "bash_1" || sys.TaskStatus != "failed" {
+		t.Errorf("TaskID, TaskStatus = %q, %q, want bash_1, failed", sys.TaskID, sys.TaskStatus)
+	}
+}
@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"sort"
+	"context"
 	"sync"
 	"time"
 )
@@ -28,17 +28,20 @@ func NewSessionCache() *SessionCache {
 }
 
 // getOrScan returns cached metadata when the file hasn't changed (same modTime),
-// otherwise rescans and updates the cache.
+// otherwise rescans and updates the cache. The lock is not held during the
+// scan so parallel discovery can scan several files at once.
 func (c *SessionCache) getOrScan(path string, modTime time.Time) sessionMetadata {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if cached, ok := c.entries[path]; ok && cached.modTime.Equal(modTime) {
+	cached, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && cached.modTime.Equal(modTime) {
 		return cached.meta
 	}
 
 	meta := scanSessionMetadata(path)
+	c.mu.Lock()
 	c.entries[path] = cachedSession{modTime: modTime, meta: meta}
+	c.mu.Unlock()
 	return meta
 }
 
@@ -46,25 +49,24 @@ func (c *SessionCache) getOrScan(path string, modTime time.Time) sessionMetadata
 // using cached metadata for unchanged files. Same logic as the standalone
 // DiscoverProjectSessions but avoids redundant file scans across refreshes.
 func (c *SessionCache) DiscoverProjectSessions(projectDir string) ([]SessionInfo, error) {
-	return discoverSessions(projectDir, c.getOrScan)
+	return discoverSessions(context.Background(), Discovery, projectDir, c.getOrScan)
 }
 
 // DiscoverAllProjectSessions finds sessions across multiple project directories,
 // using cached metadata for unchanged files. Same merge-and-sort logic as the
 // standalone DiscoverAllProjectSessions.
 func (c *SessionCache) DiscoverAllProjectSessions(projectDirs []string) ([]SessionInfo, error) {
-	var all []SessionInfo
-	for _, dir := range projectDirs {
-		sessions, err := c.DiscoverProjectSessions(dir)
-		if err != nil {
-			continue
-		}
-		all = append(all, sessions...)
-	}
+	return c.DiscoverAllProjectSessionsContext(context.Background(), projectDirs)
+}
 
-	sort.Slice(all, func(i, j int) bool {
-		return all[i].ModTime.After(all[j].ModTime)
-	})
+// DiscoverAllProjectSessionsContext is DiscoverAllProjectSessions with
+// cancellation: once ctx is done, scanning stops and ctx.Err() is returned.
+func (c *SessionCache) DiscoverAllProjectSessionsContext(ctx context.Context, projectDirs []string) ([]SessionInfo, error) {
+	return discoverAllSessions(ctx, Discovery, projectDirs, c.getOrScan)
+}
 
-	return all, nil
+// DiscoverAllProjectSessionsIn is DiscoverAllProjectSessionsContext under
+// scope rather than Discovery.
+func (c *SessionCache) DiscoverAllProjectSessionsIn(ctx context.Context, scope DiscoveryScope, projectDirs []string) ([]SessionInfo, error) {
+	return discoverAllSessions(ctx, scope, projectDirs, c.getOrScan)
 }
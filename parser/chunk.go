@@ -2,6 +2,7 @@ package parser
 
 import (
 	"encoding/json"
+	"slices"
 	"strings"
 	"time"
 )
@@ -23,8 +24,34 @@ const (
 	ItemToolCall
 	ItemSubagent        // Task tool spawned subagent
 	ItemTeammateMessage // message from a teammate agent
+	ItemHook            // hook output not attributable to a tool call
+	ItemQueuedMessage   // prompt the user sent while the turn was running
 )
 
+// Attachment is a file or directory injected into the prompt by an @-mention.
+type Attachment struct {
+	ToolName   string // "Read", "Bash"
+	Path       string
+	TokenCount int // estimated tokens of the injected content: len/4
+}
+
+// HookOutput is one hook's output attached to a DisplayItem.
+type HookOutput struct {
+	Event   string // "PreToolUse", "PostToolUse", ...
+	Matcher string // "Bash"; empty when absent
+	Command string
+	Output  string
+	IsError bool
+}
+
+// Name returns "PreToolUse:Bash", or just the event when there is no matcher.
+func (h HookOutput) Name() string {
+	if h.Matcher == "" {
+		return h.Event
+	}
+	return h.Event + ":" + h.Matcher
+}
+
 // DisplayItem is a structured element within an AI chunk's detail view.
 type DisplayItem struct {
 	Type        DisplayItemType
@@ -35,8 +62,10 @@ type DisplayItem struct {
 	ToolSummary string // "main.go" for Read, "go test" for Bash
 	ToolResult  string
 	ToolError   bool
-	DurationMs  int64 // tool_use -> tool_result timestamp delta
-	TokenCount  int   // estimated tokens: len(text)/4
+	DurationMs  int64     // tool_use -> tool_result timestamp delta
+	ExecMs      int64     // run time the tool reported in its result; 0 when not recorded or the tool never prompts
+	TokenCount  int       // estimated tokens: len(text)/4
+	Timestamp   time.Time // when the tool was called (tool and subagent items)
 
 	// Tool categorization
 	ToolCategory ToolCategory // broad functional group (Read, Edit, Bash, etc.)
@@ -49,6 +78,22 @@ type DisplayItem struct {
 	// Teammate fields (ItemTeammateMessage only)
 	TeammateID    string
 	TeammateColor string // team color name (e.g. "blue", "green")
+
+	// Hook output. Tool items collect the hooks that wrapped them;
+	// ItemHook items carry exactly one.
+	Hooks []HookOutput
+
+	// Patch is the change an Edit, MultiEdit, or Write made, from its tool
+	// result; nil for other tools and transcripts that don't record it.
+	Patch *FilePatch
+
+	// Background is the lifecycle of a Bash call run in the background,
+	// completed by a later task notification; nil for foreground calls.
+	Background *BackgroundTask
+
+	// References are the sources an ItemOutput's text cites, in order of
+	// first citation.
+	References []Reference
 }
 
 // ChunkType discriminates the chunk categories.
@@ -59,6 +104,8 @@ const (
 	AIChunk
 	SystemChunk
 	CompactChunk // context compression boundary
+	CommandChunk // slash command invocation + its local output
+	ErrorChunk   // failed API requests and their retries
 )
 
 // Chunk is the output of the pipeline. Each chunk represents one visible unit
@@ -66,9 +113,14 @@ const (
 type Chunk struct {
 	Type      ChunkType
 	Timestamp time.Time
+	UUID      string // uuid of the prompt or of the turn's first entry; empty for other chunks
 
 	// User chunk fields.
-	UserText string
+	UserText       string
+	Attachments    []Attachment // @-mentioned context injected with the prompt
+	PermissionMode string       // mode the prompt was sent in; empty if not recorded
+	Branch         int          // place among the branches of a fork the prompt opens; 0 when none
+	Branches       int          // branches of that fork
 
 	// AI chunk fields.
 	Model         string
@@ -78,46 +130,129 @@ type Chunk struct {
 	Items         []DisplayItem // structured detail, nil until populated
 	Usage         Usage
 	StopReason    string
-	DurationMs    int64 // first to last message timestamp in chunk
-
-	// System chunk fields.
-	Output  string
-	IsError bool // bash stderr present or task killed
+	DurationMs    int64           // first to last message timestamp in chunk
+	ContextDelta  int             // context snapshot change since the previous AI chunk with usage
+	CacheRebuilt  int             // tokens a request re-wrote to a warm prompt cache (cache busting); 0 when none
+	Settings      RequestSettings // prompt's thinking config, responses' tier and version
+	requests      []Usage         // usage of each API request, in order
+
+	// System chunk fields. Command chunks reuse Output/IsError for the
+	// command's local stdout/stderr.
+	Output      string
+	IsError     bool       // bash stderr present, task killed, or an error status
+	SystemKind  SystemKind // what the system chunk reports
+	SystemLevel string     // status: "info", "warning", "error"; empty when not recorded
+
+	// Command chunk fields.
+	Command     string // "/review"
+	CommandArgs string // "src/"
+
+	// Error chunk fields; AI chunks use Errors for the errors and retries
+	// written during the turn.
+	Errors []ErrorMsg // consecutive API errors, oldest first
+
+	// Compact chunk fields. Output holds the boundary's title.
+	CompactTrigger string // "auto" or "manual"; empty when not recorded
+	PreTokens      int    // context tokens before compaction; 0 when not recorded
+	PostTokens     int    // context tokens after: recorded, or the next AI chunk's snapshot
+	CompactSummary string // the summary the session continues from
 }
 
 // BuildChunks folds classified messages into display chunks.
 // The algorithm buffers consecutive AI messages and flushes them into a single
 // AI chunk whenever a User or System message appears (or at end of input).
-// TeammateMsg entries fold into the current AI buffer rather than starting new chunks.
+// AttachmentMsg entries attach to the user chunk they follow.
+// TeammateMsg and HookMsg entries fold into the current AI buffer rather than
+// starting new chunks; a hook written between turns goes to the tool call it
+// wrapped in the last AI chunk, or is dropped.
+// Local command output directly following a CommandMsg attaches to that
+// command's chunk instead of becoming a separate system chunk. Typed system
+// notices (status, output style, queue) written mid-turn are held until the
+// turn's chunk is flushed, then follow it. API errors written mid-turn
+// belong to the turn's chunk; between turns they make error chunks.
 func BuildChunks(msgs []ClassifiedMsg) []Chunk {
 	var chunks []Chunk
 	var aiBuf []AIMsg
+	var prompt RequestSettings // settings of the prompt the buffered turn answers
+	var notices []Chunk        // system notices written during the buffered turn
+	var turnErrors []ErrorMsg  // API errors written during the buffered turn
 
 	flush := func() {
 		if len(aiBuf) == 0 {
 			return
 		}
-		chunks = append(chunks, mergeAIBuffer(aiBuf))
+		c := mergeAIBuffer(aiBuf)
+		c.Settings.ThinkingLevel = prompt.ThinkingLevel
+		c.Settings.ThinkingDisabled = prompt.ThinkingDisabled
+		c.Settings.ThinkingBudget = prompt.ThinkingBudget
+		if c.Settings.Version == "" {
+			c.Settings.Version = prompt.Version
+		}
+		if len(turnErrors) > 0 {
+			c.Errors = slices.Clone(turnErrors)
+		}
+		chunks = append(chunks, c)
+		chunks = append(chunks, notices...)
 		aiBuf = aiBuf[:0]
+		notices = notices[:0]
+		turnErrors = turnErrors[:0]
 	}
 
 	for _, msg := range msgs {
 		switch m := msg.(type) {
 		case UserMsg:
 			flush()
+			prompt = m.Settings
 			chunks = append(chunks, Chunk{
-				Type:      UserChunk,
-				Timestamp: m.Timestamp,
-				UserText:  m.Text,
+				Type:           UserChunk,
+				Timestamp:      m.Timestamp,
+				UUID:           m.UUID,
+				UserText:       m.Text,
+				PermissionMode: m.PermissionMode,
+				Branch:         m.Branch,
+				Branches:       m.Branches,
 			})
-		case SystemMsg:
+		case AttachmentMsg:
+			// Attachments only follow a prompt. Anything else is stray meta
+			// and stays hidden as before.
+			n := len(chunks)
+			if len(aiBuf) > 0 || n == 0 || chunks[n-1].Type != UserChunk {
+				continue
+			}
+			atts := chunks[n-1].Attachments
+			if !m.IsResult {
+				chunks[n-1].Attachments = append(atts, Attachment{ToolName: m.ToolName, Path: m.Path})
+			} else if k := len(atts); k > 0 && atts[k-1].TokenCount == 0 {
+				atts[k-1].TokenCount = len(m.Content) / 4
+			}
+		case CommandMsg:
 			flush()
 			chunks = append(chunks, Chunk{
-				Type:      SystemChunk,
-				Timestamp: m.Timestamp,
-				Output:    m.Output,
-				IsError:   m.IsError,
+				Type:        CommandChunk,
+				Timestamp:   m.Timestamp,
+				Command:     m.Name,
+				CommandArgs: m.Args,
 			})
+		case SystemMsg:
+			if m.Kind != SystemOutput && len(aiBuf) > 0 {
+				// A notice written mid-turn follows the turn rather than
+				// splitting it.
+				notices = append(notices, systemChunk(m))
+				continue
+			}
+			flush()
+			// A background task's notification completes the Bash item that
+			// started it instead of standing alone.
+			if m.TaskID != "" && finishBackgroundTask(chunks, m) {
+				continue
+			}
+			if n := len(chunks); m.IsCommandOutput && n > 0 &&
+				chunks[n-1].Type == CommandChunk && chunks[n-1].Output == "" {
+				chunks[n-1].Output = m.Output
+				chunks[n-1].IsError = m.IsError
+				continue
+			}
+			chunks = append(chunks, systemChunk(m))
 		case AIMsg:
 			aiBuf = append(aiBuf, m)
 		case TeammateMsg:
@@ -134,20 +269,197 @@ func BuildChunks(msgs []ClassifiedMsg) []Chunk {
 					TeammateColor: m.Color,
 				}},
 			})
+		case QueuedMsg:
+			// A prompt queued while Claude worked lands mid-turn; keep it
+			// in the turn it steered instead of splitting it with a user
+			// chunk.
+			aiBuf = append(aiBuf, AIMsg{
+				Timestamp: m.Timestamp,
+				IsMeta:    true,
+				Blocks:    []ContentBlock{{Type: "queued", Text: m.Text}},
+			})
+		case HookMsg:
+			// Between turns a hook belongs to the tool call it wrapped in
+			// the turn before, or to nothing; it doesn't make a turn of its
+			// own.
+			if len(aiBuf) == 0 {
+				attachHook(chunks, m)
+				continue
+			}
+			// Fold hook output into the AI buffer so mergeAIBuffer can attach
+			// it to the tool call it wrapped.
+			aiBuf = append(aiBuf, AIMsg{
+				Timestamp: m.Timestamp,
+				IsMeta:    true,
+				Blocks: []ContentBlock{{
+					Type:    "hook",
+					ToolID:  m.ToolID,
+					Content: m.Output,
+					IsError: m.IsError,
+					Hook: &HookOutput{
+						Event:   m.Event,
+						Matcher: m.Matcher,
+						Command: m.Command,
+						Output:  m.Output,
+						IsError: m.IsError,
+					},
+				}},
+			})
+		case ErrorMsg:
+			// A request failing mid-turn doesn't end the turn: the retry, or
+			// the next prompt, carries on from it.
+			if len(aiBuf) > 0 {
+				turnErrors = append(turnErrors, m)
+				continue
+			}
+			// Retries of one request arrive back to back; they share a chunk.
+			if n := len(chunks); n > 0 && chunks[n-1].Type == ErrorChunk {
+				chunks[n-1].Errors = append(chunks[n-1].Errors, m)
+				continue
+			}
+			chunks = append(chunks, Chunk{
+				Type:      ErrorChunk,
+				Timestamp: m.Timestamp,
+				Errors:    []ErrorMsg{m},
+			})
 		case CompactMsg:
 			flush()
+			// The summary entry completes the boundary just before it.
+			if n := len(chunks); n > 0 && m.Text == "" && m.Summary != "" && chunks[n-1].Type == CompactChunk && chunks[n-1].CompactSummary == "" {
+				chunks[n-1].CompactSummary = m.Summary
+				continue
+			}
 			chunks = append(chunks, Chunk{
-				Type:      CompactChunk,
-				Timestamp: m.Timestamp,
-				Output:    m.Text,
+				Type:           CompactChunk,
+				Timestamp:      m.Timestamp,
+				Output:         m.Text,
+				CompactTrigger: m.Trigger,
+				PreTokens:      m.PreTokens,
+				PostTokens:     m.PostTokens,
+				CompactSummary: m.Summary,
 			})
 		}
 	}
 	flush()
+	computeContextDeltas(chunks)
+	markCacheRebuilds(chunks)
+	fillCompactPostTokens(chunks)
 
 	return chunks
 }
 
+// attachHook adds hook output written between turns to the tool call it
+// wrapped in the last AI chunk. Hooks without one there are dropped.
+func attachHook(chunks []Chunk, m HookMsg) {
+	if m.ToolID == "" {
+		return
+	}
+	for i := len(chunks) - 1; i >= 0; i-- {
+		if chunks[i].Type != AIChunk {
+			continue
+		}
+		items := chunks[i].Items
+		for j := range items {
+			if items[j].ToolID == m.ToolID {
+				items[j].Hooks = append(items[j].Hooks, HookOutput{
+					Event:   m.Event,
+					Matcher: m.Matcher,
+					Command: m.Command,
+					Output:  m.Output,
+					IsError: m.IsError,
+				})
+				return
+			}
+		}
+		return
+	}
+}
+
+// systemChunk builds the chunk of a system message.
+func systemChunk(m SystemMsg) Chunk {
+	return Chunk{
+		Type:        SystemChunk,
+		Timestamp:   m.Timestamp,
+		Output:      m.Output,
+		IsError:     m.IsError,
+		SystemKind:  m.Kind,
+		SystemLevel: m.Level,
+	}
+}
+
+// computeContextDeltas sets ContextDelta on each AI chunk by comparing its
+// usage snapshot with the previous AI chunk that reported usage. The first
+// such chunk has no baseline and keeps a zero delta. Compaction shows up as
+// a negative delta.
+func computeContextDeltas(chunks []Chunk) {
+	prev := 0
+	for i := range chunks {
+		if chunks[i].Type != AIChunk {
+			continue
+		}
+		ctx := chunks[i].Usage.ContextTokens()
+		if ctx == 0 {
+			continue
+		}
+		if prev > 0 {
+			chunks[i].ContextDelta = ctx - prev
+		}
+		prev = ctx
+	}
+}
+
+// CacheRebuildMinTokens is the smallest cache write markCacheRebuilds flags.
+// Below it, rewriting the prompt cache costs too little to matter.
+const CacheRebuildMinTokens = 10_000
+
+// markCacheRebuilds sets CacheRebuilt on AI chunks with a request that wrote
+// most of its context to the prompt cache after earlier requests had warmed
+// it: the cached prefix was invalidated (changed system context, tools, or
+// CLAUDE.md) or expired, and the whole conversation was paid for again. The
+// first request of the session and the first after a compaction start cold
+// and are not flagged.
+func markCacheRebuilds(chunks []Chunk) {
+	warm := false
+	for i := range chunks {
+		c := &chunks[i]
+		switch c.Type {
+		case CompactChunk:
+			warm = false
+		case AIChunk:
+			for _, u := range c.requests {
+				w := u.CacheCreationTokens
+				if warm && w >= CacheRebuildMinTokens && 2*w >= u.ContextTokens() {
+					c.CacheRebuilt = max(c.CacheRebuilt, w)
+				}
+				if u.ContextTokens() > 0 {
+					warm = true
+				}
+			}
+		}
+	}
+}
+
+// fillCompactPostTokens sets PostTokens on compact chunks that recorded the
+// size before compaction but not after, from the next AI chunk's context
+// snapshot: the first request made from the summary.
+func fillCompactPostTokens(chunks []Chunk) {
+	for i := range chunks {
+		c := &chunks[i]
+		if c.Type != CompactChunk || c.PreTokens == 0 || c.PostTokens > 0 {
+			continue
+		}
+		for j := i + 1; j < len(chunks); j++ {
+			if chunks[j].Type == CompactChunk {
+				break
+			}
+			if ctx := chunks[j].Usage.ContextTokens(); chunks[j].Type == AIChunk && ctx > 0 {
+				c.PostTokens = ctx
+				break
+			}
+		}
+	}
+}
+
 // pendingTool tracks a tool_use DisplayItem awaiting its result.
 type pendingTool struct {
 	index     int       // index into the items slice
@@ -168,6 +480,7 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 	// Structured items built from ContentBlocks.
 	var items []DisplayItem
 	pending := make(map[string]pendingTool) // ToolID -> pending info
+	toolIndex := make(map[string]int)       // ToolID -> items index, kept after the result lands
 	hasBlocks := false
 
 	for _, m := range buf {
@@ -202,10 +515,21 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 						TokenCount: len(b.Text) / 4,
 					})
 				case "text":
+					// The API splits cited text into a block per citation;
+					// a cited block joins the output right before it, and so
+					// does the text after it, so the answer reads as one.
+					if n := len(items) - 1; n >= 0 && items[n].Type == ItemOutput &&
+						(len(b.References) > 0 || len(items[n].References) > 0) {
+						items[n].Text += b.Text
+						items[n].TokenCount = len(items[n].Text) / 4
+						items[n].References = MergeReferences(items[n].References, b.References...)
+						continue
+					}
 					items = append(items, DisplayItem{
 						Type:       ItemOutput,
 						Text:       b.Text,
 						TokenCount: len(b.Text) / 4,
+						References: b.References,
 					})
 				case "tool_use":
 					inputLen := len(b.ToolInput)
@@ -222,6 +546,7 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 							SubagentDesc:   info.Description,
 							TeamMemberName: info.MemberName,
 							TokenCount:     inputLen / 4,
+							Timestamp:      m.Timestamp,
 						})
 					} else {
 						items = append(items, DisplayItem{
@@ -232,8 +557,10 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 							ToolSummary:  ToolSummary(b.ToolName, b.ToolInput),
 							ToolCategory: CategorizeToolName(b.ToolName),
 							TokenCount:   inputLen / 4,
+							Timestamp:    m.Timestamp,
 						})
 					}
+					toolIndex[b.ToolID] = len(items) - 1
 					pending[b.ToolID] = pendingTool{
 						index:     len(items) - 1,
 						timestamp: m.Timestamp,
@@ -248,6 +575,15 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 					if p, ok := pending[b.ToolID]; ok {
 						items[p.index].ToolResult = b.Content
 						items[p.index].ToolError = b.IsError
+						items[p.index].Patch = b.Patch
+						// Only splits an approval wait, which tools that
+						// never prompt don't have.
+						if !NeverPrompts(items[p.index].ToolCategory) {
+							items[p.index].ExecMs = b.ExecMs
+						}
+						if id := backgroundTaskID(b.Content); id != "" && items[p.index].ToolName == "Bash" {
+							items[p.index].Background = &BackgroundTask{ID: id, Status: BackgroundRunning, Started: items[p.index].Timestamp}
+						}
 						if !p.timestamp.IsZero() && !m.Timestamp.IsZero() {
 							items[p.index].DurationMs = m.Timestamp.Sub(p.timestamp).Milliseconds()
 						}
@@ -261,6 +597,21 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 							TokenCount: len(b.Content) / 4,
 						})
 					}
+				case "hook":
+					if i, ok := toolIndex[b.ToolID]; ok && b.ToolID != "" {
+						items[i].Hooks = append(items[i].Hooks, *b.Hook)
+					} else {
+						// Unattributed hook (Stop, UserPromptSubmit, or a tool
+						// from an earlier turn) -> standalone hook item.
+						items = append(items, DisplayItem{
+							Type:       ItemHook,
+							Text:       b.Content,
+							ToolName:   b.Hook.Name(),
+							ToolError:  b.IsError,
+							Hooks:      []HookOutput{*b.Hook},
+							TokenCount: len(b.Content) / 4,
+						})
+					}
 				case "teammate":
 					items = append(items, DisplayItem{
 						Type:          ItemTeammateMessage,
@@ -269,6 +620,13 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 						TeammateColor: b.TeammateColor,
 						TokenCount:    len(b.Text) / 4,
 					})
+				case "queued":
+					items = append(items, DisplayItem{
+						Type:       ItemQueuedMessage,
+						Text:       b.Text,
+						Timestamp:  m.Timestamp,
+						TokenCount: len(b.Text) / 4,
+					})
 				}
 			}
 		}
@@ -298,9 +656,29 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 	// reports input_tokens as the full context window per call, so the last
 	// call is the correct per-turn metric (not the sum across round trips).
 	var usage Usage
+	var requests []Usage
+	for _, msg := range buf {
+		if !msg.IsMeta && msg.Usage.TotalTokens() > 0 {
+			usage = msg.Usage
+			requests = append(requests, msg.Usage)
+		}
+	}
+
+	// Service tier and version: last assistant message that recorded them.
+	var settings RequestSettings
 	for i := len(buf) - 1; i >= 0; i-- {
-		if !buf[i].IsMeta && buf[i].Usage.TotalTokens() > 0 {
-			usage = buf[i].Usage
+		if !buf[i].IsMeta && (buf[i].Settings.ServiceTier != "" || buf[i].Settings.Version != "") {
+			settings = buf[i].Settings
+			break
+		}
+	}
+
+	// The first entry that has one names the turn; synthesized hook and
+	// teammate messages don't.
+	var uuid string
+	for _, msg := range buf {
+		if msg.UUID != "" {
+			uuid = msg.UUID
 			break
 		}
 	}
@@ -308,6 +686,7 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 	return Chunk{
 		Type:          AIChunk,
 		Timestamp:     ts,
+		UUID:          uuid,
 		Model:         model,
 		Text:          strings.Join(texts, "\n"),
 		ThinkingCount: thinking,
@@ -316,6 +695,8 @@ func mergeAIBuffer(buf []AIMsg) Chunk {
 		Usage:         usage,
 		StopReason:    stop,
 		DurationMs:    dur,
+		Settings:      settings,
+		requests:      requests,
 	}
 }
 
@@ -346,7 +727,7 @@ func suppressInflatedDurations(items []DisplayItem) {
 	// is close to or exceeds the Task duration (suggesting they waited
 	// for the same background work).
 	for i := range items {
-		if items[i].Type == ItemSubagent || items[i].Type == ItemTeammateMessage {
+		if items[i].Type == ItemSubagent || items[i].Type == ItemTeammateMessage || items[i].Type == ItemHook || items[i].Type == ItemQueuedMessage {
 			continue
 		}
 		if items[i].DurationMs > concurrentTaskDurationThreshold {
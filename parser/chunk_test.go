@@ -2,6 +2,8 @@ package parser_test
 
 import (
 	"encoding/json"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,8 +13,9 @@ import (
 func TestBuildChunks_SingleUser(t *testing.T) {
 	msgs := []parser.ClassifiedMsg{
 		parser.UserMsg{
-			Timestamp: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
-			Text:      "Hello",
+			Timestamp:      time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+			Text:           "Hello",
+			PermissionMode: "acceptEdits",
 		},
 	}
 	chunks := parser.BuildChunks(msgs)
@@ -25,6 +28,9 @@ func TestBuildChunks_SingleUser(t *testing.T) {
 	if chunks[0].UserText != "Hello" {
 		t.Errorf("UserText = %q, want %q", chunks[0].UserText, "Hello")
 	}
+	if chunks[0].PermissionMode != "acceptEdits" {
+		t.Errorf("PermissionMode = %q, want %q", chunks[0].PermissionMode, "acceptEdits")
+	}
 }
 
 func TestBuildChunks_UserAIUser(t *testing.T) {
@@ -53,6 +59,7 @@ func TestBuildChunks_ConsecutiveAIMerged(t *testing.T) {
 	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
 	msgs := []parser.ClassifiedMsg{
 		parser.AIMsg{
+			UUID:          "a1",
 			Timestamp:     t0,
 			Text:          "First response",
 			Model:         "claude-opus-4-6",
@@ -61,6 +68,7 @@ func TestBuildChunks_ConsecutiveAIMerged(t *testing.T) {
 			Usage:         parser.Usage{InputTokens: 100, OutputTokens: 50},
 		},
 		parser.AIMsg{
+			UUID:          "a2",
 			Timestamp:     t0.Add(3 * time.Second),
 			Text:          "Continued response",
 			IsMeta:        true,
@@ -81,6 +89,9 @@ func TestBuildChunks_ConsecutiveAIMerged(t *testing.T) {
 	if c.Text != "First response\nContinued response" {
 		t.Errorf("Text = %q, want merged text", c.Text)
 	}
+	if c.UUID != "a1" {
+		t.Errorf("UUID = %q, want the first entry's a1", c.UUID)
+	}
 	if c.ThinkingCount != 1 {
 		t.Errorf("Thinking = %d, want 1", c.ThinkingCount)
 	}
@@ -249,6 +260,36 @@ func TestBuildChunks_Items_ToolUseLinkedToResult(t *testing.T) {
 	}
 }
 
+func TestBuildChunks_Items_ExecMsOnlyWherePrompted(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.AIMsg{
+			Timestamp: t0,
+			Model:     "claude-opus-4-6",
+			Blocks: []parser.ContentBlock{
+				{Type: "tool_use", ToolID: "call_1", ToolName: "Grep", ToolInput: json.RawMessage(`{"pattern":"x"}`)},
+				{Type: "tool_use", ToolID: "call_2", ToolName: "WebFetch", ToolInput: json.RawMessage(`{"url":"https://example.com"}`)},
+			},
+		},
+		parser.AIMsg{Timestamp: t0.Add(time.Second), IsMeta: true, Blocks: []parser.ContentBlock{
+			{Type: "tool_result", ToolID: "call_1", Content: "a.go", ExecMs: 40},
+		}},
+		parser.AIMsg{Timestamp: t0.Add(2 * time.Second), IsMeta: true, Blocks: []parser.ContentBlock{
+			{Type: "tool_result", ToolID: "call_2", Content: "page", ExecMs: 900},
+		}},
+	}
+	items := parser.BuildChunks(msgs)[0].Items
+	if len(items) != 2 {
+		t.Fatalf("len(Items) = %d, want 2", len(items))
+	}
+	if items[0].ExecMs != 0 {
+		t.Errorf("Grep ExecMs = %d, want 0: it never prompts", items[0].ExecMs)
+	}
+	if items[1].ExecMs != 900 {
+		t.Errorf("WebFetch ExecMs = %d, want 900", items[1].ExecMs)
+	}
+}
+
 func TestBuildChunks_Items_ToolError(t *testing.T) {
 	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
 	msgs := []parser.ClassifiedMsg{
@@ -743,6 +784,288 @@ func TestBuildChunks_CompactChunkFlushesAIBuffer(t *testing.T) {
 	}
 }
 
+func TestReadSession_CompactBoundaryMetadata(t *testing.T) {
+	chunks, err := parser.ReadSession(filepath.Join("testdata", "compacted.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compacts []parser.Chunk
+	for _, c := range chunks {
+		if c.Type == parser.CompactChunk {
+			compacts = append(compacts, c)
+		}
+	}
+	if len(compacts) != 1 {
+		t.Fatalf("got %d compact chunks, want 1 (the summary joins the boundary)", len(compacts))
+	}
+	c := compacts[0]
+	if c.Output != "Conversation compacted" || c.CompactTrigger != "auto" {
+		t.Errorf("Output = %q, CompactTrigger = %q", c.Output, c.CompactTrigger)
+	}
+	if c.PreTokens != 155000 {
+		t.Errorf("PreTokens = %d, want 155000", c.PreTokens)
+	}
+	// Not recorded: taken from the first request after the boundary.
+	if c.PostTokens != 8200 {
+		t.Errorf("PostTokens = %d, want 8200", c.PostTokens)
+	}
+	if !strings.Contains(c.CompactSummary, "parser was refactored") {
+		t.Errorf("CompactSummary = %q", c.CompactSummary)
+	}
+}
+
+func TestBuildChunks_QueuedMsgStaysInTurn(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.UserMsg{Timestamp: t0, Text: "fix the bug"},
+		parser.AIMsg{Timestamp: t0.Add(time.Second), Text: "Looking", Model: "claude-opus-4-6",
+			Blocks: []parser.ContentBlock{{Type: "text", Text: "Looking"}}},
+		parser.QueuedMsg{Timestamp: t0.Add(2 * time.Second), Text: "also add a test"},
+		parser.AIMsg{Timestamp: t0.Add(3 * time.Second), Text: "Adding a test too", Model: "claude-opus-4-6",
+			Blocks: []parser.ContentBlock{{Type: "text", Text: "Adding a test too"}}},
+	}
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 2 {
+		t.Fatalf("len(chunks) = %d, want 2 (user + one AI turn)", len(chunks))
+	}
+	var queued []parser.DisplayItem
+	for _, it := range chunks[1].Items {
+		if it.Type == parser.ItemQueuedMessage {
+			queued = append(queued, it)
+		}
+	}
+	if len(queued) != 1 || queued[0].Text != "also add a test" || !queued[0].Timestamp.Equal(t0.Add(2*time.Second)) {
+		t.Errorf("queued items = %+v", queued)
+	}
+	if items := chunks[1].Items; len(items) != 3 || items[1].Type != parser.ItemQueuedMessage {
+		t.Errorf("items = %+v, want the queued prompt between the two outputs", items)
+	}
+}
+
+// --- CommandChunk tests ---
+
+func TestBuildChunks_CommandOutputFoldsIntoCommandChunk(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.AIMsg{Timestamp: t0, Text: "Done", Model: "claude-opus-4-6"},
+		parser.CommandMsg{Timestamp: t0.Add(1 * time.Second), Name: "/model", Args: "sonnet"},
+		parser.SystemMsg{Timestamp: t0.Add(2 * time.Second), Output: "Set model to sonnet", IsCommandOutput: true},
+		parser.SystemMsg{Timestamp: t0.Add(3 * time.Second), Output: "unrelated", IsCommandOutput: true},
+	}
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 3 {
+		t.Fatalf("len(chunks) = %d, want 3 (AI + command + system)", len(chunks))
+	}
+	cmd := chunks[1]
+	if cmd.Type != parser.CommandChunk {
+		t.Fatalf("chunks[1].Type = %d, want CommandChunk", cmd.Type)
+	}
+	if cmd.Command != "/model" || cmd.CommandArgs != "sonnet" {
+		t.Errorf("Command = %q %q, want /model sonnet", cmd.Command, cmd.CommandArgs)
+	}
+	if cmd.Output != "Set model to sonnet" {
+		t.Errorf("Output = %q, want %q", cmd.Output, "Set model to sonnet")
+	}
+	// Only the first output attaches; later output stays a system chunk.
+	if chunks[2].Type != parser.SystemChunk {
+		t.Errorf("chunks[2].Type = %d, want SystemChunk", chunks[2].Type)
+	}
+}
+
+func TestBuildChunks_StatusNoticeFollowsTurn(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.UserMsg{Timestamp: t0, Text: "go"},
+		parser.AIMsg{Timestamp: t0.Add(1 * time.Second), Text: "Working", Model: "claude-opus-4-6"},
+		parser.SystemMsg{Timestamp: t0.Add(2 * time.Second), Kind: parser.SystemStatus, Level: "warning", Output: "Context low"},
+		parser.AIMsg{Timestamp: t0.Add(3 * time.Second), Text: "Done", Model: "claude-opus-4-6"},
+	}
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 3 {
+		t.Fatalf("len(chunks) = %d, want 3 (user + AI + status)", len(chunks))
+	}
+	if chunks[1].Type != parser.AIChunk || chunks[1].Text != "Working\nDone" {
+		t.Errorf("chunks[1] = %+v, want one AI turn with both outputs", chunks[1])
+	}
+	sys := chunks[2]
+	if sys.Type != parser.SystemChunk || sys.SystemKind != parser.SystemStatus || sys.SystemLevel != "warning" || sys.Output != "Context low" {
+		t.Errorf("chunks[2] = %+v, want the warning status after the turn", sys)
+	}
+}
+
+// --- Attachment tests ---
+
+func TestBuildChunks_AttachmentsFoldIntoUserChunk(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.UserMsg{Timestamp: t0, Text: "explain @main.go and @go.mod"},
+		parser.AttachmentMsg{Timestamp: t0, ToolName: "Read", Path: "/repo/main.go"},
+		parser.AttachmentMsg{Timestamp: t0, ToolName: "Read", Content: strings.Repeat("x", 4000), IsResult: true},
+		parser.AttachmentMsg{Timestamp: t0, ToolName: "Read", Path: "/repo/go.mod"},
+		parser.AttachmentMsg{Timestamp: t0, ToolName: "Read", Content: strings.Repeat("y", 400), IsResult: true},
+		parser.AIMsg{Timestamp: t0.Add(time.Second), Text: "Sure", Model: "claude-opus-4-6"},
+		// Stray attachment after AI output is ignored.
+		parser.AttachmentMsg{Timestamp: t0, ToolName: "Read", Path: "/repo/stray.go"},
+	}
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 2 {
+		t.Fatalf("len(chunks) = %d, want 2 (user + AI)", len(chunks))
+	}
+	atts := chunks[0].Attachments
+	if len(atts) != 2 {
+		t.Fatalf("len(Attachments) = %d, want 2", len(atts))
+	}
+	if atts[0].Path != "/repo/main.go" || atts[0].TokenCount != 1000 {
+		t.Errorf("atts[0] = %+v, want /repo/main.go ~1000 tok", atts[0])
+	}
+	if atts[1].Path != "/repo/go.mod" || atts[1].TokenCount != 100 {
+		t.Errorf("atts[1] = %+v, want /repo/go.mod ~100 tok", atts[1])
+	}
+}
+
+// --- Hook attribution tests ---
+
+func TestBuildChunks_HookAttachesToToolCall(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.AIMsg{
+			Timestamp: t0,
+			Model:     "claude-opus-4-6",
+			Blocks: []parser.ContentBlock{
+				{Type: "tool_use", ToolID: "call_1", ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"ls"}`)},
+			},
+		},
+		parser.HookMsg{Timestamp: t0.Add(1 * time.Second), Event: "PreToolUse", Matcher: "Bash", Output: "ok", ToolID: "call_1"},
+		parser.AIMsg{
+			Timestamp: t0.Add(2 * time.Second),
+			IsMeta:    true,
+			Blocks:    []parser.ContentBlock{{Type: "tool_result", ToolID: "call_1", Content: "main.go"}},
+		},
+		parser.HookMsg{Timestamp: t0.Add(3 * time.Second), Event: "PostToolUse", Matcher: "Bash", Output: "lint failed", IsError: true, ToolID: "call_1"},
+		parser.HookMsg{Timestamp: t0.Add(4 * time.Second), Event: "Stop", Output: "done"},
+	}
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 1 {
+		t.Fatalf("len(chunks) = %d, want 1", len(chunks))
+	}
+	items := chunks[0].Items
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, want 2 (tool + stop hook)", len(items))
+	}
+	tool := items[0]
+	if len(tool.Hooks) != 2 {
+		t.Fatalf("len(tool.Hooks) = %d, want 2", len(tool.Hooks))
+	}
+	if tool.Hooks[0].Name() != "PreToolUse:Bash" || tool.Hooks[1].Name() != "PostToolUse:Bash" {
+		t.Errorf("hook names = %q, %q", tool.Hooks[0].Name(), tool.Hooks[1].Name())
+	}
+	if !tool.Hooks[1].IsError {
+		t.Error("PostToolUse hook should be an error")
+	}
+	if tool.ToolError {
+		t.Error("hook failure should not mark the tool result itself as an error")
+	}
+	if items[1].Type != parser.ItemHook || items[1].ToolName != "Stop" {
+		t.Errorf("items[1] = %d %q, want ItemHook Stop", items[1].Type, items[1].ToolName)
+	}
+}
+
+func TestBuildChunks_HookBetweenTurns(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.HookMsg{Timestamp: t0, Event: "SessionStart", Output: "loaded"},
+		parser.UserMsg{Timestamp: t0.Add(time.Second), Text: "List files"},
+		parser.AIMsg{
+			Timestamp: t0.Add(2 * time.Second),
+			Model:     "claude-opus-4-6",
+			Blocks: []parser.ContentBlock{
+				{Type: "tool_use", ToolID: "call_1", ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"ls"}`)},
+			},
+		},
+		parser.UserMsg{Timestamp: t0.Add(3 * time.Second), Text: "Thanks"},
+		// Written after the next prompt, for the call in the turn before.
+		parser.HookMsg{Timestamp: t0.Add(4 * time.Second), Event: "PostToolUse", Matcher: "Bash", Output: "late", ToolID: "call_1"},
+		parser.HookMsg{Timestamp: t0.Add(5 * time.Second), Event: "UserPromptSubmit", Output: "checked"},
+	}
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 3 {
+		t.Fatalf("len(chunks) = %d, want user, AI, user (no turns made of hooks)", len(chunks))
+	}
+	if hooks := chunks[1].Items[0].Hooks; len(hooks) != 1 || hooks[0].Output != "late" {
+		t.Errorf("tool hooks = %+v, want the late PostToolUse hook", hooks)
+	}
+}
+
+// --- Context delta tests ---
+
+func TestBuildChunks_ContextDelta(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.UserMsg{Timestamp: t0, Text: "one"},
+		parser.AIMsg{Timestamp: t0, Text: "a", Usage: parser.Usage{InputTokens: 100, CacheReadTokens: 10000, OutputTokens: 50}},
+		parser.UserMsg{Timestamp: t0, Text: "two"},
+		parser.AIMsg{Timestamp: t0, Text: "b", Usage: parser.Usage{InputTokens: 200, CacheReadTokens: 13100}},
+		parser.UserMsg{Timestamp: t0, Text: "three"},
+		parser.AIMsg{Timestamp: t0, Text: "no usage"},
+		parser.UserMsg{Timestamp: t0, Text: "four"},
+		parser.AIMsg{Timestamp: t0, Text: "c", Usage: parser.Usage{InputTokens: 5000}},
+	}
+	chunks := parser.BuildChunks(msgs)
+	var deltas []int
+	for _, c := range chunks {
+		if c.Type == parser.AIChunk {
+			deltas = append(deltas, c.ContextDelta)
+		}
+	}
+	want := []int{0, 3200, 0, -8300}
+	if len(deltas) != len(want) {
+		t.Fatalf("got %d AI chunks, want %d", len(deltas), len(want))
+	}
+	for i := range want {
+		if deltas[i] != want[i] {
+			t.Errorf("deltas[%d] = %d, want %d", i, deltas[i], want[i])
+		}
+	}
+}
+
+func TestBuildChunks_CacheRebuilt(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		// Cold start: the first request writes the whole cache.
+		parser.UserMsg{Timestamp: t0, Text: "one"},
+		parser.AIMsg{Timestamp: t0, Text: "a", Usage: parser.Usage{InputTokens: 10, CacheCreationTokens: 40000}},
+		// Warm: mostly read from the cache.
+		parser.UserMsg{Timestamp: t0, Text: "two"},
+		parser.AIMsg{Timestamp: t0, Text: "b", Usage: parser.Usage{InputTokens: 10, CacheReadTokens: 40000, CacheCreationTokens: 2000}},
+		// Busted mid-turn: the second request re-writes everything.
+		parser.UserMsg{Timestamp: t0, Text: "three"},
+		parser.AIMsg{Timestamp: t0, Text: "c", Usage: parser.Usage{InputTokens: 10, CacheReadTokens: 42000, CacheCreationTokens: 500}},
+		parser.AIMsg{Timestamp: t0, Text: "d", Usage: parser.Usage{InputTokens: 10, CacheCreationTokens: 43000}},
+		// A large write that is still mostly a cache read.
+		parser.UserMsg{Timestamp: t0, Text: "four"},
+		parser.AIMsg{Timestamp: t0, Text: "e", Usage: parser.Usage{InputTokens: 10, CacheReadTokens: 43000, CacheCreationTokens: 15000}},
+		// Compaction starts a cold cache again.
+		parser.CompactMsg{Timestamp: t0, Text: "Conversation compacted"},
+		parser.UserMsg{Timestamp: t0, Text: "five"},
+		parser.AIMsg{Timestamp: t0, Text: "f", Usage: parser.Usage{InputTokens: 10, CacheCreationTokens: 12000}},
+	}
+	var got []int
+	for _, c := range parser.BuildChunks(msgs) {
+		if c.Type == parser.AIChunk {
+			got = append(got, c.CacheRebuilt)
+		}
+	}
+	want := []int{0, 0, 43000, 0, 0}
+	if len(got) != len(want) {
+		t.Fatalf("got %d AI chunks, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CacheRebuilt[%d] = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
 // --- Usage snapshot tests ---
 // The Claude API reports input_tokens as the full context window per API call,
 // not incremental. Chunk.Usage should reflect the last assistant message's
@@ -1056,3 +1379,105 @@ func TestBuildChunks_ConcurrentTask_ShortDurationPreserved(t *testing.T) {
 		t.Errorf("Read DurationMs = %d, want 2000 (under threshold, preserved)", items[0].DurationMs)
 	}
 }
+
+func TestBuildChunks_Settings(t *testing.T) {
+	lines := []string{
+		`{"type":"user","uuid":"u1","timestamp":"2025-01-15T10:00:00Z","version":"2.0.14","thinkingMetadata":{"level":"high","disabled":false,"maxThinkingTokens":31999},"message":{"role":"user","content":"Plan it"}}`,
+		`{"type":"assistant","uuid":"a1","timestamp":"2025-01-15T10:00:02Z","version":"2.0.14","message":{"role":"assistant","model":"claude-opus-4-6","content":[{"type":"text","text":"Done"}],"usage":{"input_tokens":10,"output_tokens":5,"service_tier":"standard"}}}`,
+		`{"type":"user","uuid":"u2","timestamp":"2025-01-15T10:01:00Z","version":"2.0.15","thinkingMetadata":{"level":"none","disabled":true,"maxThinkingTokens":0},"message":{"role":"user","content":"Quick one"}}`,
+		`{"type":"assistant","uuid":"a2","timestamp":"2025-01-15T10:01:01Z","message":{"role":"assistant","model":"claude-opus-4-6","content":[{"type":"text","text":"Sure"}]}}`,
+	}
+	var msgs []parser.ClassifiedMsg
+	for _, line := range lines {
+		e, ok := parser.ParseEntry([]byte(line))
+		if !ok {
+			t.Fatalf("ParseEntry failed: %s", line)
+		}
+		if msg, ok := parser.Classify(e); ok {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 4 {
+		t.Fatalf("len(chunks) = %d, want 4", len(chunks))
+	}
+	want := parser.RequestSettings{ThinkingLevel: "high", ThinkingBudget: 31999, ServiceTier: "standard", Version: "2.0.14"}
+	if got := chunks[1].Settings; got != want {
+		t.Errorf("first turn Settings = %+v, want %+v", got, want)
+	}
+	// The second response records nothing itself: thinking and version come
+	// from its prompt.
+	want = parser.RequestSettings{ThinkingLevel: "none", ThinkingDisabled: true, Version: "2.0.15"}
+	if got := chunks[3].Settings; got != want {
+		t.Errorf("second turn Settings = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuildChunks_APIErrors(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := []parser.ClassifiedMsg{
+		parser.UserMsg{Timestamp: t0, Text: "Go"},
+		parser.ErrorMsg{Timestamp: t0.Add(time.Second), Kind: "overloaded_error", RetryAttempt: 1, MaxRetries: 10},
+		parser.ErrorMsg{Timestamp: t0.Add(2 * time.Second), Kind: "overloaded_error", RetryAttempt: 2, MaxRetries: 10},
+	}
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 2 || chunks[1].Type != parser.ErrorChunk {
+		t.Fatalf("chunks = %+v, want user + one error chunk", chunks)
+	}
+	if n := len(chunks[1].Errors); n != 2 {
+		t.Errorf("len(Errors) = %d, want 2", n)
+	}
+	if !chunks[1].Timestamp.Equal(t0.Add(time.Second)) {
+		t.Errorf("Timestamp = %v, want first error's", chunks[1].Timestamp)
+	}
+	if !parser.IsOngoing(chunks) {
+		t.Error("session retrying a request should be ongoing")
+	}
+
+	// The response that finally arrives starts a new AI chunk; an error
+	// after it belongs to that turn rather than standing alone.
+	msgs = append(msgs,
+		parser.AIMsg{Timestamp: t0.Add(5 * time.Second), Text: "Done", Model: "claude-opus-4-6"},
+		parser.ErrorMsg{Timestamp: t0.Add(9 * time.Second), Final: true},
+	)
+	chunks = parser.BuildChunks(msgs)
+	if len(chunks) != 3 || chunks[2].Type != parser.AIChunk || len(chunks[2].Errors) != 1 {
+		t.Fatalf("chunks = %+v, want user, error, AI carrying the final error", chunks)
+	}
+	if parser.IsOngoing(chunks) {
+		t.Error("session ending in a failed request should not be ongoing")
+	}
+
+	// Mid-turn: an error between two responses of one turn.
+	msgs = []parser.ClassifiedMsg{
+		parser.UserMsg{Timestamp: t0, Text: "Go"},
+		parser.AIMsg{Timestamp: t0.Add(time.Second), Text: "Reading", Model: "claude-opus-4-6"},
+		parser.ErrorMsg{Timestamp: t0.Add(2 * time.Second), Kind: "overloaded_error", RetryAttempt: 1, MaxRetries: 10},
+	}
+	// Retry scheduled after the turn's last response: still going.
+	chunks = parser.BuildChunks(msgs)
+	if len(chunks) != 2 || chunks[1].Type != parser.AIChunk || len(chunks[1].Errors) != 1 {
+		t.Fatalf("chunks = %+v, want user + AI carrying the error", chunks)
+	}
+	if !parser.IsOngoing(chunks) {
+		t.Error("turn retrying a request should be ongoing")
+	}
+
+	// The retry succeeds: one turn, not two around the error.
+	msgs = append(msgs,
+		parser.ErrorMsg{Timestamp: t0.Add(4 * time.Second), Kind: "overloaded_error", RetryAttempt: 2, MaxRetries: 10},
+		parser.AIMsg{Timestamp: t0.Add(6 * time.Second), Text: "Done", Model: "claude-opus-4-6"},
+		parser.UserMsg{Timestamp: t0.Add(9 * time.Second), Text: "Thanks"},
+	)
+	chunks = parser.BuildChunks(msgs)
+	if len(chunks) != 3 || chunks[1].Type != parser.AIChunk || chunks[2].Type != parser.UserChunk {
+		t.Fatalf("chunk types = %v, want user, AI, user", chunks)
+	}
+	if got := chunks[1].Text; !strings.Contains(got, "Reading") || !strings.Contains(got, "Done") {
+		t.Errorf("AI chunk text = %q, want both responses", got)
+	}
+	if n := len(chunks[1].Errors); n != 2 {
+		t.Errorf("len(Errors) = %d, want both retries on the turn", n)
+	}
+}
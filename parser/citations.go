@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Reference is a source a response's text cites: a web page from a search,
+// or a document or search result passed to the model.
+type Reference struct {
+	Title     string `json:"title,omitempty"`
+	URL       string `json:"url,omitempty"`
+	CitedText string `json:"citedText,omitempty"` // the passage the text relies on
+}
+
+// Label returns the reference's title, or its URL when it has none.
+func (r Reference) Label() string {
+	if r.Title != "" {
+		return r.Title
+	}
+	return r.URL
+}
+
+// citationJSON is one entry of a text block's citations array. Web search
+// citations carry a URL; document citations (char_location, page_location,
+// content_block_location) a document title and index; search result
+// citations a source.
+type citationJSON struct {
+	Type          string `json:"type"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	Source        string `json:"source"`
+	DocumentTitle string `json:"document_title"`
+	DocumentIndex *int   `json:"document_index"`
+	CitedText     string `json:"cited_text"`
+}
+
+// parseCitations converts a text block's citations array into references,
+// merged as by MergeReferences. Returns nil when raw holds none.
+func parseCitations(raw json.RawMessage) []Reference {
+	if len(raw) == 0 {
+		return nil
+	}
+	var cites []citationJSON
+	if err := json.Unmarshal(raw, &cites); err != nil {
+		return nil
+	}
+	var refs []Reference
+	for _, c := range cites {
+		r := Reference{Title: c.Title, URL: c.URL, CitedText: c.CitedText}
+		if r.URL == "" {
+			r.URL = c.Source
+		}
+		if r.Title == "" {
+			r.Title = c.DocumentTitle
+		}
+		if r.Title == "" && r.URL == "" && c.DocumentIndex != nil {
+			r.Title = fmt.Sprintf("Document %d", *c.DocumentIndex+1)
+		}
+		if r.Label() == "" {
+			continue
+		}
+		refs = MergeReferences(refs, r)
+	}
+	return refs
+}
+
+// MergeReferences appends refs to dst, skipping sources dst already has
+// (same URL, or same title when neither has a URL). The first cited
+// passage of a source is kept.
+func MergeReferences(dst []Reference, refs ...Reference) []Reference {
+	for _, r := range refs {
+		dup := false
+		for _, d := range dst {
+			if d.URL == r.URL && (r.URL != "" || d.Title == r.Title) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, r)
+		}
+	}
+	return dst
+}
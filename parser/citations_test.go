@@ -0,0 +1,60 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestBuildChunks_CitationsBecomeReferences(t *testing.T) {
+	lines := []string{
+		`{"type":"user","uuid":"u1","timestamp":"2025-01-15T10:00:00Z","message":{"role":"user","content":"What changed in Go 1.22?"}}`,
+		`{"type":"assistant","uuid":"a1","timestamp":"2025-01-15T10:00:02Z","message":{"role":"assistant","model":"claude-opus-4-6","content":[` +
+			`{"type":"text","text":"Go 1.22 "},` +
+			`{"type":"text","text":"gives each loop iteration its own variable","citations":[{"type":"web_search_result_location","url":"https://go.dev/doc/go1.22","title":"Go 1.22 Release Notes","cited_text":"Each iteration of the loop creates new variables."}]},` +
+			`{"type":"text","text":" and ranges over integers","citations":[{"type":"web_search_result_location","url":"https://go.dev/doc/go1.22","title":"Go 1.22 Release Notes","cited_text":"For loops may now range over integers."},{"type":"char_location","document_index":0,"document_title":"spec.md","cited_text":"range 10"},{"type":"page_location","document_index":1}]},` +
+			`{"type":"text","text":"."}]}}`,
+	}
+	var msgs []parser.ClassifiedMsg
+	for _, line := range lines {
+		e, ok := parser.ParseEntry([]byte(line))
+		if !ok {
+			t.Fatalf("ParseEntry failed: %s", line)
+		}
+		if msg, ok := parser.Classify(e); ok {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 2 || len(chunks[1].Items) != 1 {
+		t.Fatalf("want one output item in the Claude turn, got %+v", chunks)
+	}
+	out := chunks[1].Items[0]
+	if want := "Go 1.22 gives each loop iteration its own variable and ranges over integers."; out.Text != want {
+		t.Errorf("Text = %q, want %q", out.Text, want)
+	}
+	want := []parser.Reference{
+		{Title: "Go 1.22 Release Notes", URL: "https://go.dev/doc/go1.22", CitedText: "Each iteration of the loop creates new variables."},
+		{Title: "spec.md", CitedText: "range 10"},
+		{Title: "Document 2"},
+	}
+	if len(out.References) != len(want) {
+		t.Fatalf("References = %+v, want %+v", out.References, want)
+	}
+	for i := range want {
+		if out.References[i] != want[i] {
+			t.Errorf("References[%d] = %+v, want %+v", i, out.References[i], want[i])
+		}
+	}
+}
+
+func TestBuildChunks_UncitedTextStaysSeparate(t *testing.T) {
+	msgs := []parser.ClassifiedMsg{
+		parser.AIMsg{Blocks: []parser.ContentBlock{{Type: "text", Text: "One."}, {Type: "text", Text: "Two."}}},
+	}
+	chunks := parser.BuildChunks(msgs)
+	if len(chunks) != 1 || len(chunks[0].Items) != 2 {
+		t.Fatalf("want two output items, got %+v", chunks)
+	}
+}
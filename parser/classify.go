@@ -2,6 +2,7 @@ package parser
 
 import (
 	"encoding/json"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,16 +15,20 @@ type ClassifiedMsg interface {
 
 // UserMsg represents genuine user input that starts a new request cycle.
 type UserMsg struct {
+	UUID           string // the entry's uuid
 	Timestamp      time.Time
-	Text           string // sanitized display text
-	PermissionMode string // "default", "acceptEdits", "bypassPermissions", "plan"; empty if not present
+	Text           string          // sanitized display text
+	PermissionMode string          // "default", "acceptEdits", "bypassPermissions", "plan"; empty if not present
+	Settings       RequestSettings // thinking configuration and version
+	Branch         int             // place among the prompts sent from a fork (1-based, in order sent); set by Lineage.Apply
+	Branches       int             // prompts sent from that fork; 0 when the prompt opens no branch
 }
 
 func (UserMsg) classifiedMsg() {}
 
 // ContentBlock represents a single content block from an assistant or tool result message.
 type ContentBlock struct {
-	Type          string          // "thinking", "text", "tool_use", "tool_result", "teammate"
+	Type          string          // "thinking", "text", "tool_use", "tool_result", "teammate", "hook", "queued"
 	Text          string          // thinking or text content
 	ToolID        string          // tool_use: call ID; tool_result: tool_use_id
 	ToolName      string          // tool_use only
@@ -32,10 +37,15 @@ type ContentBlock struct {
 	IsError       bool            // tool_result only
 	TeammateID    string          // teammate only
 	TeammateColor string          // teammate only: team color name
+	Hook          *HookOutput     // hook only
+	Patch         *FilePatch      // tool_result only: the change an Edit, MultiEdit, or Write made
+	ExecMs        int64           // tool_result only: run time the tool reported; 0 when not recorded
+	References    []Reference     // text only: the sources its citations point to
 }
 
 // AIMsg represents assistant responses and internal flow messages (tool results).
 type AIMsg struct {
+	UUID          string // the entry's uuid
 	Timestamp     time.Time
 	Model         string
 	Text          string // sanitized text content
@@ -44,11 +54,23 @@ type AIMsg struct {
 	Blocks        []ContentBlock // ordered content blocks, nil until populated
 	Usage         Usage
 	StopReason    string
-	IsMeta        bool // internal user message (tool results)
+	IsMeta        bool            // internal user message (tool results)
+	Settings      RequestSettings // service tier and version; zero for meta messages
 }
 
 func (AIMsg) classifiedMsg() {}
 
+// RequestSettings holds the request-level settings a transcript records for
+// a turn. Thinking settings come from the prompt; the service tier comes from
+// the response usage. Effort and beta flags aren't written to transcripts.
+type RequestSettings struct {
+	ThinkingLevel    string // "none", "high", ...; empty if not recorded
+	ThinkingDisabled bool
+	ThinkingBudget   int    // max thinking tokens; 0 if not recorded
+	ServiceTier      string // "standard", "priority", ...
+	Version          string // Claude Code version
+}
+
 // ToolCall is a tool invocation extracted from an assistant message.
 type ToolCall struct {
 	ID   string
@@ -68,15 +90,74 @@ func (u Usage) TotalTokens() int {
 	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheCreationTokens
 }
 
-// SystemMsg represents command output (slash command results, bash mode, task notifications).
+// ContextTokens returns the context window snapshot: input plus cache tokens,
+// excluding output.
+func (u Usage) ContextTokens() int {
+	return u.InputTokens + u.CacheReadTokens + u.CacheCreationTokens
+}
+
+// SystemMsg represents command output (slash command results, bash mode, task
+// notifications) and the typed notices of system entries (status, output
+// style, queue).
 type SystemMsg struct {
-	Timestamp time.Time
-	Output    string // extracted from stdout/stderr/notification tags
-	IsError   bool   // true when stderr is non-empty or task was killed
+	Timestamp       time.Time
+	Kind            SystemKind
+	Level           string // status only: "info", "warning", "error"; empty when not recorded
+	Output          string // extracted from stdout/stderr/notification tags, or the notice text
+	IsError         bool   // true when stderr is non-empty or task was killed
+	IsCommandOutput bool   // true for <local-command-stdout/stderr> (slash command results)
+	TaskID          string // <task-notification> only: the background task it reports on
+	TaskStatus      string // <task-notification> only: "completed", "killed", "failed"
 }
 
 func (SystemMsg) classifiedMsg() {}
 
+// CommandMsg represents a slash command invocation (<command-name> markup).
+// BuildChunks folds the command's local output into the same chunk.
+type CommandMsg struct {
+	Timestamp time.Time
+	Name      string // "/review", "/model"
+	Args      string // raw argument string; empty when none
+}
+
+func (CommandMsg) classifiedMsg() {}
+
+// AttachmentMsg represents context injected at prompt time by an @-mention.
+// Claude Code writes a synthetic tool call entry followed by a result entry;
+// the call carries Path, the result carries Content.
+type AttachmentMsg struct {
+	Timestamp time.Time
+	ToolName  string // "Read" for files, "Bash" for directory listings
+	Path      string // call only: file path or command
+	Content   string // result only: injected text
+	IsResult  bool
+}
+
+func (AttachmentMsg) classifiedMsg() {}
+
+// QueuedMsg represents a prompt the user sent while Claude was working,
+// delivered into the running turn rather than starting a new one.
+type QueuedMsg struct {
+	Timestamp time.Time
+	Text      string
+}
+
+func (QueuedMsg) classifiedMsg() {}
+
+// HookMsg represents output from a Claude Code hook (type=system entries).
+// BuildChunks attaches it to the tool call it wrapped when ToolID matches.
+type HookMsg struct {
+	Timestamp time.Time
+	Event     string // "PreToolUse", "PostToolUse", "Stop", ...
+	Matcher   string // "Bash" from "PreToolUse:Bash"; empty when absent
+	Command   string // hook command from the [...] segment
+	Output    string // remaining text: status line plus stdout/stderr
+	IsError   bool   // hook failed or blocked the tool
+	ToolID    string // tool_use ID the hook ran for; empty for non-tool hooks
+}
+
+func (HookMsg) classifiedMsg() {}
+
 // TeammateMsg represents a message from a teammate agent.
 // Folded into the AI turn during chunk building rather than starting a new user chunk.
 type TeammateMsg struct {
@@ -88,23 +169,43 @@ type TeammateMsg struct {
 
 func (TeammateMsg) classifiedMsg() {}
 
-// CompactMsg represents a context compression boundary (summary entries).
-// Displayed as a visual divider in the conversation timeline.
+// CompactMsg represents a context compression boundary: a summary entry, a
+// compact_boundary system entry with its token metrics, or the compaction
+// summary that follows one. Displayed as a visual divider in the
+// conversation timeline.
 type CompactMsg struct {
-	Timestamp time.Time
-	Text      string
+	Timestamp  time.Time
+	Text       string
+	Trigger    string // "auto" or "manual"; empty when not recorded
+	PreTokens  int    // context tokens before compaction; 0 when not recorded
+	PostTokens int    // context tokens after compaction; 0 when not recorded
+	Summary    string // the summary the session continues from
 }
 
 func (CompactMsg) classifiedMsg() {}
 
+// ErrorMsg represents a failed API request: an api_error entry with the
+// retry it scheduled, or the final error once retries ran out.
+type ErrorMsg struct {
+	Timestamp    time.Time
+	Kind         string // "overloaded_error", "rate_limit_error", ...; empty if not recorded
+	Status       int    // HTTP status; 0 if not recorded
+	Message      string // error message from the API
+	RetryAttempt int    // 1-based retry this error scheduled; 0 for the final error
+	MaxRetries   int
+	RetryInMs    int64
+	Final        bool // no retry follows: the request failed
+}
+
+func (ErrorMsg) classifiedMsg() {}
+
 // --- Hard noise detection ---
 
 // noiseEntryTypes are entry types that never produce visible messages.
-// Note: "summary" is handled separately as CompactMsg, not noise.
+// Note: "summary" is handled separately as CompactMsg, and "system" and
+// "queue-operation" by their subtype or operation, not noise.
 var noiseEntryTypes = map[string]bool{
-	"system":                true,
 	"file-history-snapshot": true,
-	"queue-operation":       true,
 	"progress":              true,
 }
 
@@ -138,6 +239,24 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 
 	ts := parseTimestamp(e.Timestamp)
 
+	// System entries are classified by subtype (hook output by its text),
+	// and queue operations by what they did to the queue. Whatever those
+	// don't recognize is noise.
+	switch e.Type {
+	case "system":
+		return classifySystemEntry(e, ts)
+	case "queue-operation":
+		return classifyQueueOperation(e, ts)
+	}
+
+	// The final API error is a synthetic assistant message; recover it
+	// before synthetic messages are dropped as noise.
+	if e.Type == "assistant" && e.IsAPIErrorMessage {
+		msg := parseAPIErrorText(ExtractText(e.Message.Content))
+		msg.Timestamp = ts
+		return msg, true
+	}
+
 	// 1. Hard noise: structural metadata types.
 	if noiseEntryTypes[e.Type] {
 		return nil, false
@@ -152,6 +271,20 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 		}, true
 	}
 
+	// The compaction summary is injected as a user message; it belongs to
+	// the boundary before it, not the conversation.
+	if e.Type == "user" && e.IsCompactSummary {
+		return CompactMsg{
+			Timestamp: ts,
+			Summary:   strings.TrimSpace(ExtractText(e.Message.Content)),
+		}, true
+	}
+
+	if q, ok := parseQueuedPrompt(e); ok {
+		q.Timestamp = ts
+		return q, true
+	}
+
 	// Hard noise: synthetic assistant messages.
 	if e.Type == "assistant" && e.Message.Model == "<synthetic>" {
 		return nil, false
@@ -193,10 +326,19 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 	// 2. System message: user entry starting with command output tag.
 	if e.Type == "user" {
 		trimmed := strings.TrimSpace(contentStr)
+
+		// Prompt-time attachments (@file). Checked regardless of isMeta since
+		// older sessions omit the flag on these entries.
+		if att, ok := parseAttachment(trimmed); ok {
+			att.Timestamp = ts
+			return att, true
+		}
 		if strings.HasPrefix(trimmed, localCommandStdoutTag) || strings.HasPrefix(trimmed, localCommandStderrTag) {
 			return SystemMsg{
-				Timestamp: ts,
-				Output:    ExtractCommandOutput(contentStr),
+				Timestamp:       ts,
+				Output:          ExtractCommandOutput(contentStr),
+				IsError:         strings.HasPrefix(trimmed, localCommandStderrTag),
+				IsCommandOutput: true,
 			}, true
 		}
 
@@ -215,14 +357,19 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 
 		// Background task notifications.
 		if strings.HasPrefix(trimmed, taskNotificationTag) {
-			status := ""
+			status, taskID := "", ""
 			if m := reTaskNotifyStatus.FindStringSubmatch(contentStr); m != nil {
 				status = strings.TrimSpace(m[1])
 			}
+			if m := reTaskNotifyID.FindStringSubmatch(contentStr); m != nil {
+				taskID = strings.TrimSpace(m[1])
+			}
 			return SystemMsg{
-				Timestamp: ts,
-				Output:    extractTaskNotification(contentStr),
-				IsError:   status == "killed",
+				Timestamp:  ts,
+				Output:     extractTaskNotification(contentStr),
+				IsError:    status == "killed",
+				TaskID:     taskID,
+				TaskStatus: status,
 			}, true
 		}
 	}
@@ -231,6 +378,13 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 	if e.Type == "user" && !e.IsMeta {
 		trimmed := strings.TrimSpace(contentStr)
 
+		// Slash command invocations get their own type so the TUI can render
+		// them as a command line rather than a chat bubble.
+		if cmd, ok := parseCommandInvocation(trimmed); ok {
+			cmd.Timestamp = ts
+			return cmd, true
+		}
+
 		// Exclude messages starting with system output tags.
 		excluded := false
 		for _, tag := range systemOutputTags {
@@ -242,9 +396,11 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 
 		if !excluded && hasUserContent(e.Message.Content, contentStr) {
 			return UserMsg{
+				UUID:           e.UUID,
 				Timestamp:      ts,
 				Text:           SanitizeContent(contentStr),
 				PermissionMode: e.PermissionMode,
+				Settings:       promptSettings(e),
 			}, true
 		}
 	}
@@ -257,6 +413,7 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 			stopReason = *e.Message.StopReason
 		}
 		return AIMsg{
+			UUID:          e.UUID,
 			Timestamp:     ts,
 			Model:         e.Message.Model,
 			Text:          SanitizeContent(ExtractText(e.Message.Content)),
@@ -270,6 +427,10 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 				CacheCreationTokens: e.Message.Usage.CacheCreationInputTokens,
 			},
 			StopReason: stopReason,
+			Settings: RequestSettings{
+				ServiceTier: e.Message.Usage.ServiceTier,
+				Version:     e.Version,
+			},
 		}, true
 	}
 
@@ -279,7 +440,10 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 	// if the content has tool_result blocks it extracts them; otherwise it returns
 	// a text fallback that mergeAIBuffer silently ignores.
 	blocks := extractMetaBlocks(e.Message.Content, contentStr)
+	attachPatch(blocks, e.ToolUseResult)
+	attachExecTime(blocks, e.ToolUseResult)
 	return AIMsg{
+		UUID:      e.UUID,
 		Timestamp: ts,
 		Text:      contentStr,
 		IsMeta:    true,
@@ -287,6 +451,195 @@ func Classify(e Entry) (ClassifiedMsg, bool) {
 	}, true
 }
 
+// promptSettings reads the thinking configuration recorded on a prompt.
+func promptSettings(e Entry) RequestSettings {
+	s := RequestSettings{Version: e.Version}
+	if t := e.ThinkingMetadata; t != nil {
+		s.ThinkingLevel = t.Level
+		s.ThinkingDisabled = t.Disabled
+		s.ThinkingBudget = t.MaxThinkingTokens
+	}
+	return s
+}
+
+// parseCommandInvocation extracts the command name and arguments from
+// <command-name>/<command-args> markup. Only content that starts with a
+// command tag qualifies; prose that merely mentions the tags does not.
+func parseCommandInvocation(s string) (CommandMsg, bool) {
+	if !strings.HasPrefix(s, "<command-name>") && !strings.HasPrefix(s, "<command-message>") {
+		return CommandMsg{}, false
+	}
+	m := reCommandName.FindStringSubmatch(s)
+	if m == nil {
+		return CommandMsg{}, false
+	}
+	cmd := CommandMsg{Name: "/" + strings.TrimSpace(m[1])}
+	if am := reCommandArgs.FindStringSubmatch(s); am != nil {
+		cmd.Args = strings.TrimSpace(am[1])
+	}
+	return cmd, true
+}
+
+// parseAttachment recognizes the synthetic call/result pair that an @-mention
+// injects after the user prompt.
+func parseAttachment(s string) (AttachmentMsg, bool) {
+	if m := reAttachmentCall.FindStringSubmatch(s); m != nil {
+		var input struct {
+			FilePath string `json:"file_path"`
+			Path     string `json:"path"`
+			Command  string `json:"command"`
+		}
+		if json.Unmarshal([]byte(m[2]), &input) != nil {
+			return AttachmentMsg{}, false
+		}
+		path := input.FilePath
+		if path == "" {
+			path = input.Path
+		}
+		if path == "" {
+			path = input.Command
+		}
+		return AttachmentMsg{ToolName: m[1], Path: path}, true
+	}
+	if m := reAttachmentResult.FindStringSubmatch(s); m != nil {
+		return AttachmentMsg{ToolName: m[1], Content: m[2], IsResult: true}, true
+	}
+	return AttachmentMsg{}, false
+}
+
+// parseHookOutput recognizes a system entry as hook output by its leading
+// "{Event}:{Matcher}", "{Event} [{command}]", or "{Event}:" prefix. ANSI
+// styling is stripped before matching.
+func parseHookOutput(e Entry) (HookMsg, bool) {
+	text := strings.TrimSpace(reANSI.ReplaceAllString(ExtractText(e.Content), ""))
+	m := reHookOutput.FindStringSubmatch(text)
+	if m == nil || strings.TrimSpace(m[2]) == "" {
+		return HookMsg{}, false
+	}
+	output := strings.TrimSpace(m[5])
+	return HookMsg{
+		Event:   m[1],
+		Matcher: m[3],
+		Command: strings.TrimSpace(m[4]),
+		Output:  output,
+		IsError: e.Level == "error" || e.Level == "warning" || reHookFailed.MatchString(output),
+		ToolID:  e.ToolUseID,
+	}, true
+}
+
+// apiErrorBody is the error payload shape shared by api_error entries
+// ({"status":529,"error":{"error":{"type":...,"message":...}}}) and the JSON
+// embedded in final error text ({"type":"error","error":{...}}).
+type apiErrorBody struct {
+	Status  int    `json:"status"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Error   *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+		Error   *struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"error"`
+}
+
+// kindAndMessage digs the most specific error type and message out of the
+// nested payload.
+func (b apiErrorBody) kindAndMessage() (kind, message string) {
+	kind, message = b.Type, b.Message
+	if b.Error != nil {
+		if b.Error.Type != "" {
+			kind = b.Error.Type
+		}
+		if b.Error.Message != "" {
+			message = b.Error.Message
+		}
+		if inner := b.Error.Error; inner != nil {
+			if inner.Type != "" {
+				kind = inner.Type
+			}
+			if inner.Message != "" {
+				message = inner.Message
+			}
+		}
+	}
+	if kind == "error" {
+		kind = ""
+	}
+	return kind, message
+}
+
+// parseAPIError reads an api_error system entry. The error payload is
+// missing on connection failures; the entry's content text stands in.
+func parseAPIError(e Entry) ErrorMsg {
+	msg := ErrorMsg{
+		RetryAttempt: e.RetryAttempt,
+		MaxRetries:   e.MaxRetries,
+		RetryInMs:    int64(e.RetryInMs),
+	}
+	var body apiErrorBody
+	if json.Unmarshal(e.Error, &body) == nil {
+		msg.Status = body.Status
+		msg.Kind, msg.Message = body.kindAndMessage()
+	}
+	if msg.Message == "" {
+		msg.Message = strings.TrimSpace(ExtractText(e.Content))
+	}
+	return msg
+}
+
+// parseQueuedPrompt recognizes a prompt delivered mid-turn: a queued_command
+// attachment entry, or the system reminder older versions wrapped it in.
+func parseQueuedPrompt(e Entry) (QueuedMsg, bool) {
+	switch {
+	case e.Type == "attachment" && e.Attachment != nil && e.Attachment.Type == "queued_command":
+		text := strings.TrimSpace(SanitizeContent(ExtractText(e.Attachment.Prompt)))
+		return QueuedMsg{Text: text}, text != ""
+	case e.Type == "user":
+		m := reQueuedReminder.FindStringSubmatch(strings.TrimSpace(ExtractText(e.Message.Content)))
+		if m == nil {
+			return QueuedMsg{}, false
+		}
+		text := strings.TrimSpace(m[1])
+		return QueuedMsg{Text: text}, text != ""
+	}
+	return QueuedMsg{}, false
+}
+
+// parseCompactBoundary reads a compact_boundary system entry: its title
+// ("Conversation compacted") and token metrics.
+func parseCompactBoundary(e Entry, ts time.Time) CompactMsg {
+	msg := CompactMsg{
+		Timestamp: ts,
+		Text:      strings.TrimSpace(ExtractText(e.Content)),
+	}
+	if md := e.CompactMetadata; md != nil {
+		msg.Trigger = md.Trigger
+		msg.PreTokens = md.PreTokens
+		msg.PostTokens = md.PostTokens
+	}
+	return msg
+}
+
+// parseAPIErrorText reads the final error text of a failed request:
+// "API Error: 529 {json}" or "API Error: Request timed out.".
+func parseAPIErrorText(text string) ErrorMsg {
+	msg := ErrorMsg{Final: true, Message: strings.TrimSpace(text)}
+	m := reAPIErrorText.FindStringSubmatch(msg.Message)
+	if m == nil {
+		return msg
+	}
+	msg.Status, _ = strconv.Atoi(m[1])
+	rest := strings.TrimSpace(m[2])
+	msg.Message = rest
+	var body apiErrorBody
+	if strings.HasPrefix(rest, "{") && json.Unmarshal([]byte(rest), &body) == nil {
+		msg.Kind, msg.Message = body.kindAndMessage()
+	}
+	return msg
+}
+
 // extractTeammateID extracts the teammate_id attribute from a teammate-message XML tag.
 func extractTeammateID(s string) string {
 	m := teammateIDRe.FindStringSubmatch(s)
@@ -410,8 +763,9 @@ func extractAssistantDetails(raw json.RawMessage) (int, []ToolCall, []ContentBlo
 			})
 		case "text":
 			contentBlocks = append(contentBlocks, ContentBlock{
-				Type: "text",
-				Text: b.Text,
+				Type:       "text",
+				Text:       b.Text,
+				References: parseCitations(b.Citations),
 			})
 		case "tool_use":
 			if b.ID != "" && b.Name != "" {
@@ -471,6 +825,49 @@ func extractMetaBlocks(raw json.RawMessage, textFallback string) []ContentBlock
 	return contentBlocks
 }
 
+// attachPatch hands the file patch recorded in an entry's toolUseResult to
+// its tool result. The entry carries one toolUseResult, so it is only
+// attributed when there is a single tool_result block.
+func attachPatch(blocks []ContentBlock, toolUseResult json.RawMessage) {
+	if len(blocks) != 1 || blocks[0].Type != "tool_result" {
+		return
+	}
+	blocks[0].Patch = parseFilePatch(toolUseResult)
+}
+
+// attachExecTime hands the run time recorded in an entry's toolUseResult to
+// its tool result, under the same single-result rule as attachPatch.
+func attachExecTime(blocks []ContentBlock, toolUseResult json.RawMessage) {
+	if len(blocks) != 1 || blocks[0].Type != "tool_result" {
+		return
+	}
+	blocks[0].ExecMs = parseExecTime(toolUseResult)
+}
+
+// parseExecTime reads how long a tool ran from its toolUseResult: WebFetch
+// records durationMs and WebSearch durationSeconds. Grep and Glob record
+// durationMs too, which pairing drops since they never prompt. Returns 0
+// for tools that record none, such as Bash and the edit tools.
+func parseExecTime(raw json.RawMessage) int64 {
+	if len(raw) == 0 || raw[0] != '{' {
+		return 0
+	}
+	var r struct {
+		DurationMs      float64 `json:"durationMs"`
+		DurationSeconds float64 `json:"durationSeconds"`
+	}
+	if json.Unmarshal(raw, &r) != nil {
+		return 0
+	}
+	switch {
+	case r.DurationMs > 0:
+		return int64(r.DurationMs)
+	case r.DurationSeconds > 0:
+		return int64(r.DurationSeconds * 1000)
+	}
+	return 0
+}
+
 // stringifyContent converts tool_result content (string or array of text blocks) to a string.
 func stringifyContent(raw json.RawMessage) string {
 	if len(raw) == 0 {
@@ -125,6 +125,134 @@ func TestClassify_SystemMessage(t *testing.T) {
 	}
 }
 
+func TestClassify_SlashCommandProducesCommandMsg(t *testing.T) {
+	content := json.RawMessage(`"<command-message>review</command-message>\n<command-name>/review</command-name>\n<command-args>src/</command-args>"`)
+	e := makeEntry("user", "c1", "2025-01-15T10:00:06.000Z", content)
+
+	msg, ok := parser.Classify(e)
+	if !ok {
+		t.Fatal("expected Classify to succeed for slash command")
+	}
+	cmd, isCmd := msg.(parser.CommandMsg)
+	if !isCmd {
+		t.Fatalf("expected CommandMsg, got %T", msg)
+	}
+	if cmd.Name != "/review" {
+		t.Errorf("Name = %q, want %q", cmd.Name, "/review")
+	}
+	if cmd.Args != "src/" {
+		t.Errorf("Args = %q, want %q", cmd.Args, "src/")
+	}
+}
+
+func TestClassify_LocalCommandStderrIsError(t *testing.T) {
+	content := json.RawMessage(`"<local-command-stderr>unknown model</local-command-stderr>"`)
+	e := makeEntry("user", "s2", "2025-01-15T10:00:06.000Z", content)
+
+	msg, _ := parser.Classify(e)
+	sys, isSys := msg.(parser.SystemMsg)
+	if !isSys {
+		t.Fatalf("expected SystemMsg, got %T", msg)
+	}
+	if !sys.IsError {
+		t.Error("IsError should be true for local-command-stderr")
+	}
+	if !sys.IsCommandOutput {
+		t.Error("IsCommandOutput should be true for local-command-stderr")
+	}
+}
+
+func TestClassify_HookOutputProducesHookMsg(t *testing.T) {
+	e := makeEntry("system", "h1", "2025-01-15T10:00:06.000Z", nil)
+	e.Content = json.RawMessage(`"\u001b[1mPreToolUse:Bash\u001b[22m [~/hooks/guard.sh] failed with non-blocking status code 1: rm is not allowed"`)
+	e.Level = "warning"
+	e.ToolUseID = "toolu_01"
+
+	msg, ok := parser.Classify(e)
+	if !ok {
+		t.Fatal("expected Classify to succeed for hook output")
+	}
+	hook, isHook := msg.(parser.HookMsg)
+	if !isHook {
+		t.Fatalf("expected HookMsg, got %T", msg)
+	}
+	if hook.Event != "PreToolUse" || hook.Matcher != "Bash" {
+		t.Errorf("Event/Matcher = %q/%q, want PreToolUse/Bash", hook.Event, hook.Matcher)
+	}
+	if hook.Command != "~/hooks/guard.sh" {
+		t.Errorf("Command = %q, want %q", hook.Command, "~/hooks/guard.sh")
+	}
+	want := "failed with non-blocking status code 1: rm is not allowed"
+	if hook.Output != want {
+		t.Errorf("Output = %q, want %q", hook.Output, want)
+	}
+	if !hook.IsError {
+		t.Error("IsError should be true for a failed hook")
+	}
+	if hook.ToolID != "toolu_01" {
+		t.Errorf("ToolID = %q, want %q", hook.ToolID, "toolu_01")
+	}
+}
+
+func TestClassify_ProseIsNotHookOutput(t *testing.T) {
+	for _, text := range []string{
+		"Stopped the dev server before rebuilding",
+		"Notifications are now enabled",
+		"Stop hook feedback follows",
+	} {
+		e := makeEntry("system", "h1", "2025-01-15T10:00:06.000Z", nil)
+		e.Content = json.RawMessage(`"` + text + `"`)
+		if msg, ok := parser.Classify(e); ok {
+			if _, isHook := msg.(parser.HookMsg); isHook {
+				t.Errorf("%q classified as hook output", text)
+			}
+		}
+	}
+	e := makeEntry("system", "h1", "2025-01-15T10:00:06.000Z", nil)
+	e.Content = json.RawMessage(`"Stop [~/hooks/notify.sh] completed successfully"`)
+	if msg, _ := parser.Classify(e); msg == nil {
+		t.Fatal("expected a message for Stop hook output")
+	} else if hook, ok := msg.(parser.HookMsg); !ok || hook.Event != "Stop" {
+		t.Errorf("got %#v, want a Stop HookMsg", msg)
+	}
+}
+
+func TestClassify_NonHookSystemEntryIsNoise(t *testing.T) {
+	e := makeEntry("system", "h2", "2025-01-15T10:00:06.000Z", nil)
+	e.Content = json.RawMessage(`"Conversation compacted"`)
+	if _, ok := parser.Classify(e); ok {
+		t.Error("expected non-hook system entry to be filtered")
+	}
+}
+
+func TestClassify_AttachmentCallAndResult(t *testing.T) {
+	call := makeEntry("user", "a1", "2025-01-15T10:00:01.000Z",
+		json.RawMessage(`[{"type":"text","text":"Called the Read tool with the following input: {\"file_path\":\"/repo/main.go\"}"}]`), withMeta())
+	msg, ok := parser.Classify(call)
+	if !ok {
+		t.Fatal("expected Classify to succeed for attachment call")
+	}
+	att, isAtt := msg.(parser.AttachmentMsg)
+	if !isAtt {
+		t.Fatalf("expected AttachmentMsg, got %T", msg)
+	}
+	if att.Path != "/repo/main.go" || att.ToolName != "Read" || att.IsResult {
+		t.Errorf("call = %+v, want Read /repo/main.go", att)
+	}
+
+	// Older sessions omit isMeta; the result must still not become a UserMsg.
+	result := makeEntry("user", "a2", "2025-01-15T10:00:01.000Z",
+		json.RawMessage(`"Result of calling the Read tool: package main"`))
+	msg, _ = parser.Classify(result)
+	att, isAtt = msg.(parser.AttachmentMsg)
+	if !isAtt {
+		t.Fatalf("expected AttachmentMsg, got %T", msg)
+	}
+	if !att.IsResult || att.Content != "package main" {
+		t.Errorf("result = %+v, want IsResult with content", att)
+	}
+}
+
 func TestClassify_SidechainFiltered(t *testing.T) {
 	e := makeEntry("assistant", "sc1", "2025-01-15T10:00:00Z",
 		json.RawMessage(`[{"type":"text","text":"sidechain"}]`),
@@ -627,6 +755,48 @@ func TestClassify_TeammateRealMessageNotFiltered(t *testing.T) {
 	}
 }
 
+// --- QueuedMsg classification tests ---
+
+func TestClassify_QueuedPrompt(t *testing.T) {
+	attachment := makeEntry("attachment", "q1", "2025-01-15T10:00:00Z", nil, func(e *parser.Entry) {
+		e.Attachment = &struct {
+			Type   string          `json:"type"`
+			Prompt json.RawMessage `json:"prompt"`
+		}{Type: "queued_command", Prompt: json.RawMessage(`"also update the README"`)}
+	})
+	reminder := makeEntry("user", "q2", "2025-01-15T10:00:00Z", json.RawMessage(
+		`[{"type":"text","text":"<system-reminder>\nThe user sent the following message:\nuse tabs\n\nPlease address this message and continue with your tasks.\n</system-reminder>"}]`), withMeta())
+
+	for _, tt := range []struct {
+		name string
+		e    parser.Entry
+		want string
+	}{
+		{"queued_command attachment", attachment, "also update the README"},
+		{"system reminder", reminder, "use tabs"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, ok := parser.Classify(tt.e)
+			if !ok {
+				t.Fatal("queued prompt was filtered")
+			}
+			q, is := msg.(parser.QueuedMsg)
+			if !is {
+				t.Fatalf("got %T, want QueuedMsg", msg)
+			}
+			if q.Text != tt.want || q.Timestamp.IsZero() {
+				t.Errorf("QueuedMsg = %+v, want Text %q with a timestamp", q, tt.want)
+			}
+		})
+	}
+
+	other := makeEntry("user", "r1", "2025-01-15T10:00:00Z",
+		json.RawMessage(`"<system-reminder>Todo list is empty</system-reminder>"`), withMeta())
+	if _, ok := parser.Classify(other); ok {
+		t.Error("other system reminders should stay noise")
+	}
+}
+
 // --- CompactMsg classification tests ---
 
 func TestClassify_SummaryProducesCompactMsg(t *testing.T) {
@@ -852,3 +1022,164 @@ func TestClassify_BashInputStrippedInUserMsg(t *testing.T) {
 		t.Errorf("Text = %q, want %q (bash-input tags should be stripped)", usr.Text, "git push")
 	}
 }
+
+func TestClassify_APIErrors(t *testing.T) {
+	classify := func(line string) parser.ErrorMsg {
+		t.Helper()
+		e, ok := parser.ParseEntry([]byte(line))
+		if !ok {
+			t.Fatalf("ParseEntry failed: %s", line)
+		}
+		msg, ok := parser.Classify(e)
+		if !ok {
+			t.Fatalf("Classify dropped %s", line)
+		}
+		em, isErr := msg.(parser.ErrorMsg)
+		if !isErr {
+			t.Fatalf("expected ErrorMsg, got %T", msg)
+		}
+		return em
+	}
+
+	t.Run("retry entry", func(t *testing.T) {
+		got := classify(`{"type":"system","subtype":"api_error","level":"error","uuid":"s1","timestamp":"2025-01-15T10:00:00Z",` +
+			`"error":{"status":529,"headers":{},"error":{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}},` +
+			`"retryInMs":1120.5,"retryAttempt":2,"maxRetries":10}`)
+		want := parser.ErrorMsg{Kind: "overloaded_error", Status: 529, Message: "Overloaded", RetryAttempt: 2, MaxRetries: 10, RetryInMs: 1120}
+		got.Timestamp = time.Time{}
+		if got != want {
+			t.Errorf("ErrorMsg = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("connection error without payload", func(t *testing.T) {
+		got := classify(`{"type":"system","subtype":"api_error","level":"error","uuid":"s2","timestamp":"2025-01-15T10:00:00Z",` +
+			`"content":"Connection error.","retryAttempt":1,"maxRetries":10}`)
+		if got.Message != "Connection error." || got.Status != 0 || got.Final {
+			t.Errorf("ErrorMsg = %+v, want connection error retry", got)
+		}
+	})
+
+	t.Run("final synthetic message", func(t *testing.T) {
+		got := classify(`{"type":"assistant","uuid":"a1","timestamp":"2025-01-15T10:00:00Z","isApiErrorMessage":true,` +
+			`"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text",` +
+			`"text":"API Error: 429 {\"type\":\"error\",\"error\":{\"type\":\"rate_limit_error\",\"message\":\"Rate limited\"}}"}]}}`)
+		if !got.Final || got.Status != 429 || got.Kind != "rate_limit_error" || got.Message != "Rate limited" {
+			t.Errorf("ErrorMsg = %+v, want final 429 rate_limit_error", got)
+		}
+	})
+
+	t.Run("final plain text", func(t *testing.T) {
+		got := classify(`{"type":"assistant","uuid":"a2","timestamp":"2025-01-15T10:00:00Z","isApiErrorMessage":true,` +
+			`"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: Request timed out."}]}}`)
+		if !got.Final || got.Status != 0 || got.Message != "Request timed out." {
+			t.Errorf("ErrorMsg = %+v, want final timeout", got)
+		}
+	})
+}
+
+func TestClassify_TypedSystemEntries(t *testing.T) {
+	classify := func(line string) (parser.ClassifiedMsg, bool) {
+		t.Helper()
+		e, ok := parser.ParseEntry([]byte(line))
+		if !ok {
+			t.Fatalf("ParseEntry failed: %s", line)
+		}
+		return parser.Classify(e)
+	}
+	const ts = `"uuid":"s1","timestamp":"2025-01-15T10:00:00Z"`
+	cases := []struct {
+		name string
+		line string
+		want parser.SystemMsg
+	}{
+		{"warning status",
+			`{"type":"system","subtype":"informational","level":"warning",` + ts + `,"content":"\u001b[33mContext low\u001b[39m"}`,
+			parser.SystemMsg{Kind: parser.SystemStatus, Level: "warning", Output: "Context low"}},
+		{"error status",
+			`{"type":"system","subtype":"informational","level":"error",` + ts + `,"content":"Hook timed out"}`,
+			parser.SystemMsg{Kind: parser.SystemStatus, Level: "error", Output: "Hook timed out", IsError: true}},
+		{"output style field",
+			`{"type":"system","subtype":"output_style","outputStyle":"Explanatory",` + ts + `,"content":"ignored"}`,
+			parser.SystemMsg{Kind: parser.SystemOutputStyle, Output: "Explanatory"}},
+		{"output style content",
+			`{"type":"system","subtype":"output_style",` + ts + `,"content":" Learning "}`,
+			parser.SystemMsg{Kind: parser.SystemOutputStyle, Output: "Learning"}},
+		{"local command output",
+			`{"type":"system","subtype":"local_command",` + ts + `,"content":"<local-command-stdout>Set model to sonnet</local-command-stdout>"}`,
+			parser.SystemMsg{Output: "Set model to sonnet", IsCommandOutput: true}},
+		{"queued prompt removed",
+			`{"type":"queue-operation","operation":"remove","timestamp":"2025-01-15T10:00:00Z","content":"also\n  add a test"}`,
+			parser.SystemMsg{Kind: parser.SystemQueue, Output: "Queued prompt removed: also add a test"}},
+		{"queue popped",
+			`{"type":"queue-operation","operation":"popAll","timestamp":"2025-01-15T10:00:00Z"}`,
+			parser.SystemMsg{Kind: parser.SystemQueue, Output: "Queued prompts taken back to edit"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, ok := classify(tc.line)
+			if !ok {
+				t.Fatal("Classify dropped the entry")
+			}
+			got, isSys := msg.(parser.SystemMsg)
+			if !isSys {
+				t.Fatalf("expected SystemMsg, got %T", msg)
+			}
+			got.Timestamp = time.Time{}
+			if got != tc.want {
+				t.Errorf("SystemMsg = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("local command invocation", func(t *testing.T) {
+		msg, _ := classify(`{"type":"system","subtype":"local_command",` + ts +
+			`,"content":"<command-name>/model</command-name><command-args>sonnet</command-args>"}`)
+		if cmd, ok := msg.(parser.CommandMsg); !ok || cmd.Name != "/model" || cmd.Args != "sonnet" {
+			t.Errorf("got %#v, want CommandMsg /model sonnet", msg)
+		}
+	})
+
+	for _, line := range []string{
+		`{"type":"system","subtype":"turn_duration","durationMs":1200,` + ts + `}`,
+		`{"type":"system","subtype":"informational",` + ts + `,"content":"  "}`,
+		`{"type":"queue-operation","operation":"enqueue","timestamp":"2025-01-15T10:00:00Z","content":"later"}`,
+		`{"type":"queue-operation","operation":"dequeue","timestamp":"2025-01-15T10:00:00Z"}`,
+	} {
+		if msg, ok := classify(line); ok {
+			t.Errorf("%s classified as %#v, want noise", line, msg)
+		}
+	}
+}
+
+func TestClassify_ToolResultExecTime(t *testing.T) {
+	execMs := func(toolUseResult string) int64 {
+		t.Helper()
+		e := makeEntry("user", "u1", "2025-01-15T10:00:00.000Z",
+			json.RawMessage(`[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]`),
+			func(e *parser.Entry) { e.ToolUseResult = json.RawMessage(toolUseResult) })
+		msg, ok := parser.Classify(e)
+		ai, isAI := msg.(parser.AIMsg)
+		if !ok || !isAI || len(ai.Blocks) != 1 {
+			t.Fatalf("got %#v, want an AIMsg with one block", msg)
+		}
+		return ai.Blocks[0].ExecMs
+	}
+
+	tests := []struct {
+		name   string
+		result string
+		want   int64
+	}{
+		{"WebFetch", `{"bytes":1024,"code":200,"result":"page","durationMs":42}`, 42},
+		{"Task", `{"status":"completed","totalDurationMs":61000,"totalTokens":900}`, 0},
+		{"WebSearch", `{"query":"go","results":[],"durationSeconds":2.5}`, 2500},
+		{"Bash", `{"stdout":"ok","stderr":"","interrupted":false}`, 0},
+		{"string", `"Error: file not found"`, 0},
+	}
+	for _, tt := range tests {
+		if got := execMs(tt.result); got != tt.want {
+			t.Errorf("%s: ExecMs = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+)
+
+// Continuation is how one session file relates to another that it may
+// carry on: resuming a session in another terminal writes a new file that
+// starts with copies of the original's entries (same uuids) or links its
+// first entry to the original's last, then goes on without it.
+type Continuation struct {
+	Path    string // the file that may carry on the original
+	Shared  int    // leading entries copied from the original
+	Linked  bool   // its first entry of its own has its parent in the original
+	New     int    // entries of its own, past the shared ones
+	BaseEnd int64  // offset in the original after the last entry it copied or links to
+	Skip    int64  // offset in it where its own entries start
+}
+
+// Continues reports whether the file carries on the original: it shares
+// or links to the original's entries and has written its own since.
+func (c Continuation) Continues() bool {
+	return (c.Shared > 0 || c.Linked) && c.New > 0
+}
+
+// continuationLine is the part of an entry FindContinuation reads.
+type continuationLine struct {
+	UUID              string `json:"uuid"`
+	ParentUUID        string `json:"parentUuid"`
+	LogicalParentUUID string `json:"logicalParentUuid"`
+	IsSidechain       bool   `json:"isSidechain"`
+}
+
+// scanEntries calls fn with each main-chain entry of the file at path from
+// offset from on, and the offset just past its line. It returns the offset
+// it read to: a trailing line still being written is left for next time.
+func scanEntries(path string, from int64, fn func(e continuationLine, end int64)) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return from, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(from, io.SeekStart); err != nil {
+		return from, err
+	}
+	lr := newLineReader(f)
+	for {
+		line, ok := lr.next()
+		if !ok {
+			break
+		}
+		var e continuationLine
+		if json.Unmarshal([]byte(line), &e) != nil || e.UUID == "" || e.IsSidechain {
+			continue
+		}
+		fn(e, from+lr.BytesRead())
+	}
+	return from + lr.BytesRead(), lr.Err()
+}
+
+// EntryEnds is where each main-chain entry of a session file ends, by
+// uuid. It's kept up to date as the file grows, so the candidates for its
+// continuation are compared with it without reading it again each time.
+// It isn't safe for concurrent use.
+type EntryEnds struct {
+	path   string
+	offset int64 // read up to here
+	ends   map[string]int64
+}
+
+// NewEntryEnds returns the entry ends of the session at path, read on the
+// first Update.
+func NewEntryEnds(path string) *EntryEnds {
+	return &EntryEnds{path: path, ends: make(map[string]int64)}
+}
+
+// Update reads the entries written since the last call. A file shorter
+// than what was read has been rewritten, and is read again from the start.
+func (x *EntryEnds) Update() error {
+	if info, err := os.Stat(x.path); err != nil {
+		return err
+	} else if info.Size() < x.offset {
+		x.offset = 0
+		clear(x.ends)
+	}
+	offset, err := scanEntries(x.path, x.offset, func(e continuationLine, end int64) {
+		x.ends[e.UUID] = end
+	})
+	x.offset = offset
+	return err
+}
+
+// FindContinuation compares candidate with the session at original: how
+// many entries it copied, whether it links to it, and how many of its own
+// it has. Continues on the result says whether it carries the session on.
+func FindContinuation(original, candidate string) (Continuation, error) {
+	x := NewEntryEnds(original)
+	if err := x.Update(); err != nil {
+		return Continuation{}, err
+	}
+	return x.Continuation(candidate)
+}
+
+// Continuation is FindContinuation against the entries read so far.
+func (x *EntryEnds) Continuation(candidate string) (Continuation, error) {
+	c := Continuation{Path: candidate}
+	past := false // past the copied prefix
+	_, err := scanEntries(candidate, 0, func(e continuationLine, end int64) {
+		baseEnd, shared := x.ends[e.UUID]
+		switch {
+		case shared && !past:
+			c.Shared++
+			c.Skip = end
+			c.BaseEnd = max(c.BaseEnd, baseEnd)
+		case shared:
+			// Copied again after its own entries; neither prefix nor new.
+		default:
+			if !past && c.Shared == 0 {
+				parent := e.ParentUUID
+				if parent == "" {
+					parent = e.LogicalParentUUID
+				}
+				if end, ok := x.ends[parent]; ok {
+					c.Linked, c.BaseEnd = true, end
+				}
+			}
+			past = true
+			c.New++
+		}
+	})
+	return c, err
+}
+
+// MergedSource reads a session and its continuation as one transcript: the
+// original up to where the continuation took over (BaseEnd), then the
+// continuation's own entries (from Skip). Offsets count through both, so
+// tailing the merged transcript tails the continuation.
+type MergedSource struct {
+	Base    string // the original session file
+	BaseEnd int64
+	Next    string // the continuation
+	Skip    int64
+}
+
+// NewMergedSource returns the merged transcript of original and c.
+func NewMergedSource(original string, c Continuation) MergedSource {
+	return MergedSource{Base: original, BaseEnd: c.BaseEnd, Next: c.Path, Skip: c.Skip}
+}
+
+// Name is the continuation's path: the file the merged transcript grows in.
+func (s MergedSource) Name() string { return s.Next }
+
+func (s MergedSource) Open(offset int64) (io.ReadCloser, error) {
+	if offset >= s.BaseEnd {
+		return FileSource(s.Next).Open(s.Skip + offset - s.BaseEnd)
+	}
+	base, err := FileSource(s.Base).Open(offset)
+	if err != nil {
+		return nil, err
+	}
+	next, err := FileSource(s.Next).Open(s.Skip)
+	if err != nil {
+		base.Close()
+		return nil, err
+	}
+	return mergedReader{
+		Reader:  io.MultiReader(io.LimitReader(base, s.BaseEnd-offset), next),
+		closers: []io.Closer{base, next},
+	}, nil
+}
+
+func (s MergedSource) Size() (int64, error) {
+	size, err := FileSource(s.Next).Size()
+	if err != nil {
+		return 0, err
+	}
+	return s.BaseEnd + max(size-s.Skip, 0), nil
+}
+
+// mergedReader closes both files of a merged read.
+type mergedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r mergedReader) Close() error {
+	var errs []error
+	for _, c := range r.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
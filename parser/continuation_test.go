@@ -0,0 +1,140 @@
+package parser_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestFindContinuation(t *testing.T) {
+	dir := t.TempDir()
+	history := []string{
+		forkPrompt("u1", "", "01", "set up"),
+		forkReply("a1", "u1", "02", "done"),
+	}
+	original := writeJSONL(t, dir, "original.jsonl", history...)
+	copied := writeJSONL(t, dir, "copied.jsonl", append(append([]string{`{"type":"summary","summary":"set up"}`}, history...),
+		forkPrompt("u2", "a1", "03", "carry on"),
+		forkReply("a2", "u2", "04", "carried on"),
+	)...)
+	linked := writeJSONL(t, dir, "linked.jsonl",
+		forkPrompt("u2", "a1", "03", "carry on"),
+	)
+	unrelated := writeJSONL(t, dir, "unrelated.jsonl",
+		forkPrompt("x1", "", "01", "something else"),
+	)
+
+	tests := []struct {
+		name      string
+		from, to  string
+		continues bool
+		shared    int
+		newCount  int
+	}{
+		{"copies the history", original, copied, true, 2, 2},
+		{"links to the last entry", original, linked, true, 0, 1},
+		{"another session", original, unrelated, false, 0, 1},
+		{"the original of a continuation", copied, original, false, 2, 0},
+	}
+	for _, tt := range tests {
+		c, err := parser.FindContinuation(tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if c.Continues() != tt.continues || c.Shared != tt.shared || c.New != tt.newCount {
+			t.Errorf("%s: %+v; want continues %v, %d shared, %d new", tt.name, c, tt.continues, tt.shared, tt.newCount)
+		}
+	}
+}
+
+func TestEntryEnds(t *testing.T) {
+	dir := t.TempDir()
+	original := writeJSONL(t, dir, "original.jsonl",
+		forkPrompt("u1", "", "01", "set up"),
+	)
+	next := writeJSONL(t, dir, "next.jsonl",
+		forkPrompt("u2", "a1", "03", "carry on"),
+	)
+	x := parser.NewEntryEnds(original)
+	if err := x.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := x.Continuation(next); err != nil || c.Continues() {
+		t.Fatalf("before the reply it links to: %+v, %v", c, err)
+	}
+
+	// The reply is read from where the last update stopped.
+	f, err := os.OpenFile(original, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(forkReply("a1", "u1", "02", "done") + "\n")
+	f.Close()
+	if err := x.Update(); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(original)
+	if c, err := x.Continuation(next); err != nil || !c.Linked || c.BaseEnd != info.Size() {
+		t.Errorf("after the reply: %+v, %v; want linked at %d", c, err, info.Size())
+	}
+
+	// A rewritten file is read again.
+	writeJSONL(t, dir, "original.jsonl", forkPrompt("x1", "", "01", "other"))
+	if err := x.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := x.Continuation(next); c.Linked {
+		t.Error("entries of the rewritten file's old contents should be forgotten")
+	}
+}
+
+func TestMergedSource(t *testing.T) {
+	dir := t.TempDir()
+	original := writeJSONL(t, dir, "original.jsonl",
+		forkPrompt("u1", "", "01", "set up"),
+		forkReply("a1", "u1", "02", "done"),
+	)
+	for _, next := range []string{
+		// A copy of the history then its own turn, and its own turn alone.
+		writeJSONL(t, dir, "copied.jsonl",
+			forkPrompt("u1", "", "01", "set up"),
+			forkReply("a1", "u1", "02", "done"),
+			forkPrompt("u2", "a1", "03", "carry on"),
+		),
+		writeJSONL(t, dir, "linked.jsonl",
+			forkPrompt("u2", "a1", "03", "carry on"),
+		),
+	} {
+		c, err := parser.FindContinuation(original, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := parser.NewMergedSource(original, c)
+		msgs, _, end, err := parser.ReadSourceIncrementalOffsets(src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != 3 {
+			t.Fatalf("%s: merged %d messages, want 3", next, len(msgs))
+		}
+		if u, ok := msgs[2].(parser.UserMsg); !ok || u.Text != "carry on" {
+			t.Errorf("%s: last message = %+v, want the continuation's prompt", next, msgs[2])
+		}
+		if size, _ := src.Size(); size != end {
+			t.Errorf("%s: size %d, read to %d", next, size, end)
+		}
+
+		// Tailing the merged transcript reads what the continuation adds.
+		f, err := os.OpenFile(next, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(forkReply("a2", "u2", "04", "carried on") + "\n")
+		f.Close()
+		more, _, _, err := parser.ReadSourceIncrementalOffsets(src, end)
+		if err != nil || len(more) != 1 {
+			t.Errorf("%s: tail read %d messages (%v), want 1", next, len(more), err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Discovery widens or narrows which sessions discovery lists. The zero
+// value reads the top level of each project directory and keeps every
+// session. Set it once at startup, before the first discovery, like
+// ScanWorkers.
+var Discovery DiscoveryScope
+
+// DiscoveryScope is where session discovery looks and how far back.
+type DiscoveryScope struct {
+	Dirs    []string      // more directories to read alongside the project's, e.g. an archive kept elsewhere
+	Subdirs bool          // also read the subdirectories of each directory, e.g. an archive/ of old sessions
+	MaxAge  time.Duration // skip sessions last written longer ago; 0 keeps them all
+}
+
+// DiscoveryDirs returns the directories discovery reads for projectDirs:
+// each of them, their subdirectories when Discovery.Subdirs is set, and
+// Discovery.Dirs. Watchers use it to see sessions appear wherever discovery
+// would list them.
+func DiscoveryDirs(projectDirs []string) []string {
+	return Discovery.dirs(projectDirs)
+}
+
+// dirs expands projectDirs under the scope, without repeats.
+func (s DiscoveryScope) dirs(projectDirs []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if dir == "" || seen[dir] {
+			return
+		}
+		seen[dir] = true
+		out = append(out, dir)
+	}
+	for _, dir := range append(append([]string{}, projectDirs...), s.Dirs...) {
+		add(dir)
+		if s.Subdirs {
+			for _, sub := range sessionSubdirs(dir) {
+				add(sub)
+			}
+		}
+	}
+	return out
+}
+
+// sessionSubdirs returns the subdirectories under dir that can hold
+// sessions, at any depth. A directory named after a session beside it holds
+// that session's subagent traces and tool output, not sessions, and is
+// skipped with everything under it.
+func sessionSubdirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	sessions := make(map[string]bool)
+	for _, de := range entries {
+		if !de.IsDir() {
+			sessions[de.Name()] = true
+		}
+	}
+	var out []string
+	for _, de := range entries {
+		if !de.IsDir() || sessions[de.Name()+".jsonl"] {
+			continue
+		}
+		sub := filepath.Join(dir, de.Name())
+		out = append(out, sub)
+		out = append(out, sessionSubdirs(sub)...)
+	}
+	return out
+}
+
+// tooOld reports whether a session last written at modTime falls outside
+// the scope's age cutoff.
+func (s DiscoveryScope) tooOld(modTime time.Time) bool {
+	return s.MaxAge > 0 && time.Since(modTime) > s.MaxAge
+}
@@ -0,0 +1,135 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// withDiscovery sets the discovery scope for one test.
+func withDiscovery(t *testing.T, scope parser.DiscoveryScope) {
+	t.Helper()
+	prev := parser.Discovery
+	parser.Discovery = scope
+	t.Cleanup(func() { parser.Discovery = prev })
+}
+
+// sessionIDs returns the IDs of sessions, sorted.
+func sessionIDs(sessions []parser.SessionInfo) []string {
+	var ids []string
+	for _, s := range sessions {
+		ids = append(ids, s.SessionID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// discoveryTree lays out a project directory with a session, the session's
+// own directory of subagent traces, and an archive holding an older session
+// with its traces.
+func discoveryTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	session := func(dir, name string) {
+		writeJSONL(t, dir, name+".jsonl",
+			userEntry("u1", "2025-01-15T10:00:00Z", "Hello from "+name),
+			assistantEntry("a1", "2025-01-15T10:00:01Z", "Hi"),
+		)
+		subagents := filepath.Join(dir, name, "subagents")
+		if err := os.MkdirAll(subagents, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeJSONL(t, subagents, "agent-x.jsonl",
+			userEntry("s1", "2025-01-15T10:00:02Z", "Subagent task"),
+		)
+	}
+	session(dir, "current")
+	archive := filepath.Join(dir, "archive")
+	if err := os.Mkdir(archive, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	session(archive, "archived")
+	return dir
+}
+
+func TestDiscovery_TopLevelByDefault(t *testing.T) {
+	withDiscovery(t, parser.DiscoveryScope{})
+	sessions, err := parser.DiscoverAllProjectSessions([]string{discoveryTree(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sessionIDs(sessions); !slices.Equal(got, []string{"current"}) {
+		t.Errorf("sessions = %v, want [current]", got)
+	}
+}
+
+func TestDiscovery_Subdirs(t *testing.T) {
+	withDiscovery(t, parser.DiscoveryScope{Subdirs: true})
+	dir := discoveryTree(t)
+	sessions, err := parser.DiscoverAllProjectSessions([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Subagent traces under a session's directory are not sessions.
+	if got := sessionIDs(sessions); !slices.Equal(got, []string{"archived", "current"}) {
+		t.Errorf("sessions = %v, want [archived current]", got)
+	}
+	want := []string{dir, filepath.Join(dir, "archive")}
+	if got := parser.DiscoveryDirs([]string{dir}); !slices.Equal(got, want) {
+		t.Errorf("DiscoveryDirs = %v, want %v", got, want)
+	}
+}
+
+func TestDiscovery_ExtraDirs(t *testing.T) {
+	extra := t.TempDir()
+	writeJSONL(t, extra, "elsewhere.jsonl",
+		userEntry("u1", "2025-01-15T10:00:00Z", "Hello"),
+		assistantEntry("a1", "2025-01-15T10:00:01Z", "Hi"),
+	)
+	dir := discoveryTree(t)
+	withDiscovery(t, parser.DiscoveryScope{Dirs: []string{extra, dir}})
+	sessions, err := parser.DiscoverAllProjectSessions([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The project directory listed again as an extra is read once.
+	if got := sessionIDs(sessions); !slices.Equal(got, []string{"current", "elsewhere"}) {
+		t.Errorf("sessions = %v, want [current elsewhere]", got)
+	}
+}
+
+func TestDiscovery_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"recent", "stale"} {
+		writeJSONL(t, dir, name+".jsonl",
+			userEntry("u1", "2025-01-15T10:00:00Z", "Hello"),
+			assistantEntry("a1", "2025-01-15T10:00:01Z", "Hi"),
+		)
+	}
+	old := time.Now().Add(-45 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "stale.jsonl"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	withDiscovery(t, parser.DiscoveryScope{MaxAge: 30 * 24 * time.Hour})
+	sessions, err := parser.DiscoverProjectSessions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sessionIDs(sessions); !slices.Equal(got, []string{"recent"}) {
+		t.Errorf("sessions = %v, want [recent]", got)
+	}
+
+	parser.Discovery.MaxAge = 0
+	sessions, err = parser.NewSessionCache().DiscoverAllProjectSessions([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sessionIDs(sessions); !slices.Equal(got, []string{"recent", "stale"}) {
+		t.Errorf("sessions without a cutoff = %v, want [recent stale]", got)
+	}
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// EndState is how a subagent's conversation stopped, read from its final
+// entries.
+type EndState int
+
+const (
+	EndUnknown      EndState = iota // mid-turn: still running, or killed without a trace
+	EndCompleted                    // the agent answered and stopped
+	EndInterrupted                  // the user interrupted the agent
+	EndErrored                      // the last request failed after its retries
+	EndContextLimit                 // the agent ran out of context window
+)
+
+// String returns the state's label, or "" when unknown.
+func (s EndState) String() string {
+	switch s {
+	case EndCompleted:
+		return "completed"
+	case EndInterrupted:
+		return "interrupted"
+	case EndErrored:
+		return "errored"
+	case EndContextLimit:
+		return "context limit"
+	}
+	return ""
+}
+
+// nextEndState folds one entry into the end state so far. Fed every entry in
+// file order, it yields the state the conversation stopped in: an answer
+// with no tool call completes it, a tool call reopens it, and interruption
+// markers and final API errors end it abnormally. Retry entries change
+// nothing, since a retry may still succeed.
+func nextEndState(cur EndState, e Entry) EndState {
+	switch e.Type {
+	case "user":
+		if strings.HasPrefix(strings.TrimSpace(ExtractText(e.Message.Content)), "[Request interrupted by user") {
+			return EndInterrupted
+		}
+	case "assistant":
+		if e.IsAPIErrorMessage {
+			if isContextLimitError(parseAPIErrorText(ExtractText(e.Message.Content)).Message) {
+				return EndContextLimit
+			}
+			return EndErrored
+		}
+		if e.Message.StopReason != nil && *e.Message.StopReason == "model_context_window_exceeded" {
+			return EndContextLimit
+		}
+		if hasToolUse(e.Message.Content) {
+			return EndUnknown
+		}
+		return EndCompleted
+	}
+	return cur
+}
+
+// isContextLimitError reports whether an API error message says the prompt
+// no longer fits the context window.
+func isContextLimitError(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "prompt is too long") ||
+		strings.Contains(msg, "context window") ||
+		strings.Contains(msg, "context length")
+}
+
+// hasToolUse reports whether assistant content contains a tool_use block.
+func hasToolUse(content json.RawMessage) bool {
+	var blocks []contentBlockJSON
+	if json.Unmarshal(content, &blocks) != nil {
+		return false
+	}
+	for _, b := range blocks {
+		if b.Type == "tool_use" {
+			return true
+		}
+	}
+	return false
+}
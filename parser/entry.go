@@ -16,10 +16,11 @@ type Entry struct {
 		Model      string          `json:"model"`
 		StopReason *string         `json:"stop_reason"`
 		Usage      struct {
-			InputTokens              int `json:"input_tokens"`
-			OutputTokens             int `json:"output_tokens"`
-			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
-			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			InputTokens              int    `json:"input_tokens"`
+			OutputTokens             int    `json:"output_tokens"`
+			CacheReadInputTokens     int    `json:"cache_read_input_tokens"`
+			CacheCreationInputTokens int    `json:"cache_creation_input_tokens"`
+			ServiceTier              string `json:"service_tier"`
 		} `json:"usage"`
 	} `json:"message"`
 
@@ -27,6 +28,14 @@ type Entry struct {
 	Cwd            string `json:"cwd"`
 	GitBranch      string `json:"gitBranch"`
 	PermissionMode string `json:"permissionMode"` // "default", "acceptEdits", "bypassPermissions", "plan"
+	Version        string `json:"version"`        // Claude Code version that wrote the entry
+
+	// Thinking configuration the prompt was sent with (user entries only).
+	ThinkingMetadata *struct {
+		Level             string `json:"level"` // "none", "high", ...
+		Disabled          bool   `json:"disabled"`
+		MaxThinkingTokens int    `json:"maxThinkingTokens"`
+	} `json:"thinkingMetadata"`
 
 	// Tool result metadata (present on isMeta user entries for tool results).
 	// ToolUseResult holds structured output from the tool execution. For
@@ -41,6 +50,49 @@ type Entry struct {
 	// the compression title in Summary rather than message.content.
 	LeafUUID string `json:"leafUuid"`
 	Summary  string `json:"summary"`
+
+	// System entries (type=system) carry their text in a top-level content
+	// field. Hook output uses these with a level and the toolUseID of the
+	// tool call the hook wrapped.
+	Subtype   string          `json:"subtype"`
+	Content   json.RawMessage `json:"content"`
+	Level     string          `json:"level"` // "info", "warning", "error"
+	ToolUseID string          `json:"toolUseID"`
+
+	// Output style entries (type=system, subtype=output_style) name the
+	// style switched to; queue operations (type=queue-operation) record
+	// prompts queued while Claude works, with the prompt in Content.
+	OutputStyle string `json:"outputStyle"`
+	Operation   string `json:"operation"` // "enqueue", "dequeue", "remove", "popAll"
+
+	// API error entries (type=system, subtype=api_error) record a failed
+	// request and the retry scheduled after it. Once retries run out, a
+	// synthetic assistant entry flagged isApiErrorMessage carries the final
+	// error text.
+	Error             json.RawMessage `json:"error"`
+	RetryAttempt      int             `json:"retryAttempt"`
+	MaxRetries        int             `json:"maxRetries"`
+	RetryInMs         float64         `json:"retryInMs"`
+	IsAPIErrorMessage bool            `json:"isApiErrorMessage"`
+
+	// Compaction writes a compact_boundary system entry with the context
+	// size before (and in newer versions after) it, then a user entry
+	// flagged isCompactSummary carrying the summary the session continues
+	// from.
+	CompactMetadata *struct {
+		Trigger    string `json:"trigger"` // "auto" or "manual"
+		PreTokens  int    `json:"preTokens"`
+		PostTokens int    `json:"postTokens"`
+	} `json:"compactMetadata"`
+	IsCompactSummary bool `json:"isCompactSummary"`
+
+	// Attachment entries (type=attachment) carry context injected mid-turn.
+	// A queued_command is a prompt the user sent while Claude was working,
+	// delivered into the running turn.
+	Attachment *struct {
+		Type   string          `json:"type"`   // "queued_command", ...
+		Prompt json.RawMessage `json:"prompt"` // string or content blocks
+	} `json:"attachment"`
 }
 
 // ToolUseResultMap attempts to parse ToolUseResult as a JSON object.
@@ -64,8 +116,9 @@ func ParseEntry(line []byte) (Entry, bool) {
 	if err := json.Unmarshal(line, &e); err != nil {
 		return Entry{}, false
 	}
-	// Summary entries use leafUuid instead of uuid.
-	if e.UUID == "" && e.LeafUUID == "" {
+	// Summary entries use leafUuid instead of uuid; queue operations have
+	// neither.
+	if e.UUID == "" && e.LeafUUID == "" && e.Type != "queue-operation" {
 		return Entry{}, false
 	}
 	return e, true
@@ -0,0 +1,412 @@
+package parser
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Lineage is the parentUuid tree of a session's entries. A session is a
+// single chain until /rewind or a checkpoint restore sends a new prompt from
+// an earlier entry: that entry then has two prompts as children, and the
+// entries after the first one form an abandoned branch that the file still
+// holds, interleaved by time with the branch that replaced it. Lineage finds
+// these forks: Thread rebuilds the conversation from the links, and Apply
+// filters classified messages down to one branch.
+//
+// Lineage reads the file itself, so it sees entries the classifier drops
+// (progress, snapshots) that still link the chain.
+type Lineage struct {
+	nodes    map[string]*lineageNode
+	byOffset map[int64]*lineageNode // line offset -> entry, for Apply
+	newest   *lineageNode           // last entry read with a uuid
+	offset   int64                  // where the next Read resumes
+	count    int
+}
+
+// lineageNode is one entry of the tree.
+type lineageNode struct {
+	uuid     string
+	parent   *lineageNode
+	children []*lineageNode
+	index    int   // file order
+	offset   int64 // of the line
+	prompt   bool
+	text     string // prompt text
+	time     time.Time
+}
+
+// lineageEntry is the part of an entry Lineage reads.
+type lineageEntry struct {
+	Type              string `json:"type"`
+	UUID              string `json:"uuid"`
+	ParentUUID        string `json:"parentUuid"`
+	LogicalParentUUID string `json:"logicalParentUuid"` // compact boundaries restart the chain
+	IsSidechain       bool   `json:"isSidechain"`
+	IsMeta            bool   `json:"isMeta"`
+}
+
+// Branch is one line of a forked session: the prompt that opened it at its
+// fork and everything after it.
+type Branch struct {
+	Leaf    string    // uuid of the branch's newest entry; pass to Apply
+	Prompt  string    // the prompt that opened the branch
+	Time    time.Time // when that prompt was sent
+	Latest  bool      // holds the newest entry: the branch the session continues
+	Current bool      // the branch Apply was given
+}
+
+// Thread is a session's conversation rebuilt from parentUuid links rather
+// than file order: the active path from the first entry to a leaf, and the
+// branches forked away from it.
+type Thread struct {
+	Active    []ThreadEntry     // root first
+	Abandoned []AbandonedBranch // in the order their opening prompts were sent
+}
+
+// ThreadEntry locates one entry of a thread in the session file.
+type ThreadEntry struct {
+	UUID   string
+	Offset int64 // of the entry's line, as ReadSessionIncrementalOffsets reports it
+}
+
+// AbandonedBranch is a branch forked away from the active path: a prompt sent
+// from an entry on it and everything under that prompt, nested forks
+// included.
+type AbandonedBranch struct {
+	Fork    string        // uuid of the entry on the active path it was sent from
+	Prompt  string        // the prompt that opened it
+	Time    time.Time     // when that prompt was sent
+	Path    []ThreadEntry // from the prompt to the branch's newest entry
+	Entries []ThreadEntry // every entry under the prompt, in file order
+}
+
+// ReadLineage reads the lineage of the session at path.
+func ReadLineage(path string) (*Lineage, error) {
+	return ReadSourceLineage(FileSource(path))
+}
+
+// ReadSourceLineage is ReadLineage for any session source.
+func ReadSourceLineage(src SessionSource) (*Lineage, error) {
+	l := NewLineage()
+	return l, l.ReadSource(src)
+}
+
+// NewLineage returns an empty lineage; Read fills it.
+func NewLineage() *Lineage {
+	return &Lineage{
+		nodes:    make(map[string]*lineageNode),
+		byOffset: make(map[int64]*lineageNode),
+	}
+}
+
+// NewLineageFrom returns an empty lineage whose first Read starts at
+// offset, for a session read only from there on (ReadSourceTail). Forks
+// before offset go unseen.
+func NewLineageFrom(offset int64) *Lineage {
+	l := NewLineage()
+	l.offset = offset
+	return l
+}
+
+// Read adds the entries appended to path since the last Read. The first
+// Read takes the whole file.
+func (l *Lineage) Read(path string) error {
+	return l.ReadSource(FileSource(path))
+}
+
+// ReadSource is Read for any session source.
+func (l *Lineage) ReadSource(src SessionSource) error {
+	f, err := src.Open(l.offset)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	lr := newLineReader(f)
+	for {
+		start := l.offset + lr.BytesRead()
+		line, ok := lr.next()
+		if !ok {
+			break
+		}
+		l.add(start, []byte(line))
+	}
+	l.offset += lr.BytesRead()
+	return lr.Err()
+}
+
+// add records one line.
+func (l *Lineage) add(offset int64, line []byte) {
+	var e lineageEntry
+	if json.Unmarshal(line, &e) != nil || e.UUID == "" || e.IsSidechain {
+		return
+	}
+	if _, dup := l.nodes[e.UUID]; dup {
+		return
+	}
+	n := &lineageNode{uuid: e.UUID, index: l.count, offset: offset}
+	l.count++
+	parent := e.ParentUUID
+	if parent == "" {
+		parent = e.LogicalParentUUID
+	}
+	if p := l.nodes[parent]; p != nil {
+		n.parent = p
+		p.children = append(p.children, n)
+	}
+	if e.Type == "user" && !e.IsMeta {
+		if entry, ok := ParseEntry(line); ok {
+			if msg, ok := Classify(entry); ok {
+				if u, ok := msg.(UserMsg); ok {
+					n.prompt, n.text, n.time = true, u.Text, u.Timestamp
+				}
+			}
+		}
+	}
+	l.nodes[e.UUID] = n
+	l.byOffset[offset] = n
+	l.newest = n
+}
+
+// promptChildren returns the prompts sent from n, in the order sent. Two or
+// more make n a fork. Other children (tool results, progress, API errors)
+// don't fork the conversation.
+func (n *lineageNode) promptChildren() []*lineageNode {
+	var out []*lineageNode
+	for _, c := range n.children {
+		if c.prompt {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// leafNode resolves a branch leaf: "" or an unknown uuid is the newest
+// entry.
+func (l *Lineage) leafNode(leaf string) *lineageNode {
+	if n := l.nodes[leaf]; n != nil {
+		return n
+	}
+	return l.newest
+}
+
+// Forked reports whether any entry has more than one prompt sent from it.
+func (l *Lineage) Forked() bool {
+	for _, n := range l.nodes {
+		if len(n.promptChildren()) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedNodes returns every entry in file order.
+func (l *Lineage) sortedNodes() []*lineageNode {
+	nodes := make([]*lineageNode, 0, len(l.nodes))
+	for _, n := range l.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].index < nodes[j].index })
+	return nodes
+}
+
+// newestUnder maps each entry to the newest entry under it, itself
+// included. Children follow their parents in the file, so one pass from the
+// end of nodes sees every child first.
+func newestUnder(nodes []*lineageNode) map[*lineageNode]*lineageNode {
+	newest := make(map[*lineageNode]*lineageNode, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		best := n
+		for _, c := range n.children {
+			if d := newest[c]; d != nil && d.index > best.index {
+				best = d
+			}
+		}
+		newest[n] = best
+	}
+	return newest
+}
+
+// activePath walks from end to the root. It returns the entries on the way,
+// the place of each prompt on the path that opens a branch at a fork
+// (branch, branches), and the prompts sent from forks on the path but not on
+// it: the roots of the branches forked away, in file order.
+func activePath(end *lineageNode) (onPath map[*lineageNode]bool, position map[*lineageNode][2]int, away []*lineageNode) {
+	onPath = make(map[*lineageNode]bool)
+	position = make(map[*lineageNode][2]int)
+	for n := end; n != nil; n = n.parent {
+		onPath[n] = true
+	}
+	for n := range onPath {
+		prompts := n.promptChildren()
+		if len(prompts) < 2 {
+			continue
+		}
+		for i, p := range prompts {
+			if onPath[p] {
+				position[p] = [2]int{i + 1, len(prompts)}
+			} else {
+				away = append(away, p)
+			}
+		}
+	}
+	sort.Slice(away, func(i, j int) bool { return away[i].index < away[j].index })
+	return onPath, position, away
+}
+
+// entryOf locates n.
+func entryOf(n *lineageNode) ThreadEntry {
+	return ThreadEntry{UUID: n.uuid, Offset: n.offset}
+}
+
+// pathTo returns the entries from the root to end, root first.
+func pathTo(end *lineageNode, root *lineageNode) []ThreadEntry {
+	var path []ThreadEntry
+	for n := end; n != nil; n = n.parent {
+		path = append(path, entryOf(n))
+		if n == root {
+			break
+		}
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// Thread rebuilds the conversation ending at leaf ("" follows the newest
+// entry). A session that never forked has no abandoned branches, and its
+// active path holds every entry on the main chain.
+func (l *Lineage) Thread(leaf string) Thread {
+	end := l.leafNode(leaf)
+	if end == nil {
+		return Thread{}
+	}
+	t := Thread{Active: pathTo(end, nil)}
+	_, _, away := activePath(end)
+	if len(away) == 0 {
+		return t
+	}
+	nodes := l.sortedNodes()
+	newest := newestUnder(nodes)
+	for _, p := range away {
+		b := AbandonedBranch{
+			Fork:   p.parent.uuid,
+			Prompt: p.text,
+			Time:   p.time,
+			Path:   pathTo(newest[p], p),
+		}
+		under := map[*lineageNode]bool{p: true}
+		for _, n := range nodes[p.index:] {
+			if under[n] || under[n.parent] {
+				under[n] = true
+				b.Entries = append(b.Entries, entryOf(n))
+			}
+		}
+		t.Abandoned = append(t.Abandoned, b)
+	}
+	return t
+}
+
+// Branches lists the session's branches in the order their opening prompts
+// were sent, or nil when it never forked. leaf marks the Current branch,
+// as passed to Apply.
+func (l *Lineage) Branches(leaf string) []Branch {
+	if l == nil || !l.Forked() {
+		return nil
+	}
+	nodes := l.sortedNodes()
+	newest := newestUnder(nodes)
+
+	// Every prompt at a fork opens a branch ending at its newest entry.
+	// Nested forks share leaves with the forks around them; the innermost
+	// fork, latest in the file, names the branch.
+	current := l.leafNode(leaf)
+	type opened struct {
+		prompt *lineageNode
+		branch Branch
+	}
+	var found []opened
+	seen := make(map[*lineageNode]bool)
+	for i := len(nodes) - 1; i >= 0; i-- {
+		prompts := nodes[i].promptChildren()
+		if len(prompts) < 2 {
+			continue
+		}
+		for _, p := range prompts {
+			end := newest[p]
+			if seen[end] {
+				continue
+			}
+			seen[end] = true
+			found = append(found, opened{p, Branch{
+				Leaf:    end.uuid,
+				Prompt:  p.text,
+				Time:    p.time,
+				Latest:  end == l.newest,
+				Current: end == current,
+			}})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].prompt.index < found[j].prompt.index })
+	branches := make([]Branch, len(found))
+	for i, f := range found {
+		branches[i] = f.branch
+	}
+	return branches
+}
+
+// Apply keeps the messages on the branch ending at leaf ("" follows the
+// newest entry) and drops those of branches forked away from it. msgs and
+// offsets are parallel, as ReadSessionIncrementalOffsets returns them.
+// Messages from lines Lineage has no uuid for are kept, and so are side
+// entries hanging off the branch (API errors, hook output). Each prompt that
+// opens a branch at a fork is stamped with its place there. Returns msgs
+// unchanged when the session never forked.
+func (l *Lineage) Apply(msgs []ClassifiedMsg, offsets []int64, leaf string) []ClassifiedMsg {
+	if l == nil || len(offsets) != len(msgs) || !l.Forked() {
+		return msgs
+	}
+	// Everything under a prompt that opens a branch forked away from the
+	// path is dropped.
+	onPath, position, away := activePath(l.leafNode(leaf))
+	dropped := make(map[*lineageNode]bool, len(away))
+	for _, p := range away {
+		dropped[p] = true
+	}
+	// Whether an entry lies under a dropped prompt, memoized: branches
+	// run long, and each entry's answer is its parent's.
+	off := make(map[*lineageNode]bool)
+	var offBranch func(n *lineageNode) bool
+	offBranch = func(n *lineageNode) bool {
+		if n == nil || onPath[n] {
+			return false
+		}
+		if v, ok := off[n]; ok {
+			return v
+		}
+		v := dropped[n] || offBranch(n.parent)
+		off[n] = v
+		return v
+	}
+
+	out := make([]ClassifiedMsg, 0, len(msgs))
+	for i, msg := range msgs {
+		n := l.byOffset[offsets[i]]
+		if n == nil {
+			out = append(out, msg)
+			continue
+		}
+		if offBranch(n) {
+			continue
+		}
+		if u, ok := msg.(UserMsg); ok {
+			if pos, ok := position[n]; ok {
+				u.Branch, u.Branches = pos[0], pos[1]
+				msg = u
+			}
+		}
+		out = append(out, msg)
+	}
+	return out
+}
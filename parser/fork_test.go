@@ -0,0 +1,159 @@
+package parser_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// forkPrompt and forkReply build linked entries for the lineage tests.
+func forkPrompt(uuid, parent, sec, text string) string {
+	return fmt.Sprintf(`{"uuid":%q,"parentUuid":%q,"type":"user","timestamp":"2025-01-15T10:00:%sZ","message":{"role":"user","content":%q}}`, uuid, parent, sec, text)
+}
+
+func forkReply(uuid, parent, sec, text string) string {
+	return fmt.Sprintf(`{"uuid":%q,"parentUuid":%q,"type":"assistant","timestamp":"2025-01-15T10:00:%sZ","message":{"role":"assistant","content":[{"type":"text","text":%q}],"model":"claude-opus-4-6","stop_reason":"end_turn"}}`, uuid, parent, sec, text)
+}
+
+// readForked reads a session rewound once: "try A" was abandoned for
+// "try B", both sent after the first reply.
+func readForked(t *testing.T) (*parser.Lineage, []parser.ClassifiedMsg, []int64) {
+	t.Helper()
+	path := writeJSONL(t, t.TempDir(), "forked.jsonl",
+		forkPrompt("u1", "", "01", "set up"),
+		forkReply("a1", "u1", "02", "done"),
+		forkPrompt("u2", "a1", "03", "try A"),
+		forkReply("a2", "u2", "04", "A failed"),
+		forkPrompt("u3", "a1", "05", "try B"),
+		forkReply("a3", "u3", "06", "B worked"),
+	)
+	lineage := parser.NewLineage()
+	if err := lineage.Read(path); err != nil {
+		t.Fatal(err)
+	}
+	msgs, offsets, _, err := parser.ReadSessionIncrementalOffsets(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lineage, msgs, offsets
+}
+
+// prompts returns the user prompts in msgs with their branch positions.
+func prompts(msgs []parser.ClassifiedMsg) []string {
+	var out []string
+	for _, m := range msgs {
+		if u, ok := m.(parser.UserMsg); ok {
+			out = append(out, fmt.Sprintf("%s %d/%d", u.Text, u.Branch, u.Branches))
+		}
+	}
+	return out
+}
+
+func TestLineage_Branches(t *testing.T) {
+	lineage, _, _ := readForked(t)
+	branches := lineage.Branches("")
+	if len(branches) != 2 {
+		t.Fatalf("len(branches) = %d, want 2", len(branches))
+	}
+	if b := branches[0]; b.Prompt != "try A" || b.Leaf != "a2" || b.Latest || b.Current {
+		t.Errorf("branches[0] = %+v, want the abandoned try A", b)
+	}
+	if b := branches[1]; b.Prompt != "try B" || b.Leaf != "a3" || !b.Latest || !b.Current {
+		t.Errorf("branches[1] = %+v, want the latest try B, shown", b)
+	}
+	if got := lineage.Branches("a2"); !got[0].Current || got[1].Current {
+		t.Errorf("Branches(a2) marks %+v, want try A current", got)
+	}
+}
+
+func TestLineage_Apply(t *testing.T) {
+	lineage, msgs, offsets := readForked(t)
+
+	got := prompts(lineage.Apply(msgs, offsets, ""))
+	want := []string{"set up 0/0", "try B 2/2"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("newest branch prompts = %v, want %v", got, want)
+	}
+
+	kept := lineage.Apply(msgs, offsets, "a2")
+	got = prompts(kept)
+	want = []string{"set up 0/0", "try A 1/2"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("abandoned branch prompts = %v, want %v", got, want)
+	}
+	if len(kept) != 4 {
+		t.Errorf("len(kept) = %d, want 4 (two prompts, two replies)", len(kept))
+	}
+}
+
+func TestLineage_UnforkedUnchanged(t *testing.T) {
+	path := writeJSONL(t, t.TempDir(), "linear.jsonl",
+		forkPrompt("u1", "", "01", "one"),
+		forkReply("a1", "u1", "02", "ok"),
+		forkPrompt("u2", "a1", "03", "two"),
+	)
+	lineage := parser.NewLineage()
+	if err := lineage.Read(path); err != nil {
+		t.Fatal(err)
+	}
+	msgs, offsets, _, _ := parser.ReadSessionIncrementalOffsets(path, 0)
+	if got := lineage.Apply(msgs, offsets, ""); len(got) != len(msgs) {
+		t.Errorf("Apply kept %d of %d messages, want all", len(got), len(msgs))
+	}
+	if b := lineage.Branches(""); b != nil {
+		t.Errorf("Branches = %+v, want nil", b)
+	}
+}
+
+// uuids lists the entries' uuids.
+func uuids(entries []parser.ThreadEntry) string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.UUID)
+	}
+	return fmt.Sprint(out)
+}
+
+func TestLineage_Thread(t *testing.T) {
+	lineage, _, offsets := readForked(t)
+
+	th := lineage.Thread("")
+	if got := uuids(th.Active); got != "[u1 a1 u3 a3]" {
+		t.Errorf("Active = %s, want [u1 a1 u3 a3]", got)
+	}
+	if th.Active[2].Offset != offsets[4] {
+		t.Errorf("u3 offset = %d, want %d", th.Active[2].Offset, offsets[4])
+	}
+	if len(th.Abandoned) != 1 {
+		t.Fatalf("len(Abandoned) = %d, want 1", len(th.Abandoned))
+	}
+	b := th.Abandoned[0]
+	if b.Fork != "a1" || b.Prompt != "try A" || uuids(b.Path) != "[u2 a2]" || uuids(b.Entries) != "[u2 a2]" {
+		t.Errorf("Abandoned[0] = %+v, want try A forked from a1", b)
+	}
+
+	th = lineage.Thread("a2")
+	if got := uuids(th.Active); got != "[u1 a1 u2 a2]" {
+		t.Errorf("Active(a2) = %s, want [u1 a1 u2 a2]", got)
+	}
+	if len(th.Abandoned) != 1 || th.Abandoned[0].Prompt != "try B" {
+		t.Errorf("Abandoned(a2) = %+v, want try B", th.Abandoned)
+	}
+}
+
+func TestReadLineage_CompactBoundaryKeepsChain(t *testing.T) {
+	path := writeJSONL(t, t.TempDir(), "compacted.jsonl",
+		forkPrompt("u1", "", "01", "one"),
+		forkReply("a1", "u1", "02", "ok"),
+		`{"uuid":"c1","parentUuid":null,"logicalParentUuid":"a1","type":"system","subtype":"compact_boundary","timestamp":"2025-01-15T10:00:03Z","content":"Conversation compacted"}`,
+		forkPrompt("u2", "c1", "04", "two"),
+	)
+	lineage, err := parser.ReadLineage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := uuids(lineage.Thread("").Active); got != "[u1 a1 c1 u2]" {
+		t.Errorf("Active = %s, want the chain through the boundary", got)
+	}
+}
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
 	"io"
 )
 
@@ -27,6 +29,7 @@ type lineReader struct {
 	buf       []byte
 	err       error
 	bytesRead int64
+	oversized int // lines skipped for exceeding the limit
 }
 
 func newLineReader(r io.Reader) *lineReader {
@@ -70,67 +73,63 @@ func (lr *lineReader) BytesRead() int64 {
 // readLine reads a full line, returning "" for blank/oversized lines and
 // a non-nil error only at EOF or read failure.
 //
-// Uses bufio.Reader.ReadLine() which returns isPrefix=true for partial
-// reads. When accumulated bytes exceed maxLineSize, the buffer is
+// Uses bufio.Reader.ReadSlice, which returns bufio.ErrBufferFull for
+// partial reads. When accumulated bytes exceed maxLineSize, the buffer is
 // discarded and the rest of the line is consumed (to keep bytesRead
 // accurate), then "" is returned so next() skips to the following line.
+//
+// A final line without its newline may be one the writer hasn't finished:
+// Claude Code's writes can land in pieces, and a poll can fall between
+// them. Such a line is returned only when it is complete JSON; otherwise it
+// is left unread, out of bytesRead, so the next incremental read starts at
+// its beginning and sees it whole.
 func (lr *lineReader) readLine() (string, error) {
 	lr.buf = lr.buf[:0]
 	oversized := false
+	var n int64 // bytes of this line consumed so far
 
-	for {
-		chunk, isPrefix, err := lr.r.ReadLine()
-		// Count data bytes from every chunk, including oversized lines.
-		lr.bytesRead += int64(len(chunk))
-
-		if err != nil {
-			if len(lr.buf) > 0 && err == io.EOF {
-				// Final line data was accumulated in previous iterations.
-				// No \n to count -- the line ended at EOF.
-				break
-			}
-			return "", err
-		}
-
-		// bufio.ReadLine strips the \n delimiter but we still consumed it
-		// from the underlying reader. Add +1 when the line is complete.
-		//
-		// Caveat: ReadLine can't distinguish "line ended with \n" from
-		// "line ended at EOF without \n" -- both return (data, false, nil).
-		// This means BytesRead may overcount by 1 on the final line if the
-		// file lacks a trailing newline. That's harmless for JSONL tailing:
-		// real entries always end with \n, and the overcount exactly skips
-		// the \n that the next append will prepend.
-		if !isPrefix {
-			lr.bytesRead++
-		}
-
+	limit := maxLineSize
+	if lr.maxLen > 0 {
+		limit = lr.maxLen
+	}
+	keep := func(chunk []byte) {
 		if oversized {
-			if !isPrefix {
-				return "", nil // done skipping
-			}
-			continue
+			return
 		}
-
 		lr.buf = append(lr.buf, chunk...)
-
-		limit := maxLineSize
-		if lr.maxLen > 0 {
-			limit = lr.maxLen
-		}
 		if len(lr.buf) > limit {
 			oversized = true
 			lr.buf = lr.buf[:0]
-			if !isPrefix {
-				return "", nil
-			}
-			continue
 		}
+	}
 
-		if !isPrefix {
-			break
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		n += int64(len(chunk))
+		switch {
+		case err == bufio.ErrBufferFull:
+			keep(chunk)
+			continue
+		case err == io.EOF:
+			if n == 0 {
+				return "", io.EOF
+			}
+			keep(chunk)
+			if oversized || !json.Valid(lr.buf) {
+				return "", io.EOF // unfinished: leave it for the next read
+			}
+			lr.bytesRead += n
+			return string(lr.buf), nil
+		case err != nil:
+			lr.bytesRead += n
+			return "", err
 		}
+		lr.bytesRead += n
+		keep(chunk[:len(chunk)-1])
+		if oversized {
+			lr.oversized++
+			return "", nil
+		}
+		return string(bytes.TrimSuffix(lr.buf, []byte{'\r'})), nil
 	}
-
-	return string(lr.buf), nil
 }
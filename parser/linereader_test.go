@@ -49,8 +49,20 @@ func TestLineReader(t *testing.T) {
 			want:   []string{"aaa", "bbb"},
 		},
 		{
-			name:   "line without trailing newline",
-			input:  "aaa\nbbb",
+			name:   "complete line without trailing newline",
+			input:  "aaa\n{\"b\":1}",
+			maxLen: 100,
+			want:   []string{"aaa", `{"b":1}`},
+		},
+		{
+			name:   "unfinished final line held back",
+			input:  "aaa\n{\"b\":",
+			maxLen: 100,
+			want:   []string{"aaa"},
+		},
+		{
+			name:   "carriage return dropped",
+			input:  "aaa\r\nbbb\r\n",
 			maxLen: 100,
 			want:   []string{"aaa", "bbb"},
 		},
@@ -177,27 +189,29 @@ func TestLineReaderBytesReadWithSkippedLines(t *testing.T) {
 }
 
 func TestLineReaderBytesReadNoTrailingNewline(t *testing.T) {
-	input := "aaa\nbbb"
-	lr := newLineReaderWithMax(strings.NewReader(input), 100)
-
-	for {
-		_, ok := lr.next()
-		if !ok {
-			break
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  int64
+	}{
+		// A complete entry is read, and the newline the writer adds next
+		// reads as a blank line.
+		{"complete", "aaa\n{\"b\":1}", 11},
+		// A torn write stays unread so the next read starts at its line.
+		{"torn", "aaa\n{\"b\":", 4},
+	} {
+		lr := newLineReaderWithMax(strings.NewReader(tc.input), 100)
+		for {
+			if _, ok := lr.next(); !ok {
+				break
+			}
+		}
+		if lr.Err() != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, lr.Err())
+		}
+		if lr.BytesRead() != tc.want {
+			t.Errorf("%s: BytesRead() = %d, want %d", tc.name, lr.BytesRead(), tc.want)
 		}
-	}
-
-	if lr.Err() != nil {
-		t.Fatalf("unexpected error: %v", lr.Err())
-	}
-	// "aaa\n" = 4 bytes, "bbb" = 3 bytes. bufio.ReadLine can't distinguish
-	// "line ended with \n" from "line ended at EOF", so the final line
-	// overcounts by 1 (adds +1 for a nonexistent \n). This matches the
-	// old bufio.Scanner behavior and is harmless for JSONL files which
-	// always end with \n.
-	wantBytes := int64(len(input)) + 1
-	if lr.BytesRead() != wantBytes {
-		t.Errorf("BytesRead() = %d, want %d", lr.BytesRead(), wantBytes)
 	}
 }
 
@@ -0,0 +1,8 @@
+package parser
+
+import "log/slog"
+
+// Log receives the parser's diagnostics: transcript lines it skipped as
+// malformed or oversized. Set at startup (tail-claude's --log-file); the
+// default discards them.
+var Log = slog.New(slog.DiscardHandler)
@@ -3,7 +3,9 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestScanSessionMetadata_OngoingToolUse(t *testing.T) {
@@ -134,6 +136,24 @@ func TestScanSessionMetadata_NotOngoingInterruptedPending(t *testing.T) {
 	}
 }
 
+func TestScanSessionMetadata_OngoingRules(t *testing.T) {
+	old := Ongoing
+	t.Cleanup(func() { Ongoing = old })
+
+	Ongoing = OngoingRules{IgnorePending: true}
+	if meta := scanSessionMetadata(filepath.Join("testdata", "ongoing_pending_task.jsonl")); meta.isOngoing {
+		t.Error("with pending calls ignored, the answered turn should not be ongoing")
+	}
+
+	Ongoing = OngoingRules{Endings: []EndingEvent{EndOnShutdownApproval}}
+	if meta := scanSessionMetadata(filepath.Join("testdata", "not_ongoing_exitplan.jsonl")); !meta.isOngoing {
+		t.Error("with ExitPlanMode not an ending event, the activity before it should count")
+	}
+	if meta := scanSessionMetadata(filepath.Join("testdata", "not_ongoing_shutdown.jsonl")); meta.isOngoing {
+		t.Error("shutdown approval is still an ending event")
+	}
+}
+
 // --- ResolveGitRoot tests ---
 
 func TestResolveGitRoot_NormalRepo(t *testing.T) {
@@ -235,3 +255,31 @@ func TestResolveGitRoot_RealWorktree(t *testing.T) {
 		t.Errorf("ResolveGitRoot still points to worktree: %s", resolved)
 	}
 }
+
+func TestScanSessionMetadata_Compactions(t *testing.T) {
+	meta := scanSessionMetadata(filepath.Join("testdata", "compacted.jsonl"))
+	if meta.compactions != 1 {
+		t.Errorf("compactions = %d, want 1", meta.compactions)
+	}
+	// The injected summary is neither a turn nor the preview.
+	if meta.turnCount != 4 {
+		t.Errorf("turnCount = %d, want 4", meta.turnCount)
+	}
+	if meta.firstMsg != "Refactor the parser" {
+		t.Errorf("firstMsg = %q", meta.firstMsg)
+	}
+}
+
+func TestScanSessionMetadata_LongPreviewKeepsRunes(t *testing.T) {
+	// 499 ASCII bytes and then three-byte runes: byte 500 falls inside one.
+	prompt := strings.Repeat("a", 499) + strings.Repeat("é中", 10)
+	path := filepath.Join(t.TempDir(), "s.jsonl")
+	line := `{"uuid":"u1","type":"user","timestamp":"2025-01-15T10:00:00Z","message":{"role":"user","content":"` + prompt + `"}}` + "\n"
+	if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	meta := scanSessionMetadata(path)
+	if !utf8.ValidString(meta.firstMsg) || !strings.HasPrefix(prompt, meta.firstMsg) || len(meta.firstMsg) > 500 {
+		t.Errorf("firstMsg = %q (%d bytes), want a valid prefix of at most 500 bytes", meta.firstMsg, len(meta.firstMsg))
+	}
+}
@@ -2,7 +2,9 @@ package parser
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -13,6 +15,85 @@ import (
 // process is gone.
 const OngoingStalenessThreshold = 2 * time.Minute
 
+// Ongoing tunes ongoing detection for the viewer, the picker, and every
+// staleness check. The zero value is the built-in heuristics. Set it once at
+// startup, like Discovery.
+var Ongoing OngoingRules
+
+// EndingEvent names an event that ends Claude's turn. AI activity after the
+// last one means the session is still working.
+type EndingEvent string
+
+const (
+	EndOnText             EndingEvent = "text"             // a text response
+	EndOnInterruption     EndingEvent = "interruption"     // the user interrupting or rejecting a tool call (picker scan)
+	EndOnExitPlanMode     EndingEvent = "exitPlanMode"     // an ExitPlanMode call
+	EndOnShutdownApproval EndingEvent = "shutdownApproval" // a teammate approving its shutdown
+)
+
+// EndingEvents lists every ending event, the default set.
+var EndingEvents = []EndingEvent{EndOnText, EndOnInterruption, EndOnExitPlanMode, EndOnShutdownApproval}
+
+// OngoingRules are the knobs of ongoing detection.
+type OngoingRules struct {
+	Staleness     time.Duration // silence after which a session is dead whatever its content; 0 uses OngoingStalenessThreshold
+	Endings       []EndingEvent // events that end a turn; nil uses EndingEvents. A left-out event is ignored.
+	IgnorePending bool          // don't let calls still awaiting a result keep a session ongoing
+}
+
+// Threshold returns the staleness threshold in effect.
+func (r OngoingRules) Threshold() time.Duration {
+	if r.Staleness > 0 {
+		return r.Staleness
+	}
+	return OngoingStalenessThreshold
+}
+
+// Stale reports whether a session last written at modTime has been quiet
+// past the threshold by now.
+func (r OngoingRules) Stale(modTime, now time.Time) bool {
+	return now.Sub(modTime) > r.Threshold()
+}
+
+// ends reports whether e counts as an ending event.
+func (r OngoingRules) ends(e EndingEvent) bool {
+	if r.Endings == nil {
+		return true
+	}
+	return slices.Contains(r.Endings, e)
+}
+
+// String summarizes the rules for the debug view.
+func (r OngoingRules) String() string {
+	endings := r.Endings
+	if endings == nil {
+		endings = EndingEvents
+	}
+	names := make([]string, len(endings))
+	for i, e := range endings {
+		names[i] = string(e)
+	}
+	ending := "none"
+	if len(names) > 0 {
+		ending = strings.Join(names, ", ")
+	}
+	pending := "pending calls count"
+	if r.IgnorePending {
+		pending = "pending calls ignored"
+	}
+	return fmt.Sprintf("staleness %s; ending events: %s; %s", r.Threshold(), ending, pending)
+}
+
+// ValidateEndingEvents reports a name that isn't an ending event.
+func ValidateEndingEvents(names []string) error {
+	for _, n := range names {
+		if !slices.Contains(EndingEvents, EndingEvent(n)) {
+			return fmt.Errorf("unknown ending event %q (want one of %v)", n, EndingEvents)
+		}
+	}
+	return nil
+}
+
 // activityType classifies events for ongoing detection.
 type activityType int
 
@@ -21,7 +102,7 @@ const (
 	actThinking                         // extended thinking (AI activity)
 	actToolUse                          // tool invocation (AI activity)
 	actToolResult                       // tool result (AI activity)
-	actInterruption                     // user interruption (ending event)
+	actShutdown                         // shutdown approval or its result (ending event)
 	actExitPlanMode                     // ExitPlanMode tool call (ending event)
 )
 
@@ -29,11 +110,21 @@ const (
 type activity struct {
 	typ   activityType
 	index int
+	desc  string // what happened, for explanations: "Bash call", "text output"
 }
 
-// isEndingEvent returns true if this activity type terminates an ongoing session.
-func (a activity) isEndingEvent() bool {
-	return a.typ == actTextOutput || a.typ == actInterruption || a.typ == actExitPlanMode
+// isEndingEvent returns true if this activity type terminates an ongoing
+// session under the rules.
+func (a activity) isEndingEvent(r OngoingRules) bool {
+	switch a.typ {
+	case actTextOutput:
+		return r.ends(EndOnText)
+	case actShutdown:
+		return r.ends(EndOnShutdownApproval)
+	case actExitPlanMode:
+		return r.ends(EndOnExitPlanMode)
+	}
+	return false
 }
 
 // isAIActivity returns true if this activity type represents AI work in progress.
@@ -62,12 +153,46 @@ func isShutdownApproval(toolName string, toolInput json.RawMessage) bool {
 	return fields.Type == "shutdown_response" && fields.Approve != nil && *fields.Approve
 }
 
+// OngoingVerdict is ongoing detection's answer with the reasoning behind it,
+// so a session wrongly shown live (or dead) can be traced to the rule that
+// decided it.
+type OngoingVerdict struct {
+	Ongoing bool
+	Steps   []string // how the verdict was reached, in order
+}
+
+// add records a step of the reasoning.
+func (v *OngoingVerdict) add(format string, args ...any) {
+	v.Steps = append(v.Steps, fmt.Sprintf(format, args...))
+}
+
+// Override records a check made after the content verdict, such as a
+// subagent still working or the staleness threshold, and sets the verdict.
+func (v *OngoingVerdict) Override(ongoing bool, reason string) {
+	v.Ongoing = ongoing
+	v.Steps = append(v.Steps, reason)
+}
+
+// CheckStaleness ends the verdict of an ongoing session last written at
+// modTime once it has been quiet past the threshold.
+func (v *OngoingVerdict) CheckStaleness(modTime, now time.Time) {
+	if !v.Ongoing || modTime.IsZero() {
+		return
+	}
+	quiet := now.Sub(modTime).Truncate(time.Second)
+	if Ongoing.Stale(modTime, now) {
+		v.Override(false, fmt.Sprintf("last write %s ago, past the %s staleness threshold: not ongoing", quiet, Ongoing.Threshold()))
+		return
+	}
+	v.add("last write %s ago, within the %s staleness threshold", quiet, Ongoing.Threshold())
+}
+
 // IsOngoing reports whether the session appears to still be in progress.
 // A session is ongoing if either:
 //
 //  1. There's AI activity (thinking, tool_use, tool_result) after the last
-//     "ending event" (text output, interruption, ExitPlanMode, shutdown approval).
-//  2. Any tool call is still awaiting a result (pending tool calls).
+//     "ending event" (text output, ExitPlanMode, shutdown approval).
+//  2. Any agent call is still awaiting a result (pending agent calls).
 //
 // Condition 2 catches team sessions where the parent writes text output after
 // receiving partial agent results. The activity-based check (1) only looks
@@ -78,22 +203,65 @@ func isShutdownApproval(toolName string, toolInput json.RawMessage) bool {
 //
 // For chunks without structured items (old-style), falls back to checking
 // whether the last chunk is an AI chunk without a stop_reason of "end_turn".
+//
+// Ongoing decides which events end a turn and whether pending calls count.
 func IsOngoing(chunks []Chunk) bool {
+	return ExplainOngoing(chunks).Ongoing
+}
+
+// ExplainOngoing is IsOngoing with its reasoning. Staleness isn't applied:
+// callers that know the file's modification time call CheckStaleness.
+func ExplainOngoing(chunks []Chunk) OngoingVerdict {
+	var v OngoingVerdict
+	v.add("rules: %s", Ongoing)
 	if len(chunks) == 0 {
-		return false
+		v.add("no chunks: not ongoing")
+		return v
 	}
 
 	// A trailing user prompt means Claude is processing the request.
 	// Callers apply staleness thresholds to handle dead sessions where
 	// the user typed but Claude never responded.
-	if chunks[len(chunks)-1].Type == UserChunk {
-		return true
+	last := chunks[len(chunks)-1]
+	if last.Type == UserChunk {
+		v.Override(true, "last chunk is a user prompt awaiting Claude: ongoing")
+		return v
+	}
+	// Same for a slash command that hasn't produced local output yet
+	// (prompt commands like /review hand off to Claude).
+	if last.Type == CommandChunk && last.Output == "" {
+		v.Override(true, "last chunk is a slash command without output yet: ongoing")
+		return v
+	}
+	// A trailing API error is ongoing while a retry is scheduled; a final
+	// error ended the turn.
+	if last.Type == ErrorChunk {
+		if last.Errors[len(last.Errors)-1].Final {
+			v.add("last chunk is a final API error: not ongoing")
+			return v
+		}
+		v.Override(true, "last chunk is an API error with a retry scheduled: ongoing")
+		return v
+	}
+	// Likewise for an error after the last response of a turn.
+	if n := len(last.Errors); last.Type == AIChunk && n > 0 {
+		end := last.Timestamp.Add(time.Duration(last.DurationMs) * time.Millisecond)
+		if e := last.Errors[n-1]; !e.Timestamp.Before(end) {
+			if e.Final {
+				v.add("turn ends in a final API error: not ongoing")
+				return v
+			}
+			v.Override(true, "turn ends in an API error with a retry scheduled: ongoing")
+			return v
+		}
 	}
 
 	// Collect activities from structured items across all chunks.
 	var activities []activity
-	actIdx := 0
 	hasItems := false
+	record := func(typ activityType, desc string) {
+		activities = append(activities, activity{typ: typ, index: len(activities), desc: desc})
+	}
 
 	// Track tool_use IDs that are shutdown approvals so their tool_results
 	// are also treated as ending events.
@@ -112,45 +280,37 @@ func IsOngoing(chunks []Chunk) bool {
 		for _, item := range chunk.Items {
 			switch item.Type {
 			case ItemThinking:
-				activities = append(activities, activity{typ: actThinking, index: actIdx})
-				actIdx++
+				record(actThinking, "thinking")
 
 			case ItemOutput:
 				if strings.TrimSpace(item.Text) != "" {
-					activities = append(activities, activity{typ: actTextOutput, index: actIdx})
-					actIdx++
+					record(actTextOutput, "text output")
 				}
 
 			case ItemToolCall:
 				if item.ToolName == "ExitPlanMode" {
-					activities = append(activities, activity{typ: actExitPlanMode, index: actIdx})
-					actIdx++
+					record(actExitPlanMode, "ExitPlanMode call")
 				} else if isShutdownApproval(item.ToolName, item.ToolInput) {
 					shutdownToolIDs[item.ToolID] = true
-					activities = append(activities, activity{typ: actInterruption, index: actIdx})
-					actIdx++
+					record(actShutdown, "shutdown approval")
 				} else {
-					activities = append(activities, activity{typ: actToolUse, index: actIdx})
-					actIdx++
+					record(actToolUse, item.ToolName+" call")
 				}
 
 				// If this tool call has a result, track it too.
 				if item.ToolResult != "" {
 					if shutdownToolIDs[item.ToolID] {
-						activities = append(activities, activity{typ: actInterruption, index: actIdx})
+						record(actShutdown, "shutdown approval result")
 					} else {
-						activities = append(activities, activity{typ: actToolResult, index: actIdx})
+						record(actToolResult, item.ToolName+" result")
 					}
-					actIdx++
 				}
 
 			case ItemSubagent:
 				// Subagent spawns are AI activity (like tool_use).
-				activities = append(activities, activity{typ: actToolUse, index: actIdx})
-				actIdx++
+				record(actToolUse, "subagent spawn")
 				if item.ToolResult != "" {
-					activities = append(activities, activity{typ: actToolResult, index: actIdx})
-					actIdx++
+					record(actToolResult, "subagent result")
 				}
 			}
 		}
@@ -158,8 +318,9 @@ func IsOngoing(chunks []Chunk) bool {
 
 	// If we had items, use the activity-based detection.
 	if hasItems {
-		if isOngoingFromActivities(activities) {
-			return true
+		if isOngoingFromActivities(activities, Ongoing, &v) {
+			v.Ongoing = true
+			return v
 		}
 		// Activity sequence says complete, but check for pending agents.
 		// This catches team sessions where the parent writes text output after
@@ -167,26 +328,41 @@ func IsOngoing(chunks []Chunk) bool {
 		// in the activity sequence. Only agent/task calls are checked — regular
 		// tools (Read, Bash, Write) can legitimately lack results after
 		// interruptions or context compaction without meaning the session is ongoing.
-		return hasPendingAgents(chunks)
+		if name, ok := pendingAgent(chunks); ok {
+			if Ongoing.IgnorePending {
+				v.add("%s still awaits its result, but pending calls are ignored: not ongoing", name)
+				return v
+			}
+			v.Override(true, name+" still awaits its result: ongoing")
+			return v
+		}
+		v.add("no agent call awaits a result: not ongoing")
+		return v
 	}
 
 	// Fallback for old-style chunks without structured items:
 	// ongoing if the last AI chunk has no end_turn stop reason.
 	for i := len(chunks) - 1; i >= 0; i-- {
 		if chunks[i].Type == AIChunk {
-			return chunks[i].StopReason != "end_turn"
+			if chunks[i].StopReason == "end_turn" {
+				v.add("no structured items; last AI chunk stopped with end_turn: not ongoing")
+				return v
+			}
+			v.Override(true, fmt.Sprintf("no structured items; last AI chunk stop reason %q is not end_turn: ongoing", chunks[i].StopReason))
+			return v
 		}
 	}
 
-	return false
+	v.add("no AI chunks: not ongoing")
+	return v
 }
 
-// hasPendingAgents checks whether any agent/task tool call is still awaiting a
-// result. Only checks ItemSubagent items and ItemToolCall items where ToolName
-// is "Task" or "Agent" — regular tools (Read, Bash, Write, etc.) execute and
-// return within seconds, so a missing result means the session was interrupted
-// or the JSONL is incomplete, not evidence of ongoing work.
-func hasPendingAgents(chunks []Chunk) bool {
+// pendingAgent finds an agent/task tool call still awaiting a result and
+// names it. Only checks ItemSubagent items and ItemToolCall items where
+// ToolName is "Task" or "Agent" — regular tools (Read, Bash, Write, etc.)
+// execute and return within seconds, so a missing result means the session
+// was interrupted or the JSONL is incomplete, not evidence of ongoing work.
+func pendingAgent(chunks []Chunk) (string, bool) {
 	for _, chunk := range chunks {
 		if chunk.Type != AIChunk {
 			continue
@@ -195,30 +371,33 @@ func hasPendingAgents(chunks []Chunk) bool {
 			switch item.Type {
 			case ItemSubagent:
 				if item.ToolResult == "" {
-					return true
+					return "subagent " + item.ToolID, true
 				}
 			case ItemToolCall:
 				if (item.ToolName == "Task" || item.ToolName == "Agent") && item.ToolResult == "" {
-					return true
+					return item.ToolName + " call " + item.ToolID, true
 				}
 			}
 		}
 	}
-	return false
+	return "", false
 }
 
-// isOngoingFromActivities determines ongoing state from collected activities.
+// isOngoingFromActivities determines ongoing state from collected activities,
+// recording its reasoning in v.
 // Ported from claude-devtools sessionStateDetection.ts.
-func isOngoingFromActivities(activities []activity) bool {
+func isOngoingFromActivities(activities []activity, r OngoingRules, v *OngoingVerdict) bool {
 	if len(activities) == 0 {
+		v.add("no AI activity")
 		return false
 	}
 
 	// Find the index of the last ending event.
 	lastEndingIdx := -1
 	for i := len(activities) - 1; i >= 0; i-- {
-		if activities[i].isEndingEvent() {
+		if activities[i].isEndingEvent(r) {
 			lastEndingIdx = activities[i].index
+			v.add("last ending event: %s (activity %d of %d)", activities[i].desc, i+1, len(activities))
 			break
 		}
 	}
@@ -227,18 +406,33 @@ func isOngoingFromActivities(activities []activity) bool {
 	if lastEndingIdx == -1 {
 		for _, a := range activities {
 			if a.isAIActivity() {
+				v.add("no ending event, and AI activity (%s): ongoing", a.desc)
 				return true
 			}
 		}
+		v.add("no ending event and no AI activity")
 		return false
 	}
 
 	// Check for AI activity AFTER the last ending event.
+	var after []string
 	for _, a := range activities {
 		if a.index > lastEndingIdx && a.isAIActivity() {
-			return true
+			after = append(after, a.desc)
 		}
 	}
-
+	if len(after) > 0 {
+		v.add("AI activity after it: %s: ongoing", summarizeDescs(after))
+		return true
+	}
+	v.add("no AI activity after it")
 	return false
 }
+
+// summarizeDescs lists activity descriptions, eliding the middle of long runs.
+func summarizeDescs(descs []string) string {
+	if len(descs) > 4 {
+		return fmt.Sprintf("%s, … %d more, %s", strings.Join(descs[:2], ", "), len(descs)-3, descs[len(descs)-1])
+	}
+	return strings.Join(descs, ", ")
+}
@@ -2,6 +2,7 @@ package parser_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -426,3 +427,100 @@ func TestIsOngoing_PendingAgentToolCall(t *testing.T) {
 		t.Error("should be ongoing: Agent tool call a2 has no result")
 	}
 }
+
+// withOngoing sets the ongoing rules for one test.
+func withOngoing(t *testing.T, r parser.OngoingRules) {
+	t.Helper()
+	old := parser.Ongoing
+	parser.Ongoing = r
+	t.Cleanup(func() { parser.Ongoing = old })
+}
+
+// pendingTaskChunks is a turn that answered in text while a Task call
+// still awaits its result.
+func pendingTaskChunks() []parser.Chunk {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	return parser.BuildChunks([]parser.ClassifiedMsg{
+		parser.AIMsg{
+			Timestamp: t0,
+			Model:     "claude-opus-4-6",
+			Blocks: []parser.ContentBlock{
+				{Type: "tool_use", ToolID: "t1", ToolName: "Task", ToolInput: json.RawMessage(`{"description":"Agent"}`)},
+				{Type: "text", Text: "Waiting for the agent."},
+			},
+			ToolCalls: []parser.ToolCall{{ID: "t1", Name: "Task"}},
+		},
+	})
+}
+
+func TestIsOngoing_Rules(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	answered := parser.BuildChunks([]parser.ClassifiedMsg{
+		parser.AIMsg{
+			Timestamp: t0,
+			Model:     "claude-opus-4-6",
+			Blocks: []parser.ContentBlock{
+				{Type: "thinking", Text: "Let me think..."},
+				{Type: "text", Text: "Here is the answer."},
+			},
+		},
+	})
+
+	if parser.IsOngoing(answered) {
+		t.Fatal("text output ends the turn by default")
+	}
+	withOngoing(t, parser.OngoingRules{Endings: []parser.EndingEvent{parser.EndOnExitPlanMode}})
+	if !parser.IsOngoing(answered) {
+		t.Error("with text not an ending event, the thinking should count as ongoing")
+	}
+
+	if !parser.IsOngoing(pendingTaskChunks()) {
+		t.Fatal("a pending Task call is ongoing by default")
+	}
+	withOngoing(t, parser.OngoingRules{IgnorePending: true})
+	if parser.IsOngoing(pendingTaskChunks()) {
+		t.Error("with pending calls ignored, the answered turn should not be ongoing")
+	}
+}
+
+func TestExplainOngoing(t *testing.T) {
+	v := parser.ExplainOngoing(pendingTaskChunks())
+	if !v.Ongoing {
+		t.Fatal("want ongoing")
+	}
+	want := []string{
+		"rules: staleness 2m0s; ending events: text, interruption, exitPlanMode, shutdownApproval; pending calls count",
+		"last ending event: text output (activity 2 of 2)",
+		"no AI activity after it",
+		"subagent t1 still awaits its result: ongoing",
+	}
+	if strings.Join(v.Steps, "\n") != strings.Join(want, "\n") {
+		t.Errorf("steps:\n%s\nwant:\n%s", strings.Join(v.Steps, "\n"), strings.Join(want, "\n"))
+	}
+
+	now := time.Date(2025, 1, 15, 10, 10, 0, 0, time.UTC)
+	v.CheckStaleness(now.Add(-time.Minute), now)
+	if !v.Ongoing || !strings.Contains(v.Steps[len(v.Steps)-1], "within the 2m0s staleness threshold") {
+		t.Errorf("a minute's quiet: ongoing %v, step %q", v.Ongoing, v.Steps[len(v.Steps)-1])
+	}
+	v.CheckStaleness(now.Add(-5*time.Minute), now)
+	if v.Ongoing || !strings.Contains(v.Steps[len(v.Steps)-1], "last write 5m0s ago, past the 2m0s staleness threshold") {
+		t.Errorf("five minutes' quiet: ongoing %v, step %q", v.Ongoing, v.Steps[len(v.Steps)-1])
+	}
+
+	withOngoing(t, parser.OngoingRules{Staleness: 10 * time.Minute})
+	v = parser.ExplainOngoing(pendingTaskChunks())
+	v.CheckStaleness(now.Add(-5*time.Minute), now)
+	if !v.Ongoing {
+		t.Error("five minutes is within a 10m threshold")
+	}
+}
+
+func TestValidateEndingEvents(t *testing.T) {
+	if err := parser.ValidateEndingEvents([]string{"text", "exitPlanMode"}); err != nil {
+		t.Error(err)
+	}
+	if err := parser.ValidateEndingEvents([]string{"txt"}); err == nil {
+		t.Error("want an error for an unknown event")
+	}
+}
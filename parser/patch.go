@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// FilePatch is the change an Edit, MultiEdit, or Write made, as Claude Code
+// recorded it in the tool result: unified diff hunks against the file as it
+// was just before the call. It holds even after the working tree has moved
+// on.
+type FilePatch struct {
+	Path   string
+	Create bool // Write of a new file; Hunks add every line
+	Hunks  []PatchHunk
+}
+
+// PatchHunk is one hunk of a unified diff. Lines carry their " ", "-", or
+// "+" prefix.
+type PatchHunk struct {
+	OldStart int      `json:"oldStart"`
+	OldLines int      `json:"oldLines"`
+	NewStart int      `json:"newStart"`
+	NewLines int      `json:"newLines"`
+	Lines    []string `json:"lines"`
+}
+
+// parseFilePatch reads the patch from an Edit, MultiEdit, or Write
+// toolUseResult. A created file records no hunks, only its content, so its
+// single all-added hunk is built here. Returns nil for other results.
+func parseFilePatch(raw json.RawMessage) *FilePatch {
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil
+	}
+	var r struct {
+		Type            string      `json:"type"` // Write: "create" or "update"
+		FilePath        string      `json:"filePath"`
+		Content         string      `json:"content"`
+		StructuredPatch []PatchHunk `json:"structuredPatch"`
+	}
+	if json.Unmarshal(raw, &r) != nil || r.FilePath == "" {
+		return nil
+	}
+	p := &FilePatch{Path: r.FilePath, Hunks: r.StructuredPatch}
+	if r.Type == "create" {
+		p.Create = true
+		if len(p.Hunks) == 0 && r.Content != "" {
+			lines := strings.Split(strings.TrimSuffix(r.Content, "\n"), "\n")
+			h := PatchHunk{NewStart: 1, NewLines: len(lines)}
+			for _, line := range lines {
+				h.Lines = append(h.Lines, "+"+line)
+			}
+			p.Hunks = []PatchHunk{h}
+		}
+	}
+	if len(p.Hunks) == 0 && !p.Create {
+		return nil
+	}
+	return p
+}
@@ -0,0 +1,51 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestClassify_ToolResultPatch(t *testing.T) {
+	result := func(toolUseResult string) *parser.FilePatch {
+		t.Helper()
+		e := makeEntry("user", "u1", "2025-01-15T10:00:00.000Z",
+			json.RawMessage(`[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]`),
+			func(e *parser.Entry) { e.ToolUseResult = json.RawMessage(toolUseResult) })
+		msg, ok := parser.Classify(e)
+		if !ok {
+			t.Fatal("expected Classify to succeed")
+		}
+		ai, isAI := msg.(parser.AIMsg)
+		if !isAI || len(ai.Blocks) != 1 {
+			t.Fatalf("got %#v, want an AIMsg with one block", msg)
+		}
+		return ai.Blocks[0].Patch
+	}
+
+	edit := result(`{"filePath":"/repo/a.go","oldString":"x","newString":"y","structuredPatch":[{"oldStart":3,"oldLines":1,"newStart":3,"newLines":1,"lines":["-x","+y"]}]}`)
+	if edit == nil || edit.Path != "/repo/a.go" || edit.Create || len(edit.Hunks) != 1 {
+		t.Fatalf("Edit patch = %+v", edit)
+	}
+	if h := edit.Hunks[0]; h.OldStart != 3 || h.NewLines != 1 || !slices.Equal(h.Lines, []string{"-x", "+y"}) {
+		t.Errorf("Edit hunk = %+v", h)
+	}
+
+	create := result(`{"type":"create","filePath":"/repo/new.go","content":"package x\n\nfunc F() {}\n","structuredPatch":[]}`)
+	if create == nil || !create.Create || len(create.Hunks) != 1 {
+		t.Fatalf("create patch = %+v", create)
+	}
+	if h := create.Hunks[0]; h.OldStart != 0 || h.NewStart != 1 || h.NewLines != 3 ||
+		!slices.Equal(h.Lines, []string{"+package x", "+", "+func F() {}"}) {
+		t.Errorf("create hunk = %+v", h)
+	}
+
+	if p := result(`{"stdout":"ok","stderr":""}`); p != nil {
+		t.Errorf("Bash result patch = %+v, want nil", p)
+	}
+	if p := result(`"Error: file not found"`); p != nil {
+		t.Errorf("string result patch = %+v, want nil", p)
+	}
+}
@@ -33,8 +33,17 @@ var (
 	reBashInput         = regexp.MustCompile(`(?is)<bash-input>(.*?)</bash-input>`)
 	reTaskNotifySummary = regexp.MustCompile(`(?is)<summary>(.*?)</summary>`)
 	reTaskNotifyStatus  = regexp.MustCompile(`(?is)<status>(.*?)</status>`)
+	reTaskNotifyID      = regexp.MustCompile(`(?is)<task-id>(.*?)</task-id>`)
 )
 
+// reAPIErrorText matches the final error text Claude Code shows when a
+// request fails for good: "API Error: 529 {json}" or "API Error: message".
+var reAPIErrorText = regexp.MustCompile(`(?s)^API Error:?\s*(\d{3})?\s*(.*)$`)
+
+// reQueuedReminder matches the system reminder older Claude Code versions
+// used to deliver a prompt the user sent while Claude was working.
+var reQueuedReminder = regexp.MustCompile(`(?s)^<system-reminder>\s*The user sent the following message:\n(.*?)\n\nPlease address this message and continue with your tasks\.\s*</system-reminder>$`)
+
 // Teammate message regexes -- used by classify.go, session.go, and subagent.go.
 var (
 	teammateMessageRe  = regexp.MustCompile(`^<teammate-message\s+teammate_id="[^"]+"`)
@@ -45,6 +54,25 @@ var (
 	teammateProtocolRe = regexp.MustCompile(`^\s*\{\s*"type"\s*:\s*"(idle_notification|shutdown_approved|shutdown_request|teammate_terminated|task_assignment)"`)
 )
 
+// Prompt-time attachment regexes -- used by classify.go.
+// An @-mention injects a pair of meta user entries: the synthetic tool call
+// and its result.
+var (
+	reAttachmentCall   = regexp.MustCompile(`(?s)^Called the (\w+) tool with the following input: (\{.*\})\s*$`)
+	reAttachmentResult = regexp.MustCompile(`(?s)^Result of calling the (\w+) tool:\s*(.*)$`)
+)
+
+// Hook output regexes -- used by classify.go.
+// Hook system entries read "PreToolUse:Bash [~/hooks/guard.sh] failed with
+// non-blocking status code 1: <stderr>". The matcher and command are optional,
+// but the event is followed by at least one of them or a colon (group 2), so
+// prose that starts with "Stopped" or "Notification" isn't taken for a hook.
+var (
+	reHookOutput = regexp.MustCompile(`(?s)^(PreToolUse|PostToolUse|PostToolUseFailure|UserPromptSubmit|Notification|Stop|SubagentStop|SubagentStart|PreCompact|SessionStart|SessionEnd)\b((?::(\S+))?(?:\s+\[([^\]]*)\])?(?:\s*:)?)\s*(.*)$`)
+	reHookFailed = regexp.MustCompile(`(?i)\b(failed|blocking error|denied|blocked)\b`)
+	reANSI       = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
 // contentBlockJSON is the common shape for partially unmarshaling JSONL content blocks.
 // Different callers use different subsets of fields; unused fields unmarshal to zero values.
 type contentBlockJSON struct {
@@ -57,6 +85,7 @@ type contentBlockJSON struct {
 	ToolUseID string          `json:"tool_use_id"`
 	Content   json.RawMessage `json:"content"`
 	IsError   bool            `json:"is_error"`
+	Citations json.RawMessage `json:"citations"`
 }
 
 // textBlockJSON is a minimal content block for extracting text content.
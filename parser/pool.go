@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// ScanWorkers bounds how many session files are scanned concurrently.
+// Scans are mostly I/O, so a few more workers than CPUs keeps the disk busy
+// without opening hundreds of files at once.
+var ScanWorkers = min(2*runtime.NumCPU(), 16)
+
+// ForEachParallel calls fn for each index in [0, n) on at most workers
+// goroutines and waits for them to finish. Once ctx is cancelled no further
+// indexes are handed out; calls already running finish, and ctx.Err() is
+// returned. fn must be safe to call concurrently; writing to its own slot
+// of a pre-sized slice is the usual pattern.
+func ForEachParallel(ctx context.Context, n, workers int, fn func(i int)) error {
+	workers = max(min(workers, n), 1)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := range n {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		case next <- i:
+		}
+	}
+	close(next)
+	wg.Wait()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
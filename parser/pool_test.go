@@ -0,0 +1,70 @@
+package parser_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestForEachParallel(t *testing.T) {
+	const n = 50
+	var running, peak atomic.Int32
+	seen := make([]int, n)
+	err := parser.ForEachParallel(context.Background(), n, 4, func(i int) {
+		cur := running.Add(1)
+		for {
+			p := peak.Load()
+			if cur <= p || peak.CompareAndSwap(p, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		seen[i]++
+		running.Add(-1)
+	})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	for i, c := range seen {
+		if c != 1 {
+			t.Errorf("index %d visited %d times, want 1", i, c)
+		}
+	}
+	if p := peak.Load(); p > 4 {
+		t.Errorf("peak concurrency = %d, want <= 4", p)
+	}
+}
+
+func TestForEachParallel_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	calls := 0
+	err := parser.ForEachParallel(ctx, 1000, 2, func(i int) {
+		mu.Lock()
+		calls++
+		if calls == 10 {
+			cancel()
+		}
+		mu.Unlock()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if calls >= 1000 {
+		t.Errorf("calls = %d, want scanning to stop after cancel", calls)
+	}
+}
+
+func TestDiscoverAllProjectSessionsContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sessions, err := parser.DiscoverAllProjectSessionsContext(ctx, []string{"testdata"})
+	if !errors.Is(err, context.Canceled) || sessions != nil {
+		t.Errorf("got %d sessions, err %v; want nil, context.Canceled", len(sessions), err)
+	}
+}
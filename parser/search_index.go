@@ -0,0 +1,320 @@
+package parser
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// searchIndexVersion invalidates saved filters when the format or the
+	// trigram extraction changes.
+	searchIndexVersion = 2
+
+	// bloomHashes is the number of bit positions set per trigram.
+	bloomHashes = 4
+
+	// minBloomWords is the smallest filter (64 Ki bits, 8 KiB).
+	minBloomWords = 1 << 10
+
+	// bloomBitsPerTrigram is a filter's capacity: at 10 bits per trigram and
+	// four hashes, about one false positive per hundred lookups when full.
+	bloomBitsPerTrigram = 10
+)
+
+// SearchIndex keeps a Bloom filter of the trigrams in each session file under
+// a cache directory, so project-wide search can skip sessions that cannot
+// contain a query without parsing them. Filters grow with their session:
+// each Update reads only the bytes appended since the last one.
+//
+// Trigrams come from the lowercased letter-and-digit runs of the session's
+// searchable text, as its SearchText returns it. A query is tested with the
+// trigrams of its own runs, so punctuation and spacing don't hide a match.
+type SearchIndex struct {
+	dir  string
+	text SearchText
+}
+
+// SearchText returns the text a search can match in the complete lines of
+// the session at path from offset on, and the offset after the last of them.
+// A trailing line still being written is left for the next call. A viewer
+// that searches text it builds from the session ("model changed: …", a
+// failed request's status line) passes one returning that text, so the
+// filter never rules out a session the search would find.
+type SearchText func(path string, offset int64) (texts []string, next int64, err error)
+
+// SessionFilter is the saved index of one session file.
+type SessionFilter struct {
+	Version int
+	Path    string
+	Offset  int64    // bytes of the file indexed so far (complete lines only)
+	Count   int      // trigrams that set at least one new bit
+	Bits    []uint64 // Bloom filter
+}
+
+// NewSearchIndex returns an index that stores its filters in dir and reads
+// sessions with text, or with JSONSearchText when text is nil.
+func NewSearchIndex(dir string, text SearchText) *SearchIndex {
+	if text == nil {
+		text = JSONSearchText
+	}
+	return &SearchIndex{dir: dir, text: text}
+}
+
+// DefaultSearchIndexDir returns the index location under the user cache
+// directory (~/.cache/tail-claude/index on Linux).
+func DefaultSearchIndexDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tail-claude", "index"), nil
+}
+
+// Update brings the filter for the session at path up to date and saves it.
+// Appended lines are added to the saved filter; a file that shrank, or a
+// filter that filled past its capacity, is rebuilt from the start.
+func (ix *SearchIndex) Update(path string) (*SessionFilter, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	f := ix.load(path)
+	if f != nil && f.Offset == info.Size() {
+		return f, nil
+	}
+	if f == nil || f.Offset > info.Size() {
+		f = &SessionFilter{Version: searchIndexVersion, Path: path}
+	}
+
+	hashes, next, err := ix.trigramHashes(path, f.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if f.Bits == nil || f.Count+len(hashes) > len(f.Bits)*64/bloomBitsPerTrigram {
+		if f.Offset > 0 {
+			// Over capacity: rebuild at a size that fits the whole file.
+			f = &SessionFilter{Version: searchIndexVersion, Path: path}
+			if hashes, next, err = ix.trigramHashes(path, 0); err != nil {
+				return nil, err
+			}
+		}
+		f.Bits = make([]uint64, bloomWords(len(hashes)))
+	}
+	for h := range hashes {
+		if f.add(h) {
+			f.Count++
+		}
+	}
+	f.Offset = next
+
+	if err := ix.save(f); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// MightContain reports whether the session may contain query
+// (case-insensitive). False means it certainly doesn't. Queries without a
+// three-character word can't be checked and always return true.
+func (f *SessionFilter) MightContain(query string) bool {
+	if f == nil || len(f.Bits) == 0 {
+		return true
+	}
+	for h := range textTrigramHashes(query, nil) {
+		if !f.test(h) {
+			return false
+		}
+	}
+	return true
+}
+
+// add sets the bits for h, reporting whether any was newly set.
+func (f *SessionFilter) add(h uint64) bool {
+	size := uint64(len(f.Bits) * 64)
+	h1, h2 := h, h>>33|1
+	added := false
+	for i := range uint64(bloomHashes) {
+		bit := (h1 + i*h2) % size
+		if f.Bits[bit/64]&(1<<(bit%64)) == 0 {
+			f.Bits[bit/64] |= 1 << (bit % 64)
+			added = true
+		}
+	}
+	return added
+}
+
+// test reports whether all bits for h are set.
+func (f *SessionFilter) test(h uint64) bool {
+	size := uint64(len(f.Bits) * 64)
+	h1, h2 := h, h>>33|1
+	for i := range uint64(bloomHashes) {
+		bit := (h1 + i*h2) % size
+		if f.Bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomWords returns the filter size in 64-bit words for n trigrams: a power
+// of two with room for four times as many, so appends rarely force a rebuild.
+func bloomWords(n int) int {
+	words := minBloomWords
+	for words*64 < 4*n*bloomBitsPerTrigram {
+		words *= 2
+	}
+	return words
+}
+
+// trigramHashes returns the hashes of the trigrams in the searchable text of
+// path from offset on, and the offset after it.
+func (ix *SearchIndex) trigramHashes(path string, offset int64) (map[uint64]struct{}, int64, error) {
+	texts, next, err := ix.text(path, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	hashes := make(map[uint64]struct{})
+	for _, t := range texts {
+		textTrigramHashes(t, hashes)
+	}
+	return hashes, next, nil
+}
+
+// JSONSearchText is the SearchText of every JSON string (keys included) in
+// the session's lines, and of lines that aren't JSON.
+func JSONSearchText(path string, offset int64) ([]string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+
+	var texts []string
+	r := bufio.NewReaderSize(file, initialBufSize)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return texts, offset, nil
+			}
+			return nil, 0, err
+		}
+		offset += int64(len(line))
+		var v any
+		if json.Unmarshal(line, &v) != nil {
+			texts = append(texts, string(line))
+			continue
+		}
+		texts = jsonStrings(v, texts)
+	}
+}
+
+// jsonStrings appends every string and key in v to into.
+func jsonStrings(v any, into []string) []string {
+	switch v := v.(type) {
+	case string:
+		into = append(into, v)
+	case []any:
+		for _, e := range v {
+			into = jsonStrings(e, into)
+		}
+	case map[string]any:
+		for k, e := range v {
+			into = jsonStrings(e, append(into, k))
+		}
+	}
+	return into
+}
+
+// textTrigramHashes adds the hashes of every three-rune window inside the
+// lowercased letter-and-digit runs of s to into (allocated when nil).
+func textTrigramHashes(s string, into map[uint64]struct{}) map[uint64]struct{} {
+	if into == nil {
+		into = make(map[uint64]struct{})
+	}
+	var run []rune
+	flush := func() {
+		for i := 0; i+3 <= len(run); i++ {
+			into[trigramHash(run[i:i+3])] = struct{}{}
+		}
+		run = run[:0]
+	}
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			run = append(run, r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return into
+}
+
+// trigramHash is FNV-1a over the UTF-8 encoding of three runes, inlined to
+// avoid allocating a hasher per trigram.
+func trigramHash(tri []rune) uint64 {
+	const prime = 1099511628211
+	h := uint64(14695981039346656037)
+	var buf [utf8.UTFMax * 3]byte
+	for _, b := range utf8.AppendRune(utf8.AppendRune(utf8.AppendRune(buf[:0], tri[0]), tri[1]), tri[2]) {
+		h ^= uint64(b)
+		h *= prime
+	}
+	return h
+}
+
+// filterPath returns where the filter for a session file is saved.
+func (ix *SearchIndex) filterPath(path string) string {
+	sum := sha1.Sum([]byte(path))
+	return filepath.Join(ix.dir, hex.EncodeToString(sum[:])+".gob")
+}
+
+// load reads the saved filter for path, or returns nil when there is none
+// or it was written by another version.
+func (ix *SearchIndex) load(path string) *SessionFilter {
+	file, err := os.Open(ix.filterPath(path))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	var f SessionFilter
+	if gob.NewDecoder(file).Decode(&f) != nil || f.Version != searchIndexVersion || f.Path != path {
+		return nil
+	}
+	return &f
+}
+
+// save writes f atomically so concurrent updates of the same session never
+// leave a torn file.
+func (ix *SearchIndex) save(f *SessionFilter) error {
+	if err := os.MkdirAll(ix.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(ix.dir, "filter-*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(tmp).Encode(f); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), ix.filterPath(f.Path))
+}
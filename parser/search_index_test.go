@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchIndex(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	write := func(content string, flag int) {
+		t.Helper()
+		f, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	ix := NewSearchIndex(filepath.Join(dir, "index"), nil)
+	update := func() *SessionFilter {
+		t.Helper()
+		f, err := ix.Update(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	write(`{"type":"user","message":{"content":"Fix the \"ConnectionRefused\" error"}}`+"\n", os.O_TRUNC)
+	f := update()
+	for query, want := range map[string]bool{
+		"connectionrefused":      true,
+		`"ConnectionRefused" er`: true, // punctuation and short words don't count
+		"message":                true, // keys are indexed too
+		"timeout":                false,
+		"zz":                     true, // too short to check
+	} {
+		if got := f.MightContain(query); got != want {
+			t.Errorf("MightContain(%q) = %v, want %v", query, got, want)
+		}
+	}
+
+	// Appends are indexed incrementally; a partial line waits.
+	indexed := f.Offset
+	write(`{"type":"assistant","message":{"content":"raised the timeout"}}`+"\n"+`{"type":"user","mess`, os.O_APPEND)
+	f = update()
+	if !f.MightContain("timeout") || !f.MightContain("connectionrefused") {
+		t.Error("appended text should be found along with the old")
+	}
+	if f.Offset <= indexed {
+		t.Errorf("offset = %d, want past %d", f.Offset, indexed)
+	}
+	info, _ := os.Stat(path)
+	if f.Offset == info.Size() {
+		t.Error("the partial trailing line should not be indexed yet")
+	}
+
+	// Completing the line indexes it.
+	write(`age":"x"}`+"\n", os.O_APPEND)
+	if f = update(); f.Offset != info.Size()+int64(len(`age":"x"}`+"\n")) {
+		t.Errorf("offset = %d after completing the line", f.Offset)
+	}
+
+	// A rewritten, shorter file is indexed from scratch.
+	write(`{"type":"user","message":{"content":"brand new"}}`+"\n", os.O_TRUNC)
+	f = update()
+	if f.MightContain("timeout") || !f.MightContain("brand") {
+		t.Error("a truncated file should be reindexed from the start")
+	}
+}
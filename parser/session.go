@@ -1,12 +1,15 @@
 package parser
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // SessionInfo holds metadata about a discovered session file for the picker.
@@ -23,6 +26,7 @@ type SessionInfo struct {
 	Cwd            string // working directory from session entries
 	GitBranch      string // git branch from session entries
 	PermissionMode string // last permission mode: "default", "acceptEdits", "bypassPermissions", "plan"
+	Compactions    int    // context compactions (compact_boundary entries)
 }
 
 // SessionMeta holds session-level metadata extracted from a JSONL file.
@@ -37,7 +41,12 @@ type SessionMeta struct {
 // ExtractSessionMeta returns session-level metadata from a JSONL file.
 // Reads the full file to capture the last permissionMode (mode can change mid-session).
 func ExtractSessionMeta(path string) SessionMeta {
-	m := scanSessionMetadata(path)
+	return ExtractSourceMeta(FileSource(path))
+}
+
+// ExtractSourceMeta is ExtractSessionMeta for any session source.
+func ExtractSourceMeta(src SessionSource) SessionMeta {
+	m := scanSourceMetadata(src)
 	return SessionMeta{
 		Cwd:            m.cwd,
 		GitBranch:      m.gitBranch,
@@ -59,27 +68,67 @@ func ReadSession(path string) ([]Chunk, error) {
 // and any error. This is the building block for live tailing -- the caller
 // accumulates classified messages and re-runs BuildChunks after each call.
 func ReadSessionIncremental(path string, offset int64) ([]ClassifiedMsg, int64, error) {
-	f, err := os.Open(path)
+	msgs, _, next, err := readSessionRange(FileSource(path), offset, -1)
+	return msgs, next, err
+}
+
+// ReadSessionIncrementalOffsets is ReadSessionIncremental that also returns,
+// for each message, the byte offset of the line it came from. Callers that
+// drop old messages keep the offsets so they can re-read them later with
+// ReadSessionRange.
+func ReadSessionIncrementalOffsets(path string, offset int64) ([]ClassifiedMsg, []int64, int64, error) {
+	return readSessionRange(FileSource(path), offset, -1)
+}
+
+// ReadSourceIncrementalOffsets is ReadSessionIncrementalOffsets for any
+// session source.
+func ReadSourceIncrementalOffsets(src SessionSource, offset int64) ([]ClassifiedMsg, []int64, int64, error) {
+	return readSessionRange(src, offset, -1)
+}
+
+// ReadSessionRange reads the messages whose lines start in [from, to), with
+// their offsets. Used to reload messages evicted from a bounded tail.
+func ReadSessionRange(path string, from, to int64) ([]ClassifiedMsg, []int64, error) {
+	return ReadSourceRange(FileSource(path), from, to)
+}
+
+// ReadSourceRange is ReadSessionRange for any session source.
+func ReadSourceRange(src SessionSource, from, to int64) ([]ClassifiedMsg, []int64, error) {
+	msgs, offsets, _, err := readSessionRange(src, from, to)
+	return msgs, offsets, err
+}
+
+// readSessionRange reads lines starting at offset, stopping before the first
+// line that starts at or after limit (limit < 0 reads to EOF). Returns the
+// messages, their line offsets, and the offset after the last line read.
+func readSessionRange(src SessionSource, offset, limit int64) ([]ClassifiedMsg, []int64, int64, error) {
+	f, err := src.Open(offset)
 	if err != nil {
-		return nil, offset, err
+		return nil, nil, offset, err
 	}
 	defer f.Close()
 
-	if _, err := f.Seek(offset, 0); err != nil {
-		return nil, offset, err
-	}
-
 	lr := newLineReader(f)
 
 	var msgs []ClassifiedMsg
+	var offsets []int64
 
 	for {
+		// Blank lines are skipped inside next, so start may point just
+		// before the line; re-reading from it yields the same message.
+		start := offset + lr.BytesRead()
+		if limit >= 0 && start >= limit {
+			break
+		}
 		line, ok := lr.next()
 		if !ok {
 			break
 		}
 		entry, ok := ParseEntry([]byte(line))
 		if !ok {
+			if !json.Valid([]byte(line)) {
+				Log.Warn("skipped malformed line", "source", src.Name(), "offset", start, "bytes", len(line))
+			}
 			continue
 		}
 		msg, ok := Classify(entry)
@@ -87,12 +136,16 @@ func ReadSessionIncremental(path string, offset int64) ([]ClassifiedMsg, int64,
 			continue
 		}
 		msgs = append(msgs, msg)
+		offsets = append(offsets, start)
+	}
+	if lr.oversized > 0 {
+		Log.Warn("skipped oversized lines", "source", src.Name(), "count", lr.oversized, "limit", maxLineSize)
 	}
 	if err := lr.Err(); err != nil {
-		return msgs, offset + lr.BytesRead(), err
+		return msgs, offsets, offset + lr.BytesRead(), err
 	}
 
-	return msgs, offset + lr.BytesRead(), nil
+	return msgs, offsets, offset + lr.BytesRead(), nil
 }
 
 // ProjectDirForPath returns the Claude CLI projects directory for an absolute
@@ -164,20 +217,46 @@ func ResolveGitRoot(dir string) string {
 
 // DiscoverProjectSessions finds all session .jsonl files in a project directory,
 // scans each for metadata, and returns them sorted by modification time (newest first).
-// Subagent files (agent_*) are excluded.
+// Subagent files (agent_*) and sessions older than Discovery.MaxAge are excluded.
 func DiscoverProjectSessions(projectDir string) ([]SessionInfo, error) {
-	return discoverSessions(projectDir, func(path string, _ time.Time) sessionMetadata {
-		return scanSessionMetadata(path)
-	})
+	return discoverSessions(context.Background(), Discovery, projectDir, scanUncached)
 }
 
 // DiscoverAllProjectSessions finds sessions across multiple project directories
-// (main + worktree dirs). Calls DiscoverProjectSessions on each, merges results,
-// and sorts by ModTime descending. Missing directories are silently skipped.
+// (main + worktree dirs), widened by Discovery to its subdirectories and extra
+// directories. Calls DiscoverProjectSessions on each, merges results, and sorts
+// by ModTime descending. Missing directories are silently skipped.
 func DiscoverAllProjectSessions(projectDirs []string) ([]SessionInfo, error) {
+	return DiscoverAllProjectSessionsContext(context.Background(), projectDirs)
+}
+
+// DiscoverAllProjectSessionsContext is DiscoverAllProjectSessions with
+// cancellation: once ctx is done, scanning stops and ctx.Err() is returned.
+func DiscoverAllProjectSessionsContext(ctx context.Context, projectDirs []string) ([]SessionInfo, error) {
+	return discoverAllSessions(ctx, Discovery, projectDirs, scanUncached)
+}
+
+// DiscoverAllProjectSessionsIn is DiscoverAllProjectSessionsContext under
+// scope rather than Discovery, for views that look past the configured
+// scope, such as the age cutoff.
+func DiscoverAllProjectSessionsIn(ctx context.Context, scope DiscoveryScope, projectDirs []string) ([]SessionInfo, error) {
+	return discoverAllSessions(ctx, scope, projectDirs, scanUncached)
+}
+
+// scanUncached scans a session file without consulting a cache.
+func scanUncached(path string, _ time.Time) sessionMetadata {
+	return scanSessionMetadata(path)
+}
+
+// discoverAllSessions merges discoverSessions across the directories scope
+// reads for projectDirs, newest first.
+func discoverAllSessions(ctx context.Context, scope DiscoveryScope, projectDirs []string, scan scanFn) ([]SessionInfo, error) {
 	var all []SessionInfo
-	for _, dir := range projectDirs {
-		sessions, err := DiscoverProjectSessions(dir)
+	for _, dir := range scope.dirs(projectDirs) {
+		sessions, err := discoverSessions(ctx, scope, dir, scan)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err != nil {
 			continue // missing dir or permission error -- skip
 		}
@@ -192,18 +271,24 @@ func DiscoverAllProjectSessions(projectDirs []string) ([]SessionInfo, error) {
 }
 
 // scanFn returns session metadata for a given file path and modTime.
+// It is called concurrently from ScanWorkers goroutines.
 type scanFn func(path string, modTime time.Time) sessionMetadata
 
 // discoverSessions is the shared directory-walk logic for DiscoverProjectSessions
 // and its cached variant. The scan function determines how metadata is obtained
-// (direct scan vs cache lookup).
-func discoverSessions(projectDir string, scan scanFn) ([]SessionInfo, error) {
+// (direct scan vs cache lookup). Files are scanned in parallel, bounded by
+// ScanWorkers.
+func discoverSessions(ctx context.Context, scope DiscoveryScope, projectDir string, scan scanFn) ([]SessionInfo, error) {
 	entries, err := os.ReadDir(projectDir)
 	if err != nil {
 		return nil, err
 	}
 
-	var sessions []SessionInfo
+	type candidate struct {
+		name    string
+		modTime time.Time
+	}
+	var candidates []candidate
 	for _, de := range entries {
 		if de.IsDir() {
 			continue
@@ -217,12 +302,23 @@ func discoverSessions(projectDir string, scan scanFn) ([]SessionInfo, error) {
 		}
 
 		info, err := de.Info()
-		if err != nil {
+		if err != nil || scope.tooOld(info.ModTime()) {
 			continue
 		}
+		candidates = append(candidates, candidate{name: name, modTime: info.ModTime()})
+	}
+
+	metas := make([]sessionMetadata, len(candidates))
+	err = ForEachParallel(ctx, len(candidates), ScanWorkers, func(i int) {
+		metas[i] = scan(filepath.Join(projectDir, candidates[i].name), candidates[i].modTime)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		path := filepath.Join(projectDir, name)
-		meta := scan(path, info.ModTime())
+	var sessions []SessionInfo
+	for i, c := range candidates {
+		meta := metas[i]
 
 		// Skip ghost sessions (e.g. only file-history-snapshot entries).
 		if meta.turnCount == 0 {
@@ -230,14 +326,14 @@ func discoverSessions(projectDir string, scan scanFn) ([]SessionInfo, error) {
 		}
 
 		isOngoing := meta.isOngoing
-		if isOngoing && time.Since(info.ModTime()) > OngoingStalenessThreshold {
+		if isOngoing && Ongoing.Stale(c.modTime, time.Now()) {
 			isOngoing = false
 		}
 
 		sessions = append(sessions, SessionInfo{
-			Path:           path,
-			SessionID:      strings.TrimSuffix(name, ".jsonl"),
-			ModTime:        info.ModTime(),
+			Path:           filepath.Join(projectDir, c.name),
+			SessionID:      strings.TrimSuffix(c.name, ".jsonl"),
+			ModTime:        c.modTime,
 			FirstMessage:   meta.firstMsg,
 			TurnCount:      meta.turnCount,
 			IsOngoing:      isOngoing,
@@ -247,6 +343,7 @@ func discoverSessions(projectDir string, scan scanFn) ([]SessionInfo, error) {
 			Cwd:            meta.cwd,
 			GitBranch:      meta.gitBranch,
 			PermissionMode: meta.permissionMode,
+			Compactions:    meta.compactions,
 		})
 	}
 
@@ -262,12 +359,14 @@ type sessionMetadata struct {
 	firstMsg       string
 	turnCount      int
 	isOngoing      bool
+	ongoingWhy     OngoingVerdict // how isOngoing was decided, staleness aside
 	totalTokens    int
 	durationMs     int64
 	model          string
 	cwd            string // first non-empty cwd from any entry
 	gitBranch      string // first non-empty gitBranch from any entry
 	permissionMode string // last non-empty permissionMode (mode can change mid-session)
+	compactions    int
 }
 
 // scanSessionMetadata extracts all session metadata in a single streaming pass.
@@ -278,7 +377,23 @@ type sessionMetadata struct {
 // Ongoing detection ported from claude-devtools' analyzeSessionFileMetadata (jsonl.ts:437-499).
 // Turn counting ported from claude-devtools' analyzeSessionFileMetadata (jsonl.ts:374-385).
 func scanSessionMetadata(path string) sessionMetadata {
-	f, err := os.Open(path)
+	return scanSourceMetadata(FileSource(path))
+}
+
+// ScanOngoing is the picker's ongoing verdict for a session file, with its
+// reasoning: the one-pass metadata scan rather than ExplainOngoing over
+// built chunks, so the two can disagree. Staleness is applied.
+func ScanOngoing(path string) OngoingVerdict {
+	why := scanSessionMetadata(path).ongoingWhy
+	if info, err := os.Stat(path); err == nil {
+		why.CheckStaleness(info.ModTime(), time.Now())
+	}
+	return why
+}
+
+// scanSourceMetadata is scanSessionMetadata for any session source.
+func scanSourceMetadata(src SessionSource) sessionMetadata {
+	f, err := src.Open(0)
 	if err != nil {
 		return sessionMetadata{}
 	}
@@ -346,6 +461,10 @@ func scanSessionMetadata(path string) sessionMetadata {
 			meta.permissionMode = raw.PermissionMode
 		}
 
+		if raw.Type == "system" && raw.Subtype == "compact_boundary" {
+			meta.compactions++
+		}
+
 		// --- Turn counting (matches isParsedUserChunkMessage + AI pairing) ---
 		if isUserChunkForTurnCount(&raw) {
 			meta.turnCount++
@@ -376,7 +495,7 @@ func scanSessionMetadata(path string) sessionMetadata {
 		}
 
 		// --- Preview extraction (unchanged from scanSessionPreview) ---
-		if previewFound || linesRead > maxPreviewLines || raw.Type != "user" {
+		if previewFound || linesRead > maxPreviewLines || raw.Type != "user" || raw.IsCompactSummary {
 			continue
 		}
 
@@ -405,7 +524,12 @@ func scanSessionMetadata(path string) sessionMetadata {
 			continue
 		}
 		if len(sanitized) > 500 {
-			sanitized = sanitized[:500]
+			// Cut at a rune boundary; a split one would show as U+FFFD.
+			cut := 500
+			for cut > 0 && !utf8.RuneStart(sanitized[cut]) {
+				cut--
+			}
+			sanitized = sanitized[:cut]
 		}
 		meta.firstMsg = sanitized
 		previewFound = true
@@ -429,16 +553,28 @@ func scanSessionMetadata(path string) sessionMetadata {
 
 	// Finalize ongoing detection.
 	// Activity-based: is there AI activity after the last ending event?
-	if lastEndingIndex == -1 {
-		meta.isOngoing = hasAnyOngoingActivity
-	} else {
-		meta.isOngoing = hasActivityAfterLastEnding
+	why := &meta.ongoingWhy
+	why.add("rules: %s", Ongoing)
+	switch {
+	case lastEndingIndex == -1 && hasAnyOngoingActivity:
+		why.Override(true, "no ending event, and AI activity seen: ongoing")
+	case lastEndingIndex == -1:
+		why.add("no ending event and no AI activity: not ongoing")
+	case hasActivityAfterLastEnding:
+		why.Override(true, "AI activity after the last ending event: ongoing")
+	default:
+		why.add("no AI activity after the last ending event: not ongoing")
 	}
 	// Pending tool calls override: a tool_use without a matching tool_result
 	// means work is still in progress, even if text output appeared after it.
-	if !meta.isOngoing && len(pendingToolIDs) > 0 {
-		meta.isOngoing = true
+	if !why.Ongoing && len(pendingToolIDs) > 0 {
+		if Ongoing.IgnorePending {
+			why.add("%d tool call(s) without a result, ignored by the rules", len(pendingToolIDs))
+		} else {
+			why.Override(true, fmt.Sprintf("%d tool call(s) still awaiting a result: ongoing", len(pendingToolIDs)))
+		}
 	}
+	meta.isOngoing = why.Ongoing
 
 	// Finalize duration.
 	if !firstTS.IsZero() && !lastTS.IsZero() {
@@ -452,16 +588,18 @@ func scanSessionMetadata(path string) sessionMetadata {
 // It captures toolUseResult as raw JSON because the field can be either a
 // string or an object, and we need the raw value for rejection detection.
 type metadataScanEntry struct {
-	UUID           string          `json:"uuid"`
-	Type           string          `json:"type"`
-	Timestamp      string          `json:"timestamp"`
-	IsSidechain    bool            `json:"isSidechain"`
-	IsMeta         bool            `json:"isMeta"`
-	Cwd            string          `json:"cwd"`
-	GitBranch      string          `json:"gitBranch"`
-	PermissionMode string          `json:"permissionMode"`
-	ToolResult     json.RawMessage `json:"toolUseResult"`
-	Message        struct {
+	UUID             string          `json:"uuid"`
+	Type             string          `json:"type"`
+	Subtype          string          `json:"subtype"`
+	Timestamp        string          `json:"timestamp"`
+	IsSidechain      bool            `json:"isSidechain"`
+	IsMeta           bool            `json:"isMeta"`
+	IsCompactSummary bool            `json:"isCompactSummary"`
+	Cwd              string          `json:"cwd"`
+	GitBranch        string          `json:"gitBranch"`
+	PermissionMode   string          `json:"permissionMode"`
+	ToolResult       json.RawMessage `json:"toolUseResult"`
+	Message          struct {
 		Role    string          `json:"role"`
 		Content json.RawMessage `json:"content"`
 		Model   string          `json:"model"`
@@ -478,7 +616,7 @@ type metadataScanEntry struct {
 // type=user, isMeta=false, not teammate, not sidechain, has real user content,
 // and doesn't start with system output tags.
 func isUserChunkForTurnCount(e *metadataScanEntry) bool {
-	if e.Type != "user" || e.IsMeta || e.IsSidechain {
+	if e.Type != "user" || e.IsMeta || e.IsSidechain || e.IsCompactSummary {
 		return false
 	}
 
@@ -526,11 +664,17 @@ func scanOngoingAssistant(e *metadataScanEntry, activityIndex *int,
 				continue
 			}
 			if b.Name == "ExitPlanMode" {
+				if !Ongoing.ends(EndOnExitPlanMode) {
+					continue
+				}
 				*lastEndingIndex = *activityIndex
 				*hasAfter = false
 				*activityIndex++
 			} else if isShutdownApproval(b.Name, b.Input) {
 				shutdownIDs[b.ID] = true
+				if !Ongoing.ends(EndOnShutdownApproval) {
+					continue
+				}
 				*lastEndingIndex = *activityIndex
 				*hasAfter = false
 				*activityIndex++
@@ -543,7 +687,7 @@ func scanOngoingAssistant(e *metadataScanEntry, activityIndex *int,
 				*activityIndex++
 			}
 		case "text":
-			if strings.TrimSpace(b.Text) != "" {
+			if strings.TrimSpace(b.Text) != "" && Ongoing.ends(EndOnText) {
 				*lastEndingIndex = *activityIndex
 				*hasAfter = false
 				*activityIndex++
@@ -569,9 +713,11 @@ func scanOngoingUser(e *metadataScanEntry, activityIndex *int,
 			for id := range pendingToolIDs {
 				delete(pendingToolIDs, id)
 			}
-			*lastEndingIndex = *activityIndex
-			*hasAfter = false
-			*activityIndex++
+			if Ongoing.ends(EndOnInterruption) {
+				*lastEndingIndex = *activityIndex
+				*hasAfter = false
+				*activityIndex++
+			}
 		}
 		return
 	}
@@ -588,7 +734,10 @@ func scanOngoingUser(e *metadataScanEntry, activityIndex *int,
 				continue
 			}
 			delete(pendingToolIDs, b.ToolUseID)
-			if shutdownIDs[b.ToolUseID] || isRejection {
+			if shutdownIDs[b.ToolUseID] && !Ongoing.ends(EndOnShutdownApproval) {
+				continue // ignored, like the call
+			}
+			if shutdownIDs[b.ToolUseID] || isRejection && Ongoing.ends(EndOnInterruption) {
 				// Ending event.
 				*lastEndingIndex = *activityIndex
 				*hasAfter = false
@@ -607,9 +756,11 @@ func scanOngoingUser(e *metadataScanEntry, activityIndex *int,
 				for id := range pendingToolIDs {
 					delete(pendingToolIDs, id)
 				}
-				*lastEndingIndex = *activityIndex
-				*hasAfter = false
-				*activityIndex++
+				if Ongoing.ends(EndOnInterruption) {
+					*lastEndingIndex = *activityIndex
+					*hasAfter = false
+					*activityIndex++
+				}
 			}
 		}
 	}
@@ -638,6 +789,16 @@ type ongoingUserBlock struct {
 // when a user rejects a tool invocation.
 const toolUseRejectedMsg = "User rejected tool use"
 
+// toolUseRejectedResult is the start of the tool_result content Claude Code
+// sends the model when a user rejects a tool invocation.
+const toolUseRejectedResult = "The user doesn't want to proceed with this tool use"
+
+// IsToolRejection reports whether a tool result records the user rejecting
+// the call at the permission prompt.
+func IsToolRejection(result string) bool {
+	return strings.HasPrefix(result, toolUseRejectedResult) || result == toolUseRejectedMsg
+}
+
 // isToolUseRejection checks if a raw toolUseResult value equals the rejection string.
 func isToolUseRejection(raw json.RawMessage) bool {
 	if len(raw) == 0 {
@@ -1,8 +1,11 @@
 package parser_test
 
 import (
+	"bytes"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kylesnowschwartz/tail-claude/parser"
@@ -147,3 +150,56 @@ func TestReadSession_NoiseFiltered(t *testing.T) {
 		t.Errorf("AI ToolCalls = %d, want 1", len(ai.ToolCalls))
 	}
 }
+
+func TestReadSessionRange(t *testing.T) {
+	all, offsets, end, err := parser.ReadSessionIncrementalOffsets("testdata/minimal.jsonl", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) < 2 || len(offsets) != len(all) {
+		t.Fatalf("got %d messages with %d offsets, want matching counts >= 2", len(all), len(offsets))
+	}
+	if offsets[0] != 0 {
+		t.Errorf("offsets[0] = %d, want 0", offsets[0])
+	}
+
+	// Reading up to the second message's offset yields just the first.
+	head, headOffsets, err := parser.ReadSessionRange("testdata/minimal.jsonl", 0, offsets[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(head) != 1 || headOffsets[0] != 0 {
+		t.Errorf("range [0, %d) = %d messages, want 1", offsets[1], len(head))
+	}
+
+	// Reading from an offset resumes exactly at that message.
+	tail, _, tailEnd, err := parser.ReadSessionIncrementalOffsets("testdata/minimal.jsonl", offsets[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tail) != len(all)-1 || tailEnd != end {
+		t.Errorf("from offsets[1]: %d messages ending at %d, want %d ending at %d", len(tail), tailEnd, len(all)-1, end)
+	}
+}
+
+func TestReadSession_LogsMalformedLines(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(l *slog.Logger) { parser.Log = l }(parser.Log)
+	parser.Log = slog.New(slog.NewTextHandler(&buf, nil))
+
+	path := filepath.Join(t.TempDir(), "s.jsonl")
+	lines := `{"type":"user","uuid":"u1","timestamp":"2025-01-15T10:00:00Z","message":{"role":"user","content":"hi"}}
+{"type":"user","uuid":"u2",
+{"type":"file-history-snapshot","messageId":"m1"}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ReadSession(path); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if strings.Count(got, "skipped malformed line") != 1 || !strings.Contains(got, "offset=") {
+		t.Errorf("log = %q, want one malformed line with its offset", got)
+	}
+}
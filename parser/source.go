@@ -0,0 +1,331 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SessionSource is where a session transcript is read from: a file on the
+// local disk, a stream piped to stdin, a file on another machine over ssh,
+// or a URL. Reads start at a byte offset, so incremental reads and their
+// offset bookkeeping work the same for every backend.
+type SessionSource interface {
+	// Name identifies the source in messages: a path or URL.
+	Name() string
+	// Open returns the transcript from byte offset on. Reading past the
+	// end is not an error: there's just nothing new yet.
+	Open(offset int64) (io.ReadCloser, error)
+	// Size returns the transcript's current length in bytes.
+	Size() (int64, error)
+}
+
+// ParseSource returns the source a command-line argument names: "-" for
+// stdin, an http:// or https:// URL, ssh://[user@]host[:port]/path, or
+// otherwise a local path.
+func ParseSource(spec string) (SessionSource, error) {
+	switch {
+	case spec == "-":
+		return NewStreamSource("stdin", os.Stdin)
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return HTTPSource{URL: spec}, nil
+	case strings.HasPrefix(spec, "ssh://"):
+		u, err := url.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		if u.Host == "" || u.Path == "" || u.Path == "/" {
+			return nil, fmt.Errorf("%s: want ssh://[user@]host[:port]/path", spec)
+		}
+		host := u.Hostname()
+		if u.User != nil {
+			host = u.User.Username() + "@" + host
+		}
+		return SSHSource{Host: host, Port: u.Port(), Path: u.Path}, nil
+	}
+	return FileSource(spec), nil
+}
+
+// LocalPath returns the path of a source backed by a local file. Only local
+// sessions have fsnotify events, subagent traces, and a project directory.
+func LocalPath(src SessionSource) (string, bool) {
+	f, ok := src.(FileSource)
+	return string(f), ok
+}
+
+// FileSource is a session file on the local disk.
+type FileSource string
+
+func (s FileSource) Name() string { return string(s) }
+
+func (s FileSource) Open(offset int64) (io.ReadCloser, error) {
+	f, err := os.Open(string(s))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s FileSource) Size() (int64, error) {
+	info, err := os.Stat(string(s))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// HTTPSource is a transcript served over HTTP(S). Reads send a Range
+// request, so tailing fetches only what's new; when the server ignores it
+// and sends the whole file, Open skips to the offset itself.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client // nil uses http.DefaultClient
+}
+
+func (s HTTPSource) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return http.DefaultClient
+}
+
+func (s HTTPSource) Name() string { return s.URL }
+
+func (s HTTPSource) Open(offset int64) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Nothing past the offset yet.
+		resp.Body.Close()
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	case http.StatusOK:
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil && err != io.EOF {
+			resp.Body.Close()
+			return nil, err
+		}
+		return resp.Body, nil
+	}
+	resp.Body.Close()
+	return nil, fmt.Errorf("GET %s: %s", s.URL, resp.Status)
+}
+
+func (s HTTPSource) Size() (int64, error) {
+	resp, err := s.client().Head(s.URL)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HEAD %s: %s", s.URL, resp.Status)
+	}
+	if resp.ContentLength < 0 {
+		return 0, fmt.Errorf("HEAD %s: no Content-Length", s.URL)
+	}
+	return resp.ContentLength, nil
+}
+
+// SSHSource is a transcript on another machine, read by running tail and
+// wc there over ssh. ssh runs in batch mode: keys and config come from the
+// user's ssh setup, and tail-claude never prompts for a password.
+type SSHSource struct {
+	Host string // [user@]host, as ssh takes it
+	Port string // empty for ssh's default
+	Path string // absolute, or ~/ for the remote home directory
+}
+
+func (s SSHSource) Name() string {
+	host := s.Host
+	if s.Port != "" {
+		host += ":" + s.Port
+	}
+	if strings.HasPrefix(s.Path, "~/") {
+		return "ssh://" + host + "/" + s.Path
+	}
+	return "ssh://" + host + s.Path
+}
+
+// remotePath returns Path quoted for the remote shell.
+func (s SSHSource) remotePath() string {
+	p := strings.TrimPrefix(s.Path, "/")
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return `"$HOME"/` + shellQuote(rest)
+	}
+	return shellQuote(s.Path)
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// command returns ssh running script on the host. The host follows "--",
+// so a host beginning with "-" can't be taken for an option.
+func (s SSHSource) command(script string) *exec.Cmd {
+	args := []string{"-o", "BatchMode=yes"}
+	if s.Port != "" {
+		args = append(args, "-p", s.Port)
+	}
+	args = append(args, "--", s.Host, script)
+	return exec.Command("ssh", args...)
+}
+
+func (s SSHSource) Open(offset int64) (io.ReadCloser, error) {
+	cmd := s.command("tail -c +" + strconv.FormatInt(offset+1, 10) + " -- " + s.remotePath())
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	r := &commandReader{name: s.Name(), cmd: cmd, out: out}
+	cmd.Stderr = &r.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s SSHSource) Size() (int64, error) {
+	cmd := s.command("wc -c < " + s.remotePath())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, commandError(s.Name(), err, stderr.String())
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+}
+
+// commandReader is the output of a running command. The command's failure
+// is reported at the end of its output, so a transcript that couldn't be
+// read isn't mistaken for an empty one.
+type commandReader struct {
+	name   string
+	cmd    *exec.Cmd
+	out    io.ReadCloser
+	stderr bytes.Buffer
+	once   sync.Once
+	err    error
+}
+
+func (r *commandReader) Read(p []byte) (int, error) {
+	n, err := r.out.Read(p)
+	if err == io.EOF {
+		if werr := r.wait(); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+func (r *commandReader) Close() error {
+	r.out.Close()
+	r.once.Do(func() {
+		// Closed early: don't wait for the rest of the output.
+		r.cmd.Process.Kill()
+		r.cmd.Wait()
+	})
+	return nil
+}
+
+// wait waits for the command to exit after its output ends.
+func (r *commandReader) wait() error {
+	r.once.Do(func() {
+		if err := r.cmd.Wait(); err != nil {
+			r.err = commandError(r.name, err, r.stderr.String())
+		}
+	})
+	return r.err
+}
+
+// commandError adds a command's stderr to its exit error.
+func commandError(name string, err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%s: %w: %s", name, err, msg)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// StreamSource is a transcript arriving on a stream, such as a pipe to
+// stdin. The stream is spooled into a temp file as it arrives so it can be
+// read from any offset, like a file. Close removes the spool.
+type StreamSource struct {
+	name  string
+	spool *os.File
+	size  atomic.Int64  // bytes spooled so far
+	first chan struct{} // closed once data arrives or the stream ends
+	once  sync.Once
+}
+
+// NewStreamSource starts spooling r in the background.
+func NewStreamSource(name string, r io.Reader) (*StreamSource, error) {
+	f, err := os.CreateTemp("", "tail-claude-stream-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	s := &StreamSource{name: name, spool: f, first: make(chan struct{})}
+	go s.copy(r)
+	return s, nil
+}
+
+// copy spools r until it ends or the source is closed.
+func (s *StreamSource) copy(r io.Reader) {
+	defer s.once.Do(func() { close(s.first) })
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := s.spool.Write(buf[:n]); werr != nil {
+				return
+			}
+			s.size.Add(int64(n))
+			s.once.Do(func() { close(s.first) })
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// WaitData blocks until the first data arrives or the stream ends empty, so
+// a session isn't opened before there's anything to show.
+func (s *StreamSource) WaitData() {
+	<-s.first
+}
+
+func (s *StreamSource) Name() string { return s.name }
+
+func (s *StreamSource) Open(offset int64) (io.ReadCloser, error) {
+	n := max(s.size.Load()-offset, 0)
+	return io.NopCloser(io.NewSectionReader(s.spool, offset, n)), nil
+}
+
+func (s *StreamSource) Size() (int64, error) { return s.size.Load(), nil }
+
+// Close stops spooling and removes the spool file.
+func (s *StreamSource) Close() error {
+	s.spool.Close()
+	return os.Remove(s.spool.Name())
+}
@@ -0,0 +1,178 @@
+package parser_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestParseSource(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want parser.SessionSource
+	}{
+		{"/tmp/a.jsonl", parser.FileSource("/tmp/a.jsonl")},
+		{"a.jsonl", parser.FileSource("a.jsonl")},
+		{"https://example.com/s/a.jsonl", parser.HTTPSource{URL: "https://example.com/s/a.jsonl"}},
+		{"ssh://devbox/var/log/a.jsonl", parser.SSHSource{Host: "devbox", Path: "/var/log/a.jsonl"}},
+		{"ssh://me@devbox/~/a.jsonl", parser.SSHSource{Host: "me@devbox", Path: "/~/a.jsonl"}},
+		{"ssh://me@devbox:2222/var/log/a.jsonl", parser.SSHSource{Host: "me@devbox", Port: "2222", Path: "/var/log/a.jsonl"}},
+	} {
+		got, err := parser.ParseSource(tc.spec)
+		if err != nil {
+			t.Errorf("ParseSource(%q): %v", tc.spec, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseSource(%q) = %#v, want %#v", tc.spec, got, tc.want)
+		}
+		if got.Name() != tc.spec {
+			t.Errorf("ParseSource(%q).Name() = %q", tc.spec, got.Name())
+		}
+	}
+	if _, err := parser.ParseSource("ssh://devbox"); err == nil {
+		t.Error("ssh:// without a path should be an error")
+	}
+}
+
+// readAll reads a whole source as the loader does.
+func readAll(t *testing.T, src parser.SessionSource) ([]parser.ClassifiedMsg, int64) {
+	t.Helper()
+	msgs, _, end, err := parser.ReadSourceIncrementalOffsets(src, 0)
+	if err != nil {
+		t.Fatalf("reading %s: %v", src.Name(), err)
+	}
+	return msgs, end
+}
+
+func TestHTTPSource(t *testing.T) {
+	path := writeJSONL(t, t.TempDir(), "s.jsonl",
+		userEntry("u1", "2025-01-15T10:00:00Z", "Hello"),
+		assistantEntry("a1", "2025-01-15T10:00:01Z", "Hi"),
+	)
+	file := parser.FileSource(path)
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		f, err := os.Open(path)
+		if err != nil || r.URL.Path != "/s.jsonl" {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		info, _ := f.Stat()
+		http.ServeContent(w, r, "s.jsonl", info.ModTime(), f)
+	}
+	ranged := httptest.NewServer(http.HandlerFunc(serve))
+	defer ranged.Close()
+	// A server that ignores Range and always sends the whole file.
+	whole := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del("Range")
+		serve(w, r)
+	}))
+	defer whole.Close()
+
+	for _, server := range []*httptest.Server{ranged, whole} {
+		src := parser.HTTPSource{URL: server.URL + "/s.jsonl"}
+		want, wantEnd := readAll(t, file)
+		got, end := readAll(t, src)
+		if len(got) != len(want) || end != wantEnd {
+			t.Fatalf("%s: read %d messages to %d, want %d to %d", server.URL, len(got), end, len(want), wantEnd)
+		}
+
+		// At the end there's nothing new yet.
+		more, _, next, err := parser.ReadSourceIncrementalOffsets(src, end)
+		if err != nil || len(more) != 0 || next != end {
+			t.Fatalf("read at end: %d messages, offset %d, err %v", len(more), next, err)
+		}
+	}
+
+	src := parser.HTTPSource{URL: ranged.URL + "/s.jsonl"}
+	_, end := readAll(t, src)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(userEntry("u2", "2025-01-15T10:01:00Z", "More") + "\n")
+	f.Close()
+
+	if size, err := src.Size(); err != nil || size <= end {
+		t.Fatalf("Size after append = %d, %v; want more than %d", size, err, end)
+	}
+	more, _, _, err := parser.ReadSourceIncrementalOffsets(src, end)
+	if err != nil || len(more) != 1 {
+		t.Fatalf("read appended line: %d messages, err %v", len(more), err)
+	}
+	if u, ok := more[0].(parser.UserMsg); !ok || u.Text != "More" {
+		t.Errorf("appended message = %#v", more[0])
+	}
+
+	missing := parser.HTTPSource{URL: ranged.URL + "/missing.jsonl"}
+	if _, err := missing.Open(0); err == nil {
+		t.Error("Open of a missing URL should fail")
+	}
+	if _, err := missing.Size(); err == nil {
+		t.Error("Size of a missing URL should fail")
+	}
+}
+
+func TestStreamSource(t *testing.T) {
+	r, w := io.Pipe()
+	src, err := parser.NewStreamSource("stdin", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	go w.Write([]byte(userEntry("u1", "2025-01-15T10:00:00Z", "Hello") + "\n"))
+	src.WaitData()
+	msgs, end := readAll(t, src)
+	if len(msgs) != 1 {
+		t.Fatalf("read %d messages, want 1", len(msgs))
+	}
+
+	// Half a line isn't read until the rest arrives.
+	line := assistantEntry("a1", "2025-01-15T10:00:01Z", "Hi") + "\n"
+	w.Write([]byte(line[:20]))
+	waitSize(t, src, end+20)
+	if more, _, next, _ := parser.ReadSourceIncrementalOffsets(src, end); len(more) != 0 || next != end {
+		t.Fatalf("half a line: %d messages, offset %d; want none at %d", len(more), next, end)
+	}
+	w.Write([]byte(line[20:]))
+	w.Close()
+	waitSize(t, src, end+int64(len(line)))
+	if more, _, _, _ := parser.ReadSourceIncrementalOffsets(src, end); len(more) != 1 {
+		t.Fatalf("completed line: %d messages, want 1", len(more))
+	}
+}
+
+// waitSize waits for a stream to spool at least n bytes.
+func waitSize(t *testing.T, src parser.SessionSource, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if size, _ := src.Size(); size >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: timed out waiting for %d bytes", src.Name(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStreamSource_EmptyStream(t *testing.T) {
+	src, err := parser.NewStreamSource("stdin", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	src.WaitData() // returns once the stream ends
+	if size, _ := src.Size(); size != 0 {
+		t.Errorf("Size = %d, want 0", size)
+	}
+}
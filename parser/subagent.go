@@ -23,9 +23,14 @@ type SubagentProcess struct {
 	Usage         Usage // aggregated from all AI chunks
 	Description   string
 	SubagentType  string
-	ParentTaskID  string // tool_use_id of spawning Task call
-	TeamSummary   string // summary attr from first <teammate-message> (team agents only)
-	TeammateColor string // color attr from first <teammate-message> (team agents only)
+	ParentTaskID  string   // tool_use_id of spawning Task call
+	TeamSummary   string   // summary attr from first <teammate-message> (team agents only)
+	TeammateColor string   // color attr from first <teammate-message> (team agents only)
+	EndState      EndState // how the conversation stopped, from its final entries
+
+	// Children are subagents spawned by this one's own Task calls, filled by
+	// LinkNestedSubagents. Pointers into the same process slice.
+	Children []*SubagentProcess
 }
 
 // DiscoverSubagents finds and parses subagent files for a session.
@@ -89,25 +94,26 @@ func DiscoverSubagents(sessionPath string) ([]SubagentProcess, error) {
 		// Subagent entries all have isSidechain=true (they run in the
 		// parent's sidechain context), but within the subagent file
 		// they're the main conversation.
-		chunks, teamSummary, teamColor, err := readSubagentSession(filePath)
-		if err != nil || len(chunks) == 0 {
+		sess, err := readSubagentSession(filePath)
+		if err != nil || len(sess.chunks) == 0 {
 			continue
 		}
 
-		startTime, endTime, durationMs := chunkTiming(chunks)
-		usage := aggregateUsage(chunks)
+		startTime, endTime, durationMs := chunkTiming(sess.chunks)
+		usage := aggregateUsage(sess.chunks)
 
 		procs = append(procs, SubagentProcess{
 			ID:            agentID,
 			FilePath:      filePath,
 			FileModTime:   info.ModTime(),
-			Chunks:        chunks,
+			Chunks:        sess.chunks,
 			StartTime:     startTime,
 			EndTime:       endTime,
 			DurationMs:    durationMs,
 			Usage:         usage,
-			TeamSummary:   teamSummary,
-			TeammateColor: teamColor,
+			TeamSummary:   sess.teamSummary,
+			TeammateColor: sess.teamColor,
+			EndState:      sess.endState,
 		})
 	}
 
@@ -186,17 +192,27 @@ func chunkTiming(chunks []Chunk) (start, end time.Time, durationMs int64) {
 	return
 }
 
-// readSubagentSession reads a subagent JSONL file and returns chunks plus
-// team metadata (summary and color). Both are extracted from the raw entry
-// content before Classify strips the XML tag attributes.
+// subagentSession is a parsed subagent JSONL file.
+type subagentSession struct {
+	chunks      []Chunk
+	teamSummary string // summary attr from the first <teammate-message>
+	teamColor   string // color attr from the first <teammate-message>
+	endState    EndState
+}
+
+// readSubagentSession reads a subagent JSONL file and returns its chunks,
+// team metadata (summary and color), and end state. The team metadata is
+// extracted from the raw entry content before Classify strips the XML tag
+// attributes, and the end state from entries Classify drops as noise
+// (interruption markers).
 //
 // Unlike ReadSession, it ignores the isSidechain flag since all entries
 // in subagent files are marked isSidechain=true but represent the
 // subagent's own main conversation.
-func readSubagentSession(path string) ([]Chunk, string, string, error) {
+func readSubagentSession(path string) (subagentSession, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, "", "", err
+		return subagentSession{}, err
 	}
 	defer f.Close()
 
@@ -205,6 +221,7 @@ func readSubagentSession(path string) ([]Chunk, string, string, error) {
 	var msgs []ClassifiedMsg
 	var teamSummary, teamColor string
 	extractedTeamMeta := false
+	endState := EndUnknown
 	for {
 		line, ok := lr.next()
 		if !ok {
@@ -214,6 +231,7 @@ func readSubagentSession(path string) ([]Chunk, string, string, error) {
 		if !ok {
 			continue
 		}
+		endState = nextEndState(endState, entry)
 
 		// Extract team summary and color from the first user entry's
 		// <teammate-message> tag before Classify strips the XML attributes.
@@ -239,10 +257,15 @@ func readSubagentSession(path string) ([]Chunk, string, string, error) {
 		msgs = append(msgs, msg)
 	}
 	if err := lr.Err(); err != nil {
-		return nil, "", "", err
+		return subagentSession{}, err
 	}
 
-	return BuildChunks(msgs), teamSummary, teamColor, nil
+	return subagentSession{
+		chunks:      BuildChunks(msgs),
+		teamSummary: teamSummary,
+		teamColor:   teamColor,
+		endState:    endState,
+	}, nil
 }
 
 // aggregateUsage returns the last AI chunk's usage snapshot. Each chunk already
@@ -412,6 +435,61 @@ func LinkSubagents(processes []SubagentProcess, parentChunks []Chunk, parentSess
 	return links.toolIDToColor
 }
 
+// LinkNestedSubagents connects subagents spawned by other subagents to the
+// process that spawned them. Run after LinkSubagents: processes it left
+// unlinked are matched against each linked process's own Task calls (using
+// the same strategy, scanning the subagent's JSONL for toolUseResult links)
+// and appended to that process's Children. Repeats level by level so
+// arbitrarily deep nesting resolves. Mutates processes in place.
+//
+// Candidates are limited to processes that started within the spawner's
+// lifetime so the positional fallback can't pair a subagent with a sibling's
+// nested agents.
+func LinkNestedSubagents(processes []SubagentProcess) {
+	var frontier []int
+	for i := range processes {
+		if processes[i].ParentTaskID != "" {
+			frontier = append(frontier, i)
+		}
+	}
+
+	for len(frontier) > 0 {
+		var next []int
+		for _, pi := range frontier {
+			parent := &processes[pi]
+			var candidates []int
+			for i := range processes {
+				p := &processes[i]
+				if i == pi || p.ParentTaskID != "" {
+					continue
+				}
+				if p.StartTime.Before(parent.StartTime) || p.StartTime.After(parent.EndTime) {
+					continue
+				}
+				candidates = append(candidates, i)
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+
+			subset := make([]SubagentProcess, len(candidates))
+			for k, ci := range candidates {
+				subset[k] = processes[ci]
+			}
+			LinkSubagents(subset, parent.Chunks, parent.FilePath)
+			for k, ci := range candidates {
+				if subset[k].ParentTaskID == "" {
+					continue
+				}
+				processes[ci] = subset[k]
+				parent.Children = append(parent.Children, &processes[ci])
+				next = append(next, ci)
+			}
+		}
+		frontier = next
+	}
+}
+
 // filterTeamTasks returns unmatched Task items whose input contains both
 // team_name and name keys, identifying them as team member spawns.
 func filterTeamTasks(items []*DisplayItem, matched map[string]bool) []*DisplayItem {
@@ -671,24 +749,25 @@ func DiscoverTeamSessions(sessionPath string, parentChunks []Chunk) ([]SubagentP
 			continue
 		}
 
-		chunks, _, teamColor, err := readSubagentSession(filePath)
-		if err != nil || len(chunks) == 0 {
+		sess, err := readSubagentSession(filePath)
+		if err != nil || len(sess.chunks) == 0 {
 			continue
 		}
 
-		startTime, endTime, durationMs := chunkTiming(chunks)
-		usage := aggregateUsage(chunks)
+		startTime, endTime, durationMs := chunkTiming(sess.chunks)
+		usage := aggregateUsage(sess.chunks)
 
 		procs = append(procs, SubagentProcess{
 			ID:            agentName + "@" + teamName,
 			FilePath:      filePath,
 			FileModTime:   info.ModTime(),
-			Chunks:        chunks,
+			Chunks:        sess.chunks,
 			StartTime:     startTime,
 			EndTime:       endTime,
 			DurationMs:    durationMs,
 			Usage:         usage,
-			TeammateColor: teamColor,
+			TeammateColor: sess.teamColor,
+			EndState:      sess.endState,
 		})
 	}
 
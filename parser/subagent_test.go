@@ -182,6 +182,53 @@ func TestDiscoverSubagents_EmptySubagentsDir(t *testing.T) {
 	}
 }
 
+func TestDiscoverSubagents_EndState(t *testing.T) {
+	const (
+		prompt    = `{"uuid":"u1","type":"user","timestamp":"2025-01-01T10:00:00Z","isSidechain":true,"message":{"role":"user","content":"Map the parser"}}`
+		toolUse   = `{"uuid":"u2","type":"assistant","timestamp":"2025-01-01T10:00:01Z","isSidechain":true,"message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"a.go"}}]}}`
+		result    = `{"uuid":"u3","type":"user","timestamp":"2025-01-01T10:00:02Z","isSidechain":true,"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"package a"}]}}`
+		answer    = `{"uuid":"u4","type":"assistant","timestamp":"2025-01-01T10:00:03Z","isSidechain":true,"message":{"role":"assistant","content":[{"type":"text","text":"Done."}]}}`
+		interrupt = `{"uuid":"u5","type":"user","timestamp":"2025-01-01T10:00:03Z","isSidechain":true,"message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user for tool use]"}]}}`
+		retry     = `{"uuid":"u6","type":"system","subtype":"api_error","timestamp":"2025-01-01T10:00:03Z","retryAttempt":1,"maxRetries":10}`
+		failed    = `{"uuid":"u7","type":"assistant","timestamp":"2025-01-01T10:00:04Z","isSidechain":true,"isApiErrorMessage":true,"message":{"role":"assistant","content":[{"type":"text","text":"API Error: Request timed out."}]}}`
+		tooLong   = `{"uuid":"u8","type":"assistant","timestamp":"2025-01-01T10:00:04Z","isSidechain":true,"isApiErrorMessage":true,"message":{"role":"assistant","content":[{"type":"text","text":"API Error: 400 {\"type\":\"error\",\"error\":{\"type\":\"invalid_request_error\",\"message\":\"prompt is too long: 201234 tokens > 200000 maximum\"}}"}]}}`
+	)
+	tests := []struct {
+		name    string
+		entries []string
+		want    parser.EndState
+	}{
+		{"completed", []string{prompt, toolUse, result, answer}, parser.EndCompleted},
+		{"mid tool call", []string{prompt, toolUse, result}, parser.EndUnknown},
+		{"interrupted", []string{prompt, toolUse, result, interrupt}, parser.EndInterrupted},
+		{"retrying", []string{prompt, toolUse, result, retry}, parser.EndUnknown},
+		{"errored", []string{prompt, toolUse, result, retry, failed}, parser.EndErrored},
+		{"context limit", []string{prompt, toolUse, result, tooLong}, parser.EndContextLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			sessionPath := filepath.Join(dir, "sess.jsonl")
+			subDir := filepath.Join(dir, "sess", "subagents")
+			if err := os.MkdirAll(subDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			content := strings.Join(tt.entries, "\n") + "\n"
+			if err := os.WriteFile(filepath.Join(subDir, "agent-a1.jsonl"), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			procs, err := parser.DiscoverSubagents(sessionPath)
+			if err != nil || len(procs) != 1 {
+				t.Fatalf("DiscoverSubagents = %d procs, %v", len(procs), err)
+			}
+			if got := procs[0].EndState; got != tt.want {
+				t.Errorf("EndState = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // --- LinkSubagents tests ---
 
 // writeParentSession creates a temp JSONL file with tool result entries
@@ -766,6 +813,47 @@ func TestLinkSubagents_TeamAndRegularMixed(t *testing.T) {
 	}
 }
 
+// --- LinkNestedSubagents tests ---
+
+func TestLinkNestedSubagents(t *testing.T) {
+	t0 := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	aPath := writeParentSession(t, []string{
+		`{"uuid":"r1","type":"user","timestamp":"2025-06-15T10:01:00Z","isMeta":true,"sourceToolUseID":"tool-2","toolUseResult":{"agentId":"b","status":"completed"},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tool-2","content":"Done."}]}}`,
+	})
+	bPath := writeParentSession(t, []string{
+		`{"uuid":"r2","type":"user","timestamp":"2025-06-15T10:02:00Z","isMeta":true,"sourceToolUseID":"tool-3","toolUseResult":{"agentId":"c","status":"completed"},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tool-3","content":"Done."}]}}`,
+	})
+
+	procs := []parser.SubagentProcess{
+		// Spawned before "a" started, so it can't be one of a's children
+		// even though the positional fallback would otherwise pair it.
+		{ID: "z", StartTime: t0.Add(-time.Hour), EndTime: t0.Add(-time.Hour)},
+		{ID: "a", ParentTaskID: "tool-1", FilePath: aPath, StartTime: t0, EndTime: t0.Add(10 * time.Minute),
+			Chunks: []parser.Chunk{makeTaskChunk("tool-2", "Explore", "Look around")}},
+		{ID: "b", FilePath: bPath, StartTime: t0.Add(time.Minute), EndTime: t0.Add(5 * time.Minute),
+			Chunks: []parser.Chunk{makeTaskChunk("tool-3", "Plan", "Plan it")}},
+		{ID: "c", StartTime: t0.Add(2 * time.Minute), EndTime: t0.Add(3 * time.Minute)},
+	}
+
+	parser.LinkNestedSubagents(procs)
+
+	if procs[2].ParentTaskID != "tool-2" || procs[2].SubagentType != "Explore" {
+		t.Errorf("b linked to %q (%q), want tool-2 (Explore)", procs[2].ParentTaskID, procs[2].SubagentType)
+	}
+	if procs[3].ParentTaskID != "tool-3" {
+		t.Errorf("c.ParentTaskID = %q, want tool-3", procs[3].ParentTaskID)
+	}
+	if procs[0].ParentTaskID != "" {
+		t.Errorf("z.ParentTaskID = %q, want unlinked", procs[0].ParentTaskID)
+	}
+	if len(procs[1].Children) != 1 || procs[1].Children[0] != &procs[2] {
+		t.Errorf("a.Children = %v, want [b]", procs[1].Children)
+	}
+	if len(procs[2].Children) != 1 || procs[2].Children[0] != &procs[3] {
+		t.Errorf("b.Children = %v, want [c]", procs[2].Children)
+	}
+}
+
 // --- readTeamSessionMeta tests ---
 
 func TestReadTeamSessionMeta_TeamSession(t *testing.T) {
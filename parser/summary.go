@@ -56,6 +56,8 @@ func ToolSummary(name string, input json.RawMessage) string {
 		return summaryTaskUpdate(fields)
 	case "SendMessage":
 		return summarySendMessage(fields)
+	case "Skill":
+		return summarySkill(fields)
 	default:
 		return summaryDefault(name, fields)
 	}
@@ -244,6 +246,36 @@ func summaryNotebookEdit(f map[string]json.RawMessage) string {
 	return base
 }
 
+// summarySkill returns "pdf" or "pdf: fill the form".
+func summarySkill(f map[string]json.RawMessage) string {
+	name := skillField(f)
+	if name == "" {
+		return "Skill"
+	}
+	if args := getString(f, "args"); args != "" {
+		return name + ": " + Truncate(args, 40)
+	}
+	return name
+}
+
+// SkillName returns the skill a Skill tool call invoked, or "" when the
+// input names none.
+func SkillName(input json.RawMessage) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return ""
+	}
+	return skillField(fields)
+}
+
+// skillField reads the skill name: "skill", or "command" in older versions.
+func skillField(f map[string]json.RawMessage) string {
+	if name := getString(f, "skill"); name != "" {
+		return name
+	}
+	return getString(f, "command")
+}
+
 func summaryTaskCreate(f map[string]json.RawMessage) string {
 	if subj := getString(f, "subject"); subj != "" {
 		return Truncate(subj, 50)
@@ -361,36 +393,3 @@ func getNumber(fields map[string]json.RawMessage, key string) int {
 	}
 	return int(n)
 }
-
-// Truncate shortens a string to maxLen runes, appending an ellipsis if truncated.
-// The result is exactly maxLen runes when truncation occurs.
-// Collapses newlines to spaces since summaries are single-line display strings.
-func Truncate(s string, maxLen int) string {
-	s = strings.ReplaceAll(s, "\n", " ")
-	runes := []rune(s)
-	if len(runes) <= maxLen {
-		return s
-	}
-	return string(runes[:maxLen-1]) + ellipsis
-}
-
-// TruncateWord shortens a string to maxLen runes, breaking at the nearest
-// preceding word boundary (space). Searches up to 20 characters back from
-// the cut point. Falls back to hard truncation if no space is found.
-func TruncateWord(s string, maxLen int) string {
-	runes := []rune(s)
-	if len(runes) <= maxLen {
-		return s
-	}
-	cutoff := maxLen - 1
-	searchStart := cutoff - 20
-	if searchStart < 0 {
-		searchStart = 0
-	}
-	for i := cutoff; i >= searchStart; i-- {
-		if runes[i] == ' ' {
-			return string(runes[:i]) + ellipsis
-		}
-	}
-	return string(runes[:cutoff]) + ellipsis
-}
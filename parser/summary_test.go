@@ -725,3 +725,24 @@ func TestTruncateWord(t *testing.T) {
 		})
 	}
 }
+
+func TestToolSummary_Skill(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"skill", `{"skill":"pdf"}`, "pdf"},
+		{"with args", `{"skill":"pdf","args":"fill the form"}`, "pdf: fill the form"},
+		{"older command field", `{"command":"xlsx"}`, "xlsx"},
+		{"no name", `{}`, "Skill"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parser.ToolSummary("Skill", json.RawMessage(tt.input))
+			if got != tt.want {
+				t.Errorf("ToolSummary(Skill, %s) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
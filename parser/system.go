@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// SystemKind says what a system message reports. The zero value is output
+// recognized by its markup: bash mode, slash command results, and task
+// notifications.
+type SystemKind string
+
+const (
+	SystemOutput      SystemKind = ""            // bash mode, command output, task notifications
+	SystemStatus      SystemKind = "status"      // a notice from Claude Code (subtype informational)
+	SystemOutputStyle SystemKind = "outputStyle" // the session's output style changed
+	SystemQueue       SystemKind = "queue"       // queued prompts taken back before Claude read them
+)
+
+// SystemKinds lists every kind, in the order the hiddenSystem config names
+// them.
+var SystemKinds = []SystemKind{SystemStatus, SystemOutputStyle, SystemQueue}
+
+// System entry subtypes with a structured meaning. Other subtypes (turn
+// timing, hook summaries) carry nothing to show and stay noise.
+const (
+	subtypeCompactBoundary = "compact_boundary"
+	subtypeAPIError        = "api_error"
+	subtypeInformational   = "informational"
+	subtypeLocalCommand    = "local_command"
+	subtypeOutputStyle     = "output_style"
+)
+
+// classifySystemEntry maps a type=system entry by its subtype, falling back
+// to recognizing hook output by its text for entries that predate subtypes.
+// Returns false for entries that stay noise.
+func classifySystemEntry(e Entry, ts time.Time) (ClassifiedMsg, bool) {
+	switch e.Subtype {
+	case subtypeCompactBoundary:
+		return parseCompactBoundary(e, ts), true
+	case subtypeAPIError:
+		msg := parseAPIError(e)
+		msg.Timestamp = ts
+		return msg, true
+	case subtypeInformational:
+		// Hook output may be written as a notice; it attaches to its tool.
+		if hook, ok := parseHookOutput(e); ok {
+			hook.Timestamp = ts
+			return hook, true
+		}
+		text := strings.TrimSpace(reANSI.ReplaceAllString(ExtractText(e.Content), ""))
+		if text == "" {
+			return nil, false
+		}
+		return SystemMsg{
+			Timestamp: ts,
+			Kind:      SystemStatus,
+			Level:     e.Level,
+			Output:    text,
+			IsError:   e.Level == "error",
+		}, true
+	case subtypeOutputStyle:
+		style := e.OutputStyle
+		if style == "" {
+			style = strings.TrimSpace(ExtractText(e.Content))
+		}
+		if style == "" {
+			return nil, false
+		}
+		return SystemMsg{Timestamp: ts, Kind: SystemOutputStyle, Output: style}, true
+	case subtypeLocalCommand:
+		// Newer versions write slash commands and their output as system
+		// entries with the same markup older ones put in user entries.
+		text := strings.TrimSpace(ExtractText(e.Content))
+		if cmd, ok := parseCommandInvocation(text); ok {
+			cmd.Timestamp = ts
+			return cmd, true
+		}
+		if strings.HasPrefix(text, localCommandStdoutTag) || strings.HasPrefix(text, localCommandStderrTag) {
+			out := ExtractCommandOutput(text)
+			if out == "" {
+				return nil, false
+			}
+			return SystemMsg{
+				Timestamp:       ts,
+				Output:          out,
+				IsError:         strings.HasPrefix(text, localCommandStderrTag),
+				IsCommandOutput: true,
+			}, true
+		}
+		return nil, false
+	}
+	if hook, ok := parseHookOutput(e); ok {
+		hook.Timestamp = ts
+		return hook, true
+	}
+	return nil, false
+}
+
+// Queue operations. A prompt sent while Claude works is enqueued, then
+// dequeued when it is delivered into the turn (shown as queued there).
+// Removing it, or popping the queue back into the input box, withdraws it.
+const (
+	queueRemove = "remove"
+	queuePopAll = "popAll"
+)
+
+// classifyQueueOperation maps a queue-operation entry that withdrew queued
+// prompts to a SystemQueue message. Enqueue and dequeue are noise: the
+// prompt shows up in the turn it was delivered to.
+func classifyQueueOperation(e Entry, ts time.Time) (ClassifiedMsg, bool) {
+	var text string
+	if len(e.Content) > 0 && json.Unmarshal(e.Content, &text) != nil {
+		text = ExtractText(e.Content)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	switch e.Operation {
+	case queueRemove:
+		if text == "" {
+			return SystemMsg{Timestamp: ts, Kind: SystemQueue, Output: "Queued prompt removed"}, true
+		}
+		return SystemMsg{Timestamp: ts, Kind: SystemQueue, Output: "Queued prompt removed: " + text}, true
+	case queuePopAll:
+		return SystemMsg{Timestamp: ts, Kind: SystemQueue, Output: "Queued prompts taken back to edit"}, true
+	}
+	return nil, false
+}
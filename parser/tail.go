@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+)
+
+// tailSpan is how much of a session ReadSourceTail reads first. Each retry
+// reads four times as much.
+const tailSpan = 4 << 20
+
+// ReadSourceTail reads the last turns of a session without parsing what
+// comes before them: the messages from the turns-th prompt from the end on,
+// with their offsets. It reads ever larger spans back from the end until
+// one holds that many prompts. Returns where the first message's line
+// starts (0 when the session has no more prompts than turns) and the
+// offset after the last line read, as ReadSourceIncrementalOffsets does.
+// turns must be at least 1.
+func ReadSourceTail(src SessionSource, turns int) (msgs []ClassifiedMsg, offsets []int64, start, end int64, err error) {
+	size, err := src.Size()
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	for span := int64(tailSpan); ; span *= 4 {
+		from := max(size-span, 0)
+		if from > 0 {
+			if from, err = lineStartAfter(src, from); err != nil {
+				return nil, nil, 0, 0, err
+			}
+		}
+		msgs, offsets, end, err = readSessionRange(src, from, -1)
+		if err != nil {
+			return nil, nil, 0, 0, err
+		}
+		var prompts []int
+		for i, msg := range msgs {
+			if _, ok := msg.(UserMsg); ok {
+				prompts = append(prompts, i)
+			}
+		}
+		if len(prompts) > turns || (from > 0 && len(prompts) == turns) {
+			// Messages before the cut belong to earlier turns, perhaps
+			// only partly read.
+			cut := prompts[len(prompts)-turns]
+			return msgs[cut:], offsets[cut:], offsets[cut], end, nil
+		}
+		if from == 0 {
+			return msgs, offsets, 0, end, nil
+		}
+	}
+}
+
+// lineStartAfter returns the offset of the first line starting at or after
+// offset, or the source's end when no line does.
+func lineStartAfter(src SessionSource, offset int64) (int64, error) {
+	f, err := src.Open(offset - 1)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	n := offset - 1
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		n++
+		if b == '\n' {
+			return n, nil
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package parser_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// writeLongSession writes a session of n turns, each answered with about
+// 1 MB of text, so reading its tail takes more than one span.
+func writeLongSession(t *testing.T, n int) string {
+	t.Helper()
+	var b strings.Builder
+	answer := strings.Repeat("x", 1<<20)
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, `{"type":"user","uuid":"u%d","timestamp":"2025-01-15T10:%02d:00Z","message":{"role":"user","content":"prompt %d"}}`+"\n", i, i, i)
+		fmt.Fprintf(&b, `{"type":"assistant","uuid":"a%d","timestamp":"2025-01-15T10:%02d:05Z","message":{"role":"assistant","content":[{"type":"text","text":"%s"}],"stop_reason":"end_turn"}}`+"\n", i, i, answer)
+	}
+	path := filepath.Join(t.TempDir(), "long.jsonl")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadSourceTail(t *testing.T) {
+	path := writeLongSession(t, 6)
+	all, allOffsets, allEnd, err := parser.ReadSessionIncrementalOffsets(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		turns      int
+		wantFirst  string // text of the first message read
+		wantStart  int64
+		wantLength int
+	}{
+		{2, "prompt 5", allOffsets[8], 4},  // within the first span
+		{5, "prompt 2", allOffsets[2], 10}, // takes a larger span
+		{6, "prompt 1", 0, 12},
+		{10, "prompt 1", 0, 12},
+	}
+	for _, tt := range tests {
+		msgs, offsets, start, end, err := parser.ReadSourceTail(parser.FileSource(path), tt.turns)
+		if err != nil {
+			t.Fatalf("turns=%d: %v", tt.turns, err)
+		}
+		if len(msgs) != tt.wantLength || len(offsets) != len(msgs) {
+			t.Errorf("turns=%d: %d messages with %d offsets, want %d", tt.turns, len(msgs), len(offsets), tt.wantLength)
+			continue
+		}
+		if u, ok := msgs[0].(parser.UserMsg); !ok || u.Text != tt.wantFirst {
+			t.Errorf("turns=%d: first message = %+v, want %q", tt.turns, msgs[0], tt.wantFirst)
+		}
+		if start != tt.wantStart || offsets[0] != start {
+			t.Errorf("turns=%d: start = %d (offsets[0] %d), want %d", tt.turns, start, offsets[0], tt.wantStart)
+		}
+		if end != allEnd {
+			t.Errorf("turns=%d: end = %d, want %d", tt.turns, end, allEnd)
+		}
+	}
+	if len(all) != 12 {
+		t.Errorf("full read = %d messages, want 12", len(all))
+	}
+}
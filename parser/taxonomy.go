@@ -96,3 +96,13 @@ func CategorizeToolName(name string) ToolCategory {
 		return CategoryOther
 	}
 }
+
+// NeverPrompts reports whether tools of category c run without asking for
+// approval in any permission mode: reads, searches, and subagents.
+func NeverPrompts(c ToolCategory) bool {
+	switch c {
+	case CategoryRead, CategoryGrep, CategoryGlob, CategoryTask:
+		return true
+	}
+	return false
+}
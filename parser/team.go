@@ -37,90 +37,157 @@ type TeamSnapshot struct {
 // status and ownership. If a worker update has no explicit owner field,
 // the worker's own name (from its ID) is used as fallback.
 //
-// Phase 3 populates member colors from worker TeammateColor metadata.
+// Phase 3 populates member colors and ongoing state from worker metadata.
+//
+// This is a one-shot replay over a TeamTracker; callers that receive chunks
+// over time can drive a TeamTracker directly.
 func ReconstructTeams(chunks []Chunk, workers []SubagentProcess) []TeamSnapshot {
-	var teams []TeamSnapshot
-	activeIdx := -1
-	taskCounter := 0
+	t := NewTeamTracker()
 
 	// Phase 1: Lead chunk events.
 	for i := range chunks {
-		if chunks[i].Type != AIChunk {
-			continue
+		t.ApplyChunk(&chunks[i])
+	}
+
+	// Phase 2: Worker TaskUpdate events.
+	for i := range workers {
+		for j := range workers[i].Chunks {
+			t.ApplyWorkerChunk(workers[i].ID, &workers[i].Chunks[j])
 		}
-		for j := range chunks[i].Items {
-			it := &chunks[i].Items[j]
+	}
 
-			switch {
-			case it.Type == ItemToolCall && it.ToolName == "TeamCreate":
-				teams = append(teams, teamSnapshotFromCreate(it.ToolInput))
-				activeIdx = len(teams) - 1
-				taskCounter = 0
+	// Phase 3: Member colors and ongoing state from worker sessions.
+	for i := range workers {
+		t.SetMemberState(workers[i].ID, workers[i].TeammateColor, IsOngoing(workers[i].Chunks))
+	}
 
-			case it.Type == ItemToolCall && it.ToolName == "TaskCreate" && activeIdx >= 0:
-				taskCounter++
-				teams[activeIdx].Tasks = append(teams[activeIdx].Tasks,
-					teamTaskFromCreate(it.ToolInput, taskCounter))
+	return t.Snapshots()
+}
 
-			case it.Type == ItemToolCall && it.ToolName == "TaskUpdate" && activeIdx >= 0:
-				applyTeamTaskUpdate(it.ToolInput, &teams[activeIdx])
+// TeamTracker builds team task boards incrementally. Feed lead session
+// chunks to ApplyChunk and worker session chunks to ApplyWorkerChunk, each in
+// chronological order and each chunk exactly once, then read the current
+// state with Snapshots.
+//
+// BuildChunks merges consecutive assistant messages, so the last chunk of a
+// live session can still grow. Hold it back until a later chunk follows it,
+// or replay from scratch with ReconstructTeams.
+type TeamTracker struct {
+	teams       []TeamSnapshot
+	activeIdx   int // index of the team receiving TaskCreate/TaskUpdate; -1 when none
+	taskCounter int // sequential task IDs within the active team
+}
 
-			case it.Type == ItemToolCall && it.ToolName == "TeamDelete" && activeIdx >= 0:
-				teams[activeIdx].Deleted = true
-				activeIdx = -1
+// NewTeamTracker returns a tracker with no teams.
+func NewTeamTracker() *TeamTracker {
+	return &TeamTracker{activeIdx: -1}
+}
 
-			case it.Type == ItemSubagent && IsTeamTask(it):
-				addTeamSpawnMember(it.ToolInput, teams)
-			}
-		}
+// ApplyChunk applies the team events in a lead session chunk: TeamCreate,
+// TaskCreate, TaskUpdate, TeamDelete, and team Task spawns. Non-AI chunks
+// are ignored.
+func (t *TeamTracker) ApplyChunk(c *Chunk) {
+	if c.Type != AIChunk {
+		return
 	}
+	for j := range c.Items {
+		it := &c.Items[j]
 
-	// Phase 2: Worker TaskUpdate events.
-	for i := range workers {
-		agentName, teamName := splitWorkerID(workers[i].ID)
-		if teamName == "" {
-			continue
+		switch {
+		case it.Type == ItemToolCall && it.ToolName == "TeamCreate":
+			t.teams = append(t.teams, teamSnapshotFromCreate(it.ToolInput))
+			t.activeIdx = len(t.teams) - 1
+			t.taskCounter = 0
+
+		case it.Type == ItemToolCall && it.ToolName == "TaskCreate" && t.activeIdx >= 0:
+			t.taskCounter++
+			t.teams[t.activeIdx].Tasks = append(t.teams[t.activeIdx].Tasks,
+				teamTaskFromCreate(it.ToolInput, t.taskCounter))
+
+		case it.Type == ItemToolCall && it.ToolName == "TaskUpdate" && t.activeIdx >= 0:
+			applyTeamTaskUpdate(it.ToolInput, &t.teams[t.activeIdx])
+
+		case it.Type == ItemToolCall && it.ToolName == "TeamDelete" && t.activeIdx >= 0:
+			t.teams[t.activeIdx].Deleted = true
+			t.activeIdx = -1
+
+		case it.Type == ItemSubagent && IsTeamTask(it):
+			addTeamSpawnMember(it.ToolInput, t.teams)
 		}
-		team := findTeamByName(teams, teamName)
-		if team == nil {
-			continue
+	}
+}
+
+// ApplyWorkerChunk applies TaskUpdate calls from a worker's session chunk.
+// workerID is the "agentName@teamName" process ID; chunks from non-team
+// workers or unknown teams are ignored.
+func (t *TeamTracker) ApplyWorkerChunk(workerID string, c *Chunk) {
+	agentName, teamName := splitWorkerID(workerID)
+	if teamName == "" || c.Type != AIChunk {
+		return
+	}
+	team := findTeamByName(t.teams, teamName)
+	if team == nil {
+		return
+	}
+	for j := range c.Items {
+		it := &c.Items[j]
+		if it.Type == ItemToolCall && it.ToolName == "TaskUpdate" {
+			applyWorkerTaskUpdate(it.ToolInput, team, agentName)
 		}
-		applyWorkerTaskUpdates(workers[i].Chunks, team, agentName)
 	}
+}
 
-	// Phase 3: Populate member colors from worker metadata.
-	for i := range teams {
-		teams[i].MemberColors = make(map[string]string)
-		teams[i].MemberOngoing = make(map[string]bool)
+// SetMemberState records a worker's team color and whether its session is
+// still in progress. Empty colors are ignored; ongoing replaces the previous
+// value, so call it again as the worker's session changes.
+func (t *TeamTracker) SetMemberState(workerID, color string, ongoing bool) {
+	agentName, teamName := splitWorkerID(workerID)
+	if teamName == "" {
+		return
 	}
-	for _, w := range workers {
-		agentName, teamName := splitWorkerID(w.ID)
-		if teamName == "" || w.TeammateColor == "" {
+	for i := range t.teams {
+		if t.teams[i].Name != teamName {
 			continue
 		}
-		for i := range teams {
-			if teams[i].Name == teamName {
-				teams[i].MemberColors[agentName] = w.TeammateColor
+		if color != "" {
+			if t.teams[i].MemberColors == nil {
+				t.teams[i].MemberColors = make(map[string]string)
+			}
+			t.teams[i].MemberColors[agentName] = color
+		}
+		if ongoing {
+			if t.teams[i].MemberOngoing == nil {
+				t.teams[i].MemberOngoing = make(map[string]bool)
 			}
+			t.teams[i].MemberOngoing[agentName] = true
+		} else {
+			delete(t.teams[i].MemberOngoing, agentName)
 		}
 	}
+}
 
-	// Phase 4: Populate member ongoing state from worker sessions.
-	for _, w := range workers {
-		agentName, teamName := splitWorkerID(w.ID)
-		if teamName == "" {
-			continue
+// Snapshots returns a copy of the current team state. The result shares no
+// memory with the tracker, so it stays valid as more chunks are applied.
+// Member maps are always non-nil.
+func (t *TeamTracker) Snapshots() []TeamSnapshot {
+	if len(t.teams) == 0 {
+		return nil
+	}
+	out := make([]TeamSnapshot, len(t.teams))
+	for i, team := range t.teams {
+		out[i] = team
+		out[i].Tasks = append([]TeamTask(nil), team.Tasks...)
+		out[i].Members = append([]string(nil), team.Members...)
+		out[i].MemberColors = make(map[string]string, len(team.MemberColors))
+		for k, v := range team.MemberColors {
+			out[i].MemberColors[k] = v
 		}
-		if IsOngoing(w.Chunks) {
-			for i := range teams {
-				if teams[i].Name == teamName {
-					teams[i].MemberOngoing[agentName] = true
-				}
-			}
+		out[i].MemberOngoing = make(map[string]bool, len(team.MemberOngoing))
+		for k, v := range team.MemberOngoing {
+			out[i].MemberOngoing[k] = v
 		}
 	}
-
-	return teams
+	return out
 }
 
 // teamSnapshotFromCreate extracts team name and description from TeamCreate input.
@@ -189,41 +256,30 @@ func addTeamSpawnMember(input json.RawMessage, teams []TeamSnapshot) {
 	}
 }
 
-// applyWorkerTaskUpdates scans a worker's chunks for TaskUpdate calls and
-// applies them to the team's tasks. If the update has no explicit owner
-// field, the worker's own name is used as fallback — workers typically
-// claim tasks by setting themselves as owner, but the field is optional.
-func applyWorkerTaskUpdates(chunks []Chunk, team *TeamSnapshot, workerName string) {
-	for i := range chunks {
-		if chunks[i].Type != AIChunk {
+// applyWorkerTaskUpdate applies a worker's TaskUpdate to the matching task.
+// If the update has no explicit owner field, the worker's own name is used
+// as fallback — workers typically claim tasks by setting themselves as
+// owner, but the field is optional.
+func applyWorkerTaskUpdate(input json.RawMessage, team *TeamSnapshot, workerName string) {
+	fields := parseInputFields(input)
+	taskID := getString(fields, "taskId")
+	if taskID == "" {
+		return
+	}
+	for k := range team.Tasks {
+		if team.Tasks[k].ID != taskID {
 			continue
 		}
-		for j := range chunks[i].Items {
-			it := &chunks[i].Items[j]
-			if it.Type != ItemToolCall || it.ToolName != "TaskUpdate" {
-				continue
-			}
-			fields := parseInputFields(it.ToolInput)
-			taskID := getString(fields, "taskId")
-			if taskID == "" {
-				continue
-			}
-			for k := range team.Tasks {
-				if team.Tasks[k].ID != taskID {
-					continue
-				}
-				if status := getString(fields, "status"); status != "" {
-					team.Tasks[k].Status = status
-				}
-				if owner := getString(fields, "owner"); owner != "" {
-					team.Tasks[k].Owner = owner
-				} else if team.Tasks[k].Owner == "" {
-					team.Tasks[k].Owner = workerName
-				}
-				if subject := getString(fields, "subject"); subject != "" {
-					team.Tasks[k].Subject = subject
-				}
-			}
+		if status := getString(fields, "status"); status != "" {
+			team.Tasks[k].Status = status
+		}
+		if owner := getString(fields, "owner"); owner != "" {
+			team.Tasks[k].Owner = owner
+		} else if team.Tasks[k].Owner == "" {
+			team.Tasks[k].Owner = workerName
+		}
+		if subject := getString(fields, "subject"); subject != "" {
+			team.Tasks[k].Subject = subject
 		}
 	}
 }
@@ -584,3 +584,59 @@ func TestReconstructTeams_NonTeamWorkerIgnored(t *testing.T) {
 		t.Errorf("Status = %q, want %q (non-team worker)", teams[0].Tasks[0].Status, "pending")
 	}
 }
+
+func TestTeamTracker_Incremental(t *testing.T) {
+	tr := parser.NewTeamTracker()
+	if got := tr.Snapshots(); got != nil {
+		t.Fatalf("empty tracker Snapshots() = %+v, want nil", got)
+	}
+
+	create := makeToolCallItem("TeamCreate", map[string]interface{}{"team_name": "proj"})
+	tr.ApplyChunk(&create)
+	task := makeToolCallItem("TaskCreate", map[string]interface{}{"subject": "Task one"})
+	tr.ApplyChunk(&task)
+	spawn := makeTeamSpawnItem("proj", "worker-a")
+	tr.ApplyChunk(&spawn)
+
+	before := tr.Snapshots()
+	if len(before) != 1 || len(before[0].Tasks) != 1 || before[0].Tasks[0].Status != "pending" {
+		t.Fatalf("after lead chunks: %+v", before)
+	}
+	if len(before[0].Members) != 1 || before[0].Members[0] != "worker-a" {
+		t.Errorf("Members = %v, want [worker-a]", before[0].Members)
+	}
+
+	// A worker claims the task later; earlier snapshots must not change.
+	claim := makeToolCallItem("TaskUpdate", map[string]interface{}{"taskId": "1", "status": "in_progress"})
+	tr.ApplyWorkerChunk("worker-a@proj", &claim)
+	tr.SetMemberState("worker-a@proj", "blue", true)
+
+	after := tr.Snapshots()
+	if got := after[0].Tasks[0]; got.Status != "in_progress" || got.Owner != "worker-a" {
+		t.Errorf("task after worker update = %+v, want in_progress owned by worker-a", got)
+	}
+	if before[0].Tasks[0].Status != "pending" {
+		t.Error("earlier snapshot changed after ApplyWorkerChunk")
+	}
+	if after[0].MemberColors["worker-a"] != "blue" || !after[0].MemberOngoing["worker-a"] {
+		t.Errorf("member state = %v %v", after[0].MemberColors, after[0].MemberOngoing)
+	}
+
+	// Ongoing clears when the worker's session settles.
+	tr.SetMemberState("worker-a@proj", "", false)
+	final := tr.Snapshots()
+	if final[0].MemberOngoing["worker-a"] {
+		t.Error("MemberOngoing should clear")
+	}
+	if final[0].MemberColors["worker-a"] != "blue" {
+		t.Error("empty color should keep the previous color")
+	}
+
+	// Non-team workers and unknown teams are ignored.
+	tr.ApplyWorkerChunk("abc123", &claim)
+	tr.ApplyWorkerChunk("worker-b@other", &claim)
+	tr.SetMemberState("worker-b@other", "red", true)
+	if got := tr.Snapshots(); len(got) != 1 || len(got[0].MemberColors) != 1 {
+		t.Errorf("unexpected state from unrelated workers: %+v", got)
+	}
+}
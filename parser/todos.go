@@ -0,0 +1,57 @@
+package parser
+
+import "encoding/json"
+
+// Todo is one item of the checklist Claude keeps with TodoWrite.
+type Todo struct {
+	Content    string `json:"content"`
+	Status     string `json:"status"` // "pending" | "in_progress" | "completed"
+	ActiveForm string `json:"activeForm,omitempty"`
+}
+
+// Done reports whether the todo was completed.
+func (t Todo) Done() bool { return t.Status == "completed" }
+
+// ParseTodoWrite returns the list a TodoWrite call's input sets, or nil if
+// the input has no todos array.
+func ParseTodoWrite(input json.RawMessage) []Todo {
+	fields := parseInputFields(input)
+	raw, ok := fields["todos"]
+	if !ok {
+		return nil
+	}
+	var todos []Todo
+	if err := json.Unmarshal(raw, &todos); err != nil {
+		return nil
+	}
+	return todos
+}
+
+// FinalTodos returns the todo list as the session's last TodoWrite call
+// left it, or nil if it never made one. Each call replaces the whole list,
+// so the last one is the state the session ended in. Calls that failed
+// changed nothing and are skipped.
+func FinalTodos(chunks []Chunk) []Todo {
+	var final []Todo
+	for _, c := range chunks {
+		for _, it := range c.Items {
+			if it.Type == ItemToolCall && it.ToolName == "TodoWrite" && !it.ToolError {
+				if todos := ParseTodoWrite(it.ToolInput); todos != nil {
+					final = todos
+				}
+			}
+		}
+	}
+	return final
+}
+
+// UnfinishedTodos returns the todos not completed, in list order.
+func UnfinishedTodos(todos []Todo) []Todo {
+	var out []Todo
+	for _, t := range todos {
+		if !t.Done() {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func todoWrite(todos ...map[string]interface{}) parser.Chunk {
+	return makeToolCallItem("TodoWrite", map[string]interface{}{"todos": todos})
+}
+
+func TestFinalTodos(t *testing.T) {
+	failed := todoWrite(map[string]interface{}{"content": "Rejected", "status": "pending"})
+	failed.Items[0].ToolError = true
+	chunks := []parser.Chunk{
+		todoWrite(map[string]interface{}{"content": "Read the code", "status": "in_progress"}),
+		makeToolCallItem("Bash", map[string]interface{}{"command": "ls"}),
+		todoWrite(
+			map[string]interface{}{"content": "Read the code", "status": "completed"},
+			map[string]interface{}{"content": "Fix the bug", "status": "in_progress", "activeForm": "Fixing the bug"},
+			map[string]interface{}{"content": "Write tests", "status": "pending"},
+		),
+		failed,
+	}
+
+	final := parser.FinalTodos(chunks)
+	want := []parser.Todo{
+		{Content: "Read the code", Status: "completed"},
+		{Content: "Fix the bug", Status: "in_progress", ActiveForm: "Fixing the bug"},
+		{Content: "Write tests", Status: "pending"},
+	}
+	if !reflect.DeepEqual(final, want) {
+		t.Fatalf("FinalTodos = %+v, want %+v", final, want)
+	}
+	if got := parser.UnfinishedTodos(final); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("UnfinishedTodos = %+v, want %+v", got, want[1:])
+	}
+
+	if got := parser.FinalTodos(chunks[1:2]); got != nil {
+		t.Errorf("no TodoWrite: FinalTodos = %+v, want nil", got)
+	}
+}
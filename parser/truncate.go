@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// Widths here are terminal cells, measured per grapheme cluster as lipgloss
+// measures them: CJK characters and most emoji take two cells, combining
+// marks and joiners none. Cuts fall between clusters, so a multibyte rune,
+// an emoji sequence, or a flag is never split into invalid UTF-8 or a
+// half-drawn glyph.
+
+// scanWidth walks s by grapheme cluster and returns the byte length of its
+// longest prefix at most keep cells wide, and whether all of s fits in limit
+// cells. It stops at the first cluster past limit, so cutting a short prefix
+// off a long tool result doesn't scan the whole of it. Newlines count one
+// cell: the truncating callers show them as spaces.
+func scanWidth(s string, keep, limit int) (cut int, fits bool) {
+	width, state := 0, -1
+	for rest := s; rest != ""; {
+		var cluster string
+		var w int
+		cluster, rest, w, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if cluster == "\n" || cluster == "\r\n" {
+			w = 1
+		}
+		width += w
+		if width > limit {
+			return cut, false
+		}
+		if width <= keep {
+			cut = len(s) - len(rest)
+		}
+	}
+	return len(s), true
+}
+
+// CutWidth splits s after its longest prefix at most width cells wide. The
+// head holds at least one grapheme cluster when s is non-empty, even one
+// wider than width, so callers wrapping text always make progress.
+func CutWidth(s string, width int) (head, rest string) {
+	cut, _ := scanWidth(s, width, width)
+	if cut == 0 && s != "" {
+		cluster, _, _, _ := uniseg.FirstGraphemeClusterInString(s, -1)
+		cut = len(cluster)
+	}
+	return s[:cut], s[cut:]
+}
+
+// Truncate shortens a string to maxLen cells, appending an ellipsis if
+// truncated. The result is at most maxLen cells wide; a wide character that
+// would straddle the limit is dropped whole. Collapses newlines to spaces
+// since summaries are single-line display strings.
+func Truncate(s string, maxLen int) string {
+	cut, fits := scanWidth(s, maxLen-1, maxLen)
+	if fits {
+		return strings.ReplaceAll(s, "\n", " ")
+	}
+	return strings.ReplaceAll(s[:cut], "\n", " ") + ellipsis
+}
+
+// TruncateWord shortens a string to maxLen cells, breaking at the nearest
+// preceding word boundary (space). Searches up to 20 characters back from
+// the cut point. Falls back to hard truncation if no space is found.
+func TruncateWord(s string, maxLen int) string {
+	cut, fits := scanWidth(s, maxLen-1, maxLen)
+	if fits {
+		return s
+	}
+	head := s[:cut]
+	if strings.HasPrefix(s[cut:], " ") {
+		return head + ellipsis
+	}
+	floor := len(head)
+	for range 20 {
+		if floor == 0 {
+			break
+		}
+		_, size := utf8.DecodeLastRuneInString(head[:floor])
+		floor -= size
+	}
+	if i := strings.LastIndexByte(head[floor:], ' '); i >= 0 {
+		return head[:floor+i] + ellipsis
+	}
+	return head + ellipsis
+}
@@ -0,0 +1,75 @@
+package parser_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+	"github.com/rivo/uniseg"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"short ASCII unchanged", "hello", 10, "hello"},
+		{"ASCII cut", "hello world", 8, "hello w…"},
+		{"newlines collapsed", "a\nb", 10, "a b"},
+		{"CJK counts two cells", "日本語のテキスト", 7, "日本語…"},
+		{"CJK fits exactly", "日本語", 6, "日本語"},
+		{"wide char straddling the limit dropped", "ab日本", 4, "ab…"},
+		{"emoji counts two cells", "ok 🎉🎉🎉", 7, "ok 🎉…"},
+		{"ZWJ sequence kept whole", "👨‍👩‍👧👨‍👩‍👧 family", 4, "👨‍👩‍👧…"},
+		{"flag kept whole", "🇯🇵🇫🇷🇩🇪", 5, "🇯🇵🇫🇷…"},
+		{"combining mark stays on its letter", "café au lait", 5, "café…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parser.Truncate(tt.input, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.input, tt.maxLen, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Truncate(%q, %d) = %q is not valid UTF-8", tt.input, tt.maxLen, got)
+			}
+			if w := uniseg.StringWidth(got); w > tt.maxLen {
+				t.Errorf("Truncate(%q, %d) is %d cells wide", tt.input, tt.maxLen, w)
+			}
+		})
+	}
+}
+
+func TestTruncate_NeverSplitsRunes(t *testing.T) {
+	s := "混合 text with 絵文字 🎉 and ünïcödé"
+	for n := 1; n <= uniseg.StringWidth(s)+1; n++ {
+		got := parser.Truncate(s, n)
+		if !utf8.ValidString(got) || uniseg.StringWidth(got) > n {
+			t.Fatalf("Truncate(s, %d) = %q: valid %v, %d cells", n, got, utf8.ValidString(got), uniseg.StringWidth(got))
+		}
+		if w := parser.TruncateWord(s, n); !utf8.ValidString(w) || uniseg.StringWidth(w) > n {
+			t.Fatalf("TruncateWord(s, %d) = %q: valid %v, %d cells", n, w, utf8.ValidString(w), uniseg.StringWidth(w))
+		}
+	}
+}
+
+func TestTruncateWord_CJK(t *testing.T) {
+	got := parser.TruncateWord("こんにちは 世界の皆さん", 14)
+	if got != "こんにちは…" {
+		t.Errorf("TruncateWord = %q, want a break at the space", got)
+	}
+}
+
+func TestCutWidth(t *testing.T) {
+	head, rest := parser.CutWidth("日本語", 3)
+	if head != "日" || rest != "本語" {
+		t.Errorf("CutWidth = %q, %q, want 日, 本語", head, rest)
+	}
+	// A cluster wider than the width still makes progress.
+	head, rest = parser.CutWidth("日本", 1)
+	if head != "日" || rest != "本" {
+		t.Errorf("CutWidth(width 1) = %q, %q, want 日, 本", head, rest)
+	}
+}
@@ -0,0 +1,255 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TurnSummary is a one-line digest of an AI turn for the outline and
+// collapsed cards.
+type TurnSummary struct {
+	Text     string // first sentence of the turn's last text block, markdown stripped
+	Activity string // tool activity: "edited 3 files, ran tests ✓"
+}
+
+// String joins Text and Activity with a middle dot, omitting empty parts.
+func (s TurnSummary) String() string {
+	switch {
+	case s.Text == "":
+		return s.Activity
+	case s.Activity == "":
+		return s.Text
+	}
+	return s.Text + " \u00B7 " + s.Activity
+}
+
+// reTestCommand matches Bash commands that run a test suite.
+var reTestCommand = regexp.MustCompile(`\b(go test|pytest|cargo test|npm (run )?test|yarn test|pnpm test|bun test|jest|vitest|rspec|make test|just test)\b`)
+
+// reListMarker matches a leading markdown list marker ("- ", "* ", "1. ").
+var reListMarker = regexp.MustCompile(`^([-*+]|\d+[.)])\s+`)
+
+// SummarizeTurn builds a TurnSummary from an AI chunk. The text prefers the
+// last Output item over the flat chunk text, since the final block is where
+// Claude states what it did.
+func SummarizeTurn(c Chunk) TurnSummary {
+	text := c.Text
+	for i := len(c.Items) - 1; i >= 0; i-- {
+		if c.Items[i].Type == ItemOutput && strings.TrimSpace(c.Items[i].Text) != "" {
+			text = c.Items[i].Text
+			break
+		}
+	}
+	return TurnSummary{
+		Text:     FirstSentence(StripMarkdown(text)),
+		Activity: summarizeActivity(c.Items),
+	}
+}
+
+// StripMarkdown removes fenced code blocks, headers, list markers, quotes,
+// emphasis, and inline code markers, leaving plain prose lines. Tables and
+// horizontal rules are dropped.
+func StripMarkdown(s string) string {
+	var out []string
+	inFence := false
+	for _, line := range strings.Split(s, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.HasPrefix(trimmed, "|") || isHorizontalRule(trimmed) {
+			continue
+		}
+		trimmed = strings.TrimLeft(trimmed, "#>")
+		trimmed = reListMarker.ReplaceAllString(strings.TrimSpace(trimmed), "")
+		trimmed = strings.NewReplacer("**", "", "__", "", "`", "").Replace(trimmed)
+		out = append(out, strings.TrimSpace(trimmed))
+	}
+	return strings.Join(out, "\n")
+}
+
+// isHorizontalRule reports whether a trimmed line is a markdown rule (---, ***, ___).
+func isHorizontalRule(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+	return strings.Trim(s, "-") == "" || strings.Trim(s, "*") == "" || strings.Trim(s, "_") == ""
+}
+
+// FirstSentence returns the first sentence of the first non-empty line.
+func FirstSentence(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		for _, sep := range []string{". ", "! ", "? "} {
+			if i := strings.Index(line, sep); i >= 0 {
+				line = line[:i+1]
+			}
+		}
+		return line
+	}
+	return ""
+}
+
+// CurrentStep describes what a live agent is doing from the last item of its
+// latest AI chunk: "Reading chunk.go…", "Run the test suite…", "Thinking…",
+// or the first sentence of its latest text. Returns "" before the agent's
+// first AI item.
+func CurrentStep(chunks []Chunk) string {
+	for i := len(chunks) - 1; i >= 0; i-- {
+		c := chunks[i]
+		if c.Type != AIChunk || len(c.Items) == 0 {
+			continue
+		}
+		it := c.Items[len(c.Items)-1]
+		switch it.Type {
+		case ItemThinking:
+			return "Thinking" + ellipsis
+		case ItemOutput:
+			return FirstSentence(StripMarkdown(it.Text))
+		case ItemSubagent:
+			if it.SubagentDesc != "" {
+				return "Delegating: " + it.SubagentDesc
+			}
+			return "Delegating" + ellipsis
+		case ItemToolCall:
+			return toolStep(it) + ellipsis
+		}
+		return ""
+	}
+	return ""
+}
+
+// toolStep phrases a tool call as an activity: "Reading chunk.go". Bash
+// prefers the call's own description over the raw command.
+func toolStep(it DisplayItem) string {
+	var verb string
+	switch it.ToolCategory {
+	case CategoryRead:
+		verb = "Reading"
+	case CategoryEdit:
+		verb = "Editing"
+	case CategoryWrite:
+		verb = "Writing"
+	case CategoryGrep, CategoryGlob:
+		verb = "Searching"
+	case CategoryWeb:
+		verb = "Fetching"
+		if it.ToolName == "WebSearch" {
+			verb = "Searching the web for"
+		}
+	case CategoryBash:
+		if desc := toolInputString(it.ToolInput, "description"); desc != "" {
+			return Truncate(desc, 60)
+		}
+		return "Running " + Truncate(toolInputString(it.ToolInput, "command"), 50)
+	default:
+		verb = it.ToolName
+	}
+	if it.ToolSummary == "" || it.ToolSummary == it.ToolName {
+		return verb
+	}
+	return verb + " " + it.ToolSummary
+}
+
+// summarizeActivity describes a turn's tool use in a short comma list.
+// Files are counted by distinct path so repeated edits to one file count once.
+func summarizeActivity(items []DisplayItem) string {
+	edited := make(map[string]bool)
+	read := make(map[string]bool)
+	var commands, tests, agents int
+	testsFailed := false
+
+	for _, it := range items {
+		switch it.Type {
+		case ItemSubagent:
+			agents++
+		case ItemToolCall:
+			switch it.ToolCategory {
+			case CategoryEdit, CategoryWrite:
+				edited[toolInputPath(it.ToolInput, it.ToolSummary)] = true
+			case CategoryRead:
+				read[toolInputPath(it.ToolInput, it.ToolSummary)] = true
+			case CategoryBash:
+				if reTestCommand.MatchString(toolInputString(it.ToolInput, "command")) {
+					tests++
+					testsFailed = testsFailed || it.ToolError
+				} else {
+					commands++
+				}
+			}
+		}
+	}
+
+	var parts []string
+	if n := len(edited); n > 0 {
+		parts = append(parts, "edited "+Plural(n, "file"))
+	}
+	if n := len(read); n > 0 {
+		parts = append(parts, "read "+Plural(n, "file"))
+	}
+	if tests > 0 {
+		mark := "\u2713" // check mark
+		if testsFailed {
+			mark = "\u2717" // ballot x
+		}
+		parts = append(parts, "ran tests "+mark)
+	}
+	if commands > 0 {
+		parts = append(parts, "ran "+Plural(commands, "command"))
+	}
+	if agents > 0 {
+		parts = append(parts, "spawned "+Plural(agents, "agent"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// toolInputPath returns the file path a tool operated on, falling back to
+// its display summary when the input has no path field.
+func toolInputPath(input json.RawMessage, fallback string) string {
+	for _, key := range []string{"file_path", "notebook_path", "path"} {
+		if p := toolInputString(input, key); p != "" {
+			return p
+		}
+	}
+	return fallback
+}
+
+// ToolFilePath returns the file path a tool operated on, or "" when its input
+// has no path field.
+func ToolFilePath(input json.RawMessage) string {
+	return toolInputPath(input, "")
+}
+
+// ToolTarget returns what a tool acted on: its file path, shell command, URL,
+// or search pattern. Returns "" when the input has none of them.
+func ToolTarget(input json.RawMessage) string {
+	for _, key := range []string{"file_path", "notebook_path", "path", "command", "url", "pattern"} {
+		if v := toolInputString(input, key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// toolInputString extracts a string field from tool input JSON.
+func toolInputString(input json.RawMessage, key string) string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(input, &fields) != nil {
+		return ""
+	}
+	return getString(fields, key)
+}
+
+// Plural formats "1 file" / "3 files".
+func Plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
@@ -0,0 +1,136 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestSummarizeTurn_Fixture(t *testing.T) {
+	// Fixture: read + two edits of one file, a new test file, a passing test
+	// run, then a final text block that opens with a code fence.
+	path := filepath.Join("testdata", "turn_summary.jsonl")
+	chunks, err := parser.ReadSession(path)
+	if err != nil {
+		t.Fatalf("ReadSession(%q) error: %v", path, err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("len(chunks) = %d, want 2 (user + AI)", len(chunks))
+	}
+
+	got := parser.SummarizeTurn(chunks[1])
+	if want := "Fixed the parse off-by-one."; got.Text != want {
+		t.Errorf("Text = %q, want %q", got.Text, want)
+	}
+	if want := "edited 2 files, read 1 file, ran tests ✓"; got.Activity != want {
+		t.Errorf("Activity = %q, want %q", got.Activity, want)
+	}
+}
+
+func TestSummarizeTurn_FailedTestsAndCommands(t *testing.T) {
+	c := parser.Chunk{
+		Type: parser.AIChunk,
+		Text: "Tests are failing.",
+		Items: []parser.DisplayItem{
+			{Type: parser.ItemToolCall, ToolName: "Bash", ToolCategory: parser.CategoryBash, ToolInput: json.RawMessage(`{"command":"git status"}`)},
+			{Type: parser.ItemToolCall, ToolName: "Bash", ToolCategory: parser.CategoryBash, ToolInput: json.RawMessage(`{"command":"npm test"}`), ToolError: true},
+			{Type: parser.ItemSubagent, ToolName: "Task"},
+		},
+	}
+	got := parser.SummarizeTurn(c)
+	if got.Text != "Tests are failing." {
+		t.Errorf("Text = %q, want flat text fallback", got.Text)
+	}
+	if want := "ran tests ✗, ran 1 command, spawned 1 agent"; got.Activity != want {
+		t.Errorf("Activity = %q, want %q", got.Activity, want)
+	}
+}
+
+func TestTurnSummaryString(t *testing.T) {
+	tests := []struct {
+		in   parser.TurnSummary
+		want string
+	}{
+		{parser.TurnSummary{}, ""},
+		{parser.TurnSummary{Text: "Done."}, "Done."},
+		{parser.TurnSummary{Activity: "edited 1 file"}, "edited 1 file"},
+		{parser.TurnSummary{Text: "Done.", Activity: "edited 1 file"}, "Done. · edited 1 file"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"## Summary", "Summary"},
+		{"- **Bold** item", "Bold item"},
+		{"1. Use `go vet`", "Use go vet"},
+		{"> quoted", "quoted"},
+		{"```\ncode\n```\nafter", "after"},
+		{"---\n| a | b |\ntext", "text"},
+	}
+	for _, tt := range tests {
+		if got := parser.StripMarkdown(tt.input); got != tt.want {
+			t.Errorf("StripMarkdown(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFirstSentence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"Done.", "Done."},
+		{"Fixed the bug. Tests pass now.", "Fixed the bug."},
+		{"\n\n  Really? Yes.", "Really?"},
+		{"No terminator here\nsecond line", "No terminator here"},
+		{"Version 1.2.3 is out. Upgrade.", "Version 1.2.3 is out."},
+	}
+	for _, tt := range tests {
+		if got := parser.FirstSentence(tt.input); got != tt.want {
+			t.Errorf("FirstSentence(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCurrentStep(t *testing.T) {
+	ai := func(items ...parser.DisplayItem) []parser.Chunk {
+		return []parser.Chunk{
+			{Type: parser.UserChunk, UserText: "investigate"},
+			{Type: parser.AIChunk, Items: items},
+		}
+	}
+	read := parser.DisplayItem{Type: parser.ItemToolCall, ToolName: "Read", ToolCategory: parser.CategoryRead, ToolSummary: "parser/chunk.go"}
+
+	tests := []struct {
+		name   string
+		chunks []parser.Chunk
+		want   string
+	}{
+		{"no AI yet", ai(), ""},
+		{"latest tool call", ai(parser.DisplayItem{Type: parser.ItemThinking}, read), "Reading parser/chunk.go\u2026"},
+		{"thinking", ai(read, parser.DisplayItem{Type: parser.ItemThinking}), "Thinking\u2026"},
+		{"bash description", ai(parser.DisplayItem{Type: parser.ItemToolCall, ToolName: "Bash", ToolCategory: parser.CategoryBash,
+			ToolInput: json.RawMessage(`{"command":"go test ./...","description":"Run the tests"}`)}), "Run the tests\u2026"},
+		{"bash command", ai(parser.DisplayItem{Type: parser.ItemToolCall, ToolName: "Bash", ToolCategory: parser.CategoryBash,
+			ToolInput: json.RawMessage(`{"command":"go vet ./..."}`)}), "Running go vet ./...\u2026"},
+		{"output", ai(read, parser.DisplayItem{Type: parser.ItemOutput, Text: "Found **two** callers. Details follow."}), "Found two callers."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.CurrentStep(tt.chunks); got != tt.want {
+				t.Errorf("CurrentStep() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
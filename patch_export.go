@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// patchEdit is one Edit, MultiEdit, or Write in a patch export.
+type patchEdit struct {
+	turn   int    // 1-based index of the user prompt the edit answered; 0 before the first
+	prompt string // first line of that prompt
+	agent  string // "main" or the subagent label
+	tool   string
+	time   string
+	path   string            // absolute, as the tool call named it
+	patch  *parser.FilePatch // nil when the transcript has no patch for the call
+}
+
+// isEditTool reports whether a tool changes files in a way a patch export
+// can replay.
+func isEditTool(name string) bool {
+	return name == "Edit" || name == "MultiEdit" || name == "Write"
+}
+
+// buildPatchEdits lists every file change in the session, including those
+// made by subagents (nested ones too), in transcript order. Calls that
+// failed or were rejected changed nothing and are left out.
+func buildPatchEdits(msgs []message) []patchEdit {
+	var edits []patchEdit
+	var turn int
+	var prompt string
+	var visit func(items []displayItem, agent string)
+	visit = func(items []displayItem, agent string) {
+		for _, item := range items {
+			if item.itemType == parser.ItemToolCall && isEditTool(item.toolName) && !item.toolError {
+				e := patchEdit{
+					turn:   turn,
+					prompt: prompt,
+					agent:  agent,
+					tool:   item.toolName,
+					time:   formatTime(item.timestamp),
+					patch:  item.patch,
+				}
+				if item.patch != nil {
+					e.path = item.patch.Path
+				} else {
+					e.path = editFilePath(item.toolInput)
+				}
+				if e.path != "" {
+					edits = append(edits, e)
+				}
+			}
+			if item.subagentProcess != nil {
+				visit(buildTraceItems(item), agentLabel(item.subagentProcess))
+			}
+		}
+	}
+	for _, msg := range msgs {
+		if msg.role == RoleUser {
+			turn++
+			prompt, _, _ = strings.Cut(strings.TrimSpace(msg.content), "\n")
+		}
+		visit(msg.items, mainAgentLabel)
+	}
+	return edits
+}
+
+// editFilePath returns the file_path of an edit tool's input.
+func editFilePath(input string) string {
+	var in struct {
+		FilePath string `json:"file_path"`
+	}
+	if json.Unmarshal([]byte(input), &in) != nil {
+		return ""
+	}
+	return in.FilePath
+}
+
+// patchSeries renders the edits as a series of unified diffs, one patch per
+// turn or, with byFile, one per file. Within a patch each edit is its own
+// diff, in the order it was made: every hunk is numbered against the file as
+// that edit found it, so `git apply` or `patch -p1` replays a patch in
+// sequence on the tree the session started from. Edits whose transcript
+// recorded no patch are noted in comments rather than guessed at.
+func patchSeries(session, cwd string, edits []patchEdit, byFile bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# File changes from session %s, as unified diffs.\n", session)
+	if cwd != "" {
+		fmt.Fprintf(&b, "# Paths are relative to %s; apply with `git apply` or `patch -p1` there.\n", cwd)
+	}
+
+	type group struct {
+		title string
+		edits []patchEdit
+	}
+	var groups []group
+	index := map[string]int{}
+	for _, e := range edits {
+		key, title := fmt.Sprint(e.turn), fmt.Sprintf("turn %d", e.turn)
+		if e.turn == 0 {
+			title = "before the first prompt"
+		} else if e.prompt != "" {
+			title += ": " + parser.Truncate(e.prompt, 72)
+		}
+		if byFile {
+			key = e.path
+			title = patchPath(e.path, cwd)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, group{title: title})
+		}
+		groups[i].edits = append(groups[i].edits, e)
+	}
+
+	for n, g := range groups {
+		fmt.Fprintf(&b, "\n# Patch %d/%d: %s (%s)\n", n+1, len(groups), g.title, parser.Plural(len(g.edits), "edit"))
+		for _, e := range g.edits {
+			writeEditDiff(&b, e, cwd)
+		}
+	}
+	return b.String()
+}
+
+// writeEditDiff writes one edit as a git-style diff, preceded by a comment
+// naming the call.
+func writeEditDiff(b *strings.Builder, e patchEdit, cwd string) {
+	path := patchPath(e.path, cwd)
+	header := "# " + e.tool
+	if e.agent != mainAgentLabel {
+		header += " by " + e.agent
+	}
+	if e.time != "" {
+		header += " at " + e.time
+	}
+	b.WriteString(header + "\n")
+	if e.patch == nil {
+		fmt.Fprintf(b, "# %s: no patch recorded in the transcript; not included\n", path)
+		return
+	}
+	fmt.Fprintf(b, "diff --git a/%s b/%s\n", path, path)
+	if e.patch.Create {
+		b.WriteString("new file mode 100644\n--- /dev/null\n")
+	} else {
+		fmt.Fprintf(b, "--- a/%s\n", path)
+	}
+	fmt.Fprintf(b, "+++ b/%s\n", path)
+	for _, h := range e.patch.Hunks {
+		fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+		for _, line := range h.Lines {
+			b.WriteString(line + "\n")
+		}
+	}
+}
+
+// patchPath is the path a diff names: relative to cwd, without a leading
+// slash for files outside it.
+func patchPath(path, cwd string) string {
+	return strings.TrimPrefix(relPath(path, cwd), "/")
+}
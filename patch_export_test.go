@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestPatchSeries(t *testing.T) {
+	create := &parser.FilePatch{Path: "/repo/hello.txt", Create: true, Hunks: []parser.PatchHunk{
+		{NewStart: 1, NewLines: 3, Lines: []string{"+one", "+two", "+three"}},
+	}}
+	edit := &parser.FilePatch{Path: "/repo/hello.txt", Hunks: []parser.PatchHunk{
+		{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: []string{" one", "-two", "+TWO", " three"}},
+	}}
+	explore := &parser.SubagentProcess{
+		ID:           "a1b2c3d4e5",
+		SubagentType: "Explore",
+		Chunks: []parser.Chunk{
+			{Type: parser.AIChunk, Items: []parser.DisplayItem{
+				{Type: parser.ItemToolCall, ToolName: "Edit", ToolInput: []byte(`{"file_path":"/repo/hello.txt"}`), ToolResult: "ok", Patch: edit},
+			}},
+		},
+	}
+	msgs := []message{
+		userMsg("add hello\nwith three lines"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Write", toolInput: `{"file_path":"/repo/hello.txt"}`, toolResult: "ok", patch: create},
+				{itemType: parser.ItemToolCall, toolName: "Edit", toolInput: `{"file_path":"/repo/gone.go"}`, toolResult: "String not found", toolError: true},
+			}
+		}),
+		userMsg("shout two"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemSubagent, toolName: "Task", subagentType: "Explore", subagentProcess: explore, toolResult: "done"},
+				{itemType: parser.ItemToolCall, toolName: "Edit", toolInput: `{"file_path":"/repo/old.go"}`, toolResult: "ok"},
+			}
+		}),
+	}
+
+	edits := buildPatchEdits(msgs)
+	if len(edits) != 3 {
+		t.Fatalf("got %d edits, want 3 (failed edit left out): %+v", len(edits), edits)
+	}
+	if edits[0].turn != 1 || edits[0].prompt != "add hello" || edits[1].turn != 2 || edits[1].agent != agentLabel(explore) {
+		t.Errorf("edits = %+v", edits)
+	}
+
+	byTurn := patchSeries("abc123", "/repo", edits, false)
+	for _, want := range []string{
+		"# Patch 1/2: turn 1: add hello (1 edit)",
+		"diff --git a/hello.txt b/hello.txt\nnew file mode 100644\n--- /dev/null\n+++ b/hello.txt\n@@ -0,0 +1,3 @@\n+one\n",
+		"# Patch 2/2: turn 2: shout two (2 edits)",
+		"# Edit by " + agentLabel(explore),
+		"--- a/hello.txt\n+++ b/hello.txt\n@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n",
+		"# old.go: no patch recorded in the transcript; not included",
+	} {
+		if !strings.Contains(byTurn, want) {
+			t.Errorf("per-turn series missing %q:\n%s", want, byTurn)
+		}
+	}
+
+	byFile := patchSeries("abc123", "/repo", edits, true)
+	for _, want := range []string{"# Patch 1/2: hello.txt (2 edits)", "# Patch 2/2: old.go (1 edit)"} {
+		if !strings.Contains(byFile, want) {
+			t.Errorf("per-file series missing %q:\n%s", want, byFile)
+		}
+	}
+
+	// The series replays on an empty tree with standard tooling.
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	patchFile := filepath.Join(dir, "series.patch")
+	if err := os.WriteFile(patchFile, []byte(byFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "apply", patchFile)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git apply: %v\n%s", err, out)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "hello.txt"))
+	if err != nil || string(got) != "one\nTWO\nthree\n" {
+		t.Errorf("hello.txt after apply = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// perfPhase is one kind of work timed for the perf overlay.
+type perfPhase int
+
+const (
+	perfLayout    perfPhase = iota // layoutList: rendering every list message
+	perfMarkdown                   // glamour renders
+	perfHighlight                  // search-match and JSON syntax highlighting
+	perfPhases
+)
+
+// perfStats times the work behind each frame for the perf overlay (ctrl+p),
+// a hidden diagnostic for slowness on real sessions. Shared by pointer like
+// mdRenderer, so value-receiver View and the renderers can record into it.
+// Nothing is timed while the overlay is off.
+type perfStats struct {
+	on bool
+
+	// The frame being built: everything since the last View returned.
+	spent [perfPhases]time.Duration
+	calls [perfPhases]int
+
+	last    perfFrame
+	frames  int
+	mallocs uint64 // runtime.MemStats.Mallocs when the last frame ended
+	alloc   uint64 // runtime.MemStats.TotalAlloc when the last frame ended
+}
+
+// perfFrame is what the overlay shows: the last completed frame.
+type perfFrame struct {
+	spent    [perfPhases]time.Duration
+	calls    [perfPhases]int
+	view     time.Duration // View, markdown and highlighting included
+	messages int
+	mallocs  uint64 // allocations during the frame
+	alloc    uint64 // bytes allocated during the frame
+	heap     uint64 // live heap when the frame ended
+	numGC    uint32
+}
+
+// toggle shows or hides the overlay, starting the allocation counts afresh.
+func (p *perfStats) toggle() {
+	*p = perfStats{on: !p.on}
+	if p.on {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		p.mallocs, p.alloc = ms.Mallocs, ms.TotalAlloc
+	}
+}
+
+// start returns the time to hand to record, or the zero time when the
+// overlay is off.
+func (p *perfStats) start() time.Time {
+	if p == nil || !p.on {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// record charges the time since start to phase. Meant for defer:
+//
+//	defer m.perf.record(perfLayout, m.perf.start())
+func (p *perfStats) record(phase perfPhase, start time.Time) {
+	if p == nil || start.IsZero() {
+		return
+	}
+	p.spent[phase] += time.Since(start)
+	p.calls[phase]++
+}
+
+// endFrame closes the frame View started at start and makes it the one the
+// overlay shows.
+func (p *perfStats) endFrame(start time.Time, messages int) {
+	if p == nil || start.IsZero() {
+		return
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	p.last = perfFrame{
+		spent:    p.spent,
+		calls:    p.calls,
+		view:     time.Since(start),
+		messages: messages,
+		mallocs:  ms.Mallocs - p.mallocs,
+		alloc:    ms.TotalAlloc - p.alloc,
+		heap:     ms.HeapAlloc,
+		numGC:    ms.NumGC,
+	}
+	p.spent = [perfPhases]time.Duration{}
+	p.calls = [perfPhases]int{}
+	p.mallocs, p.alloc = ms.Mallocs, ms.TotalAlloc
+	p.frames++
+}
+
+// formatMs renders a duration in milliseconds: "12.34ms".
+func formatMs(d time.Duration) string {
+	return formatDecimal(float64(d.Microseconds())/1000, 2) + "ms"
+}
+
+// perfLines returns the overlay's rows for the last frame. The total is the
+// frame's list layout plus its View; markdown and highlighting happen inside
+// those two.
+func (p *perfStats) perfLines() []string {
+	f := p.last
+	row := func(label, value, note string) string {
+		return fmt.Sprintf("%-10s %10s  %s", label, value, note)
+	}
+	calls := func(phase perfPhase) string {
+		return parser.Plural(f.calls[phase], "call")
+	}
+	return []string{
+		row("frame", fmt.Sprintf("#%d", p.frames), ""),
+		row("layout", formatMs(f.spent[perfLayout]), calls(perfLayout)),
+		row("markdown", formatMs(f.spent[perfMarkdown]), calls(perfMarkdown)),
+		row("highlight", formatMs(f.spent[perfHighlight]), calls(perfHighlight)),
+		row("view", formatMs(f.view), ""),
+		row("total", formatMs(f.spent[perfLayout]+f.view), ""),
+		row("messages", formatCount(f.messages), ""),
+		row("allocs", formatTokens(int(f.mallocs)), formatBytes(float64(f.alloc))),
+		row("heap", formatBytes(float64(f.heap)), parser.Plural(int(f.numGC), "GC")),
+	}
+}
+
+// withPerfOverlay draws the overlay box over the top-right corner of a
+// rendered screen.
+func (m model) withPerfOverlay(content string) string {
+	body := strings.Join(m.perf.perfLines(), "\n")
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorAccent).
+		Padding(0, 1).
+		Render(StyleAccentBold.Render("perf") + "\n" + StyleSecondary.Render(body))
+	x := max(m.width-lipgloss.Width(box), 0)
+	// Canvas.Compose draws a layer over the whole canvas; the compositor is
+	// what places layers at their offsets.
+	return lipgloss.NewCanvas(m.width, max(m.height, lipgloss.Height(content))).
+		Compose(lipgloss.NewCompositor(
+			lipgloss.NewLayer(content),
+			lipgloss.NewLayer(box).X(x).Z(1),
+		)).
+		Render()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPerfOverlay(t *testing.T) {
+	m := testModel()
+	if got := m.perf.start(); !got.IsZero() {
+		t.Fatal("nothing should be timed while the overlay is off")
+	}
+
+	result, _ := m.Update(key("ctrl+p"))
+	m = asModel(result)
+	if !m.perf.on {
+		t.Fatal("ctrl+p should turn the overlay on")
+	}
+
+	m.layoutList()
+	out := m.View().Content
+	if m.perf.frames != 1 {
+		t.Errorf("frames = %d, want 1", m.perf.frames)
+	}
+	if m.perf.last.calls[perfLayout] != 1 || m.perf.last.messages != len(m.messages) {
+		t.Errorf("last frame = %+v, want one layout of %d messages", m.perf.last, len(m.messages))
+	}
+	if m.perf.last.calls[perfMarkdown] == 0 {
+		t.Error("layout should have timed its markdown renders")
+	}
+	for _, want := range []string{"perf", "layout", "markdown", "highlight", "total", "allocs", "heap"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("overlay missing %q", want)
+		}
+	}
+	// The box sits in the top-right corner, over the list rather than instead of it.
+	if !strings.Contains(out, "Hello, world") {
+		t.Errorf("list hidden under the overlay:\n%s", out)
+	}
+	if first, _, _ := strings.Cut(out, "\n"); !strings.HasSuffix(strings.TrimRight(first, " "), "╮") || strings.HasPrefix(first, "╭") {
+		t.Errorf("overlay not in the top-right corner: %q", first)
+	}
+
+	result, _ = m.Update(key("ctrl+p"))
+	m = asModel(result)
+	if m.perf.on || strings.Contains(m.View().Content, "markdown") {
+		t.Error("ctrl+p again should hide the overlay")
+	}
+}
+
+func TestPerfStats_NilSafe(t *testing.T) {
+	var p *perfStats
+	p.record(perfLayout, p.start())
+	p.endFrame(time.Now(), 0)
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/kylesnowschwartz/tail-claude/parser"
 
@@ -51,10 +52,11 @@ func loadPickerSessionsCmd(projectDirs []string, cache *parser.SessionCache) tea
 }
 
 // loadSessionCmd returns a command that loads a session file into messages.
-// Delegates to loadSession so the parsing pipeline lives in one place.
-func loadSessionCmd(path string) tea.Cmd {
+// Delegates to loadSource so the parsing pipeline lives in one place; turns
+// above 0 reads only the session's last turns.
+func loadSessionCmd(path string, turns int) tea.Cmd {
 	return func() tea.Msg {
-		result, err := loadSession(path)
+		result, err := loadSource(parser.FileSource(path), turns)
 		if err != nil {
 			return loadSessionMsg{err: err}
 		}
@@ -145,6 +147,41 @@ func (m model) updatePicker(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		m.ensurePickerVisible()
 	case "g":
 		m.pickerCursorFirst()
+	case "space":
+		// Mark for export and move on, so runs of sessions mark quickly.
+		if s := m.pickerSelectedSession(); s != nil {
+			if m.pickerMarked == nil {
+				m.pickerMarked = make(map[string]bool)
+			}
+			if m.pickerMarked[s.Path] {
+				delete(m.pickerMarked, s.Path)
+			} else {
+				m.pickerMarked[s.Path] = true
+			}
+			m.pickerCursorDown()
+			m.ensurePickerVisible()
+		}
+	case "/":
+		m.openProjectSearch()
+	case "A":
+		cmd := m.openLeaderboard()
+		return m, cmd
+	case "C":
+		cmd := m.openCleanup()
+		return m, cmd
+	case "x", "X":
+		format := exportMarkdown
+		if msg.String() == "X" {
+			format = exportJSON
+		}
+		if m.readOnly {
+			m.flashStatus = "Read-only: exports are off"
+			return m, flashClearCmd()
+		}
+		if paths := m.pickerExportPaths(); len(paths) > 0 {
+			m.flashStatus = fmt.Sprintf("Exporting %s...", pluralCount(len(paths), "session"))
+			return m, exportSessionsCmd(m.viewContext(), paths, format, exportDir)
+		}
 	case "tab":
 		if m.pickerExpanded == nil {
 			m.pickerExpanded = make(map[int]bool)
@@ -159,17 +196,17 @@ func (m model) updatePicker(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 				m.pickerWatcher.stop()
 				m.pickerWatcher = nil
 			}
-			return m, loadSessionCmd(s.Path)
+			cmd := m.openSession(s.Path)
+			return m, cmd
 		}
 	case "b":
 		if len(m.worktreeProjectDirs) == 0 {
 			return m, nil
 		}
 		m.pickerWorktreeMode = !m.pickerWorktreeMode
+		m.projectDirs = m.homeProjectDirs()
 		if m.pickerWorktreeMode {
-			m.projectDirs = dedup(append([]string{m.projectDir}, m.worktreeProjectDirs...))
-		} else {
-			m.projectDirs = []string{m.projectDir}
+			m.projectDirs = dedup(append(m.projectDirs, m.worktreeProjectDirs...))
 		}
 		if m.pickerWatcher != nil {
 			m.pickerWatcher.stop()
@@ -178,6 +215,9 @@ func (m model) updatePicker(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		m.pickerLoading = true
 		m.pickerTickActive = true
 		return m, tea.Batch(loadPickerSessionsCmd(m.projectDirs, m.sessionCache), pickerTickCmd())
+	case "+":
+		cmd := m.toggleExactTokens()
+		return m, cmd
 	case "?":
 		m.showKeybinds = !m.showKeybinds
 		m.ensurePickerVisible()
@@ -210,6 +250,23 @@ func (m model) pickerSelectedSession() *parser.SessionInfo {
 	return item.session
 }
 
+// pickerExportPaths returns the marked sessions in list order, or the
+// session under the cursor when none are marked.
+func (m model) pickerExportPaths() []string {
+	var paths []string
+	for _, item := range m.pickerItems {
+		if item.typ == pickerItemSession && m.pickerMarked[item.session.Path] {
+			paths = append(paths, item.session.Path)
+		}
+	}
+	if len(paths) == 0 {
+		if s := m.pickerSelectedSession(); s != nil {
+			paths = append(paths, s.Path)
+		}
+	}
+	return paths
+}
+
 // pickerCursorDown moves cursor to next session item (skipping headers).
 func (m *model) pickerCursorDown() {
 	for i := m.pickerCursor + 1; i < len(m.pickerItems); i++ {
@@ -274,6 +331,16 @@ func (m *model) ensurePickerVisible() {
 	}
 }
 
+// pickerListed reports whether the picker lists the session at path.
+func (m model) pickerListed(path string) bool {
+	for _, s := range m.pickerSessions {
+		if s.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
 // updatePickerSessionState sets derived state: ongoing flag, uniform model, tick.
 // Called when sessions arrive or refresh. Uses the same rising/falling-edge
 // grace period as the main session watcher to avoid spinner churn when a
@@ -380,9 +447,18 @@ func (m model) viewPicker() string {
 	// Header
 	header := StyleAccentBold.Render("Sessions") + " " +
 		StyleDim.Render(fmt.Sprintf("(%d)", len(m.pickerSessions)))
+	if n := len(m.pickerMarked); n > 0 {
+		header += " " + Icon.Task.Done.Render() + " " + StyleSecondary.Render(fmt.Sprintf("%d marked", n))
+	}
+	if m.workspace != nil {
+		header += " " + StyleMuted.Render(m.workspace.name)
+	}
 	if m.pickerWorktreeMode {
 		header += " " + Icon.Branch.Render() + " " + StyleMuted.Render("worktrees")
 	}
+	if age := parser.Discovery.MaxAge; age > 0 {
+		header += " " + StyleMuted.Render("last "+formatMaxAge(age))
+	}
 	header += "\n"
 
 	// Empty state
@@ -391,6 +467,9 @@ func (m model) viewPicker() string {
 			frame := SpinnerFrames[m.pickerAnimFrame%len(SpinnerFrames)]
 			return header + "\n" + StyleDim.Render(frame+" Loading sessions...")
 		}
+		if age := parser.Discovery.MaxAge; age > 0 {
+			return header + "\n" + StyleDim.Render("No sessions in the last "+formatMaxAge(age)+" (--max-age).")
+		}
 		return header + "\n" + StyleDim.Render("No sessions found for this project.")
 	}
 
@@ -402,12 +481,13 @@ func (m model) viewPicker() string {
 	// Center content within the terminal when wider than the content cap.
 	content = centerBlock(content, width, m.width)
 
-	// Pad to fill viewport so footer stays at bottom.
-	targetLines := m.height - m.footerHeight()
+	// Pad to fill viewport so the stats line and footer stay at bottom.
+	targetLines := m.height - m.footerHeight() - pickerStatsHeight
 	renderedLines := strings.Count(content, "\n") + 1
 	if renderedLines < targetLines {
 		content += strings.Repeat("\n", targetLines-renderedLines)
 	}
+	content += "\n" + centerBlock(m.renderPickerStats(width), width, m.width)
 
 	// Scroll position indicator.
 	scrollInfo := m.pickerScrollInfo()
@@ -416,6 +496,12 @@ func (m model) viewPicker() string {
 		"j/k", "nav",
 		"tab", "preview",
 		"enter", "open",
+		"space", "mark",
+		"x/X", "export md/json",
+		"/", "search all",
+		"A", "agents",
+		"C", "cleanup",
+		"+", "exact tokens",
 	}
 	if len(m.worktreeProjectDirs) > 0 {
 		if m.pickerWorktreeMode {
@@ -510,6 +596,14 @@ func (m model) renderPickerSession(s *parser.SessionInfo, isSelected bool, width
 	// --- Line 1: ongoing dot + preview text ---
 	var line1Parts []string
 
+	if m.pickerMarked[s.Path] {
+		markStyle := lipgloss.NewStyle().Foreground(Icon.Task.Done.Color)
+		if isSelected {
+			markStyle = markStyle.Background(ColorPickerSelectedBg)
+		}
+		line1Parts = append(line1Parts, markStyle.Render(Icon.Task.Done.Glyph+" "))
+	}
+
 	if s.IsOngoing {
 		frame := SpinnerFrames[m.pickerAnimFrame%len(SpinnerFrames)]
 		spinStyle := lipgloss.NewStyle().Foreground(ColorOngoing)
@@ -534,6 +628,9 @@ func (m model) renderPickerSession(s *parser.SessionInfo, isSelected bool, width
 	if s.IsOngoing {
 		previewMaxWidth -= 2
 	}
+	if m.pickerMarked[s.Path] {
+		previewMaxWidth -= 2
+	}
 	previewMaxWidth = max(previewMaxWidth, 20)
 	if lipgloss.Width(preview) > previewMaxWidth {
 		preview = parser.TruncateWord(preview, previewMaxWidth)
@@ -554,6 +651,13 @@ func (m model) renderPickerSession(s *parser.SessionInfo, isSelected bool, width
 	var metaParts []string
 	dot := Icon.Dot.Render()
 
+	// Workspace root, padded so the rest of the line stays aligned.
+	if m.workspace != nil {
+		label := m.workspace.label(s.Path)
+		pad := strings.Repeat(" ", max(m.workspace.width-lipgloss.Width(label), 0))
+		metaParts = append(metaParts, StyleSecondary.Render(label+pad))
+	}
+
 	if s.Model != "" {
 		short := fmt.Sprintf("%-10s", shortModel(s.Model))
 		mColor := modelColor(s.Model)
@@ -566,9 +670,7 @@ func (m model) renderPickerSession(s *parser.SessionInfo, isSelected bool, width
 	if s.GitBranch != "" {
 		branchIcon := Icon.Branch.WithColor(ColorPickerMeta)
 		branchName := s.GitBranch
-		if len(branchName) > 20 {
-			branchName = branchName[:17] + "..."
-		}
+		branchName = parser.Truncate(branchName, 20)
 		branchStr := lipgloss.NewStyle().Foreground(metaColor).Render(branchName)
 		metaParts = append(metaParts, branchIcon+" "+branchStr)
 	}
@@ -630,38 +732,108 @@ func (m model) renderPickerSession(s *parser.SessionInfo, isSelected bool, width
 	return lines
 }
 
-// wrapText breaks text into lines of at most maxWidth runes.
+// wrapText breaks text into lines of at most maxWidth cells, at a space
+// when one falls near the end of the line.
 func wrapText(s string, maxWidth int) []string {
 	if maxWidth <= 0 {
 		return []string{s}
 	}
 	var lines []string
-	runes := []rune(s)
-	for len(runes) > 0 {
-		if len(runes) <= maxWidth {
-			lines = append(lines, string(runes))
+	for s != "" {
+		head, rest := parser.CutWidth(s, maxWidth)
+		if rest == "" {
+			lines = append(lines, head)
 			break
 		}
-		// Find last space within maxWidth.
-		cut := maxWidth
-		for i := maxWidth; i > maxWidth-20 && i > 0; i-- {
-			if runes[i] == ' ' {
-				cut = i
-				break
+		// Find last space among the line's last 20 characters.
+		if !strings.HasPrefix(rest, " ") {
+			floor := len(head)
+			for range 19 {
+				if floor == 0 {
+					break
+				}
+				_, size := utf8.DecodeLastRuneInString(head[:floor])
+				floor -= size
+			}
+			if i := strings.LastIndexByte(head[floor:], ' '); i >= 0 && floor+i > 0 {
+				head, rest = head[:floor+i], head[floor+i:]+rest
 			}
 		}
-		lines = append(lines, string(runes[:cut]))
-		runes = runes[cut:]
+		lines = append(lines, head)
 		// Skip leading space on next line.
-		if len(runes) > 0 && runes[0] == ' ' {
-			runes = runes[1:]
-		}
+		s = strings.TrimPrefix(rest, " ")
 	}
 	return lines
 }
 
+// pickerStatsHeight is the line reserved for the group stats above the footer.
+const pickerStatsHeight = 1
+
+// pickerGroupStats aggregates one date group of the picker.
+type pickerGroupStats struct {
+	category    parser.DateCategory
+	sessions    int
+	ongoing     int
+	tokens      int
+	durationMs  int64
+	compactions int
+}
+
+// cursorGroupStats totals the date group containing the picker cursor, so
+// moving onto "Today" answers how much was spent today. ok is false when
+// the picker is empty.
+func (m model) cursorGroupStats() (stats pickerGroupStats, ok bool) {
+	if m.pickerCursor >= len(m.pickerItems) {
+		return stats, false
+	}
+	start := m.pickerCursor
+	for start > 0 && m.pickerItems[start].typ != pickerItemHeader {
+		start--
+	}
+	stats.category = m.pickerItems[start].category
+	for _, item := range m.pickerItems[start+1:] {
+		if item.typ == pickerItemHeader {
+			break
+		}
+		s := item.session
+		stats.sessions++
+		stats.tokens += s.TotalTokens
+		stats.durationMs += s.DurationMs
+		stats.compactions += s.Compactions
+		if s.IsOngoing {
+			stats.ongoing++
+		}
+	}
+	return stats, true
+}
+
+// renderPickerStats renders "Today  5 sessions · 1.2M tok · 3h12m · 1 compaction · 2 ongoing"
+// for the cursor's date group.
+func (m model) renderPickerStats(width int) string {
+	stats, ok := m.cursorGroupStats()
+	if !ok {
+		return ""
+	}
+	parts := []string{pluralCount(stats.sessions, "session")}
+	if stats.tokens > 0 {
+		parts = append(parts, formatTokens(stats.tokens)+" tok")
+	}
+	if stats.durationMs > 0 {
+		parts = append(parts, formatSessionDuration(stats.durationMs))
+	}
+	if stats.compactions > 0 {
+		parts = append(parts, parser.Plural(stats.compactions, "compaction"))
+	}
+	if stats.ongoing > 0 {
+		parts = append(parts, fmt.Sprintf("%d ongoing", stats.ongoing))
+	}
+	label := string(stats.category)
+	room := max(width-lipgloss.Width(label)-2, 10)
+	return StyleSecondaryBold.Render(label) + "  " + StyleDim.Render(parser.Truncate(strings.Join(parts, " · "), room))
+}
+
 // formatSessionDuration formats session duration for the picker.
-// Shorter format than formatDuration: "5s", "2m", "1h", "3h".
+// Shorter format than render.Duration: "5s", "2m", "1h", "3h".
 func formatSessionDuration(ms int64) string {
 	d := time.Duration(ms) * time.Millisecond
 	switch {
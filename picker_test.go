@@ -4,8 +4,11 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	"charm.land/lipgloss/v2"
 )
 
 // pickerModel builds a model in picker view with sensible defaults.
@@ -191,3 +194,137 @@ func TestPickerInitFiresTick(t *testing.T) {
 type errForTest string
 
 func (e errForTest) Error() string { return string(e) }
+
+// --- TestPickerMarkExport ---------------------------------------------------
+
+func TestPickerMarkExport(t *testing.T) {
+	sessionsModel := func() model {
+		m := pickerModel()
+		m.pickerSessions = []parser.SessionInfo{
+			{Path: "/p/a.jsonl", SessionID: "a", ModTime: time.Now()},
+			{Path: "/p/b.jsonl", SessionID: "b", ModTime: time.Now()},
+			{Path: "/p/c.jsonl", SessionID: "c", ModTime: time.Now()},
+		}
+		m.pickerItems = rebuildPickerItems(m.pickerSessions)
+		m.pickerCursorFirst()
+		return m
+	}
+
+	t.Run("no marks exports the cursor session", func(t *testing.T) {
+		m := sessionsModel()
+		paths := m.pickerExportPaths()
+		if len(paths) != 1 || paths[0] != "/p/a.jsonl" {
+			t.Errorf("paths = %v, want [/p/a.jsonl]", paths)
+		}
+	})
+
+	t.Run("space marks and advances", func(t *testing.T) {
+		m := sessionsModel()
+		result, _ := m.updatePicker(key("space"))
+		m = asModel(result)
+		result, _ = m.updatePicker(key("j"))
+		m = asModel(result)
+		result, _ = m.updatePicker(key("space"))
+		m = asModel(result)
+
+		paths := m.pickerExportPaths()
+		if strings.Join(paths, ",") != "/p/a.jsonl,/p/c.jsonl" {
+			t.Errorf("paths = %v, want a and c", paths)
+		}
+		if !strings.Contains(m.viewPicker(), "2 marked") {
+			t.Error("header should show the marked count")
+		}
+
+		// Space on a marked session unmarks it.
+		m.pickerCursorFirst()
+		result, _ = m.updatePicker(key("space"))
+		m = asModel(result)
+		if m.pickerMarked["/p/a.jsonl"] {
+			t.Error("second space should unmark")
+		}
+	})
+
+	t.Run("x starts an export", func(t *testing.T) {
+		m := sessionsModel()
+		result, cmd := m.updatePicker(key("x"))
+		got := asModel(result)
+		if cmd == nil {
+			t.Fatal("x should return the export command")
+		}
+		if !strings.Contains(got.flashStatus, "Exporting 1 session") {
+			t.Errorf("flashStatus = %q", got.flashStatus)
+		}
+	})
+
+	t.Run("export result clears marks and flashes", func(t *testing.T) {
+		m := sessionsModel()
+		m.pickerMarked = map[string]bool{"/p/a.jsonl": true}
+		result, _ := m.Update(sessionsExportedMsg{count: 1, dir: exportDir})
+		got := asModel(result)
+		if got.pickerMarked != nil {
+			t.Error("marks should clear after a successful export")
+		}
+		if got.flashStatus != "Exported 1 session to tail-claude-export/" {
+			t.Errorf("flashStatus = %q", got.flashStatus)
+		}
+	})
+}
+
+func TestPickerGroupStats(t *testing.T) {
+	now := time.Now()
+	old := now.AddDate(0, -3, 0)
+	m := pickerModel()
+	m.pickerSessions = []parser.SessionInfo{
+		{Path: "/p/a.jsonl", ModTime: now, TotalTokens: 1_000_000, DurationMs: 3_600_000, IsOngoing: true},
+		{Path: "/p/b.jsonl", ModTime: now, TotalTokens: 200_000, DurationMs: 720_000, Compactions: 2},
+		{Path: "/p/c.jsonl", ModTime: old, TotalTokens: 5_000, DurationMs: 60_000},
+	}
+	m.pickerItems = rebuildPickerItems(m.pickerSessions)
+	m.pickerCursorFirst()
+
+	stats, ok := m.cursorGroupStats()
+	if !ok {
+		t.Fatal("cursorGroupStats not ok with sessions loaded")
+	}
+	want := pickerGroupStats{category: parser.DateToday, sessions: 2, ongoing: 1, tokens: 1_200_000, durationMs: 4_320_000, compactions: 2}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+	line := m.renderPickerStats(120)
+	for _, s := range []string{"Today", "2 sessions", "1.2M tok", "1h12m", "2 compactions", "1 ongoing"} {
+		if !strings.Contains(line, s) {
+			t.Errorf("stats line %q missing %q", line, s)
+		}
+	}
+	if !strings.Contains(m.viewPicker(), "2 sessions") {
+		t.Error("viewPicker does not show the group stats line")
+	}
+
+	// Moving into the older group totals that group instead.
+	m.pickerCursor = len(m.pickerItems) - 1
+	if stats, _ := m.cursorGroupStats(); stats.category != parser.DateOlder || stats.sessions != 1 {
+		t.Errorf("older group stats = %+v, want 1 session in %s", stats, parser.DateOlder)
+	}
+
+	if _, ok := pickerModel().cursorGroupStats(); ok {
+		t.Error("cursorGroupStats ok on an empty picker")
+	}
+}
+
+func TestWrapText_WideCharacters(t *testing.T) {
+	lines := wrapText("日本語のテキストを折り返す 🎉🎉 emoji and ASCII words", 10)
+	if len(lines) < 2 {
+		t.Fatalf("lines = %q, want wrapped", lines)
+	}
+	for _, line := range lines {
+		if w := lipgloss.Width(line); w > 10 {
+			t.Errorf("line %q is %d cells wide, want at most 10", line, w)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %q is not valid UTF-8", line)
+		}
+	}
+	if got := strings.Join(lines, ""); !strings.Contains(got, "🎉🎉") {
+		t.Errorf("wrapped text lost characters: %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -38,17 +39,20 @@ func newPickerWatcher(projectDirs []string, cache *parser.SessionCache) *pickerW
 }
 
 // run watches all project directories for .jsonl changes. Debounces 500ms
-// before rescanning. Blocks until stop() is called.
+// before rescanning. Blocks until stop() is called, which also cancels a
+// rescan in progress.
 func (pw *pickerWatcher) run() {
 	w, err := fsnotify.NewWatcher()
 	if err != nil {
 		return
 	}
 	defer w.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// Watch all existing project directories. Missing dirs are silently
+	// Watch every directory discovery reads. Missing dirs are silently
 	// skipped -- they may not exist yet if no worktree session has been created.
-	for _, dir := range pw.projectDirs {
+	for _, dir := range parser.DiscoveryDirs(pw.projectDirs) {
 		if _, err := os.Stat(dir); err == nil {
 			_ = w.Add(dir)
 		}
@@ -85,9 +89,9 @@ func (pw *pickerWatcher) run() {
 				var sessions []parser.SessionInfo
 				var err error
 				if pw.cache != nil {
-					sessions, err = pw.cache.DiscoverAllProjectSessions(pw.projectDirs)
+					sessions, err = pw.cache.DiscoverAllProjectSessionsContext(ctx, pw.projectDirs)
 				} else {
-					sessions, err = parser.DiscoverAllProjectSessions(pw.projectDirs)
+					sessions, err = parser.DiscoverAllProjectSessionsContext(ctx, pw.projectDirs)
 				}
 				if err != nil {
 					return
@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kylesnowschwartz/tail-claude/filter"
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// projectHit is one match in a project-wide search: a session-search hit
+// plus the session it was found in and the turn that contains it.
+type projectHit struct {
+	searchHit
+	path  string // session JSONL
+	title string // session's first prompt
+	turn  int    // 1-based prompt the hit belongs to; 0 before the first prompt
+}
+
+// projectSearchDoneMsg delivers the results of a project search. seq
+// identifies the search so results of a superseded one are dropped.
+// stopped marks a search cut short by leaving the view: hits are partial.
+type projectSearchDoneMsg struct {
+	seq     int
+	hits    []projectHit
+	stopped bool
+}
+
+// sessionsIndexedMsg reports that a background index update finished.
+type sessionsIndexedMsg struct{}
+
+// newSearchIndex returns the search index in the user cache directory, or nil
+// when disabled (--no-index) or the directory can't be determined.
+func newSearchIndex(disabled bool) *parser.SearchIndex {
+	if disabled {
+		return nil
+	}
+	dir, err := parser.DefaultSearchIndexDir()
+	if err != nil {
+		return nil
+	}
+	return parser.NewSearchIndex(dir, sessionSearchText)
+}
+
+// sessionSearchText is the index's parser.SearchText: the fields
+// searchMessages matches in the messages of the lines from offset on. Read
+// apart from the lines before them, a turn's first model change has no
+// previous model, so every Claude message adds the text its change divider
+// would have; a filter that says too much costs only a parse.
+func sessionSearchText(path string, offset int64) ([]string, int64, error) {
+	classified, _, next, err := parser.ReadSessionIncrementalOffsets(path, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	var texts []string
+	for _, msg := range chunksToMessages(parser.BuildChunks(classified), nil, nil) {
+		if msg.role == RoleClaude && msg.model != "" {
+			texts = append(texts, "model changed: "+msg.model) // as modelChangeMessage writes it
+		}
+		if len(msg.items) == 0 {
+			texts = append(texts, msg.content)
+			continue
+		}
+		for _, item := range msg.items {
+			texts = append(texts, itemSearchFields(item)...)
+		}
+	}
+	return texts, next, nil
+}
+
+// searchProject searches the text of every session concurrently and returns
+// the hits in session order. Sessions that fail to parse are skipped.
+// Subagent traces aren't loaded, so only the main sessions are searched.
+// With an index, sessions its filter rules out aren't parsed at all.
+// expr, when set, keeps only the hits in messages it matches. Once ctx ends
+// no more sessions are searched, and the error says why.
+func searchProject(ctx context.Context, sessions []parser.SessionInfo, query string, index *parser.SearchIndex, expr filter.Expr) ([]projectHit, error) {
+	perSession := make([][]projectHit, len(sessions))
+	err := parser.ForEachParallel(ctx, len(sessions), parser.ScanWorkers, func(i int) {
+		if index != nil {
+			// A nil filter (index unreadable) can't rule anything out.
+			if f, _ := index.Update(sessions[i].Path); !f.MightContain(query) {
+				return
+			}
+		}
+		perSession[i] = searchSessionFile(sessions[i], query, expr)
+	})
+	var hits []projectHit
+	for _, h := range perSession {
+		hits = append(hits, h...)
+	}
+	return hits, err
+}
+
+// searchSessionFile parses one session and searches its messages, those
+// expr matches when it's set.
+func searchSessionFile(s parser.SessionInfo, query string, expr filter.Expr) []projectHit {
+	classified, _, _, err := parser.ReadSessionIncrementalOffsets(s.Path, 0)
+	if err != nil {
+		return nil
+	}
+	msgs := chunksToMessages(parser.BuildChunks(classified), nil, nil)
+	var hits []projectHit
+	for _, h := range searchMessages(msgs, query, false) {
+		if expr != nil && !expr.Match(messageRecord{&msgs[h.msgIndex]}) {
+			continue
+		}
+		hits = append(hits, projectHit{
+			searchHit: h,
+			path:      s.Path,
+			title:     s.FirstMessage,
+			turn:      promptNumber(msgs, h.msgIndex),
+		})
+	}
+	return hits
+}
+
+// promptNumber counts the user prompts up to and including msgs[idx].
+func promptNumber(msgs []message, idx int) int {
+	n := 0
+	for i := 0; i <= idx && i < len(msgs); i++ {
+		if msgs[i].role == RoleUser {
+			n++
+		}
+	}
+	return n
+}
+
+// searchProjectCmd runs a project search off the UI goroutine until ctx
+// ends.
+func searchProjectCmd(ctx context.Context, sessions []parser.SessionInfo, query string, index *parser.SearchIndex, seq int) tea.Cmd {
+	return func() tea.Msg {
+		hits, err := searchProject(ctx, sessions, query, index, nil)
+		return projectSearchDoneMsg{seq: seq, hits: hits, stopped: err != nil}
+	}
+}
+
+// indexSessionsCmd brings the index up to date with every session in the
+// background, so the next project search only reads what was appended since.
+// Sessions left when ctx ends wait for the next update.
+func indexSessionsCmd(ctx context.Context, index *parser.SearchIndex, sessions []parser.SessionInfo) tea.Cmd {
+	return func() tea.Msg {
+		_ = parser.ForEachParallel(ctx, len(sessions), parser.ScanWorkers, func(i int) {
+			_, _ = index.Update(sessions[i].Path)
+		})
+		return sessionsIndexedMsg{}
+	}
+}
+
+// maybeIndexSessions starts a background index update unless the index is
+// disabled or an update is already running.
+func (m *model) maybeIndexSessions() tea.Cmd {
+	if m.searchIndex == nil || m.indexing || len(m.pickerSessions) == 0 {
+		return nil
+	}
+	m.indexing = true
+	return indexSessionsCmd(m.viewContext(), m.searchIndex, m.pickerSessions)
+}
+
+// runGrep implements `tail-claude grep [--no-index] [--filter EXPR]
+// <pattern>`: it searches every session of the current project and prints
+// the hits, those in messages EXPR matches with --filter. Returns the exit
+// status, as grep does: 0 with matches, 1 without, 2 on errors.
+func runGrep(args []string) int {
+	const usage = "usage: tail-claude grep [--no-index] [--filter EXPR] <pattern>"
+	noIndex := false
+	var expr filter.Expr
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		switch args[0] {
+		case "--no-index":
+			noIndex = true
+		case "--filter":
+			if len(args) < 2 {
+				fmt.Fprintln(os.Stderr, "--filter requires an expression")
+				return 2
+			}
+			e, err := parseMessageFilter(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--filter: %v\n", err)
+				return 2
+			}
+			expr = e
+			args = args[1:]
+		default:
+			fmt.Fprintln(os.Stderr, usage)
+			return 2
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	projectDir, err := parser.CurrentProjectDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 2
+	}
+	sessions, err := parser.DiscoverAllProjectSessions([]string{projectDir})
+	if err != nil || len(sessions) == 0 {
+		fmt.Fprintln(os.Stderr, "No sessions found for this project.")
+		return 2
+	}
+	hits, _ := searchProject(context.Background(), sessions, strings.Join(args, " "), newSearchIndex(noIndex), expr)
+	writeGrepResults(os.Stdout, hits)
+	if len(hits) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// writeGrepResults prints hits one per line as "path:turn: Source: snippet",
+// so a session can be opened with `tail-claude path`.
+func writeGrepResults(w io.Writer, hits []projectHit) {
+	for _, h := range hits {
+		source := h.source
+		if h.agent != "" {
+			source += " (" + h.agent + ")"
+		}
+		fmt.Fprintf(w, "%s:%d: %s: %s\n", h.path, h.turn, source, h.snippet)
+	}
+}
+
+// openProjectSearch switches to the project search view with the query
+// prompt active.
+func (m *model) openProjectSearch() {
+	m.projectInput = true
+	m.projectCursor = 0
+	m.projectScroll = 0
+	m.view = viewProjectSearch
+}
+
+// updateProjectSearch handles key events in the project search view. While
+// the prompt is active, keys edit the query and Enter runs the search;
+// otherwise they navigate the results.
+func (m model) updateProjectSearch(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if m.projectInput {
+		switch key {
+		case "enter":
+			if strings.TrimSpace(m.projectQuery) == "" {
+				return m, nil
+			}
+			m.projectInput = false
+			m.projectSearching = true
+			m.projectHits = nil
+			m.projectCursor = 0
+			m.projectScroll = 0
+			m.projectSearchSeq++
+			return m, searchProjectCmd(m.viewContext(), m.pickerSessions, m.projectQuery, m.searchIndex, m.projectSearchSeq)
+		case "esc", "escape":
+			m.projectInput = false
+			if m.projectHits == nil && !m.projectSearching {
+				m.view = viewPicker
+			}
+		case "backspace":
+			if len(m.projectQuery) > 0 {
+				_, size := utf8.DecodeLastRuneInString(m.projectQuery)
+				m.projectQuery = m.projectQuery[:len(m.projectQuery)-size]
+			}
+		case "space":
+			m.projectQuery += " "
+		default:
+			if len(key) == 1 && key[0] >= 32 && key[0] < 127 {
+				m.projectQuery += key
+			}
+		}
+		return m, nil
+	}
+
+	switch key {
+	case "q", "esc", "escape", "backspace":
+		m.view = viewPicker
+	case "/":
+		m.projectInput = true
+	case "j", "down":
+		if m.projectCursor < len(m.projectHits)-1 {
+			m.projectCursor++
+		}
+		m.ensureProjectSearchVisible()
+	case "k", "up":
+		if m.projectCursor > 0 {
+			m.projectCursor--
+		}
+		m.ensureProjectSearchVisible()
+	case "G":
+		m.projectCursor = max(len(m.projectHits)-1, 0)
+		m.ensureProjectSearchVisible()
+	case "g":
+		m.projectCursor = 0
+		m.projectScroll = 0
+	case "enter":
+		if m.projectCursor < len(m.projectHits) {
+			hit := m.projectHits[m.projectCursor]
+			if m.pickerWatcher != nil {
+				m.pickerWatcher.stop()
+				m.pickerWatcher = nil
+			}
+			m.pendingHit = &hit
+			cmd := m.openSession(hit.path)
+			return m, cmd
+		}
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// jumpToPendingHit opens the project search hit that loaded the current
+// session. The hit's indices come from a load without subagents or hidden
+// tools, so the hit is looked up again in the displayed messages by its
+// snippet; the message itself is the fallback.
+func (m *model) jumpToPendingHit() {
+	h := m.pendingHit
+	m.pendingHit = nil
+	if h == nil || h.path != m.sessionPath {
+		return
+	}
+	target := searchHit{msgIndex: h.msgIndex, itemIndex: -1}
+	for _, sh := range searchMessages(m.messages, m.projectQuery, false) {
+		if sh.msgIndex == h.msgIndex && sh.snippet == h.snippet {
+			target = sh
+			break
+		}
+	}
+	m.highlightQuery = m.projectQuery
+	m.jumpToHit(target)
+}
+
+// projectSearchViewHeight returns the visible result rows (minus header and
+// footer).
+func (m model) projectSearchViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// ensureProjectSearchVisible adjusts projectScroll so the cursor row is
+// visible.
+func (m *model) ensureProjectSearchVisible() {
+	m.projectScroll = scrollToShow(m.projectScroll, m.projectCursor, 1, m.projectSearchViewHeight())
+}
+
+// viewProjectSearch renders the query prompt and one row per hit.
+func (m model) viewProjectSearch() string {
+	width := m.clampWidth()
+
+	prompt := StyleAccentBold.Render("/") + m.projectQuery
+	if m.projectInput {
+		prompt += StyleAccentBold.Render("_")
+	}
+	var status string
+	if m.projectSearching {
+		status = StyleDim.Render(fmt.Sprintf("searching %s...", pluralCount(len(m.pickerSessions), "session")))
+	} else {
+		sessions := make(map[string]bool)
+		for _, h := range m.projectHits {
+			sessions[h.path] = true
+		}
+		status = StyleDim.Render(fmt.Sprintf("%d hits in %s", len(m.projectHits), pluralCount(len(sessions), "session")))
+	}
+	header := spaceBetween(prompt, status+"  "+StyleMuted.Render("project"), width) + "\n"
+
+	var lines []string
+	for i, h := range m.projectHits {
+		lines = append(lines, renderProjectHit(h, i == m.projectCursor, width))
+	}
+
+	content := header
+	switch {
+	case m.projectSearching:
+	case len(m.projectHits) > 0:
+		content += "\n" + strings.Join(scrollWindow(lines, m.projectSearchViewHeight(), m.projectScroll), "\n")
+	case m.projectInput:
+		content += "\n" + StyleDim.Render("Type a query and press Enter to search every session in the project.")
+	default:
+		content += "\n" + StyleDim.Render("No matches.")
+	}
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	var footer string
+	if m.projectInput {
+		footer = m.renderFooter(
+			"enter", "search",
+			"esc", "cancel",
+		)
+	} else {
+		footer = m.renderFooter(
+			"j/k", "nav",
+			"enter", "open",
+			"/", "edit",
+			"q/esc", "back",
+			"?", "keys",
+		)
+	}
+	return content + "\n" + footer
+}
+
+// renderProjectHit renders "{sel} session title  #3  Source      snippet".
+func renderProjectHit(h projectHit, isSelected bool, width int) string {
+	sel := selectionIndicator(isSelected)
+	titleStyle := StyleSecondary
+	if isSelected {
+		titleStyle = StylePrimaryBold
+	}
+	title := h.title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(h.path), ".jsonl")
+	}
+	left := sel + titleStyle.Render(fmt.Sprintf("%-24s", parser.Truncate(title, 24))) + " " +
+		StyleMuted.Render(fmt.Sprintf("#%-3d", h.turn)) + " " +
+		StyleSecondary.Render(fmt.Sprintf("%-10s", parser.Truncate(h.source, 10))) + " "
+	room := max(width-lipgloss.Width(left)-1, 10)
+	return left + StyleDim.Render(parser.Truncate(h.snippet, room))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestSearchProject(t *testing.T) {
+	first, second := writeTurns(t, 3), writeTurns(t, 5)
+	sessions := []parser.SessionInfo{
+		{Path: first, FirstMessage: "prompt 0"},
+		{Path: second, FirstMessage: "prompt 0"},
+	}
+
+	hits, _ := searchProject(context.Background(), sessions, "ANSWER 2", nil, nil)
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits, want one per session: %+v", len(hits), hits)
+	}
+	if hits[0].path != first || hits[1].path != second {
+		t.Errorf("hits out of session order: %s, %s", hits[0].path, hits[1].path)
+	}
+	if hits[0].turn != 3 || hits[0].source != "Output" {
+		t.Errorf("hit = turn %d in %q, want turn 3 in Output", hits[0].turn, hits[0].source)
+	}
+
+	if got, _ := searchProject(context.Background(), sessions, "prompt 4", nil, nil); len(got) != 1 || got[0].path != second || got[0].turn != 5 {
+		t.Errorf("prompt 4 hits = %+v, want turn 5 of the second session", got)
+	}
+
+	// A filter keeps the hits in the messages it matches.
+	claude, err := parseMessageFilter("role=claude")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := searchProject(context.Background(), sessions, "2", nil, claude); len(got) != 2 || got[0].source != "Output" || got[1].source != "Output" {
+		t.Errorf("role=claude hits for 2 = %+v, want answer 2 of each session", got)
+	}
+
+	// The index skips sessions without the query and keeps the rest.
+	index := parser.NewSearchIndex(t.TempDir(), sessionSearchText)
+	if got, _ := searchProject(context.Background(), sessions, "prompt 4", index, nil); len(got) != 1 || got[0].path != second {
+		t.Errorf("indexed prompt 4 hits = %+v, want the second session only", got)
+	}
+
+	var out bytes.Buffer
+	writeGrepResults(&out, hits[:1])
+	if want := first + ":3: Output: answer 2\n"; out.String() != want {
+		t.Errorf("grep output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestSearchProject_IndexCoversDisplayedText(t *testing.T) {
+	path := writeTurns(t, 2)
+	sessions := []parser.SessionInfo{{Path: path}}
+	index := parser.NewSearchIndex(t.TempDir(), sessionSearchText)
+	if _, err := index.Update(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The model changes in lines appended after the index last read the file.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, `{"uuid":"u9","type":"user","timestamp":"2025-01-15T11:00:00.000Z","message":{"role":"user","content":"again"}}`)
+	fmt.Fprintln(f, `{"uuid":"a9","type":"assistant","timestamp":"2025-01-15T11:00:05.000Z","message":{"role":"assistant","content":[{"type":"text","text":"done"}],"model":"claude-sonnet-4-5","stop_reason":"end_turn"}}`)
+	f.Close()
+
+	// "model changed" is only in the divider the viewer draws, not the file.
+	want, _ := searchProject(context.Background(), sessions, "model changed", nil, nil)
+	if len(want) != 1 {
+		t.Fatalf("unindexed hits = %+v, want the model change", want)
+	}
+	if got, _ := searchProject(context.Background(), sessions, "model changed", index, nil); len(got) != len(want) {
+		t.Errorf("indexed hits = %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdateProjectSearch(t *testing.T) {
+	m := testModel()
+	m.view = viewPicker
+	m.pickerSessions = []parser.SessionInfo{{Path: writeTurns(t, 2)}}
+
+	result, _ := m.updatePicker(key("/"))
+	m = asModel(result)
+	if m.view != viewProjectSearch || !m.projectInput {
+		t.Fatalf("/ should open the project search prompt")
+	}
+
+	for _, k := range []string{"a", "n", "s", "w", "e", "r"} {
+		result, _ = m.Update(key(k))
+		m = asModel(result)
+	}
+	result, cmd := m.Update(key("enter"))
+	m = asModel(result)
+	if !m.projectSearching || cmd == nil {
+		t.Fatalf("enter should start a search")
+	}
+
+	// Results of a superseded search are dropped.
+	result, _ = m.Update(projectSearchDoneMsg{seq: m.projectSearchSeq - 1})
+	if !asModel(result).projectSearching {
+		t.Error("stale results should be ignored")
+	}
+
+	result, _ = m.Update(cmd())
+	m = asModel(result)
+	if m.projectSearching || len(m.projectHits) != 2 {
+		t.Fatalf("searching = %v, hits = %d; want done with 2 hits", m.projectSearching, len(m.projectHits))
+	}
+	if !strings.Contains(m.viewProjectSearch(), "2 hits in 1 session") {
+		t.Error("header should count hits and sessions")
+	}
+
+	result, cmd = m.Update(key("enter"))
+	m = asModel(result)
+	if m.pendingHit == nil || cmd == nil {
+		t.Error("enter on a hit should load its session")
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// rangeCountDelay is how long a selection must hold still before the token
+// counter runs on it, so extending it with j/k starts one run, not many.
+const rangeCountDelay = 300 * time.Millisecond
+
+// rangeCountTimeout caps a token counter run.
+const rangeCountTimeout = 10 * time.Second
+
+// rangeCountTickMsg ends the delay after the selection changed.
+type rangeCountTickMsg struct{ seq int }
+
+// rangeCountMsg reports a token counter run on the selection as it was at
+// seq.
+type rangeCountMsg struct {
+	seq    int
+	tokens int
+	err    error
+}
+
+// toggleRange starts a selection at the cursor (v), or drops the one in
+// progress.
+func (m *model) toggleRange() tea.Cmd {
+	if m.rangeActive {
+		m.clearRange()
+		return nil
+	}
+	m.rangeActive = true
+	m.rangeAnchor = m.cursor
+	return m.rangeChanged()
+}
+
+// clearRange drops the selection; a count still running for it is ignored
+// when it lands.
+func (m *model) clearRange() {
+	m.rangeActive = false
+	m.rangeSeq++
+	m.rangeTokens, m.rangeCounting, m.rangeCountErr = 0, false, nil
+}
+
+// rangeChanged notes a new extent: an exact count is stale, and with a
+// token counter configured a new run waits for the selection to hold still.
+func (m *model) rangeChanged() tea.Cmd {
+	if !m.rangeActive {
+		return nil
+	}
+	m.rangeSeq++
+	m.rangeTokens, m.rangeCounting, m.rangeCountErr = 0, false, nil
+	if m.cfg.TokenCounter == "" {
+		return nil
+	}
+	seq := m.rangeSeq
+	return tea.Tick(rangeCountDelay, func(time.Time) tea.Msg {
+		return rangeCountTickMsg{seq}
+	})
+}
+
+// startRangeCount runs the token counter once the selection has held still
+// since seq.
+func (m *model) startRangeCount(seq int) tea.Cmd {
+	if !m.rangeActive || seq != m.rangeSeq || m.cfg.TokenCounter == "" {
+		return nil
+	}
+	m.rangeCounting = true
+	return tokenCountCmd(m.cfg.TokenCounter, m.selectionMarkdown(), seq)
+}
+
+// applyRangeCount records a finished count unless the selection has moved
+// on since it started.
+func (m *model) applyRangeCount(msg rangeCountMsg) {
+	if !m.rangeActive || msg.seq != m.rangeSeq {
+		return
+	}
+	m.rangeCounting = false
+	m.rangeTokens, m.rangeCountErr = msg.tokens, msg.err
+}
+
+// tokenCountCmd pipes text through the configured counter (run with sh -c),
+// which prints the number of tokens: a tokenizer script, or a call to the
+// API's token counting endpoint.
+func tokenCountCmd(command, text string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), rangeCountTimeout)
+		defer cancel()
+		out, err := runFilter(ctx, command, text)
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", rangeCountTimeout)
+		}
+		if err != nil {
+			return rangeCountMsg{seq: seq, err: err}
+		}
+		fields := strings.Fields(string(out))
+		if len(fields) == 0 {
+			return rangeCountMsg{seq: seq, err: fmt.Errorf("printed no count")}
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n < 0 {
+			return rangeCountMsg{seq: seq, err: fmt.Errorf("printed %q, not a count", parser.Truncate(fields[0], 20))}
+		}
+		return rangeCountMsg{seq: seq, tokens: n}
+	}
+}
+
+// rangeBounds returns the first and last list positions of the selection.
+func (m model) rangeBounds() (lo, hi int) {
+	a, c := m.listPos(m.rangeAnchor), m.listPos(m.cursor)
+	return min(a, c), max(a, c)
+}
+
+// inRange reports whether message i is selected.
+func (m model) inRange(i int) bool {
+	if !m.rangeActive {
+		return false
+	}
+	lo, hi := m.rangeBounds()
+	pos := m.listPos(i)
+	return pos >= lo && pos <= hi
+}
+
+// selectedMessages returns the selected messages in list order.
+func (m model) selectedMessages() []message {
+	if !m.rangeActive || len(m.messages) == 0 {
+		return nil
+	}
+	lo, hi := m.rangeBounds()
+	hi = min(hi, len(m.messages)-1)
+	msgs := make([]message, 0, hi-lo+1)
+	for pos := lo; pos <= hi; pos++ {
+		msgs = append(msgs, m.messages[m.listAt(pos)])
+	}
+	return msgs
+}
+
+// selectionMarkdown returns the selection as y copies it: one Markdown
+// section per message, as in the session export.
+func (m model) selectionMarkdown() string {
+	var b strings.Builder
+	writeMessagesMarkdown(&b, m.selectedMessages())
+	return strings.TrimSpace(b.String()) + "\n"
+}
+
+// estimateTokens approximates the tokens in text at four bytes each, the
+// estimate the parser makes for items.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// copyRange copies the selection to the clipboard and ends it.
+func (m *model) copyRange() tea.Cmd {
+	text := m.selectionMarkdown()
+	n := len(m.selectedMessages())
+	tokens := m.rangeTokenLabel(estimateTokens(text))
+	m.clearRange()
+	m.layoutList()
+	m.flashStatus = fmt.Sprintf("Copied %s (%s)", pluralCount(n, "message"), tokens)
+	return tea.Batch(tea.SetClipboard(text), flashClearCmd())
+}
+
+// rangeTokenLabel renders the selection's size: the counter's exact count
+// once it has one, else "~" and the estimate.
+func (m model) rangeTokenLabel(estimate int) string {
+	if m.rangeTokens > 0 {
+		return formatCount(m.rangeTokens) + " tokens"
+	}
+	return "~" + formatTokens(estimate) + " tokens"
+}
+
+// renderRangeStatus renders the info bar while selecting: "3 messages
+// selected · ~4.2k tokens · y copy · esc clear", with the counter's state
+// when one is configured.
+func (m model) renderRangeStatus() string {
+	text := m.selectionMarkdown()
+	status := StyleAccentBold.Render(pluralCount(len(m.selectedMessages()), "message")+" selected") +
+		" " + Icon.Dot.Render() + " " + StyleSecondaryBold.Render(m.rangeTokenLabel(estimateTokens(text)))
+	switch {
+	case m.rangeCountErr != nil:
+		status += " " + StyleErrorBold.Render("(counter: "+m.rangeCountErr.Error()+")")
+	case m.rangeCounting:
+		status += " " + StyleDim.Render("counting…")
+	}
+	return " " + status + StyleDim.Render(" · y copy · esc clear")
+}
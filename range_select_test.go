@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRangeSelection(t *testing.T) {
+	m := testModel()
+	m.cursor = 0
+	for _, k := range []string{"v", "j", "j"} {
+		result, _ := m.Update(key(k))
+		m = asModel(result)
+	}
+	if got := len(m.selectedMessages()); got != 3 {
+		t.Fatalf("selected %d messages, want 3", got)
+	}
+	md := m.selectionMarkdown()
+	if !strings.Contains(md, "Hello, world") || !strings.Contains(md, "system output") {
+		t.Errorf("selection markdown = %q", md)
+	}
+	status := plain(m.renderInfoBar())
+	est := "~" + formatTokens(estimateTokens(md)) + " tokens"
+	if !strings.Contains(status, "3 messages selected") || !strings.Contains(status, est) {
+		t.Errorf("info bar = %q, want the count and %q", status, est)
+	}
+
+	// k shrinks it; y copies it and ends the selection.
+	result, _ := m.Update(key("k"))
+	m = asModel(result)
+	if got := len(m.selectedMessages()); got != 2 {
+		t.Errorf("after k: %d selected, want 2", got)
+	}
+	result, cmd := m.Update(key("y"))
+	m = asModel(result)
+	if m.rangeActive || cmd == nil || !strings.Contains(m.flashStatus, "Copied 2 messages (~") {
+		t.Errorf("after y: active %v, flash %q", m.rangeActive, m.flashStatus)
+	}
+
+	// esc drops a selection before anything else.
+	m.flashStatus = ""
+	result, _ = m.Update(key("v"))
+	m = asModel(result)
+	result, _ = m.Update(key("esc"))
+	m = asModel(result)
+	if m.rangeActive || m.view != viewList {
+		t.Errorf("esc: active %v, view %v, want the selection dropped in the list", m.rangeActive, m.view)
+	}
+}
+
+func TestRangeTokenCounter(t *testing.T) {
+	m := testModel()
+	m.cfg.TokenCounter = "cat >/dev/null; echo 1234"
+	result, cmd := m.Update(key("v"))
+	m = asModel(result)
+	if cmd == nil {
+		t.Fatal("v with a counter configured should schedule a count")
+	}
+	// A tick for an older extent starts nothing.
+	if cmd := m.startRangeCount(m.rangeSeq - 1); cmd != nil {
+		t.Error("a stale tick should not run the counter")
+	}
+	count := m.startRangeCount(m.rangeSeq)
+	if count == nil || !m.rangeCounting {
+		t.Fatal("a current tick should run the counter")
+	}
+	m.applyRangeCount(count().(rangeCountMsg))
+	if got := plain(m.renderRangeStatus()); !strings.Contains(got, "1,234 tokens") {
+		t.Errorf("status = %q, want the exact count", got)
+	}
+
+	msg := tokenCountCmd("echo lots", "text", 1)().(rangeCountMsg)
+	if msg.err == nil || !strings.Contains(msg.err.Error(), `printed "lots"`) {
+		t.Errorf("bad counter output: err = %v", msg.err)
+	}
+}
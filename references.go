@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// referenceQuoteChars caps the cited passage shown under a reference.
+const referenceQuoteChars = 200
+
+// renderReferences renders the sources an output cites under a
+// "References:" header: a numbered title, its URL, and the passage cited,
+// dimmed and shortened.
+func renderReferences(refs []parser.Reference, wrapWidth int, indent string) string {
+	lines := []string{indent + StyleSecondaryBold.Render("References:")}
+	for i, r := range refs {
+		num := fmt.Sprintf("%d. ", i+1)
+		pad := indent + strings.Repeat(" ", len(num))
+		lines = append(lines, indent+StyleMuted.Render(num)+parser.Truncate(r.Label(), max(wrapWidth-len(num), 10)))
+		if r.URL != "" && r.URL != r.Label() {
+			lines = append(lines, pad+StyleDim.Render(r.URL))
+		}
+		for _, l := range wrapText(referenceQuote(r), max(wrapWidth-len(num), 10)) {
+			lines = append(lines, pad+StyleDim.Render(l))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// referenceQuote returns a reference's cited passage on one line, quoted
+// and capped at referenceQuoteChars, or "" when none was recorded.
+func referenceQuote(r parser.Reference) string {
+	text := strings.Join(strings.Fields(r.CitedText), " ")
+	if text == "" {
+		return ""
+	}
+	return `"` + parser.Truncate(text, referenceQuoteChars) + `"`
+}
+
+// referencesMarkdown renders references as a numbered Markdown list under
+// "References:", titles linked to their URLs.
+func referencesMarkdown(refs []parser.Reference) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("References:\n\n")
+	for i, r := range refs {
+		fmt.Fprintf(&b, "%d. ", i+1)
+		if r.URL != "" {
+			fmt.Fprintf(&b, "[%s](%s)", r.Label(), r.URL)
+		} else {
+			b.WriteString(r.Label())
+		}
+		if quote := referenceQuote(r); quote != "" {
+			b.WriteString(" — " + quote)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// messageReferences collects the distinct sources a message's outputs
+// cite, in order.
+func messageReferences(msg message) []parser.Reference {
+	var refs []parser.Reference
+	for _, item := range msg.items {
+		if item.itemType == parser.ItemOutput {
+			refs = parser.MergeReferences(refs, item.references...)
+		}
+	}
+	return refs
+}
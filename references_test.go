@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestRenderReferences(t *testing.T) {
+	refs := []parser.Reference{
+		{Title: "Go 1.22 Release Notes", URL: "https://go.dev/doc/go1.22", CitedText: "Each iteration of the loop\ncreates new variables."},
+		{URL: "https://go.dev/blog/loopvar-preview"},
+	}
+	got := plain(renderReferences(refs, 80, "    "))
+	want := strings.Join([]string{
+		"    References:",
+		"    1. Go 1.22 Release Notes",
+		"       https://go.dev/doc/go1.22",
+		`       "Each iteration of the loop creates new variables."`,
+		"    2. https://go.dev/blog/loopvar-preview",
+	}, "\n")
+	if got != want {
+		t.Errorf("renderReferences =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDetailOutputShowsReferences(t *testing.T) {
+	m := testModel()
+	item := displayItem{itemType: parser.ItemOutput, text: "Loops changed.", references: []parser.Reference{
+		{Title: "Go 1.22 Release Notes", URL: "https://go.dev/doc/go1.22"},
+	}}
+	got := plain(m.renderDetailItemExpanded(item, 100).content)
+	if !strings.Contains(got, "Loops changed.") || !strings.Contains(got, "References:") ||
+		!strings.Contains(got, "https://go.dev/doc/go1.22") {
+		t.Errorf("expanded output =\n%s\nwant the text and its references", got)
+	}
+	if links := messageLinks(message{items: []displayItem{item}}); len(links) != 1 {
+		t.Errorf("messageLinks = %v, want the reference URL", links)
+	}
+}
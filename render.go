@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"image/color"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/kylesnowschwartz/tail-claude/parser"
+	"github.com/kylesnowschwartz/tail-claude/render"
 
 	"charm.land/lipgloss/v2"
 )
@@ -30,17 +33,18 @@ func newRendered(content string) rendered {
 // maxContentWidth is the maximum width for content rendering.
 const maxContentWidth = 160
 
-// maxCollapsedLines is the maximum content lines shown when a message is collapsed.
-const maxCollapsedLines = 12
-
 // keybindBarHeight is the rendered line count of the keybind hints bar
 // (rounded border: top + content + bottom = 3 lines).
 const keybindBarHeight = 3
 
 // infoBarHeight returns the rendered line count of the session info bar.
 // Colored modes (bypassPermissions, acceptEdits, plan) render a 3-line
-// RoundedBorder chip; default mode renders a plain 1-line bar.
+// RoundedBorder chip; default mode, or a layout without the mode, renders a
+// plain 1-line bar.
 func (m model) infoBarHeight() int {
+	if !m.cfg.infoBarLayout().has(infoMode) {
+		return 1
+	}
 	switch m.sessionMode {
 	case "bypassPermissions", "acceptEdits", "plan":
 		return 3
@@ -49,14 +53,6 @@ func (m model) infoBarHeight() int {
 	}
 }
 
-// detailItemTokWidth is the fixed column width for token counts in the detail
-// item row right side. Fits "~9.9k tok" (9 chars); right-aligns smaller values.
-const detailItemTokWidth = 9
-
-// detailItemDurWidth is the fixed column width for durations in the detail
-// item row right side. Fits "999ms" and "1m 5s" (5 chars); left-aligns shorter values.
-const detailItemDurWidth = 5
-
 // beadCount is the number of dots in the activity indicator animation.
 const beadCount = 5
 
@@ -79,8 +75,17 @@ func selectionIndicator(selected bool) string {
 }
 
 // userHeaderLine renders "timestamp  You {icon}" used in both list and detail views.
+// Retried prompts lead with an "attempt 2 of 3" badge, and prompts that open
+// a branch of a forked session with a "branch 2 of 3" fork marker.
 func userHeaderLine(msg message) string {
-	return StyleDim.Render(msg.timestamp) + "  " + StylePrimaryBold.Render("You") + " " + Icon.User.Render()
+	line := StyleDim.Render(msg.timestamp) + "  " + StylePrimaryBold.Render("You") + " " + Icon.User.Render()
+	if msg.attempts > 1 {
+		line = StyleSecondaryBold.Render(fmt.Sprintf("attempt %d of %d", msg.attempt, msg.attempts)) + "  " + line
+	}
+	if msg.branches > 1 {
+		line = Icon.Branch.Render() + " " + StyleSecondaryBold.Render(fmt.Sprintf("branch %d of %d", msg.branch, msg.branches)) + "  " + line
+	}
+	return line
 }
 
 // spaceBetween lays out left and right strings with gap-fill spacing to span width.
@@ -132,8 +137,9 @@ func truncateLines(content string, maxLines int) (string, int) {
 	return strings.Join(lines[:maxLines], "\n"), len(lines) - maxLines
 }
 
-// formatToolResultPreview renders a one-line tool result summary for collapsed view.
-func formatToolResultPreview(lo *parser.LastOutput) string {
+// formatToolResultPreview renders a one-line tool result summary for
+// collapsed view, truncating the result to limit characters.
+func formatToolResultPreview(lo *parser.LastOutput, limit int) string {
 	icon := Icon.Tool.Ok
 	if lo.IsError {
 		icon = Icon.Tool.Err
@@ -141,14 +147,9 @@ func formatToolResultPreview(lo *parser.LastOutput) string {
 	nameStyle := StylePrimaryBold
 	resultStyle := StyleSecondary
 
-	result := lo.ToolResult
-	if len(result) > 200 {
-		result = result[:200] + Icon.Ellipsis.Glyph
-	}
-	// Collapse newlines for single-line preview
-	result = strings.ReplaceAll(result, "\n", " ")
-
-	return icon.Render() + " " + nameStyle.Render(lo.ToolName) + " " + resultStyle.Render(parser.Truncate(result, 80))
+	// Truncate collapses newlines for the single-line preview, and stops
+	// reading a long result at the limit.
+	return icon.Render() + " " + nameStyle.Render(lo.ToolName) + " " + resultStyle.Render(parser.Truncate(lo.ToolResult, limit))
 }
 
 // -- Message rendering --------------------------------------------------------
@@ -164,12 +165,98 @@ func (m model) renderMessage(msg message, containerWidth int, isSelected, isExpa
 		content = renderSystemMessage(msg, containerWidth, isSelected, isExpanded)
 	case RoleCompact:
 		content = renderCompactMessage(msg, containerWidth)
+	case RoleModel:
+		content = renderModelChangeMessage(msg, containerWidth)
+	case RoleMode:
+		content = renderModeChangeMessage(msg, containerWidth)
+	case RoleCommand:
+		content = renderCommandMessage(msg, containerWidth, isSelected, isExpanded)
+	case RoleError:
+		content = renderAPIErrorMessage(msg, containerWidth, isSelected)
 	default:
 		content = msg.content
 	}
 	return newRendered(content)
 }
 
+// denseTimeWidth fits formatTime's widest output, "12:59:59 PM".
+const denseTimeWidth = 11
+
+// renderDenseMessage renders msg as a single line for the compact list:
+// "{glyph}  3:04:05 PM  summary ... ⬡ 12.3k  ⏱ 4.1s". Enter still opens the
+// full message in the detail view.
+func renderDenseMessage(msg message, width int, isSelected bool) string {
+	glyph, text := denseLabel(msg)
+	left := selectionIndicator(isSelected) + glyph + " " +
+		StyleDim.Render(fmt.Sprintf("%*s", denseTimeWidth, msg.timestamp)) + "  "
+
+	var meta []string
+	if msg.tokensRaw > 0 {
+		meta = append(meta, Icon.Token.Render()+" "+StyleSecondary.Render(formatTokens(msg.tokensRaw)))
+	}
+	if msg.durationMs > 0 {
+		meta = append(meta, Icon.Clock.Render()+" "+StyleSecondary.Render(localRender.Duration(msg.durationMs)))
+	}
+	right := strings.Join(meta, "  ")
+
+	textStyle := StyleSecondary
+	if isSelected {
+		textStyle = StylePrimaryBold
+	}
+	room := max(width-lipgloss.Width(left)-lipgloss.Width(right)-2, 10)
+	return spaceBetween(left+textStyle.Render(parser.Truncate(text, room)), right, width)
+}
+
+// denseLabel returns the role glyph and one-line summary of msg for the
+// compact list. Claude messages use their turn digest; everything else its
+// first line of content.
+func denseLabel(msg message) (glyph, text string) {
+	first := func(s string) string {
+		line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+		return line
+	}
+	switch msg.role {
+	case RoleClaude:
+		text = msg.summary.String()
+		if text == "" {
+			text = first(msg.content)
+		}
+		if msg.loopCount > 0 {
+			text = fmt.Sprintf("possible loop %s ×%d · %s", msg.loopTool, msg.loopCount, text)
+		}
+		return Icon.Claude.Render(), text
+	case RoleUser:
+		return Icon.User.Render(), first(msg.content)
+	case RoleSystem:
+		if msg.systemKind == parser.SystemOutputStyle {
+			return systemIcon(msg), "output style " + first(msg.content)
+		}
+		return systemIcon(msg), first(msg.content)
+	case RoleCommand:
+		text = msg.command
+		if out := first(msg.content); out != "" {
+			text += " → " + out
+		}
+		return Icon.Command.Render(), text
+	case RoleError:
+		glyph = Icon.Warning.Render()
+		if msg.isError {
+			glyph = Icon.SystemErr.Render()
+		}
+		text = "API error"
+		if n := len(msg.apiErrors); n > 0 {
+			text += ": " + localRender.ErrorLine(msg.apiErrors[n-1])
+			if n > 1 {
+				text += fmt.Sprintf("  ×%d", n)
+			}
+		}
+		return glyph, text
+	case RoleCompact:
+		return StyleMuted.Render(GlyphHRule), compactLabel(msg)
+	}
+	return StyleMuted.Render(GlyphHRule), first(msg.content)
+}
+
 func (m model) renderClaudeMessage(msg message, containerWidth int, isSelected, isExpanded bool) string {
 	sel := selectionIndicator(isSelected)
 	chev := chevron(isExpanded)
@@ -216,20 +303,45 @@ func (m model) claudeMessageBody(msg message, isExpanded bool, cw int) string {
 			fmt.Sprintf("%s (%d lines hidden)", Icon.Ellipsis.Render(), hidden))
 		rendered += "\n" + hint
 	}
+	// Collapsed cards only show the final text; note what the turn did.
+	if !isExpanded && msg.summary.Activity != "" {
+		rendered += "\n" + StyleDim.Render(parser.Truncate(msg.summary.Activity, cw))
+	}
+	if !isExpanded {
+		for _, line := range queuedLines(msg, cw) {
+			rendered += "\n" + line
+		}
+	}
 	return rendered
 }
 
+// queuedLines renders one line per prompt the user queued into the turn:
+// "queued  also update the README". Collapsed cards hide the item rows, so
+// these keep a steering message visible where it landed.
+func queuedLines(msg message, cw int) []string {
+	var lines []string
+	for _, item := range msg.items {
+		if item.itemType != parser.ItemQueuedMessage {
+			continue
+		}
+		text := parser.Truncate(strings.Join(strings.Fields(item.text), " "), max(cw-10, 10))
+		lines = append(lines, Icon.User.Render()+" "+StyleWarningBold.Render("queued")+"  "+StyleSecondary.Render(text))
+	}
+	return lines
+}
+
 // claudeExpandedItems renders structured item rows plus truncated last output.
 func (m model) claudeExpandedItems(msg message, cw int) string {
 	var rows []string
+	cols := m.itemColumns(msg.items)
 	for i, item := range msg.items {
-		rows = append(rows, m.renderDetailItemRow(item, i, -1, false, cw))
+		rows = append(rows, m.renderDetailItemRow(item, i, -1, false, false, cols, cw))
 	}
 
 	// Append truncated last output text below the items
 	if msg.lastOutput != nil && msg.lastOutput.Text != "" {
 		outputText := msg.lastOutput.Text
-		truncated, hidden := truncateLines(outputText, maxCollapsedLines)
+		truncated, hidden := truncateLines(outputText, m.collapse().List.Lines)
 		if hidden > 0 {
 			outputText = truncated
 		}
@@ -245,6 +357,11 @@ func (m model) claudeExpandedItems(msg message, cw int) string {
 	return strings.Join(rows, "\n")
 }
 
+// collapse returns the preview limits for collapsed content.
+func (m model) collapse() collapseConfig {
+	return m.cfg.Collapse.withDefaults()
+}
+
 // claudeCollapsedContent selects and truncates text for collapsed display.
 // Returns the content string and the number of hidden lines (0 when not truncated).
 // The caller is responsible for rendering the truncation hint after markdown.
@@ -253,22 +370,23 @@ func (m model) claudeCollapsedContent(msg message, isExpanded bool) (string, int
 	if isExpanded {
 		return content, 0
 	}
+	limits := m.collapse().List
 
 	if msg.lastOutput != nil {
 		switch msg.lastOutput.Type {
 		case parser.LastOutputText:
 			content = msg.lastOutput.Text
-			truncated, hidden := truncateLines(content, maxCollapsedLines)
+			truncated, hidden := truncateLines(content, limits.Lines)
 			if hidden > 0 {
 				return truncated, hidden
 			}
 			return content, 0
 		case parser.LastOutputToolResult:
-			return formatToolResultPreview(msg.lastOutput), 0
+			return formatToolResultPreview(msg.lastOutput, limits.Preview), 0
 		}
 	}
 
-	truncated, hidden := truncateLines(content, maxCollapsedLines)
+	truncated, hidden := truncateLines(content, limits.Lines)
 	if hidden > 0 {
 		return truncated, hidden
 	}
@@ -303,7 +421,7 @@ func (m model) renderUserMessage(msg message, containerWidth int, isSelected, is
 
 	// Truncate long user messages when collapsed
 	if !isExpanded {
-		truncated, hidden := truncateLines(content, maxCollapsedLines)
+		truncated, hidden := truncateLines(content, m.collapse().List.Lines)
 		if hidden > 0 {
 			content = truncated
 			hint = StyleDim.Render(fmt.Sprintf("%s (%d lines hidden)", Icon.Ellipsis.Render(), hidden))
@@ -334,20 +452,47 @@ func (m model) renderUserMessage(msg message, containerWidth int, isSelected, is
 	alignedBubble := lipgloss.PlaceHorizontal(bubbleAlignWidth, lipgloss.Right, bubble)
 
 	// Prepend selection indicator to each bubble line
-	return header + "\n" + indentBlock(alignedBubble, sel)
+	out := header + "\n" + indentBlock(alignedBubble, sel)
+	if len(msg.attachments) > 0 {
+		out += "\n" + indentBlock(renderAttachments(msg.attachments, bubbleAlignWidth, isExpanded), sel)
+	}
+	return out
+}
+
+// renderAttachments renders the right-aligned "attachments: 3 files (12.4k tok)"
+// summary under a user bubble. Expanded, each attachment gets its own line.
+func renderAttachments(atts []parser.Attachment, width int, isExpanded bool) string {
+	total := 0
+	for _, a := range atts {
+		total += a.TokenCount
+	}
+	summary := "attachments: " + parser.Plural(len(atts), "file")
+	if total > 0 {
+		summary += fmt.Sprintf(" (%s tok)", formatTokens(total))
+	}
+	lines := []string{StyleDim.Render(summary) + " " + chevron(isExpanded)}
+	if isExpanded {
+		for _, a := range atts {
+			line := StyleSecondary.Render(a.Path)
+			if a.TokenCount > 0 {
+				line += "  " + StyleDim.Render("~"+formatTokens(a.TokenCount)+" tok")
+			}
+			lines = append(lines, line)
+		}
+	}
+	for i, l := range lines {
+		lines[i] = lipgloss.PlaceHorizontal(width, lipgloss.Right, l)
+	}
+	return strings.Join(lines, "\n")
 }
 
 func renderSystemMessage(msg message, containerWidth int, isSelected, _ bool) string {
 	// System messages always show inline -- they're short
 	sel := selectionIndicator(isSelected)
 
-	icon := Icon.System
-	if msg.isError {
-		icon = Icon.SystemErr
-	}
-	sysIcon := icon.Render()
+	sysIcon := systemIcon(msg)
 
-	label := StyleSecondary.Render("System")
+	label := StyleSecondary.Render(render.SystemLabel(msg.systemKind))
 
 	ts := StyleDim.Render(msg.timestamp)
 
@@ -357,12 +502,143 @@ func renderSystemMessage(msg message, containerWidth int, isSelected, _ bool) st
 	return "\n" + line + "\n"
 }
 
+// systemIcon renders a system message's glyph: red for errors, amber for
+// warning statuses.
+func systemIcon(msg message) string {
+	switch {
+	case msg.isError:
+		return Icon.SystemErr.Render()
+	case msg.systemLevel == "warning":
+		return Icon.Warning.Render()
+	}
+	return Icon.System.Render()
+}
+
+// apiErrorHeaderLine renders "{icon} API error" in the warning style while
+// retries are pending, or the error style once the request failed.
+func apiErrorHeaderLine(msg message) string {
+	if msg.isError {
+		return Icon.SystemErr.Render() + " " + StyleErrorBold.Render("API error")
+	}
+	return Icon.Warning.Render() + " " + StyleWarningBold.Render("API error")
+}
+
+// renderAPIErrorMessage renders a run of API errors as a single line with
+// the latest error inline: "API error · 10:04:12 AM  overloaded (529) ·
+// retry 3/10 in 2.2s  ×3". The detail view lists every attempt.
+func renderAPIErrorMessage(msg message, containerWidth int, isSelected bool) string {
+	sel := selectionIndicator(isSelected)
+	line := sel + apiErrorHeaderLine(msg) + "  " + Icon.Dot.Glyph + "  " + StyleDim.Render(msg.timestamp)
+	if n := len(msg.apiErrors); n > 0 {
+		last := localRender.ErrorLine(msg.apiErrors[n-1])
+		if n > 1 {
+			last += fmt.Sprintf("  ×%d", n)
+		}
+		room := max(containerWidth-lipgloss.Width(line)-2, 10)
+		line += "  " + StyleDim.Render(parser.Truncate(last, room))
+	}
+	return "\n" + line + "\n"
+}
+
+// commandHeaderLine renders "{icon} /review src/ →" shared by list and detail views.
+func commandHeaderLine(msg message) string {
+	icon := Icon.Command
+	if msg.isError {
+		icon = Icon.SystemErr
+	}
+	return icon.Render() + " " + StyleAccentBold.Render(msg.command) + " " + StyleDim.Render("\u2192")
+}
+
+// renderCommandMessage renders a slash command as a single line with the
+// first line of its output inline. Expanded, the full output is shown below.
+func renderCommandMessage(msg message, containerWidth int, isSelected, isExpanded bool) string {
+	sel := selectionIndicator(isSelected)
+	ts := StyleDim.Render(msg.timestamp)
+	left := sel + commandHeaderLine(msg)
+
+	output := strings.TrimSpace(msg.content)
+	multiline := strings.Contains(output, "\n")
+	if multiline {
+		left += " " + chevron(isExpanded)
+	}
+
+	if !isExpanded || !multiline {
+		if output != "" {
+			first, _, _ := strings.Cut(output, "\n")
+			room := containerWidth - lipgloss.Width(left) - lipgloss.Width(ts) - 4
+			if room > 10 {
+				left += "  " + StyleDim.Render(parser.Truncate(first, room))
+			}
+		}
+		return "\n" + spaceBetween(left, ts, containerWidth) + "\n"
+	}
+
+	indent := sel + "    "
+	body := StyleDim.Width(max(containerWidth-lipgloss.Width(indent), 20)).Render(output)
+	return "\n" + spaceBetween(left, ts, containerWidth) + "\n" + indentBlock(body, indent) + "\n"
+}
+
 func renderCompactMessage(msg message, width int) string {
-	dim := StyleMuted
+	text := compactLabel(msg)
+	left, right := dividerRules(text, width)
+	return StyleMuted.Render(left + " " + text + " " + right)
+}
+
+// compactLabel describes a compaction with what it recorded, e.g.
+// "Conversation compacted (auto) · 155.0k → 8.2k tokens".
+func compactLabel(msg message) string {
 	text := msg.content
 	if text == "" {
 		text = "Context compressed"
 	}
+	if msg.compactTrigger != "" {
+		text += " (" + msg.compactTrigger + ")"
+	}
+	switch {
+	case msg.compactPre > 0 && msg.compactPost > 0:
+		text += " · " + formatTokens(msg.compactPre) + " \u2192 " + formatTokens(msg.compactPost) + " tokens"
+	case msg.compactPre > 0:
+		text += " · from " + formatTokens(msg.compactPre) + " tokens"
+	}
+	return text
+}
+
+// renderModelChangeMessage renders a model switch as a centered divider with
+// the label tinted by the new model's family color.
+func renderModelChangeMessage(msg message, width int) string {
+	left, right := dividerRules(msg.content, width)
+	label := lipgloss.NewStyle().Foreground(modelColor(msg.model)).Render(msg.content)
+	return StyleMuted.Render(left+" ") + label + StyleMuted.Render(" "+right)
+}
+
+// renderModeChangeMessage renders a permission mode divider, colored like the
+// info bar's mode badge.
+func renderModeChangeMessage(msg message, width int) string {
+	left, right := dividerRules(msg.content, width)
+	clr := modeColor(msg.permissionMode)
+	if clr == nil {
+		clr = ColorTextSecondary
+	}
+	label := lipgloss.NewStyle().Foreground(clr).Render(msg.content)
+	return StyleMuted.Render(left+" ") + label + StyleMuted.Render(" "+right)
+}
+
+// renderFinalAnswer renders the session's final answer under a "final answer"
+// divider for --dump output. Returns empty when there is no answer.
+func (m model) renderFinalAnswer(width int) string {
+	text := finalAnswerText(m.messages)
+	if text == "" {
+		return ""
+	}
+	label := "final answer"
+	left, right := dividerRules(label, width)
+	divider := StyleAccentBold.Render(left + " " + label + " " + right)
+	return "\n" + divider + "\n\n" + m.md.renderMarkdown(text, max(width-4, 20))
+}
+
+// dividerRules returns the left and right horizontal rules that center text
+// (plus one space of padding on each side) within width.
+func dividerRules(text string, width int) (string, string) {
 	textWidth := lipgloss.Width(text) + 2 // " text " with spacing
 	leftPad := (width - textWidth) / 2
 	if leftPad < 0 {
@@ -372,9 +648,7 @@ func renderCompactMessage(msg message, width int) string {
 	if rightPad < 0 {
 		rightPad = 0
 	}
-	left := strings.Repeat(GlyphHRule, leftPad)
-	right := strings.Repeat(GlyphHRule, rightPad)
-	return dim.Render(left + " " + text + " " + right)
+	return strings.Repeat(GlyphHRule, leftPad), strings.Repeat(GlyphHRule, rightPad)
 }
 
 // -- Detail rendering ---------------------------------------------------------
@@ -390,18 +664,32 @@ func (m model) renderDetailContent(msg message, width int) rendered {
 	var header, body string
 	switch msg.role {
 	case RoleClaude:
-		header = m.renderDetailHeader(msg, width).content
+		header = m.renderDetailTop(msg, width).content
 		body = m.md.renderMarkdown(msg.content, width-4)
 	case RoleUser:
 		header = userHeaderLine(msg)
 		body = m.md.renderMarkdown(msg.content, width-4)
 	case RoleSystem:
-		header = Icon.System.Render() +
-			" " + StyleSecondary.Render("System") +
+		header = systemIcon(msg) +
+			" " + StyleSecondary.Render(render.SystemLabel(msg.systemKind)) +
 			"  " + StyleDim.Render(msg.timestamp)
 		body = StyleDim.Render(msg.content)
+	case RoleCommand:
+		header = commandHeaderLine(msg) + "  " + StyleDim.Render(msg.timestamp)
+		body = StyleDim.Width(max(width-4, 20)).Render(msg.content)
+	case RoleError:
+		header = apiErrorHeaderLine(msg) + "  " + StyleDim.Render(msg.timestamp)
+		body = strings.Join(apiErrorLines(msg.apiErrors), "\n")
 	case RoleCompact:
-		return newRendered(renderCompactMessage(msg, width))
+		divider := renderCompactMessage(msg, width)
+		if msg.compactSummary == "" {
+			return newRendered(divider)
+		}
+		return newRendered(divider + "\n\n" + m.md.renderMarkdown(msg.compactSummary, width-4))
+	case RoleModel:
+		return newRendered(renderModelChangeMessage(msg, width))
+	case RoleMode:
+		return newRendered(renderModeChangeMessage(msg, width))
 	}
 
 	return newRendered(header + "\n\n" + body)
@@ -414,18 +702,24 @@ func (m model) renderDetailContent(msg message, width int) rendered {
 // Uses the flat visible-row list so that expanded subagent children are
 // interleaved with parent items and can receive cursor highlights.
 func (m model) renderDetailItemsContent(msg message, width int) string {
-	header := m.renderDetailHeader(msg, width).content
+	header := m.renderDetailTop(msg, width).content
 	rows := buildVisibleRows(msg.items, m.detailExpanded)
 
 	childIndent := "    " // 4 spaces for child rows
 	childWidth := max(width-4, 20)
+	rowItems := make([]displayItem, len(rows))
+	for i, row := range rows {
+		rowItems[i] = row.item
+	}
+	cols := m.itemColumns(rowItems)
 
 	var itemLines []string
 	for ri, row := range rows {
 		if row.childIndex == -1 {
 			// Parent row.
 			isExp := m.detailExpanded[row.parentIndex]
-			rowStr := m.renderDetailItemRow(row.item, ri, m.detailCursor, isExp, width)
+			isMarked := m.detailMarked[visibleRowKey{row.parentIndex, -1}]
+			rowStr := m.renderDetailItemRow(row.item, ri, m.detailCursor, isExp, isMarked, cols, width)
 
 			if isExp {
 				if row.item.itemType == parser.ItemSubagent && row.item.subagentProcess != nil {
@@ -445,7 +739,7 @@ func (m model) renderDetailItemsContent(msg message, width int) string {
 			// Child row (indented, belongs to an expanded subagent).
 			key := visibleRowKey{row.parentIndex, row.childIndex}
 			isExp := m.detailChildExpanded[key]
-			childRow := m.renderDetailItemRow(row.item, ri, m.detailCursor, isExp, childWidth)
+			childRow := m.renderDetailItemRow(row.item, ri, m.detailCursor, isExp, m.detailMarked[key], cols, childWidth)
 
 			rowStr := childIndent + childRow
 			if isExp {
@@ -476,26 +770,31 @@ func renderTraceHeader(parent displayItem) string {
 	return traceIcon + "  " + traceLabel + dot + countStr
 }
 
-// renderDetailItemRow renders a single item row in the detail view.
-// Format: {cursor} {indicator} {name:<12} {summary}  {tokens} {duration}
-// isExpanded controls the cursor chevron direction (down vs right).
-func (m model) renderDetailItemRow(item displayItem, index, cursorIndex int, isExpanded bool, width int) string {
-	// Cursor indicator: drillable items get a distinct arrow, expanded items
-	// get chevron-down, collapsed items get chevron-right.
-	cursor := "  "
-	if index == cursorIndex {
-		if item.subagentProcess != nil {
-			cursor = Icon.DrillDown.RenderBold() + " "
-		} else if isExpanded {
-			cursor = Icon.Expanded.RenderBold() + " "
-		} else {
-			cursor = Icon.Collapsed.Render() + " "
-		}
-	}
+// itemColumns holds the widths of a set of item rows' aligned columns.
+type itemColumns struct {
+	name int // name, padded on the right
+	tok  int // token count, right-aligned
+	dur  int // duration, left-aligned
+}
 
-	// Type indicator and name
-	var indicator, name string
+// itemColumns sizes the columns of rows shown together: each is at least its
+// configured width and widens to fit the longest value in items, the name
+// column no further than columns.maxName.
+func (m model) itemColumns(items []displayItem) itemColumns {
+	c := m.cfg.Columns.withDefaults()
+	cols := itemColumns{name: c.Name, tok: c.Tokens, dur: c.Duration}
+	for _, item := range items {
+		_, name := itemIndicatorName(item)
+		cols.name = max(cols.name, min(lipgloss.Width(name), c.MaxName))
+		tok, dur := m.itemStats(item)
+		cols.tok = max(cols.tok, lipgloss.Width(tok))
+		cols.dur = max(cols.dur, lipgloss.Width(dur))
+	}
+	return cols
+}
 
+// itemIndicatorName returns an item row's type icon and name.
+func itemIndicatorName(item displayItem) (indicator, name string) {
 	switch item.itemType {
 	case parser.ItemThinking:
 		indicator = Icon.Thinking.Render()
@@ -507,7 +806,14 @@ func (m model) renderDetailItemRow(item displayItem, index, cursorIndex int, isE
 			name = item.toolName
 		}
 	case parser.ItemToolCall:
-		indicator = toolCategoryIcon(item.toolCategory, item.toolError)
+		indicator = toolCategoryIcon(item.toolCategory, item.toolError || item.hookError())
+		name = item.toolName
+	case parser.ItemHook:
+		if item.toolError {
+			indicator = Icon.Hook.WithColor(ColorError)
+		} else {
+			indicator = Icon.Hook.Render()
+		}
 		name = item.toolName
 	case parser.ItemSubagent:
 		if item.teamColor != "" {
@@ -534,50 +840,24 @@ func (m model) renderDetailItemRow(item displayItem, index, cursorIndex int, isE
 		if name == "" {
 			name = "Teammate"
 		}
+	case parser.ItemQueuedMessage:
+		indicator = Icon.User.Render()
+		name = "Queued"
 	}
 
-	// Pad name to 12 chars
-	nameStr := fmt.Sprintf("%-12s", name)
-	var nameRendered string
-	if item.teamColor != "" {
-		nameRendered = lipgloss.NewStyle().Bold(true).Foreground(teamColor(item.teamColor)).Render(nameStr)
-	} else {
-		nameRendered = StylePrimaryBold.Render(nameStr)
-	}
-
-	// Ongoing spinner for subagent items: 1 glyph + 1 space, or 2 spaces for alignment.
-	spinnerSlot := "  "
-	if item.itemType == parser.ItemSubagent && item.subagentOngoing {
-		frame := SpinnerFrames[m.animFrame%len(SpinnerFrames)]
-		spinnerSlot = lipgloss.NewStyle().Foreground(ColorOngoing).Render(frame) + " "
-	}
-
-	// Summary
-	var summary string
-	switch item.itemType {
-	case parser.ItemThinking, parser.ItemOutput:
-		summary = parser.Truncate(item.text, 40)
-	case parser.ItemToolCall:
-		summary = item.toolSummary
-	case parser.ItemSubagent:
-		summary = item.subagentDesc
-		if summary == "" {
-			summary = item.toolSummary
-		}
-	case parser.ItemTeammateMessage:
-		summary = parser.Truncate(item.text, 60)
-	}
-	// Suppress summary when it just repeats the tool name (common for MCP
-	// tools with empty input, where summaryDefault returns the name).
-	if summary == item.toolName {
-		summary = ""
-	}
-	summaryRendered := StyleSecondary.Render(summary)
+	return indicator, name
+}
 
-	// Right-side: tokens + duration.
-	// Prefer subagent process stats when linked (actual internal consumption).
+// itemStats returns an item row's token count and duration, or "" for each
+// it doesn't have. A linked subagent reports its own run (actual internal
+// consumption) rather than the Task call's.
+func (m model) itemStats(item displayItem) (tok, dur string) {
 	tokCount := item.tokenCount
 	durMs := item.durationMs
+	if bg := item.background; bg != nil {
+		// The call returns at once; the task's own run is what matters.
+		durMs = backgroundElapsedMs(bg, m.watching, time.Now())
+	}
 	if item.subagentProcess != nil {
 		if t := item.subagentProcess.Usage.TotalTokens(); t > 0 {
 			tokCount = t
@@ -586,23 +866,66 @@ func (m model) renderDetailItemRow(item displayItem, index, cursorIndex int, isE
 			durMs = d
 		}
 	}
-	// Build fixed-width right side so tok and dur columns align across all rows.
-	// "%*s  %-*s": tok right-aligned in detailItemTokWidth, dur left-aligned in detailItemDurWidth.
-	// Empty strings produce spaces, keeping the total width constant.
-	tokStr := ""
 	if tokCount > 0 {
-		tokStr = fmt.Sprintf("~%s tok", formatTokens(tokCount))
+		tok = fmt.Sprintf("~%s tok", formatTokens(tokCount))
 	}
-	durStr := ""
 	if durMs >= 1000 {
-		durStr = formatDuration(durMs)
+		dur = localRender.Duration(durMs)
 	} else if durMs > 0 {
-		durStr = "<1s"
+		dur = "<1s"
+	}
+	return tok, dur
+}
+
+// renderDetailItemRow renders a single item row in the detail view.
+// Format: {cursor} {mark} {indicator} {name} {summary}  {tokens} {duration},
+// with name, tokens, and duration in the widths of cols. isExpanded controls
+// the cursor chevron direction (down vs right); isMarked adds a check mark
+// for rows marked for bulk actions.
+func (m model) renderDetailItemRow(item displayItem, index, cursorIndex int, isExpanded, isMarked bool, cols itemColumns, width int) string {
+	// Cursor indicator: drillable items get a distinct arrow, expanded items
+	// get chevron-down, collapsed items get chevron-right.
+	cursor := "  "
+	if index == cursorIndex {
+		if item.subagentProcess != nil {
+			cursor = Icon.DrillDown.RenderBold() + " "
+		} else if isExpanded {
+			cursor = Icon.Expanded.RenderBold() + " "
+		} else {
+			cursor = Icon.Collapsed.Render() + " "
+		}
+	}
+
+	indicator, name := itemIndicatorName(item)
+	if lipgloss.Width(name) > cols.name {
+		name = parser.Truncate(name, cols.name)
+	}
+
+	nameStr := name + strings.Repeat(" ", max(cols.name-lipgloss.Width(name), 0))
+	var nameRendered string
+	if item.teamColor != "" {
+		nameRendered = lipgloss.NewStyle().Bold(true).Foreground(teamColor(item.teamColor)).Render(nameStr)
+	} else {
+		nameRendered = StylePrimaryBold.Render(nameStr)
 	}
+
+	// Ongoing spinner for subagent items: 1 glyph + 1 space, or 2 spaces for alignment.
+	spinnerSlot := "  "
+	if item.itemType == parser.ItemSubagent && item.subagentOngoing {
+		frame := SpinnerFrames[m.animFrame%len(SpinnerFrames)]
+		spinnerSlot = lipgloss.NewStyle().Foreground(ColorOngoing).Render(frame) + " "
+	}
+
+	summary := m.detailItemSummary(item)
+	summaryRendered := StyleSecondary.Render(summary)
+
+	// Right side: tokens and duration, in columns aligned across the rows.
+	// Empty values pad to the column width, keeping the total width constant.
+	tokStr, durStr := m.itemStats(item)
 	var rightSide string
 	if tokStr != "" || durStr != "" {
-		tokPart := StyleDim.Render(fmt.Sprintf("%*s", detailItemTokWidth, tokStr))
-		durPart := StyleDim.Render(fmt.Sprintf("%-*s", detailItemDurWidth, durStr))
+		tokPart := StyleDim.Render(fmt.Sprintf("%*s", cols.tok, tokStr))
+		durPart := StyleDim.Render(fmt.Sprintf("%-*s", cols.dur, durStr))
 		// When both present, prefix duration with a green dot separator.
 		// The dot + space adds 2 visible chars; pad the else branch to match.
 		if tokStr != "" && durStr != "" {
@@ -613,15 +936,188 @@ func (m model) renderDetailItemRow(item displayItem, index, cursorIndex int, isE
 		}
 	}
 
+	if isMarked {
+		cursor += Icon.Task.Done.Render() + " "
+	}
+
+	if m.basketIndex(item) >= 0 {
+		spinnerSlot += Icon.Basket.Render() + " "
+	}
+	// Finished subagents that stopped abnormally say why.
+	if badge := endStateBadge(item); badge != "" {
+		spinnerSlot += badge + " "
+	}
+	if badge := backgroundBadge(item); badge != "" {
+		spinnerSlot += badge + " "
+	}
+	if skill := itemSkill(item); skill != "" {
+		spinnerSlot += StyleAccentBold.Render(skill) + " "
+	}
+
 	var left string
 	if summary != "" {
 		left = cursor + indicator + " " + nameRendered + spinnerSlot + StyleDim.Render("- ") + summaryRendered
 	} else {
 		left = cursor + indicator + " " + nameRendered + spinnerSlot
 	}
+
+	// Live step of an ongoing subagent, in whatever room the row has left.
+	if item.subagentOngoing && item.subagentStep != "" {
+		sep := " " + Icon.Dot.Glyph + " "
+		if summary == "" {
+			sep = ""
+		}
+		room := width - lipgloss.Width(left) - lipgloss.Width(rightSide) - lipgloss.Width(sep) - 2
+		if room >= 10 {
+			step := lipgloss.NewStyle().Foreground(ColorOngoing).Render(parser.Truncate(item.subagentStep, room))
+			left += StyleDim.Render(sep) + step
+		}
+	}
 	return spaceBetween(left, rightSide, width)
 }
 
+// itemSkill returns the skill a Skill tool call invoked, or "".
+func itemSkill(item displayItem) string {
+	if item.itemType != parser.ItemToolCall || item.toolName != "Skill" {
+		return ""
+	}
+	return parser.SkillName(json.RawMessage(item.toolInput))
+}
+
+// endStateBadge returns the end state of a finished subagent item that was
+// interrupted, errored, or hit the context limit, or "" otherwise.
+func endStateBadge(item displayItem) string {
+	if item.itemType != parser.ItemSubagent || item.subagentOngoing || item.subagentProcess == nil {
+		return ""
+	}
+	switch state := item.subagentProcess.EndState; state {
+	case parser.EndInterrupted:
+		return StyleWarningBold.Render(state.String())
+	case parser.EndErrored, parser.EndContextLimit:
+		return StyleErrorBold.Render(state.String())
+	}
+	return ""
+}
+
+// backgroundBadge returns the state of a background Bash task, or "" for
+// other items.
+func backgroundBadge(item displayItem) string {
+	bg := item.background
+	if bg == nil {
+		return ""
+	}
+	label := "bg " + bg.Status
+	switch bg.Status {
+	case parser.BackgroundRunning:
+		return lipgloss.NewStyle().Foreground(ColorOngoing).Render(label)
+	case "completed":
+		return StyleDim.Render(label)
+	case "killed":
+		return StyleWarningBold.Render(label)
+	}
+	return StyleErrorBold.Render(label)
+}
+
+// backgroundElapsedMs returns how long a background task ran, start to
+// notification. A task still running counts up to now while the session is
+// being watched, unless it started so long ago that the notification was
+// evidently lost.
+func backgroundElapsedMs(bg *parser.BackgroundTask, watching bool, now time.Time) int64 {
+	if !bg.Running() {
+		return bg.ElapsedMs()
+	}
+	if !watching || bg.Started.IsZero() || now.Sub(bg.Started) > staleSessionThreshold {
+		return 0
+	}
+	return now.Sub(bg.Started).Milliseconds()
+}
+
+// renderBackgroundLifecycle renders a background task's transitions, one
+// per line: started, running under its task ID, then how it ended.
+func renderBackgroundLifecycle(bg *parser.BackgroundTask, watching bool, now time.Time, indent string) string {
+	row := func(state, detail string) string {
+		return indent + StyleSecondaryBold.Render(fmt.Sprintf("%-10s", state)) + StyleDim.Render(detail)
+	}
+	lines := []string{
+		row("started", formatTime(bg.Started)),
+		row("running", "task "+bg.ID),
+	}
+	if bg.Running() {
+		if ms := backgroundElapsedMs(bg, watching, now); ms > 0 {
+			lines[1] += StyleDim.Render(" " + Icon.Dot.Glyph + " " + localRender.Duration(ms) + " so far")
+		}
+		return strings.Join(lines, "\n")
+	}
+	ended := formatTime(bg.Ended)
+	if ms := bg.ElapsedMs(); ms > 0 {
+		ended += " " + Icon.Dot.Glyph + " " + localRender.Duration(ms)
+	}
+	lines = append(lines, row(bg.Status, ended))
+	if bg.Summary != "" {
+		lines = append(lines, indent+"  "+StyleDim.Render(bg.Summary))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// detailItemSummary returns the one-line summary shown after an item's name
+// in the detail view, truncated to the configured limits.
+func (m model) detailItemSummary(item displayItem) string {
+	var summary string
+	switch item.itemType {
+	case parser.ItemThinking, parser.ItemOutput:
+		summary = parser.Truncate(item.text, m.collapse().Detail.Text)
+	case parser.ItemToolCall:
+		summary = item.toolSummary
+		if skill := itemSkill(item); skill != "" {
+			// The row badges the skill; keep only its arguments.
+			summary = strings.TrimPrefix(strings.TrimPrefix(summary, skill), ": ")
+		}
+	case parser.ItemSubagent:
+		summary = item.subagentDesc
+		if summary == "" {
+			summary = item.toolSummary
+		}
+	case parser.ItemTeammateMessage, parser.ItemHook, parser.ItemQueuedMessage:
+		summary = parser.Truncate(item.text, m.collapse().Detail.Message)
+	}
+	// Suppress summary when it just repeats the tool name (common for MCP
+	// tools with empty input, where summaryDefault returns the name).
+	if summary == item.toolName {
+		return ""
+	}
+	return summary
+}
+
+// fullItemSummary returns the untruncated text behind an item's summary:
+// "description: command" for Bash, the description for subagents, the tool
+// target (path, command, URL, pattern) for other tools, and the whole text
+// for thinking, output, hooks, and teammate messages. Newlines become spaces.
+func fullItemSummary(item displayItem) string {
+	var full string
+	switch item.itemType {
+	case parser.ItemToolCall, parser.ItemSubagent:
+		var input struct {
+			Description string `json:"description"`
+			Command     string `json:"command"`
+		}
+		_ = json.Unmarshal([]byte(item.toolInput), &input)
+		switch {
+		case input.Description != "" && input.Command != "":
+			full = input.Description + ": " + input.Command
+		case item.itemType == parser.ItemSubagent && input.Description != "":
+			full = input.Description
+		default:
+			full = parser.ToolTarget(json.RawMessage(item.toolInput))
+		}
+		if full == "" {
+			full = item.toolSummary
+		}
+	default:
+		full = item.text
+	}
+	return strings.Join(strings.Fields(full), " ")
+}
+
 // renderDetailItemExpanded renders the expanded content for a detail item.
 // Indented 4 spaces, word-wrapped to width-8.
 func (m model) renderDetailItemExpanded(item displayItem, width int) rendered {
@@ -630,13 +1126,17 @@ func (m model) renderDetailItemExpanded(item displayItem, width int) rendered {
 
 	var content string
 	switch item.itemType {
-	case parser.ItemThinking, parser.ItemOutput, parser.ItemTeammateMessage:
+	case parser.ItemThinking, parser.ItemOutput, parser.ItemTeammateMessage, parser.ItemQueuedMessage:
 		text := strings.TrimSpace(item.text)
 		if text == "" {
 			return rendered{}
 		}
 		md := m.md.renderMarkdown(text, wrapWidth)
 		content = indentBlock(md, indent)
+		if len(item.references) > 0 {
+			content += "\n" + indent + StyleMuted.Render(strings.Repeat("-", wrapWidth)) +
+				"\n" + renderReferences(item.references, wrapWidth, indent)
+		}
 
 	case parser.ItemSubagent:
 		if item.subagentProcess != nil {
@@ -647,11 +1147,17 @@ func (m model) renderDetailItemExpanded(item displayItem, width int) rendered {
 
 	case parser.ItemToolCall:
 		content = m.renderToolExpanded(item, wrapWidth, indent)
+
+	case parser.ItemHook:
+		content = renderHookOutputs(item.hooks, wrapWidth, indent)
 	}
 
 	if content == "" {
 		return rendered{}
 	}
+	if summary := m.renderItemSummary(item, wrapWidth, indent); summary != "" {
+		content = summary + "\n" + content
+	}
 	return newRendered(content)
 }
 
@@ -659,11 +1165,18 @@ func (m model) renderDetailItemExpanded(item displayItem, width int) rendered {
 func (m model) renderToolExpanded(item displayItem, wrapWidth int, indent string) string {
 	var sections []string
 
+	if diff := m.renderInputDiff(item, wrapWidth, indent); diff != "" {
+		sections = append(sections, diff)
+	}
 	if item.toolInput != "" {
 		headerStyle := StyleSecondaryBold
 		sections = append(sections, indent+headerStyle.Render("Input:"))
-		sections = append(sections, indentBlock(
-			m.highlightOrDim(item.toolInput, wrapWidth), indent))
+		if tree, ok := renderJSONTreeInline(item.toolInput, wrapWidth); ok {
+			sections = append(sections, indentBlock(tree, indent))
+		} else {
+			sections = append(sections, indentBlock(
+				m.highlightOrDim(item.toolInput, wrapWidth), indent))
+		}
 	}
 
 	if item.toolResult != "" || item.toolError {
@@ -681,7 +1194,23 @@ func (m model) renderToolExpanded(item displayItem, wrapWidth int, indent string
 		}
 
 		sections = append(sections, indentBlock(
-			m.highlightOrDim(item.toolResult, wrapWidth), indent))
+			m.renderToolResult(item.toolResult, wrapWidth), indent))
+	}
+
+	if item.background != nil {
+		if len(sections) > 0 {
+			sections = append(sections, indent+StyleMuted.Render(strings.Repeat("-", wrapWidth)))
+		}
+		sections = append(sections, indent+StyleSecondaryBold.Render("Background:"))
+		sections = append(sections, renderBackgroundLifecycle(item.background, m.watching, time.Now(), indent+"  "))
+	}
+
+	if len(item.hooks) > 0 {
+		if len(sections) > 0 {
+			sections = append(sections, indent+StyleMuted.Render(strings.Repeat("-", wrapWidth)))
+		}
+		sections = append(sections, indent+StyleSecondaryBold.Render("Hooks:"))
+		sections = append(sections, renderHookOutputs(item.hooks, wrapWidth, indent))
 	}
 
 	if len(sections) == 0 {
@@ -690,6 +1219,29 @@ func (m model) renderToolExpanded(item displayItem, wrapWidth int, indent string
 	return strings.Join(sections, "\n")
 }
 
+// renderHookOutputs renders one "{icon} PreToolUse:Bash [cmd]" line per hook,
+// followed by its output. Failed hooks render in the error color.
+func renderHookOutputs(hooks []parser.HookOutput, wrapWidth int, indent string) string {
+	var lines []string
+	for _, h := range hooks {
+		icon := Icon.Hook.Render()
+		name := StylePrimaryBold.Render(h.Name())
+		if h.IsError {
+			icon = Icon.Hook.WithColor(ColorError)
+			name = StyleErrorBold.Render(h.Name())
+		}
+		header := icon + " " + name
+		if h.Command != "" {
+			header += " " + StyleDim.Render("["+h.Command+"]")
+		}
+		lines = append(lines, indent+header)
+		if out := strings.TrimSpace(h.Output); out != "" {
+			lines = append(lines, indentBlock(StyleDim.Width(max(wrapWidth-2, 10)).Render(out), indent+"  "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // highlightOrDim tries JSON syntax highlighting; falls back to dim text.
 // Width wrapping is applied in both paths for consistent layout.
 func (m model) highlightOrDim(text string, wrapWidth int) string {
@@ -734,12 +1286,9 @@ func (m model) renderTaskInput(item displayItem, wrapWidth int, indent string) s
 	if raw, ok := fields["prompt"]; ok {
 		var prompt string
 		if json.Unmarshal(raw, &prompt) == nil && prompt != "" {
+			// Truncate collapses newlines for a compact preview.
 			const maxPrompt = 500
-			if len(prompt) > maxPrompt {
-				prompt = prompt[:maxPrompt] + Icon.Ellipsis.Glyph
-			}
-			// Collapse newlines for a compact preview.
-			prompt = strings.ReplaceAll(prompt, "\n", " ")
+			prompt = parser.Truncate(prompt, maxPrompt)
 			promptRendered := valueStyle.Width(wrapWidth).Render(prompt)
 			lines = append(lines, indent+labelStyle.Render("prompt:")+
 				" "+promptRendered)
@@ -758,7 +1307,7 @@ func (m model) renderTaskInput(item displayItem, wrapWidth int, indent string) s
 			lines = append(lines, indent+labelStyle.Render("Result:"))
 		}
 		lines = append(lines, indentBlock(
-			m.highlightOrDim(item.toolResult, wrapWidth), indent))
+			m.renderToolResult(item.toolResult, wrapWidth), indent))
 	}
 
 	if len(lines) == 0 {
@@ -778,8 +1327,9 @@ func (m model) renderSubagentTrace(item displayItem, wrapWidth int, indent strin
 
 	// All trace items as compact rows (no cursor, no cap).
 	nestedWidth := wrapWidth - 4
+	cols := m.itemColumns(traceItems)
 	for i, di := range traceItems {
-		row := m.renderDetailItemRow(di, i, -1, false, nestedWidth)
+		row := m.renderDetailItemRow(di, i, -1, false, false, cols, nestedWidth)
 		lines = append(lines, indent+"  "+row)
 	}
 
@@ -791,7 +1341,7 @@ func (m model) renderSubagentTrace(item displayItem, wrapWidth int, indent strin
 // in list view). Matches the list view header layout for visual consistency.
 //
 // When in a trace drill-down (savedDetail != nil && traceMsg != nil), a dim
-// breadcrumb prefix shows the parent view: "Claude opus4.6 > ..."
+// breadcrumb prefix shows the parent views: "Claude opus4.6 > Explore > ..."
 func (m model) renderDetailHeader(msg message, width int, leftSuffix ...string) rendered {
 	headerIcon := Icon.Claude
 	headerLabel := "Claude"
@@ -807,10 +1357,21 @@ func (m model) renderDetailHeader(msg message, width int, leftSuffix ...string)
 	var breadcrumb string
 	if m.savedDetail != nil && m.traceMsg != nil {
 		sep := StyleMuted.Render(" > ")
-		breadcrumb = StyleDim.Render(m.savedDetail.label) + sep
+		for _, label := range traceBreadcrumb(m.savedDetail) {
+			breadcrumb += StyleDim.Render(label) + sep
+		}
 	}
 
 	left := breadcrumb + icon + " " + modelName + " " + modelVer + detailHeaderStats(msg) + subagentIcons(msg.items)
+	if badge := loopBadge(msg); badge != "" {
+		left += "  " + badge
+	}
+	if badge := cacheRebuildBadge(msg); badge != "" {
+		left += "  " + badge
+	}
+	if badge := turnErrorBadge(msg); badge != "" {
+		left += "  " + badge
+	}
 	for _, s := range leftSuffix {
 		left += "  " + s
 	}
@@ -818,6 +1379,25 @@ func (m model) renderDetailHeader(msg message, width int, leftSuffix ...string)
 	return newRendered(spaceBetween(left, detailHeaderMeta(msg), width))
 }
 
+// maxBreadcrumbLevels is how many parent levels the breadcrumb shows before
+// eliding the middle ones.
+const maxBreadcrumbLevels = 3
+
+// traceBreadcrumb returns the labels of every level above the current trace,
+// outermost first. Deep stacks keep the top level and the nearest parents,
+// with an ellipsis standing in for the rest.
+func traceBreadcrumb(saved *savedDetailState) []string {
+	var labels []string
+	for s := saved; s != nil; s = s.parent {
+		labels = append([]string{s.label}, labels...)
+	}
+	if len(labels) > maxBreadcrumbLevels {
+		tail := labels[len(labels)-(maxBreadcrumbLevels-1):]
+		labels = append([]string{labels[0], Icon.Ellipsis.Glyph}, tail...)
+	}
+	return labels
+}
+
 // detailHeaderStats formats the stats summary using icons for compactness:
 // 🧠2  󰯠9  💬4  instead of "2 thinking, 9 tool calls, 4 messages".
 func detailHeaderStats(msg message) string {
@@ -837,6 +1417,9 @@ func detailHeaderStats(msg message) string {
 	if msg.teammateMessages > 0 {
 		parts = append(parts, Icon.Chat.Render()+" "+StyleSecondary.Render(fmt.Sprintf("%d", msg.teammateMessages)))
 	}
+	if msg.hiddenToolCount > 0 {
+		parts = append(parts, StyleDim.Render(fmt.Sprintf("(%d hidden)", msg.hiddenToolCount)))
+	}
 
 	if len(parts) == 0 {
 		return ""
@@ -845,6 +1428,117 @@ func detailHeaderStats(msg message) string {
 	return dot + strings.Join(parts, "  ")
 }
 
+// loopBadge returns "possible loop Bash ×4" for messages flagged by
+// markLoops, or "" otherwise.
+func loopBadge(msg message) string {
+	if msg.loopCount == 0 {
+		return ""
+	}
+	return StyleErrorBold.Render("possible loop") + " " +
+		StyleDim.Render(fmt.Sprintf("%s ×%d", msg.loopTool, msg.loopCount))
+}
+
+// cacheRebuildBadge returns "cache rebuilt 82.0k" for turns that re-wrote a
+// warm prompt cache, or "" otherwise.
+func cacheRebuildBadge(msg message) string {
+	if msg.cacheRebuilt == 0 {
+		return ""
+	}
+	return StyleWarningBold.Render("cache rebuilt") + " " + StyleDim.Render(formatTokens(msg.cacheRebuilt))
+}
+
+// turnErrorBadge returns "2 API errors" for turns with failed requests in
+// them, or "" otherwise. It's red when the last one gave up.
+func turnErrorBadge(msg message) string {
+	n := len(msg.apiErrors)
+	if msg.role != RoleClaude || n == 0 {
+		return ""
+	}
+	style := StyleWarningBold
+	if msg.apiErrors[n-1].Final {
+		style = StyleErrorBold
+	}
+	return style.Render(parser.Plural(n, "API error"))
+}
+
+// apiErrorLines renders one line per API error: "10:04:12 AM  overloaded
+// (529) · retry 3/10 in 2.2s".
+func apiErrorLines(errs []parser.ErrorMsg) []string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = StyleDim.Render(formatTime(e.Timestamp)) + "  " + StyleSecondary.Render(localRender.ErrorLine(e))
+	}
+	return lines
+}
+
+// settingPart is one named request setting as displayed.
+type settingPart struct {
+	name, value string
+}
+
+// settingParts lists the recorded settings in display order, skipping those
+// the transcript didn't record.
+func settingParts(s parser.RequestSettings) []settingPart {
+	var parts []settingPart
+	switch {
+	case s.ThinkingDisabled:
+		parts = append(parts, settingPart{"thinking", "off"})
+	case s.ThinkingLevel != "":
+		parts = append(parts, settingPart{"thinking", s.ThinkingLevel})
+	}
+	if s.ThinkingBudget > 0 {
+		parts = append(parts, settingPart{"budget", formatTokens(s.ThinkingBudget)})
+	}
+	if s.ServiceTier != "" {
+		parts = append(parts, settingPart{"tier", s.ServiceTier})
+	}
+	if s.Version != "" {
+		parts = append(parts, settingPart{"version", s.Version})
+	}
+	return parts
+}
+
+// settingsLine renders the turn's request settings under the detail header:
+// "thinking high · budget 32.0k · tier standard · version 2.0.14". Settings
+// that differ from the previous Claude turn are highlighted with their old
+// value. Returns "" when nothing was recorded.
+func settingsLine(msg message) string {
+	parts := settingParts(msg.settings)
+	if len(parts) == 0 {
+		return ""
+	}
+	prev := make(map[string]string)
+	if msg.prevSettings != nil {
+		for _, p := range settingParts(*msg.prevSettings) {
+			prev[p.name] = p.value
+		}
+	}
+	var rendered []string
+	for _, p := range parts {
+		s := StyleDim.Render(p.name) + " "
+		if old := prev[p.name]; old != "" && old != p.value {
+			s += StyleSecondaryBold.Render(p.value) + StyleDim.Render(" (was "+old+")")
+		} else {
+			s += StyleSecondary.Render(p.value)
+		}
+		rendered = append(rendered, s)
+	}
+	return strings.Join(rendered, StyleDim.Render(" "+Icon.Dot.Glyph+" "))
+}
+
+// renderDetailTop renders the detail header plus, for turns that recorded
+// them, the request settings line and the API errors below it.
+func (m model) renderDetailTop(msg message, width int) rendered {
+	header := m.renderDetailHeader(msg, width).content
+	if line := settingsLine(msg); line != "" {
+		header += "\n" + line
+	}
+	if msg.role == RoleClaude && len(msg.apiErrors) > 0 {
+		header += "\n" + strings.Join(apiErrorLines(msg.apiErrors), "\n")
+	}
+	return newRendered(header)
+}
+
 // subagentIcons returns a colored bot icon for each subagent spawned in this
 // message. Provides an at-a-glance count and identity of spawned agents.
 func subagentIcons(items []displayItem) string {
@@ -860,14 +1554,18 @@ func subagentIcons(items []displayItem) string {
 	return " " + strings.Join(icons, " ")
 }
 
-// detailHeaderMeta formats the right-side metadata (tokens, duration, timestamp).
+// detailHeaderMeta formats the right-side metadata (context delta, tokens,
+// duration, timestamp).
 func detailHeaderMeta(msg message) string {
 	var parts []string
+	if d := formatContextDelta(msg.contextDelta); d != "" {
+		parts = append(parts, StyleDim.Render(d))
+	}
 	if msg.tokensRaw > 0 {
 		parts = append(parts, Icon.Token.Render()+" "+StyleSecondary.Render(formatTokens(msg.tokensRaw)))
 	}
 	if msg.durationMs > 0 {
-		parts = append(parts, Icon.Clock.Render()+" "+StyleSecondary.Render(formatDuration(msg.durationMs)))
+		parts = append(parts, Icon.Clock.Render()+" "+StyleSecondary.Render(localRender.Duration(msg.durationMs)))
 	}
 	if msg.timestamp != "" {
 		parts = append(parts, StyleDim.Render(msg.timestamp))
@@ -911,6 +1609,11 @@ func (m model) viewDebugLog() string {
 		filterPromptHeight = 1
 	}
 
+	header := m.renderDebugHeader(width)
+	if m.debugOngoing {
+		header += "\n" + m.renderOngoingPanel(width)
+	}
+
 	if len(m.debugFiltered) == 0 {
 		filterInfo := debugFilterLabel(m.debugMinLevel)
 		if m.debugFilterText != "" {
@@ -919,7 +1622,7 @@ func (m model) viewDebugLog() string {
 		empty := StyleDim.Render("No debug entries (filter: " + filterInfo + ")")
 		footer := m.renderDebugFooter("")
 		padding := strings.Repeat("\n", max(m.debugViewHeight()-filterPromptHeight-1, 0))
-		output := centerBlock(empty+padding, width, m.width)
+		output := centerBlock(header+"\n"+empty+padding, width, m.width)
 		if m.debugFilterMode {
 			output += "\n" + m.renderDebugFilterPrompt(width)
 		}
@@ -965,7 +1668,7 @@ func (m model) viewDebugLog() string {
 		allLines = append(allLines, "")
 	}
 
-	output := strings.Join(allLines, "\n")
+	output := header + "\n" + strings.Join(allLines, "\n")
 	output = centerBlock(output, width, m.width)
 
 	// Scroll position indicator
@@ -984,6 +1687,55 @@ func (m model) viewDebugLog() string {
 	return output + "\n" + footer
 }
 
+// renderDebugHeader renders the debug view title with the entry count and,
+// while tailing, the session watcher's current poll interval.
+func (m model) renderDebugHeader(width int) string {
+	left := StyleAccentBold.Render("Debug log") + " " +
+		StyleDim.Render(fmt.Sprintf("(%d entries)", len(m.debugFiltered)))
+	if m.pollRate.interval == 0 {
+		return left
+	}
+	rate := "poll " + m.pollRate.interval.String()
+	if m.pollRate.idle {
+		rate += " (idle)"
+	}
+	return spaceBetween(left, StyleMuted.Render(rate), width)
+}
+
+// renderOngoingPanel explains the ongoing verdict for the session: the rules
+// in effect and each step that led to the answer. The live indicator can lag
+// the verdict by the grace period. For a session the picker lists, the
+// picker's own verdict follows.
+func (m model) renderOngoingPanel(width int) string {
+	indicator := "idle"
+	if m.sessionOngoing {
+		indicator = "live"
+	}
+	lines := ongoingVerdictLines("Why", m.ongoingWhy, "(indicator: "+indicator+")", width)
+	if m.debugPickerWhy != nil {
+		lines = append(lines, ongoingVerdictLines("Picker: why", *m.debugPickerWhy, "(metadata scan)", width)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ongoingVerdictLines renders one verdict for the why-ongoing panel: the
+// heading ("Why ongoing") with a muted note, then a bullet per step.
+func ongoingVerdictLines(heading string, why parser.OngoingVerdict, note string, width int) []string {
+	verdict := "not ongoing"
+	if why.Ongoing {
+		verdict = "ongoing"
+	}
+	lines := []string{StyleSecondaryBold.Render(heading+" "+verdict) + " " + StyleMuted.Render(note)}
+	if len(why.Steps) == 0 {
+		lines = append(lines, StyleDim.Render("  No verdict yet"))
+	}
+	for _, step := range why.Steps {
+		wrapped := indentBlock(StyleDim.Width(max(width-4, 1)).Render(step), "    ")
+		lines = append(lines, "  -"+wrapped[3:])
+	}
+	return lines
+}
+
 // renderDebugFooter builds the footer for the debug view, including
 // text filter state and the standard keybind pairs.
 func (m model) renderDebugFooter(scrollInfo string) string {
@@ -998,6 +1750,7 @@ func (m model) renderDebugFooter(scrollInfo string) string {
 		"f", filterLabel,
 		"y", "copy path",
 		"O", "editor",
+		"w", "why ongoing",
 		"q/esc", "back"+scrollInfo,
 		"?", "keys",
 	)
@@ -1088,34 +1841,7 @@ func (m model) renderDebugEntry(entry parser.DebugEntry, index int, isCursor boo
 // styleDebugMessage styles a debug message string by level, then highlights
 // any text filter matches with a reverse-video accent.
 func (m model) styleDebugMessage(msg string, level parser.DebugLevel) string {
-	if m.debugFilterText == "" {
-		// No filter active: plain level-based styling.
-		return debugLevelStyle(msg, level)
-	}
-
-	// Highlight matched substrings. Case-insensitive scan, preserving original case.
-	query := strings.ToLower(m.debugFilterText)
-	lower := strings.ToLower(msg)
-	hlStyle := lipgloss.NewStyle().Bold(true).Reverse(true).Foreground(ColorAccent)
-
-	var out strings.Builder
-	pos := 0
-	for {
-		idx := strings.Index(lower[pos:], query)
-		if idx < 0 {
-			out.WriteString(debugLevelStyle(msg[pos:], level))
-			break
-		}
-		// Text before the match.
-		if idx > 0 {
-			out.WriteString(debugLevelStyle(msg[pos:pos+idx], level))
-		}
-		// The matched substring, highlighted.
-		matchEnd := pos + idx + len(query)
-		out.WriteString(hlStyle.Render(msg[pos+idx : matchEnd]))
-		pos = matchEnd
-	}
-	return out.String()
+	return highlightMatches(debugLevelStyle(msg, level), m.debugFilterText)
 }
 
 // debugLevelStyle applies the standard level-based foreground color to text.
@@ -1141,12 +1867,51 @@ func (m model) activityIndicatorHeight() int {
 	return 0
 }
 
+// -- Summary footnote ----------------------------------------------------------
+
+// maxFootnoteLines caps how many wrapped lines the full-summary footnote may
+// take above the footer.
+const maxFootnoteLines = 4
+
+// renderDetailFootnote renders the full summary of the selected detail row,
+// wrapped to width and capped at maxFootnoteLines, or "" when none is shown.
+func (m model) renderDetailFootnote(width int) string {
+	if m.detailFootnote == "" {
+		return ""
+	}
+	prefix := Icon.Ellipsis.Render() + " "
+	body := lipgloss.NewStyle().Width(max(width-lipgloss.Width(prefix), 10)).
+		Render(StyleSecondary.Render(m.detailFootnote))
+	lines := strings.Split(body, "\n")
+	if len(lines) > maxFootnoteLines {
+		lines = lines[:maxFootnoteLines]
+		lines[maxFootnoteLines-1] += Icon.Ellipsis.Render()
+	}
+	for i := range lines {
+		if i == 0 {
+			lines[i] = prefix + lines[i]
+		} else {
+			lines[i] = "  " + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// detailFootnoteHeight returns the lines the full-summary footnote occupies
+// above the footer (0 when hidden).
+func (m model) detailFootnoteHeight() int {
+	if m.detailFootnote == "" {
+		return 0
+	}
+	return strings.Count(m.renderDetailFootnote(m.clampWidth()), "\n") + 1
+}
+
 // -- Footer height ------------------------------------------------------------
 
 // footerHeight returns the total footer line count: info bar (always) +
 // keybind hints (when showKeybinds is true).
 func (m model) footerHeight() int {
-	h := m.infoBarHeight()
+	h := m.errorBannerHeight() + m.infoBarHeight()
 	if m.showKeybinds {
 		h += keybindBarHeight
 	}
@@ -1162,7 +1927,7 @@ func (m model) footerHeight() int {
 
 // listViewHeight returns the visible content lines in the message list view.
 func (m model) listViewHeight() int {
-	h := m.height - m.footerHeight() - m.activityIndicatorHeight() - 1
+	h := m.height - m.footerHeight() - m.activityIndicatorHeight() - m.sortBannerHeight() - m.filterBarHeight() - 1
 	if h <= 0 {
 		return 1
 	}
@@ -1171,16 +1936,17 @@ func (m model) listViewHeight() int {
 
 // detailViewHeight returns the visible content lines in the detail view.
 func (m model) detailViewHeight() int {
-	h := m.height - m.footerHeight() - m.activityIndicatorHeight()
+	h := m.height - m.footerHeight() - m.activityIndicatorHeight() - m.detailFootnoteHeight()
 	if h <= 0 {
 		return 1
 	}
 	return h
 }
 
-// pickerViewHeight returns the visible content lines in the session picker.
+// pickerViewHeight returns the visible content lines in the session picker:
+// everything but the header (2 lines), the group stats line, and the footer.
 func (m model) pickerViewHeight() int {
-	h := m.height - 2 - m.footerHeight()
+	h := m.height - 2 - pickerStatsHeight - m.footerHeight()
 	if h <= 0 {
 		return 1
 	}
@@ -1223,6 +1989,20 @@ func (m model) renderActivityIndicator(width int) string {
 
 // -- Info bar -----------------------------------------------------------------
 
+// modeColor returns the badge color for a non-default permission mode, or nil
+// for default/unknown modes.
+func modeColor(mode string) color.Color {
+	switch mode {
+	case "bypassPermissions":
+		return ColorPillBypass
+	case "acceptEdits":
+		return ColorPillAcceptEdits
+	case "plan":
+		return ColorPillPlan
+	}
+	return nil
+}
+
 // renderModeBadge renders the permission mode as a 3-line RoundedBorder chip.
 // Returns an empty string for default/unknown modes (caller falls back to plain text).
 //
@@ -1231,15 +2011,8 @@ func (m model) renderActivityIndicator(width int) string {
 //	╰──────────╯
 func renderModeBadge(mode string) string {
 	label := shortMode(mode)
-	var clr color.Color
-	switch mode {
-	case "bypassPermissions":
-		clr = ColorPillBypass
-	case "acceptEdits":
-		clr = ColorPillAcceptEdits
-	case "plan":
-		clr = ColorPillPlan
-	default:
+	clr := modeColor(mode)
+	if clr == nil {
 		return ""
 	}
 	return lipgloss.NewStyle().
@@ -1253,53 +2026,45 @@ func renderModeBadge(mode string) string {
 // renderInfoBar renders the session metadata bar.
 //
 // When a flash status is active, it replaces the normal info bar content.
-// When a colored mode badge is active the bar is 3 lines: a RoundedBorder chip
-// on the left, with project/branch/context% vertically centered on the middle
-// row beside it. Otherwise it collapses to a single line.
+// Elements appear in the order the configured layout gives them. When a
+// colored mode badge is active (and the layout shows the mode) the bar is 3
+// lines: a RoundedBorder chip on the left, with the other elements vertically
+// centered on the middle row beside it. Otherwise it collapses to a single
+// line.
 func (m model) renderInfoBar() string {
 	// Flash status overrides the normal info bar.
 	if m.flashStatus != "" {
 		return " " + StyleAccentBold.Render(m.flashStatus)
 	}
+	if m.largeConfirm != "" {
+		return m.renderLargeConfirm()
+	}
+	if m.forkConfirm {
+		return m.renderForkConfirm()
+	}
+	if m.rangeActive && m.view == viewList {
+		return m.renderRangeStatus()
+	}
 
 	sep := " " + Icon.Dot.Render() + " "
+	layout := m.cfg.infoBarLayout()
 
-	// Build left metadata parts (path, branch).
-	var leftParts []string
-	if proj := shortPath(m.sessionCwd, m.sessionGitBranch); proj != "" {
-		leftParts = append(leftParts, StyleSecondary.Render(proj))
-	}
-	if m.liveBranch != "" {
-		branch := StyleDim.Render(m.liveBranch)
-		if m.liveDirty {
-			branch += lipgloss.NewStyle().Foreground(ColorContextWarn).Render("*")
-		}
-		leftParts = append(leftParts, branch)
+	var badge string
+	if layout.has(infoMode) {
+		badge = renderModeBadge(m.sessionMode)
 	}
-
-	// Context usage percentage (right-aligned).
-	var rightStr string
-	if pct := contextPercent(m.messages); pct >= 0 {
-		var clr color.Color
-		switch {
-		case pct > 80:
-			clr = ColorContextCrit
-		case pct > 50:
-			clr = ColorContextWarn
-		default:
-			clr = ColorContextOk
+	join := func(names []string) string {
+		var parts []string
+		for _, name := range names {
+			if part := m.infoBarElement(name, badge != ""); part != "" {
+				parts = append(parts, part)
+			}
 		}
-		rightStr = lipgloss.NewStyle().Foreground(clr).Render(fmt.Sprintf("%d%% ctx", pct))
+		return strings.Join(parts, sep)
 	}
-
-	badge := renderModeBadge(m.sessionMode)
+	leftStr, rightStr := join(layout.Left), join(layout.Right)
 
 	if badge == "" {
-		// No badge: single-line layout with mode as plain muted text.
-		if m.sessionMode != "" {
-			leftParts = append(leftParts, StyleMuted.Render(shortMode(m.sessionMode)))
-		}
-		leftStr := strings.Join(leftParts, sep)
 		if rightStr != "" {
 			return spaceBetween(" "+leftStr, rightStr+" ", m.width)
 		}
@@ -1310,7 +2075,6 @@ func (m model) renderInfoBar() string {
 	// Badge occupies the left column; metadata is a single line placed beside
 	// it using JoinHorizontal(Center), which vertically centers the 1-line
 	// string at the middle row of the 3-line badge.
-	leftStr := strings.Join(leftParts, sep)
 	badgeWidth := lipgloss.Width(badge)
 	remainingWidth := m.width - badgeWidth
 	var metaLine string
@@ -1322,9 +2086,87 @@ func (m model) renderInfoBar() string {
 	return lipgloss.JoinHorizontal(lipgloss.Center, badge, metaLine)
 }
 
+// infoBarElement renders one named info bar element, or "" when it has
+// nothing to show. With a mode badge, the mode element is the badge itself
+// and renders nothing inline.
+func (m model) infoBarElement(name string, hasBadge bool) string {
+	switch name {
+	case infoProject:
+		if proj := shortPath(m.sessionCwd, m.sessionGitBranch); proj != "" {
+			return StyleSecondary.Render(proj)
+		}
+	case infoBranch:
+		if m.liveBranch != "" {
+			branch := StyleDim.Render(m.liveBranch)
+			if m.liveDirty {
+				branch += lipgloss.NewStyle().Foreground(ColorContextWarn).Render("*")
+			}
+			return branch
+		}
+	case infoWindow:
+		// Tail window: older turns live on disk until L reloads them.
+		if m.partialHistory {
+			return StyleMuted.Render("earlier history not loaded (L)")
+		}
+		if m.evictedTurns > 0 {
+			return StyleMuted.Render(pluralCount(m.evictedTurns, "earlier turn") + " evicted (L)")
+		}
+		if m.fullHistory {
+			return StyleMuted.Render("full history (L)")
+		}
+	case infoViewers:
+		warn := lipgloss.NewStyle().Foreground(ColorWarning)
+		var parts []string
+		switch {
+		case m.otherViewers == 1:
+			parts = append(parts, warn.Render("also viewed by another instance"))
+		case m.otherViewers > 1:
+			parts = append(parts, warn.Render(fmt.Sprintf("also viewed by %d other instances", m.otherViewers)))
+		}
+		// Other terminals also resume the session into a new file.
+		if m.fork != nil {
+			parts = append(parts, warn.Render("resumed in another terminal (R)"))
+		}
+		if m.mergedFrom != "" {
+			parts = append(parts, StyleMuted.Render("merged with "+filepath.Base(m.mergedFrom)))
+		}
+		return strings.Join(parts, " ")
+	case infoFollow:
+		return m.renderFollowState()
+	case infoMode:
+		if !hasBadge && m.sessionMode != "" {
+			return StyleMuted.Render(shortMode(m.sessionMode))
+		}
+	case infoGrowth:
+		// Session growth rate while tailing: a live "is it making progress" proxy.
+		if m.watching {
+			if g := formatGrowth(m.growth, time.Now()); g != "" {
+				return StyleMuted.Render(g)
+			}
+		}
+	case infoContext:
+		if pct := contextPercent(m.messages); pct >= 0 {
+			var clr color.Color
+			switch {
+			case pct > 80:
+				clr = ColorContextCrit
+			case pct > 50:
+				clr = ColorContextWarn
+			default:
+				clr = ColorContextOk
+			}
+			return lipgloss.NewStyle().Foreground(clr).Render(fmt.Sprintf("%d%% ctx", pct))
+		}
+	}
+	return ""
+}
+
 // renderFooter builds the complete footer: info bar + optional keybind hints.
 func (m model) renderFooter(keybindPairs ...string) string {
 	footer := m.renderInfoBar()
+	if m.errorBanner != "" {
+		footer = m.renderErrorBanner() + "\n" + footer
+	}
 	if m.showKeybinds {
 		footer += "\n" + m.renderKeybindBar(keybindPairs...)
 	}
@@ -1378,7 +2220,7 @@ func (m model) teamViewHeight() int {
 func (m model) viewTeamBoard() string {
 	width := m.clampWidth()
 
-	if len(m.teams) == 0 {
+	if !m.hasTaskBoard() {
 		empty := StyleDim.Render("No teams found")
 		padding := strings.Repeat("\n", max(m.teamViewHeight()-1, 0))
 		footer := m.renderFooter("q/esc", "back", "?", "keys")
@@ -1431,10 +2273,14 @@ func (m model) viewTeamBoard() string {
 	return output + "\n" + footer
 }
 
-// renderTeamContent renders all team sections joined by blank lines.
-// Most recent team first (reverse order, since teams are appended chronologically).
+// renderTeamContent renders the unfinished work panel, then all team
+// sections, joined by blank lines. Most recent team first (reverse order,
+// since teams are appended chronologically).
 func (m model) renderTeamContent(width, animFrame int) string {
 	var sections []string
+	if todo := renderTodoSection(m.todos, width); todo != "" {
+		sections = append(sections, todo)
+	}
 	for i := len(m.teams) - 1; i >= 0; i-- {
 		team := m.teams[i]
 		if team.Deleted {
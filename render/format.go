@@ -0,0 +1,88 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// Options tunes how the labels are written. The zero value, which the
+// package-level functions use, writes numbers strconv's way: "1234.5".
+type Options struct {
+	// Decimal writes f with prec decimals, for a program that localizes
+	// numbers. Nil uses strconv.
+	Decimal func(f float64, prec int) string
+}
+
+// decimal writes f with prec decimals through o.Decimal.
+func (o Options) decimal(f float64, prec int) string {
+	if o.Decimal == nil {
+		return strconv.FormatFloat(f, 'f', prec, 64)
+	}
+	return o.Decimal(f, prec)
+}
+
+// Duration formats milliseconds for reading: 71000 is "1m 11s", 3500 is
+// "3.5s".
+func Duration(ms int64) string { return Options{}.Duration(ms) }
+
+// Duration is the package-level Duration, with numbers written by o.
+func (o Options) Duration(ms int64) string {
+	secs := float64(ms) / 1000
+	switch {
+	case secs >= 60:
+		mins := int(secs) / 60
+		rem := int(secs) % 60
+		return fmt.Sprintf("%sm %ds", o.decimal(float64(mins), 0), rem)
+	case secs >= 10:
+		return o.decimal(secs, 0) + "s"
+	default:
+		return o.decimal(secs, 1) + "s"
+	}
+}
+
+// SystemLabel names what a system entry of the given kind reports.
+func SystemLabel(kind parser.SystemKind) string {
+	switch kind {
+	case parser.SystemStatus:
+		return "Status"
+	case parser.SystemOutputStyle:
+		return "Output style"
+	case parser.SystemQueue:
+		return "Queue"
+	}
+	return "System"
+}
+
+// ErrorLine describes one API error: "overloaded (529) · retry 2/10 in
+// 1.1s", or "... · failed" when no retry followed.
+func ErrorLine(e parser.ErrorMsg) string { return Options{}.ErrorLine(e) }
+
+// ErrorLine is the package-level ErrorLine, with numbers written by o.
+func (o Options) ErrorLine(e parser.ErrorMsg) string {
+	kind := strings.ReplaceAll(strings.TrimSuffix(e.Kind, "_error"), "_", " ")
+	desc := kind
+	if e.Message != "" && !strings.EqualFold(e.Message, kind) {
+		if desc != "" {
+			desc += ": "
+		}
+		desc += e.Message
+	}
+	if desc == "" {
+		desc = "request failed"
+	}
+	if e.Status != 0 {
+		desc += fmt.Sprintf(" (%d)", e.Status)
+	}
+	switch {
+	case e.Final:
+		desc += " · failed"
+	case e.RetryAttempt > 0 && e.MaxRetries > 0:
+		desc += fmt.Sprintf(" · retry %d/%d in %s", e.RetryAttempt, e.MaxRetries, o.Duration(e.RetryInMs))
+	case e.RetryAttempt > 0:
+		desc += fmt.Sprintf(" · retry %d in %s", e.RetryAttempt, o.Duration(e.RetryInMs))
+	}
+	return desc
+}
@@ -0,0 +1,66 @@
+package render_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+	"github.com/kylesnowschwartz/tail-claude/render"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		input int64
+		want  string
+	}{
+		{0, "0.0s"},
+		{3500, "3.5s"},
+		{9999, "10.0s"},
+		{15000, "15s"},
+		{60000, "1m 0s"},
+		{71000, "1m 11s"},
+	}
+	for _, tt := range tests {
+		got := render.Duration(tt.input)
+		if got != tt.want {
+			t.Errorf("Duration(%d) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestErrorLine(t *testing.T) {
+	tests := []struct {
+		name string
+		err  parser.ErrorMsg
+		want string
+	}{
+		{"overloaded retry", parser.ErrorMsg{Kind: "overloaded_error", Status: 529, Message: "Overloaded", RetryAttempt: 2, MaxRetries: 10, RetryInMs: 1100}, "overloaded (529) · retry 2/10 in 1.1s"},
+		{"rate limit failed", parser.ErrorMsg{Kind: "rate_limit_error", Status: 429, Message: "Too many requests", Final: true}, "rate limit: Too many requests (429) · failed"},
+		{"message only", parser.ErrorMsg{Message: "Connection error.", RetryAttempt: 1, RetryInMs: 500}, "Connection error. · retry 1 in 0.5s"},
+		{"nothing recorded", parser.ErrorMsg{Final: true}, "request failed · failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render.ErrorLine(tt.err); got != tt.want {
+				t.Errorf("ErrorLine = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptions_Decimal(t *testing.T) {
+	comma := render.Options{Decimal: func(f float64, prec int) string {
+		return strings.Replace(strconv.FormatFloat(f, 'f', prec, 64), ".", ",", 1)
+	}}
+	if got := comma.Duration(3500); got != "3,5s" {
+		t.Errorf("Duration = %q, want the comma decimal", got)
+	}
+	if got := render.Duration(3500); got != "3.5s" {
+		t.Errorf("package Duration = %q, want strconv's format", got)
+	}
+	e := parser.ErrorMsg{Kind: "overloaded_error", Status: 529, RetryAttempt: 1, RetryInMs: 1100}
+	if got := comma.ErrorLine(e); !strings.HasSuffix(got, "in 1,1s") {
+		t.Errorf("ErrorLine = %q, want the retry delay through Decimal", got)
+	}
+}
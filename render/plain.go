@@ -0,0 +1,271 @@
+// Package render turns parser chunks into text for tools that embed the
+// parser. PlainSession is the no-color form: no ANSI escapes, no terminal
+// width, one section per chunk.
+package render
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// PlainResultLines caps the lines of a tool result or subagent report shown
+// under its call; the rest is counted, not shown.
+const PlainResultLines = 20
+
+// indent prefixes item bodies under their label.
+const indent = "    "
+
+// PlainSession renders a session's chunks as plain text: a header line per
+// chunk ("You · 2025-01-15T10:00:00Z", "Claude · claude-opus-4-6 · ..."),
+// its text, and for Claude turns one entry per item. Chunks are separated
+// by a blank line. Todos the session's last TodoWrite left open close it.
+func PlainSession(chunks []parser.Chunk) string { return Options{}.PlainSession(chunks) }
+
+// PlainSession is the package-level PlainSession, with numbers written by o.
+func (o Options) PlainSession(chunks []parser.Chunk) string {
+	parts := make([]string, 0, len(chunks)+1)
+	for _, c := range chunks {
+		if s := o.PlainChunk(c); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if s := PlainTodos(parser.FinalTodos(chunks)); s != "" {
+		parts = append(parts, s)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// PlainChunk renders one chunk as PlainSession does, without a trailing
+// newline.
+func PlainChunk(c parser.Chunk) string { return Options{}.PlainChunk(c) }
+
+// PlainChunk is the package-level PlainChunk, with numbers written by o.
+func (o Options) PlainChunk(c parser.Chunk) string {
+	var b strings.Builder
+	b.WriteString(o.header(c))
+	switch c.Type {
+	case parser.UserChunk:
+		writeBody(&b, c.UserText)
+		for _, a := range c.Attachments {
+			b.WriteString("\n@" + a.Path)
+		}
+	case parser.AIChunk:
+		if len(c.Items) == 0 {
+			writeBody(&b, c.Text)
+		}
+		for _, it := range c.Items {
+			if s := o.PlainItem(it); s != "" {
+				b.WriteString("\n" + s)
+			}
+		}
+	case parser.ErrorChunk:
+		for _, e := range c.Errors {
+			b.WriteString("\n" + o.ErrorLine(e))
+		}
+	case parser.CompactChunk:
+		writeBody(&b, c.Output)
+		if c.PreTokens > 0 {
+			fmt.Fprintf(&b, "\nfrom %d to %d context tokens", c.PreTokens, c.PostTokens)
+		}
+	default:
+		writeBody(&b, c.Output)
+	}
+	return b.String()
+}
+
+// PlainItem renders one item of a Claude turn: thinking and output text,
+// a tool call as "Name: summary" with its result indented below, or a
+// subagent, teammate message, hook, or queued prompt under its label.
+func PlainItem(it parser.DisplayItem) string { return Options{}.PlainItem(it) }
+
+// PlainItem is the package-level PlainItem, with numbers written by o.
+func (o Options) PlainItem(it parser.DisplayItem) string {
+	switch it.Type {
+	case parser.ItemThinking:
+		return labeled("Thinking", it.Text)
+	case parser.ItemOutput:
+		return strings.TrimSpace(it.Text) + plainReferences(it.References)
+	case parser.ItemToolCall, parser.ItemSubagent:
+		name := it.ToolName
+		if it.Type == parser.ItemSubagent {
+			name = it.ToolName + " " + it.SubagentType
+			if it.SubagentDesc != "" && it.ToolSummary == "" {
+				it.ToolSummary = it.SubagentDesc
+			}
+		}
+		line := strings.TrimSpace(name) + ":"
+		if it.ToolSummary != "" {
+			line += " " + it.ToolSummary
+		}
+		if it.DurationMs > 0 {
+			line += " (" + o.Duration(it.DurationMs) + ")"
+		}
+		if it.ToolError {
+			line += " [error]"
+		}
+		for _, h := range it.Hooks {
+			line += "\n" + indent + hookLine(h)
+		}
+		if result := capLines(strings.TrimSpace(it.ToolResult), PlainResultLines); result != "" {
+			line += "\n" + indentLines(result)
+		}
+		return line
+	case parser.ItemTeammateMessage:
+		return labeled("Teammate "+it.TeammateID, it.Text)
+	case parser.ItemHook:
+		if len(it.Hooks) == 0 {
+			return ""
+		}
+		return hookLine(it.Hooks[0])
+	case parser.ItemQueuedMessage:
+		return "Queued: " + strings.Join(strings.Fields(it.Text), " ")
+	}
+	return ""
+}
+
+// plainReferences renders the sources an output cites under
+// "References:", one "[n] title <url>" line each, or "" when it cites none.
+func plainReferences(refs []parser.Reference) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	lines := []string{"\nReferences:"}
+	for i, r := range refs {
+		line := fmt.Sprintf("%s[%d] %s", indent, i+1, r.Label())
+		if r.URL != "" && r.URL != r.Label() {
+			line += " <" + r.URL + ">"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PlainTodos renders the unfinished todos of a TodoWrite list under
+// "Unfinished work:", one "[ ] content" line each, or "" when every todo
+// is done.
+func PlainTodos(todos []parser.Todo) string {
+	var lines []string
+	for _, t := range parser.UnfinishedTodos(todos) {
+		line := indent + "[ ] " + t.Content
+		if t.Status == "in_progress" {
+			line += " (in progress)"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "Unfinished work:\n" + strings.Join(lines, "\n")
+}
+
+// header names a chunk and when it happened, joined by " · ".
+func (o Options) header(c parser.Chunk) string {
+	var parts []string
+	switch c.Type {
+	case parser.UserChunk:
+		parts = append(parts, "You")
+		if c.PermissionMode != "" && c.PermissionMode != "default" {
+			parts = append(parts, c.PermissionMode)
+		}
+	case parser.AIChunk:
+		parts = append(parts, "Claude")
+		if c.Model != "" {
+			parts = append(parts, c.Model)
+		}
+	case parser.SystemChunk:
+		parts = append(parts, systemLabel(c))
+	case parser.CommandChunk:
+		parts = append(parts, strings.TrimSpace(c.Command+" "+c.CommandArgs))
+	case parser.ErrorChunk:
+		parts = append(parts, "API error")
+	case parser.CompactChunk:
+		label := "Compacted"
+		if c.CompactTrigger != "" {
+			label += " (" + c.CompactTrigger + ")"
+		}
+		parts = append(parts, label)
+	}
+	if !c.Timestamp.IsZero() {
+		parts = append(parts, c.Timestamp.Format(time.RFC3339))
+	}
+	if c.Type == parser.AIChunk {
+		if n := c.Usage.TotalTokens(); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d tokens", n))
+		}
+		if c.DurationMs > 0 {
+			parts = append(parts, o.Duration(c.DurationMs))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// systemLabel names what a system chunk reports, marking errors and
+// warnings the TUI shows with an icon.
+func systemLabel(c parser.Chunk) string {
+	label := SystemLabel(c.SystemKind)
+	if c.IsError {
+		label += " [error]"
+	} else if c.SystemLevel == "warning" {
+		label += " [warning]"
+	}
+	return label
+}
+
+// hookLine renders "Hook PreToolUse:Bash: output", marked when it failed.
+func hookLine(h parser.HookOutput) string {
+	line := "Hook " + h.Name()
+	if h.IsError {
+		line += " [error]"
+	}
+	if out := strings.Join(strings.Fields(h.Output), " "); out != "" {
+		line += ": " + out
+	}
+	return line
+}
+
+// labeled renders "Label:" with text indented below it.
+func labeled(label, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return label + ":"
+	}
+	return label + ":\n" + indentLines(text)
+}
+
+// writeBody appends text on the lines after a header.
+func writeBody(b *strings.Builder, text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		b.WriteString("\n" + text)
+	}
+}
+
+// indentLines indents every non-empty line of text.
+func indentLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// capLines keeps the first n lines of text, counting the rest.
+func capLines(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) <= n {
+		return text
+	}
+	more := len(lines) - n
+	noun := "lines"
+	if more == 1 {
+		noun = "line"
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n… %d more %s", more, noun)
+}
@@ -0,0 +1,91 @@
+package render_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+	"github.com/kylesnowschwartz/tail-claude/render"
+)
+
+func TestPlainSession(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	chunks := []parser.Chunk{
+		{Type: parser.UserChunk, Timestamp: t0, UserText: "List the files",
+			Attachments: []parser.Attachment{{ToolName: "Read", Path: "main.go"}}},
+		{Type: parser.AIChunk, Timestamp: t0.Add(time.Second), Model: "claude-opus-4-6", DurationMs: 3400,
+			Usage: parser.Usage{InputTokens: 1000, OutputTokens: 200},
+			Items: []parser.DisplayItem{
+				{Type: parser.ItemThinking, Text: "Check the directory."},
+				{Type: parser.ItemToolCall, ToolName: "Bash", ToolSummary: "ls", DurationMs: 1500,
+					ToolResult: "a.go\nb.go", Hooks: []parser.HookOutput{{Event: "PreToolUse", Matcher: "Bash", Output: "ok"}}},
+				{Type: parser.ItemToolCall, ToolName: "Read", ToolSummary: "c.go", ToolError: true, ToolResult: "no such file"},
+				{Type: parser.ItemToolCall, ToolName: "TodoWrite", ToolSummary: "2 items",
+					ToolInput: json.RawMessage(`{"todos":[{"content":"List files","status":"completed"},{"content":"Count them","status":"in_progress"}]}`)},
+				{Type: parser.ItemQueuedMessage, Text: "also\n count them"},
+				{Type: parser.ItemOutput, Text: "Two files.\n"},
+			}},
+		{Type: parser.SystemChunk, Timestamp: t0.Add(5 * time.Second), SystemKind: parser.SystemStatus,
+			SystemLevel: "warning", Output: "Context low"},
+		{Type: parser.ErrorChunk, Timestamp: t0.Add(6 * time.Second), Errors: []parser.ErrorMsg{
+			{Kind: "overloaded_error", Status: 529, Message: "Overloaded", RetryAttempt: 1, MaxRetries: 10, RetryInMs: 1100}}},
+	}
+
+	want := `You · 2025-01-15T10:00:00Z
+List the files
+@main.go
+
+Claude · claude-opus-4-6 · 2025-01-15T10:00:01Z · 1200 tokens · 3.4s
+Thinking:
+    Check the directory.
+Bash: ls (1.5s)
+    Hook PreToolUse:Bash: ok
+    a.go
+    b.go
+Read: c.go [error]
+    no such file
+TodoWrite: 2 items
+Queued: also count them
+Two files.
+
+Status [warning] · 2025-01-15T10:00:05Z
+Context low
+
+API error · 2025-01-15T10:00:06Z
+overloaded (529) · retry 1/10 in 1.1s
+
+Unfinished work:
+    [ ] Count them (in progress)
+`
+	if got := render.PlainSession(chunks); got != want {
+		t.Errorf("PlainSession =\n%s\nwant\n%s", got, want)
+	}
+	if got := render.PlainSession(nil); got != "" {
+		t.Errorf("PlainSession(nil) = %q, want empty", got)
+	}
+}
+
+func TestPlainItem_CapsLongResults(t *testing.T) {
+	lines := make([]string, render.PlainResultLines+3)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	got := render.PlainItem(parser.DisplayItem{Type: parser.ItemToolCall, ToolName: "Grep", ToolResult: strings.Join(lines, "\n")})
+	if !strings.HasSuffix(got, "    line 20\n    … 3 more lines") {
+		t.Errorf("PlainItem =\n%s\nwant the first %d lines and a count", got, render.PlainResultLines)
+	}
+}
+
+func TestPlainItem_References(t *testing.T) {
+	got := render.PlainItem(parser.DisplayItem{Type: parser.ItemOutput, Text: "Loops got new semantics.", References: []parser.Reference{
+		{Title: "Go 1.22 Release Notes", URL: "https://go.dev/doc/go1.22"},
+		{Title: "spec.md"},
+	}})
+	want := "Loops got new semantics.\nReferences:\n    [1] Go 1.22 Release Notes <https://go.dev/doc/go1.22>\n    [2] spec.md"
+	if got != want {
+		t.Errorf("PlainItem =\n%s\nwant\n%s", got, want)
+	}
+}
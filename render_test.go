@@ -3,6 +3,10 @@ package main
 import (
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
 
 	"charm.land/lipgloss/v2"
 )
@@ -277,3 +281,296 @@ func TestDetailHeaderMeta(t *testing.T) {
 		}
 	})
 }
+
+func TestSettingsLine(t *testing.T) {
+	t.Run("nothing recorded", func(t *testing.T) {
+		if got := settingsLine(claudeMsg()); got != "" {
+			t.Errorf("settingsLine = %q, want empty", got)
+		}
+	})
+
+	t.Run("first turn", func(t *testing.T) {
+		msg := claudeMsg(func(m *message) {
+			m.settings = parser.RequestSettings{ThinkingLevel: "high", ThinkingBudget: 31999, ServiceTier: "standard", Version: "2.0.14"}
+		})
+		got := settingsLine(msg)
+		for _, want := range []string{"thinking high", "budget 32.0k", "tier standard", "version 2.0.14"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("settingsLine = %q, missing %q", got, want)
+			}
+		}
+		if strings.Contains(got, "was") {
+			t.Errorf("first turn shows a change: %q", got)
+		}
+	})
+
+	t.Run("changed since the previous turn", func(t *testing.T) {
+		msgs := []message{
+			claudeMsg(func(m *message) { m.settings = parser.RequestSettings{ThinkingLevel: "high", Version: "2.0.14"} }),
+			userMsg("quick one"),
+			claudeMsg(func(m *message) {
+				m.settings = parser.RequestSettings{ThinkingDisabled: true, ServiceTier: "priority", Version: "2.0.14"}
+			}),
+		}
+		linkSettings(msgs)
+		got := settingsLine(msgs[2])
+		if !strings.Contains(got, "thinking off (was high)") {
+			t.Errorf("settingsLine = %q, want thinking change", got)
+		}
+		if strings.Contains(got, "priority (was") || strings.Contains(got, "2.0.14 (was") {
+			t.Errorf("unchanged or newly recorded setting marked as changed: %q", got)
+		}
+	})
+}
+
+func TestRenderInfoBar_Layout(t *testing.T) {
+	m := testModel()
+	m.width = 120
+	m.sessionCwd = "/Users/kyle/Code/tail-claude"
+	m.liveBranch = "main"
+	m.sessionMode = "acceptEdits"
+
+	if h := m.infoBarHeight(); h != 3 {
+		t.Fatalf("default layout infoBarHeight = %d, want 3 (mode chip)", h)
+	}
+
+	m.cfg.InfoBar = &infoBarLayout{Left: []string{"branch", "project"}, Right: []string{}}
+	if h := m.infoBarHeight(); h != 1 {
+		t.Errorf("layout without mode infoBarHeight = %d, want 1", h)
+	}
+	bar := m.renderInfoBar()
+	if strings.Contains(bar, "auto-edit") {
+		t.Errorf("bar %q shows the mode although the layout omits it", bar)
+	}
+	branch, proj := strings.Index(bar, "main"), strings.Index(bar, "tail-claude")
+	if branch < 0 || proj < 0 || branch > proj {
+		t.Errorf("bar %q: want branch before project", bar)
+	}
+}
+
+func TestClaudeCollapsedContent_Limits(t *testing.T) {
+	body := strings.Repeat("line\n", 29) + "line"
+	msg := claudeMsg(func(m *message) { m.content = body })
+
+	m := testModel()
+	if _, hidden := m.claudeCollapsedContent(msg, false); hidden != 30-defaultCollapsedLines {
+		t.Errorf("default hidden = %d, want %d", hidden, 30-defaultCollapsedLines)
+	}
+
+	m.cfg.Collapse.List.Lines = 25
+	if _, hidden := m.claudeCollapsedContent(msg, false); hidden != 5 {
+		t.Errorf("hidden with 25-line limit = %d, want 5", hidden)
+	}
+
+	m.cfg.Collapse.List.Preview = 10
+	msg.lastOutput = &parser.LastOutput{Type: parser.LastOutputToolResult, ToolName: "Bash", ToolResult: strings.Repeat("x", 50)}
+	if got, _ := m.claudeCollapsedContent(msg, false); strings.Contains(got, strings.Repeat("x", 11)) {
+		t.Errorf("preview %q exceeds the 10-character limit", got)
+	}
+}
+
+func TestItemColumns(t *testing.T) {
+	m := testModel()
+	items := []displayItem{
+		{itemType: parser.ItemToolCall, toolName: "Read", toolSummary: "a.go", durationMs: 1200},
+		{itemType: parser.ItemToolCall, toolName: "mcp__github__create_pull_request", toolSummary: "Add columns", tokenCount: 1_234_567},
+	}
+	cols := m.itemColumns(items)
+	if cols.name != 32 || cols.tok != len("~1.2M tok") || cols.dur != 5 {
+		t.Errorf("columns = %+v, want the name widened to the longest", cols)
+	}
+	read := plain(m.renderDetailItemRow(items[0], 0, -1, false, false, cols, 120))
+	mcp := plain(m.renderDetailItemRow(items[1], 1, -1, false, false, cols, 120))
+	if !strings.Contains(mcp, "mcp__github__create_pull_request") {
+		t.Errorf("long name cut: %q", mcp)
+	}
+	if strings.Index(read, "- a.go") != strings.Index(mcp, "- Add columns") {
+		t.Errorf("summaries not aligned:\n%s\n%s", read, mcp)
+	}
+
+	// The name column stops at maxName; longer names are cut.
+	m.cfg.Columns = columnsConfig{Name: 8, MaxName: 16}
+	cols = m.itemColumns(items)
+	if cols.name != 16 {
+		t.Errorf("name column = %d, want maxName 16", cols.name)
+	}
+	if row := plain(m.renderDetailItemRow(items[1], 1, -1, false, false, cols, 120)); !strings.Contains(row, "mcp__github__cr… ") {
+		t.Errorf("row %q should cut the name at 16 cells", row)
+	}
+}
+
+func TestRenderDetailItemRow_SubagentStep(t *testing.T) {
+	m := testModel()
+	item := displayItem{
+		itemType:        parser.ItemSubagent,
+		subagentType:    "Explore",
+		subagentDesc:    "Map the parser",
+		subagentOngoing: true,
+		subagentStep:    "Reading parser/chunk.go…",
+	}
+	row := m.renderDetailItemRow(item, 0, 0, false, false, m.itemColumns(nil), 160)
+	if !strings.Contains(row, "Reading parser/chunk.go") {
+		t.Errorf("ongoing row %q should show the agent's current step", row)
+	}
+
+	item.subagentOngoing = false
+	row = m.renderDetailItemRow(item, 0, 0, false, false, m.itemColumns(nil), 160)
+	if strings.Contains(row, "Reading") {
+		t.Errorf("finished row %q should not show a step", row)
+	}
+}
+
+func TestEndStateBadge(t *testing.T) {
+	item := displayItem{
+		itemType:        parser.ItemSubagent,
+		subagentProcess: &parser.SubagentProcess{EndState: parser.EndContextLimit},
+	}
+	if got := endStateBadge(item); !strings.Contains(got, "context limit") {
+		t.Errorf("badge = %q, want context limit", got)
+	}
+	item.subagentOngoing = true
+	if got := endStateBadge(item); got != "" {
+		t.Errorf("ongoing agent badge = %q, want none", got)
+	}
+	item.subagentOngoing = false
+	item.subagentProcess.EndState = parser.EndCompleted
+	if got := endStateBadge(item); got != "" {
+		t.Errorf("completed agent badge = %q, want none", got)
+	}
+}
+
+func TestCompactLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  message
+		want string
+	}{
+		{"no metrics", message{content: "Conversation compacted"}, "Conversation compacted"},
+		{"empty title", message{}, "Context compressed"},
+		{
+			"before and after",
+			message{content: "Conversation compacted", compactTrigger: "auto", compactPre: 62000, compactPost: 8000},
+			"Conversation compacted (auto) · 62.0k → 8.0k tokens",
+		},
+		{
+			"before only",
+			message{content: "Conversation compacted", compactTrigger: "manual", compactPre: 62000},
+			"Conversation compacted (manual) · from 62.0k tokens",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compactLabel(tt.msg); got != tt.want {
+				t.Errorf("compactLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueuedLines(t *testing.T) {
+	msg := message{role: RoleClaude, items: []displayItem{
+		{itemType: parser.ItemOutput, text: "working"},
+		{itemType: parser.ItemQueuedMessage, text: "also\nupdate the README"},
+	}}
+	lines := queuedLines(msg, 80)
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1", len(lines))
+	}
+	if !strings.Contains(lines[0], "queued") || !strings.Contains(lines[0], "also update the README") {
+		t.Errorf("line = %q", lines[0])
+	}
+}
+
+func TestDetailItemSummary_SkillArgs(t *testing.T) {
+	m := testModel()
+	item := displayItem{itemType: parser.ItemToolCall, toolName: "Skill",
+		toolInput: `{"skill": "pdf", "args": "fill the form"}`, toolSummary: "pdf: fill the form"}
+	if got := m.detailItemSummary(item); got != "fill the form" {
+		t.Errorf("summary = %q, want the arguments only", got)
+	}
+	if row := m.renderDetailItemRow(item, 0, -1, false, false, m.itemColumns(nil), 100); !strings.Contains(row, "pdf") {
+		t.Errorf("row %q should badge the skill", row)
+	}
+}
+
+func TestRenderDenseMessage(t *testing.T) {
+	msg := claudeMsg(func(m *message) {
+		m.timestamp = "3:04:05 PM"
+		m.content = "Done.\nMore detail below."
+		m.summary = parser.TurnSummary{Text: "Fixed the parser", Activity: "edited 2 files"}
+		m.tokensRaw = 12300
+		m.durationMs = 4100
+	})
+	line := renderDenseMessage(msg, 100, false)
+	if strings.Contains(line, "\n") {
+		t.Fatalf("dense row spans lines: %q", line)
+	}
+	for _, want := range []string{"3:04:05 PM", "Fixed the parser \u00B7 edited 2 files", "12.3k", "4.1s"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("row %q missing %q", line, want)
+		}
+	}
+	if w := lipgloss.Width(line); w != 100 {
+		t.Errorf("row width = %d, want 100", w)
+	}
+
+	if _, text := denseLabel(userMsg("first line\nsecond line")); text != "first line" {
+		t.Errorf("user label = %q, want the first line", text)
+	}
+}
+
+func TestBackgroundLifecycle(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	bg := &parser.BackgroundTask{ID: "bash_1", Status: parser.BackgroundRunning, Started: t0}
+	item := displayItem{itemType: parser.ItemToolCall, toolName: "Bash", background: bg}
+
+	if got := backgroundBadge(item); !strings.Contains(got, "bg running") {
+		t.Errorf("badge = %q, want bg running", got)
+	}
+	if got := backgroundElapsedMs(bg, true, t0.Add(30*time.Second)); got != 30000 {
+		t.Errorf("watched elapsed = %d, want 30000", got)
+	}
+	if got := backgroundElapsedMs(bg, false, t0.Add(30*time.Second)); got != 0 {
+		t.Errorf("unwatched elapsed = %d, want 0", got)
+	}
+	if got := backgroundElapsedMs(bg, true, t0.Add(staleSessionThreshold+time.Minute)); got != 0 {
+		t.Errorf("stale elapsed = %d, want 0", got)
+	}
+
+	bg.Status = "completed"
+	bg.Ended = t0.Add(90 * time.Second)
+	bg.Summary = `Background command "Run tests" completed (exit code 0)`
+	if got := backgroundBadge(item); !strings.Contains(got, "bg completed") {
+		t.Errorf("badge = %q, want bg completed", got)
+	}
+	got := renderBackgroundLifecycle(bg, true, t0.Add(time.Hour), "")
+	for _, want := range []string{"started", "task bash_1", "completed", localRender.Duration(90000), "Run tests"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("lifecycle missing %q:\n%s", want, got)
+		}
+	}
+	if backgroundBadge(displayItem{itemType: parser.ItemToolCall, toolName: "Bash"}) != "" {
+		t.Error("foreground Bash call should have no badge")
+	}
+}
+
+func TestFormatToolResultPreview_WideCharacters(t *testing.T) {
+	lo := &parser.LastOutput{ToolName: "Bash", ToolResult: strings.Repeat("日本語の出力\n", 100)}
+	got := formatToolResultPreview(lo, 20)
+	if !utf8.ValidString(got) {
+		t.Fatalf("preview %q is not valid UTF-8", got)
+	}
+	// Icon, space, "Bash", space, then at most 20 cells of result.
+	if w := lipgloss.Width(got); w > lipgloss.Width(Icon.Tool.Ok.Glyph)+6+20 {
+		t.Errorf("preview is %d cells wide: %q", w, got)
+	}
+}
+
+func TestRenderDebugEntry_WideCharacters(t *testing.T) {
+	m := testModel()
+	entry := parser.DebugEntry{Level: parser.LevelDebug, Message: strings.Repeat("漢字", 200)}
+	row := m.renderDebugEntry(entry, 0, false, 80)
+	if w := lipgloss.Width(row); w > 80 {
+		t.Errorf("debug row is %d cells wide, want at most 80", w)
+	}
+}
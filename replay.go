@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// replayOptions sets the pace of a replay.
+type replayOptions struct {
+	delay  time.Duration // pause before each line when speed is 0
+	speed  float64       // replay the recorded gaps between entry timestamps this many times faster; 0 uses delay
+	maxGap time.Duration // longest pause, so idle stretches in the recording don't stall the replay; 0 is no cap
+	split  bool          // write each line in two parts, the first without its newline, as a writer flushing mid-line does
+}
+
+// replayGap returns the pause before a line recorded at ts, given the
+// timestamp of the line before it (zero for none).
+func (o replayOptions) replayGap(prev, ts time.Time) time.Duration {
+	gap := o.delay
+	if o.speed > 0 {
+		gap = 0
+		if !prev.IsZero() && ts.After(prev) {
+			gap = time.Duration(float64(ts.Sub(prev)) / o.speed)
+		}
+	}
+	if o.maxGap > 0 {
+		gap = min(gap, o.maxGap)
+	}
+	return gap
+}
+
+// readReplayLines reads the non-empty lines of a session file.
+func readReplayLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		if line := sc.Text(); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, sc.Err()
+}
+
+// lineTimestamp returns the timestamp recorded on a session line, or the
+// zero time.
+func lineTimestamp(line string) time.Time {
+	var e struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	_ = json.Unmarshal([]byte(line), &e)
+	return e.Timestamp
+}
+
+// replayLines appends lines to dst at the pace opts sets, as Claude Code
+// writes a live transcript, and returns once all are written or ctx is
+// done. Each write is flushed to disk before the next pause, so watchers see
+// the file grow the way they would in use.
+func replayLines(ctx context.Context, lines []string, dst string, opts replayOptions) error {
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var prev time.Time
+	for _, line := range lines {
+		ts := lineTimestamp(line)
+		if err := replayPause(ctx, opts.replayGap(prev, ts)); err != nil {
+			return err
+		}
+		if !ts.IsZero() {
+			prev = ts
+		}
+		if opts.split && len(line) > 1 {
+			half := len(line) / 2
+			if _, err := f.WriteString(line[:half]); err != nil {
+				return err
+			}
+			if err := replayPause(ctx, max(opts.delay/2, time.Millisecond)); err != nil {
+				return err
+			}
+			line = line[half:]
+		}
+		if _, err := f.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayPause waits d, or until ctx is done.
+func replayPause(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// replaySeed returns how many leading lines of a recording make a session
+// tail-claude can open: everything up to and including the first prompt.
+func replaySeed(lines []string) int {
+	for i, line := range lines {
+		var e struct {
+			Type   string `json:"type"`
+			IsMeta bool   `json:"isMeta"`
+		}
+		if json.Unmarshal([]byte(line), &e) == nil && e.Type == "user" && !e.IsMeta {
+			return i + 1
+		}
+	}
+	return len(lines)
+}
+
+// startReplay implements `tail-claude replay`: it copies the opening
+// prompt of a recorded session into a temp file, keeps appending the rest
+// in the background at the requested pace, and returns the arguments to
+// open the TUI on the growing file, so live tailing can be watched and
+// debugged without a running Claude Code. Flags after the recording pass
+// through to the TUI. code is nonzero when the command should exit.
+func startReplay(args []string) (tuiArgs []string, code int) {
+	usage := "usage: tail-claude replay [--delay D | --speed X] [--max-gap D] [--split] <session.jsonl> [flags]"
+	opts := replayOptions{delay: 200 * time.Millisecond, maxGap: 5 * time.Second}
+	src := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func() (string, bool) {
+			i++
+			if i >= len(args) {
+				fmt.Fprintf(os.Stderr, "%s requires a value\n", arg)
+				return "", false
+			}
+			return args[i], true
+		}
+		switch {
+		case src != "":
+			tuiArgs = append(tuiArgs, arg)
+		case arg == "--delay" || arg == "--max-gap":
+			v, ok := value()
+			if !ok {
+				return nil, 2
+			}
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				fmt.Fprintf(os.Stderr, "%s: invalid duration %q\n", arg, v)
+				return nil, 2
+			}
+			if arg == "--delay" {
+				opts.delay = d
+			} else {
+				opts.maxGap = d
+			}
+		case arg == "--speed":
+			v, ok := value()
+			if !ok {
+				return nil, 2
+			}
+			x, err := strconv.ParseFloat(v, 64)
+			if err != nil || x <= 0 {
+				fmt.Fprintf(os.Stderr, "--speed: want a positive number, got %q\n", v)
+				return nil, 2
+			}
+			opts.speed = x
+		case arg == "--split":
+			opts.split = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintln(os.Stderr, usage)
+			return nil, 2
+		default:
+			src = arg
+		}
+	}
+	if src == "" {
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, 2
+	}
+
+	lines, err := readReplayLines(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return nil, 2
+	}
+	dir, err := os.MkdirTemp("", "tail-claude-replay-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return nil, 2
+	}
+	dst := filepath.Join(dir, filepath.Base(src))
+	seed := replaySeed(lines)
+	if err := os.WriteFile(dst, []byte(strings.Join(lines[:seed], "\n")+"\n"), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return nil, 2
+	}
+	fmt.Fprintf(os.Stderr, "replaying %d lines of %s into %s\n", len(lines)-seed, src, dst)
+	go func() {
+		_ = replayLines(context.Background(), lines[seed:], dst, opts)
+	}()
+	return append(tuiArgs, dst), 0
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replayHarness drives the real watcher and model over a session file that
+// a replay is still writing, as a running Claude Code would.
+type replayHarness struct {
+	t    *testing.T
+	m    model
+	done chan error // the replay's result, once every line is written
+}
+
+// newReplayHarness seeds a temp file with the opening prompt of the
+// recording at fixture, opens it as the picker would, and starts replaying
+// the rest at the pace opts sets. The watcher and replay stop with the test.
+func newReplayHarness(t *testing.T, fixture string, opts replayOptions) *replayHarness {
+	t.Helper()
+	lines, err := readReplayLines(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), filepath.Base(fixture))
+	seed := replaySeed(lines)
+	if err := os.WriteFile(dst, []byte(strings.Join(lines[:seed], "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loadSession(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := testModel()
+	m.pollBase = minPollInterval
+	m, _ = m.switchSession(result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &replayHarness{t: t, m: m, done: make(chan error, 1)}
+	go func() { h.done <- replayLines(ctx, lines[seed:], dst, opts) }()
+	t.Cleanup(func() {
+		cancel()
+		h.m.watcher.stop()
+	})
+	return h
+}
+
+// waitFor feeds the watcher's updates through Update until the replay has
+// finished and cond holds, failing the test after timeout.
+func (h *replayHarness) waitFor(cond func(model) bool, timeout time.Duration) {
+	h.t.Helper()
+	deadline := time.After(timeout)
+	finished := false
+	for !finished || !cond(h.m) {
+		select {
+		case err := <-h.done:
+			if err != nil {
+				h.t.Fatalf("replay: %v", err)
+			}
+			finished = true
+		case msg, ok := <-h.m.tailSub:
+			if !ok {
+				h.t.Fatal("watcher stopped")
+			}
+			msg.source = h.m.tailSub
+			result, _ := h.m.Update(msg)
+			h.m = asModel(result)
+			_ = h.m.viewList() // render each update, as the TUI would
+		case err := <-h.m.tailErrc:
+			h.t.Fatalf("watcher: %v", err)
+		case <-deadline:
+			h.t.Fatalf("timed out after %s: replay finished %v, %d messages", timeout, finished, len(h.m.messages))
+		}
+	}
+}
+
+// sameMessages reports whether a tailed session shows what a full load of
+// the finished file does.
+func sameMessages(got, want []message) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range want {
+		if got[i].role != want[i].role || got[i].content != want[i].content || got[i].toolCallCount != want[i].toolCallCount {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReplay_LiveTailMatchesFullLoad(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		fixture string
+		opts    replayOptions
+	}{
+		{"line by line", "parser/testdata/multi_turn.jsonl", replayOptions{delay: 5 * time.Millisecond}},
+		{"lines split mid-write", "parser/testdata/multi_turn.jsonl", replayOptions{delay: 300 * time.Millisecond, split: true}},
+		{"in one burst", "parser/testdata/compacted.jsonl", replayOptions{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want, err := loadSession(tc.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := newReplayHarness(t, tc.fixture, tc.opts)
+			if len(h.m.messages) >= len(want.messages) {
+				t.Fatalf("seeded with %d of %d messages; nothing left to tail", len(h.m.messages), len(want.messages))
+			}
+			h.waitFor(func(m model) bool { return sameMessages(m.messages, want.messages) }, 10*time.Second)
+
+			last := want.messages[len(want.messages)-1]
+			if line, _, _ := strings.Cut(last.content, "\n"); line != "" && !strings.Contains(h.m.viewList(), line[:min(len(line), 20)]) {
+				t.Errorf("list view doesn't show the last message %q", line)
+			}
+		})
+	}
+}
+
+func TestReplayOptions_Gap(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name      string
+		opts      replayOptions
+		prev, now time.Time
+		want      time.Duration
+	}{
+		{"fixed delay", replayOptions{delay: 200 * time.Millisecond}, t0, t0.Add(time.Minute), 200 * time.Millisecond},
+		{"recorded pace sped up", replayOptions{speed: 10}, t0, t0.Add(5 * time.Second), 500 * time.Millisecond},
+		{"long idle capped", replayOptions{speed: 10, maxGap: time.Second}, t0, t0.Add(time.Hour), time.Second},
+		{"first line", replayOptions{speed: 10}, time.Time{}, t0, 0},
+		{"clock going back", replayOptions{speed: 10}, t0, t0.Add(-time.Second), 0},
+	} {
+		if got := tc.opts.replayGap(tc.prev, tc.now); got != tc.want {
+			t.Errorf("%s: gap = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestReplaySeed(t *testing.T) {
+	lines := []string{
+		`{"type":"file-history-snapshot"}`,
+		`{"type":"user","isMeta":true,"message":{"role":"user","content":"caveat"}}`,
+		`{"type":"user","message":{"role":"user","content":"hello"}}`,
+		`{"type":"assistant"}`,
+	}
+	if got := replaySeed(lines); got != 3 {
+		t.Errorf("replaySeed = %d, want 3 (through the first prompt)", got)
+	}
+}
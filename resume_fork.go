@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// forkScanInterval is how often the session's directory is checked for a
+// file that carries the session on: resuming it in another terminal leaves
+// this file behind, and the tail would go quiet without saying why.
+const forkScanInterval = 5 * time.Second
+
+// forkTickMsg schedules the next check.
+type forkTickMsg struct{}
+
+// forkScanMsg reports a check of session's directory: the continuation
+// found, if any, and the files it ruled out for good.
+type forkScanMsg struct {
+	session  string
+	found    *parser.Continuation
+	ruledOut []string
+}
+
+// forkTickCmd schedules the next check.
+func forkTickCmd() tea.Cmd {
+	return tea.Tick(forkScanInterval, func(time.Time) tea.Msg {
+		return forkTickMsg{}
+	})
+}
+
+// forkScanCmd looks off the UI goroutine for a session file beside session
+// written since since that carries it on. Files in checked aren't looked
+// at again. ends is session's, brought up to date first; only one scan
+// runs at a time, so it's never updated twice at once.
+func forkScanCmd(session string, ends *parser.EntryEnds, since time.Time, checked map[string]bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := forkScanMsg{session: session}
+		entries, err := os.ReadDir(filepath.Dir(session))
+		if err != nil {
+			return msg
+		}
+		if err := ends.Update(); err != nil {
+			return msg
+		}
+		for _, e := range entries {
+			path := filepath.Join(filepath.Dir(session), e.Name())
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") || path == session || checked[path] {
+				continue
+			}
+			if info, err := e.Info(); err != nil || !info.ModTime().After(since) {
+				continue
+			}
+			c, err := ends.Continuation(path)
+			switch {
+			case err != nil:
+			case c.Continues():
+				msg.found = &c
+				return msg
+			case c.New > 0:
+				// A session of its own; one that has only copied so far
+				// is looked at again.
+				msg.ruledOut = append(msg.ruledOut, path)
+			}
+		}
+		return msg
+	}
+}
+
+// startForkScan checks the current session's directory, restarting the
+// bookkeeping when the session changed since the last check. Only local
+// sessions are checked.
+func (m *model) startForkScan() tea.Cmd {
+	if !m.watching || m.watcher == nil || m.fork != nil || m.forkScanning {
+		return nil
+	}
+	if _, local := parser.LocalPath(m.watcher.src); !local && m.mergedFrom == "" {
+		return nil
+	}
+	if m.forkSession != m.sessionPath {
+		m.forkSession = m.sessionPath
+		m.forkSince = time.Now().Add(-forkScanInterval)
+		m.forkChecked = make(map[string]bool)
+		m.forkEnds = parser.NewEntryEnds(m.sessionPath)
+		if m.mergedFrom != "" {
+			m.forkChecked[m.mergedFrom] = true
+		}
+	}
+	m.forkScanning = true
+	return forkScanCmd(m.sessionPath, m.forkEnds, m.forkSince, maps.Clone(m.forkChecked))
+}
+
+// applyForkScan records a check of the current session.
+func (m *model) applyForkScan(msg forkScanMsg) tea.Cmd {
+	m.forkScanning = false
+	if msg.session != m.sessionPath || msg.session != m.forkSession {
+		return nil
+	}
+	for _, path := range msg.ruledOut {
+		m.forkChecked[path] = true
+	}
+	if msg.found == nil || m.fork != nil {
+		return nil
+	}
+	m.fork = msg.found
+	m.flashStatus = fmt.Sprintf("This session was resumed in another terminal as %s: R to follow it", filepath.Base(msg.found.Path))
+	return flashClearCmd()
+}
+
+// openFork asks how to follow the continuation found (R).
+func (m *model) openFork() tea.Cmd {
+	if m.fork == nil {
+		m.flashStatus = "No other terminal has resumed this session"
+		return flashClearCmd()
+	}
+	m.forkConfirm = true
+	return nil
+}
+
+// updateForkConfirm answers the question R asks: s or enter switch to the
+// continuation, m merges the two, d stops offering it, and any other key
+// leaves it for later.
+func (m model) updateForkConfirm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	m.forkConfirm = false
+	c := *m.fork
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "s", "enter":
+		m.fork = nil
+		cmd := m.openSession(c.Path)
+		return m, cmd
+	case "m":
+		m.fork = nil
+		return m, loadMergedCmd(m.sessionPath, c)
+	case "d":
+		m.fork = nil
+		m.forkChecked[c.Path] = true
+	}
+	return m, nil
+}
+
+// loadMergedCmd loads original and its continuation c as one session.
+func loadMergedCmd(original string, c parser.Continuation) tea.Cmd {
+	return func() tea.Msg {
+		result, err := loadSource(parser.NewMergedSource(original, c), 0)
+		if err != nil {
+			return loadSessionMsg{err: err}
+		}
+		return loadSessionMsg{loadResult: result}
+	}
+}
+
+// renderForkConfirm renders R's question in the info bar.
+func (m model) renderForkConfirm() string {
+	question := fmt.Sprintf("%s carries this session on. s/enter switch to it · m merged view · d don't offer again · other key later",
+		filepath.Base(m.fork.Path))
+	return " " + StyleErrorBold.Render(question)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestResumedSessionFound(t *testing.T) {
+	path := writeTurns(t, 2)
+	history, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := filepath.Join(filepath.Dir(path), "resumed.jsonl")
+	own := `{"uuid":"u9","parentUuid":"a1","type":"user","timestamp":"2025-01-15T11:00:00.000Z","message":{"role":"user","content":"carry on"}}` + "\n"
+	if err := os.WriteFile(resumed, append(history, own...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(filepath.Dir(path), "other.jsonl")
+	if err := os.WriteFile(other, []byte(`{"uuid":"x1","type":"user","message":{"role":"user","content":"unrelated"}}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := testModel()
+	m.sessionPath = path
+	m.watcher = newSessionWatcher(parser.FileSource(path), nil, 0)
+	m.watching = true
+	cmd := m.startForkScan()
+	if cmd == nil || !m.forkScanning {
+		t.Fatal("startForkScan should start a check")
+	}
+	msg := cmd().(forkScanMsg)
+	if msg.found == nil || msg.found.Path != resumed {
+		t.Fatalf("scan found %+v, want %s", msg.found, resumed)
+	}
+	result, _ := m.Update(msg)
+	m = asModel(result)
+	if m.fork == nil || !m.forkChecked[other] || m.forkScanning {
+		t.Fatalf("after the scan: fork %v, checked %v, scanning %v", m.fork, m.forkChecked, m.forkScanning)
+	}
+	m.flashStatus = ""
+	if got := plain(m.renderInfoBar()); !strings.Contains(got, "resumed in another terminal (R)") {
+		t.Errorf("info bar = %q, want the notice", got)
+	}
+
+	// R asks; m loads the two files as one session.
+	result, _ = m.Update(key("R"))
+	m = asModel(result)
+	if !m.forkConfirm || !strings.Contains(plain(m.renderInfoBar()), "resumed.jsonl carries this session on") {
+		t.Fatalf("R: confirm %v, info bar %q", m.forkConfirm, plain(m.renderInfoBar()))
+	}
+	result, cmd = m.Update(key("m"))
+	m = asModel(result)
+	load, ok := cmd().(loadSessionMsg)
+	if !ok || load.err != nil || len(load.classified) != 5 {
+		t.Fatalf("merged load = %+v, want 5 messages", load)
+	}
+	result, _ = m.Update(load)
+	m = asModel(result)
+	defer m.watcher.stop()
+	if m.sessionPath != resumed || m.mergedFrom != path || m.fork != nil {
+		t.Errorf("merged view: session %s, merged from %q, fork %v", m.sessionPath, m.mergedFrom, m.fork)
+	}
+	if got := plain(m.renderInfoBar()); !strings.Contains(got, "merged with session.jsonl") {
+		t.Errorf("info bar = %q, want the merge noted", got)
+	}
+}
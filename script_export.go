@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// reExitCode matches the status line Claude Code puts first in a failed Bash
+// result: "Exit code 2".
+var reExitCode = regexp.MustCompile(`^Exit code (\d+)`)
+
+// scriptCommand is one Bash call in a script export.
+type scriptCommand struct {
+	agent       string // "main" or the subagent label
+	time        string // formatTime of the call; empty when not recorded
+	command     string
+	description string
+	background  bool
+	status      string // "exit 0", "exit 2", "failed", "rejected", "no result"
+	ran         bool   // false for calls rejected at the prompt
+}
+
+// bashInput is the part of a Bash tool input a script export uses.
+type bashInput struct {
+	Command         string `json:"command"`
+	Description     string `json:"description"`
+	RunInBackground bool   `json:"run_in_background"`
+}
+
+// buildScript lists every Bash call in the session, including those made by
+// subagents (nested ones too), in transcript order.
+func buildScript(msgs []message) []scriptCommand {
+	var cmds []scriptCommand
+	var visit func(items []displayItem, agent string)
+	visit = func(items []displayItem, agent string) {
+		for _, item := range items {
+			if item.itemType == parser.ItemToolCall && item.toolName == "Bash" {
+				var in bashInput
+				if json.Unmarshal([]byte(item.toolInput), &in) == nil && strings.TrimSpace(in.Command) != "" {
+					status, ran := bashStatus(item)
+					cmds = append(cmds, scriptCommand{
+						agent:       agent,
+						time:        formatTime(item.timestamp),
+						command:     in.Command,
+						description: in.Description,
+						background:  in.RunInBackground,
+						status:      status,
+						ran:         ran,
+					})
+				}
+			}
+			if item.subagentProcess != nil {
+				visit(buildTraceItems(item), agentLabel(item.subagentProcess))
+			}
+		}
+	}
+	for _, msg := range msgs {
+		visit(msg.items, mainAgentLabel)
+	}
+	return cmds
+}
+
+// bashStatus describes how a Bash call ended, and whether it ran at all.
+func bashStatus(item displayItem) (status string, ran bool) {
+	switch {
+	case item.toolError && parser.IsToolRejection(item.toolResult):
+		return "rejected", false
+	case item.toolError:
+		if m := reExitCode.FindStringSubmatch(strings.TrimSpace(item.toolResult)); m != nil {
+			return "exit " + m[1], true
+		}
+		return "failed", true
+	case item.toolResult == "":
+		return "no result", true
+	}
+	return "exit 0", true
+}
+
+// scriptShell renders the Bash calls as a shell script, one commented block
+// per call. It starts in the session's working directory; calls rejected at
+// the prompt are kept, commented out, so the script reads like the session.
+// Meant for review, or replaying steps by hand, not for running blind.
+func scriptShell(session, cwd string, cmds []scriptCommand) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&b, "# Bash commands from session %s, in the order they ran.\n", session)
+	b.WriteString("# Review before running: commands are replayed exactly as the agent wrote them.\n")
+	if cwd != "" {
+		fmt.Fprintf(&b, "\ncd %s || exit 1\n", shellQuote(cwd))
+	}
+	for i, c := range cmds {
+		header := fmt.Sprintf("# [%d]", i+1)
+		if c.agent != mainAgentLabel {
+			header += " " + c.agent + ":"
+		}
+		if c.time != "" {
+			header += " " + c.time
+		}
+		if c.description != "" {
+			header += " · " + c.description
+		}
+		status := c.status
+		if c.background {
+			status += ", ran in the background"
+		}
+		fmt.Fprintf(&b, "\n%s\n# %s\n", header, status)
+		command := strings.TrimRight(c.command, "\n")
+		if !c.ran {
+			command = "# " + strings.ReplaceAll(command, "\n", "\n# ")
+		}
+		b.WriteString(command + "\n")
+	}
+	return b.String()
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
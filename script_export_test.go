@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestBuildScript(t *testing.T) {
+	explore := &parser.SubagentProcess{
+		ID:           "a1b2c3d4e5",
+		SubagentType: "Explore",
+		Chunks: []parser.Chunk{
+			{Type: parser.AIChunk, Items: []parser.DisplayItem{
+				{Type: parser.ItemToolCall, ToolName: "Bash", ToolCategory: parser.CategoryBash, ToolInput: []byte(`{"command":"ls internal"}`), ToolResult: "a.go"},
+			}},
+		},
+	}
+	msgs := []message{
+		userMsg("build it"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "go build ./...", "description": "Build"}`, toolResult: "ok"},
+				{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "/repo/main.go"}`, toolResult: "package main"},
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "go test ./..."}`, toolResult: "Exit code 1\nFAIL", toolError: true},
+				{itemType: parser.ItemSubagent, toolName: "Task", subagentType: "Explore", subagentProcess: explore, toolResult: "done"},
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "rm -rf build\nmkdir build"}`, toolResult: "The user doesn't want to proceed with this tool use. The tool use was rejected.", toolError: true},
+				{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "npm run dev", "run_in_background": true}`},
+			}
+		}),
+	}
+
+	cmds := buildScript(msgs)
+	want := []scriptCommand{
+		{agent: "main", command: "go build ./...", description: "Build", status: "exit 0", ran: true},
+		{agent: "main", command: "go test ./...", status: "exit 1", ran: true},
+		{agent: agentLabel(explore), command: "ls internal", status: "exit 0", ran: true},
+		{agent: "main", command: "rm -rf build\nmkdir build", status: "rejected"},
+		{agent: "main", command: "npm run dev", background: true, status: "no result", ran: true},
+	}
+	if len(cmds) != len(want) {
+		t.Fatalf("got %d commands, want %d: %+v", len(cmds), len(want), cmds)
+	}
+	for i := range want {
+		if cmds[i] != want[i] {
+			t.Errorf("command %d = %+v, want %+v", i, cmds[i], want[i])
+		}
+	}
+
+	script := scriptShell("abc123", "/home/me/it's", cmds)
+	for _, line := range []string{
+		"#!/usr/bin/env bash",
+		`cd '/home/me/it'\''s' || exit 1`,
+		"# [1] · Build",
+		"# exit 0",
+		"go build ./...",
+		"# [3] " + agentLabel(explore) + ":",
+		"# rm -rf build",
+		"# mkdir build",
+		"# no result, ran in the background",
+	} {
+		if !strings.Contains(script, line+"\n") {
+			t.Errorf("script missing line %q:\n%s", line, script)
+		}
+	}
+	if strings.Contains(script, "\nrm -rf build") {
+		t.Error("a rejected command should stay commented out")
+	}
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"strings"
+	"time"
 
 	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
 )
 
 // clampWidth returns m.width capped at maxContentWidth.
@@ -17,36 +20,128 @@ func (m model) clampWidth() int {
 // layoutList renders every message once, caching both the rendered content
 // (listParts) and the line-offset metadata used by scroll math. viewList
 // assembles its output from listParts, so layout and view always agree.
+// In the compact list (Z) every message is a single line. A sorted list (S)
+// lays messages out in sorted order. While tailing, the now marker line is
+// counted with the first recent message. In the grouped list (#) each turn
+// is framed in a container whose header and bottom edge count with its
+// first and last messages; a folded turn is its header line alone, which
+// the turn's other messages share.
 func (m *model) layoutList() {
 	if m.width == 0 || len(m.messages) == 0 {
 		return
 	}
+	defer m.perf.record(perfLayout, m.perf.start())
 	width := m.clampWidth()
+	m.relayoutPending = false
+	m.relayoutFollow = false
+	m.layoutShift = 0
+	m.sortList()
+
+	turns := m.listTurns()
+	turnAt := turnIndex(turns, len(m.messages))
+	if t := turnAt[min(m.cursor, len(m.messages)-1)]; t >= 0 && m.foldedTurns[turns[t].number] {
+		m.cursor = turns[t].first
+	}
 
-	m.listParts = make([]string, len(m.messages))
+	m.listParts = make([]string, 0, len(m.messages))
 	m.lineOffsets = make([]int, len(m.messages))
 	m.messageLines = make([]int, len(m.messages))
+	marker := m.nowMarkerIndex(time.Now())
 	currentLine := 0
-	for i, msg := range m.messages {
+	for pos := range m.messages {
+		i := m.listAt(pos)
+		msg := m.messages[i]
+		t := turnAt[i]
+		var turn listTurn
+		active := false
+		if t >= 0 {
+			turn = turns[t]
+			active = m.cursor >= turn.first && m.cursor <= turn.last
+		}
+		if t >= 0 && m.foldedTurns[turn.number] {
+			if i != turn.first {
+				m.lineOffsets[i] = m.lineOffsets[turn.first]
+				m.messageLines[i] = 1
+				continue
+			}
+			m.lineOffsets[i] = currentLine
+			m.listParts = append(m.listParts, m.renderFoldedTurn(turn, width, active))
+			m.messageLines[i] = 1
+			currentLine++
+			continue
+		}
 		m.lineOffsets[i] = currentLine
-		r := m.renderMessage(msg, width, i == m.cursor, m.expanded[i])
-		m.listParts[i] = r.content
+		msgWidth := width
+		if t >= 0 {
+			msgWidth = width - 4
+		}
+		var r rendered
+		selected := i == m.cursor || m.inRange(i)
+		if m.denseList {
+			r = newRendered(renderDenseMessage(msg, msgWidth, selected))
+		} else {
+			r = m.renderMessage(msg, msgWidth, selected, m.expanded[i])
+		}
+		if i == marker {
+			r = rendered{content: m.renderNowMarker(msgWidth) + "\n" + r.content, lines: r.lines + 1}
+		}
+		if t >= 0 {
+			r = m.railTurnPart(r, turn, i, width, active)
+		}
+		m.listParts = append(m.listParts, r.content)
 		m.messageLines[i] = r.lines
 		currentLine += r.lines
 	}
+	m.totalRenderedLines = currentLine
+}
 
-	if len(m.messages) > 0 {
-		last := len(m.messages) - 1
-		m.totalRenderedLines = m.lineOffsets[last] + m.messageLines[last]
-	} else {
-		m.totalRenderedLines = 0
+// relayoutInterval caps how often tail updates lay out the view (10Hz).
+// During tool-heavy bursts the watcher delivers several updates a second;
+// each one applies its data at once, but they share a single layout.
+const relayoutInterval = 100 * time.Millisecond
+
+// relayoutMsg ends an interval that deferred a layout.
+type relayoutMsg struct{}
+
+// relayoutCmd delivers relayoutMsg after d.
+func relayoutCmd(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return relayoutMsg{}
+	})
+}
+
+// relayoutTail lays out the view after tail updates. The list follows the
+// newest message when the cursor was on the last one, and otherwise keeps
+// the message being read on the same screen row. The detail view only
+// recomputes its max scroll, leaving the scroll position alone.
+func (m *model) relayoutTail() {
+	follow := m.relayoutFollow
+	m.relayoutPending = false
+	m.relayoutFollow = false
+	m.lastRelayout = time.Now()
+
+	switch m.view {
+	case viewList:
+		// The anchor comes from the layout still on screen, so it holds
+		// however many updates the deferred layout covers.
+		anchor, anchored := m.listScrollAnchor()
+		m.layoutList()
+		if follow {
+			m.ensureCursorVisible()
+		} else if anchored {
+			m.restoreScrollAnchor(anchor)
+		}
+	case viewDetail:
+		// The current detail message may have grown (new tool calls,
+		// streaming text), so the user must be able to reach the new content.
+		m.computeDetailMaxScroll()
 	}
 }
 
 // ensureCursorVisible adjusts scroll so the cursor's message is within
 // the visible viewport.
 func (m *model) ensureCursorVisible() {
-	if len(m.lineOffsets) == 0 || m.height == 0 {
+	if m.cursor >= len(m.lineOffsets) || m.height == 0 {
 		return
 	}
 	viewHeight := m.listViewHeight()
@@ -65,6 +160,75 @@ func (m *model) ensureCursorVisible() {
 	}
 }
 
+// followLatest puts the cursor on the newest message and expands the latest
+// Claude message, for --follow. The viewport follows once the terminal size
+// is known.
+func (m *model) followLatest() {
+	if len(m.messages) == 0 {
+		return
+	}
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].role == RoleClaude {
+			m.expanded[i] = true
+			break
+		}
+	}
+	m.cursor = len(m.messages) - 1
+	m.layoutList()
+	m.ensureCursorVisible()
+}
+
+// scrollAnchor pins a message to a screen row so the viewport can be
+// restored after layoutList recomputes line offsets.
+type scrollAnchor struct {
+	index int // message index
+	row   int // screen row of the message's first line; negative when clipped above
+}
+
+// listScrollAnchor picks the message to hold steady across a relayout: the
+// cursor message when it's on screen, otherwise the message at the top of
+// the viewport. The layout on screen may predate an eviction (layoutShift);
+// the anchor's index is into the messages as they are now. Returns false
+// when there is no layout yet or the anchor was evicted.
+func (m model) listScrollAnchor() (scrollAnchor, bool) {
+	if len(m.lineOffsets) == 0 || m.height == 0 {
+		return scrollAnchor{}, false
+	}
+	viewHeight := m.listViewHeight()
+
+	if cursor := m.cursor + m.layoutShift; cursor < len(m.lineOffsets) {
+		start := m.lineOffsets[cursor]
+		end := start + m.messageLines[cursor]
+		if end > m.scroll && start < m.scroll+viewHeight {
+			return scrollAnchor{index: m.cursor, row: start - m.scroll}, true
+		}
+	}
+
+	top := m.listAt(0)
+	for pos := range m.lineOffsets {
+		i := m.listAt(pos)
+		if m.lineOffsets[i] > m.scroll {
+			break
+		}
+		top = i
+	}
+	if top < m.layoutShift {
+		return scrollAnchor{}, false
+	}
+	return scrollAnchor{index: top - m.layoutShift, row: m.lineOffsets[top] - m.scroll}, true
+}
+
+// restoreScrollAnchor sets scroll so the anchored message sits on the same
+// screen row it did before the relayout. Growth above the anchor shifts the
+// scroll by the same amount; growth below leaves it alone.
+func (m *model) restoreScrollAnchor(a scrollAnchor) {
+	if a.index >= len(m.lineOffsets) {
+		return
+	}
+	m.scroll = m.lineOffsets[a.index] - a.row
+	m.clampListScroll()
+}
+
 // clampListScroll caps the list scroll offset so it can't exceed the content.
 func (m *model) clampListScroll() {
 	maxScroll := m.totalRenderedLines - m.listViewHeight()
@@ -146,7 +310,7 @@ func (m *model) detailCursorLine() int {
 	width := m.clampWidth()
 
 	// Count header lines (header + blank separator)
-	header := m.renderDetailHeader(msg, width)
+	header := m.renderDetailTop(msg, width)
 	cursorLine := header.lines + 1 // +1 for blank line separator from "\n\n"
 
 	rows := buildVisibleRows(msg.items, m.detailExpanded)
@@ -202,3 +366,31 @@ func (m *model) ensureDetailCursorVisible() {
 		m.detailScroll = 0
 	}
 }
+
+// panelHeight returns the rows a full-screen panel (outline, search, file
+// report, ...) has for its list under a header of headerLines, above the
+// footer.
+func (m model) panelHeight(headerLines int) int {
+	return max(m.height-m.footerHeight()-headerLines, 1)
+}
+
+// scrollToShow returns scroll moved just enough that the rows lines from
+// row fit in a window of viewHeight lines; row wins when they don't all fit.
+func scrollToShow(scroll, row, rows, viewHeight int) int {
+	if last := row + rows - 1; last >= scroll+viewHeight {
+		scroll = last - viewHeight + 1
+	}
+	if row < scroll {
+		scroll = row
+	}
+	return max(scroll, 0)
+}
+
+// fillToFooter pads a panel's content with blank lines down to the footer,
+// so the footer stays at the bottom of the screen.
+func (m model) fillToFooter(content string) string {
+	if rendered := strings.Count(content, "\n") + 1; rendered < m.height-m.footerHeight() {
+		content += strings.Repeat("\n", m.height-m.footerHeight()-rendered)
+	}
+	return content
+}
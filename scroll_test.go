@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
 )
 
 // scrollModel builds a model with pre-populated scroll state so tests don't
@@ -112,6 +114,30 @@ func TestEnsureCursorVisible(t *testing.T) {
 	})
 }
 
+// --- followLatest -------------------------------------------------------
+
+func TestFollowLatest(t *testing.T) {
+	var msgs []message
+	for range 10 {
+		msgs = append(msgs, userMsg("prompt"), claudeMsg(), userMsg("follow-up"))
+	}
+	m := initialModel(msgs, true)
+	m.followLatest()
+	if m.cursor != len(msgs)-1 {
+		t.Errorf("cursor = %d, want the newest message %d", m.cursor, len(msgs)-1)
+	}
+	if !m.expanded[len(msgs)-2] || m.expanded[len(msgs)-5] {
+		t.Errorf("only the latest Claude message should expand: %v", m.expanded)
+	}
+
+	// The viewport reaches the bottom once the terminal size arrives.
+	result, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	m = asModel(result)
+	if want := max(m.totalRenderedLines-m.listViewHeight(), 0); m.scroll != want || want == 0 {
+		t.Errorf("scroll = %d, want the bottom %d", m.scroll, want)
+	}
+}
+
 // --- view height methods --------------------------------------------------
 
 func TestViewHeights(t *testing.T) {
@@ -137,10 +163,10 @@ func TestViewHeights(t *testing.T) {
 
 	t.Run("pickerViewHeight normal", func(t *testing.T) {
 		m := model{height: 40, showKeybinds: true}
-		// 40 - 2 - 4 = 34
+		// 40 - 2 (header) - 1 (group stats) - 4 = 33
 		got := m.pickerViewHeight()
-		if got != 34 {
-			t.Errorf("pickerViewHeight = %d, want 34", got)
+		if got != 33 {
+			t.Errorf("pickerViewHeight = %d, want 33", got)
 		}
 	})
 
@@ -251,3 +277,107 @@ func TestLayoutListAgreement(t *testing.T) {
 		}
 	})
 }
+
+// --- scroll anchoring ------------------------------------------------------
+
+func TestScrollAnchor(t *testing.T) {
+	t.Run("cursor on screen holds its row when content above grows", func(t *testing.T) {
+		m := scrollModel(100, 20)
+		m.cursor = 1
+		m.scroll = 4
+
+		a, ok := m.listScrollAnchor()
+		if !ok || a.index != 1 || a.row != 2 {
+			t.Fatalf("anchor = %+v (ok=%v), want {index:1 row:2}", a, ok)
+		}
+
+		// Message 0 grew by 10 lines.
+		m.lineOffsets = []int{0, 16, 22}
+		m.restoreScrollAnchor(a)
+		if m.scroll != 14 {
+			t.Errorf("scroll = %d, want 14", m.scroll)
+		}
+	})
+
+	t.Run("cursor off screen anchors the top message", func(t *testing.T) {
+		m := scrollModel(100, 20)
+		m.cursor = 0
+		m.scroll = 8 // message 0 (lines 0-4) is above the viewport
+
+		a, ok := m.listScrollAnchor()
+		if !ok || a.index != 1 || a.row != -2 {
+			t.Fatalf("anchor = %+v (ok=%v), want {index:1 row:-2}", a, ok)
+		}
+	})
+
+	t.Run("growth below the anchor leaves scroll alone", func(t *testing.T) {
+		m := scrollModel(100, 20)
+		m.cursor = 1
+		m.scroll = 4
+		a, _ := m.listScrollAnchor()
+
+		m.messageLines = []int{5, 5, 30}
+		m.totalRenderedLines = 130
+		m.restoreScrollAnchor(a)
+		if m.scroll != 4 {
+			t.Errorf("scroll = %d, want 4", m.scroll)
+		}
+	})
+
+	t.Run("no layout yet", func(t *testing.T) {
+		m := scrollModel(100, 20)
+		m.lineOffsets = nil
+		if _, ok := m.listScrollAnchor(); ok {
+			t.Error("expected no anchor without line offsets")
+		}
+	})
+}
+
+func TestRelayoutTail_CoalescesBursts(t *testing.T) {
+	m := testModel()
+	m.cursor = len(m.messages) - 1
+	grow := func(m model, prompt string) tailUpdateMsg {
+		return tailUpdateMsg{messages: append(append([]message(nil), m.rawMessages...), userMsg(prompt))}
+	}
+
+	// The first update after a quiet spell lays out at once.
+	result, _ := m.Update(grow(m, "one"))
+	m = asModel(result)
+	if len(m.listParts) != 4 || m.relayoutPending {
+		t.Fatalf("first update: %d parts (pending=%v), want 4 laid out", len(m.listParts), m.relayoutPending)
+	}
+
+	// The next one inside the interval applies its data but defers layout.
+	result, _ = m.Update(grow(m, "two"))
+	m = asModel(result)
+	if len(m.messages) != 5 || len(m.listParts) != 4 || !m.relayoutPending {
+		t.Fatalf("burst update: %d messages, %d parts (pending=%v), want 5 messages on the old layout",
+			len(m.messages), len(m.listParts), m.relayoutPending)
+	}
+	if m.cursor != 4 {
+		t.Errorf("cursor = %d, want 4 (following the newest message)", m.cursor)
+	}
+
+	result, _ = m.Update(relayoutMsg{})
+	m = asModel(result)
+	if len(m.listParts) != 5 || m.relayoutPending {
+		t.Errorf("after relayoutMsg: %d parts (pending=%v), want 5 laid out", len(m.listParts), m.relayoutPending)
+	}
+}
+
+func TestScrollToShow(t *testing.T) {
+	tests := []struct {
+		scroll, row, rows, view, want int
+	}{
+		{0, 3, 1, 10, 0},  // already visible
+		{5, 2, 1, 10, 2},  // above: scroll up to it
+		{0, 12, 1, 10, 3}, // below: scroll down until it is the last row
+		{0, 8, 4, 10, 2},  // a tall entry shows whole
+		{0, 8, 20, 10, 8}, // taller than the window: its top wins
+	}
+	for _, tt := range tests {
+		if got := scrollToShow(tt.scroll, tt.row, tt.rows, tt.view); got != tt.want {
+			t.Errorf("scrollToShow(%d, %d, %d, %d) = %d, want %d", tt.scroll, tt.row, tt.rows, tt.view, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// searchSnippetLead is how many bytes of context to keep before a match when
+// a matching line is cut for display.
+const searchSnippetLead = 40
+
+// searchHit is one match in the session. Hits inside subagent traces point at
+// the top-level subagent item that contains them, so Enter opens the trace's
+// entry point in the detail view.
+type searchHit struct {
+	msgIndex  int    // index into m.messages
+	itemIndex int    // index into the message's items, or -1 for message content
+	source    string // where the match was found: "You", "Output", "Bash", ...
+	agent     string // subagent label ("Explore a1b2c3d"); empty for the main session
+	snippet   string // the matching line, trimmed
+}
+
+// searchMessages finds every message and item containing query
+// (case-insensitive). With includeAgents, linked subagent traces are searched
+// too, recursing into nested subagents, and their hits are labeled with the
+// agent that produced them.
+func searchMessages(msgs []message, query string, includeAgents bool) []searchHit {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return nil
+	}
+	var hits []searchHit
+	for i, msg := range msgs {
+		if len(msg.items) == 0 {
+			if snip, ok := matchSnippet(msg.content, q); ok {
+				hits = append(hits, searchHit{msgIndex: i, itemIndex: -1, source: roleLabel(msg.role), snippet: snip})
+			}
+			continue
+		}
+		for j, item := range msg.items {
+			if snip, ok := matchItem(item, q); ok {
+				hits = append(hits, searchHit{msgIndex: i, itemIndex: j, source: searchSourceLabel(item), snippet: snip})
+			}
+			if includeAgents && item.subagentProcess != nil {
+				hits = append(hits, searchTrace(item, q, i, j)...)
+			}
+		}
+	}
+	return hits
+}
+
+// searchTrace searches a subagent's execution trace and any nested subagents
+// it spawned. Hits carry msgIdx/itemIdx of the top-level subagent item.
+func searchTrace(parent displayItem, q string, msgIdx, itemIdx int) []searchHit {
+	agent := agentLabel(parent.subagentProcess)
+	var hits []searchHit
+	for _, item := range buildTraceItems(parent) {
+		if snip, ok := matchItem(item, q); ok {
+			hits = append(hits, searchHit{
+				msgIndex:  msgIdx,
+				itemIndex: itemIdx,
+				source:    searchSourceLabel(item),
+				agent:     agent,
+				snippet:   snip,
+			})
+		}
+		if item.subagentProcess != nil {
+			hits = append(hits, searchTrace(item, q, msgIdx, itemIdx)...)
+		}
+	}
+	return hits
+}
+
+// itemSearchFields returns the fields of an item a search matches: its text,
+// summary, input, and result.
+func itemSearchFields(item displayItem) []string {
+	return []string{item.text, item.subagentDesc, item.toolSummary, item.toolInput, item.toolResult}
+}
+
+// matchItem returns the first matching line across an item's search fields.
+func matchItem(item displayItem, q string) (string, bool) {
+	for _, field := range itemSearchFields(item) {
+		if snip, ok := matchSnippet(field, q); ok {
+			return snip, true
+		}
+	}
+	return "", false
+}
+
+// matchSnippet returns the first line of text containing q (already
+// lowercased). Long lines are cut so the match stays near the start.
+func matchSnippet(text, q string) (string, bool) {
+	if text == "" {
+		return "", false
+	}
+	for _, line := range strings.Split(text, "\n") {
+		lower := strings.ToLower(line)
+		idx := strings.Index(lower, q)
+		if idx < 0 {
+			continue
+		}
+		// Offsets only carry over when lowercasing kept the byte length,
+		// and they move left by the leading space trimmed.
+		sameLen := len(lower) == len(line)
+		idx -= len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		line = strings.TrimSpace(line)
+		if sameLen && idx > searchSnippetLead {
+			start := idx - searchSnippetLead
+			for start < len(line) && !utf8.RuneStart(line[start]) {
+				start++
+			}
+			line = Icon.Ellipsis.Glyph + line[start:]
+		}
+		return line, true
+	}
+	return "", false
+}
+
+// agentLabel names a subagent for hit labels: its type plus a short ID.
+func agentLabel(proc *parser.SubagentProcess) string {
+	label := proc.SubagentType
+	if label == "" {
+		label = "agent"
+	}
+	if id := proc.ID; id != "" {
+		if len(id) > 7 && !strings.Contains(id, "@") {
+			id = id[:7]
+		}
+		label += " " + id
+	}
+	return label
+}
+
+// roleLabel names the author of a message without items.
+func roleLabel(role string) string {
+	switch role {
+	case RoleUser:
+		return "You"
+	case RoleCommand:
+		return "Command"
+	case RoleSystem:
+		return "System"
+	case RoleError:
+		return "API error"
+	case RoleModel:
+		return "Model"
+	case RoleMode:
+		return "Mode"
+	default:
+		return "Claude"
+	}
+}
+
+// searchSourceLabel names the item a hit was found in.
+func searchSourceLabel(item displayItem) string {
+	switch item.itemType {
+	case parser.ItemThinking:
+		return "Thinking"
+	case parser.ItemOutput:
+		if item.toolName != "" {
+			return item.toolName
+		}
+		return "Output"
+	case parser.ItemSubagent:
+		if item.subagentType != "" {
+			return item.subagentType
+		}
+		return "Subagent"
+	case parser.ItemQueuedMessage:
+		return "Queued"
+	}
+	if item.toolName != "" {
+		return item.toolName
+	}
+	return "Item"
+}
+
+// openSearch switches to the search view with the query prompt active.
+func (m *model) openSearch() {
+	m.searchInput = true
+	m.searchCursor = 0
+	m.searchScroll = 0
+	m.view = viewSearch
+}
+
+// updateSearch handles key events in the search view. While the prompt is
+// active, keys edit the query; otherwise they navigate the results.
+func (m model) updateSearch(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if m.searchInput {
+		switch key {
+		case "enter":
+			m.searchInput = false
+		case "esc", "escape":
+			m.searchInput = false
+			m.view = viewList
+		case "backspace":
+			if len(m.searchQuery) > 0 {
+				_, size := utf8.DecodeLastRuneInString(m.searchQuery)
+				m.searchQuery = m.searchQuery[:len(m.searchQuery)-size]
+			}
+		case "space":
+			m.searchQuery += " "
+		case "tab":
+			m.searchAgents = !m.searchAgents
+		default:
+			if len(key) == 1 && key[0] >= 32 && key[0] < 127 {
+				m.searchQuery += key
+			}
+		}
+		m.searchCursor = 0
+		m.searchScroll = 0
+		return m, nil
+	}
+
+	hits := searchMessages(m.messages, m.searchQuery, m.searchAgents)
+	switch key {
+	case "q", "esc", "escape", "backspace":
+		m.view = viewList
+	case "/":
+		m.searchInput = true
+	case "a", "tab":
+		m.searchAgents = !m.searchAgents
+		m.searchCursor = 0
+		m.searchScroll = 0
+	case "j", "down":
+		if m.searchCursor < len(hits)-1 {
+			m.searchCursor++
+		}
+		m.ensureSearchVisible()
+	case "k", "up":
+		if m.searchCursor > 0 {
+			m.searchCursor--
+		}
+		m.ensureSearchVisible()
+	case "G":
+		m.searchCursor = max(len(hits)-1, 0)
+		m.ensureSearchVisible()
+	case "g":
+		m.searchCursor = 0
+		m.searchScroll = 0
+	case "enter":
+		if m.searchCursor < len(hits) {
+			m.highlightQuery = m.searchQuery
+			m.jumpToHit(hits[m.searchCursor])
+		}
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// jumpToHit moves the list cursor to the hit's message. Item hits open the
+// detail view on that item; message hits scroll the list to it.
+func (m *model) jumpToHit(h searchHit) {
+	if h.msgIndex >= len(m.messages) {
+		return
+	}
+	m.cursor = h.msgIndex
+	m.view = viewList
+	m.layoutList()
+	if h.itemIndex < 0 {
+		if m.cursor < len(m.lineOffsets) {
+			m.scroll = m.lineOffsets[m.cursor]
+			m.clampListScroll()
+		}
+		return
+	}
+	m.ensureCursorVisible()
+	m.openDetailItem(h.itemIndex)
+}
+
+// searchStops returns the places n and N stop for the highlighted query,
+// in the order the list shows them: each message or top-level item with a
+// hit, once. Hits inside a subagent trace stop on the subagent's item.
+func (m model) searchStops() []searchHit {
+	var stops []searchHit
+	seen := make(map[[2]int]bool)
+	for _, h := range searchMessages(m.messages, m.highlightQuery, m.searchAgents) {
+		at := [2]int{h.msgIndex, h.itemIndex}
+		if seen[at] {
+			continue
+		}
+		seen[at] = true
+		stops = append(stops, h)
+	}
+	slices.SortStableFunc(stops, func(a, b searchHit) int {
+		return cmp.Or(cmp.Compare(m.listPos(a.msgIndex), m.listPos(b.msgIndex)), cmp.Compare(a.itemIndex, b.itemIndex))
+	})
+	return stops
+}
+
+// stepSearchHit moves to the next hit of the highlighted query after the
+// cursor (n), or the one before it (N), in list order and wrapping at
+// either end. In the detail view the cursor is the item under it; in the
+// list, the message.
+func (m *model) stepSearchHit(forward bool) tea.Cmd {
+	stops := m.searchStops()
+	if len(stops) == 0 {
+		m.flashStatus = fmt.Sprintf("No matches for %q", m.highlightQuery)
+		return flashClearCmd()
+	}
+	item := -1
+	if m.view == viewDetail && m.traceMsg == nil {
+		if rows := m.detailVisibleRows(); m.detailCursor < len(rows) {
+			item = rows[m.detailCursor].parentIndex
+		}
+	}
+	// cmpCursor orders hit h against the cursor.
+	pos := m.listPos(m.cursor)
+	cmpCursor := func(h searchHit) int {
+		return cmp.Or(cmp.Compare(m.listPos(h.msgIndex), pos), cmp.Compare(h.itemIndex, item))
+	}
+
+	idx, wrapped := -1, false
+	if forward {
+		idx = slices.IndexFunc(stops, func(h searchHit) bool { return cmpCursor(h) > 0 })
+		if idx < 0 {
+			idx, wrapped = 0, true
+		}
+	} else {
+		for i := len(stops) - 1; i >= 0; i-- {
+			if cmpCursor(stops[i]) < 0 {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			idx, wrapped = len(stops)-1, true
+		}
+	}
+	m.jumpToHit(stops[idx])
+	m.flashStatus = fmt.Sprintf("Match %d of %d for %q", idx+1, len(stops), m.highlightQuery)
+	if wrapped {
+		m.flashStatus += " (wrapped)"
+	}
+	return flashClearCmd()
+}
+
+// searchViewHeight returns the visible result rows (minus header and footer).
+func (m model) searchViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// ensureSearchVisible adjusts searchScroll so the cursor row is visible.
+func (m *model) ensureSearchVisible() {
+	m.searchScroll = scrollToShow(m.searchScroll, m.searchCursor, 1, m.searchViewHeight())
+}
+
+// viewSearch renders the query prompt and one row per hit.
+func (m model) viewSearch() string {
+	width := m.clampWidth()
+	hits := searchMessages(m.messages, m.searchQuery, m.searchAgents)
+
+	scope := "session"
+	if m.searchAgents {
+		scope = "session + agents"
+	}
+	prompt := StyleAccentBold.Render("/") + m.searchQuery
+	if m.searchInput {
+		prompt += StyleAccentBold.Render("_")
+	}
+	header := spaceBetween(prompt,
+		StyleDim.Render(fmt.Sprintf("%d hits", len(hits)))+"  "+StyleMuted.Render(scope), width) + "\n"
+
+	var lines []string
+	for i, h := range hits {
+		lines = append(lines, renderSearchHit(h, i == m.searchCursor, width))
+	}
+
+	content := header
+	switch {
+	case strings.TrimSpace(m.searchQuery) == "":
+		content += "\n" + StyleDim.Render("Type to search messages, tool input, and results.")
+	case len(hits) == 0:
+		content += "\n" + StyleDim.Render("No matches.")
+	default:
+		content += "\n" + strings.Join(scrollWindow(lines, m.searchViewHeight(), m.searchScroll), "\n")
+	}
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	var footer string
+	if m.searchInput {
+		footer = m.renderFooter(
+			"enter", "done",
+			"tab", "agents",
+			"esc", "cancel",
+		)
+	} else {
+		footer = m.renderFooter(
+			"j/k", "nav",
+			"enter", "open",
+			"/", "edit",
+			"a", "agents",
+			"q/esc", "back",
+			"?", "keys",
+		)
+	}
+	return content + "\n" + footer
+}
+
+// renderSearchHit renders "{sel} Source  {agent}  snippet" on one line.
+func renderSearchHit(h searchHit, isSelected bool, width int) string {
+	sel := selectionIndicator(isSelected)
+	sourceStyle := StyleSecondary
+	if isSelected {
+		sourceStyle = StylePrimaryBold
+	}
+	left := sel + sourceStyle.Render(fmt.Sprintf("%-10s", parser.Truncate(h.source, 10))) + " "
+	if h.agent != "" {
+		left += Icon.Subagent.Render() + " " + StyleSecondaryBold.Render(h.agent) + " "
+	}
+	room := max(width-lipgloss.Width(left)-1, 10)
+	return left + StyleDim.Render(parser.Truncate(h.snippet, room))
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func searchMsgs() []message {
+	return []message{
+		userMsg("where is the retry logic?"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Grep", toolInput: `{"pattern": "backoff"}`},
+				claudeMsgWithNestedSubagent().items[0],
+			}
+		}),
+	}
+}
+
+func TestSearchMessages(t *testing.T) {
+	t.Run("matches message content and item fields", func(t *testing.T) {
+		hits := searchMessages(searchMsgs(), "RETRY", false)
+		if len(hits) != 1 || hits[0].msgIndex != 0 || hits[0].itemIndex != -1 || hits[0].source != "You" {
+			t.Fatalf("hits = %+v, want one user-message hit", hits)
+		}
+
+		hits = searchMessages(searchMsgs(), "backoff", false)
+		if len(hits) != 1 || hits[0].itemIndex != 0 || hits[0].source != "Grep" {
+			t.Fatalf("hits = %+v, want one Grep hit", hits)
+		}
+	})
+
+	t.Run("subagent traces only searched in agents mode", func(t *testing.T) {
+		if hits := searchMessages(searchMsgs(), "TODO", false); len(hits) != 0 {
+			t.Errorf("session-only hits = %+v, want none", hits)
+		}
+
+		// "TODO" lives in the nested Plan agent's Grep call.
+		hits := searchMessages(searchMsgs(), "TODO", true)
+		if len(hits) != 1 {
+			t.Fatalf("hits = %+v, want one nested hit", hits)
+		}
+		h := hits[0]
+		if h.msgIndex != 1 || h.itemIndex != 1 {
+			t.Errorf("hit points at %d/%d, want the top-level subagent item 1/1", h.msgIndex, h.itemIndex)
+		}
+		if !strings.HasPrefix(h.agent, "agent") {
+			t.Errorf("agent = %q, want the nested agent's label", h.agent)
+		}
+	})
+
+	t.Run("blank query has no hits", func(t *testing.T) {
+		if hits := searchMessages(searchMsgs(), "  ", true); hits != nil {
+			t.Errorf("hits = %+v, want nil", hits)
+		}
+	})
+}
+
+func TestMatchSnippet(t *testing.T) {
+	text := "first line\n  " + strings.Repeat("x", 60) + " needle here"
+	got, ok := matchSnippet(text, "needle")
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if !strings.HasPrefix(got, Icon.Ellipsis.Glyph) || !strings.Contains(got, "needle here") {
+		t.Errorf("snippet = %q, want the line cut to keep the match", got)
+	}
+
+	// The Kelvin sign lowercases to a shorter k; trimming the trailing
+	// spaces must not make the lengths look equal again.
+	kelvin := "\u212a" + strings.Repeat("x", 60) + " needle  "
+	if got, _ := matchSnippet(kelvin, "needle"); got != strings.TrimSpace(kelvin) {
+		t.Errorf("snippet = %q, want the whole line: its offsets don't carry over", got)
+	}
+}
+
+func TestAgentLabel(t *testing.T) {
+	got := agentLabel(&parser.SubagentProcess{ID: "a1b2c3d4e5f6", SubagentType: "Explore"})
+	if got != "Explore a1b2c3d" {
+		t.Errorf("agentLabel = %q, want %q", got, "Explore a1b2c3d")
+	}
+	got = agentLabel(&parser.SubagentProcess{ID: "planner@analysis"})
+	if got != "agent planner@analysis" {
+		t.Errorf("agentLabel = %q, want %q", got, "agent planner@analysis")
+	}
+}
+
+func TestUpdateSearch(t *testing.T) {
+	m := testModel()
+	m.messages = searchMsgs()
+	m.layoutList()
+	m.openSearch()
+
+	for _, k := range []string{"b", "a", "c", "k", "o", "f", "f"} {
+		result, _ := m.updateSearch(key(k))
+		m = asModel(result)
+	}
+	if m.searchQuery != "backoff" {
+		t.Fatalf("searchQuery = %q, want backoff", m.searchQuery)
+	}
+
+	result, _ := m.updateSearch(key("enter"))
+	m = asModel(result)
+	if m.searchInput {
+		t.Fatal("enter should leave the prompt")
+	}
+
+	result, _ = m.updateSearch(key("enter"))
+	m = asModel(result)
+	if m.view != viewDetail || m.cursor != 1 || !m.detailExpanded[0] {
+		t.Errorf("view=%v cursor=%d expanded=%v, want detail on message 1 item 0", m.view, m.cursor, m.detailExpanded)
+	}
+}
+
+func TestStepSearchHit(t *testing.T) {
+	prev := exactTokens
+	t.Cleanup(func() { exactTokens = prev })
+	m := testModel()
+	m.messages = []message{
+		userMsg("fix foo.go"),
+		claudeMsg(func(m *message) {
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "bar.go"}`},
+				{itemType: parser.ItemToolCall, toolName: "Edit", toolInput: `{"file_path": "foo.go"}`},
+			}
+		}),
+	}
+	m.layoutList()
+	m.highlightQuery = "foo.go"
+
+	result, _ := m.Update(key("n"))
+	m = asModel(result)
+	if m.view != viewDetail || m.cursor != 1 || m.detailVisibleRows()[m.detailCursor].parentIndex != 1 {
+		t.Fatalf("n from the prompt: view=%v cursor=%d, want the Edit call in message 1", m.view, m.cursor)
+	}
+
+	result, _ = m.Update(key("n"))
+	m = asModel(result)
+	if m.view != viewList || m.cursor != 0 || !strings.Contains(m.flashStatus, "wrapped") {
+		t.Errorf("n past the last hit: view=%v cursor=%d flash=%q, want the prompt, wrapped", m.view, m.cursor, m.flashStatus)
+	}
+
+	result, _ = m.Update(key("N"))
+	m = asModel(result)
+	if m.cursor != 1 || exactTokens != prev {
+		t.Errorf("N: cursor=%d exactTokens=%v, want the last hit and exact tokens untouched", m.cursor, exactTokens)
+	}
+}
+
+func TestSearchStops(t *testing.T) {
+	m := testModel()
+	m.messages = []message{
+		userMsg("check the retry loop"),
+		claudeMsg(func(m *message) {
+			m.tokensRaw = 100
+			m.items = []displayItem{{itemType: parser.ItemOutput, text: "the retry loop is fine"}}
+		}),
+		claudeMsg(func(m *message) {
+			m.tokensRaw = 900
+			m.items = []displayItem{claudeMsgWithSubagent().items[1]}
+			m.items[0].subagentProcess.Chunks = []parser.Chunk{
+				{Type: parser.AIChunk, Items: []parser.DisplayItem{
+					{Type: parser.ItemOutput, Text: "retry one"},
+					{Type: parser.ItemOutput, Text: "retry two"},
+				}},
+			}
+		}),
+	}
+	m.highlightQuery = "retry"
+	m.searchAgents = true
+	m.listSort = sortTokens
+	m.layoutList()
+
+	var got [][2]int
+	for _, h := range m.searchStops() {
+		got = append(got, [2]int{h.msgIndex, h.itemIndex})
+	}
+	// The subagent's two trace hits are one stop, and the list shows the
+	// 900-token turn first.
+	if want := [][2]int{{2, 0}, {1, 0}, {0, -1}}; !slices.Equal(got, want) {
+		t.Errorf("stops = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version is the release this binary was built from, set at link time by
+// `just release`: -ldflags "-X main.version=v0.3.4". Builds without it fall
+// back to the module version `go install ...@v0.3.4` records.
+var version = ""
+
+// latestReleaseURL is the GitHub API endpoint for the newest release.
+var latestReleaseURL = "https://api.github.com/repos/kylesnowschwartz/tail-claude/releases/latest"
+
+// releaseChecksums is the release asset listing each binary's SHA-256, in
+// sha256sum format.
+const releaseChecksums = "checksums.txt"
+
+// updateTimeout bounds each request of a check or update.
+const updateTimeout = 2 * time.Minute
+
+// currentVersion returns the running binary's version, or "dev" for a build
+// from a source checkout.
+func currentVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// release is the part of a GitHub release the updater reads.
+type release struct {
+	TagName string         `json:"tag_name"`
+	HTMLURL string         `json:"html_url"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+// releaseAsset is a file attached to a release.
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the URL of the named asset.
+func (r release) asset(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// releaseBinary names the release asset built for goos/goarch, e.g.
+// "tail-claude_darwin_arm64".
+func releaseBinary(goos, goarch string) string {
+	name := "tail-claude_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// fetchLatestRelease asks GitHub for the newest release.
+func fetchLatestRelease(client *http.Client, url string) (release, error) {
+	var rel release
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return rel, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return rel, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return rel, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return rel, fmt.Errorf("reading release: %w", err)
+	}
+	if rel.TagName == "" {
+		return rel, errors.New("reading release: no tag")
+	}
+	return rel, nil
+}
+
+// compareVersions orders two "vMAJOR.MINOR.PATCH" versions like strings.Compare.
+// Anything that doesn't parse, such as "dev", sorts before every release.
+func compareVersions(a, b string) int {
+	pa, okA := parseVersion(a)
+	pb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseVersion reads "v1.2.3" (the v optional, a pre-release or build
+// suffix ignored).
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+// runVersion implements `tail-claude version [--check]`. With --check it
+// exits 1 when a newer release exists, so scripts can act on it.
+func runVersion(args []string) int {
+	check := false
+	for _, a := range args {
+		if a != "--check" {
+			fmt.Fprintln(os.Stderr, "usage: tail-claude version [--check]")
+			return 2
+		}
+		check = true
+	}
+	cur := currentVersion()
+	fmt.Printf("tail-claude %s (%s/%s)\n", cur, runtime.GOOS, runtime.GOARCH)
+	if !check {
+		return 0
+	}
+	rel, err := fetchLatestRelease(&http.Client{Timeout: updateTimeout}, latestReleaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 2
+	}
+	if compareVersions(cur, rel.TagName) >= 0 {
+		fmt.Printf("Up to date: %s is the latest release.\n", rel.TagName)
+		return 0
+	}
+	fmt.Printf("%s is available: run `tail-claude self-update`.\n%s\n", rel.TagName, rel.HTMLURL)
+	return 1
+}
+
+// runSelfUpdate implements `tail-claude self-update [--force]`: it replaces
+// the running executable with the latest release's binary for this platform
+// once its checksum verifies. --force reinstalls even when up to date.
+func runSelfUpdate(args []string) int {
+	force := false
+	for _, a := range args {
+		if a != "--force" {
+			fmt.Fprintln(os.Stderr, "usage: tail-claude self-update [--force]")
+			return 2
+		}
+		force = true
+	}
+	client := &http.Client{Timeout: updateTimeout}
+	rel, err := fetchLatestRelease(client, latestReleaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 2
+	}
+	cur := currentVersion()
+	if !force && compareVersions(cur, rel.TagName) >= 0 {
+		fmt.Printf("Up to date: %s is the latest release.\n", rel.TagName)
+		return 0
+	}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: finding the running executable: %v\n", err)
+		return 2
+	}
+	if err := installRelease(client, rel, releaseBinary(runtime.GOOS, runtime.GOARCH), exe); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 2
+	}
+	fmt.Printf("Updated %s from %s to %s.\n", exe, cur, rel.TagName)
+	return 0
+}
+
+// installRelease downloads the release's binary named name, checks it
+// against the release's checksums, and moves it over target. The download
+// lands next to target first, so a failure leaves target untouched and the
+// final rename is atomic.
+func installRelease(client *http.Client, rel release, name, target string) error {
+	binURL, ok := rel.asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for this platform (%s)", rel.TagName, name)
+	}
+	sumsURL, ok := rel.asset(releaseChecksums)
+	if !ok {
+		return fmt.Errorf("release %s has no %s to verify against", rel.TagName, releaseChecksums)
+	}
+	want, err := fetchChecksum(client, sumsURL, name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".tail-claude-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	got, err := download(client, binURL, tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("%s: checksum mismatch: got %s, want %s", name, got, want)
+	}
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(target); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		// A running .exe can't be replaced, only renamed out of the way.
+		// Put it back if the new one can't take its place.
+		old := target + ".old"
+		os.Remove(old)
+		if err := os.Rename(target, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), target); err != nil {
+			if undo := os.Rename(old, target); undo != nil {
+				return fmt.Errorf("%w (and the old binary is left at %s: %v)", err, old, undo)
+			}
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// download writes url's body to w and returns its SHA-256 in hex.
+func download(client *http.Client, url string, w io.Writer) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+		return "", fmt.Errorf("downloading %s: %w", url, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fetchChecksum reads name's SHA-256 from a sha256sum-format listing.
+func fetchChecksum(client *http.Client, url, name string) (string, error) {
+	var buf strings.Builder
+	if _, err := download(client, url, &buf); err != nil {
+		return "", err
+	}
+	sc := bufio.NewScanner(strings.NewReader(buf.String()))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s lists no checksum for %s", releaseChecksums, name)
+}
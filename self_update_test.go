@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v0.3.3", "v0.3.3", 0},
+		{"v0.3.3", "v0.3.4", -1},
+		{"v0.10.0", "v0.9.9", 1},
+		{"0.3.3", "v0.3.3", 0},
+		{"v0.3.4-0.20260101000000-abcdef", "v0.3.4", 0},
+		{"dev", "v0.1.0", -1},
+		{"v0.1.0", "dev", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestInstallRelease(t *testing.T) {
+	const name = "tail-claude_linux_amd64"
+	binary := "#!/bin/sh\necho new\n"
+	sum := sha256.Sum256([]byte(binary))
+	checksums := hex.EncodeToString(sum[:]) + "  " + name + "\n"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		base := "http://" + r.Host
+		w.Write([]byte(`{"tag_name": "v9.9.9", "assets": [
+			{"name": "` + name + `", "browser_download_url": "` + base + `/bin"},
+			{"name": "checksums.txt", "browser_download_url": "` + base + `/sums"}]}`))
+	})
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(binary)) })
+	mux.HandleFunc("/sums", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(checksums)) })
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	rel, err := fetchLatestRelease(srv.Client(), srv.URL+"/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.TagName != "v9.9.9" {
+		t.Errorf("tag = %q", rel.TagName)
+	}
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "tail-claude")
+	if err := os.WriteFile(target, []byte("old"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := installRelease(srv.Client(), rel, name, target); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != binary {
+		t.Errorf("target = %q, want the release binary", got)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0o700 {
+		t.Errorf("mode = %v, want the old binary's 0700", info.Mode().Perm())
+	}
+
+	// A binary that doesn't match its checksum is left uninstalled.
+	checksums = strings.Repeat("0", 64) + "  " + name + "\n"
+	os.WriteFile(target, []byte("old"), 0o700)
+	err = installRelease(srv.Client(), rel, name, target)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("err = %v, want a checksum mismatch", err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "old" {
+		t.Errorf("target = %q after a failed update", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temp download left behind: %d entries", len(entries))
+	}
+
+	if err := installRelease(srv.Client(), rel, "tail-claude_plan9_386", target); err == nil {
+		t.Error("want an error for a platform without a binary")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// shutdownTimeout bounds how long a signal-triggered quit may take before
+// the program is killed. Writes to a terminal that has gone away can block.
+const shutdownTimeout = 2 * time.Second
+
+// uiState is where a run left the list, so the next run on the same
+// session starts there rather than at the newest message.
+type uiState struct {
+	Message string    `json:"message"` // uuid of the message under the cursor
+	Saved   time.Time `json:"saved"`
+}
+
+// signalQuitMsg asks the model to save its UI state before the quit that
+// follows it: the terminal closed or the process was asked to stop.
+type signalQuitMsg struct{}
+
+// quitOnSignals quits p cleanly when the terminal closes (SIGHUP) or the
+// process is asked to stop (SIGTERM, or SIGINT when input isn't a terminal),
+// so Run restores the terminal instead of leaving it in raw mode and main can
+// stop the watchers. It stands in for Bubble Tea's own handler (run p
+// WithoutSignalHandler), which quits on SIGTERM before the model can save
+// anything and leaves SIGHUP to kill the process mid-frame. The model saves
+// its UI state before it quits (signalQuitMsg).
+// If the quit stalls past shutdownTimeout, or a second signal arrives, the
+// program is killed, which still restores the terminal.
+//
+// The returned function stops listening; call it once Run returns.
+func quitOnSignals(p *tea.Program) func() {
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-sig:
+			// Sends block while Update is busy; the timeout below still runs.
+			go func() {
+				p.Send(signalQuitMsg{})
+				p.Quit()
+			}()
+		}
+		select {
+		case <-done:
+		case <-sig:
+			p.Kill()
+		case <-time.After(shutdownTimeout):
+			p.Kill()
+		}
+	}()
+
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
+
+// runProgram runs the TUI until it quits, then saves the final model's UI
+// state (unless a signal already did) and stops its watchers. A kill after a
+// signal counts as a clean exit; only quitOnSignals kills the program.
+func runProgram(m model) error {
+	// Lay out the first frame at the terminal's size when it can be read
+	// now, rather than waiting for Bubble Tea's first WindowSizeMsg.
+	opts := []tea.ProgramOption{tea.WithoutSignalHandler()}
+	if w, h, ok := terminalSize(); ok {
+		m.resize(w, h)
+		opts = append(opts, tea.WithWindowSize(w, h))
+	}
+	p := tea.NewProgram(m, opts...)
+	stopSignals := quitOnSignals(p)
+	final, err := p.Run()
+	stopSignals()
+	if fm, ok := final.(model); ok {
+		if !fm.uiStateSaved && err == nil {
+			if err := fm.saveUIState(statePath()); err != nil {
+				logUIError("save UI state", err)
+			}
+		}
+		fm.stopWatchers()
+		fm.viewers.leave(fm.sessionPath)
+	}
+	if errors.Is(err, tea.ErrProgramKilled) {
+		return nil
+	}
+	return err
+}
+
+// stopWatchers stops every watcher goroutine the model owns and cancels its
+// view's background work. Called on the final model after Run returns,
+// however the program exited.
+func (m *model) stopWatchers() {
+	if m.watcher != nil {
+		m.watcher.stop()
+		m.watcher = nil
+	}
+	m.stopDebugWatcher()
+	if m.pickerWatcher != nil {
+		m.pickerWatcher.stop()
+		m.pickerWatcher = nil
+	}
+	if m.viewWork != nil {
+		m.viewWork.cancel()
+		m.viewWork = nil
+	}
+}
+
+// saveUIState records where the list is in the state file at path. Nothing
+// is written under --read-only or without a session.
+func (m model) saveUIState(path string) error {
+	if m.readOnly || m.sessionPath == "" || path == "" {
+		return nil
+	}
+	if m.cursor >= len(m.messages) || m.messages[m.cursor].uuid == "" {
+		return nil
+	}
+	st, _ := loadState(path)
+	if st.Resume == nil {
+		st.Resume = make(map[string]uiState)
+	}
+	st.Resume[m.sessionPath] = uiState{Message: m.messages[m.cursor].uuid, Saved: time.Now()}
+	pruneResumePoints(st.Resume)
+	return saveState(path, st)
+}
+
+// pruneResumePoints drops the oldest resume points past maxResumePoints.
+func pruneResumePoints(points map[string]uiState) {
+	for len(points) > maxResumePoints {
+		oldest := ""
+		for session, r := range points {
+			if oldest == "" || r.Saved.Before(points[oldest].Saved) {
+				oldest = session
+			}
+		}
+		delete(points, oldest)
+	}
+}
+
+// restoreUIState puts the cursor back on the message the last run on this
+// session left it on. When that message isn't loaded (evicted, hidden by a
+// filter, or outside a partial load) the cursor stays at the newest.
+func (m *model) restoreUIState(st appState) {
+	r, ok := st.Resume[m.sessionPath]
+	if !ok || r.Message == "" {
+		return
+	}
+	i := slices.IndexFunc(m.messages, func(msg message) bool { return msg.uuid == r.Message })
+	if i < 0 {
+		return
+	}
+	m.cursor = i
+	m.layoutList()
+	m.ensureCursorVisible()
+}
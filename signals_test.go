@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// idleModel never quits on its own.
+type idleModel struct{}
+
+func (idleModel) Init() tea.Cmd                       { return nil }
+func (idleModel) Update(tea.Msg) (tea.Model, tea.Cmd) { return idleModel{}, nil }
+func (idleModel) View() tea.View                      { return tea.NewView("") }
+
+func TestQuitOnSignals_Hangup(t *testing.T) {
+	p := tea.NewProgram(idleModel{}, tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutSignalHandler())
+	stop := quitOnSignals(p)
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Run()
+		done <- err
+	}()
+
+	// Give Run a moment to start before the terminal "closes".
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v, want clean quit", err)
+		}
+	case <-time.After(shutdownTimeout + time.Second):
+		t.Fatal("program did not exit after SIGHUP")
+	}
+}
+
+func TestStopWatchers(t *testing.T) {
+	m := testModel()
+	m.watcher = newSessionWatcher(parser.FileSource("/nonexistent.jsonl"), nil, 0)
+	done := m.watcher.done
+
+	m.stopWatchers()
+	if m.watcher != nil {
+		t.Error("watcher should be cleared")
+	}
+	select {
+	case <-done:
+	default:
+		t.Error("session watcher was not stopped")
+	}
+
+	// Safe to call again with nothing running.
+	m.stopWatchers()
+}
+
+func TestQuitOnSignals_SavesUIState(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	m := testModel()
+	m.sessionPath = "/tmp/session.jsonl"
+	m.messages[1].uuid = "a1"
+	m.cursor = 1
+
+	p := tea.NewProgram(m, tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutSignalHandler())
+	stop := quitOnSignals(p)
+	defer stop()
+
+	done := make(chan tea.Model, 1)
+	go func() {
+		final, _ := p.Run()
+		done <- final
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+
+	select {
+	case final := <-done:
+		if fm, ok := final.(model); !ok || !fm.uiStateSaved {
+			t.Error("the model did not save its UI state on the way out")
+		}
+	case <-time.After(shutdownTimeout + time.Second):
+		t.Fatal("program did not exit after SIGTERM")
+	}
+
+	st, ok := loadState(statePath())
+	if r := st.Resume["/tmp/session.jsonl"]; !ok || r.Message != "a1" {
+		t.Errorf("resume = %+v, want the session with the cursor on a1", st.Resume)
+	}
+}
+
+func TestRestoreUIState(t *testing.T) {
+	m := testModel()
+	m.sessionPath = "/tmp/session.jsonl"
+	for i := range m.messages {
+		m.messages[i].uuid = fmt.Sprintf("m%d", i)
+	}
+	m.cursor = len(m.messages) - 1
+
+	m.restoreUIState(appState{Resume: map[string]uiState{"/tmp/other.jsonl": {Message: "m0"}}})
+	if m.cursor != len(m.messages)-1 {
+		t.Errorf("cursor = %d, another session's resume point moved it", m.cursor)
+	}
+	m.restoreUIState(appState{Resume: map[string]uiState{"/tmp/session.jsonl": {Message: "evicted"}}})
+	if m.cursor != len(m.messages)-1 {
+		t.Errorf("cursor = %d, a resume point on a message not loaded moved it", m.cursor)
+	}
+	m.restoreUIState(appState{Resume: map[string]uiState{"/tmp/session.jsonl": {Message: "m1"}}})
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want 1 from the resume point", m.cursor)
+	}
+
+	// Messages dropped from the front since: the point follows its message.
+	m.setMessages(m.rawMessages[1:])
+	m.cursor = len(m.messages) - 1
+	m.restoreUIState(appState{Resume: map[string]uiState{"/tmp/session.jsonl": {Message: "m1"}}})
+	if m.messages[m.cursor].uuid != "m1" {
+		t.Errorf("cursor on %q, want m1", m.messages[m.cursor].uuid)
+	}
+}
+
+func TestSaveUIState_KeepsEachSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	m := testModel()
+	for i := range m.messages {
+		m.messages[i].uuid = fmt.Sprintf("m%d", i)
+	}
+	for i, session := range []string{"/tmp/a.jsonl", "/tmp/b.jsonl"} {
+		m.sessionPath, m.cursor = session, i
+		if err := m.saveUIState(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st, _ := loadState(path)
+	if st.Resume["/tmp/a.jsonl"].Message != "m0" || st.Resume["/tmp/b.jsonl"].Message != "m1" {
+		t.Errorf("resume = %+v, want both sessions' points", st.Resume)
+	}
+
+	points := make(map[string]uiState)
+	for i := range maxResumePoints + 1 {
+		points[fmt.Sprint(i)] = uiState{Message: "x", Saved: time.Unix(int64(i), 0)}
+	}
+	pruneResumePoints(points)
+	if _, ok := points["0"]; ok || len(points) != maxResumePoints {
+		t.Errorf("kept %d points (oldest kept: %v), want the %d newest", len(points), ok, maxResumePoints)
+	}
+}
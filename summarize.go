@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// defaultSummarizeMinChars is how long a tool result or thinking block must
+// be before S offers to summarize it.
+const defaultSummarizeMinChars = 2000
+
+// defaultSummarizeTimeout bounds a summarizer run.
+const defaultSummarizeTimeout = time.Minute
+
+// summaryMaxBytes caps the summary kept from a command's output.
+const summaryMaxBytes = 16 * 1024
+
+// summarizerConfig names an external command that reduces long content: a
+// local LLM call, `head -40`, anything that reads stdin and writes stdout.
+type summarizerConfig struct {
+	Command  string `json:"command"`            // run with sh -c; the content arrives on stdin
+	MinChars int    `json:"minChars,omitempty"` // shortest content S summarizes; 0 uses 2000
+	Timeout  string `json:"timeout,omitempty"`  // e.g. "2m"; empty uses 1m
+}
+
+// validateSummarizer reports an empty command or a bad timeout.
+func validateSummarizer(c *summarizerConfig) error {
+	if c == nil {
+		return nil
+	}
+	if strings.TrimSpace(c.Command) == "" {
+		return errors.New("command is empty")
+	}
+	if c.MinChars < 0 {
+		return fmt.Errorf("minChars %d is negative", c.MinChars)
+	}
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("timeout %q is not a positive duration", c.Timeout)
+		}
+	}
+	return nil
+}
+
+// minChars returns the configured threshold or the default.
+func (c *summarizerConfig) minChars() int {
+	if c.MinChars > 0 {
+		return c.MinChars
+	}
+	return defaultSummarizeMinChars
+}
+
+// timeout returns the configured run limit or the default.
+func (c *summarizerConfig) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultSummarizeTimeout
+}
+
+// summaryKey identifies summarized content by its bytes, so a summary
+// follows the content across rebuilds, trace levels, and sessions.
+type summaryKey [sha256.Size]byte
+
+// itemSummary is the state of one summary.
+type itemSummary struct {
+	running bool
+	text    string
+	err     error
+}
+
+// summaryDoneMsg reports a finished summarizer run.
+type summaryDoneMsg struct {
+	key  summaryKey
+	text string
+	err  error
+}
+
+// summarizable returns the content of an item that S can summarize: a
+// thinking block, or a tool call's result. kind names it for the command.
+func summarizable(item displayItem) (text, kind string) {
+	switch {
+	case item.itemType == parser.ItemThinking:
+		return strings.TrimSpace(item.text), "thinking"
+	case item.toolResult != "":
+		return item.toolResult, "tool_result"
+	}
+	return "", ""
+}
+
+// summarizeCmd pipes text through the summarizer. The command also gets
+// TAIL_CLAUDE_KIND ("thinking" or "tool_result") and, for tool results,
+// TAIL_CLAUDE_TOOL in its environment, so one script can reduce each kind
+// its own way.
+func summarizeCmd(c *summarizerConfig, key summaryKey, text, kind, tool string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout())
+		defer cancel()
+		out, err := runFilter(ctx, c.Command, text, "TAIL_CLAUDE_KIND="+kind, "TAIL_CLAUDE_TOOL="+tool)
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", c.timeout())
+		}
+		if err != nil {
+			return summaryDoneMsg{key: key, err: err}
+		}
+		return summaryDoneMsg{key: key, text: strings.TrimSpace(string(out))}
+	}
+}
+
+// runFilter pipes stdin through command, run with sh -c and env added to
+// the environment, and returns what it printed, cut to summaryMaxBytes on a
+// rune boundary. A failure carries the start of the command's stderr; a run
+// the context stopped returns the context's error.
+func runFilter(ctx context.Context, command, stdin string, env ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = strings.NewReader(stdin)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, parser.Truncate(msg, 200))
+		}
+		return nil, err
+	}
+	if len(out) > summaryMaxBytes {
+		out = out[:summaryMaxBytes]
+		for len(out) > 0 && !utf8.Valid(out) {
+			out = out[:len(out)-1]
+		}
+	}
+	return out, nil
+}
+
+// summarizeDetailItem starts the summarizer on the item under the detail
+// cursor and expands it so the summary shows above the raw content. Pressing
+// S again on a summarized item runs it afresh.
+func (m *model) summarizeDetailItem() tea.Cmd {
+	c := m.cfg.Summarizer
+	if c == nil {
+		m.flashStatus = "No summarizer configured (summarizer.command in config)"
+		return flashClearCmd()
+	}
+	rows := m.detailVisibleRows()
+	if m.detailCursor >= len(rows) {
+		return nil
+	}
+	row := rows[m.detailCursor]
+	text, kind := summarizable(row.item)
+	if utf8.RuneCountInString(text) < c.minChars() {
+		m.flashStatus = fmt.Sprintf("Nothing to summarize: only long results and thinking (%s+ chars)", formatCount(c.minChars()))
+		return flashClearCmd()
+	}
+	key := summaryKey(sha256.Sum256([]byte(text)))
+	if m.summaries[key].running {
+		return nil
+	}
+	if m.summaries == nil {
+		m.summaries = make(map[summaryKey]itemSummary)
+	}
+	m.summaries[key] = itemSummary{running: true}
+
+	expanded := m.detailExpanded[row.parentIndex]
+	if row.childIndex != -1 {
+		expanded = m.detailChildExpanded[visibleRowKey{row.parentIndex, row.childIndex}]
+	}
+	if !expanded {
+		m.toggleDetailExpansion()
+	} else {
+		m.computeDetailMaxScroll()
+	}
+	tool := ""
+	if kind == "tool_result" {
+		tool = row.item.toolName
+	}
+	return summarizeCmd(c, key, text, kind, tool)
+}
+
+// applySummary records a finished run.
+func (m *model) applySummary(msg summaryDoneMsg) {
+	if m.summaries == nil {
+		m.summaries = make(map[summaryKey]itemSummary)
+	}
+	m.summaries[msg.key] = itemSummary{text: msg.text, err: msg.err}
+	if m.view == viewDetail {
+		m.computeDetailMaxScroll()
+	}
+}
+
+// renderItemSummary renders the summary of an expanded item, if one was
+// asked for, followed by a separator from the raw content below it.
+func (m model) renderItemSummary(item displayItem, wrapWidth int, indent string) string {
+	if len(m.summaries) == 0 {
+		return ""
+	}
+	text, _ := summarizable(item)
+	if text == "" {
+		return ""
+	}
+	s, ok := m.summaries[summaryKey(sha256.Sum256([]byte(text)))]
+	if !ok {
+		return ""
+	}
+	lines := []string{indent + StyleSecondaryBold.Render("Summary:")}
+	switch {
+	case s.running:
+		lines = append(lines, indent+StyleMuted.Render("Summarizing…"))
+	case s.err != nil:
+		lines = append(lines, indent+StyleErrorBold.Render("Summarizer failed"),
+			indentBlock(StyleDim.Width(wrapWidth).Render(s.err.Error()), indent))
+	case s.text == "":
+		lines = append(lines, indent+StyleMuted.Render("(empty summary)"))
+	default:
+		lines = append(lines, indentBlock(m.md.renderMarkdown(s.text, wrapWidth), indent))
+	}
+	lines = append(lines, indent+StyleMuted.Render(strings.Repeat("-", wrapWidth)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestValidateSummarizer(t *testing.T) {
+	for _, tc := range []struct {
+		cfg *summarizerConfig
+		ok  bool
+	}{
+		{nil, true},
+		{&summarizerConfig{Command: "head -5"}, true},
+		{&summarizerConfig{Command: "head -5", Timeout: "2m", MinChars: 100}, true},
+		{&summarizerConfig{Command: "  "}, false},
+		{&summarizerConfig{Command: "head", Timeout: "soon"}, false},
+		{&summarizerConfig{Command: "head", MinChars: -1}, false},
+	} {
+		if err := validateSummarizer(tc.cfg); (err == nil) != tc.ok {
+			t.Errorf("validateSummarizer(%+v) = %v, want ok %v", tc.cfg, err, tc.ok)
+		}
+	}
+}
+
+func TestSummarizeDetailItem(t *testing.T) {
+	long := strings.Repeat("line of build output\n", 10)
+	msg := claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "make"}`, toolResult: long},
+			{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "a.go"}`, toolResult: "short"},
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		m := detailModel(msg)
+		result, _ := m.updateDetail(key("S"))
+		if got := asModel(result).flashStatus; !strings.Contains(got, "No summarizer") {
+			t.Errorf("flash = %q", got)
+		}
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		m := detailModel(msg)
+		m.cfg.Summarizer = &summarizerConfig{Command: "head -c 4", MinChars: 50}
+		m.detailCursor = 1
+		result, cmd := m.updateDetail(key("S"))
+		if got := asModel(result).flashStatus; !strings.Contains(got, "Nothing to summarize") {
+			t.Errorf("flash = %q", got)
+		}
+		if cmd == nil {
+			t.Error("want a flash clear command")
+		}
+	})
+
+	t.Run("summary above the result", func(t *testing.T) {
+		m := detailModel(msg)
+		m.cfg.Summarizer = &summarizerConfig{Command: `printf 'KIND=%s TOOL=%s FIRST=' "$TAIL_CLAUDE_KIND" "$TAIL_CLAUDE_TOOL"; head -n 1`, MinChars: 50}
+		result, cmd := m.updateDetail(key("S"))
+		m = asModel(result)
+		if cmd == nil {
+			t.Fatal("S should start the summarizer")
+		}
+		if !m.detailExpanded[0] {
+			t.Error("S should expand the item")
+		}
+		if out := m.viewDetail(); !strings.Contains(out, "Summarizing…") {
+			t.Errorf("running summary not shown:\n%s", out)
+		}
+
+		done := cmd().(summaryDoneMsg)
+		if done.err != nil {
+			t.Fatal(done.err)
+		}
+		if want := "KIND=tool_result TOOL=Bash FIRST=line of build output"; done.text != want {
+			t.Errorf("summary = %q, want %q", done.text, want)
+		}
+		result, _ = m.Update(done)
+		m = asModel(result)
+		out := m.viewDetail()
+		summary, raw := strings.Index(out, "FIRST=line of build output"), strings.Index(out, "Result:")
+		if summary < 0 || raw < 0 || summary > raw {
+			t.Errorf("want the summary above the raw result:\n%s", out)
+		}
+	})
+
+	t.Run("failure shows stderr", func(t *testing.T) {
+		m := detailModel(msg)
+		m.cfg.Summarizer = &summarizerConfig{Command: "echo no model >&2; exit 3", MinChars: 50}
+		result, cmd := m.updateDetail(key("S"))
+		m = asModel(result)
+		result, _ = m.Update(cmd())
+		m = asModel(result)
+		if out := m.viewDetail(); !strings.Contains(out, "Summarizer failed") || !strings.Contains(out, "no model") {
+			t.Errorf("failure not shown:\n%s", out)
+		}
+	})
+}
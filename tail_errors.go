@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// errorBannerTTL is how long a tail error stays on screen unless dismissed.
+const errorBannerTTL = 10 * time.Second
+
+// maxTailErrors caps the tail errors kept for the debug view.
+const maxTailErrors = 100
+
+// tailErrorCategory labels tail-claude's own errors in the debug view.
+const tailErrorCategory = "tail-claude"
+
+// errorBannerClearMsg hides the error banner. The seq field ties it to the
+// error that scheduled it, so a newer error keeps its full time on screen.
+type errorBannerClearMsg struct{ seq int }
+
+// errorBannerClearCmd schedules the banner for seq to hide.
+func errorBannerClearCmd(seq int) tea.Cmd {
+	return tea.Tick(errorBannerTTL, func(time.Time) tea.Msg {
+		return errorBannerClearMsg{seq: seq}
+	})
+}
+
+// reportTailError shows err in the error banner and logs it, with the
+// session it came from, as an ERROR entry in the debug view. Repeats of the
+// banner's error bump a counter instead of replacing it.
+func (m *model) reportTailError(err error, now time.Time) tea.Cmd {
+	text := err.Error()
+	message, extra, _ := strings.Cut(text, "\n")
+	if m.sessionPath != "" {
+		if extra != "" {
+			extra += "\n"
+		}
+		extra += "session: " + m.sessionPath
+	}
+	m.tailErrors = append(m.tailErrors, parser.DebugEntry{
+		Timestamp: now,
+		Level:     parser.LevelError,
+		Category:  tailErrorCategory,
+		Message:   message,
+		Extra:     extra,
+		Count:     1,
+	})
+	if over := len(m.tailErrors) - maxTailErrors; over > 0 {
+		m.tailErrors = slices.Delete(m.tailErrors, 0, over)
+	}
+	if m.view == viewDebug {
+		m.applyDebugFilters()
+	}
+
+	if message == m.errorBanner {
+		m.errorRepeats++
+	} else {
+		m.errorBanner = message
+		m.errorRepeats = 1
+	}
+	m.errorBannerSeq++
+	return errorBannerClearCmd(m.errorBannerSeq)
+}
+
+// dismissErrorBanner hides the banner. The error stays in the debug view.
+func (m *model) dismissErrorBanner() {
+	m.errorBanner = ""
+	m.errorRepeats = 0
+}
+
+// withTailErrors merges logged tail errors into debug entries by time.
+func withTailErrors(entries, tailErrors []parser.DebugEntry) []parser.DebugEntry {
+	if len(tailErrors) == 0 {
+		return entries
+	}
+	merged := slices.Concat(entries, tailErrors)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+	return merged
+}
+
+// errorBannerHeight is the number of lines the banner adds above the info bar.
+func (m model) errorBannerHeight() int {
+	if m.errorBanner == "" {
+		return 0
+	}
+	return 1
+}
+
+// renderErrorBanner renders "✗ reading abc.jsonl at byte 1024: ... (×3)  x dismiss · d log".
+func (m model) renderErrorBanner() string {
+	hint := StyleDim.Render("  x dismiss " + Icon.Dot.Glyph + " d log")
+	label := "tail error: " + m.errorBanner
+	if m.errorRepeats > 1 {
+		label += fmt.Sprintf(" (×%d)", m.errorRepeats)
+	}
+	room := max(m.width-lipgloss.Width(hint)-4, 10)
+	return " " + Icon.Tool.Err.Render() + " " + StyleErrorBold.Render(parser.Truncate(label, room)) + hint
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestWatcherErrBanner(t *testing.T) {
+	m := testModel()
+	m.sessionPath = "/nonexistent/abc.jsonl"
+	before := m.footerHeight()
+
+	result, cmd := m.Update(watcherErrMsg{err: errors.New("reading abc.jsonl at byte 42: input/output error")})
+	m = asModel(result)
+	if cmd == nil {
+		t.Fatal("watcherErrMsg should schedule the banner clear and re-subscribe")
+	}
+	if m.errorBanner == "" || m.footerHeight() != before+1 {
+		t.Fatalf("banner %q, footer %d; want banner shown one line above the info bar", m.errorBanner, m.footerHeight())
+	}
+	if out := m.renderFooter(); !strings.Contains(out, "input/output error") {
+		t.Errorf("footer missing error text:\n%s", out)
+	}
+
+	// A repeat bumps the counter; the stale clear timer is ignored.
+	result, _ = m.Update(watcherErrMsg{err: errors.New("reading abc.jsonl at byte 42: input/output error")})
+	m = asModel(result)
+	if m.errorRepeats != 2 || !strings.Contains(m.renderErrorBanner(), "(×2)") {
+		t.Errorf("errorRepeats = %d, banner %q; want 2 and a (×2) count", m.errorRepeats, m.renderErrorBanner())
+	}
+	result, _ = m.Update(errorBannerClearMsg{seq: m.errorBannerSeq - 1})
+	if asModel(result).errorBanner == "" {
+		t.Error("stale clear message hid the banner")
+	}
+
+	// x dismisses; the errors stay logged.
+	result, _ = m.Update(key("x"))
+	m = asModel(result)
+	if m.errorBanner != "" || m.footerHeight() != before {
+		t.Errorf("after x: banner %q, footer %d; want hidden", m.errorBanner, m.footerHeight())
+	}
+	if len(m.tailErrors) != 2 {
+		t.Fatalf("len(tailErrors) = %d, want 2", len(m.tailErrors))
+	}
+	e := m.tailErrors[0]
+	if e.Level != parser.LevelError || e.Category != tailErrorCategory || !strings.Contains(e.Extra, "abc.jsonl") {
+		t.Errorf("logged entry = %+v, want ERROR [%s] with session context", e, tailErrorCategory)
+	}
+
+	// With no debug log file, d opens the debug view on the tail errors.
+	result, _ = m.Update(key("d"))
+	m = asModel(result)
+	if m.view != viewDebug || len(m.debugFiltered) != 2 {
+		t.Errorf("view %d with %d entries; want viewDebug with both errors", m.view, len(m.debugFiltered))
+	}
+}
+
+func TestWithTailErrors(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	log := []parser.DebugEntry{
+		{Timestamp: t0, Message: "first"},
+		{Timestamp: t0.Add(2 * time.Second), Message: "third"},
+	}
+	tail := []parser.DebugEntry{{Timestamp: t0.Add(time.Second), Message: "second"}}
+
+	merged := withTailErrors(log, tail)
+	var got []string
+	for _, e := range merged {
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, ",") != "first,second,third" {
+		t.Errorf("merged order = %v, want first,second,third", got)
+	}
+	if len(log) != 2 {
+		t.Errorf("withTailErrors modified its input: %d entries", len(log))
+	}
+}
+
+func TestReportTailError_Cap(t *testing.T) {
+	m := testModel()
+	for i := range maxTailErrors + 5 {
+		m.reportTailError(errors.New(strings.Repeat("x", i+1)), time.Now())
+	}
+	if len(m.tailErrors) != maxTailErrors {
+		t.Errorf("len(tailErrors) = %d, want %d", len(m.tailErrors), maxTailErrors)
+	}
+	if m.tailErrors[0].Message != strings.Repeat("x", 6) {
+		t.Errorf("oldest kept = %q, want the 6th error", m.tailErrors[0].Message)
+	}
+}
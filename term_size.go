@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"golang.org/x/term"
+)
+
+// sizeProbeInterval is how often the UI asks the terminal for its size
+// while it has none: some terminals send the first WindowSizeMsg late, or
+// report 0 columns until they settle.
+const sizeProbeInterval = 100 * time.Millisecond
+
+// sizeProbeLimit caps the probes; past it the UI waits for a resize.
+const sizeProbeLimit = 20
+
+// sizeProbeMsg asks the terminal for its size again if it's still unknown.
+type sizeProbeMsg struct{ n int }
+
+// sizeProbeCmd schedules probe n.
+func sizeProbeCmd(n int) tea.Cmd {
+	return tea.Tick(sizeProbeInterval, func(time.Time) tea.Msg {
+		return sizeProbeMsg{n}
+	})
+}
+
+// terminalSize returns the size of the terminal on stdout, stderr, or
+// stdin, whichever is one first, so the first frame can be laid out before
+// Bubble Tea reports the size. ok is false when none is a terminal.
+func terminalSize() (width, height int, ok bool) {
+	for _, f := range []*os.File{os.Stdout, os.Stderr, os.Stdin} {
+		if w, h, err := term.GetSize(int(f.Fd())); err == nil && w > 0 && h > 0 {
+			return w, h, true
+		}
+	}
+	return 0, 0, false
+}
+
+// resize lays the UI out for a width x height terminal. A size with no
+// columns or rows, which some terminals report while starting up, is
+// ignored so the last good layout stays up. Returns whether it was used.
+func (m *model) resize(width, height int) bool {
+	if width <= 0 || height <= 0 {
+		return false
+	}
+	m.width, m.height = width, height
+	m.layoutList()
+	m.ensureCursorVisible()
+	if m.view == viewDetail {
+		m.computeDetailMaxScroll()
+	}
+	return true
+}
+
+// redraw relays out the current view, clears the screen, and asks the
+// terminal for its size again (ctrl+l), for a frame garbled by a late or
+// bogus size or by output from another process.
+func (m *model) redraw() tea.Cmd {
+	m.resize(m.width, m.height)
+	return tea.Batch(tea.ClearScreen, tea.RequestWindowSize)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestWindowSizeIgnoresZeroColumns(t *testing.T) {
+	m := testModel()
+	result, _ := m.Update(tea.WindowSizeMsg{Width: 0, Height: 0})
+	m = asModel(result)
+	if m.width != 120 || len(m.listParts) == 0 {
+		t.Errorf("after a 0x0 size: width %d, %d list parts; want the last layout kept", m.width, len(m.listParts))
+	}
+	result, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = asModel(result)
+	if m.width != 80 || m.height != 30 {
+		t.Errorf("size = %dx%d, want 80x30", m.width, m.height)
+	}
+}
+
+func TestSizeProbe(t *testing.T) {
+	m := initialModel([]message{userMsg("hi")}, true)
+	if got := m.View().Content; got != "" {
+		t.Errorf("View before a size = %q, want nothing drawn", got)
+	}
+	if _, cmd := m.Update(sizeProbeMsg{n: 1}); cmd == nil {
+		t.Error("probe without a size: want another request")
+	}
+	if _, cmd := m.Update(sizeProbeMsg{n: sizeProbeLimit + 1}); cmd != nil {
+		t.Error("probe past the limit: want it to stop")
+	}
+	m.resize(100, 40)
+	if _, cmd := m.Update(sizeProbeMsg{n: 1}); cmd != nil {
+		t.Error("probe with a size: want it to stop")
+	}
+}
+
+func TestRedraw(t *testing.T) {
+	m := testModel()
+	m.listParts = nil
+	result, cmd := m.Update(key("ctrl+l"))
+	m = asModel(result)
+	if cmd == nil || len(m.listParts) == 0 {
+		t.Errorf("ctrl+l: cmd %v, %d list parts; want a relayout and a repaint", cmd, len(m.listParts))
+	}
+}
@@ -24,6 +24,7 @@ import (
 // | TextMuted           | "245" | "240" | med-lt gray   | dark gray      |
 // | Accent              |   "4" |  "75" | blue          | blue           |
 // | Error               |   "1" | "196" | red           | red            |
+// | Warning             |   "3" | "214" | gold          | amber          |
 // | Info                |   "4" |  "69" | blue          | blue           |
 // | Border              | "250" |  "60" | subtle gray   | muted blue     |
 // | ModelOpus           |   "1" | "204" | red           | coral          |
@@ -44,9 +45,10 @@ var (
 	ColorTextMuted     color.Color
 
 	// Accents
-	ColorAccent color.Color
-	ColorError  color.Color
-	ColorInfo   color.Color
+	ColorAccent  color.Color
+	ColorError   color.Color
+	ColorWarning color.Color
+	ColorInfo    color.Color
 
 	// Surfaces
 	ColorBorder color.Color
@@ -114,6 +116,7 @@ var (
 	StyleMuted         lipgloss.Style
 	StyleAccentBold    lipgloss.Style
 	StyleErrorBold     lipgloss.Style
+	StyleWarningBold   lipgloss.Style
 )
 
 // initTheme resolves all colors for the detected background and rebuilds
@@ -130,6 +133,7 @@ func initTheme(hasDarkBg bool) {
 	// Accents
 	ColorAccent = ld(lipgloss.Color("4"), lipgloss.Color("75"))
 	ColorError = ld(lipgloss.Color("1"), lipgloss.Color("196"))
+	ColorWarning = ld(lipgloss.Color("3"), lipgloss.Color("214"))
 	ColorInfo = ld(lipgloss.Color("4"), lipgloss.Color("69"))
 
 	// Surfaces
@@ -191,4 +195,5 @@ func initTheme(hasDarkBg bool) {
 	StyleMuted = lipgloss.NewStyle().Foreground(ColorTextMuted)
 	StyleAccentBold = lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
 	StyleErrorBold = lipgloss.NewStyle().Bold(true).Foreground(ColorError)
+	StyleWarningBold = lipgloss.NewStyle().Bold(true).Foreground(ColorWarning)
 }
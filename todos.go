@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// hasTaskBoard reports whether the task board (t) has something to show:
+// a team, or todos the session left unfinished.
+func (m model) hasTaskBoard() bool {
+	return len(m.teams) > 0 || len(parser.UnfinishedTodos(m.todos)) > 0
+}
+
+// renderTodoSection renders the task board's "unfinished work" panel: the
+// todos the session's last TodoWrite left open, for whoever picks the work
+// up next. Returns "" when nothing is unfinished.
+func renderTodoSection(todos []parser.Todo, width int) string {
+	open := parser.UnfinishedTodos(todos)
+	if len(open) == 0 {
+		return ""
+	}
+	lines := []string{
+		renderTeamDivider("unfinished work", width),
+		StyleDim.Render(fmt.Sprintf("%d of %d todos unfinished", len(open), len(todos))),
+		"",
+	}
+	contentWidth := max(width-7, 10) // indent(2) + status(3) + gap(2)
+	for _, t := range open {
+		lines = append(lines, "  "+taskStatusGlyph(t.Status)+"  "+parser.Truncate(t.Content, contentWidth))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// todoMarkdown renders unfinished todos as a Markdown task list under an
+// "Unfinished work" heading, for exports. Returns "" when nothing is
+// unfinished.
+func todoMarkdown(todos []parser.Todo) string {
+	open := parser.UnfinishedTodos(todos)
+	if len(open) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Unfinished work\n\n")
+	for _, t := range open {
+		b.WriteString("- [ ] " + t.Content)
+		if t.Status == "in_progress" {
+			b.WriteString(" (in progress)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
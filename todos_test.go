@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// todoList is a session's last TodoWrite list: one todo done, two not.
+func todoList() []parser.Todo {
+	return []parser.Todo{
+		{Content: "Fix the bug", Status: "completed"},
+		{Content: "Write tests", Status: "in_progress"},
+		{Content: "Update docs", Status: "pending"},
+	}
+}
+
+func TestTaskBoardShowsUnfinishedTodos(t *testing.T) {
+	m := testModel()
+	if m.hasTaskBoard() {
+		t.Fatal("task board without teams or todos")
+	}
+	m.todos = todoList()
+	if !m.hasTaskBoard() {
+		t.Fatal("unfinished todos should make a task board")
+	}
+
+	got := plain(m.renderTeamContent(80, 0))
+	for _, want := range []string{"unfinished work", "2 of 3 todos unfinished", "Write tests", "Update docs"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("board missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Fix the bug") {
+		t.Errorf("board lists a finished todo:\n%s", got)
+	}
+}
+
+func TestSessionMarkdown_UnfinishedWork(t *testing.T) {
+	md := sessionMarkdown("abc123", "", exportMsgs(), todoList())
+	want := "## Unfinished work\n\n- [ ] Write tests (in progress)\n- [ ] Update docs\n"
+	if !strings.HasSuffix(md, want) {
+		t.Errorf("markdown should end with %q:\n%s", want, md)
+	}
+	if md := sessionMarkdown("abc123", "", exportMsgs(), nil); strings.Contains(md, "Unfinished work") {
+		t.Error("unfinished work section without todos")
+	}
+}
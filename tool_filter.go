@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/filter"
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// hiddenToolFilter is the tool menu's hidden set as filter terms,
+// "tool!=TodoWrite AND tool!=Read", matched against each tool item rather
+// than the message; nil when nothing is hidden.
+func hiddenToolFilter(hidden map[string]bool) filter.Expr {
+	return filter.Excluding("tool", slices.Sorted(maps.Keys(hidden))...)
+}
+
+// filterHiddenTools returns msgs with the tool items expr rejects removed.
+// Message indices are preserved so expansion state keyed by index survives
+// toggling. The input slice is not modified.
+func filterHiddenTools(msgs []message, expr filter.Expr) []message {
+	if expr == nil {
+		return msgs
+	}
+	out := make([]message, len(msgs))
+	for i, msg := range msgs {
+		out[i] = filterMessageTools(msg, expr)
+	}
+	return out
+}
+
+// filterMessageTools drops the tool items of a single message that expr
+// rejects and adjusts its tool count. The number removed is kept in
+// hiddenToolCount for the header indicator.
+func filterMessageTools(msg message, expr filter.Expr) message {
+	if expr == nil || len(msg.items) == 0 {
+		return msg
+	}
+	var kept []displayItem
+	removed := 0
+	for _, it := range msg.items {
+		isTool := it.itemType == parser.ItemToolCall || it.itemType == parser.ItemSubagent
+		if isTool && !expr.Match(messageRecord{&message{items: []displayItem{it}}}) {
+			removed++
+			continue
+		}
+		kept = append(kept, it)
+	}
+	if removed == 0 {
+		return msg
+	}
+	msg.items = kept
+	msg.toolCallCount = max(msg.toolCallCount-removed, 0)
+	msg.hiddenToolCount = removed
+	return msg
+}
+
+// toolMenuEntry is one row of the tool visibility menu.
+type toolMenuEntry struct {
+	name  string
+	count int // calls across the session
+}
+
+// collectToolNames tallies tool calls by name across all messages, sorted by
+// descending count then name. Uses unfiltered messages so hidden tools stay
+// listed and can be shown again.
+func collectToolNames(msgs []message) []toolMenuEntry {
+	counts := make(map[string]int)
+	for _, msg := range msgs {
+		for _, it := range msg.items {
+			if (it.itemType == parser.ItemToolCall || it.itemType == parser.ItemSubagent) && it.toolName != "" {
+				counts[it.toolName]++
+			}
+		}
+	}
+	entries := make([]toolMenuEntry, 0, len(counts))
+	for name, n := range counts {
+		entries = append(entries, toolMenuEntry{name: name, count: n})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].name < entries[j].name
+	})
+	return entries
+}
+
+// hiddenSystemFilter is the hiddenSystem config as filter terms,
+// "system!=status AND system!=queue"; nil when nothing is hidden. Unlike
+// hidden tools the set only changes with the config, so the shifted indices
+// don't strand expansion state on a toggle.
+func hiddenSystemFilter(hidden map[parser.SystemKind]bool) filter.Expr {
+	kinds := make([]string, 0, len(hidden))
+	for kind := range hidden {
+		kinds = append(kinds, string(kind))
+	}
+	slices.Sort(kinds)
+	return filter.Excluding("system", kinds...)
+}
+
+// setMessages stores the unfiltered messages and derives the visible set:
+// the messages the hidden system kinds and the list filter let through, with
+// the hidden tools' terms applied to their items. Whole-session figures the
+// views show on every render are computed here too.
+func (m *model) setMessages(msgs []message) {
+	m.rawMessages = msgs
+	numberTurns(msgs)
+	m.approvalWait = buildApprovalWait(msgs).String()
+	m.messages = filterHiddenTools(filterMessages(msgs, filter.And(hiddenSystemFilter(m.hiddenSystem), m.listFilter)), hiddenToolFilter(m.hiddenTools))
+}
+
+// toggleToolHidden flips a tool's visibility, re-derives messages, and
+// persists the change. Another instance may have saved the config since this
+// one loaded it, so the toggle is applied to the file as it is now and its
+// hidden tools are adopted. A save failure is surfaced as a flash status.
+func (m *model) toggleToolHidden(name string) tea.Cmd {
+	latest := m.cfg
+	if m.configPath != "" {
+		if disk, err := loadConfig(m.configPath); err == nil {
+			latest = disk
+		}
+	}
+	hidden := latest.hiddenToolSet()
+	if m.hiddenTools[name] {
+		delete(hidden, name)
+	} else {
+		hidden[name] = true
+	}
+	m.hiddenTools = hidden
+	m.setMessages(m.rawMessages)
+
+	m.cfg = m.cfg.withHiddenTools(m.hiddenTools)
+	if m.configPath == "" {
+		return nil
+	}
+	if err := saveConfig(m.configPath, latest.withHiddenTools(m.hiddenTools)); err != nil {
+		m.flashStatus = "Config not saved: " + err.Error()
+		logUIError("config not saved", err, "path", m.configPath)
+		return flashClearCmd()
+	}
+	return nil
+}
+
+// updateToolMenu handles key events in the tool visibility menu.
+func (m model) updateToolMenu(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	entries := collectToolNames(m.rawMessages)
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "backspace", "H":
+		m.view = viewList
+		m.layoutList()
+		m.clampListScroll()
+	case "j", "down":
+		if m.toolMenuCursor < len(entries)-1 {
+			m.toolMenuCursor++
+		}
+		m.ensureToolMenuVisible()
+	case "k", "up":
+		if m.toolMenuCursor > 0 {
+			m.toolMenuCursor--
+		}
+		m.ensureToolMenuVisible()
+	case "G":
+		m.toolMenuCursor = max(len(entries)-1, 0)
+		m.ensureToolMenuVisible()
+	case "g":
+		m.toolMenuCursor = 0
+		m.toolMenuScroll = 0
+	case "space", " ", "enter", "tab":
+		if m.toolMenuCursor < len(entries) {
+			return m, m.toggleToolHidden(entries[m.toolMenuCursor].name)
+		}
+	case "?":
+		m.showKeybinds = !m.showKeybinds
+	}
+	return m, nil
+}
+
+// toolMenuViewHeight returns the visible menu rows (minus header and footer).
+func (m model) toolMenuViewHeight() int {
+	return m.panelHeight(2)
+}
+
+// ensureToolMenuVisible adjusts toolMenuScroll so the cursor row is visible.
+func (m *model) ensureToolMenuVisible() {
+	m.toolMenuScroll = scrollToShow(m.toolMenuScroll, m.toolMenuCursor, 1, m.toolMenuViewHeight())
+}
+
+// viewToolMenu renders the tool visibility menu: one checkbox row per tool.
+func (m model) viewToolMenu() string {
+	width := m.clampWidth()
+	entries := collectToolNames(m.rawMessages)
+
+	hiddenCount := 0
+	for _, e := range entries {
+		if m.hiddenTools[e.name] {
+			hiddenCount++
+		}
+	}
+	header := StyleAccentBold.Render("Tool visibility") + " " +
+		StyleDim.Render(fmt.Sprintf("(%d hidden)", hiddenCount)) + "\n"
+
+	var lines []string
+	for i, e := range entries {
+		sel := selectionIndicator(i == m.toolMenuCursor)
+		box := Icon.Task.Done.Render()
+		name := StylePrimaryBold.Render(e.name)
+		if m.hiddenTools[e.name] {
+			box = Icon.Task.Pending.Render()
+			name = StyleMuted.Render(e.name)
+		}
+		left := sel + box + " " + name
+		lines = append(lines, spaceBetween(left, StyleDim.Render(fmt.Sprintf("%d calls", e.count)), width))
+	}
+
+	content := header
+	if len(entries) == 0 {
+		content += "\n" + StyleDim.Render("No tool calls in this session.")
+	} else {
+		content += "\n" + strings.Join(scrollWindow(lines, m.toolMenuViewHeight(), m.toolMenuScroll), "\n")
+	}
+	content = centerBlock(content, width, m.width)
+
+	content = m.fillToFooter(content)
+
+	footer := m.renderFooter(
+		"j/k", "nav",
+		"space", "show/hide",
+		"q/esc", "back",
+		"?", "keys",
+	)
+	return content + "\n" + footer
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func toolFilterMsgs() []message {
+	return []message{
+		userMsg("hi"),
+		claudeMsg(func(m *message) {
+			m.toolCallCount = 3
+			m.items = []displayItem{
+				{itemType: parser.ItemToolCall, toolName: "TodoWrite"},
+				{itemType: parser.ItemOutput, text: "TodoWrite"},
+				{itemType: parser.ItemToolCall, toolName: "Read"},
+				{itemType: parser.ItemToolCall, toolName: "TodoWrite"},
+			}
+		}),
+	}
+}
+
+func TestFilterHiddenTools(t *testing.T) {
+	msgs := toolFilterMsgs()
+	got := filterHiddenTools(msgs, hiddenToolFilter(map[string]bool{"TodoWrite": true}))
+
+	if len(got) != len(msgs) {
+		t.Fatalf("len = %d, want %d (indices preserved)", len(got), len(msgs))
+	}
+	c := got[1]
+	if len(c.items) != 2 {
+		t.Fatalf("len(items) = %d, want 2 (output + Read)", len(c.items))
+	}
+	if c.toolCallCount != 1 {
+		t.Errorf("toolCallCount = %d, want 1", c.toolCallCount)
+	}
+	if c.hiddenToolCount != 2 {
+		t.Errorf("hiddenToolCount = %d, want 2", c.hiddenToolCount)
+	}
+	if len(msgs[1].items) != 4 {
+		t.Error("input messages were modified")
+	}
+}
+
+func TestHiddenSystemFilter(t *testing.T) {
+	msgs := []message{
+		userMsg("hi"),
+		{role: RoleSystem, content: "Context low", systemKind: parser.SystemStatus},
+		{role: RoleSystem, content: "Explanatory", systemKind: parser.SystemOutputStyle},
+		{role: RoleSystem, content: "ls output"},
+	}
+	expr := hiddenSystemFilter(config{HiddenSystem: []string{"status", "outputStyle"}}.hiddenSystemSet())
+	if got, want := expr.String(), "system!=outputStyle AND system!=status"; got != want {
+		t.Errorf("terms = %q, want %q", got, want)
+	}
+	got := filterMessages(msgs, expr)
+	if len(got) != 2 || got[1].content != "ls output" {
+		t.Errorf("got %+v, want the user message and the command output", got)
+	}
+	if len(msgs) != 4 {
+		t.Error("input slice was modified")
+	}
+
+	if err := validateHiddenSystem([]string{"queue", "hooks"}); err == nil || !strings.Contains(err.Error(), `"hooks"`) {
+		t.Errorf("validateHiddenSystem = %v, want an error naming hooks", err)
+	}
+}
+
+func TestCollectToolNames(t *testing.T) {
+	entries := collectToolNames(toolFilterMsgs())
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+	if entries[0].name != "TodoWrite" || entries[0].count != 2 {
+		t.Errorf("entries[0] = %+v, want TodoWrite x2", entries[0])
+	}
+	if entries[1].name != "Read" || entries[1].count != 1 {
+		t.Errorf("entries[1] = %+v, want Read x1", entries[1])
+	}
+}
+
+func TestUpdateToolMenu(t *testing.T) {
+	t.Run("space hides the tool and persists it", func(t *testing.T) {
+		m := testModel()
+		m.applyConfig(filepath.Join(t.TempDir(), "config.json"), config{})
+		m.setMessages(toolFilterMsgs())
+		m.view = viewTools
+
+		result, _ := m.updateToolMenu(key("space"))
+		got := asModel(result)
+		if !got.hiddenTools["TodoWrite"] {
+			t.Fatal("TodoWrite should be hidden")
+		}
+		if len(got.messages[1].items) != 2 {
+			t.Errorf("visible items = %d, want 2", len(got.messages[1].items))
+		}
+		saved, err := loadConfig(got.configPath)
+		if err != nil || len(saved.HiddenTools) != 1 || saved.HiddenTools[0] != "TodoWrite" {
+			t.Errorf("saved config = %v (err %v), want [TodoWrite]", saved.HiddenTools, err)
+		}
+
+		// Toggling again restores the tool.
+		result, _ = got.updateToolMenu(key("space"))
+		got = asModel(result)
+		if len(got.messages[1].items) != 4 {
+			t.Errorf("visible items = %d, want 4 after unhide", len(got.messages[1].items))
+		}
+	})
+
+	t.Run("a toggle keeps tools another instance hid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		m := testModel()
+		m.applyConfig(path, config{})
+		m.setMessages(toolFilterMsgs())
+		m.view = viewTools
+
+		// Another instance hides Bash after this one loaded the config.
+		if err := saveConfig(path, config{HiddenTools: []string{"Bash"}}); err != nil {
+			t.Fatal(err)
+		}
+		result, _ := m.updateToolMenu(key("space"))
+		got := asModel(result)
+		saved, _ := loadConfig(path)
+		if strings.Join(saved.HiddenTools, ",") != "Bash,TodoWrite" || !got.hiddenTools["Bash"] {
+			t.Errorf("saved %v, hidden %v; want both instances' tools", saved.HiddenTools, got.hiddenTools)
+		}
+	})
+
+	t.Run("H from list opens the menu", func(t *testing.T) {
+		m := testModel()
+		result, _ := m.updateList(key("H"))
+		if got := asModel(result); got.view != viewTools {
+			t.Errorf("view = %v, want viewTools", got.view)
+		}
+	})
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	"charm.land/lipgloss/v2"
+)
+
+// resultKind is the detected shape of a tool result, which picks its renderer.
+type resultKind int
+
+const (
+	resultPlain resultKind = iota // dim text
+	resultJSON                    // pretty-printed, syntax highlighted
+	resultDiff                    // unified diff, colorized by line
+	resultLog                     // log lines, colorized by level
+	resultTable                   // pipe-, tab-, comma-, or space-separated rows, aligned into columns
+)
+
+// maxDetectLines caps how many lines detection inspects, so huge results
+// don't slow down every render.
+const maxDetectLines = 200
+
+var (
+	// reDiffHunk matches a unified diff hunk header: "@@ -12,7 +12,9 @@".
+	reDiffHunk = regexp.MustCompile(`^@@ -\d+(,\d+)? \+\d+(,\d+)? @@`)
+
+	// reLogLine matches lines that start like log output: a date or time
+	// stamp, or a level keyword (bare, bracketed, or as level=...).
+	reLogLine = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}|\d{2}:\d{2}:\d{2}|\[?(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL)\]?[\s:]|level=)`)
+
+	// reLogError and reLogWarn pick a log line's level.
+	reLogError = regexp.MustCompile(`(?i)\b(error|fatal|panic|fail(ed)?)\b`)
+	reLogWarn  = regexp.MustCompile(`(?i)\b(warn|warning)\b`)
+
+	// reTableRule matches a table separator row: Markdown's "|---|:---:|",
+	// psql's "----+----", or MySQL's "+----+----+".
+	reTableRule = regexp.MustCompile(`^[|+]?\s*:?-{3,}:?\s*([|+]\s*:?-{3,}:?\s*)*[|+]?$`)
+
+	// reRowCount matches the row count a SQL client prints under its
+	// results: "(3 rows)".
+	reRowCount = regexp.MustCompile(`^\(\d+ rows?\)$`)
+
+	// reLsTotal matches the block total ls -l prints above its listing.
+	reLsTotal = regexp.MustCompile(`^total \d+`)
+
+	// reNumeric matches a number as tools print them: "42", "-1.5",
+	// "12,345", "10:04", "85%", "4.0K", "20G".
+	reNumeric = regexp.MustCompile(`^[-+]?\d[\d.,:]*(%|[KMGTP]i?B?|[kmg]?B)?$`)
+)
+
+// detectResultKind guesses what a tool result contains from its first lines.
+func detectResultKind(text string) resultKind {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return resultPlain
+	}
+	if json.Valid([]byte(trimmed)) {
+		return resultJSON
+	}
+
+	lines := strings.Split(trimmed, "\n")
+	if len(lines) > maxDetectLines {
+		lines = lines[:maxDetectLines]
+	}
+	// Delimited rows are a table before they're a log; columns split only
+	// at spaces are a table only when they aren't.
+	switch {
+	case isDiff(lines):
+		return resultDiff
+	case delimitedCells(lines) != nil:
+		return resultTable
+	case isLog(lines):
+		return resultLog
+	case columnCells(lines) != nil:
+		return resultTable
+	}
+	return resultPlain
+}
+
+// isDiff reports whether lines hold a unified diff: at least one hunk header
+// with added or removed lines after it.
+func isDiff(lines []string) bool {
+	inHunk := false
+	for _, line := range lines {
+		if reDiffHunk.MatchString(line) {
+			inHunk = true
+			continue
+		}
+		if inHunk && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")) &&
+			!strings.HasPrefix(line, "+++") && !strings.HasPrefix(line, "---") {
+			return true
+		}
+	}
+	return false
+}
+
+// isLog reports whether most non-empty lines (and at least two) start like
+// log output.
+func isLog(lines []string) bool {
+	var total, matched int
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		total++
+		if reLogLine.MatchString(line) {
+			matched++
+		}
+	}
+	return matched >= 2 && matched*2 > total
+}
+
+// tableCells splits lines into a table's rows of cells, by delimiter or
+// else by the runs of spaces between columns. Returns nil when the lines
+// aren't a table.
+func tableCells(lines []string) [][]string {
+	if rows := delimitedCells(lines); rows != nil {
+		return rows
+	}
+	return columnCells(lines)
+}
+
+// delimitedCells splits lines into cells when every line has the same
+// number (two or more) of pipe-, tab-, or comma-separated cells, and there
+// are at least two rows (three for commas, which prose has too). Separator
+// rows and a SQL client's row count are dropped. Returns nil otherwise.
+func delimitedCells(lines []string) [][]string {
+	if n := len(lines); n > 0 && reRowCount.MatchString(strings.TrimSpace(lines[n-1])) {
+		lines = lines[:n-1]
+	}
+	var first string
+	for _, line := range lines {
+		if !reTableRule.MatchString(strings.TrimSpace(line)) {
+			first = line
+			break
+		}
+	}
+	switch {
+	case strings.Contains(first, "|"):
+		return splitCells(lines, "|")
+	case strings.Contains(first, "\t"):
+		return splitCells(lines, "\t")
+	case strings.Contains(first, ","):
+		return csvCells(lines)
+	}
+	return nil
+}
+
+// splitCells splits lines at sep, as delimitedCells describes.
+func splitCells(lines []string, sep string) [][]string {
+	var rows [][]string
+	cols := 0
+	for _, line := range lines {
+		if sep == "|" && reTableRule.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		if sep == "|" {
+			line = strings.TrimSpace(line)
+			line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+		}
+		cells := strings.Split(line, sep)
+		if len(cells) < 2 || (cols != 0 && len(cells) != cols) {
+			return nil
+		}
+		cols = len(cells)
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) < 2 {
+		return nil
+	}
+	return rows
+}
+
+// csvCells reads lines as CSV, quoted fields included. Every record must
+// have the same number of fields; two fields a line split at ", " is more
+// likely prose than data.
+func csvCells(lines []string) [][]string {
+	r := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+	rows, err := r.ReadAll()
+	if err != nil || len(rows) < 3 || len(rows[0]) < 2 {
+		return nil
+	}
+	if len(rows[0]) == 2 && strings.Contains(strings.Join(lines, "\n"), ", ") {
+		return nil
+	}
+	return rows
+}
+
+// columnCells splits lines at runs of spaces, for output laid out in
+// columns: ls -l, ps, df, kubectl get. The first line sets the number of
+// columns (three or more); a longer line's extra words stay in its last
+// cell, as a file name or command with spaces does. Words can't tell
+// columns from prose, so besides three rows it takes a column other than
+// the last that holds a number on every row past the first. Returns nil
+// otherwise.
+func columnCells(lines []string) [][]string {
+	if len(lines) > 0 && reLsTotal.MatchString(lines[0]) {
+		lines = lines[1:]
+	}
+	if len(lines) < 3 {
+		return nil
+	}
+	var rows [][]string
+	cols := 0
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if cols == 0 {
+			cols = len(fields)
+		}
+		if cols < 3 || len(fields) < cols {
+			return nil
+		}
+		if len(fields) > cols {
+			fields = append(fields[:cols-1], strings.Join(fields[cols-1:], " "))
+		}
+		rows = append(rows, fields)
+	}
+	for c := range cols - 1 {
+		if numericColumn(rows[1:], c) {
+			return rows
+		}
+	}
+	return nil
+}
+
+// numericColumn reports whether column c holds a number on every row.
+func numericColumn(rows [][]string, c int) bool {
+	for _, row := range rows {
+		if !reNumeric.MatchString(row[c]) {
+			return false
+		}
+	}
+	return len(rows) > 0
+}
+
+// tableHeader reports whether a table's first row names its columns: it
+// does unless it holds a number in a column that is numeric throughout.
+func tableHeader(rows [][]string) bool {
+	if len(rows) < 2 {
+		return false
+	}
+	for c := range rows[0] {
+		if numericColumn(rows, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderToolResult renders a tool result with the renderer its detected
+// kind calls for, wrapped to wrapWidth. Falls back to dim text. Base64
+// blobs are abbreviated unless keepBase64 is set, and runs too wide to wrap
+// at a space are hard-broken first.
+func (m model) renderToolResult(text string, wrapWidth int) string {
+	if !m.cfg.KeepBase64 {
+		text = abbreviateBase64(text)
+	}
+	kind := detectResultKind(text)
+	// A table lays itself out to the width, scrolling sideways if wider.
+	if kind == resultTable {
+		return renderTable(tableCells(strings.Split(strings.TrimSpace(text), "\n")), wrapWidth, m.tableScroll)
+	}
+	text, _ = breakLongRuns(text, wrapWidth)
+	switch kind {
+	case resultJSON:
+		return m.highlightOrDim(text, wrapWidth)
+	case resultDiff:
+		return lipgloss.NewStyle().Width(wrapWidth).Render(renderDiff(text))
+	case resultLog:
+		return lipgloss.NewStyle().Width(wrapWidth).Render(renderLog(text))
+	}
+	return StyleDim.Width(wrapWidth).Render(text)
+}
+
+// renderDiff colors a unified diff: additions green, removals red, hunk
+// headers in the accent color, file headers bold, context dim.
+func renderDiff(text string) string {
+	add := lipgloss.NewStyle().Foreground(ColorContextOk)
+	del := lipgloss.NewStyle().Foreground(ColorError)
+	hunk := lipgloss.NewStyle().Foreground(ColorAccent)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "diff "):
+			lines[i] = StyleSecondaryBold.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = hunk.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = add.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = del.Render(line)
+		default:
+			lines[i] = StyleDim.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderLog colors log lines by level: errors red, warnings amber, the rest
+// dim.
+func renderLog(text string) string {
+	errStyle := lipgloss.NewStyle().Foreground(ColorError)
+	warnStyle := lipgloss.NewStyle().Foreground(ColorWarning)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case reLogError.MatchString(line):
+			lines[i] = errStyle.Render(line)
+		case reLogWarn.MatchString(line):
+			lines[i] = warnStyle.Render(line)
+		default:
+			lines[i] = StyleDim.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maxTableScroll returns how far ←/→ can scroll: to the last column of the
+// widest table among the detail view's results.
+func (m model) maxTableScroll() int {
+	cols := 0
+	for _, row := range m.detailVisibleRows() {
+		text := strings.TrimSpace(row.item.toolResult)
+		if text == "" || detectResultKind(text) != resultTable {
+			continue
+		}
+		if rows := tableCells(strings.Split(text, "\n")); len(rows) > 0 {
+			cols = max(cols, len(rows[0]))
+		}
+	}
+	return max(cols-1, 0)
+}
+
+// renderTable aligns rows into columns, numbers right-aligned and the
+// first row bold when it's a header. A table wider than width shows the
+// columns from offset on that fit, the last of them cut, and a line saying
+// which are shown; ←/→ in the detail view move offset.
+func renderTable(rows [][]string, width, offset int) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	header := tableHeader(rows)
+	body := rows
+	if header {
+		body = rows[1:]
+	}
+	cols := len(rows[0])
+	widths := make([]int, cols)
+	numeric := make([]bool, cols)
+	for c := range cols {
+		numeric[c] = numericColumn(body, c)
+	}
+	for _, row := range rows {
+		for c, cell := range row {
+			widths[c] = max(widths[c], lipgloss.Width(cell))
+		}
+	}
+
+	// The columns shown: from offset, as many as fit, the last one cut.
+	offset = max(min(offset, cols-1), 0)
+	shown := widths[offset:offset]
+	used := 0
+	for c := offset; c < cols; c++ {
+		gap := 0
+		if c > offset {
+			gap = 2
+		}
+		room := width - used - gap
+		if widths[c] > room {
+			if c == offset || room >= 4 {
+				shown = append(shown, room)
+			}
+			break
+		}
+		shown = append(shown, widths[c])
+		used += gap + widths[c]
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for r, row := range rows {
+		style := StyleDim
+		if header && r == 0 {
+			style = StyleSecondaryBold
+		}
+		cells := make([]string, len(shown))
+		for i, w := range shown {
+			c := offset + i
+			cell := row[c]
+			if lipgloss.Width(cell) > w {
+				cell = parser.Truncate(cell, w)
+			}
+			pad := strings.Repeat(" ", max(w-lipgloss.Width(cell), 0))
+			switch {
+			case numeric[c]:
+				cells[i] = pad + style.Render(cell)
+			case i < len(shown)-1:
+				cells[i] = style.Render(cell) + pad
+			default:
+				cells[i] = style.Render(cell)
+			}
+		}
+		lines = append(lines, strings.Join(cells, "  "))
+	}
+	if last := offset + len(shown); offset > 0 || last < cols || shown[len(shown)-1] < widths[last-1] {
+		lines = append(lines, StyleMuted.Render(fmt.Sprintf("columns %d-%d of %d · ←/→ scroll", offset+1, last, cols)))
+	}
+	return strings.Join(lines, "\n")
+}
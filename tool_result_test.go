@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	"charm.land/lipgloss/v2"
+)
+
+func TestDetectResultKind(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want resultKind
+	}{
+		{"empty", "", resultPlain},
+		{"json object", `{"ok": true, "items": [1, 2]}`, resultJSON},
+		{"json array", "[\n  1,\n  2\n]", resultJSON},
+		{"unified diff", "diff --git a/x.go b/x.go\n--- a/x.go\n+++ b/x.go\n@@ -1,3 +1,3 @@\n package x\n-var a = 1\n+var a = 2", resultDiff},
+		{"hunk without changes", "@@ -1,2 +1,2 @@\n same\n same", resultPlain},
+		{"markdown table", "| Name | Size |\n|------|-----:|\n| a.go | 12 |\n| b.go | 340 |", resultTable},
+		{"tab separated", "PID\tCMD\n12\tgo\n340\tnode", resultTable},
+		{"ragged pipes", "a | b\nc | d | e", resultPlain},
+		{"psql", " id | name\n----+------\n  1 | ann\n  2 | bob\n(2 rows)", resultTable},
+		{"mysql", "+----+------+\n| id | name |\n+----+------+\n|  1 | ann  |\n+----+------+", resultTable},
+		{"csv", "id,name,note\n1,ann,\"likes, commas\"\n2,bob,", resultTable},
+		{"comma prose", "First, read it.\nThen, write it.\nLast, ship.", resultPlain},
+		{"ls -l", "total 16\n-rw-r--r--  1 kyle  staff   120 Jan  2 10:04 a.go\n-rw-r--r--  1 kyle  staff  4096 Jan  2 10:05 my notes.txt\ndrwxr-xr-x  3 kyle  staff    96 Jan  2 10:06 parser", resultTable},
+		{"ps", "  PID TTY          TIME CMD\n  101 pts/0    00:00:01 bash\n  202 pts/0    00:00:00 ps", resultTable},
+		{"spaced prose", "the quick brown fox\njumps over the lazy\ndog and then some", resultPlain},
+		{"log lines", "2025-01-15 10:00:01 INFO starting\n2025-01-15 10:00:02 WARN slow\n2025-01-15 10:00:03 ERROR failed", resultLog},
+		{"level prefixes", "[INFO] build\n[ERROR] test failed\nsummary", resultLog},
+		{"prose", "Ran 12 tests.\nAll passed.", resultPlain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectResultKind(tt.text); got != tt.want {
+				t.Errorf("detectResultKind = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	rows := tableCells([]string{"| Name | Size |", "|---|---|", "| a.go | 12 |", "| main_test.go | 340 |"})
+	lines := strings.Split(plain(renderTable(rows, 80, 0)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3 (separator row dropped):\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	// The numeric column is right-aligned: every row ends at the same width.
+	for _, line := range lines[1:] {
+		if len(line) != len(lines[0]) {
+			t.Errorf("row %q is %d wide, want %d", line, len(line), len(lines[0]))
+		}
+	}
+}
+
+func TestRenderTable_ScrollsWideTables(t *testing.T) {
+	rows := [][]string{
+		{"alpha", "bravo", "charlie", "delta"},
+		{"aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc", "dddddddddd"},
+	}
+	got := plain(renderTable(rows, 30, 0))
+	if !strings.Contains(got, "columns 1-3 of 4") {
+		t.Errorf("no scroll hint for a table wider than the view:\n%s", got)
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if w := lipgloss.Width(line); w > 30 {
+			t.Errorf("line %q is %d wide, want at most 30", line, w)
+		}
+	}
+
+	got = plain(renderTable(rows, 30, 2))
+	if strings.Contains(got, "alpha") || !strings.Contains(got, "charlie") || !strings.Contains(got, "columns 3-4 of 4") {
+		t.Errorf("offset 2 should show the last two columns:\n%s", got)
+	}
+	if got := plain(renderTable(rows, 80, 0)); strings.Contains(got, "scroll") {
+		t.Errorf("a table that fits has no scroll hint:\n%s", got)
+	}
+}
+
+func TestTableCells_LsLong(t *testing.T) {
+	rows := tableCells([]string{
+		"total 16",
+		"-rw-r--r--  1 kyle  staff   120 Jan  2 10:04 a.go",
+		"-rw-r--r--  1 kyle  staff  4096 Jan  2 10:05 my notes.txt",
+		"drwxr-xr-x  3 kyle  staff    96 Jan  2 10:06 parser",
+	})
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3 (total line dropped)", len(rows))
+	}
+	if got := rows[1][len(rows[1])-1]; got != "my notes.txt" {
+		t.Errorf("last cell = %q, want the file name with its space", got)
+	}
+	if tableHeader(rows) {
+		t.Error("ls -l has no header row")
+	}
+}
+
+func TestDetailTableScroll(t *testing.T) {
+	m := detailModel(message{role: "claude", items: []displayItem{{
+		itemType:   parser.ItemToolCall,
+		toolName:   "Bash",
+		toolResult: "a,b,c\n1,2,3\n4,5,6",
+	}}})
+	for range 5 {
+		result, _ := m.Update(key("right"))
+		m = asModel(result)
+	}
+	if m.tableScroll != 2 {
+		t.Errorf("tableScroll = %d, want 2 (the last column)", m.tableScroll)
+	}
+	result, _ := m.Update(key("left"))
+	m = asModel(result)
+	if m.tableScroll != 1 {
+		t.Errorf("tableScroll = %d after left, want 1", m.tableScroll)
+	}
+	result, _ = m.Update(key("q"))
+	m = asModel(result)
+	if m.tableScroll != 0 {
+		t.Errorf("tableScroll = %d after leaving the detail view, want 0", m.tableScroll)
+	}
+}
+
+func TestRenderToolResult_FallsBackToDim(t *testing.T) {
+	m := testModel()
+	text := "plain output\nsecond line"
+	if got := m.renderToolResult(text, 80); !strings.Contains(got, "plain output") {
+		t.Errorf("renderToolResult = %q, want the text", got)
+	}
+}
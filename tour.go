@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// appState is what tail-claude remembers between runs that isn't a
+// preference: kept apart from config.json, which is the user's to edit.
+type appState struct {
+	TourOffered bool               `json:"tourOffered,omitempty"` // the first-run tour has been shown once
+	Resume      map[string]uiState `json:"resume,omitempty"`      // where the last run on each session left the list, by session path
+}
+
+// maxResumePoints bounds the sessions whose resume points are kept; the
+// ones saved longest ago go first.
+const maxResumePoints = 200
+
+// statePath returns the state file's location, next to the config.
+func statePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "tail-claude", "state.json")
+}
+
+// loadState reads the state at path. ok is false when there is no state
+// file, which marks a first run.
+func loadState(path string) (st appState, ok bool) {
+	if path == "" {
+		return st, true // nowhere to keep state: never treat a run as the first
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return st, false
+	}
+	if err == nil {
+		_ = json.Unmarshal(data, &st)
+	}
+	return st, true
+}
+
+// saveState writes the state to path, creating the parent directory if
+// needed. The file is replaced atomically, since the runs on different
+// sessions all write it.
+func saveState(path string, st appState) error {
+	if path == "" {
+		return errors.New("no config directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// tourStep is one stop of the onboarding tour: a view opened on the loaded
+// session, and a note on what it shows and the keys it takes.
+type tourStep struct {
+	title string
+	show  func(m *model)
+	body  func(m model) string
+}
+
+// tourSteps walks from the message list through the views most sessions
+// use. Each step opens its view for real, so the tour shows this session
+// rather than a mock-up.
+var tourSteps = []tourStep{
+	{
+		title: "Welcome to tail-claude",
+		show:  showTourList,
+		body: func(m model) string {
+			return fmt.Sprintf("This session has %s. A short tour of the views follows, using it; "+
+				"esc closes the tour at any point and T replays it from the list.", pluralCount(len(m.messages), "message"))
+		},
+	},
+	{
+		title: "Message list",
+		show:  showTourList,
+		body: func(model) string {
+			return "Each card is a prompt or a Claude turn. j/k moves between them, tab expands one, " +
+				"e/c expand or collapse every turn, and G jumps to the newest. New messages stream in while the session runs."
+		},
+	},
+	{
+		title: "Detail view",
+		show: func(m *model) {
+			m.view = viewDetail
+			m.resetDetailState()
+			m.traceMsg = nil
+			m.savedDetail = nil
+			m.computeDetailMaxScroll()
+		},
+		body: func(model) string {
+			return "enter on a message opens it: thinking, tool calls, and output, one row each. " +
+				"j/k moves between rows, tab expands one, and enter on a subagent opens its trace. esc goes back."
+		},
+	},
+	{
+		title: "Outline",
+		show:  func(m *model) { m.openOutline() },
+		body: func(m model) string {
+			return fmt.Sprintf("o lists the session turn by turn (%s here) with a digest of each; enter jumps to a turn.",
+				pluralCount(len(buildOutline(m.messages)), "turn"))
+		},
+	},
+	{
+		title: "Files",
+		show: func(m *model) {
+			m.filesCursor = 0
+			m.filesScroll = 0
+			m.view = viewFiles
+		},
+		body: func(model) string {
+			return "F lists every file read, edited, or written, by the main agent and its subagents."
+		},
+	},
+	{
+		title: "Search",
+		show:  func(m *model) { m.openSearch() },
+		body: func(model) string {
+			return "/ searches the session's text; tab in the prompt adds subagent traces. " +
+				"From the session picker (q), / searches every session in the project."
+		},
+	},
+	{
+		title: "That's it",
+		show:  showTourList,
+		body: func(model) string {
+			return "? shows the keys of whichever view you're in, and q opens the picker for the project's other sessions. " +
+				"T replays this tour."
+		},
+	},
+}
+
+// showTourList returns to the message list.
+func showTourList(m *model) {
+	m.view = viewList
+	m.layoutList()
+	m.ensureCursorVisible()
+}
+
+// startTour opens the tour at its first step.
+func (m *model) startTour() {
+	m.touring = true
+	m.tourStep = 0
+	tourSteps[0].show(m)
+}
+
+// endTour closes the tour, back on the message list.
+func (m *model) endTour() {
+	m.touring = false
+	m.searchInput = false
+	showTourList(m)
+}
+
+// updateTour handles keys while the tour is open; it takes them all, so the
+// view under it only changes when the tour moves on.
+func (m model) updateTour(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "escape", "q":
+		m.endTour()
+	case "right", "l", "n", "enter", "space":
+		if m.tourStep == len(tourSteps)-1 {
+			m.endTour()
+			return m, nil
+		}
+		m.tourStep++
+		tourSteps[m.tourStep].show(&m)
+	case "left", "h", "p", "backspace":
+		if m.tourStep > 0 {
+			m.tourStep--
+			tourSteps[m.tourStep].show(&m)
+		}
+	}
+	return m, nil
+}
+
+// withTourOverlay draws the current step's note over the bottom of the
+// rendered view, above the footer.
+func (m model) withTourOverlay(content string) string {
+	step := tourSteps[m.tourStep]
+	boxWidth := max(min(72, m.width-4), 30)
+	next := "next"
+	if m.tourStep == len(tourSteps)-1 {
+		next = "done"
+	}
+	hints := StyleAccentBold.Render("→") + " " + StyleDim.Render(next)
+	if m.tourStep > 0 {
+		hints = StyleAccentBold.Render("←") + " " + StyleDim.Render("back") + "  " + hints
+	}
+	hints += "  " + StyleAccentBold.Render("esc") + " " + StyleDim.Render("close")
+	title := StyleAccentBold.Render(step.title) + " " +
+		StyleDim.Render(fmt.Sprintf("%d/%d", m.tourStep+1, len(tourSteps)))
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorAccent).
+		Padding(0, 1).
+		Width(boxWidth).
+		Render(title + "\n\n" + StyleSecondary.Render(step.body(m)) + "\n\n" + hints)
+	height := max(m.height, lipgloss.Height(content))
+	x := max((m.width-lipgloss.Width(box))/2, 0)
+	y := max(m.height-m.footerHeight()-lipgloss.Height(box), 0)
+	return lipgloss.NewCanvas(m.width, height).
+		Compose(lipgloss.NewCompositor(
+			lipgloss.NewLayer(content),
+			lipgloss.NewLayer(box).X(x).Y(y).Z(1),
+		)).
+		Render()
+}
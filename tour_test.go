@@ -0,0 +1,66 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTour(t *testing.T) {
+	m := testModel()
+	result, _ := m.Update(key("T"))
+	m = asModel(result)
+	if !m.touring || m.tourStep != 0 || m.view != viewList {
+		t.Fatalf("T: touring = %v, step = %d, view = %d", m.touring, m.tourStep, m.view)
+	}
+	out := m.View().Content
+	if !strings.Contains(out, "Welcome to tail-claude") || !strings.Contains(out, "3 messages") {
+		t.Errorf("welcome step missing from the view:\n%s", out)
+	}
+	if !strings.Contains(out, "Hello, world") {
+		t.Errorf("session hidden under the tour:\n%s", out)
+	}
+
+	// Each step opens its view on the loaded session; keys go to the tour.
+	wantViews := []viewState{viewList, viewList, viewDetail, viewOutline, viewFiles, viewSearch, viewList}
+	for i := 1; i < len(tourSteps); i++ {
+		result, _ = m.Update(key("n"))
+		m = asModel(result)
+		if m.tourStep != i || m.view != wantViews[i] {
+			t.Errorf("step %d: tourStep = %d, view = %d; want view %d", i, m.tourStep, m.view, wantViews[i])
+		}
+	}
+	result, _ = m.Update(key("p"))
+	m = asModel(result)
+	if m.view != viewSearch {
+		t.Errorf("back: view = %d, want search", m.view)
+	}
+	result, _ = m.Update(key("x")) // not a tour key: swallowed, not typed into search
+	m = asModel(result)
+	if m.searchQuery != "" {
+		t.Errorf("search query = %q, want keys kept from the view under the tour", m.searchQuery)
+	}
+
+	result, _ = m.Update(key("esc"))
+	m = asModel(result)
+	if m.touring || m.view != viewList || m.searchInput {
+		t.Errorf("esc: touring = %v, view = %d, searchInput = %v; want back on the list", m.touring, m.view, m.searchInput)
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail-claude", "state.json")
+	if _, ok := loadState(path); ok {
+		t.Fatal("loadState without a file: ok = true, want a first run")
+	}
+	if err := saveState(path, appState{TourOffered: true}); err != nil {
+		t.Fatal(err)
+	}
+	st, ok := loadState(path)
+	if !ok || !st.TourOffered {
+		t.Errorf("loadState after save = %+v, %v", st, ok)
+	}
+	if _, ok := loadState(""); !ok {
+		t.Error("loadState with no config dir: ok = false, want never a first run")
+	}
+}
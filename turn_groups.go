@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	"charm.land/lipgloss/v2"
+)
+
+// listTurn is one round trip in the grouped list (#): a prompt and the
+// Claude, system, and error messages that follow it, up to the next prompt.
+type listTurn struct {
+	number      int // 1-based, counting turns evicted by --window
+	first, last int // indexes in m.messages
+}
+
+// numberTurns sets each message's turn: the count of prompts up to and
+// including it, so messages before the first prompt are in turn 0.
+func numberTurns(msgs []message) {
+	turn := 0
+	for i := range msgs {
+		if msgs[i].role == RoleUser {
+			turn++
+		}
+		msgs[i].turn = turn
+	}
+}
+
+// groupingTurns reports whether the list is drawn as turn containers:
+// grouping is on and the list is in session order. A sorted list has no
+// round trips to show.
+func (m model) groupingTurns() bool {
+	return m.turnGroups && m.listSort == sortChronological
+}
+
+// listTurns returns the turns of the list as shown, or nil when it isn't
+// grouped. Messages before the first prompt belong to no turn.
+func (m model) listTurns() []listTurn {
+	if !m.groupingTurns() {
+		return nil
+	}
+	var turns []listTurn
+	for i, msg := range m.messages {
+		if msg.turn == 0 {
+			continue
+		}
+		number := msg.turn + m.evictedTurns
+		if n := len(turns); n > 0 && turns[n-1].number == number {
+			turns[n-1].last = i
+			continue
+		}
+		turns = append(turns, listTurn{number: number, first: i, last: i})
+	}
+	return turns
+}
+
+// turnIndex maps each of n messages to its turn's index in turns, or -1.
+func turnIndex(turns []listTurn, n int) []int {
+	at := make([]int, n)
+	for i := range at {
+		at[i] = -1
+	}
+	for t, turn := range turns {
+		for i := turn.first; i <= turn.last; i++ {
+			at[i] = t
+		}
+	}
+	return at
+}
+
+// foldedTurnAt returns the folded turn holding message i, if any.
+func (m model) foldedTurnAt(i int) (listTurn, bool) {
+	for _, t := range m.listTurns() {
+		if i >= t.first && i <= t.last {
+			return t, m.foldedTurns[t.number]
+		}
+	}
+	return listTurn{}, false
+}
+
+// toggleCursorTurn folds the turn under the cursor to its header line, or
+// unfolds it. Folding moves the cursor to the turn's first message. Returns
+// false when the cursor is in no turn.
+func (m *model) toggleCursorTurn() bool {
+	for _, t := range m.listTurns() {
+		if m.cursor < t.first || m.cursor > t.last {
+			continue
+		}
+		if m.foldedTurns[t.number] {
+			delete(m.foldedTurns, t.number)
+		} else {
+			m.foldedTurns[t.number] = true
+			m.cursor = t.first
+		}
+		return true
+	}
+	return false
+}
+
+// turnStats renders a turn's size for its header: "4 messages · 12.3k tok".
+func (m model) turnStats(t listTurn) string {
+	tokens := 0
+	for _, msg := range m.messages[t.first : t.last+1] {
+		tokens += msg.tokensRaw
+	}
+	stats := pluralCount(t.last-t.first+1, "message")
+	if tokens > 0 {
+		stats += " · " + formatTokens(tokens) + " tok"
+	}
+	return stats
+}
+
+// turnRailStyle colors a turn container's border: accent while the cursor
+// is in the turn, muted otherwise.
+func turnRailStyle(active bool) lipgloss.Style {
+	if active {
+		return lipgloss.NewStyle().Foreground(ColorAccent)
+	}
+	return StyleMuted
+}
+
+// renderTurnHeader renders the top edge of an unfolded turn's container:
+// "╭─ ▾ Turn 7 ──────── 4 messages · 12.3k tok ─╮".
+func (m model) renderTurnHeader(t listTurn, width int, active bool) string {
+	rail := turnRailStyle(active)
+	title := chevron(true) + " " + StyleAccentBold.Render(fmt.Sprintf("Turn %d", t.number))
+	stats := StyleDim.Render(m.turnStats(t))
+	fill := max(width-lipgloss.Width(title)-lipgloss.Width(stats)-8, 1)
+	return rail.Render("╭─") + " " + title + " " + rail.Render(strings.Repeat(GlyphHRule, fill)) +
+		" " + stats + " " + rail.Render("─╮")
+}
+
+// renderFoldedTurn renders a folded turn as one line: its number, the first
+// line of its prompt, and its size.
+func (m model) renderFoldedTurn(t listTurn, width int, active bool) string {
+	left := selectionIndicator(active) + chevron(false) + " " +
+		StyleAccentBold.Render(fmt.Sprintf("Turn %d", t.number)) + "  "
+	right := StyleDim.Render(m.turnStats(t))
+	prompt, _, _ := strings.Cut(strings.TrimSpace(m.messages[t.first].content), "\n")
+	textStyle := StyleSecondary
+	if active {
+		textStyle = StylePrimaryBold
+	}
+	room := max(width-lipgloss.Width(left)-lipgloss.Width(right)-2, 10)
+	return spaceBetween(left+textStyle.Render(parser.Truncate(prompt, room)), right, width)
+}
+
+// railTurnPart frames a message rendered at width-4 inside its turn's
+// container: a rail on either side of each line, the header above the
+// turn's first message, and the bottom edge below its last.
+func (m model) railTurnPart(r rendered, t listTurn, i, width int, active bool) rendered {
+	rail := turnRailStyle(active)
+	inner := width - 4
+	lines := strings.Split(r.content, "\n")
+	for j, line := range lines {
+		pad := max(inner-lipgloss.Width(line), 0)
+		lines[j] = rail.Render("│") + " " + line + strings.Repeat(" ", pad) + " " + rail.Render("│")
+	}
+	if i == t.first {
+		lines = append([]string{m.renderTurnHeader(t, width, active)}, lines...)
+	}
+	if i == t.last {
+		lines = append(lines, rail.Render("╰"+strings.Repeat(GlyphHRule, width-2)+"╯"))
+	}
+	return rendered{content: strings.Join(lines, "\n"), lines: len(lines)}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// turnsModel is a grouped list of two turns after a leading system message.
+func turnsModel() model {
+	msgs := []message{
+		{role: RoleSystem, content: "session start", timestamp: "09:59:00 AM"},
+		userMsg("first prompt"),
+		claudeMsg(),
+		userMsg("second prompt"),
+		claudeMsg(func(m *message) { m.tokensRaw = 1500 }),
+		{role: RoleSystem, content: "stderr", timestamp: "10:00:05 AM"},
+	}
+	m := initialModel(msgs, true)
+	m.width = 100
+	m.height = 60
+	m.turnGroups = true
+	m.layoutList()
+	return m
+}
+
+func TestListTurns(t *testing.T) {
+	m := turnsModel()
+	m.evictedTurns = 4
+	turns := m.listTurns()
+	want := []listTurn{{number: 5, first: 1, last: 2}, {number: 6, first: 3, last: 5}}
+	if len(turns) != len(want) {
+		t.Fatalf("listTurns = %+v, want %+v", turns, want)
+	}
+	for i := range want {
+		if turns[i] != want[i] {
+			t.Errorf("turn %d = %+v, want %+v", i, turns[i], want[i])
+		}
+	}
+	if got := m.turnStats(turns[1]); got != "3 messages · 1.5k tok" {
+		t.Errorf("turnStats = %q", got)
+	}
+
+	m.listSort = sortTokens
+	if turns := m.listTurns(); turns != nil {
+		t.Errorf("sorted list: listTurns = %+v, want nil", turns)
+	}
+}
+
+func TestGroupedListFoldsTurns(t *testing.T) {
+	m := turnsModel()
+	view := plain(strings.Join(m.listParts, "\n"))
+	for _, want := range []string{"Turn 1", "Turn 2", "3 messages", "╰"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("grouped list missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(strings.SplitN(view, "\n", 2)[0], "╭") {
+		t.Error("the message before the first prompt should sit outside any turn")
+	}
+
+	// Fold turn 1 from its Claude message: the cursor moves to its header.
+	m.cursor = 2
+	result, _ := m.Update(key("space"))
+	m = asModel(result)
+	if !m.foldedTurns[1] || m.cursor != 1 {
+		t.Fatalf("after space: folded %v, cursor %d, want turn 1 folded at 1", m.foldedTurns, m.cursor)
+	}
+	if m.messageLines[1] != 1 || m.lineOffsets[2] != m.lineOffsets[1] {
+		t.Errorf("folded turn: lines %v offsets %v, want one shared header line", m.messageLines, m.lineOffsets)
+	}
+	if got := plain(m.listParts[1]); !strings.Contains(got, "Turn 1") || !strings.Contains(got, "first prompt") {
+		t.Errorf("folded header = %q", got)
+	}
+
+	// j steps over the folded turn; k lands back on its header.
+	result, _ = m.Update(key("j"))
+	m = asModel(result)
+	if m.cursor != 3 {
+		t.Errorf("j from a folded turn: cursor %d, want 3", m.cursor)
+	}
+	result, _ = m.Update(key("k"))
+	m = asModel(result)
+	if m.cursor != 1 {
+		t.Errorf("k into a folded turn: cursor %d, want its header at 1", m.cursor)
+	}
+
+	// tab unfolds it; # turns grouping off.
+	result, _ = m.Update(key("tab"))
+	m = asModel(result)
+	if m.foldedTurns[1] {
+		t.Error("tab on a folded turn should unfold it")
+	}
+	result, _ = m.Update(key("#"))
+	m = asModel(result)
+	if m.turnGroups || strings.Contains(plain(strings.Join(m.listParts, "\n")), "Turn 1") {
+		t.Error("# should turn grouping off")
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// defaultTurnSummaryCommand is the Claude CLI call a turn is summarized
+// with when the config names none.
+const defaultTurnSummaryCommand = "claude -p"
+
+// defaultTurnSummaryPrompt is sent ahead of the turn when the config gives
+// no prompt.
+const defaultTurnSummaryPrompt = "Below is one turn of a Claude Code session: the user's prompt and what Claude did in answer. " +
+	"Summarize it in a few sentences: what was asked, what Claude did, and how it ended. " +
+	"Name any errors or open questions. Reply with the summary only."
+
+// defaultTurnSummaryTimeout bounds a turn summary run: a Claude CLI call
+// takes far longer than a local summarizer.
+const defaultTurnSummaryTimeout = 2 * time.Minute
+
+// turnSummaryConfig names the command E pipes a turn through, by default
+// the Claude CLI in print mode. Set to {} to use the defaults.
+type turnSummaryConfig struct {
+	Command string `json:"command,omitempty"` // run with sh -c, the prompt and turn on stdin; empty uses "claude -p"
+	Prompt  string `json:"prompt,omitempty"`  // instructions sent ahead of the turn; empty asks for a few sentences
+	Timeout string `json:"timeout,omitempty"` // e.g. "5m"; empty uses 2m
+}
+
+// validateTurnSummary reports a bad timeout.
+func validateTurnSummary(c *turnSummaryConfig) error {
+	if c == nil || c.Timeout == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+		return fmt.Errorf("timeout %q is not a positive duration", c.Timeout)
+	}
+	return nil
+}
+
+// command returns the configured command or the default.
+func (c *turnSummaryConfig) command() string {
+	if cmd := strings.TrimSpace(c.Command); cmd != "" {
+		return cmd
+	}
+	return defaultTurnSummaryCommand
+}
+
+// prompt returns the configured prompt or the default.
+func (c *turnSummaryConfig) prompt() string {
+	if p := strings.TrimSpace(c.Prompt); p != "" {
+		return p
+	}
+	return defaultTurnSummaryPrompt
+}
+
+// timeout returns the configured run limit or the default.
+func (c *turnSummaryConfig) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultTurnSummaryTimeout
+}
+
+// turnSummaryPopup is the summary shown over the list (E).
+type turnSummaryPopup struct {
+	turn   int    // number, counting turns evicted by --window
+	key    string // the turn's content hash, into m.turnSummaries
+	cached bool   // read from the cache rather than run now
+	scroll int
+}
+
+// turnSummaryMsg reports a finished summary of the turn hashed to key.
+type turnSummaryMsg struct {
+	key    string
+	text   string
+	err    error
+	cached bool
+}
+
+// turnSummaryCache is the cache file of one session: summaries by the
+// content hash of their turn, so a turn still running when summarized is
+// summarized afresh once it has grown.
+type turnSummaryCache map[string]turnSummaryEntry
+
+// turnSummaryEntry is one cached summary.
+type turnSummaryEntry struct {
+	Turn    int       `json:"turn"`
+	Summary string    `json:"summary"`
+	Created time.Time `json:"created"`
+}
+
+// turnSummaryCachePath returns the cache file for session under the user
+// cache directory, or "" when it can't be determined.
+func turnSummaryCachePath(session string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil || session == "" {
+		return ""
+	}
+	sum := sha1.Sum([]byte(session))
+	return filepath.Join(dir, "tail-claude", "turn-summaries", hex.EncodeToString(sum[:])+".json")
+}
+
+// readTurnSummaryCache reads a session's cache file; a missing or
+// unreadable one is empty.
+func readTurnSummaryCache(path string) turnSummaryCache {
+	cache := make(turnSummaryCache)
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// turnSummaryWrites serializes writeTurnSummary: two summaries finishing
+// together would otherwise each read the file, add one entry, and the
+// second rename would drop the first's.
+var turnSummaryWrites sync.Mutex
+
+// writeTurnSummary adds a summary to a session's cache file, replacing the
+// file whole so a reader never sees it half written.
+func writeTurnSummary(path, key string, entry turnSummaryEntry) error {
+	turnSummaryWrites.Lock()
+	defer turnSummaryWrites.Unlock()
+
+	cache := readTurnSummaryCache(path)
+	cache[key] = entry
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// cursorTurn returns the messages of the turn under the cursor, as
+// positions in m.messages, and its number. ok is false before the first
+// prompt.
+func (m model) cursorTurn() (first, last, number int, ok bool) {
+	if m.cursor >= len(m.messages) || m.messages[m.cursor].turn == 0 {
+		return 0, 0, 0, false
+	}
+	turn := m.messages[m.cursor].turn
+	first, last = m.cursor, m.cursor
+	for first > 0 && m.messages[first-1].turn == turn {
+		first--
+	}
+	for last < len(m.messages)-1 && m.messages[last+1].turn == turn {
+		last++
+	}
+	return first, last, turn + m.evictedTurns, true
+}
+
+// turnTranscript returns the messages first through last as the session
+// export writes them.
+func (m model) turnTranscript(first, last int) string {
+	var b strings.Builder
+	writeMessagesMarkdown(&b, m.messages[first:last+1])
+	return strings.TrimSpace(b.String()) + "\n"
+}
+
+// summarizeTurn opens the summary of the turn under the cursor, running the
+// turn summary command on it unless it was summarized before in this or an
+// earlier run. fresh runs it again regardless.
+func (m *model) summarizeTurn(fresh bool) tea.Cmd {
+	c := m.cfg.TurnSummary
+	if c == nil {
+		m.flashStatus = `No turn summaries configured ("turnSummary": {} in config uses claude -p)`
+		return flashClearCmd()
+	}
+	first, last, number, ok := m.cursorTurn()
+	if !ok {
+		m.flashStatus = "No turn here: the cursor is before the first prompt"
+		return flashClearCmd()
+	}
+	transcript := m.turnTranscript(first, last)
+	sum := sha256.Sum256([]byte(transcript))
+	key := hex.EncodeToString(sum[:])
+	m.turnPopup = &turnSummaryPopup{turn: number, key: key}
+	if m.turnSummaries == nil {
+		m.turnSummaries = make(map[string]itemSummary)
+	}
+	s, seen := m.turnSummaries[key]
+	if s.running || (seen && s.err == nil && !fresh) {
+		return nil
+	}
+	m.turnSummaries[key] = itemSummary{running: true}
+	return turnSummaryCmd(c, key, number, transcript, turnSummaryCachePath(m.sessionPath), !fresh, !m.readOnly)
+}
+
+// turnSummaryCmd summarizes a turn off the UI goroutine: from the cache
+// file at cachePath when useCache and it has one, else by piping the
+// prompt and transcript through the command and, when store, caching what
+// it printed.
+func turnSummaryCmd(c *turnSummaryConfig, key string, turn int, transcript, cachePath string, useCache, store bool) tea.Cmd {
+	return func() tea.Msg {
+		if useCache && cachePath != "" {
+			if e, ok := readTurnSummaryCache(cachePath)[key]; ok {
+				return turnSummaryMsg{key: key, text: e.Summary, cached: true}
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout())
+		defer cancel()
+		out, err := runFilter(ctx, c.command(), c.prompt()+"\n\n"+transcript)
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", c.timeout())
+		}
+		if err != nil {
+			return turnSummaryMsg{key: key, err: err}
+		}
+		text := strings.TrimSpace(string(out))
+		if text == "" {
+			return turnSummaryMsg{key: key, err: errors.New("printed nothing")}
+		}
+		if store && cachePath != "" {
+			if err := writeTurnSummary(cachePath, key, turnSummaryEntry{Turn: turn, Summary: text, Created: time.Now()}); err != nil {
+				logUIError("turn summary cache write failed", err, "path", cachePath)
+			}
+		}
+		return turnSummaryMsg{key: key, text: text}
+	}
+}
+
+// applyTurnSummary records a finished summary.
+func (m *model) applyTurnSummary(msg turnSummaryMsg) {
+	if m.turnSummaries == nil {
+		m.turnSummaries = make(map[string]itemSummary)
+	}
+	m.turnSummaries[msg.key] = itemSummary{text: msg.text, err: msg.err}
+	if m.turnPopup != nil && m.turnPopup.key == msg.key {
+		m.turnPopup.cached = msg.cached
+	}
+	if msg.err != nil {
+		logUIError("turn summary failed", msg.err)
+	}
+}
+
+// updateTurnPopup handles keys while the summary popup is open: j/k
+// scroll, r runs the summary again, y copies it, and esc, q, or E close it.
+func (m model) updateTurnPopup(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	p := *m.turnPopup
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "escape", "E":
+		m.turnPopup = nil
+		return m, nil
+	case "j", "down":
+		p.scroll = min(p.scroll+1, m.turnPopupMaxScroll())
+	case "k", "up":
+		p.scroll = max(p.scroll-1, 0)
+	case "r":
+		if s := m.turnSummaries[p.key]; !s.running {
+			cmd := m.summarizeTurn(true)
+			return m, cmd
+		}
+	case "y":
+		if s := m.turnSummaries[p.key]; s.text != "" {
+			m.flashStatus = fmt.Sprintf("Copied the summary of turn %d", p.turn)
+			return m, tea.Batch(tea.SetClipboard(s.text), flashClearCmd())
+		}
+	}
+	m.turnPopup = &p
+	return m, nil
+}
+
+// turnPopupWidth returns the popup's text width.
+func (m model) turnPopupWidth() int {
+	return max(min(90, m.width-4), 30) - 4
+}
+
+// turnPopupHeight returns the text lines the popup shows at once.
+func (m model) turnPopupHeight() int {
+	return max(m.height-m.footerHeight()-8, 3)
+}
+
+// turnPopupLines renders the popup's text: the summary, or what became of
+// the run.
+func (m model) turnPopupLines() []string {
+	width := m.turnPopupWidth()
+	s := m.turnSummaries[m.turnPopup.key]
+	var text string
+	switch {
+	case s.running:
+		text = StyleMuted.Render("Summarizing with " + m.cfg.TurnSummary.command() + "…")
+	case s.err != nil:
+		text = StyleErrorBold.Render("Turn summary failed") + "\n" + StyleDim.Width(width).Render(s.err.Error())
+	default:
+		text = m.md.renderMarkdown(s.text, width)
+	}
+	return strings.Split(strings.TrimRight(text, "\n"), "\n")
+}
+
+// turnPopupMaxScroll returns how far the popup's text scrolls.
+func (m model) turnPopupMaxScroll() int {
+	return max(len(m.turnPopupLines())-m.turnPopupHeight(), 0)
+}
+
+// withTurnPopup draws the summary popup over content.
+func (m model) withTurnPopup(content string) string {
+	p := m.turnPopup
+	title := StyleAccentBold.Render(fmt.Sprintf("Turn %d summary", p.turn))
+	if p.cached {
+		title += " " + StyleDim.Render("(cached)")
+	}
+	lines := m.turnPopupLines()
+	body := strings.Join(scrollWindow(lines, m.turnPopupHeight(), p.scroll), "\n")
+	hints := StyleAccentBold.Render("r") + " " + StyleDim.Render("rerun") + "  " +
+		StyleAccentBold.Render("y") + " " + StyleDim.Render("copy") + "  " +
+		StyleAccentBold.Render("esc") + " " + StyleDim.Render("close")
+	if len(lines) > m.turnPopupHeight() {
+		hints = StyleAccentBold.Render("j/k") + " " + StyleDim.Render("scroll") + "  " + hints
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorAccent).
+		Padding(0, 1).
+		Width(m.turnPopupWidth() + 4).
+		Render(title + "\n\n" + body + "\n\n" + hints)
+	height := max(m.height, lipgloss.Height(content))
+	x := max((m.width-lipgloss.Width(box))/2, 0)
+	y := max((m.height-m.footerHeight()-lipgloss.Height(box))/2, 0)
+	return lipgloss.NewCanvas(m.width, height).
+		Compose(lipgloss.NewCompositor(
+			lipgloss.NewLayer(content),
+			lipgloss.NewLayer(box).X(x).Y(y).Z(1),
+		)).
+		Render()
+}
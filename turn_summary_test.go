@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSummarizeTurn(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	m := testModel()
+	m.sessionPath = "/tmp/session.jsonl"
+
+	result, _ := m.Update(key("E"))
+	if m = asModel(result); m.turnPopup != nil || !strings.Contains(m.flashStatus, "turnSummary") {
+		t.Fatalf("E unconfigured: popup %v, flash %q", m.turnPopup, m.flashStatus)
+	}
+
+	// The command gets the prompt and then the turn on stdin.
+	m.cfg.TurnSummary = &turnSummaryConfig{Command: `grep -c "Hello, world"`, Prompt: "Sum up."}
+	m.cursor = 1
+	result, cmd := m.Update(key("E"))
+	m = asModel(result)
+	if m.turnPopup == nil || m.turnPopup.turn != 1 || cmd == nil {
+		t.Fatalf("E: popup %+v, cmd %v; want turn 1 summarizing", m.turnPopup, cmd)
+	}
+	if got := plain(m.View().Content); !strings.Contains(got, "Summarizing with grep") {
+		t.Errorf("popup while running = %q", got)
+	}
+	msg := cmd()
+	result, _ = m.Update(msg)
+	m = asModel(result)
+	if s := m.turnSummaries[m.turnPopup.key]; s.text != "1" || s.err != nil {
+		t.Fatalf("summary = %+v, want the command's output", s)
+	}
+	if got := plain(m.View().Content); !strings.Contains(got, "Turn 1 summary") {
+		t.Errorf("popup = %q", got)
+	}
+
+	// Keys go to the popup until it's closed.
+	result, _ = m.Update(key("j"))
+	if m = asModel(result); m.cursor != 1 {
+		t.Errorf("j with the popup open moved the cursor to %d", m.cursor)
+	}
+	result, _ = m.Update(key("esc"))
+	if m = asModel(result); m.turnPopup != nil {
+		t.Fatal("esc should close the popup")
+	}
+
+	// A later run reads the cache instead of running the command.
+	fresh := testModel()
+	fresh.sessionPath = m.sessionPath
+	fresh.cfg.TurnSummary = &turnSummaryConfig{Command: "exit 1"}
+	fresh.cursor = 2
+	_, cmd = fresh.Update(key("E"))
+	if got, ok := cmd().(turnSummaryMsg); !ok || !got.cached || got.text != "1" {
+		t.Errorf("second session run = %+v, want the cached summary", got)
+	}
+}
+
+func TestSummarizeTurnReadOnly(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	m := testModel()
+	m.sessionPath = "/tmp/session.jsonl"
+	m.readOnly = true
+	m.cfg.TurnSummary = &turnSummaryConfig{Command: "echo summary"}
+	_, cmd := m.Update(key("E"))
+	if got := cmd().(turnSummaryMsg); got.text != "summary" {
+		t.Fatalf("summary = %+v", got)
+	}
+	if cache := readTurnSummaryCache(turnSummaryCachePath(m.sessionPath)); len(cache) != 0 {
+		t.Errorf("--read-only cached %d summaries, want none", len(cache))
+	}
+}
+
+func TestWriteTurnSummary_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "turn-summaries", "s.json")
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			if err := writeTurnSummary(path, fmt.Sprint(i), turnSummaryEntry{Turn: i}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if cache := readTurnSummaryCache(path); len(cache) != 20 {
+		t.Errorf("cache has %d summaries, want all 20", len(cache))
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(tmps) != 0 {
+		t.Errorf("temporary files left behind: %v", tmps)
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/kylesnowschwartz/tail-claude/parser"
@@ -10,56 +13,114 @@ import (
 	tea "charm.land/bubbletea/v2"
 )
 
+// toggleExactTokens switches token counts between abbreviated and whole
+// (+) in every view, and lays out the current one again.
+func (m *model) toggleExactTokens() tea.Cmd {
+	exactTokens = !exactTokens
+	m.flashStatus = "Abbreviated token counts"
+	if exactTokens {
+		m.flashStatus = "Exact token counts"
+	}
+	switch m.view {
+	case viewList:
+		m.layoutList()
+	case viewDetail:
+		m.computeDetailMaxScroll()
+	}
+	return flashClearCmd()
+}
+
 // resetDetailState zeroes the detail view cursor, scroll, and expansion maps.
 func (m *model) resetDetailState() {
 	m.detailCursor = 0
 	m.detailScroll = 0
 	m.detailExpanded = make(map[int]bool)
 	m.detailChildExpanded = make(map[visibleRowKey]bool)
+	m.detailMarked = nil
+	m.detailFootnote = ""
+	m.tableScroll = 0
 }
 
 // updateList handles key events in the message list view.
 func (m model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m.filterInput {
+		return m.updateFilterBar(msg)
+	}
 	switch msg.String() {
 	case "ctrl+c":
 		return m, tea.Quit
 	case "q", "esc", "escape", "backspace":
+		// Esc clears a range selection, search highlights, then a sort,
+		// before it leaves the session.
+		if m.rangeActive && (msg.String() == "esc" || msg.String() == "escape") {
+			m.clearRange()
+			m.layoutList()
+			return m, nil
+		}
+		if m.highlightQuery != "" && (msg.String() == "esc" || msg.String() == "escape") {
+			m.highlightQuery = ""
+			return m, nil
+		}
+		if m.listSort != sortChronological && (msg.String() == "esc" || msg.String() == "escape") {
+			m.setListSort(sortChronological)
+			return m, nil
+		}
+		if m.listFilter != nil && (msg.String() == "esc" || msg.String() == "escape") {
+			m.setListFilter(nil)
+			return m, nil
+		}
 		return m, loadPickerSessionsCmd(m.projectDirs, m.sessionCache)
+	case "ctrl+o":
+		return m.toggleAltSession()
 	case "j":
-		if m.cursor < len(m.messages)-1 {
-			m.cursor++
-		}
+		m.moveListCursor(1)
 		m.layoutList()
 		m.ensureCursorVisible()
+		return m, m.rangeChanged()
 	case "k":
-		if m.cursor > 0 {
-			m.cursor--
+		if len(m.messages) > 0 && m.listPos(m.cursor) == 0 {
+			cmd := m.scrollPastTop()
+			return m, cmd
 		}
+		m.moveListCursor(-1)
 		m.layoutList()
 		m.ensureCursorVisible()
+		return m, m.rangeChanged()
 	case "down":
 		m.scroll += 3
 		m.clampListScroll()
 	case "up":
+		if m.scroll == 0 {
+			cmd := m.scrollPastTop()
+			return m, cmd
+		}
 		m.scroll -= 3
 		if m.scroll < 0 {
 			m.scroll = 0
 		}
 	case "G":
 		if len(m.messages) > 0 {
-			m.cursor = len(m.messages) - 1
+			m.cursor = m.listAt(len(m.messages) - 1)
 			m.layoutList()
 			m.ensureCursorVisible()
 		}
+		return m, m.rangeChanged()
+	case "f":
+		// Follow new messages again after moving off the newest.
+		m.followNewest()
 	case "g":
-		m.cursor = 0
+		m.cursor = m.listAt(0)
 		m.scroll = 0
 		m.layoutList()
+		return m, m.rangeChanged()
 	case "tab":
-		// Toggle expand/collapse for Claude and User messages
-		if m.cursor < len(m.messages) {
+		// Toggle expand/collapse for Claude, User, and command messages;
+		// a folded turn unfolds.
+		if _, folded := m.foldedTurnAt(m.cursor); folded {
+			m.toggleCursorTurn()
+		} else if m.cursor < len(m.messages) {
 			role := m.messages[m.cursor].role
-			if role == RoleClaude || role == RoleUser {
+			if role == RoleClaude || role == RoleUser || role == RoleCommand {
 				m.expanded[m.cursor] = !m.expanded[m.cursor]
 			}
 		}
@@ -68,11 +129,7 @@ func (m model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		// Enter detail view for current message
 		if len(m.messages) > 0 {
-			m.view = viewDetail
-			m.resetDetailState()
-			m.traceMsg = nil
-			m.savedDetail = nil
-			m.computeDetailMaxScroll()
+			m.enterDetail()
 		}
 	case "e":
 		// Expand all Claude messages
@@ -92,6 +149,87 @@ func (m model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 		m.layoutList()
 		m.ensureCursorVisible()
+	case "H":
+		// Open the tool visibility menu.
+		m.toolMenuCursor = 0
+		m.toolMenuScroll = 0
+		m.view = viewTools
+	case "/":
+		// Search the session; tab in the prompt adds subagent traces.
+		m.openSearch()
+	case "F":
+		// Open the file report.
+		m.filesCursor = 0
+		m.filesScroll = 0
+		m.view = viewFiles
+	case "x":
+		// Dismiss the tail error banner; the error stays in the debug log.
+		if m.errorBanner != "" {
+			m.dismissErrorBanner()
+			m.layoutList()
+		}
+	case "L":
+		// Reload turns evicted by --window or left unread by a reduced
+		// open, or resume windowing.
+		if m.watcher == nil || !m.fullHistory {
+			cmd := m.loadEarlierHistory()
+			return m, cmd
+		}
+		m.flashStatus = fmt.Sprintf("Keeping the last %s in memory", pluralCount(m.watcher.window, "turn"))
+		m.watcher.requestHistory(false)
+		return m, flashClearCmd()
+	case "b":
+		// Pick a branch of a session forked by /rewind.
+		cmd := m.openBranches()
+		return m, cmd
+	case "D":
+		// Compare the session's edits with the files on disk.
+		return m, m.openDrift()
+	case "M":
+		// Show the CLAUDE.md files the session runs under.
+		cmd := m.openMemory()
+		return m, cmd
+	case "u":
+		// Numbered links in the message under the cursor.
+		if m.cursor < len(m.messages) {
+			cmd := m.openLinks(m.messages[m.cursor])
+			return m, cmd
+		}
+	case "o":
+		// Open the turn outline.
+		m.openOutline()
+	case "T":
+		// Replay the onboarding tour.
+		if len(m.messages) > 0 {
+			m.startTour()
+		}
+	case "z":
+		// Jump to the session's final answer.
+		m.jumpToFinalAnswer()
+	case "Z":
+		// Toggle the compact list: one line per message.
+		m.denseList = !m.denseList
+		m.layoutList()
+		m.ensureCursorVisible()
+	case "#":
+		// Toggle the grouped list: each turn framed in a container.
+		m.turnGroups = !m.turnGroups
+		m.layoutList()
+		m.ensureCursorVisible()
+	case "space":
+		// Fold or unfold the cursor's turn in the grouped list.
+		if m.toggleCursorTurn() {
+			m.layoutList()
+			m.ensureCursorVisible()
+		}
+	case "S":
+		// Sort the list by tokens, duration, or errors, then back.
+		m.cycleListSort()
+	case "=":
+		// Filter the list with an expression: role=claude AND error.
+		m.openFilterBar()
+		m.layoutList()
+		m.clampListScroll()
 	case "s":
 		// Open session picker
 		return m, loadPickerSessionsCmd(m.projectDirs, m.sessionCache)
@@ -106,29 +244,26 @@ func (m model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.scroll = 0
 		}
 	case "t":
-		// Open team task board (only when teams exist).
-		if len(m.teams) > 0 {
+		// Open the task board (only when it has something to show).
+		if m.hasTaskBoard() {
 			m.teamScroll = 0
 			m.view = viewTeam
 		}
 	case "d":
 		// Open debug log viewer for current session.
+		// Tail errors and the why-ongoing panel (w) live there too, so
+		// open it without a debug log as well.
 		debugPath := parser.DebugLogPath(m.sessionPath)
 		if debugPath == "" {
-			return m, nil // no debug file, no-op
+			m.stopDebugWatcher()
+			m.openDebugView(nil, "")
+			return m, nil
 		}
 		entries, offset, err := parser.ReadDebugLog(debugPath)
 		if err != nil {
 			return m, nil
 		}
-		m.debugEntries = entries
-		m.debugPath = debugPath
-		m.debugCursor = 0
-		m.debugScroll = 0
-		m.debugMinLevel = parser.LevelDebug
-		m.debugExpanded = make(map[int]bool)
-		m.applyDebugFilters()
-		m.view = viewDebug
+		m.openDebugView(entries, debugPath)
 
 		// Start debug file watcher for live tailing.
 		m.stopDebugWatcher()
@@ -136,8 +271,16 @@ func (m model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		go dw.run()
 		m.debugWatcher = dw
 		return m, waitForDebugUpdate(dw.sub)
+	case "v":
+		// Select a range of messages from the cursor; j/k extend it.
+		cmd := m.toggleRange()
+		m.layoutList()
+		return m, cmd
 	case "y":
-		// Copy session JSONL path to clipboard.
+		// Copy the selected range as Markdown, or else the session JSONL path.
+		if m.rangeActive {
+			return m, m.copyRange()
+		}
 		if m.sessionPath != "" {
 			m.flashStatus = "Copied: " + m.sessionPath
 			return m, tea.Batch(tea.SetClipboard(m.sessionPath), flashClearCmd())
@@ -153,6 +296,23 @@ func (m model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.flashStatus = "No $EDITOR set"
 			return m, flashClearCmd()
 		}
+	case "B":
+		cmd := m.openBasket()
+		return m, cmd
+	case "E":
+		cmd := m.summarizeTurn(false)
+		return m, cmd
+	case "R":
+		cmd := m.openFork()
+		return m, cmd
+	case "n", "N":
+		if m.highlightQuery != "" {
+			cmd := m.stepSearchHit(msg.String() == "n")
+			return m, cmd
+		}
+	case "+":
+		cmd := m.toggleExactTokens()
+		return m, cmd
 	case "?":
 		m.showKeybinds = !m.showKeybinds
 		m.layoutList()
@@ -161,6 +321,138 @@ func (m model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// finalAnswer locates the last Output item of the session. Returns the
+// message index and item index; itemIdx is -1 for Claude messages without
+// structured items, and both are -1 when the session has no answer yet.
+func finalAnswer(msgs []message) (msgIdx, itemIdx int) {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := msgs[i]
+		if msg.role != RoleClaude {
+			continue
+		}
+		if len(msg.items) == 0 {
+			if strings.TrimSpace(msg.content) != "" {
+				return i, -1
+			}
+			continue
+		}
+		for j := len(msg.items) - 1; j >= 0; j-- {
+			if msg.items[j].itemType == parser.ItemOutput && strings.TrimSpace(msg.items[j].text) != "" {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// finalAnswerText returns the text of the session's final answer, or "" when
+// it has none yet.
+func finalAnswerText(msgs []message) string {
+	msgIdx, itemIdx := finalAnswer(msgs)
+	switch {
+	case msgIdx < 0:
+		return ""
+	case itemIdx < 0:
+		return strings.TrimSpace(msgs[msgIdx].content)
+	}
+	return strings.TrimSpace(msgs[msgIdx].items[itemIdx].text)
+}
+
+// jumpToFinalAnswer moves the cursor to the final answer and opens it in the
+// detail view with the Output item expanded. Messages without items expand
+// in place in the list instead.
+func (m *model) jumpToFinalAnswer() {
+	msgIdx, itemIdx := finalAnswer(m.messages)
+	if msgIdx < 0 {
+		return
+	}
+	m.cursor = msgIdx
+	if itemIdx < 0 {
+		m.expanded[msgIdx] = true
+		m.layoutList()
+		m.ensureCursorVisible()
+		return
+	}
+	m.layoutList()
+	m.ensureCursorVisible()
+	m.openDetailItem(itemIdx)
+}
+
+// openDetailItem opens the cursor message in the detail view with the item at
+// itemIdx expanded and selected.
+func (m *model) openDetailItem(itemIdx int) {
+	m.view = viewDetail
+	m.resetDetailState()
+	m.traceMsg = nil
+	m.savedDetail = nil
+	m.detailExpanded[itemIdx] = true
+	m.selectDetailItem(itemIdx)
+	m.computeDetailMaxScroll()
+	m.ensureDetailCursorVisible()
+}
+
+// selectDetailItem puts the detail cursor on the row of item itemIdx.
+func (m *model) selectDetailItem(itemIdx int) {
+	for i, row := range m.detailVisibleRows() {
+		if row.parentIndex == itemIdx && row.childIndex == -1 {
+			m.detailCursor = i
+			return
+		}
+	}
+}
+
+// Detail focus modes, named as they appear in the detailFocus config: where
+// the cursor starts when a message opens in the detail view.
+const (
+	detailFocusCursor = "cursor" // on the first failed item, else the final output (default)
+	detailFocusExpand = "expand" // the same item, expanded
+	detailFocusTop    = "top"    // on the first item
+)
+
+var detailFocusModes = []string{detailFocusCursor, detailFocusExpand, detailFocusTop}
+
+// validateDetailFocus reports an unknown mode. Empty keeps the default.
+func validateDetailFocus(mode string) error {
+	if mode == "" || slices.Contains(detailFocusModes, mode) {
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q (want one of %s)", mode, strings.Join(detailFocusModes, ", "))
+}
+
+// relevantItem returns the item most likely wanted on opening a turn: the
+// first whose tool call or hook failed, else the last output with text.
+// Returns -1 when there's neither.
+func relevantItem(items []displayItem) int {
+	for i, item := range items {
+		if item.toolError || item.hookError() {
+			return i
+		}
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].itemType == parser.ItemOutput && strings.TrimSpace(items[i].text) != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// enterDetail opens the cursor message in the detail view, starting on its
+// relevantItem (expanded too in the expand mode) unless detailFocus is top.
+func (m *model) enterDetail() {
+	m.view = viewDetail
+	m.resetDetailState()
+	m.traceMsg = nil
+	m.savedDetail = nil
+	if m.cfg.DetailFocus != detailFocusTop {
+		if idx := relevantItem(m.currentDetailMsg().items); idx >= 0 {
+			m.detailExpanded[idx] = m.cfg.DetailFocus == detailFocusExpand
+			m.selectDetailItem(idx)
+		}
+	}
+	m.computeDetailMaxScroll()
+	m.ensureDetailCursorVisible()
+}
+
 // detailHasItems returns true when the current detail message has structured items.
 func (m model) detailHasItems() bool {
 	return len(m.currentDetailMsg().items) > 0
@@ -201,26 +493,176 @@ func (m *model) toggleDetailExpansion() {
 	}
 }
 
+// popTraceLevel restores the detail state saved when drilling into the
+// current subagent trace, moving up one level.
+func (m *model) popTraceLevel() {
+	saved := m.savedDetail
+	m.detailCursor = saved.cursor
+	m.detailScroll = saved.scroll
+	m.detailExpanded = saved.expanded
+	m.detailChildExpanded = saved.childExpanded
+	m.detailMarked = saved.marked
+	m.traceMsg = saved.traceMsg
+	m.savedDetail = saved.parent
+	m.computeDetailMaxScroll()
+}
+
+// copyItemSection copies a tool call's input ("i"), result ("r"), or target
+// path/command ("p") to the clipboard and flashes what was copied.
+func (m *model) copyItemSection(item displayItem, key string) tea.Cmd {
+	if item.itemType != parser.ItemToolCall && item.itemType != parser.ItemSubagent {
+		return nil
+	}
+	var section, text string
+	switch key {
+	case "i":
+		section, text = "input", item.toolInput
+	case "r":
+		section, text = "result", item.toolResult
+	case "p":
+		section, text = "path", parser.ToolTarget(json.RawMessage(item.toolInput))
+	}
+	name := item.toolName
+	if name == "" {
+		name = item.subagentType
+	}
+	if text == "" {
+		m.flashStatus = fmt.Sprintf("No %s to copy", section)
+		return flashClearCmd()
+	}
+	m.flashStatus = fmt.Sprintf("Copied %s %s", name, section)
+	if section == "path" {
+		m.flashStatus = "Copied: " + parser.Truncate(text, 60)
+	}
+	return tea.Batch(tea.SetClipboard(text), flashClearCmd())
+}
+
+// toggleDetailFootnote shows the untruncated summary of the selected row
+// above the footer, or hides it when already shown. Flashes a notice when the
+// summary isn't truncated.
+func (m *model) toggleDetailFootnote() tea.Cmd {
+	if m.detailFootnote != "" {
+		m.detailFootnote = ""
+		m.computeDetailMaxScroll()
+		return nil
+	}
+	rows := m.detailVisibleRows()
+	if m.detailCursor >= len(rows) {
+		return nil
+	}
+	item := rows[m.detailCursor].item
+	full := fullItemSummary(item)
+	// parser.Truncate marks every cut with an ellipsis.
+	if !strings.Contains(m.detailItemSummary(item), "\u2026") || full == "" {
+		m.flashStatus = "Summary is not truncated"
+		return flashClearCmd()
+	}
+	m.detailFootnote = full
+	m.computeDetailMaxScroll()
+	m.ensureDetailCursorVisible()
+	return nil
+}
+
 // updateDetail handles key events in the full-screen detail view.
 func (m model) updateDetail(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	hasItems := m.detailHasItems()
 	detailMsg := m.currentDetailMsg()
 
+	// The full-summary footnote lasts until the next key press.
+	if m.detailFootnote != "" && msg.String() != "f" {
+		m.detailFootnote = ""
+		m.computeDetailMaxScroll()
+	}
+
 	switch msg.String() {
 	case "q", "esc", "escape", "backspace":
 		if m.traceMsg != nil {
-			// Pop back to parent detail view.
-			m.detailCursor = m.savedDetail.cursor
-			m.detailScroll = m.savedDetail.scroll
-			m.detailExpanded = m.savedDetail.expanded
-			m.detailChildExpanded = m.savedDetail.childExpanded
-			m.traceMsg = nil
-			m.savedDetail = nil
-			m.computeDetailMaxScroll()
+			m.popTraceLevel()
 		} else {
 			m.view = viewList
 			m.resetDetailState()
 		}
+	case "i", "r", "p":
+		// Copy one section of the tool call under the cursor.
+		if hasItems {
+			rows := m.detailVisibleRows()
+			if m.detailCursor < len(rows) {
+				return m, m.copyItemSection(rows[m.detailCursor].item, msg.String())
+			}
+		}
+	case "f":
+		if hasItems {
+			cmd := m.toggleDetailFootnote()
+			return m, cmd
+		}
+	case "S":
+		// Pipe a long result or thinking block through the summarizer.
+		if hasItems {
+			cmd := m.summarizeDetailItem()
+			return m, cmd
+		}
+	case "D":
+		// Diff the tool input against the previous call of the same tool.
+		if hasItems {
+			cmd := m.toggleInputDiff()
+			return m, cmd
+		}
+	case "left", "right":
+		// Scroll tables wider than the view a column at a time.
+		if msg.String() == "right" {
+			m.tableScroll = min(m.tableScroll+1, m.maxTableScroll())
+		} else if m.tableScroll > 0 {
+			m.tableScroll--
+		}
+		m.computeDetailMaxScroll()
+	case "l":
+		// Browse the tool input under the cursor as a collapsible tree.
+		if hasItems {
+			rows := m.detailVisibleRows()
+			if m.detailCursor < len(rows) {
+				cmd := m.openJSONTree(rows[m.detailCursor].item)
+				return m, cmd
+			}
+		}
+	case "v":
+		// Page the raw result under the cursor, base64 and long lines intact.
+		if hasItems {
+			rows := m.detailVisibleRows()
+			if m.detailCursor < len(rows) && rows[m.detailCursor].item.toolResult != "" {
+				return m, tea.ExecProcess(pagerCmd(rows[m.detailCursor].item.toolResult), func(err error) tea.Msg {
+					return editorFinishedMsg{err}
+				})
+			}
+		}
+	case "space":
+		// Mark for bulk actions and move on, so runs of items mark quickly.
+		if hasItems {
+			m.toggleDetailMark()
+		}
+	case "Y":
+		if hasItems {
+			cmd := m.copyMarkedItems()
+			return m, cmd
+		}
+	case "x":
+		if hasItems {
+			cmd := m.exportMarkedItems()
+			return m, cmd
+		}
+	case "C":
+		if hasItems {
+			cmd := m.collapseUnmarked()
+			return m, cmd
+		}
+	case "u":
+		// Numbered links in this message (or trace).
+		cmd := m.openLinks(detailMsg)
+		return m, cmd
+	case "-":
+		// Up one trace level; unlike q, never leaves the detail view.
+		if m.traceMsg != nil {
+			m.popTraceLevel()
+		}
 	case "tab":
 		if hasItems {
 			m.toggleDetailExpansion()
@@ -230,9 +672,10 @@ func (m model) updateDetail(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			rows := m.detailVisibleRows()
 			if m.detailCursor < len(rows) {
 				row := rows[m.detailCursor]
-				// Only parent subagent rows with a linked process drill in.
-				if row.childIndex == -1 && row.item.subagentProcess != nil {
-					synth := buildSubagentMessage(row.item.subagentProcess, row.item.subagentType)
+				// Subagent rows with a linked process drill in, including
+				// nested subagents listed in an expanded trace.
+				if row.item.subagentProcess != nil {
+					synth := filterMessageTools(buildSubagentMessage(row.item.subagentProcess, row.item.subagentType), hiddenToolFilter(m.hiddenTools))
 					clonedExp := make(map[int]bool, len(m.detailExpanded))
 					for k, v := range m.detailExpanded {
 						clonedExp[k] = v
@@ -253,7 +696,10 @@ func (m model) updateDetail(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 						scroll:        m.detailScroll,
 						expanded:      clonedExp,
 						childExpanded: clonedChild,
+						marked:        m.detailMarked,
 						label:         parentLabel,
+						traceMsg:      m.traceMsg,
+						parent:        m.savedDetail,
 					}
 					m.traceMsg = &synth
 					m.resetDetailState()
@@ -314,6 +760,19 @@ func (m model) updateDetail(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if hasItems {
 			m.detailCursor = 0
 		}
+	case "B":
+		if hasItems {
+			cmd := m.toggleBasket()
+			return m, cmd
+		}
+	case "n", "N":
+		if m.highlightQuery != "" {
+			cmd := m.stepSearchHit(msg.String() == "n")
+			return m, cmd
+		}
+	case "+":
+		cmd := m.toggleExactTokens()
+		return m, cmd
 	case "?":
 		m.showKeybinds = !m.showKeybinds
 		m.computeDetailMaxScroll()
@@ -413,6 +872,17 @@ func (m model) updateDebug(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.flashStatus = "Copied: " + m.debugPath
 			return m, tea.Batch(tea.SetClipboard(m.debugPath), flashClearCmd())
 		}
+	case "w":
+		// Toggle the why-ongoing panel above the entries. A session the
+		// picker lists also gets the picker's verdict, which comes from a
+		// different scan and can disagree.
+		m.debugOngoing = !m.debugOngoing
+		m.debugPickerWhy = nil
+		if m.debugOngoing && m.pickerListed(m.sessionPath) {
+			why := parser.ScanOngoing(m.sessionPath)
+			m.debugPickerWhy = &why
+		}
+		m.ensureDebugCursorVisible()
 	case "O":
 		// Open debug log in $EDITOR.
 		if cmd := editorCmd(m.debugPath); cmd != nil {
@@ -511,9 +981,13 @@ func (m model) debugMaxScroll() int {
 	return maxScroll
 }
 
-// debugViewHeight returns the visible content lines in the debug view.
+// debugViewHeight returns the visible content lines in the debug view
+// (minus the header line and the why-ongoing panel when shown).
 func (m model) debugViewHeight() int {
-	h := m.height - m.footerHeight()
+	h := m.height - m.footerHeight() - 1
+	if m.debugOngoing {
+		h -= strings.Count(m.renderOngoingPanel(m.clampWidth()), "\n") + 1
+	}
 	if h <= 0 {
 		return 1
 	}
@@ -587,11 +1061,13 @@ func (m model) updateDebugMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 func (m model) updateListMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	switch msg.Mouse().Button {
 	case tea.MouseWheelUp:
-		if m.scroll > 0 {
-			m.scroll -= 3
-			if m.scroll < 0 {
-				m.scroll = 0
-			}
+		if m.scroll == 0 {
+			cmd := m.scrollPastTop()
+			return m, cmd
+		}
+		m.scroll -= 3
+		if m.scroll < 0 {
+			m.scroll = 0
 		}
 	case tea.MouseWheelDown:
 		m.scroll += 3
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
@@ -211,6 +216,113 @@ func TestUpdateList(t *testing.T) {
 		}
 	})
 
+	t.Run("z opens final answer expanded in detail view", func(t *testing.T) {
+		m := testModel()
+		m.messages = append(m.messages, claudeMsg(func(msg *message) {
+			msg.items = []displayItem{
+				{itemType: parser.ItemOutput, text: "first draft"},
+				{itemType: parser.ItemToolCall, toolName: "Bash"},
+				{itemType: parser.ItemOutput, text: "final answer"},
+				{itemType: parser.ItemToolCall, toolName: "Read"},
+			}
+		}), userMsg("thanks"))
+		m.cursor = 0
+		result, _ := m.updateList(key("z"))
+		got := asModel(result)
+		if got.cursor != 3 {
+			t.Errorf("cursor = %d, want 3", got.cursor)
+		}
+		if got.view != viewDetail {
+			t.Fatalf("view = %v, want viewDetail", got.view)
+		}
+		if got.detailCursor != 2 || !got.detailExpanded[2] {
+			t.Errorf("detailCursor = %d expanded = %v, want 2 expanded", got.detailCursor, got.detailExpanded[2])
+		}
+	})
+
+	t.Run("z without items expands the Claude message in place", func(t *testing.T) {
+		m := testModel()
+		m.cursor = 0
+		result, _ := m.updateList(key("z"))
+		got := asModel(result)
+		if got.view != viewList {
+			t.Errorf("view = %v, want viewList", got.view)
+		}
+		if got.cursor != 1 || !got.expanded[1] {
+			t.Errorf("cursor = %d expanded = %v, want 1 expanded", got.cursor, got.expanded[1])
+		}
+	})
+
+	t.Run("Z lays out one line per message", func(t *testing.T) {
+		m := testModel()
+		result, _ := m.updateList(key("Z"))
+		got := asModel(result)
+		if !got.denseList {
+			t.Fatal("Z should turn on the compact list")
+		}
+		if got.totalRenderedLines != len(got.messages) {
+			t.Errorf("totalRenderedLines = %d, want %d", got.totalRenderedLines, len(got.messages))
+		}
+		result, _ = got.updateList(key("enter"))
+		if asModel(result).view != viewDetail {
+			t.Error("enter should still open the detail view")
+		}
+		result, _ = got.updateList(key("Z"))
+		if asModel(result).totalRenderedLines <= len(got.messages) {
+			t.Error("Z again should restore the full cards")
+		}
+	})
+
+	t.Run("S sorts the list and esc restores session order", func(t *testing.T) {
+		m := testModel()
+		m.messages = []message{
+			userMsg("first"),
+			claudeMsg(func(m *message) { m.content = "small"; m.tokensRaw = 100; m.durationMs = 9000 }),
+			userMsg("second"),
+			claudeMsg(func(m *message) {
+				m.content = "big"
+				m.tokensRaw = 5000
+				m.durationMs = 1000
+				m.items = []displayItem{{itemType: parser.ItemToolCall, toolName: "Bash", toolError: true}}
+			}),
+		}
+		m.expanded = map[int]bool{}
+		m.cursor = 0
+		m.layoutList()
+
+		result, _ := m.updateList(key("S"))
+		got := asModel(result)
+		if got.listSort != sortTokens || !slices.Equal(got.listOrder, []int{3, 1, 0, 2}) {
+			t.Fatalf("sort = %v order = %v, want tokens [3 1 0 2]", got.listSort, got.listOrder)
+		}
+		if !strings.Contains(got.viewList(), "Sorted by tokens") {
+			t.Error("sorted list should say so")
+		}
+		result, _ = got.updateList(key("g"))
+		result, _ = asModel(result).updateList(key("j"))
+		if c := asModel(result).cursor; c != 1 {
+			t.Errorf("j from the top: cursor = %d, want message 1 (second most tokens)", c)
+		}
+
+		result, _ = got.updateList(key("S"))
+		if o := asModel(result).listOrder; !slices.Equal(o, []int{1, 3, 0, 2}) {
+			t.Errorf("duration order = %v, want [1 3 0 2]", o)
+		}
+		result, _ = asModel(result).updateList(key("S"))
+		if o := asModel(result).listOrder; o[0] != 3 {
+			t.Errorf("errors order = %v, want the failing turn first", o)
+		}
+
+		result, _ = asModel(result).updateList(key("esc"))
+		got = asModel(result)
+		if got.listSort != sortChronological || got.listOrder != nil || got.view != viewList {
+			t.Errorf("esc: sort = %v view = %v, want session order in the list", got.listSort, got.view)
+		}
+		if strings.Contains(got.viewList(), "Sorted by") {
+			t.Error("banner should go with the sort")
+		}
+	})
+
 	t.Run("ctrl+c returns Quit", func(t *testing.T) {
 		m := testModel()
 		_, cmd := m.updateList(key("ctrl+c"))
@@ -469,6 +581,66 @@ func detailModel(msg message) model {
 	return m
 }
 
+func TestRelevantItem(t *testing.T) {
+	thinking := displayItem{itemType: parser.ItemThinking, text: "hmm"}
+	ok := displayItem{itemType: parser.ItemToolCall, toolName: "Bash"}
+	failed := displayItem{itemType: parser.ItemToolCall, toolName: "Bash", toolError: true}
+	hookFailed := displayItem{itemType: parser.ItemToolCall, toolName: "Edit", hooks: []parser.HookOutput{{IsError: true}}}
+	output := displayItem{itemType: parser.ItemOutput, text: "Done."}
+	empty := displayItem{itemType: parser.ItemOutput, text: "  "}
+	for _, tc := range []struct {
+		name  string
+		items []displayItem
+		want  int
+	}{
+		{"first error wins", []displayItem{thinking, ok, failed, failed, output}, 2},
+		{"failed hook counts", []displayItem{ok, hookFailed, output}, 1},
+		{"else the final output", []displayItem{output, ok, output, empty}, 2},
+		{"neither", []displayItem{thinking, ok}, -1},
+		{"no items", nil, -1},
+	} {
+		if got := relevantItem(tc.items); got != tc.want {
+			t.Errorf("%s: relevantItem = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEnterDetail_Focus(t *testing.T) {
+	msg := claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemThinking, text: "let me think"},
+			{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "go test"}`, toolResult: "FAIL", toolError: true},
+			{itemType: parser.ItemOutput, text: "The tests fail."},
+		}
+	})
+	for _, tc := range []struct {
+		mode     string
+		cursor   int
+		expanded bool
+	}{
+		{"", 1, false},
+		{detailFocusExpand, 1, true},
+		{detailFocusTop, 0, false},
+	} {
+		m := initialModel([]message{msg}, true)
+		m.width, m.height = 120, 40
+		m.cfg.DetailFocus = tc.mode
+		m.layoutList()
+		result, _ := m.updateList(key("enter"))
+		m = asModel(result)
+		if m.view != viewDetail || m.detailCursor != tc.cursor || m.detailExpanded[1] != tc.expanded {
+			t.Errorf("detailFocus %q: view %v, cursor %d, expanded %v; want cursor %d, expanded %v",
+				tc.mode, m.view, m.detailCursor, m.detailExpanded[1], tc.cursor, tc.expanded)
+		}
+		if err := validateDetailFocus(tc.mode); err != nil {
+			t.Errorf("validateDetailFocus(%q) = %v", tc.mode, err)
+		}
+	}
+	if err := validateDetailFocus("last"); err == nil {
+		t.Error("validateDetailFocus should reject an unknown mode")
+	}
+}
+
 func TestUpdateDetail_TreeCursor(t *testing.T) {
 	t.Run("j navigates into expanded subagent children", func(t *testing.T) {
 		m := detailModel(claudeMsgWithSubagent())
@@ -756,3 +928,227 @@ func TestUpdateDetailMouse(t *testing.T) {
 		}
 	})
 }
+
+// claudeMsgWithNestedSubagent returns a message whose subagent spawned its
+// own subagent: Explore -> Plan.
+func claudeMsgWithNestedSubagent() message {
+	inner := &parser.SubagentProcess{
+		ParentTaskID: "tool-inner",
+		Chunks: []parser.Chunk{
+			{Type: parser.AIChunk, Items: []parser.DisplayItem{
+				{Type: parser.ItemToolCall, ToolName: "Grep", ToolSummary: "TODO"},
+			}},
+		},
+	}
+	outer := &parser.SubagentProcess{
+		Chunks: []parser.Chunk{
+			{Type: parser.UserChunk, UserText: "investigate this"},
+			{Type: parser.AIChunk, Items: []parser.DisplayItem{
+				{Type: parser.ItemSubagent, ToolName: "Task", ToolID: "tool-inner", SubagentType: "Plan"},
+			}},
+		},
+		Children: []*parser.SubagentProcess{inner},
+	}
+	return claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemSubagent, subagentType: "Explore", subagentProcess: outer},
+		}
+	})
+}
+
+func TestUpdateDetail_NestedTrace(t *testing.T) {
+	// Drill Explore (top-level row 0), then Plan (trace row 1, after Input).
+	drill := func(t *testing.T) model {
+		t.Helper()
+		m := detailModel(claudeMsgWithNestedSubagent())
+		result, _ := m.updateDetail(key("enter"))
+		m = asModel(result)
+		m.detailCursor = 1
+		result, _ = m.updateDetail(key("enter"))
+		return asModel(result)
+	}
+
+	t.Run("nested subagent in a trace drills in", func(t *testing.T) {
+		got := drill(t)
+		if got.traceMsg == nil || got.traceMsg.subagentLabel != "Plan" {
+			t.Fatalf("traceMsg = %+v, want the Plan trace", got.traceMsg)
+		}
+		if got.savedDetail == nil || got.savedDetail.parent == nil {
+			t.Fatal("savedDetail should chain two levels")
+		}
+	})
+
+	t.Run("expanded trace child rows drill in", func(t *testing.T) {
+		m := detailModel(claudeMsgWithNestedSubagent())
+		m.detailExpanded[0] = true
+		m.detailCursor = 2 // Explore, Input(child0), Plan(child1)
+
+		result, _ := m.updateDetail(key("enter"))
+		got := asModel(result)
+		if got.traceMsg == nil || got.traceMsg.subagentLabel != "Plan" {
+			t.Fatalf("traceMsg = %+v, want the Plan trace", got.traceMsg)
+		}
+	})
+
+	t.Run("minus goes up one level at a time", func(t *testing.T) {
+		got := drill(t)
+
+		result, _ := got.updateDetail(key("-"))
+		got = asModel(result)
+		if got.traceMsg == nil || got.traceMsg.subagentLabel != "Explore" {
+			t.Fatalf("traceMsg = %+v, want the Explore trace", got.traceMsg)
+		}
+		if got.detailCursor != 1 {
+			t.Errorf("detailCursor = %d, want 1 (restored)", got.detailCursor)
+		}
+
+		result, _ = got.updateDetail(key("-"))
+		got = asModel(result)
+		if got.traceMsg != nil || got.savedDetail != nil {
+			t.Error("second - should return to the top-level message")
+		}
+
+		result, _ = got.updateDetail(key("-"))
+		got = asModel(result)
+		if got.view != viewDetail {
+			t.Error("- at the top level should stay in the detail view")
+		}
+	})
+
+	t.Run("breadcrumb lists every level", func(t *testing.T) {
+		got := drill(t)
+		labels := traceBreadcrumb(got.savedDetail)
+		if len(labels) != 2 || labels[0] != "Claude opus4.6" || labels[1] != "Explore" {
+			t.Errorf("breadcrumb = %v, want [Claude opus4.6 Explore]", labels)
+		}
+	})
+}
+
+func TestTraceBreadcrumb_ElidesDeepStacks(t *testing.T) {
+	var saved *savedDetailState
+	for _, label := range []string{"Claude", "A", "B", "C", "D"} {
+		saved = &savedDetailState{label: label, parent: saved}
+	}
+	got := traceBreadcrumb(saved)
+	want := []string{"Claude", Icon.Ellipsis.Glyph, "C", "D"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("breadcrumb = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateDetail_CopySection(t *testing.T) {
+	msg := claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemThinking, text: "let me think"},
+			{itemType: parser.ItemToolCall, toolName: "Bash", toolInput: `{"command": "go test ./..."}`, toolResult: "ok"},
+			{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "/repo/main.go"}`},
+		}
+	})
+
+	tests := []struct {
+		cursor    int
+		key       string
+		wantFlash string
+		wantCmd   bool
+	}{
+		{1, "i", "Copied Bash input", true},
+		{1, "r", "Copied Bash result", true},
+		{1, "p", "Copied: go test ./...", true},
+		{2, "p", "Copied: /repo/main.go", true},
+		{2, "r", "No result to copy", true},
+		{0, "i", "", false}, // thinking rows have no sections
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+" on row "+strconv.Itoa(tt.cursor), func(t *testing.T) {
+			m := detailModel(msg)
+			m.detailCursor = tt.cursor
+			result, cmd := m.updateDetail(key(tt.key))
+			got := asModel(result)
+			if got.flashStatus != tt.wantFlash {
+				t.Errorf("flashStatus = %q, want %q", got.flashStatus, tt.wantFlash)
+			}
+			if (cmd != nil) != tt.wantCmd {
+				t.Errorf("cmd = %v, want non-nil %v", cmd != nil, tt.wantCmd)
+			}
+		})
+	}
+}
+
+func TestUpdateDetail_Footnote(t *testing.T) {
+	longCmd := "go test -run TestSomethingVeryLong ./internal/... -count=1 -v -timeout 10m"
+	msg := claudeMsg(func(m *message) {
+		m.items = []displayItem{
+			{itemType: parser.ItemToolCall, toolName: "Bash",
+				toolInput:   `{"command": "` + longCmd + `", "description": "Run tests"}`,
+				toolSummary: parser.Truncate("Run tests: "+longCmd, 60)},
+			{itemType: parser.ItemToolCall, toolName: "Read", toolInput: `{"file_path": "main.go"}`, toolSummary: "main.go"},
+		}
+	})
+
+	m := detailModel(msg)
+	result, _ := m.updateDetail(key("f"))
+	m = asModel(result)
+	if want := "Run tests: " + longCmd; m.detailFootnote != want {
+		t.Fatalf("detailFootnote = %q, want %q", m.detailFootnote, want)
+	}
+	if !strings.Contains(m.viewDetail(), "-timeout 10m") {
+		t.Error("footnote should render the untruncated command")
+	}
+
+	// Any other key hides it; the cursor still moves.
+	result, _ = m.updateDetail(key("j"))
+	m = asModel(result)
+	if m.detailFootnote != "" || m.detailCursor != 1 {
+		t.Errorf("after j: footnote = %q, cursor = %d", m.detailFootnote, m.detailCursor)
+	}
+
+	// Untruncated summaries flash instead.
+	result, _ = m.updateDetail(key("f"))
+	m = asModel(result)
+	if m.detailFootnote != "" || m.flashStatus != "Summary is not truncated" {
+		t.Errorf("footnote = %q, flash = %q", m.detailFootnote, m.flashStatus)
+	}
+}
+
+func TestDebugOngoingPanel(t *testing.T) {
+	m := testModel()
+	m.openDebugView([]parser.DebugEntry{{Message: "hello"}}, "")
+	m.ongoingWhy = parser.ExplainOngoing(nil)
+	before := m.debugViewHeight()
+
+	result, _ := m.updateDebug(key("w"))
+	m = asModel(result)
+	out := m.viewDebugLog()
+	if !strings.Contains(out, "Why not ongoing") || !strings.Contains(out, "no chunks: not ongoing") {
+		t.Errorf("panel missing from the debug view:\n%s", out)
+	}
+	if m.debugViewHeight() != before-3 {
+		t.Errorf("debugViewHeight = %d, want %d (less the 3-line panel)", m.debugViewHeight(), before-3)
+	}
+
+	result, _ = m.updateDebug(key("w"))
+	if out := asModel(result).viewDebugLog(); strings.Contains(out, "Why not ongoing") {
+		t.Error("w should hide the panel again")
+	}
+}
+
+func TestDebugOngoingPanel_PickerSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.jsonl")
+	os.WriteFile(path, []byte(`{"type":"user","uuid":"u1","timestamp":"2025-01-15T10:00:00Z","message":{"role":"user","content":"hi"}}`+"\n"+
+		`{"type":"assistant","uuid":"a1","timestamp":"2025-01-15T10:00:01Z","message":{"role":"assistant","content":[{"type":"text","text":"hello"}]}}`+"\n"), 0o644)
+	m := testModel()
+	m.sessionPath = path
+	m.openDebugView([]parser.DebugEntry{{Message: "hello"}}, "")
+
+	result, _ := m.updateDebug(key("w"))
+	if out := asModel(result).viewDebugLog(); strings.Contains(out, "Picker:") {
+		t.Error("a session the picker doesn't list should get no picker verdict")
+	}
+
+	m.pickerSessions = []parser.SessionInfo{{Path: path}}
+	result, _ = m.updateDebug(key("w"))
+	out := asModel(result).viewDebugLog()
+	if !strings.Contains(out, "Picker: why not ongoing") || !strings.Contains(out, "no AI activity after the last ending event") {
+		t.Errorf("picker verdict missing from the panel:\n%s", out)
+	}
+}
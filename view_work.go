@@ -0,0 +1,31 @@
+package main
+
+import "context"
+
+// viewWork is the background work started from one view: scans across every
+// session of the project that only that view shows the result of.
+type viewWork struct {
+	view   viewState
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// viewContext returns the context for background work the current view
+// starts. It is cancelled once the model leaves the view, so a scan nobody
+// will see the end of stops parsing sessions.
+func (m *model) viewContext() context.Context {
+	if m.viewWork == nil || m.viewWork.view != m.view {
+		m.endViewWork()
+		ctx, cancel := context.WithCancel(context.Background())
+		m.viewWork = &viewWork{view: m.view, ctx: ctx, cancel: cancel}
+	}
+	return m.viewWork.ctx
+}
+
+// endViewWork cancels the work of a view the model has left.
+func (m *model) endViewWork() {
+	if m.viewWork != nil && m.viewWork.view != m.view {
+		m.viewWork.cancel()
+		m.viewWork = nil
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// viewerHeartbeat is how often an instance refreshes its viewer file.
+const viewerHeartbeat = 5 * time.Second
+
+// viewerStale is how long a viewer file may go unrefreshed before its
+// instance counts as gone (quit without cleanup, crashed, suspended).
+const viewerStale = 3 * viewerHeartbeat
+
+// viewerRegistry records which sessions this instance is viewing, one file
+// per instance under a directory per session, so instances watching the same
+// session can see each other. Liveness is the file's modification time.
+type viewerRegistry struct {
+	dir string // root: one subdirectory per session
+	id  string // this instance's file name
+}
+
+// viewerTickMsg triggers the next heartbeat.
+type viewerTickMsg struct{}
+
+// viewersMsg reports how many other instances view session.
+type viewersMsg struct {
+	session string
+	others  int
+}
+
+// newViewerRegistry returns the registry under the user cache directory, or
+// nil when it can't be determined.
+func newViewerRegistry() *viewerRegistry {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return &viewerRegistry{
+		dir: filepath.Join(dir, "tail-claude", "viewers"),
+		id:  strconv.Itoa(os.Getpid()),
+	}
+}
+
+// sessionDir returns the directory holding the viewer files of session.
+func (r *viewerRegistry) sessionDir(session string) string {
+	sum := sha1.Sum([]byte(session))
+	return filepath.Join(r.dir, hex.EncodeToString(sum[:]))
+}
+
+// beat marks this instance as viewing session and returns how many other
+// instances do. Files of instances gone stale are removed.
+func (r *viewerRegistry) beat(session string, now time.Time) int {
+	dir := r.sessionDir(session)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0
+	}
+	own := filepath.Join(dir, r.id)
+	if err := os.Chtimes(own, now, now); err != nil {
+		if err := os.WriteFile(own, nil, 0o644); err != nil {
+			return 0
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	others := 0
+	for _, e := range entries {
+		if e.Name() == r.id {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if now.Sub(info.ModTime()) > viewerStale {
+			os.Remove(filepath.Join(dir, e.Name()))
+			continue
+		}
+		others++
+	}
+	return others
+}
+
+// leave removes this instance's file for session, and the session's
+// directory once no viewer is left.
+func (r *viewerRegistry) leave(session string) {
+	if r == nil || session == "" {
+		return
+	}
+	dir := r.sessionDir(session)
+	os.Remove(filepath.Join(dir, r.id))
+	os.Remove(dir) // fails while other viewers remain
+}
+
+// viewerTickCmd schedules the next heartbeat.
+func viewerTickCmd() tea.Cmd {
+	return tea.Tick(viewerHeartbeat, func(time.Time) tea.Msg {
+		return viewerTickMsg{}
+	})
+}
+
+// viewerBeatCmd refreshes this instance's viewer file for session off the UI
+// goroutine. Nil without a registry or session.
+func viewerBeatCmd(r *viewerRegistry, session string) tea.Cmd {
+	if r == nil || session == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		return viewersMsg{session: session, others: r.beat(session, time.Now())}
+	}
+}
+
+// moveViewer leaves the session at old after a switch and announces the
+// current one.
+func (m *model) moveViewer(old string) tea.Cmd {
+	if old != m.sessionPath {
+		m.viewers.leave(old)
+		m.otherViewers = 0
+	}
+	return viewerBeatCmd(m.viewers, m.sessionPath)
+}
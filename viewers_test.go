@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestViewerRegistry(t *testing.T) {
+	dir := t.TempDir()
+	a := &viewerRegistry{dir: dir, id: "100"}
+	b := &viewerRegistry{dir: dir, id: "200"}
+	now := time.Now()
+
+	if n := a.beat("/s.jsonl", now); n != 0 {
+		t.Errorf("alone: others = %d, want 0", n)
+	}
+	if n := b.beat("/s.jsonl", now); n != 1 {
+		t.Errorf("b sees %d others, want a", n)
+	}
+	if n := a.beat("/other.jsonl", now); n != 0 {
+		t.Errorf("another session has %d viewers, want 0", n)
+	}
+
+	// a stops refreshing: once stale, b stops counting it and cleans it up.
+	later := now.Add(viewerStale + time.Second)
+	if n := b.beat("/s.jsonl", later); n != 0 {
+		t.Errorf("stale viewer still counted: %d", n)
+	}
+	if _, err := os.Stat(filepath.Join(a.sessionDir("/s.jsonl"), a.id)); !os.IsNotExist(err) {
+		t.Error("stale viewer file should be removed")
+	}
+
+	b.leave("/s.jsonl")
+	if _, err := os.Stat(b.sessionDir("/s.jsonl")); !os.IsNotExist(err) {
+		t.Error("the last viewer leaving should remove the session dir")
+	}
+}
+
+func TestInfoBarViewers(t *testing.T) {
+	m := testModel()
+	m.sessionPath = "/s.jsonl"
+	result, _ := m.Update(viewersMsg{session: "/s.jsonl", others: 1})
+	m = asModel(result)
+	if !strings.Contains(m.renderInfoBar(), "also viewed by another instance") {
+		t.Errorf("info bar should note the other viewer:\n%s", m.renderInfoBar())
+	}
+
+	result, _ = m.Update(viewersMsg{session: "/old.jsonl", others: 3})
+	if got := asModel(result).otherViewers; got != 1 {
+		t.Errorf("a count for another session should be ignored, got %d", got)
+	}
+}
@@ -36,7 +36,8 @@ func buildVisibleRows(items []displayItem, expanded map[int]bool) []visibleRow {
 
 // buildTraceItems creates display items from a subagent's execution trace.
 // UserChunks become "Input" items; AIChunk items pass through with full
-// field mapping via displayItemFromParser.
+// field mapping via displayItemFromParser. Task items are linked to the
+// nested subagents they spawned so they can be drilled into as well.
 func buildTraceItems(parent displayItem) []displayItem {
 	if parent.subagentProcess == nil {
 		return nil
@@ -53,7 +54,20 @@ func buildTraceItems(parent displayItem) []displayItem {
 			})
 		case parser.AIChunk:
 			for _, it := range c.Items {
-				items = append(items, displayItemFromParser(it))
+				di := displayItemFromParser(it)
+				if it.Type == parser.ItemSubagent {
+					for _, child := range proc.Children {
+						if child.ParentTaskID == it.ToolID {
+							di.subagentProcess = child
+							di.subagentOngoing = isSubagentOngoing(child)
+							if di.subagentOngoing {
+								di.subagentStep = parser.CurrentStep(child.Chunks)
+							}
+							break
+						}
+					}
+				}
+				items = append(items, di)
 			}
 		}
 	}
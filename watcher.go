@@ -1,7 +1,12 @@
 package main
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,19 +21,69 @@ import (
 // round-trips) into a single re-read, reducing visual churn.
 const watcherDebounce = 500 * time.Millisecond
 
+// defaultPollInterval is how often the watcher stats the session file while
+// the session is active. fsnotify delivers most writes; the poll catches the
+// ones it misses (network filesystems, editors that replace the file).
+const defaultPollInterval = time.Second
+
+// minPollInterval is the smallest poll interval accepted from flags or config.
+const minPollInterval = 100 * time.Millisecond
+
+// pollIdleStep is how long the session must be idle before the poll interval
+// doubles. Each further step doubles it again, up to maxPollInterval. Matches
+// the default ongoing staleness threshold: a session quiet this long has stopped.
+const pollIdleStep = parser.OngoingStalenessThreshold
+
+// maxPollInterval caps the idle backoff.
+const maxPollInterval = 30 * time.Second
+
+// pollBackoff returns the poll interval after idle time without new data:
+// base while the session is active, doubling per pollIdleStep once it goes
+// quiet.
+func pollBackoff(base, idle time.Duration) time.Duration {
+	if idle < pollIdleStep {
+		return base
+	}
+	interval := base
+	for steps := idle / pollIdleStep; steps > 0 && interval < maxPollInterval; steps-- {
+		interval *= 2
+	}
+	return max(min(interval, maxPollInterval), base)
+}
+
+// pollRateMsg reports a change in the watcher's poll interval.
+type pollRateMsg struct {
+	interval time.Duration
+	idle     bool // true when backed off from the base interval
+	source   chan pollRateMsg
+}
+
 // tailUpdateMsg carries the full rebuilt message list after an incremental read.
 // We send the complete list (not a diff) because BuildChunks merges consecutive
 // AI messages -- the last chunk can grow as new tool calls or text arrive.
 type tailUpdateMsg struct {
 	messages       []message
 	teams          []parser.TeamSnapshot
-	ongoing        bool   // whether the session appears to still be in progress
+	todos          []parser.Todo
+	ongoing        bool // whether the session appears to still be in progress
+	ongoingWhy     parser.OngoingVerdict
 	permissionMode string // last-seen permissionMode from new entries; empty if unchanged
+	growth         growthRate
+	evictedTurns   int             // turns dropped from the front by the tail window
+	branches       []parser.Branch // nil unless the session forked
+	fullHistory    bool            // evicted turns were reloaded and windowing is paused
+	partialHistory bool            // the session was opened reduced and its start is still unread
+
+	// source is the channel the update came through, set by
+	// waitForTailUpdate, so updates from the alternate session's watcher
+	// aren't applied to the current one.
+	source chan tailUpdateMsg
 }
 
 // watcherErrMsg reports errors from the file watcher goroutine.
 type watcherErrMsg struct {
-	err error
+	err    error
+	source chan error
 }
 
 // sessionWatcher monitors a JSONL session file for appended lines and pushes
@@ -39,13 +94,52 @@ type watcherErrMsg struct {
 // run() goroutine. Timer callbacks send signals instead of calling methods
 // directly, avoiding data races.
 type sessionWatcher struct {
-	path          string
+	path          string               // src's name
+	src           parser.SessionSource // where the session is read from
+	local         bool                 // src is a local file, with fsnotify events and subagent traces
 	offset        int64
 	allClassified []parser.ClassifiedMsg
 	sub           chan tailUpdateMsg
 	errc          chan error
 	done          chan struct{}
 	signals       chan struct{} // debounced rebuild trigger; capacity 1
+	rates         chan pollRateMsg
+	log           *slog.Logger // watcher activity for --log-file
+
+	// Adaptive polling. pollBase is set before run(); the rest is only
+	// touched by run().
+	pollBase     time.Duration
+	pollRate     time.Duration // last interval reported on rates
+	lastActivity time.Time     // when new session data was last read
+
+	// Change detection for network and synced drives; see change_detect.go.
+	// detect and rereadEvery are set before run().
+	detect      string            // detectStat or detectContent; empty means detectStat
+	rereadEvery time.Duration     // forced full re-read period; 0 disables
+	lastReread  time.Time         // when the file was last read whole
+	tailSum     [sha256.Size]byte // hash of the data before offset (content mode)
+
+	// Tail window, only touched by run() after it starts. window is set
+	// before run(); lineOffsets parallels allClassified.
+	window       int // turns kept resident; 0 keeps everything
+	lineOffsets  []int64
+	windowStart  int64 // file offset of the first resident message
+	evictedTurns int
+	fullHistory  bool      // evicted turns reloaded; eviction paused
+	partial      bool      // opened reduced: the file before windowStart was never read, so evictedTurns undercounts
+	history      chan bool // UI requests: true reloads evicted turns, false resumes windowing
+
+	// Forks from /rewind and checkpoint restores, only touched by run().
+	// The lineage reads the file on its own; leaf picks the branch shown,
+	// "" following the newest.
+	lineage *parser.Lineage
+	leaf    string
+	branch  chan string // UI requests: the leaf of the branch to show
+
+	// Growth rate, only touched by run().
+	growth growthTracker
+	tokens int        // context size from the last assistant response
+	rate   growthRate // rate as of the last read with new data
 
 	// Guards debounce timers so stop() can cancel them safely.
 	// Does NOT guard data fields — those are only touched by run().
@@ -59,17 +153,29 @@ type sessionWatcher struct {
 	// Set by run(), used by readAndRebuild to add newly discovered team files.
 	fsWatcher        *fsnotify.Watcher
 	watchedProcPaths map[string]bool // subagent/team file paths already watched
+	subagentsDir     string          // where this session's subagent traces are written
+	subagentsWatched bool            // subagentsDir is watched for new agent files
 }
 
-func newSessionWatcher(path string, initialClassified []parser.ClassifiedMsg, initialOffset int64) *sessionWatcher {
+func newSessionWatcher(src parser.SessionSource, initialClassified []parser.ClassifiedMsg, initialOffset int64) *sessionWatcher {
+	_, local := parser.LocalPath(src)
 	return &sessionWatcher{
-		path:          path,
+		path:          src.Name(),
+		src:           src,
+		local:         local,
 		offset:        initialOffset,
 		allClassified: initialClassified,
 		sub:           make(chan tailUpdateMsg, 1),
 		errc:          make(chan error, 1),
 		done:          make(chan struct{}),
 		signals:       make(chan struct{}, 1),
+		rates:         make(chan pollRateMsg, 1),
+		history:       make(chan bool, 1),
+		lineage:       parser.NewLineage(),
+		branch:        make(chan string, 1),
+		pollBase:      defaultPollInterval,
+		tokens:        lastUsageTokens(initialClassified),
+		log:           logger.With("component", "watcher", "session", src.Name()),
 	}
 }
 
@@ -99,37 +205,122 @@ func (w *sessionWatcher) sendSignal() {
 	}
 }
 
+// reportErr forwards a non-fatal error to the TUI without blocking. If an
+// error is already pending, the new one is dropped.
+func (w *sessionWatcher) reportErr(err error) {
+	w.log.Error("watcher error", "err", err)
+	select {
+	case w.errc <- err:
+	default:
+	}
+}
+
+// nextPoll computes the next poll interval and reports it on rates when it
+// changes. Only called from run().
+func (w *sessionWatcher) nextPoll() time.Duration {
+	interval := pollBackoff(w.pollBase, time.Since(w.lastActivity))
+	if interval != w.pollRate {
+		w.pollRate = interval
+		rate := pollRateMsg{interval: interval, idle: interval > w.pollBase}
+		w.log.Debug("poll interval", "interval", interval.String(), "idle", rate.idle)
+		select {
+		case w.rates <- rate:
+		default:
+			select {
+			case <-w.rates:
+			default:
+			}
+			w.rates <- rate
+		}
+	}
+	return interval
+}
+
+// poll rebuilds when the session's size no longer matches the read offset,
+// covering writes fsnotify didn't report; for sources other than local
+// files, it's the only way new data is noticed. The content mode checks the
+// file's bytes instead, and a configured re-read period reads it whole.
+func (w *sessionWatcher) poll() {
+	if w.rereadEvery > 0 && time.Since(w.lastReread) >= w.rereadEvery {
+		w.reread()
+		return
+	}
+	if w.detect == detectContent && w.local {
+		w.pollContent()
+		return
+	}
+	size, err := w.src.Size()
+	if err != nil || size == w.offset {
+		return
+	}
+	w.readAndRebuild()
+}
+
 // run starts the fsnotify watcher loop. Intended to be called as a goroutine.
 // Watches both the session file (for appended lines) and the project directory
 // (for new team member session files). Debounces events so rapid writes
-// coalesce into a single rebuild.
+// coalesce into a single rebuild. A poll timer backs fsnotify up, slowing
+// down while the session is idle.
 //
-// Closes sub and errc on exit so blocked waitForTailUpdate/waitForWatcherErr
+// Closes sub, errc, and rates on exit so blocked waitForTailUpdate/waitForWatcherErr
 // Cmds unblock and return nil instead of leaking goroutines.
 func (w *sessionWatcher) run() {
 	defer close(w.sub)
 	defer close(w.errc)
+	defer close(w.rates)
+
+	// Only local files deliver fsnotify events; other sources rely on the
+	// poll, and their nil channels never fire.
+	var events chan fsnotify.Event
+	var watchErrs chan error
+	if w.local {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			w.reportErr(fmt.Errorf("starting file watcher: %w", err))
+			return
+		}
+		defer watcher.Close()
 
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		w.errc <- err
-		return
-	}
-	defer watcher.Close()
+		if err := watcher.Add(w.path); err != nil {
+			w.reportErr(fmt.Errorf("watching %s: %w", filepath.Base(w.path), err))
+			return
+		}
 
-	if err := watcher.Add(w.path); err != nil {
-		w.errc <- err
-		return
+		// Watch the project directory for new team session files.
+		// Non-fatal if this fails (directory watch is an optimization).
+		projectDir := filepath.Dir(w.path)
+		_ = watcher.Add(projectDir)
+
+		// Watch the subagents directory so agents spawned later stream their
+		// progress right away. It may not exist yet; readAndRebuild adds it once
+		// the first subagent shows up.
+		w.subagentsDir = filepath.Join(strings.TrimSuffix(w.path, ".jsonl"), "subagents")
+		w.subagentsWatched = watcher.Add(w.subagentsDir) == nil
+
+		// Store fsnotify watcher so readAndRebuild can add team session files.
+		w.fsWatcher = watcher
+		w.watchedProcPaths = make(map[string]bool)
+		events, watchErrs = watcher.Events, watcher.Errors
 	}
 
-	// Watch the project directory for new team session files.
-	// Non-fatal if this fails (directory watch is an optimization).
-	projectDir := filepath.Dir(w.path)
-	_ = watcher.Add(projectDir)
-
-	// Store fsnotify watcher so readAndRebuild can add team session files.
-	w.fsWatcher = watcher
-	w.watchedProcPaths = make(map[string]bool)
+	// Idle time counts from the file's last write, so reopening an old
+	// session starts backed off.
+	w.lastActivity = time.Now()
+	if info, err := os.Stat(w.path); err == nil {
+		w.lastActivity = info.ModTime()
+	}
+	w.log.Info("watching", "offset", w.offset, "local", w.local, "detect", w.detect, "window", w.window)
+	defer w.log.Info("stopped watching", "offset", w.offset)
+	w.growth.add(growthSample{at: time.Now(), bytes: w.offset, tokens: w.tokens})
+	w.lastReread = time.Now()
+	w.noteTail()
+	pollTimer := time.NewTimer(w.nextPoll())
+	defer pollTimer.Stop()
+
+	// A long session loads whole; trim it to the window right away.
+	if w.evict() {
+		w.readAndRebuild()
+	}
 
 	for {
 		select {
@@ -140,8 +331,21 @@ func (w *sessionWatcher) run() {
 			// Debounced rebuild trigger. Read any new parent data,
 			// then rebuild everything (chunks, subagents, team sessions).
 			w.readAndRebuild()
+			pollTimer.Reset(w.nextPoll())
+
+		case <-pollTimer.C:
+			w.poll()
+			pollTimer.Reset(w.nextPoll())
+
+		case full := <-w.history:
+			w.setFullHistory(full)
+			w.readAndRebuild()
+
+		case leaf := <-w.branch:
+			w.leaf = leaf
+			w.readAndRebuild()
 
-		case event, ok := <-watcher.Events:
+		case event, ok := <-events:
 			if !ok {
 				return
 			}
@@ -154,6 +358,14 @@ func (w *sessionWatcher) run() {
 				}
 				w.debounce = time.AfterFunc(watcherDebounce, w.sendSignal)
 				w.mu.Unlock()
+			} else if event.Has(fsnotify.Create) && filepath.Dir(event.Name) == w.subagentsDir {
+				// New subagent trace: discover it and start watching it.
+				w.mu.Lock()
+				if w.dirDebounce != nil {
+					w.dirDebounce.Stop()
+				}
+				w.dirDebounce = time.AfterFunc(watcherDebounce, w.sendSignal)
+				w.mu.Unlock()
 			} else if event.Has(fsnotify.Create) && w.hasTeamTasks {
 				// New file in project directory while we have team tasks.
 				// Longer debounce — team sessions need a moment to populate.
@@ -164,8 +376,9 @@ func (w *sessionWatcher) run() {
 				w.dirDebounce = time.AfterFunc(500*time.Millisecond, w.sendSignal)
 				w.mu.Unlock()
 			} else if event.Has(fsnotify.Write) && w.watchedProcPaths[event.Name] {
-				// Team session file written to — agent is working. Debounce
-				// with a longer window to avoid rebuilding on every tool call.
+				// Subagent or team session file written to — agent is working.
+				// Debounce with a longer window to avoid rebuilding on every
+				// tool call; each rebuild refreshes the agent's current step.
 				w.mu.Lock()
 				if w.teamDebounce != nil {
 					w.teamDebounce.Stop()
@@ -174,15 +387,12 @@ func (w *sessionWatcher) run() {
 				w.mu.Unlock()
 			}
 
-		case err, ok := <-watcher.Errors:
+		case err, ok := <-watchErrs:
 			if !ok {
 				return
 			}
 			// Non-fatal: forward to TUI, don't log to stderr (leaks through alt screen).
-			select {
-			case w.errc <- err:
-			default:
-			}
+			w.reportErr(fmt.Errorf("file watcher: %w", err))
 		}
 	}
 }
@@ -191,12 +401,9 @@ func (w *sessionWatcher) run() {
 // classified messages, discovers subagents, and sends the update.
 // Only called from run() — no synchronization needed on data fields.
 func (w *sessionWatcher) readAndRebuild() {
-	newMsgs, newOffset, err := parser.ReadSessionIncremental(w.path, w.offset)
+	newMsgs, newLineOffsets, newOffset, err := parser.ReadSourceIncrementalOffsets(w.src, w.offset)
 	if err != nil {
-		select {
-		case w.errc <- err:
-		default:
-		}
+		w.reportErr(fmt.Errorf("reading %s at byte %d: %w", filepath.Base(w.path), w.offset, err))
 		return
 	}
 
@@ -204,8 +411,17 @@ func (w *sessionWatcher) readAndRebuild() {
 	// Scan new messages for the last-seen permissionMode while we have them.
 	var permissionMode string
 	if len(newMsgs) > 0 || newOffset != w.offset {
+		w.log.Debug("read", "from", w.offset, "to", newOffset, "messages", len(newMsgs))
 		w.offset = newOffset
+		w.lastActivity = time.Now()
 		w.allClassified = append(w.allClassified, newMsgs...)
+		w.lineOffsets = append(w.lineOffsets, newLineOffsets...)
+		w.evict()
+		if t := lastUsageTokens(newMsgs); t > 0 {
+			w.tokens = t
+		}
+		w.rate = w.growth.add(growthSample{at: w.lastActivity, bytes: w.offset, tokens: w.tokens})
+		w.noteTail()
 
 		for i := len(newMsgs) - 1; i >= 0; i-- {
 			if u, ok := newMsgs[i].(parser.UserMsg); ok && u.PermissionMode != "" {
@@ -215,20 +431,33 @@ func (w *sessionWatcher) readAndRebuild() {
 		}
 	}
 
-	chunks := parser.BuildChunks(w.allClassified)
-
-	subagents, _ := parser.DiscoverSubagents(w.path)
-	teamProcs, _ := parser.DiscoverTeamSessions(w.path, chunks)
-	allProcs := append(subagents, teamProcs...)
-	colorMap := parser.LinkSubagents(allProcs, chunks, w.path)
+	if err := w.lineage.ReadSource(w.src); err != nil {
+		w.reportErr(fmt.Errorf("reading branches of %s: %w", filepath.Base(w.path), err))
+	}
+	chunks := parser.BuildChunks(w.lineage.Apply(w.allClassified, w.lineOffsets, w.leaf))
+
+	// Subagent traces and team sessions live next to a local session file.
+	var subagents, allProcs []parser.SubagentProcess
+	var colorMap map[string]string
+	if w.local {
+		subagents, _ = parser.DiscoverSubagents(w.path)
+		teamProcs, _ := parser.DiscoverTeamSessions(w.path, chunks)
+		allProcs = append(subagents, teamProcs...)
+		colorMap = parser.LinkSubagents(allProcs, chunks, w.path)
+		parser.LinkNestedSubagents(allProcs)
+	}
 
 	// Track whether we have team tasks so directory watches know
 	// whether to trigger rebuilds for new .jsonl files.
 	w.hasTeamTasks = hasTeamTaskItems(chunks)
 
-	// Watch newly discovered team session files for writes so the spinner
-	// stays alive while agents work in their own session files.
+	// Watch newly discovered subagent and team session files for writes so
+	// the spinner and current step stay live while agents work in their own
+	// session files.
 	if w.fsWatcher != nil {
+		if !w.subagentsWatched && len(subagents) > 0 {
+			w.subagentsWatched = w.fsWatcher.Add(w.subagentsDir) == nil
+		}
 		for i := range allProcs {
 			fp := allProcs[i].FilePath
 			if fp != "" && !w.watchedProcPaths[fp] {
@@ -239,13 +468,13 @@ func (w *sessionWatcher) readAndRebuild() {
 		}
 	}
 
-	ongoing := parser.IsOngoing(chunks)
-	if !ongoing {
+	why := parser.ExplainOngoing(chunks)
+	if !why.Ongoing {
 		// Parent may be idle while subagents/team members are still working.
 		// Check if any linked process is ongoing (with staleness guard).
 		for i := range allProcs {
 			if isSubagentOngoing(&allProcs[i]) {
-				ongoing = true
+				why.Override(true, "subagent "+allProcs[i].ID+" is still working: ongoing")
 				break
 			}
 		}
@@ -256,8 +485,15 @@ func (w *sessionWatcher) readAndRebuild() {
 	update := tailUpdateMsg{
 		messages:       chunksToMessages(chunks, allProcs, colorMap),
 		teams:          teams,
-		ongoing:        ongoing,
+		todos:          parser.FinalTodos(chunks),
+		ongoing:        why.Ongoing,
+		ongoingWhy:     why,
 		permissionMode: permissionMode,
+		growth:         w.rate,
+		evictedTurns:   w.evictedTurns,
+		branches:       w.lineage.Branches(w.leaf),
+		fullHistory:    w.fullHistory,
+		partialHistory: w.partial,
 	}
 
 	// Non-blocking send: drop stale update if receiver hasn't consumed yet.
@@ -282,10 +518,24 @@ func waitForTailUpdate(sub chan tailUpdateMsg) tea.Cmd {
 		if !ok {
 			return nil
 		}
+		u.source = sub
 		return u
 	}
 }
 
+// waitForPollRate blocks on the rates channel and returns the next
+// pollRateMsg. Returns nil when the channel is closed (watcher stopped).
+func waitForPollRate(rates chan pollRateMsg) tea.Cmd {
+	return func() tea.Msg {
+		r, ok := <-rates
+		if !ok {
+			return nil
+		}
+		r.source = rates
+		return r
+	}
+}
+
 // waitForWatcherErr blocks on the error channel and wraps the result
 // in a watcherErrMsg for the Bubble Tea runtime. Returns nil when the
 // channel is closed (watcher stopped), unblocking the goroutine.
@@ -295,6 +545,6 @@ func waitForWatcherErr(errc chan error) tea.Cmd {
 		if !ok {
 			return nil
 		}
-		return watcherErrMsg{err: err}
+		return watcherErrMsg{err: err, source: errc}
 	}
 }
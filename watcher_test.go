@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestPollBackoff(t *testing.T) {
+	base := time.Second
+	tests := []struct {
+		name string
+		idle time.Duration
+		want time.Duration
+	}{
+		{"active session polls at base", 10 * time.Second, base},
+		{"first idle step doubles", pollIdleStep, 2 * base},
+		{"two idle steps quadruple", 2*pollIdleStep + time.Second, 4 * base},
+		{"long idle caps at max", 24 * time.Hour, maxPollInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pollBackoff(base, tt.idle); got != tt.want {
+				t.Errorf("pollBackoff(%s, %s) = %s, want %s", base, tt.idle, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("base above max is never lowered", func(t *testing.T) {
+		if got := pollBackoff(time.Minute, time.Hour); got != time.Minute {
+			t.Errorf("pollBackoff = %s, want 1m0s", got)
+		}
+	})
+}
+
+func TestPoll_RemoteSource(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("parser", "testdata", "multi_turn.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	half := len(data) / 2
+	for data[half-1] != '\n' {
+		half--
+	}
+	if err := os.WriteFile(path, data[:half], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	src := parser.HTTPSource{URL: server.URL + "/session.jsonl"}
+	msgs, _, end, err := parser.ReadSourceIncrementalOffsets(src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newSessionWatcher(src, msgs, end)
+	if w.local {
+		t.Fatal("an HTTP source isn't local")
+	}
+	updated := func() bool {
+		select {
+		case <-w.sub:
+			return true
+		default:
+			return false
+		}
+	}
+
+	w.poll()
+	if updated() {
+		t.Error("unchanged session should not rebuild")
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w.poll()
+	if !updated() || w.offset != int64(len(data)) || len(w.allClassified) <= len(msgs) {
+		t.Errorf("after append: offset = %d, %d messages; want %d bytes, more than %d messages",
+			w.offset, len(w.allClassified), len(data), len(msgs))
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Webhook events, named as they appear in the payload and the events config.
+const (
+	eventTurnCompleted  = "turn_completed"  // Claude finished answering and is waiting for the user
+	eventToolError      = "tool_error"      // a tool call failed
+	eventSessionIdle    = "session_idle"    // mid-turn but silent for ongoingIdleTimeout (permission prompt, stall)
+	eventBudgetExceeded = "budget_exceeded" // session tokens crossed tokenBudget
+)
+
+var webhookEvents = []string{eventTurnCompleted, eventToolError, eventSessionIdle, eventBudgetExceeded}
+
+// webhookTimeout bounds each POST so a slow endpoint can't pile up requests.
+const webhookTimeout = 10 * time.Second
+
+// webhookSignatureHeader carries "sha256=<hex HMAC of the body>" when a
+// secret is configured, in the format GitHub webhooks use.
+const webhookSignatureHeader = "X-Tail-Claude-Signature"
+
+// webhookResultChars caps the error text sent with tool_error.
+const webhookResultChars = 500
+
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// webhookConfig sets where session events are posted.
+type webhookConfig struct {
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`      // HMAC-SHA256 key for the signature header
+	Events      []string `json:"events,omitempty"`      // events to send; empty sends all
+	TokenBudget int      `json:"tokenBudget,omitempty"` // session tokens that trigger budget_exceeded; 0 disables
+}
+
+// validateWebhook reports a URL that isn't http(s) or an unknown event.
+func validateWebhook(c *webhookConfig) error {
+	if c == nil {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q is not an http(s) URL", c.URL)
+	}
+	for _, e := range c.Events {
+		if !slices.Contains(webhookEvents, e) {
+			return fmt.Errorf("unknown event %q (want one of %s)", e, strings.Join(webhookEvents, ", "))
+		}
+	}
+	return nil
+}
+
+// wants reports whether event should be sent.
+func (c *webhookConfig) wants(event string) bool {
+	return c != nil && (len(c.Events) == 0 || slices.Contains(c.Events, event))
+}
+
+// webhookEvent is the JSON body of a webhook POST. Fields that don't apply
+// to the event are omitted.
+type webhookEvent struct {
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"` // session file name without .jsonl
+	Path    string    `json:"path"`
+	Cwd     string    `json:"cwd,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	Turn    int       `json:"turn,omitempty"`   // prompts sent so far
+	Prompt  string    `json:"prompt,omitempty"` // the latest prompt
+	Tokens  int       `json:"tokens"`           // session tokens so far
+	Output  string    `json:"output,omitempty"` // turn_completed: Claude's last text output
+	Tool    string    `json:"tool,omitempty"`   // tool_error: tool name
+	Target  string    `json:"target,omitempty"` // tool_error: the call's summary, e.g. a file path
+	Error   string    `json:"error,omitempty"`  // tool_error: the result text
+	Budget  int       `json:"budget,omitempty"` // budget_exceeded: the configured budget
+}
+
+// webhookState tracks what has been reported for the current session, so
+// events fire once. Reset on every session switch.
+type webhookState struct {
+	toolErrors map[string]bool // IDs of the failed tool calls reported or present at load
+	overBudget bool            // budget_exceeded sent, or the session loaded over budget
+	idle       bool            // session_idle sent since the last write
+}
+
+// webhookSentMsg reports a failed webhook delivery.
+type webhookSentMsg struct {
+	event string
+	err   error
+}
+
+// resetWebhookState starts tracking the current session: failed tool calls
+// and a budget already crossed when it loads are not news.
+func (m *model) resetWebhookState() {
+	m.hookState = webhookState{
+		toolErrors: toolIDs(failedToolCalls(m.rawMessages)),
+		overBudget: m.cfg.Webhook != nil && m.cfg.Webhook.TokenBudget > 0 &&
+			sessionTokens(m.rawMessages) >= m.cfg.Webhook.TokenBudget,
+	}
+}
+
+// failedToolCalls returns the main session's failed tool calls in order.
+func failedToolCalls(msgs []message) []displayItem {
+	var failed []displayItem
+	for _, msg := range msgs {
+		for _, item := range msg.items {
+			if item.itemType == parser.ItemToolCall && item.toolError {
+				failed = append(failed, item)
+			}
+		}
+	}
+	return failed
+}
+
+// toolIDs returns the set of the items' tool IDs.
+func toolIDs(items []displayItem) map[string]bool {
+	ids := make(map[string]bool, len(items))
+	for _, item := range items {
+		ids[item.toolID] = true
+	}
+	return ids
+}
+
+// sessionTokens sums the token counts of the Claude messages.
+func sessionTokens(msgs []message) int {
+	total := 0
+	for _, msg := range msgs {
+		if msg.role == RoleClaude {
+			total += msg.tokensRaw
+		}
+	}
+	return total
+}
+
+// newWebhookEvent fills the fields every event carries.
+func (m model) newWebhookEvent(event string, now time.Time) webhookEvent {
+	ev := webhookEvent{
+		Event:   event,
+		Time:    now.UTC(),
+		Session: strings.TrimSuffix(filepath.Base(m.sessionPath), ".jsonl"),
+		Path:    m.sessionPath,
+		Cwd:     m.sessionCwd,
+		Branch:  m.sessionGitBranch,
+		Tokens:  sessionTokens(m.rawMessages),
+	}
+	for i := len(m.rawMessages) - 1; i >= 0; i-- {
+		if m.rawMessages[i].role == RoleUser {
+			ev.Prompt = parser.Truncate(m.rawMessages[i].content, webhookResultChars)
+			ev.Turn = promptNumber(m.rawMessages, i)
+			break
+		}
+	}
+	return ev
+}
+
+// tailWebhookEvents returns the events a tail update brought: tool calls that
+// failed since the last update and a crossed token budget.
+func (m *model) tailWebhookEvents(now time.Time) []webhookEvent {
+	m.hookState.idle = false
+	hook := m.cfg.Webhook
+	if hook == nil {
+		return nil
+	}
+	var events []webhookEvent
+
+	// Failures are told apart by tool ID: eviction from the tail window
+	// drops old ones as new ones arrive. Only the resident IDs are kept.
+	failed := failedToolCalls(m.rawMessages)
+	if hook.wants(eventToolError) {
+		for _, item := range failed {
+			if m.hookState.toolErrors[item.toolID] {
+				continue
+			}
+			ev := m.newWebhookEvent(eventToolError, now)
+			ev.Tool = item.toolName
+			ev.Target = item.toolSummary
+			ev.Error = parser.Truncate(item.toolResult, webhookResultChars)
+			events = append(events, ev)
+		}
+	}
+	m.hookState.toolErrors = toolIDs(failed)
+
+	if hook.TokenBudget > 0 && !m.hookState.overBudget && sessionTokens(m.rawMessages) >= hook.TokenBudget {
+		m.hookState.overBudget = true
+		if hook.wants(eventBudgetExceeded) {
+			ev := m.newWebhookEvent(eventBudgetExceeded, now)
+			ev.Budget = hook.TokenBudget
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// turnCompletedEvent returns turn_completed with Claude's last output.
+func (m model) turnCompletedEvent(now time.Time) []webhookEvent {
+	if !m.cfg.Webhook.wants(eventTurnCompleted) {
+		return nil
+	}
+	ev := m.newWebhookEvent(eventTurnCompleted, now)
+	for i := len(m.rawMessages) - 1; i >= 0; i-- {
+		lo := m.rawMessages[i].lastOutput
+		if m.rawMessages[i].role == RoleClaude && lo != nil && lo.Type == parser.LastOutputText {
+			ev.Output = parser.Truncate(lo.Text, webhookResultChars)
+			break
+		}
+	}
+	return []webhookEvent{ev}
+}
+
+// idleEvent returns session_idle once per silence.
+func (m *model) idleEvent(now time.Time) []webhookEvent {
+	if m.hookState.idle || !m.cfg.Webhook.wants(eventSessionIdle) {
+		return nil
+	}
+	m.hookState.idle = true
+	return []webhookEvent{m.newWebhookEvent(eventSessionIdle, now)}
+}
+
+// postWebhooksCmd posts events in order off the UI goroutine. Nil when there
+// is nothing to send.
+func postWebhooksCmd(hook *webhookConfig, events []webhookEvent) tea.Cmd {
+	if hook == nil || len(events) == 0 {
+		return nil
+	}
+	c := *hook
+	return func() tea.Msg {
+		for _, ev := range events {
+			if err := postWebhook(webhookClient, c, ev); err != nil {
+				return webhookSentMsg{event: ev.Event, err: err}
+			}
+		}
+		return nil
+	}
+}
+
+// postWebhook sends one event, signed when a secret is configured.
+func postWebhook(client *http.Client, c webhookConfig, ev webhookEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tail-claude")
+	req.Header.Set("X-Tail-Claude-Event", ev.Event)
+	if c.Secret != "" {
+		req.Header.Set(webhookSignatureHeader, webhookSignature(c.Secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+// webhookSignature returns "sha256=" and the hex HMAC-SHA256 of body.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
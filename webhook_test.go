@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestPostWebhook(t *testing.T) {
+	var got webhookEvent
+	var signature, event string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get(webhookSignatureHeader)
+		event = r.Header.Get("X-Tail-Claude-Event")
+		if signature != webhookSignature("s3cret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	c := webhookConfig{URL: srv.URL, Secret: "s3cret"}
+	ev := webhookEvent{Event: eventToolError, Session: "abc", Tool: "Bash", Error: "exit status 1"}
+	if err := postWebhook(srv.Client(), c, ev); err != nil {
+		t.Fatal(err)
+	}
+	if got.Tool != "Bash" || event != eventToolError {
+		t.Errorf("received %+v with event header %q", got, event)
+	}
+
+	c.Secret = "wrong"
+	if err := postWebhook(srv.Client(), c, ev); err == nil {
+		t.Error("a non-2xx response should be an error")
+	}
+}
+
+func TestTailWebhookEvents(t *testing.T) {
+	failing := func(result string) func(*message) {
+		return func(m *message) {
+			m.tokensRaw = 600
+			m.items = append(m.items, displayItem{itemType: parser.ItemToolCall, toolName: "Bash", toolID: "toolu_" + result, toolError: true, toolResult: result})
+		}
+	}
+	m := initialModel([]message{userMsg("fix it"), claudeMsg(failing("old failure"))}, true)
+	m.cfg.Webhook = &webhookConfig{URL: "http://example.invalid", TokenBudget: 1000}
+	m.resetWebhookState()
+
+	m.setMessages(append(m.rawMessages, claudeMsg(failing("new failure"))))
+	events := m.tailWebhookEvents(time.Now())
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want tool_error and budget_exceeded", len(events))
+	}
+	if events[0].Event != eventToolError || events[0].Error != "new failure" || events[0].Prompt != "fix it" {
+		t.Errorf("tool_error = %+v, want only the new failure", events[0])
+	}
+	if events[1].Event != eventBudgetExceeded || events[1].Tokens != 1200 {
+		t.Errorf("budget event = %+v", events[1])
+	}
+	if again := m.tailWebhookEvents(time.Now()); len(again) != 0 {
+		t.Errorf("events should fire once, got %+v", again)
+	}
+
+	// The window evicts the first failure as another arrives: the count
+	// stays the same, but the new one is still news.
+	m.setMessages(append(m.rawMessages[2:], claudeMsg(failing("after eviction"))))
+	if got := m.tailWebhookEvents(time.Now()); len(got) != 1 || got[0].Error != "after eviction" {
+		t.Errorf("events after eviction = %+v, want the new failure", got)
+	}
+
+	if len(m.idleEvent(time.Now())) != 1 || len(m.idleEvent(time.Now())) != 0 {
+		t.Error("session_idle should fire once per silence")
+	}
+
+	m.cfg.Webhook.Events = []string{eventSessionIdle}
+	m.setMessages(append(m.rawMessages, claudeMsg(failing("filtered"))))
+	if got := m.tailWebhookEvents(time.Now()); len(got) != 0 {
+		t.Errorf("events outside the configured list should be dropped, got %+v", got)
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	for _, c := range []webhookConfig{
+		{URL: "hooks.slack.com/services/x"},
+		{URL: "ftp://example.com"},
+		{URL: "https://example.com", Events: []string{"turn_done"}},
+	} {
+		if validateWebhook(&c) == nil {
+			t.Errorf("validateWebhook(%+v) should fail", c)
+		}
+	}
+	if err := validateWebhook(&webhookConfig{URL: "https://example.com/hook", Events: []string{eventToolError}}); err != nil {
+		t.Error(err)
+	}
+}
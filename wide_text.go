@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// Expanded tool results can hold single tokens tens of kilobytes long:
+// base64 images, minified JS. lipgloss wraps such a token
+// slowly, cell by cell, on every render. These helpers shorten and break
+// them first; v in the detail view pages the raw result instead.
+
+// minBase64Run is the shortest run of base64 characters abbreviated in
+// expanded tool results, long enough that words, paths, and hashes never
+// qualify.
+const minBase64Run = 1024
+
+// isBase64Byte reports whether c can appear in standard or URL-safe base64.
+func isBase64Byte(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '+' || c == '/' || c == '-' || c == '_' || c == '='
+}
+
+// looksBase64 reports whether run mixes upper case, lower case, and digits,
+// as encoded bytes do and rules ("-----") or hex dumps don't.
+func looksBase64(run string) bool {
+	var upper, lower, digit bool
+	for i := 0; i < len(run); i++ {
+		c := run[i]
+		upper = upper || 'A' <= c && c <= 'Z'
+		lower = lower || 'a' <= c && c <= 'z'
+		digit = digit || '0' <= c && c <= '9'
+		if upper && lower && digit {
+			return true
+		}
+	}
+	return false
+}
+
+// abbreviateBase64 replaces each run of at least minBase64Run base64
+// characters with "…[37.5 KB base64 omitted]". The replacement holds no
+// quotes or backslashes, so JSON stays valid.
+func abbreviateBase64(text string) string {
+	var b strings.Builder
+	last, start := 0, -1
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && isBase64Byte(text[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minBase64Run && looksBase64(text[start:i]) {
+			b.WriteString(text[last:start])
+			b.WriteString("…[" + formatBytes(float64(i-start)) + " base64 omitted]")
+			last = i
+		}
+		start = -1
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// breakLongRuns hard-breaks every space-free run wider than width onto
+// lines of at most width cells. Reports false, with text unchanged, when
+// no run needed breaking.
+func breakLongRuns(text string, width int) (string, bool) {
+	if width <= 0 {
+		return text, false
+	}
+	broke := false
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if len(line) <= width {
+			continue
+		}
+		words := strings.Split(line, " ")
+		for j, word := range words {
+			if len(word) <= width || lipgloss.Width(word) <= width {
+				continue
+			}
+			var pieces []string
+			for word != "" {
+				var head string
+				head, word = parser.CutWidth(word, width)
+				pieces = append(pieces, head)
+			}
+			words[j] = strings.Join(pieces, "\n")
+			broke = true
+		}
+		lines[i] = strings.Join(words, " ")
+	}
+	if !broke {
+		return text, false
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// pagerCmd returns an *exec.Cmd that shows text in the user's $PAGER, or
+// less when none is set. The text arrives on stdin; pagers read keys from
+// the terminal.
+func pagerCmd(text string) *exec.Cmd {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = []string{"less"}
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd
+}
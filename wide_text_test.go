@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"charm.land/lipgloss/v2"
+)
+
+func TestAbbreviateBase64(t *testing.T) {
+	blob := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("image bytes ", 3200)))
+	got := abbreviateBase64(`{"type":"image","data":"` + blob + `"}`)
+	if want := `{"type":"image","data":"…[50.0 KB base64 omitted]"}`; got != want {
+		t.Errorf("abbreviateBase64 = %q, want %q", got, want)
+	}
+
+	for _, text := range []string{
+		"a short line with sha 3f9a2c1b",
+		strings.Repeat("-", 2000),         // a rule, not encoded bytes
+		strings.Repeat("deadbeef01", 200), // hex: no upper case
+		"aB3" + strings.Repeat("x", 100),  // too short
+	} {
+		if got := abbreviateBase64(text); got != text {
+			t.Errorf("abbreviateBase64 changed %.20q... to %.40q", text, got)
+		}
+	}
+}
+
+func TestBreakLongRuns(t *testing.T) {
+	text := "short words\n" + strings.Repeat("x", 25) + " tail"
+	got, ok := breakLongRuns(text, 10)
+	if !ok {
+		t.Fatal("breakLongRuns reported nothing to break")
+	}
+	for _, word := range strings.Fields(got) {
+		if w := lipgloss.Width(word); w > 10 {
+			t.Errorf("run %q is %d cells, want at most 10", word, w)
+		}
+	}
+	if strings.ReplaceAll(got, "\n", "") != strings.ReplaceAll(text, "\n", "") {
+		t.Errorf("breakLongRuns lost text: %q", got)
+	}
+
+	if _, ok := breakLongRuns("a few words that wrap at spaces", 10); ok {
+		t.Error("breakLongRuns broke text with no long run")
+	}
+}
+
+func TestRenderToolResult_WideJSON(t *testing.T) {
+	m := testModel()
+	blob := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("png", 1000)))
+	got := plain(m.renderToolResult(`{"data":"`+blob+`","name":"`+strings.Repeat("n", 150)+`"}`, 60))
+	if !strings.Contains(got, "KB base64") {
+		t.Errorf("blob not abbreviated:\n%s", got)
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if w := lipgloss.Width(line); w > 60 {
+			t.Errorf("line is %d cells, want at most 60: %q", w, line)
+		}
+	}
+
+	m.cfg.KeepBase64 = true
+	if got := plain(m.renderToolResult(`{"data":"`+blob+`"}`, 60)); strings.Contains(got, "omitted") {
+		t.Error("keepBase64 still abbreviated the blob")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// evict drops the oldest turns once more than w.window prompts are resident,
+// remembering the file offset where the resident messages begin so the
+// dropped turns can be re-read later. Messages before the first kept prompt
+// go with them. Returns whether anything was dropped. Only called from run().
+func (w *sessionWatcher) evict() bool {
+	if w.window <= 0 || w.fullHistory || len(w.lineOffsets) != len(w.allClassified) {
+		return false
+	}
+	var prompts []int
+	for i, msg := range w.allClassified {
+		if _, ok := msg.(parser.UserMsg); ok {
+			prompts = append(prompts, i)
+		}
+	}
+	drop := len(prompts) - w.window
+	if drop <= 0 {
+		return false
+	}
+	cut := prompts[drop]
+	w.windowStart = w.lineOffsets[cut]
+	w.evictedTurns += drop
+	// Clone so the evicted messages' backing array can be collected.
+	w.allClassified = slices.Clone(w.allClassified[cut:])
+	w.lineOffsets = slices.Clone(w.lineOffsets[cut:])
+	return true
+}
+
+// startPartial notes that the session was read from offset on, as a large
+// session opened reduced is: the turns before it are unread rather than
+// evicted, and the lineage starts there too. Called before run().
+func (w *sessionWatcher) startPartial(offset int64) {
+	if offset == 0 {
+		return
+	}
+	w.windowStart, w.partial = offset, true
+	w.lineage = parser.NewLineageFrom(offset)
+}
+
+// setFullHistory reloads the evicted turns from disk and pauses eviction
+// (full), or resumes windowing and evicts again (!full). Only called from
+// run().
+func (w *sessionWatcher) setFullHistory(full bool) {
+	if !full {
+		w.fullHistory = false
+		w.evict()
+		return
+	}
+	if w.evictedTurns == 0 && !w.partial {
+		return
+	}
+	msgs, offsets, err := parser.ReadSourceRange(w.src, 0, w.windowStart)
+	if err != nil {
+		w.reportErr(fmt.Errorf("reloading evicted turns: %w", err))
+		return
+	}
+	w.allClassified = append(msgs, w.allClassified...)
+	w.lineOffsets = append(offsets, w.lineOffsets...)
+	if w.partial {
+		// The lineage never saw the reloaded lines.
+		w.lineage = parser.NewLineage()
+	}
+	w.windowStart, w.evictedTurns, w.partial = 0, 0, false
+	w.fullHistory = true
+}
+
+// requestHistory asks the watcher to reload evicted turns (full) or resume
+// windowing. Safe to call from the UI goroutine; a pending request is
+// replaced by the newer one.
+func (w *sessionWatcher) requestHistory(full bool) {
+	select {
+	case w.history <- full:
+	default:
+		select {
+		case <-w.history:
+		default:
+		}
+		w.history <- full
+	}
+}
+
+// evictionShift returns how many places the messages kept from old moved up
+// in msgs after the oldest were evicted: the first old message still there,
+// found by uuid, tells. When none is left, all of old went.
+func evictionShift(old, msgs []message) int {
+	at := make(map[string]int, len(msgs))
+	for i, msg := range msgs {
+		if msg.uuid != "" {
+			at[msg.uuid] = i
+		}
+	}
+	for i, msg := range old {
+		if j, ok := at[msg.uuid]; ok && msg.uuid != "" {
+			return i - j
+		}
+	}
+	return len(old)
+}
+
+// shiftMessages moves the index-keyed list state up after n messages and
+// turns turns were evicted from the front: the cursor, the expanded
+// messages, the folded turns, and the anchor the next relayout takes from
+// the layout still on screen. State of evicted messages is dropped.
+func (m *model) shiftMessages(n, turns int) {
+	if n > 0 {
+		m.cursor = max(m.cursor-n, 0)
+		m.layoutShift += n
+		expanded := make(map[int]bool, len(m.expanded))
+		for i, v := range m.expanded {
+			if i >= n {
+				expanded[i-n] = v
+			}
+		}
+		m.expanded = expanded
+	}
+	folded := make(map[int]bool, len(m.foldedTurns))
+	for t, v := range m.foldedTurns {
+		if t > turns {
+			folded[t-turns] = v
+		}
+	}
+	m.foldedTurns = folded
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// writeTurns writes a session with n prompt/answer turns and returns its path.
+func writeTurns(t *testing.T, n int) string {
+	t.Helper()
+	var b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&b, `{"uuid":"u%d","type":"user","timestamp":"2025-01-15T10:%02d:00.000Z","message":{"role":"user","content":"prompt %d"}}`+"\n", i, i, i)
+		fmt.Fprintf(&b, `{"uuid":"a%d","type":"assistant","timestamp":"2025-01-15T10:%02d:05.000Z","message":{"role":"assistant","content":[{"type":"text","text":"answer %d"}],"model":"claude-opus-4-6","stop_reason":"end_turn"}}`+"\n", i, i, i)
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// firstPrompt returns the text of the first resident user message.
+func firstPrompt(w *sessionWatcher) string {
+	for _, msg := range w.allClassified {
+		if u, ok := msg.(parser.UserMsg); ok {
+			return u.Text
+		}
+	}
+	return ""
+}
+
+func TestSessionWatcher_Window(t *testing.T) {
+	path := writeTurns(t, 5)
+	msgs, offsets, end, err := parser.ReadSessionIncrementalOffsets(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newSessionWatcher(parser.FileSource(path), msgs, end)
+	w.lineOffsets = offsets
+	w.window = 2
+
+	if !w.evict() {
+		t.Fatal("evict() = false, want 3 turns dropped")
+	}
+	if w.evictedTurns != 3 || len(w.allClassified) != 4 || len(w.lineOffsets) != 4 {
+		t.Fatalf("evicted %d turns, %d resident (%d offsets); want 3, 4, 4",
+			w.evictedTurns, len(w.allClassified), len(w.lineOffsets))
+	}
+	if got := firstPrompt(w); got != "prompt 3" {
+		t.Errorf("first resident prompt = %q, want %q", got, "prompt 3")
+	}
+	if w.evict() {
+		t.Error("second evict() = true, want nothing left to drop")
+	}
+
+	// Reloading restores every turn and pauses eviction.
+	w.setFullHistory(true)
+	if w.evictedTurns != 0 || !w.fullHistory || len(w.allClassified) != 10 {
+		t.Fatalf("after reload: evicted %d, full %v, %d resident; want 0, true, 10",
+			w.evictedTurns, w.fullHistory, len(w.allClassified))
+	}
+	if got := firstPrompt(w); got != "prompt 0" {
+		t.Errorf("first prompt after reload = %q, want %q", got, "prompt 0")
+	}
+	for i := range offsets {
+		if w.lineOffsets[i] != offsets[i] {
+			t.Errorf("lineOffsets[%d] = %d, want %d", i, w.lineOffsets[i], offsets[i])
+		}
+	}
+
+	// Resuming windowing evicts again.
+	w.setFullHistory(false)
+	if w.fullHistory || w.evictedTurns != 3 {
+		t.Errorf("after resume: full %v, evicted %d; want false, 3", w.fullHistory, w.evictedTurns)
+	}
+}
+
+func TestSessionWatcher_NoWindow(t *testing.T) {
+	path := writeTurns(t, 3)
+	msgs, offsets, end, err := parser.ReadSessionIncrementalOffsets(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newSessionWatcher(parser.FileSource(path), msgs, end)
+	w.lineOffsets = offsets
+	if w.evict() || len(w.allClassified) != 6 {
+		t.Errorf("window 0 evicted messages: %d resident, want 6", len(w.allClassified))
+	}
+}
+
+func TestTailUpdate_EvictionKeepsPlace(t *testing.T) {
+	turns := func(from, to int) []message {
+		var msgs []message
+		for i := from; i < to; i++ {
+			u := userMsg(fmt.Sprintf("prompt %d", i))
+			u.uuid = fmt.Sprintf("u%d", i)
+			msgs = append(msgs, u, claudeMsg(func(m *message) {
+				m.uuid = fmt.Sprintf("a%d", i)
+				m.content = fmt.Sprintf("answer %d", i)
+			}))
+		}
+		return msgs
+	}
+	m := initialModel(turns(0, 20), true)
+	m.width, m.height = 120, 20
+	m.layoutList()
+	m.cursor = 30 // u15, well short of the end
+	m.expanded[31] = true
+	m.ensureCursorVisible()
+	row := m.lineOffsets[m.cursor] - m.scroll
+
+	// Three turns go from the front as one arrives at the end.
+	result, _ := m.Update(tailUpdateMsg{messages: turns(3, 21), evictedTurns: 3})
+	m = asModel(result)
+	if got := m.messages[m.cursor].uuid; got != "u15" {
+		t.Errorf("cursor on %s, want u15", got)
+	}
+	if !m.expanded[m.cursor+1] || len(m.expanded) != 1 {
+		t.Errorf("expanded = %v, want only a15 (%d)", m.expanded, m.cursor+1)
+	}
+	if got := m.lineOffsets[m.cursor] - m.scroll; got != row {
+		t.Errorf("cursor message on row %d, want it kept on %d", got, row)
+	}
+}
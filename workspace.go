@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"charm.land/lipgloss/v2"
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+// workspaceConfig groups repos worked on together, e.g. a frontend and its
+// backend. Run from any of them, tail-claude lists the sessions of all of
+// them in the picker and the digest, each labeled with its root.
+type workspaceConfig struct {
+	Name  string          `json:"name,omitempty"` // shown in the picker header; defaults to "workspace"
+	Roots []workspaceRoot `json:"roots"`          // repo roots, in the order their labels are listed
+}
+
+// workspaceRoot is one repo of a workspace.
+type workspaceRoot struct {
+	Path  string `json:"path"`            // where Claude Code runs for the repo; a leading "~/" is the home directory
+	Label string `json:"label,omitempty"` // shown on its sessions; defaults to the directory name
+}
+
+// workspace is a workspaceConfig resolved to project directories.
+type workspace struct {
+	name   string
+	dirs   []string          // project directory of each root, in config order
+	labels map[string]string // project directory -> root label
+	width  int               // widest label in cells, for aligning picker rows
+}
+
+// validateWorkspaces reports the first workspace without roots or with a
+// root without a path.
+func validateWorkspaces(ws []workspaceConfig) error {
+	for i, w := range ws {
+		name := w.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if len(w.Roots) == 0 {
+			return fmt.Errorf("workspace %s has no roots", name)
+		}
+		for _, r := range w.Roots {
+			if r.Path == "" {
+				return fmt.Errorf("workspace %s has a root without a path", name)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveWorkspace returns the first configured workspace with a root whose
+// project directory is projectDir, or nil when none has one.
+func resolveWorkspace(ws []workspaceConfig, projectDir string) *workspace {
+	if projectDir == "" {
+		return nil
+	}
+	for _, wc := range ws {
+		w := &workspace{name: wc.Name, labels: make(map[string]string)}
+		if w.name == "" {
+			w.name = "workspace"
+		}
+		member := false
+		for _, r := range wc.Roots {
+			path := expandHome(r.Path)
+			dir, err := parser.ProjectDirForPath(path)
+			if err != nil {
+				continue
+			}
+			label := r.Label
+			if label == "" {
+				label = filepath.Base(path)
+			}
+			w.dirs = append(w.dirs, dir)
+			w.labels[dir] = label
+			w.width = max(w.width, lipgloss.Width(label))
+			member = member || dir == projectDir
+		}
+		if member {
+			return w
+		}
+	}
+	return nil
+}
+
+// label returns the label of the root a session file belongs to, or "" for
+// sessions outside the workspace (an archive from sessionDirs, say).
+// Sessions in subdirectories of a root's project directory belong to it.
+func (w *workspace) label(sessionPath string) string {
+	if w == nil {
+		return ""
+	}
+	for dir := filepath.Dir(sessionPath); ; dir = filepath.Dir(dir) {
+		if label, ok := w.labels[dir]; ok {
+			return label
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// homeProjectDirs returns the project directories the picker lists outside
+// worktree mode: the current project's and, in a workspace, every root's.
+func (m model) homeProjectDirs() []string {
+	dirs := []string{m.projectDir}
+	if m.workspace != nil {
+		dirs = append(dirs, m.workspace.dirs...)
+	}
+	return dedup(dirs)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/tail-claude/parser"
+)
+
+func TestResolveWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	ws := []workspaceConfig{
+		{Name: "docs", Roots: []workspaceRoot{{Path: "~/code/site"}}},
+		{Name: "shop", Roots: []workspaceRoot{
+			{Path: "~/code/web", Label: "frontend"},
+			{Path: filepath.Join(home, "code", "api")},
+		}},
+	}
+	web, _ := parser.ProjectDirForPath(filepath.Join(home, "code", "web"))
+	api, _ := parser.ProjectDirForPath(filepath.Join(home, "code", "api"))
+
+	w := resolveWorkspace(ws, api)
+	if w == nil || w.name != "shop" {
+		t.Fatalf("resolveWorkspace(api) = %+v, want the shop workspace", w)
+	}
+	if len(w.dirs) != 2 || w.dirs[0] != web || w.dirs[1] != api {
+		t.Errorf("dirs = %v, want [%s %s]", w.dirs, web, api)
+	}
+	if w.width != len("frontend") {
+		t.Errorf("width = %d, want %d", w.width, len("frontend"))
+	}
+	if got := w.label(filepath.Join(web, "abc.jsonl")); got != "frontend" {
+		t.Errorf("label(web session) = %q, want frontend", got)
+	}
+	if got := w.label(filepath.Join(api, "abc", "subagents", "agent-1.jsonl")); got != "api" {
+		t.Errorf("label(api subagent) = %q, want api", got)
+	}
+	if got := w.label(filepath.Join(home, "elsewhere", "abc.jsonl")); got != "" {
+		t.Errorf("label(outside) = %q, want empty", got)
+	}
+
+	other, _ := parser.ProjectDirForPath(filepath.Join(home, "code", "other"))
+	if w := resolveWorkspace(ws, other); w != nil {
+		t.Errorf("resolveWorkspace(other) = %+v, want nil", w)
+	}
+	var none *workspace
+	if got := none.label(filepath.Join(web, "abc.jsonl")); got != "" {
+		t.Errorf("nil workspace label = %q, want empty", got)
+	}
+}
+
+func TestValidateWorkspaces(t *testing.T) {
+	tests := []struct {
+		ws   []workspaceConfig
+		want string
+	}{
+		{[]workspaceConfig{{Name: "shop", Roots: []workspaceRoot{{Path: "~/web"}}}}, ""},
+		{[]workspaceConfig{{Name: "shop"}}, "workspace shop has no roots"},
+		{[]workspaceConfig{{Roots: []workspaceRoot{{Label: "web"}}}}, "workspace #1 has a root without a path"},
+	}
+	for _, tt := range tests {
+		err := validateWorkspaces(tt.ws)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("validateWorkspaces(%+v) = %v, want nil", tt.ws, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("validateWorkspaces(%+v) = %v, want %q", tt.ws, err, tt.want)
+		}
+	}
+}
+
+func TestPickerWorkspaceLabels(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	web, _ := parser.ProjectDirForPath(filepath.Join(home, "web"))
+	m := testModel()
+	m.workspace = resolveWorkspace([]workspaceConfig{{Name: "shop", Roots: []workspaceRoot{
+		{Path: "~/web"}, {Path: "~/api", Label: "backend"},
+	}}}, web)
+	m.projectDir = web
+	s := &parser.SessionInfo{Path: filepath.Join(web, "abc.jsonl"), SessionID: "abc", FirstMessage: "fix checkout"}
+	lines := m.renderPickerSession(s, false, 100, 0)
+	if got := plain(strings.Join(lines, "\n")); !strings.Contains(got, "web    ") {
+		t.Errorf("picker row = %q, want the padded root label", got)
+	}
+	if got := m.homeProjectDirs(); len(got) != 2 {
+		t.Errorf("homeProjectDirs = %v, want both roots", got)
+	}
+}
+
+func TestPickerWorkspaceLabels_WideLabel(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	web, _ := parser.ProjectDirForPath(filepath.Join(home, "web"))
+	m := testModel()
+	// "後端" is six bytes but four cells: "web" pads to four, not six.
+	m.workspace = resolveWorkspace([]workspaceConfig{{Name: "shop", Roots: []workspaceRoot{
+		{Path: "~/web"}, {Path: "~/api", Label: "後端"},
+	}}}, web)
+	m.projectDir = web
+	s := &parser.SessionInfo{Path: filepath.Join(web, "abc.jsonl"), SessionID: "abc", FirstMessage: "fix checkout"}
+	got := plain(strings.Join(m.renderPickerSession(s, false, 100, 0), "\n"))
+	if m.workspace.width != 4 || !strings.Contains(got, "web    abc") { // one cell of padding, then the separator
+		t.Errorf("width %d, picker row = %q, want the label padded to four cells", m.workspace.width, got)
+	}
+}